@@ -1,18 +1,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/sirupsen/logrus"
 
+	"accesslog-tracker/deployments/database/migrations"
 	"accesslog-tracker/internal/api/server"
+	"accesslog-tracker/internal/botdetect"
 	"accesslog-tracker/internal/config"
 	"accesslog-tracker/internal/domain/services"
+	"accesslog-tracker/internal/geoip"
 	"accesslog-tracker/internal/infrastructure/database/postgresql"
 	postgresqlRepos "accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
+	"accesslog-tracker/internal/infrastructure/cache/memory"
 	"accesslog-tracker/internal/infrastructure/cache/redis"
+	"accesslog-tracker/internal/ingestion"
+	"accesslog-tracker/internal/referrer"
+	"accesslog-tracker/internal/uaparser"
 	"accesslog-tracker/internal/utils/logger"
 )
 
@@ -47,20 +55,111 @@ func main() {
 	}
 	defer dbConn.Close()
 
+	// マイグレーションの自動適用（アドバイザリロックにより複数ポッドでも1つずつ実行される）
+	if cfg.Database.AutoMigrate {
+		migrator, err := postgresql.NewMigrator(dbConn.GetDB(), migrations.FS)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load migrations")
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to apply migrations")
+		}
+		for _, m := range applied {
+			logger.WithFields(logrus.Fields{"version": m.Version, "name": m.Name}).Info("Applied migration")
+		}
+	}
+
 	// Redis接続の初期化
+	// 接続できない場合もプロセス内のレート制限とキャッシュで起動し、疎通を定期的に確認して復帰する
 	redisConn := redis.NewCacheService(fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port))
 	if err := redisConn.Connect(); err != nil {
-		logger.WithError(err).Fatal("Failed to connect to Redis")
+		logger.WithError(err).Warn("Redis is unavailable, starting in degraded mode")
 	}
 	defer redisConn.Close()
+	go redis.NewMonitor(redisConn, cfg.GetRedisProbeInterval(), logger).Run(context.Background())
 
 	// リポジトリの初期化
 	trackingRepo := postgresqlRepos.NewTrackingRepository(dbConn.GetDB())
 	applicationRepo := postgresqlRepos.NewApplicationRepository(dbConn.GetDB())
+	organizationRepo := postgresqlRepos.NewOrganizationRepository(dbConn.GetDB())
+
+	// インジェストパイプラインの初期化
+	var trackingOpts []services.TrackingServiceOption
+	if cfg.Ingestion.Enabled {
+		pipeline := ingestion.NewPipeline(trackingRepo, ingestion.Config{
+			QueueSize:     cfg.Ingestion.QueueSize,
+			Workers:       cfg.Ingestion.Workers,
+			BatchSize:     cfg.Ingestion.BatchSize,
+			FlushInterval: cfg.GetIngestionFlushInterval(),
+			WriteTimeout:  cfg.GetIngestionWriteTimeout(),
+			MaxRetries:    cfg.Ingestion.MaxRetries,
+			RetryBackoff:  cfg.GetIngestionRetryBackoff(),
+		}, logger)
+		pipeline.Start()
+		trackingOpts = append(trackingOpts, services.WithIngestionPipeline(pipeline))
+	}
+
+	// ユーザーエージェントの解析ルール（UA_PARSER_RULES_PATH が空の場合は組み込みのルール）
+	uaParser, err := uaparser.NewFromFile(cfg.UserAgent.RulesPath, cfg.UserAgent.CacheSize)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load user agent rules")
+	}
+	trackingOpts = append(trackingOpts, services.WithUserAgentParser(uaParser))
+
+	// ボットの判定（BOT_PATTERNS_PATH が空の場合は組み込みのパターン）
+	botDetector, err := botdetect.New(botdetect.Config{
+		PatternsPath:   cfg.Bot.PatternsPath,
+		DatacenterPath: cfg.Bot.DatacenterRangesPath,
+		RateLimit:      cfg.Bot.RateLimit,
+		RateWindow:     cfg.GetBotRateWindow(),
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load bot detection rules")
+	}
+	trackingOpts = append(trackingOpts, services.WithBotDetector(botDetector))
+
+	// 流入元の判定（REFERRER_SOURCES_PATH が空の場合は組み込みの一覧）
+	referrerClassifier, err := referrer.NewFromFile(cfg.Referrer.SourcesPath)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load referrer sources")
+	}
+	trackingOpts = append(trackingOpts, services.WithReferrerClassifier(referrerClassifier))
+
+	// 位置情報の判定（データベースのパスが設定されている場合のみ。ファイルが変更されると読み込み直す）
+	if cfg.GeoIP.CityPath != "" || cfg.GeoIP.ASNPath != "" {
+		geoResolver := geoip.NewResolver(geoip.Config{
+			CityPath:       cfg.GeoIP.CityPath,
+			ASNPath:        cfg.GeoIP.ASNPath,
+			ReloadInterval: cfg.GetGeoIPReloadInterval(),
+		}, logger)
+		if err := geoResolver.Reload(); err != nil {
+			logger.WithError(err).Warn("Failed to load GeoIP database, events are stored without location until it becomes available")
+		}
+		go geoResolver.Run(context.Background())
+		trackingOpts = append(trackingOpts, services.WithGeoResolver(geoResolver))
+	}
+
+	// 集計済みの範囲の統計はワーカーが作成したロールアップから読み込む
+	if cfg.Rollup.Enabled {
+		trackingOpts = append(trackingOpts, services.WithRollups(postgresqlRepos.NewRollupRepository(dbConn.GetDB())))
+	}
 
 	// サービスの初期化
-	trackingService := services.NewTrackingService(trackingRepo)
-	applicationService := services.NewApplicationService(applicationRepo, redisConn)
+	trackingService := services.NewTrackingService(trackingRepo, trackingOpts...)
+	// アプリケーションのキャッシュの削除はRedisのpub/subで他のインスタンスにも通知する
+	invalidationBus := redis.NewInvalidationBus(redisConn.GetClient(), redis.ApplicationInvalidationChannel, logger)
+	applicationService := services.NewApplicationService(
+		applicationRepo,
+		redis.NewFallbackCache(redisConn, memory.NewCache(memory.DefaultConfig())),
+		services.WithAPIKeyRotationGracePeriod(cfg.GetAPIKeyRotationGracePeriod()),
+		services.WithUsage(redis.NewUsageCounter(redisConn.GetClient()), postgresqlRepos.NewUsageRepository(dbConn.GetDB())),
+		services.WithLocalCache(cfg.AppCache.LocalSize, cfg.GetAppCacheLocalTTL()),
+		services.WithInvalidAPIKeyCacheTTL(cfg.GetAppCacheInvalidKeyTTL()),
+		services.WithCacheInvalidator(invalidationBus),
+	)
+	go invalidationBus.Subscribe(context.Background(), applicationService)
+	organizationService := services.NewOrganizationService(organizationRepo)
 
 	// APIサーバーの初期化
 	apiServer := server.NewServer(
@@ -68,6 +167,7 @@ func main() {
 		logger,
 		trackingService,
 		applicationService,
+		organizationService,
 		dbConn,
 		redisConn,
 	)
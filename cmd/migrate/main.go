@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"accesslog-tracker/deployments/database/migrations"
+	"accesslog-tracker/internal/config"
+	"accesslog-tracker/internal/infrastructure/database/postgresql"
+)
+
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	GoVersion = "unknown"
+)
+
+func main() {
+	var (
+		dir     = flag.String("dir", "deployments/database/migrations", "Migrations directory (used by create)")
+		timeout = flag.Duration("timeout", 10*time.Minute, "Timeout for the whole command")
+		help    = flag.Bool("help", false, "Show help")
+	)
+	flag.Usage = showHelp
+	flag.Parse()
+
+	if *help || flag.NArg() == 0 {
+		showHelp()
+		return
+	}
+
+	command := flag.Arg(0)
+
+	// create はデータベース接続なしで実行できる
+	if command == "create" {
+		if flag.NArg() < 2 {
+			log.Fatal("Usage: migrate create <name>")
+		}
+		if err := createMigration(*dir, flag.Arg(1)); err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		return
+	}
+
+	// 設定の読み込み
+	cfg := config.New()
+	if err := cfg.LoadFromEnv(); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// データベース接続の初期化
+	dbConn := postgresql.NewConnection("migrate")
+	if err := dbConn.Connect(cfg.GetDatabaseDSN()); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer dbConn.Close()
+
+	migrator, err := postgresql.NewMigrator(dbConn.GetDB(), migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			if steps, err = strconv.Atoi(flag.Arg(1)); err != nil || steps <= 0 {
+				log.Fatalf("Invalid number of steps: %s", flag.Arg(1))
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("Reverted %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+
+	case "redo":
+		redone, err := migrator.Redo(ctx)
+		if err != nil {
+			log.Fatalf("Redo failed: %v", err)
+		}
+		if redone == nil {
+			fmt.Println("No applied migrations")
+		} else {
+			fmt.Printf("Redone %03d_%s\n", redone.Version, redone.Name)
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to get migration status: %v", err)
+		}
+		printStatus(statuses)
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", command)
+		showHelp()
+		os.Exit(1)
+	}
+}
+
+// printStatus はマイグレーションの適用状況を表形式で出力します
+func printStatus(statuses []postgresql.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state := "pending"
+		appliedAt := "-"
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		if s.ChecksumMismatch {
+			state = "checksum mismatch"
+		}
+		if s.Missing {
+			state = "missing file"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	w.Flush()
+}
+
+// createMigration は次のバージョン番号で空のマイグレーションファイルを作成します
+func createMigration(dir, name string) error {
+	existing, err := postgresql.LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+
+	var next int64 = 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%03d_%s.%s.sql", next, name, direction))
+		content := fmt.Sprintf("-- %s (%s)\n", name, direction)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", path)
+	}
+	return nil
+}
+
+func showHelp() {
+	fmt.Printf(`Access Log Tracker Migration Tool %s
+
+Usage:
+  migrate [options] <command> [args]
+
+Commands:
+  up             Apply all pending migrations
+  down [N]       Roll back the last N migrations (default 1)
+  redo           Roll back and re-apply the last migration
+  status         Show applied and pending migrations
+  create <name>  Create a new pair of up/down migration files
+
+Options:
+  -dir string      Migrations directory (used by create)
+  -timeout value   Timeout for the whole command (default 10m)
+  -help            Show help
+
+Database settings are read from the same environment variables as the API server
+(DB_HOST, DB_PORT, DB_NAME, DB_USER, DB_PASSWORD, DB_SSL_MODE).
+`, Version)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -10,8 +11,15 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"accesslog-tracker/internal/config"
+	"accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/infrastructure/database/postgresql"
+	postgresqlRepos "accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
 	"accesslog-tracker/internal/infrastructure/cache/redis"
+	"accesslog-tracker/internal/partition"
+	"accesslog-tracker/internal/retention"
+	"accesslog-tracker/internal/rollup"
+	"accesslog-tracker/internal/usage"
 	"accesslog-tracker/internal/utils/logger"
 )
 
@@ -22,6 +30,18 @@ var (
 )
 
 func main() {
+	var (
+		backfillFrom = flag.String("backfill-from", "", "Recompute rollups from this date (YYYY-MM-DD, UTC) and exit")
+		backfillTo   = flag.String("backfill-to", "", "Last date to recompute with -backfill-from (YYYY-MM-DD, UTC, inclusive; default today)")
+	)
+	flag.Parse()
+
+	// 設定の読み込み
+	cfg := config.New()
+	if err := cfg.LoadFromEnv(); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
 	// ロガーの初期化
 	logger := logger.NewLogger()
 	logger.WithFields(logrus.Fields{
@@ -30,26 +50,31 @@ func main() {
 		"goVersion":  GoVersion,
 	}).Info("Starting Access Log Tracker Worker")
 
-	// 環境変数の取得
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbName := getEnv("DB_NAME", "access_log_tracker")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "password")
-	redisHost := getEnv("REDIS_HOST", "localhost")
-	redisPort := getEnv("REDIS_PORT", "6379")
-	redisPassword := getEnv("REDIS_PASSWORD", "")
-
 	// データベース接続の初期化
-	db, err := postgresql.NewConnection(dbHost, dbPort, dbName, dbUser, dbPassword)
-	if err != nil {
+	db := postgresql.NewConnection("worker")
+	if err := db.Connect(cfg.GetDatabaseDSN()); err != nil {
 		logger.WithError(err).Fatal("Failed to connect to database")
 	}
 	defer db.Close()
 
+	// ロールアップワーカーの初期化
+	rollupWorker := rollup.NewWorker(postgresqlRepos.NewRollupRepository(db.GetDB()), rollup.Config{
+		Interval:         cfg.GetRollupInterval(),
+		Delay:            cfg.GetRollupDelay(),
+		MaxBucketsPerRun: cfg.Rollup.MaxBucketsPerRun,
+	}, logger)
+
+	// バックフィルのみ実行して終了
+	if *backfillFrom != "" {
+		if err := runBackfill(rollupWorker, *backfillFrom, *backfillTo); err != nil {
+			logger.WithError(err).Fatal("Rollup backfill failed")
+		}
+		return
+	}
+
 	// Redis接続の初期化
-	redisClient, err := redis.NewConnection(redisHost, redisPort, redisPassword)
-	if err != nil {
+	redisClient := redis.NewCacheService(cfg.GetRedisAddr())
+	if err := redisClient.Connect(); err != nil {
 		logger.WithError(err).Fatal("Failed to connect to Redis")
 	}
 	defer redisClient.Close()
@@ -61,39 +86,40 @@ func main() {
 	// ワーカープロセスの開始
 	logger.Info("Starting worker processes...")
 
-	// データ処理ワーカー
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				logger.Info("Data processing worker stopped")
-				return
-			case <-ticker.C:
-				// データ処理ロジックをここに実装
-				logger.Debug("Processing data...")
-			}
-		}
-	}()
-
-	// クリーンアップワーカー
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				logger.Info("Cleanup worker stopped")
-				return
-			case <-ticker.C:
-				// クリーンアップロジックをここに実装
-				logger.Debug("Running cleanup...")
-			}
-		}
-	}()
+	// ロールアップワーカー
+	if cfg.Rollup.Enabled {
+		go rollupWorker.Run(ctx)
+	}
+
+	// パーティション管理（access_logs の将来のパーティションの作成と古いパーティションの切り離し）
+	if cfg.Partition.Enabled {
+		partitionManager := partition.NewManager(postgresqlRepos.NewPartitionRepository(db.GetDB()), partition.Config{
+			Interval:    cfg.GetPartitionInterval(),
+			Granularity: cfg.Partition.Granularity,
+			Premake:     cfg.Partition.Premake,
+			Retain:      cfg.Partition.Retain,
+			DropExpired: cfg.Partition.DropExpired,
+		}, logger)
+		go partitionManager.Run(ctx)
+	}
+
+	// クリーンアップワーカー（アプリケーションごとの保持期間を過ぎたデータを削除）
+	if cfg.Retention.Enabled {
+		retentionWorker := retention.NewWorker(postgresqlRepos.NewRetentionRepository(db.GetDB()), retention.Config{
+			Interval:         cfg.GetRetentionInterval(),
+			BatchSize:        cfg.Retention.BatchSize,
+			MaxBatchesPerRun: cfg.Retention.MaxBatchesPerRun,
+		}, logger)
+		go retentionWorker.Run(ctx)
+	}
+
+	// 使用量の突き合わせ（Redisの月間イベント数のカウンターをデータベースに保存）
+	if cfg.Usage.ReconcileEnabled {
+		usageWorker := usage.NewWorker(redis.NewUsageCounter(redisClient.GetClient()), postgresqlRepos.NewUsageRepository(db.GetDB()), usage.Config{
+			Interval: cfg.GetUsageReconcileInterval(),
+		}, logger)
+		go usageWorker.Run(ctx)
+	}
 
 	// グレースフルシャットダウンの設定
 	sigChan := make(chan os.Signal, 1)
@@ -108,9 +134,25 @@ func main() {
 	logger.Info("Worker stopped")
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// runBackfill は指定した日付範囲のロールアップを再集計します
+func runBackfill(worker *rollup.Worker, fromDate, toDate string) error {
+	from, err := time.Parse("2006-01-02", fromDate)
+	if err != nil {
+		return err
 	}
-	return defaultValue
+
+	to := time.Now().UTC()
+	if toDate != "" {
+		if to, err = time.Parse("2006-01-02", toDate); err != nil {
+			return err
+		}
+	}
+	// 終了日はその日の終わりまでを含める
+	to = rollup.Truncate(to, models.RollupGranularityDay).AddDate(0, 0, 1)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	_, err = worker.Backfill(ctx, from, to)
+	return err
 }
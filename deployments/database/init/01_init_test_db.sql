@@ -2,21 +2,65 @@
 -- 作成日: 2024年12月
 -- 説明: テスト環境用のデータベーススキーマ初期化
 
+-- 組織テーブル
+CREATE TABLE IF NOT EXISTS organizations (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ユーザーテーブル
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(255) PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 組織メンバーテーブル
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id VARCHAR(255) NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'admin', 'viewer')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
 -- アプリケーションテーブル
 CREATE TABLE IF NOT EXISTS applications (
     app_id VARCHAR(255) PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     description TEXT,
     domain VARCHAR(255) NOT NULL,
-    api_key VARCHAR(255) UNIQUE NOT NULL,
     is_active BOOLEAN DEFAULT true,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    settings JSONB NOT NULL DEFAULT '{}',
+    plan VARCHAR(32) NOT NULL DEFAULT 'free',
+    organization_id VARCHAR(255) REFERENCES organizations(id) ON DELETE RESTRICT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
--- アクセスログテーブル
-CREATE TABLE IF NOT EXISTS access_logs (
+-- APIキーテーブル（ソルト付きハッシュのみ保存）
+CREATE TABLE IF NOT EXISTS api_keys (
     id VARCHAR(255) PRIMARY KEY,
+    app_id VARCHAR(255) NOT NULL REFERENCES applications(app_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT ARRAY['ingest'],
+    signing_secret TEXT,
+    key_salt VARCHAR(64) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+-- アクセスログテーブル（timestamp による範囲パーティション）
+CREATE TABLE IF NOT EXISTS access_logs (
+    id VARCHAR(255) NOT NULL,
     app_id VARCHAR(255) NOT NULL,
     user_agent TEXT NOT NULL,
     url TEXT,
@@ -26,8 +70,12 @@ CREATE TABLE IF NOT EXISTS access_logs (
     timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
     custom_params JSONB,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id, timestamp),
     FOREIGN KEY (app_id) REFERENCES applications(app_id) ON DELETE CASCADE
-);
+) PARTITION BY RANGE (timestamp);
+
+-- どのパーティションにも含まれないデータの受け皿（範囲パーティションはテストで作成する）
+CREATE TABLE IF NOT EXISTS access_logs_default PARTITION OF access_logs DEFAULT;
 
 -- セッションテーブル
 CREATE TABLE IF NOT EXISTS sessions (
@@ -43,14 +91,61 @@ CREATE TABLE IF NOT EXISTS sessions (
     FOREIGN KEY (app_id) REFERENCES applications(app_id) ON DELETE CASCADE
 );
 
--- カスタムパラメータテーブル
+-- カスタムパラメータテーブル（access_logs の id は単独で一意でないため外部キーは設定しない）
 CREATE TABLE IF NOT EXISTS custom_parameters (
     id SERIAL PRIMARY KEY,
     access_log_id VARCHAR(255) NOT NULL,
     param_key VARCHAR(255) NOT NULL,
     param_value TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ロールアップテーブル
+CREATE TABLE IF NOT EXISTS access_log_rollups (
+    app_id VARCHAR(255) NOT NULL,
+    granularity VARCHAR(8) NOT NULL CHECK (granularity IN ('hour', 'day')),
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    dimension VARCHAR(32) NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    page_views BIGINT NOT NULL DEFAULT 0,
+    sessions BIGINT NOT NULL DEFAULT 0,
+    visitors BIGINT NOT NULL DEFAULT 0,
+    session_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    session_sketch BYTEA,
+    visitor_sketch BYTEA,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (app_id, dimension, granularity, bucket, value),
+    FOREIGN KEY (app_id) REFERENCES applications(app_id) ON DELETE CASCADE
+);
+
+-- ロールアップの集計済みの終端
+CREATE TABLE IF NOT EXISTS rollup_watermarks (
+    granularity VARCHAR(8) PRIMARY KEY,
+    processed_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- データ保持期間によるクリーンアップの実行結果
+CREATE TABLE IF NOT EXISTS retention_runs (
+    id BIGSERIAL PRIMARY KEY,
+    app_id VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    events_deleted BIGINT NOT NULL DEFAULT 0,
+    rollups_deleted BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (access_log_id) REFERENCES access_logs(id) ON DELETE CASCADE
+    FOREIGN KEY (app_id) REFERENCES applications(app_id) ON DELETE CASCADE
+);
+
+-- アプリケーションの月間の使用量
+CREATE TABLE IF NOT EXISTS usage_counters (
+    app_id VARCHAR(255) NOT NULL,
+    period DATE NOT NULL,
+    events BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (app_id, period),
+    FOREIGN KEY (app_id) REFERENCES applications(app_id) ON DELETE CASCADE
 );
 
 -- インデックスの作成
@@ -58,11 +153,16 @@ CREATE INDEX IF NOT EXISTS idx_access_logs_app_id ON access_logs(app_id);
 CREATE INDEX IF NOT EXISTS idx_access_logs_timestamp ON access_logs(timestamp);
 CREATE INDEX IF NOT EXISTS idx_access_logs_session_id ON access_logs(session_id);
 CREATE INDEX IF NOT EXISTS idx_access_logs_ip_address ON access_logs(ip_address);
-CREATE INDEX IF NOT EXISTS idx_applications_api_key ON applications(api_key);
+CREATE INDEX IF NOT EXISTS idx_api_keys_key_prefix ON api_keys(key_prefix);
+CREATE INDEX IF NOT EXISTS idx_api_keys_app_id ON api_keys(app_id);
 CREATE INDEX IF NOT EXISTS idx_applications_domain ON applications(domain);
+CREATE INDEX IF NOT EXISTS idx_applications_organization_id ON applications(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_retention_runs_app_id_started_at ON retention_runs(app_id, started_at);
 CREATE INDEX IF NOT EXISTS idx_sessions_app_id ON sessions(app_id);
 CREATE INDEX IF NOT EXISTS idx_sessions_session_id ON sessions(session_id);
 CREATE INDEX IF NOT EXISTS idx_custom_parameters_access_log_id ON custom_parameters(access_log_id);
+CREATE INDEX IF NOT EXISTS idx_access_log_rollups_granularity_bucket ON access_log_rollups(granularity, bucket);
 
 -- 統計情報用のビュー
 CREATE OR REPLACE VIEW access_log_stats AS
@@ -106,14 +206,26 @@ CREATE TRIGGER update_applications_updated_at
     EXECUTE FUNCTION update_updated_at_column();
 
 -- テスト用のサンプルデータを挿入
-INSERT INTO applications (app_id, name, description, domain, api_key, is_active) VALUES
-('test_app_001', 'Test Application 1', 'Test application for integration testing', 'test1.example.com', 'alt_test_api_key_001', true),
-('test_app_002', 'Test Application 2', 'Test application for integration testing', 'test2.example.com', 'alt_test_api_key_002', true),
-('test_app_inactive', 'Inactive Test Application', 'Inactive test application', 'inactive.example.com', 'alt_test_api_key_inactive', false)
+INSERT INTO applications (app_id, name, description, domain, is_active) VALUES
+('test_app_001', 'Test Application 1', 'Test application for integration testing', 'test1.example.com', true),
+('test_app_002', 'Test Application 2', 'Test application for integration testing', 'test2.example.com', true),
+('test_app_inactive', 'Inactive Test Application', 'Inactive test application', 'inactive.example.com', false)
 ON CONFLICT (app_id) DO NOTHING;
 
+-- テスト用のAPIキー（ソルト付きハッシュで保存）
+INSERT INTO api_keys (id, app_id, name, key_prefix, key_salt, key_hash)
+SELECT k.app_id || '_default', k.app_id, 'default', left(k.api_key, 8), k.salt,
+       encode(sha256(convert_to(k.salt || k.api_key, 'UTF8')), 'hex')
+FROM (VALUES
+    ('test_app_001', 'alt_test_api_key_001', 'test_salt_001'),
+    ('test_app_002', 'alt_test_api_key_002', 'test_salt_002'),
+    ('test_app_inactive', 'alt_test_api_key_inactive', 'test_salt_inactive')
+) AS k(app_id, api_key, salt)
+ON CONFLICT (id) DO NOTHING;
+
 -- コメントの追加
 COMMENT ON TABLE applications IS 'アプリケーション情報を管理するテーブル';
+COMMENT ON TABLE api_keys IS 'アプリケーションのAPIキー（ソルト付きハッシュのみ保存）';
 COMMENT ON TABLE access_logs IS 'アクセスログデータを保存するテーブル';
 COMMENT ON TABLE sessions IS 'セッション情報を管理するテーブル';
 COMMENT ON TABLE custom_parameters IS 'カスタムパラメータを保存するテーブル';
@@ -0,0 +1,11 @@
+-- 初期データベーススキーマのロールバック
+-- 説明: 001_initial_schema.up.sql で作成したオブジェクトを削除
+
+DROP VIEW IF EXISTS session_stats;
+DROP VIEW IF EXISTS tracking_stats;
+
+DROP TRIGGER IF EXISTS update_applications_updated_at ON applications;
+DROP FUNCTION IF EXISTS update_updated_at_column();
+
+DROP TABLE IF EXISTS tracking_data;
+DROP TABLE IF EXISTS applications;
@@ -77,6 +77,7 @@ BEGIN
 END;
 $$ language 'plpgsql';
 
+DROP TRIGGER IF EXISTS update_applications_updated_at ON applications;
 CREATE TRIGGER update_applications_updated_at 
     BEFORE UPDATE ON applications 
     FOR EACH ROW 
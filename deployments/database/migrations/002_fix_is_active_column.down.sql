@@ -0,0 +1,4 @@
+-- データベーススキーマの不整合修正のロールバック
+-- 説明: 001_initial_schema.up.sql は最初から is_active を作成するため、カラム名は戻さない
+
+COMMENT ON COLUMN applications.is_active IS NULL;
@@ -0,0 +1,18 @@
+-- データベーススキーマの不整合修正
+-- 作成日: 2025年8月18日
+-- 説明: applicationsテーブルのactiveカラムをis_activeに変更
+
+-- activeカラムをis_activeに変更（既にis_activeの場合は何もしない）
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'applications' AND column_name = 'active'
+    ) THEN
+        ALTER TABLE applications RENAME COLUMN active TO is_active;
+    END IF;
+END
+$$;
+
+-- コメントの更新
+COMMENT ON COLUMN applications.is_active IS 'アプリケーションのアクティブ状態';
@@ -0,0 +1,39 @@
+-- スキーマの整合のロールバック
+-- 説明: access_logs を tracking_data に戻し、旧ビューを再作成
+
+DROP VIEW IF EXISTS access_log_stats;
+DROP VIEW IF EXISTS session_stats;
+
+ALTER TABLE access_logs RENAME TO tracking_data;
+ALTER INDEX IF EXISTS idx_access_logs_app_id RENAME TO idx_tracking_data_app_id;
+ALTER INDEX IF EXISTS idx_access_logs_timestamp RENAME TO idx_tracking_data_timestamp;
+ALTER INDEX IF EXISTS idx_access_logs_session_id RENAME TO idx_tracking_data_session_id;
+ALTER INDEX IF EXISTS idx_access_logs_ip_address RENAME TO idx_tracking_data_ip_address;
+ALTER INDEX IF EXISTS idx_access_logs_app_timestamp RENAME TO idx_tracking_data_app_timestamp;
+
+CREATE OR REPLACE VIEW tracking_stats AS
+SELECT 
+    app_id,
+    COUNT(*) as total_requests,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    COUNT(DISTINCT ip_address) as unique_ips,
+    COUNT(CASE WHEN user_agent ILIKE '%bot%' OR user_agent ILIKE '%crawler%' THEN 1 END) as bot_requests,
+    COUNT(CASE WHEN user_agent ILIKE '%mobile%' OR user_agent ILIKE '%android%' OR user_agent ILIKE '%iphone%' THEN 1 END) as mobile_requests,
+    MIN(timestamp) as first_request,
+    MAX(timestamp) as last_request
+FROM tracking_data
+GROUP BY app_id;
+
+CREATE OR REPLACE VIEW session_stats AS
+SELECT 
+    app_id,
+    session_id,
+    COUNT(*) as page_views,
+    MIN(timestamp) as session_start,
+    MAX(timestamp) as session_end,
+    EXTRACT(EPOCH FROM (MAX(timestamp) - MIN(timestamp))) as session_duration_seconds
+FROM tracking_data
+WHERE session_id IS NOT NULL
+GROUP BY app_id, session_id;
+
+ALTER TABLE applications DROP COLUMN IF EXISTS description;
@@ -0,0 +1,86 @@
+-- スキーマの整合
+-- 作成日: 2026年10月
+-- 説明: リポジトリが使用するスキーマ（init/01_init_test_db.sql と同じ構成）に揃える
+--       applications.description の追加と、tracking_data から access_logs への移行
+
+-- applicationsテーブルに説明カラムを追加
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS description TEXT;
+
+-- tracking_data を参照する旧ビューを削除
+DROP VIEW IF EXISTS tracking_stats;
+DROP VIEW IF EXISTS session_stats;
+
+-- tracking_data を access_logs に移行
+DO $$
+BEGIN
+    IF to_regclass('tracking_data') IS NOT NULL AND to_regclass('access_logs') IS NULL THEN
+        ALTER TABLE tracking_data RENAME TO access_logs;
+        ALTER INDEX IF EXISTS idx_tracking_data_app_id RENAME TO idx_access_logs_app_id;
+        ALTER INDEX IF EXISTS idx_tracking_data_timestamp RENAME TO idx_access_logs_timestamp;
+        ALTER INDEX IF EXISTS idx_tracking_data_session_id RENAME TO idx_access_logs_session_id;
+        ALTER INDEX IF EXISTS idx_tracking_data_ip_address RENAME TO idx_access_logs_ip_address;
+        ALTER INDEX IF EXISTS idx_tracking_data_app_timestamp RENAME TO idx_access_logs_app_timestamp;
+    ELSIF to_regclass('tracking_data') IS NOT NULL THEN
+        -- 両方存在する場合は tracking_data のデータを access_logs に取り込んでから削除
+        INSERT INTO access_logs (id, app_id, user_agent, url, ip_address, session_id, referrer, timestamp, custom_params, created_at)
+        SELECT id, app_id, user_agent, url, ip_address, session_id, referrer, timestamp, custom_params, created_at
+        FROM tracking_data
+        ON CONFLICT (id) DO NOTHING;
+        DROP TABLE tracking_data;
+    END IF;
+END
+$$;
+
+-- アクセスログテーブル（新規環境用）
+CREATE TABLE IF NOT EXISTS access_logs (
+    id VARCHAR(255) PRIMARY KEY,
+    app_id VARCHAR(255) NOT NULL,
+    user_agent TEXT NOT NULL,
+    url TEXT,
+    ip_address INET,
+    session_id VARCHAR(255),
+    referrer TEXT,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    custom_params JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (app_id) REFERENCES applications(app_id) ON DELETE CASCADE
+);
+
+-- インデックスの作成
+CREATE INDEX IF NOT EXISTS idx_access_logs_app_id ON access_logs(app_id);
+CREATE INDEX IF NOT EXISTS idx_access_logs_timestamp ON access_logs(timestamp);
+CREATE INDEX IF NOT EXISTS idx_access_logs_session_id ON access_logs(session_id);
+CREATE INDEX IF NOT EXISTS idx_access_logs_ip_address ON access_logs(ip_address);
+CREATE INDEX IF NOT EXISTS idx_access_logs_app_timestamp ON access_logs(app_id, timestamp);
+
+-- 統計情報用のビュー
+CREATE OR REPLACE VIEW access_log_stats AS
+SELECT 
+    app_id,
+    COUNT(*) as total_requests,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    COUNT(DISTINCT ip_address) as unique_visitors,
+    COUNT(CASE WHEN user_agent ILIKE '%bot%' OR user_agent ILIKE '%crawler%' THEN 1 END) as bot_requests,
+    COUNT(CASE WHEN user_agent ILIKE '%mobile%' OR user_agent ILIKE '%android%' OR user_agent ILIKE '%iphone%' THEN 1 END) as mobile_requests,
+    MIN(timestamp) as first_request,
+    MAX(timestamp) as last_request
+FROM access_logs
+GROUP BY app_id;
+
+-- セッション統計用のビュー
+CREATE OR REPLACE VIEW session_stats AS
+SELECT 
+    app_id,
+    session_id,
+    COUNT(*) as page_views,
+    MIN(timestamp) as session_start,
+    MAX(timestamp) as session_end,
+    EXTRACT(EPOCH FROM (MAX(timestamp) - MIN(timestamp))) as session_duration_seconds
+FROM access_logs
+WHERE session_id IS NOT NULL
+GROUP BY app_id, session_id;
+
+-- コメントの追加
+COMMENT ON TABLE access_logs IS 'アクセスログデータを保存するテーブル';
+COMMENT ON VIEW access_log_stats IS 'アクセスログ統計情報を提供するビュー';
+COMMENT ON VIEW session_stats IS 'セッション統計情報を提供するビュー';
@@ -0,0 +1,3 @@
+-- アプリケーションのタイムゾーンの削除
+
+ALTER TABLE applications DROP COLUMN IF EXISTS timezone;
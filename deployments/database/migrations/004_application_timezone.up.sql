@@ -0,0 +1,6 @@
+-- アプリケーションのタイムゾーン
+-- 説明: 時系列統計の集計単位（日・週・月など）を決めるIANAタイムゾーン名
+
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+COMMENT ON COLUMN applications.timezone IS '統計の集計に使うIANAタイムゾーン名';
@@ -0,0 +1,4 @@
+-- 事前集計（ロールアップ）テーブルの削除
+
+DROP TABLE IF EXISTS rollup_watermarks;
+DROP TABLE IF EXISTS access_log_rollups;
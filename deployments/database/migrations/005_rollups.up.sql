@@ -0,0 +1,32 @@
+-- 事前集計（ロールアップ）テーブル
+-- 説明: ワーカーが access_logs からアプリケーションごとに時間別・日別の集計を作成する
+--       統計APIは確定済みの範囲をこのテーブルから読み込む
+
+CREATE TABLE IF NOT EXISTS access_log_rollups (
+    app_id VARCHAR(255) NOT NULL REFERENCES applications(app_id) ON DELETE CASCADE,
+    granularity VARCHAR(8) NOT NULL CHECK (granularity IN ('hour', 'day')),
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    dimension VARCHAR(32) NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    page_views BIGINT NOT NULL DEFAULT 0,
+    sessions BIGINT NOT NULL DEFAULT 0,
+    visitors BIGINT NOT NULL DEFAULT 0,
+    session_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (app_id, dimension, granularity, bucket, value)
+);
+
+-- バケット単位の置き換えに使うインデックス
+CREATE INDEX IF NOT EXISTS idx_access_log_rollups_granularity_bucket ON access_log_rollups(granularity, bucket);
+
+-- 集計済みの終端（ウォーターマーク）
+CREATE TABLE IF NOT EXISTS rollup_watermarks (
+    granularity VARCHAR(8) PRIMARY KEY,
+    processed_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE access_log_rollups IS 'アクセスログの時間別・日別の事前集計';
+COMMENT ON COLUMN access_log_rollups.dimension IS '集計軸（total, url, referrer_host, device_type, browser, os）';
+COMMENT ON COLUMN access_log_rollups.session_seconds IS 'バケット内のセッション継続時間の合計（total 軸のみ）';
+COMMENT ON TABLE rollup_watermarks IS '集計粒度ごとの集計済みの終端';
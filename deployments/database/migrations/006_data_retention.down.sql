@@ -0,0 +1,4 @@
+-- アプリケーション設定とデータ保持期間の削除
+
+DROP TABLE IF EXISTS retention_runs;
+ALTER TABLE applications DROP COLUMN IF EXISTS settings;
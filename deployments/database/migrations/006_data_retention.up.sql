@@ -0,0 +1,24 @@
+-- アプリケーション設定とデータ保持期間
+-- 説明: アプリケーションごとの設定（保持期間など）をJSONBで保持する
+--       クリーンアップワーカーは保持期間を過ぎたデータを削除し、実行結果を retention_runs に記録する
+
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS retention_runs (
+    id BIGSERIAL PRIMARY KEY,
+    app_id VARCHAR(255) NOT NULL REFERENCES applications(app_id) ON DELETE CASCADE,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    events_deleted BIGINT NOT NULL DEFAULT 0,
+    rollups_deleted BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- アプリケーションごとの実行履歴の参照に使うインデックス
+CREATE INDEX IF NOT EXISTS idx_retention_runs_app_id_started_at ON retention_runs(app_id, started_at);
+
+COMMENT ON COLUMN applications.settings IS 'アプリケーション設定（retention_raw_days, retention_rollup_days など）';
+COMMENT ON TABLE retention_runs IS 'データ保持期間によるクリーンアップの実行結果';
+COMMENT ON COLUMN retention_runs.events_deleted IS '削除したトラッキングデータの件数';
+COMMENT ON COLUMN retention_runs.rollups_deleted IS '削除したロールアップの件数';
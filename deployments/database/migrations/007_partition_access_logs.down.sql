@@ -0,0 +1,76 @@
+-- access_logs のパーティション化の取り消し
+-- 説明: 全パーティション（デフォルトを含む）のデータを通常のテーブルに戻す
+--       切り離し済み（DETACH）のパーティションのデータは戻らない
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_class WHERE oid = to_regclass('access_logs') AND relkind = 'p') THEN
+        RETURN;
+    END IF;
+
+    DROP VIEW IF EXISTS access_log_stats;
+    DROP VIEW IF EXISTS session_stats;
+
+    ALTER TABLE access_logs RENAME TO access_logs_partitioned;
+    DROP INDEX IF EXISTS idx_access_logs_app_id;
+    DROP INDEX IF EXISTS idx_access_logs_timestamp;
+    DROP INDEX IF EXISTS idx_access_logs_session_id;
+    DROP INDEX IF EXISTS idx_access_logs_ip_address;
+    DROP INDEX IF EXISTS idx_access_logs_app_timestamp;
+
+    CREATE TABLE access_logs (
+        id VARCHAR(255) PRIMARY KEY,
+        app_id VARCHAR(255) NOT NULL,
+        user_agent TEXT NOT NULL,
+        url TEXT,
+        ip_address INET,
+        session_id VARCHAR(255),
+        referrer TEXT,
+        timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+        custom_params JSONB,
+        created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+        FOREIGN KEY (app_id) REFERENCES applications(app_id) ON DELETE CASCADE
+    );
+
+    INSERT INTO access_logs (id, app_id, user_agent, url, ip_address, session_id, referrer, timestamp, custom_params, created_at)
+    SELECT id, app_id, user_agent, url, ip_address, session_id, referrer, timestamp, custom_params, created_at
+    FROM access_logs_partitioned
+    ON CONFLICT (id) DO NOTHING;
+
+    -- パーティションも合わせて削除される
+    DROP TABLE access_logs_partitioned;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_access_logs_app_id ON access_logs(app_id);
+CREATE INDEX IF NOT EXISTS idx_access_logs_timestamp ON access_logs(timestamp);
+CREATE INDEX IF NOT EXISTS idx_access_logs_session_id ON access_logs(session_id);
+CREATE INDEX IF NOT EXISTS idx_access_logs_ip_address ON access_logs(ip_address);
+CREATE INDEX IF NOT EXISTS idx_access_logs_app_timestamp ON access_logs(app_id, timestamp);
+
+CREATE OR REPLACE VIEW access_log_stats AS
+SELECT
+    app_id,
+    COUNT(*) as total_requests,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    COUNT(DISTINCT ip_address) as unique_visitors,
+    COUNT(CASE WHEN user_agent ILIKE '%bot%' OR user_agent ILIKE '%crawler%' THEN 1 END) as bot_requests,
+    COUNT(CASE WHEN user_agent ILIKE '%mobile%' OR user_agent ILIKE '%android%' OR user_agent ILIKE '%iphone%' THEN 1 END) as mobile_requests,
+    MIN(timestamp) as first_request,
+    MAX(timestamp) as last_request
+FROM access_logs
+GROUP BY app_id;
+
+CREATE OR REPLACE VIEW session_stats AS
+SELECT
+    app_id,
+    session_id,
+    COUNT(*) as page_views,
+    MIN(timestamp) as session_start,
+    MAX(timestamp) as session_end,
+    EXTRACT(EPOCH FROM (MAX(timestamp) - MIN(timestamp))) as session_duration_seconds
+FROM access_logs
+WHERE session_id IS NOT NULL
+GROUP BY app_id, session_id;
+
+COMMENT ON TABLE access_logs IS 'アクセスログデータを保存するテーブル';
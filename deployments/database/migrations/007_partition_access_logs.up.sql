@@ -0,0 +1,116 @@
+-- access_logs の範囲パーティション化
+-- 説明: access_logs を timestamp による宣言的範囲パーティションテーブルに変換する
+--       既存データは月単位のパーティションに移行し、範囲外のデータはデフォルトパーティションに入る
+--       以降のパーティションの作成・切り離しはワーカーが行う（PARTITION_* 設定）
+
+DO $$
+DECLARE
+    pk_name TEXT;
+    month_start TIMESTAMP;
+BEGIN
+    -- 既にパーティション化されている場合は何もしない
+    IF EXISTS (SELECT 1 FROM pg_class WHERE oid = to_regclass('access_logs') AND relkind = 'p') THEN
+        RETURN;
+    END IF;
+
+    -- access_logs を参照するビューを削除（後で再作成）
+    DROP VIEW IF EXISTS access_log_stats;
+    DROP VIEW IF EXISTS session_stats;
+
+    -- 既存テーブルを退避し、インデックス名の衝突を避けるため主キー・インデックスを削除
+    ALTER TABLE access_logs RENAME TO access_logs_legacy;
+    SELECT conname INTO pk_name FROM pg_constraint
+    WHERE conrelid = 'access_logs_legacy'::regclass AND contype = 'p';
+    IF pk_name IS NOT NULL THEN
+        -- パーティションテーブルの id は単独で一意にならないため、id を参照する外部キーも削除する
+        EXECUTE format('ALTER TABLE access_logs_legacy DROP CONSTRAINT %I CASCADE', pk_name);
+    END IF;
+    DROP INDEX IF EXISTS idx_access_logs_app_id;
+    DROP INDEX IF EXISTS idx_access_logs_timestamp;
+    DROP INDEX IF EXISTS idx_access_logs_session_id;
+    DROP INDEX IF EXISTS idx_access_logs_ip_address;
+    DROP INDEX IF EXISTS idx_access_logs_app_timestamp;
+
+    -- パーティションキーを含む主キーで作成
+    CREATE TABLE access_logs (
+        id VARCHAR(255) NOT NULL,
+        app_id VARCHAR(255) NOT NULL,
+        user_agent TEXT NOT NULL,
+        url TEXT,
+        ip_address INET,
+        session_id VARCHAR(255),
+        referrer TEXT,
+        timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+        custom_params JSONB,
+        created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (id, timestamp),
+        FOREIGN KEY (app_id) REFERENCES applications(app_id) ON DELETE CASCADE
+    ) PARTITION BY RANGE (timestamp);
+
+    -- どのパーティションにも含まれないデータの受け皿
+    CREATE TABLE access_logs_default PARTITION OF access_logs DEFAULT;
+
+    -- 既存データのある月と、当月から3か月先までの月単位のパーティション（UTC）
+    FOR month_start IN
+        SELECT DISTINCT date_trunc('month', timestamp AT TIME ZONE 'UTC') FROM access_logs_legacy
+        UNION
+        SELECT generate_series(
+            date_trunc('month', now() AT TIME ZONE 'UTC'),
+            date_trunc('month', now() AT TIME ZONE 'UTC') + interval '3 months',
+            interval '1 month'
+        )
+    LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF access_logs FOR VALUES FROM (%L) TO (%L)',
+            'access_logs_p' || to_char(month_start, 'YYYYMM'),
+            month_start AT TIME ZONE 'UTC',
+            (month_start + interval '1 month') AT TIME ZONE 'UTC'
+        );
+    END LOOP;
+
+    -- 既存データの移行
+    INSERT INTO access_logs (id, app_id, user_agent, url, ip_address, session_id, referrer, timestamp, custom_params, created_at)
+    SELECT id, app_id, user_agent, url, ip_address, session_id, referrer, timestamp, custom_params, created_at
+    FROM access_logs_legacy;
+
+    DROP TABLE access_logs_legacy;
+END
+$$;
+
+-- インデックスの作成（各パーティションにも作成される）
+CREATE INDEX IF NOT EXISTS idx_access_logs_app_id ON access_logs(app_id);
+CREATE INDEX IF NOT EXISTS idx_access_logs_timestamp ON access_logs(timestamp);
+CREATE INDEX IF NOT EXISTS idx_access_logs_session_id ON access_logs(session_id);
+CREATE INDEX IF NOT EXISTS idx_access_logs_ip_address ON access_logs(ip_address);
+CREATE INDEX IF NOT EXISTS idx_access_logs_app_timestamp ON access_logs(app_id, timestamp);
+
+-- 統計情報用のビュー
+CREATE OR REPLACE VIEW access_log_stats AS
+SELECT
+    app_id,
+    COUNT(*) as total_requests,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    COUNT(DISTINCT ip_address) as unique_visitors,
+    COUNT(CASE WHEN user_agent ILIKE '%bot%' OR user_agent ILIKE '%crawler%' THEN 1 END) as bot_requests,
+    COUNT(CASE WHEN user_agent ILIKE '%mobile%' OR user_agent ILIKE '%android%' OR user_agent ILIKE '%iphone%' THEN 1 END) as mobile_requests,
+    MIN(timestamp) as first_request,
+    MAX(timestamp) as last_request
+FROM access_logs
+GROUP BY app_id;
+
+-- セッション統計用のビュー
+CREATE OR REPLACE VIEW session_stats AS
+SELECT
+    app_id,
+    session_id,
+    COUNT(*) as page_views,
+    MIN(timestamp) as session_start,
+    MAX(timestamp) as session_end,
+    EXTRACT(EPOCH FROM (MAX(timestamp) - MIN(timestamp))) as session_duration_seconds
+FROM access_logs
+WHERE session_id IS NOT NULL
+GROUP BY app_id, session_id;
+
+COMMENT ON TABLE access_logs IS 'アクセスログデータを保存するテーブル（timestamp による範囲パーティション）';
+COMMENT ON VIEW access_log_stats IS 'アクセスログ統計情報を提供するビュー';
+COMMENT ON VIEW session_stats IS 'セッション統計情報を提供するビュー';
@@ -0,0 +1,7 @@
+-- 組織・ユーザーとアプリケーションの所有者の削除
+
+DROP INDEX IF EXISTS idx_applications_organization_id;
+ALTER TABLE applications DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS organizations;
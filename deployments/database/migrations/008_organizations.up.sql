@@ -0,0 +1,38 @@
+-- 組織・ユーザーとアプリケーションの所有者
+-- 説明: アプリケーションを組織（クライアント）に所属させ、組織のメンバーのロールで管理APIの操作を制限する
+--       organization_id が NULL のアプリケーションはスーパーユーザー（マスター管理トークン・管理者JWT）のみ管理できる
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(255) PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id VARCHAR(255) NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'admin', 'viewer')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+-- ユーザーの所属組織の参照に使うインデックス
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+-- 組織にアプリケーションが残っている間は組織を削除できない
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS organization_id VARCHAR(255) REFERENCES organizations(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_applications_organization_id ON applications(organization_id);
+
+COMMENT ON TABLE organizations IS 'アプリケーションを所有する組織（クライアント）';
+COMMENT ON TABLE users IS '管理APIのユーザー（JWTの sub がユーザーID）';
+COMMENT ON TABLE organization_members IS '組織のメンバーとロール（owner, admin, viewer）';
+COMMENT ON COLUMN applications.organization_id IS 'アプリケーションを所有する組織（NULLの場合はスーパーユーザーのみ管理できる）';
@@ -0,0 +1,6 @@
+-- APIキーのハッシュ化と複数キーの削除
+-- 注意: ハッシュから平文のキーは復元できないため、ロールバック後はすべてのアプリケーションでキーの再発行が必要
+
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS api_key VARCHAR(255) UNIQUE;
+CREATE INDEX IF NOT EXISTS idx_applications_api_key ON applications(api_key);
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,34 @@
+-- APIキーのハッシュ化と複数キー
+-- 説明: APIキーを平文ではなくソルト付きSHA-256ハッシュとして保存し、アプリケーションごとに名前付きの複数のキーを持てるようにする
+--       認証時は先頭8文字のプレフィックスで候補を絞り込み、ハッシュを照合する
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(255) PRIMARY KEY,
+    app_id VARCHAR(255) NOT NULL REFERENCES applications(app_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_salt VARCHAR(64) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_key_prefix ON api_keys(key_prefix);
+CREATE INDEX IF NOT EXISTS idx_api_keys_app_id ON api_keys(app_id);
+
+-- 既存の平文キーを "default" という名前のキーとして移行（行ごとに別のソルトを使う）
+INSERT INTO api_keys (id, app_id, name, key_prefix, key_salt, key_hash, created_at)
+SELECT gen_random_uuid()::text, a.app_id, 'default', left(a.api_key, 8), s.salt,
+       encode(sha256(convert_to(s.salt || a.api_key, 'UTF8')), 'hex'), a.created_at
+FROM applications a,
+     LATERAL (SELECT md5(random()::text || clock_timestamp()::text || a.app_id) AS salt) s
+WHERE a.api_key IS NOT NULL AND a.api_key <> '';
+
+-- 平文のキーは保持しない
+DROP INDEX IF EXISTS idx_applications_api_key;
+ALTER TABLE applications DROP COLUMN IF EXISTS api_key;
+
+COMMENT ON TABLE api_keys IS 'アプリケーションのAPIキー（ソルト付きハッシュのみ保存）';
+COMMENT ON COLUMN api_keys.key_prefix IS '認証時の候補の絞り込みに使うキーの先頭8文字';
+COMMENT ON COLUMN api_keys.expires_at IS 'キーの有効期限（NULLの場合は無期限）';
@@ -0,0 +1,4 @@
+-- APIキーのスコープの削除
+-- 注意: ロールバック後はすべてのキーで送信と統計の参照ができるようになる
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS scopes;
@@ -0,0 +1,7 @@
+-- APIキーのスコープ
+-- 説明: APIキーごとに許可する操作（ingest: トラッキングデータの送信、stats:read: 統計の参照、apps:admin: アプリケーションの管理）を保存する
+--       既存のキーは従来どおり送信と統計の参照を許可する
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT ARRAY['ingest', 'stats:read'];
+
+COMMENT ON COLUMN api_keys.scopes IS 'キーに許可する操作（ingest, stats:read, apps:admin）';
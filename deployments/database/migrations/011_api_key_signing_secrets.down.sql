@@ -0,0 +1,4 @@
+-- APIキーの署名シークレットの削除
+-- 注意: ロールバック後は署名なしのリクエストも受け付けるようになる
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS signing_secret;
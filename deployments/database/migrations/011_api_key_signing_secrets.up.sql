@@ -0,0 +1,8 @@
+-- APIキーの署名シークレット
+-- 説明: サーバー間連携用のキーに、リクエストのHMAC-SHA256署名を検証するためのシークレットを保存する
+--       署名の検証には平文が必要なため、キー本体と異なりハッシュ化しない
+--       シークレットを持つキーで認証するリクエストには署名が必須になる
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS signing_secret TEXT;
+
+COMMENT ON COLUMN api_keys.signing_secret IS 'リクエスト署名（HMAC-SHA256）のシークレット（NULLの場合は署名不要）';
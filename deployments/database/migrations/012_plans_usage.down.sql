@@ -0,0 +1,5 @@
+-- アプリケーションのプランと月間の使用量の削除
+-- 注意: 保存済みの使用量は失われる
+
+DROP TABLE IF EXISTS usage_counters;
+ALTER TABLE applications DROP COLUMN IF EXISTS plan;
@@ -0,0 +1,19 @@
+-- アプリケーションのプランと月間の使用量
+-- 説明: アプリケーションごとのプラン（free / pro / enterprise）で、レート制限と月間のイベント数の上限を決める
+--       イベント数はRedisのカウンターで数え、ワーカーが usage_counters と突き合わせて保存する
+--       既存のアプリケーションは free プランになる
+
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS plan VARCHAR(32) NOT NULL DEFAULT 'free';
+
+CREATE TABLE IF NOT EXISTS usage_counters (
+    app_id VARCHAR(255) NOT NULL REFERENCES applications(app_id) ON DELETE CASCADE,
+    period DATE NOT NULL,
+    events BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (app_id, period)
+);
+
+COMMENT ON COLUMN applications.plan IS 'プラン（free, pro, enterprise）';
+COMMENT ON TABLE usage_counters IS 'アプリケーションの月間の使用量';
+COMMENT ON COLUMN usage_counters.period IS '集計期間（UTCの月の初日）';
+COMMENT ON COLUMN usage_counters.events IS '受け付けたイベント数';
@@ -0,0 +1,9 @@
+-- ユーザーエージェントの解析結果の削除
+-- 注意: 保存済みの解析結果は失われる（ユーザーエージェントから再解析できる）
+
+ALTER TABLE access_logs DROP COLUMN IF EXISTS device_type;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS device_family;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS os_version;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS os;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS browser_version;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS browser;
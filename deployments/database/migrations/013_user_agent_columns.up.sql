@@ -0,0 +1,17 @@
+-- ユーザーエージェントの解析結果
+-- 説明: インジェスト時に uaparser で解析したブラウザ・OS・デバイスを保存し、統計でグループ化できるようにする
+--       既存の行は NULL のまま（ロールアップの集計時にユーザーエージェントから解析する）
+
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS browser VARCHAR(64);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS browser_version VARCHAR(32);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS os VARCHAR(64);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS os_version VARCHAR(32);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS device_family VARCHAR(64);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS device_type VARCHAR(16);
+
+COMMENT ON COLUMN access_logs.browser IS 'ブラウザのファミリー（Chrome, Safari, Samsung Internet など）';
+COMMENT ON COLUMN access_logs.browser_version IS 'ブラウザのバージョン';
+COMMENT ON COLUMN access_logs.os IS 'OSのファミリー（Windows, macOS, iOS, Android など）';
+COMMENT ON COLUMN access_logs.os_version IS 'OSのバージョン';
+COMMENT ON COLUMN access_logs.device_family IS 'デバイスのファミリー（iPhone, iPad, Generic Smartphone など）';
+COMMENT ON COLUMN access_logs.device_type IS 'デバイスタイプ（desktop, mobile, tablet, bot）';
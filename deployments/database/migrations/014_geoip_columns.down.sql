@@ -0,0 +1,7 @@
+-- IPアドレスから判定した位置情報の削除
+-- 注意: 保存済みの位置情報は失われる（IPアドレスは匿名化済みのため再判定できない）
+
+ALTER TABLE access_logs DROP COLUMN IF EXISTS asn;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS city;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS region;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS country;
@@ -0,0 +1,14 @@
+-- IPアドレスから判定した位置情報
+-- 説明: インジェスト時に匿名化の前のIPアドレスでローカルのGeoIPデータベース（GeoLite2互換）から判定した
+--       粗い位置情報（国・地域・都市・ASN）を保存し、統計を国でグループ化できるようにする
+--       IPアドレス自体は従来どおり匿名化して保存する。既存の行は NULL のまま
+
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS country VARCHAR(2);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS region VARCHAR(16);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS city VARCHAR(128);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS asn BIGINT;
+
+COMMENT ON COLUMN access_logs.country IS '国（ISO 3166-1 alpha-2）';
+COMMENT ON COLUMN access_logs.region IS '地域（ISO 3166-2、例: JP-13）';
+COMMENT ON COLUMN access_logs.city IS '都市名（英語）';
+COMMENT ON COLUMN access_logs.asn IS '自律システム番号';
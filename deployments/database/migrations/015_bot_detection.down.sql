@@ -0,0 +1,18 @@
+-- ボットの判定結果の削除
+-- 注意: 保存済みのスコアと理由は失われる（ボット数はユーザーエージェントのキーワードでの判定に戻る）
+
+CREATE OR REPLACE VIEW access_log_stats AS
+SELECT
+    app_id,
+    COUNT(*) as total_requests,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    COUNT(DISTINCT ip_address) as unique_visitors,
+    COUNT(CASE WHEN user_agent ILIKE '%bot%' OR user_agent ILIKE '%crawler%' THEN 1 END) as bot_requests,
+    COUNT(CASE WHEN user_agent ILIKE '%mobile%' OR user_agent ILIKE '%android%' OR user_agent ILIKE '%iphone%' THEN 1 END) as mobile_requests,
+    MIN(timestamp) as first_request,
+    MAX(timestamp) as last_request
+FROM access_logs
+GROUP BY app_id;
+
+ALTER TABLE access_logs DROP COLUMN IF EXISTS bot_reason;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS bot_score;
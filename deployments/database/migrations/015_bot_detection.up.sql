@@ -0,0 +1,28 @@
+-- ボットの判定結果
+-- 説明: インジェスト時に botdetect で判定したスコア（0〜100）と理由（一致したシグナルのカンマ区切り）を保存する
+--       統計のボット数はスコアが 50（botdetect.BotThreshold）以上のイベント数とし、ユーザーエージェントのキーワードでの判定をやめる
+--       既存の行は従来のキーワードで判定した結果を引き継ぐ
+
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS bot_score SMALLINT;
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS bot_reason VARCHAR(64);
+
+UPDATE access_logs
+SET bot_score = 100, bot_reason = 'user_agent'
+WHERE bot_score IS NULL AND (user_agent ILIKE '%bot%' OR user_agent ILIKE '%crawler%');
+
+COMMENT ON COLUMN access_logs.bot_score IS 'ボットのスコア（0〜100、50以上をボットとして集計）';
+COMMENT ON COLUMN access_logs.bot_reason IS 'ボットの判定理由（user_agent, headless, datacenter, rate のカンマ区切り）';
+
+-- 統計情報用のビュー（ボット数を判定結果で集計する）
+CREATE OR REPLACE VIEW access_log_stats AS
+SELECT
+    app_id,
+    COUNT(*) as total_requests,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    COUNT(DISTINCT ip_address) as unique_visitors,
+    COUNT(CASE WHEN bot_score >= 50 THEN 1 END) as bot_requests,
+    COUNT(CASE WHEN user_agent ILIKE '%mobile%' OR user_agent ILIKE '%android%' OR user_agent ILIKE '%iphone%' THEN 1 END) as mobile_requests,
+    MIN(timestamp) as first_request,
+    MAX(timestamp) as last_request
+FROM access_logs
+GROUP BY app_id;
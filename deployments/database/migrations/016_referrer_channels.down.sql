@@ -0,0 +1,12 @@
+-- 流入元のホスト・チャネルとキャンペーンのパラメータの削除
+-- 注意: 保存済みの判定結果は失われる（リファラーとページURLは残るため再判定できる）
+
+ALTER TABLE access_logs DROP COLUMN IF EXISTS fbclid;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS gclid;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS utm_content;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS utm_term;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS utm_campaign;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS utm_medium;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS utm_source;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS channel;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS referrer_host;
@@ -0,0 +1,25 @@
+-- 流入元のホスト・チャネルとキャンペーンのパラメータ
+-- 説明: インジェスト時にリファラーを組み込みの流入元の一覧（referer-parser 形式）と照合して
+--       ホストとチャネル（direct, organic_search, social, email, paid, internal, other）を判定し、
+--       ページURLのクエリの utm_* ・gclid・fbclid を列として保存して、統計をチャネル・キャンペーンでグループ化できるようにする
+--       既存の行は NULL のまま（ロールアップの集計時にリファラーとページURLから判定する）
+
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS referrer_host VARCHAR(255);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS channel VARCHAR(16);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS utm_source VARCHAR(255);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS utm_medium VARCHAR(255);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS utm_campaign VARCHAR(255);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS utm_term VARCHAR(255);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS utm_content VARCHAR(255);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS gclid VARCHAR(255);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS fbclid VARCHAR(255);
+
+COMMENT ON COLUMN access_logs.referrer_host IS 'リファラーのホスト（小文字）';
+COMMENT ON COLUMN access_logs.channel IS 'チャネル（direct, organic_search, social, email, paid, internal, other）';
+COMMENT ON COLUMN access_logs.utm_source IS 'ページURLの utm_source';
+COMMENT ON COLUMN access_logs.utm_medium IS 'ページURLの utm_medium';
+COMMENT ON COLUMN access_logs.utm_campaign IS 'ページURLの utm_campaign';
+COMMENT ON COLUMN access_logs.utm_term IS 'ページURLの utm_term';
+COMMENT ON COLUMN access_logs.utm_content IS 'ページURLの utm_content';
+COMMENT ON COLUMN access_logs.gclid IS 'ページURLの gclid（Google 広告のクリックID）';
+COMMENT ON COLUMN access_logs.fbclid IS 'ページURLの fbclid（Meta のクリックID）';
@@ -0,0 +1,5 @@
+-- 正規化したページURLとページのグループの削除
+-- 注意: 保存済みの正規化の結果は失われる（元の url は残る）
+
+ALTER TABLE access_logs DROP COLUMN IF EXISTS page_group;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS page_path;
@@ -0,0 +1,11 @@
+-- 正規化したページURLとページのグループ
+-- 説明: インジェスト時にアプリケーションの url_rules 設定（クエリパラメータの除去・フラグメントの除去・
+--       ホストの小文字化・末尾のスラッシュ）でページURLを正規化し、"/product/:id" のようなパスのパターンで
+--       グループ化した結果を元の url と並べて保存する。統計のページは正規化したページURLで集計する
+--       既存の行は NULL のまま（統計・ロールアップでは元の url で集計する）
+
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS page_path TEXT;
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS page_group TEXT;
+
+COMMENT ON COLUMN access_logs.page_path IS 'url_rules で正規化したページURL';
+COMMENT ON COLUMN access_logs.page_group IS '一致したパスのパターン（一致しない場合は正規化したパス）';
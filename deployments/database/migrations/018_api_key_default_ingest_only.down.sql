@@ -0,0 +1,4 @@
+-- APIキーのデフォルトのスコープを送信と統計の参照に戻す
+-- 注意: 018 の適用中に発行したキーのスコープは変更しない
+
+ALTER TABLE api_keys ALTER COLUMN scopes SET DEFAULT ARRAY['ingest', 'stats:read'];
@@ -0,0 +1,5 @@
+-- APIキーのデフォルトのスコープを送信のみにする
+-- 説明: スコープを指定せずに発行したキーは HTML に埋め込まれることが多いため、統計の参照（stats:read）は
+--       明示的に指定した場合のみ許可する。発行済みのキーのスコープは変更しない
+
+ALTER TABLE api_keys ALTER COLUMN scopes SET DEFAULT ARRAY['ingest'];
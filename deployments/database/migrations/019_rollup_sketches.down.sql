@@ -0,0 +1,5 @@
+-- ロールアップのセッション・訪問者のスケッチの削除
+-- 注意: 統計APIはバケットをまたぐセッション数・訪問者数を推定できなくなる
+
+ALTER TABLE access_log_rollups DROP COLUMN IF EXISTS visitor_sketch;
+ALTER TABLE access_log_rollups DROP COLUMN IF EXISTS session_sketch;
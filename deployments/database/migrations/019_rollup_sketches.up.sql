@@ -0,0 +1,12 @@
+-- ロールアップのセッション・訪問者のスケッチ
+-- 説明: バケットごとのユニーク数はバケットをまたいで合算できないため、合算できるHyperLogLogのスケッチを
+--       ロールアップの行に保存する。統計APIは期間内のスケッチを合算してセッション数・訪問者数を推定し、
+--       保持期間を過ぎて access_logs が削除された範囲もロールアップだけで集計する
+--       既存の行は NULL のまま（バケットごとのユニーク数の合計で近似する。access_logs が残っている範囲は
+--       ワーカーの -backfill-from で再集計するとスケッチが作成される）
+
+ALTER TABLE access_log_rollups ADD COLUMN IF NOT EXISTS session_sketch BYTEA;
+ALTER TABLE access_log_rollups ADD COLUMN IF NOT EXISTS visitor_sketch BYTEA;
+
+COMMENT ON COLUMN access_log_rollups.session_sketch IS 'バケット内のセッションIDのHyperLogLogのスケッチ（internal/utils/hll）';
+COMMENT ON COLUMN access_log_rollups.visitor_sketch IS 'バケット内のIPアドレスのHyperLogLogのスケッチ（internal/utils/hll）';
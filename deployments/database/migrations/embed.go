@@ -0,0 +1,9 @@
+// Package migrations はバイナリに埋め込むSQLマイグレーションファイルを提供します
+package migrations
+
+import "embed"
+
+// FS は {version}_{name}.up.sql / {version}_{name}.down.sql 形式のマイグレーションファイルです
+//
+//go:embed *.sql
+var FS embed.FS
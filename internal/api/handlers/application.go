@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"accesslog-tracker/internal/api/models"
 	domainmodels "accesslog-tracker/internal/domain/models"
@@ -28,7 +31,13 @@ func NewApplicationHandler(applicationService services.ApplicationServiceInterfa
 }
 
 // Create は新しいアプリケーションを作成します
+// スーパーユーザー以外は、オーナーまたは管理者として所属する組織にのみ作成できます
 func (h *ApplicationHandler) Create(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
 	var req models.ApplicationRequest
 
 	// リクエストボディをバインディング
@@ -45,14 +54,57 @@ func (h *ApplicationHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// タイムゾーンの検証
+	if !domainmodels.IsValidTimezone(req.Timezone) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid timezone",
+				Details: "timezone must be an IANA time zone name such as Asia/Tokyo",
+			},
+		})
+		return
+	}
+
+	// 所有する組織の検証
+	if req.OrganizationID == "" && !principal.Superuser {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Organization is required",
+				Details: domainmodels.ErrOrganizationRequired.Error(),
+			},
+		})
+		return
+	}
+	if !principal.CanManage(req.OrganizationID) {
+		h.forbidden(c, principal, req.OrganizationID)
+		return
+	}
+
 	// アプリケーションを作成
 	app := &domainmodels.Application{
-		Name:        req.Name,
-		Description: req.Description,
-		Domain:      req.Domain,
+		Name:           req.Name,
+		Description:    req.Description,
+		Domain:         req.Domain,
+		Timezone:       req.Timezone,
+		OrganizationID: req.OrganizationID,
 	}
 	err := h.applicationService.Create(c.Request.Context(), app)
 	if err != nil {
+		if errors.Is(err, domainmodels.ErrOrganizationNotFound) {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "Organization not found",
+				},
+			})
+			return
+		}
+
 		h.logger.Error("Failed to create application", "error", err.Error())
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -66,13 +118,16 @@ func (h *ApplicationHandler) Create(c *gin.Context) {
 
 	// レスポンスを作成
 	response := models.ApplicationResponse{
-		AppID:       app.AppID,
-		Name:        app.Name,
-		Description: app.Description,
-		Domain:      app.Domain,
-		APIKey:      app.APIKey,
-		CreatedAt:   app.CreatedAt,
-		UpdatedAt:   app.UpdatedAt,
+		AppID:          app.AppID,
+		Name:           app.Name,
+		Description:    app.Description,
+		Domain:         app.Domain,
+		Timezone:       app.Timezone,
+		Plan:           app.Plan,
+		APIKey:         app.APIKey,
+		OrganizationID: app.OrganizationID,
+		CreatedAt:      app.CreatedAt,
+		UpdatedAt:      app.UpdatedAt,
 	}
 
 	h.logger.Info("Application created successfully", "app_id", app.AppID, "name", app.Name)
@@ -85,6 +140,11 @@ func (h *ApplicationHandler) Create(c *gin.Context) {
 
 // Get はアプリケーションの詳細を取得します
 func (h *ApplicationHandler) Get(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
 	appID := c.Param("id")
 	if appID == "" {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
@@ -110,16 +170,22 @@ func (h *ApplicationHandler) Get(c *gin.Context) {
 		})
 		return
 	}
+	if !h.authorize(c, principal, app, false) {
+		return
+	}
 
 	// レスポンスを作成
 	response := models.ApplicationResponse{
-		AppID:       app.AppID,
-		Name:        app.Name,
-		Description: app.Description,
-		Domain:      app.Domain,
-		APIKey:      app.APIKey,
-		CreatedAt:   app.CreatedAt,
-		UpdatedAt:   app.UpdatedAt,
+		AppID:          app.AppID,
+		Name:           app.Name,
+		Description:    app.Description,
+		Domain:         app.Domain,
+		Timezone:       app.Timezone,
+		Plan:           app.Plan,
+		Settings:       app.Settings,
+		OrganizationID: app.OrganizationID,
+		CreatedAt:      app.CreatedAt,
+		UpdatedAt:      app.UpdatedAt,
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
@@ -130,6 +196,11 @@ func (h *ApplicationHandler) Get(c *gin.Context) {
 
 // Update はアプリケーションを更新します
 func (h *ApplicationHandler) Update(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
 	appID := c.Param("id")
 	if appID == "" {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
@@ -158,6 +229,19 @@ func (h *ApplicationHandler) Update(c *gin.Context) {
 		return
 	}
 
+	// タイムゾーンの検証
+	if !domainmodels.IsValidTimezone(req.Timezone) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid timezone",
+				Details: "timezone must be an IANA time zone name such as Asia/Tokyo",
+			},
+		})
+		return
+	}
+
 	// 既存のアプリケーションを取得
 	existingApp, err := h.applicationService.GetByID(c.Request.Context(), appID)
 	if err != nil {
@@ -171,16 +255,27 @@ func (h *ApplicationHandler) Update(c *gin.Context) {
 		})
 		return
 	}
+	if !h.authorize(c, principal, existingApp, true) {
+		return
+	}
 
-	// アプリケーションを更新（既存のAPIキーを保持）
+	// アプリケーションを更新（APIキーは api_keys で管理するため変更しない）
 	app := &domainmodels.Application{
-		AppID:       appID,
-		Name:        req.Name,
-		Description: req.Description,
-		Domain:      req.Domain,
-		APIKey:      existingApp.APIKey, // 既存のAPIキーを保持
-		Active:      existingApp.Active, // 既存のActive状態を保持
-		CreatedAt:   existingApp.CreatedAt,
+		AppID:          appID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Domain:         req.Domain,
+		Active:         existingApp.Active, // 既存のActive状態を保持
+		Timezone:       existingApp.Timezone,
+		Settings:       existingApp.Settings,
+		Plan:           existingApp.Plan,           // プランは UpdatePlan でのみ変更する
+		OrganizationID: existingApp.OrganizationID, // 所有する組織は変更しない
+		CreatedAt:      existingApp.CreatedAt,
+	}
+
+	// Timezoneフィールドが指定されている場合のみ更新
+	if req.Timezone != "" {
+		app.Timezone = req.Timezone
 	}
 
 	// Activeフィールドが指定されている場合のみ更新
@@ -202,13 +297,16 @@ func (h *ApplicationHandler) Update(c *gin.Context) {
 
 	// レスポンスを作成
 	response := models.ApplicationResponse{
-		AppID:       app.AppID,
-		Name:        app.Name,
-		Description: app.Description,
-		Domain:      app.Domain,
-		APIKey:      app.APIKey,
-		CreatedAt:   app.CreatedAt,
-		UpdatedAt:   app.UpdatedAt,
+		AppID:          app.AppID,
+		Name:           app.Name,
+		Description:    app.Description,
+		Domain:         app.Domain,
+		Timezone:       app.Timezone,
+		Plan:           app.Plan,
+		Settings:       app.Settings,
+		OrganizationID: app.OrganizationID,
+		CreatedAt:      app.CreatedAt,
+		UpdatedAt:      app.UpdatedAt,
 	}
 
 	h.logger.Info("Application updated successfully", "app_id", appID)
@@ -219,81 +317,126 @@ func (h *ApplicationHandler) Update(c *gin.Context) {
 	})
 }
 
-// List はアプリケーション一覧を取得します
-func (h *ApplicationHandler) List(c *gin.Context) {
-	// ページネーションパラメータを取得
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("page_size", "10")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+// UpdateSettings はアプリケーション設定を更新します
+// 指定したキーだけを上書きし、値が null のキーは削除します
+func (h *ApplicationHandler) UpdateSettings(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
 	}
 
-	pageSize, err := strconv.Atoi(pageSizeStr)
-	if err != nil || pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	appID := c.Param("id")
+	if appID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Application ID is required",
+			},
+		})
+		return
+	}
+	if !h.authorizeByID(c, principal, appID, true) {
+		return
 	}
 
-	// アプリケーション一覧を取得
-	limit := pageSize
-	offset := (page - 1) * pageSize
-	apps, err := h.applicationService.List(c.Request.Context(), limit, offset)
-	if err != nil {
-		h.logger.Error("Failed to list applications", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+	var settings map[string]interface{}
+
+	// リクエストボディをバインディング
+	if err := c.ShouldBindJSON(&settings); err != nil || len(settings) == 0 {
+		details := "settings must be a non-empty JSON object"
+		if err != nil {
+			details = err.Error()
+		}
+		h.logger.Warn("Invalid application settings request", "details", details)
+		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
-				Code:    "INTERNAL_SERVER_ERROR",
-				Message: "Failed to list applications",
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request format",
+				Details: details,
 			},
 		})
 		return
 	}
 
-	total, err := h.applicationService.Count(c.Request.Context())
+	// 設定を更新
+	if err := h.applicationService.UpdateSettings(c.Request.Context(), appID, settings); err != nil {
+		switch {
+		case errors.Is(err, domainmodels.ErrApplicationInvalidSettings):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid application settings",
+					Details: err.Error(),
+				},
+			})
+		case errors.Is(err, domainmodels.ErrApplicationNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "NOT_FOUND",
+					Message: "Application not found",
+				},
+			})
+		default:
+			h.logger.Error("Failed to update application settings", "error", err.Error(), "app_id", appID)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INTERNAL_SERVER_ERROR",
+					Message: "Failed to update application settings",
+				},
+			})
+		}
+		return
+	}
+
+	// 更新後のアプリケーションを取得
+	app, err := h.applicationService.GetByID(c.Request.Context(), appID)
 	if err != nil {
-		h.logger.Error("Failed to list applications", "error", err.Error())
+		h.logger.Error("Failed to get application", "error", err.Error(), "app_id", appID)
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "INTERNAL_SERVER_ERROR",
-				Message: "Failed to list applications",
+				Message: "Failed to get application",
 			},
 		})
 		return
 	}
 
 	// レスポンスを作成
-	responses := make([]models.ApplicationResponse, len(apps))
-	for i, app := range apps {
-		responses[i] = models.ApplicationResponse{
-			AppID:       app.AppID,
-			Name:        app.Name,
-			Description: app.Description,
-			Domain:      app.Domain,
-			APIKey:      app.APIKey,
-			CreatedAt:   app.CreatedAt,
-			UpdatedAt:   app.UpdatedAt,
-		}
+	response := models.ApplicationResponse{
+		AppID:          app.AppID,
+		Name:           app.Name,
+		Description:    app.Description,
+		Domain:         app.Domain,
+		Timezone:       app.Timezone,
+		Plan:           app.Plan,
+		Settings:       app.Settings,
+		OrganizationID: app.OrganizationID,
+		CreatedAt:      app.CreatedAt,
+		UpdatedAt:      app.UpdatedAt,
 	}
 
+	h.logger.Info("Application settings updated successfully", "app_id", appID)
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Data: gin.H{
-			"applications": responses,
-			"pagination": gin.H{
-				"page":        page,
-				"page_size":   pageSize,
-				"total":       total,
-				"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
-			},
-		},
+		Data:    response,
 	})
 }
 
-// Delete はアプリケーションを削除します
-func (h *ApplicationHandler) Delete(c *gin.Context) {
+// UpdatePlan はアプリケーションのプランを変更します
+// プランは課金に関わるため、スーパーユーザーのみ変更できます
+func (h *ApplicationHandler) UpdatePlan(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
 	appID := c.Param("id")
 	if appID == "" {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
@@ -305,14 +448,49 @@ func (h *ApplicationHandler) Delete(c *gin.Context) {
 		})
 		return
 	}
+	if !h.authorizeByID(c, principal, appID, true) {
+		return
+	}
+	if !principal.Superuser {
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "FORBIDDEN",
+				Message: "Only superusers can change the plan",
+			},
+		})
+		return
+	}
 
-	// アプリケーションを削除
-	err := h.applicationService.Delete(c.Request.Context(), appID)
-	if err != nil {
-		h.logger.Error("Failed to delete application", "error", err.Error(), "app_id", appID)
+	var req models.PlanRequest
 
-		// アプリケーションが見つからない場合
-		if errors.Is(err, domainmodels.ErrApplicationNotFound) {
+	// リクエストボディをバインディング
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid plan request", "error", err.Error())
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	// プランを変更
+	if err := h.applicationService.UpdatePlan(c.Request.Context(), appID, req.Plan); err != nil {
+		switch {
+		case domainmodels.IsValidationError(err):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid plan",
+					Details: err.Error(),
+				},
+			})
+		case errors.Is(err, domainmodels.ErrApplicationNotFound):
 			c.JSON(http.StatusNotFound, models.APIResponse{
 				Success: false,
 				Error: &models.APIError{
@@ -320,25 +498,783 @@ func (h *ApplicationHandler) Delete(c *gin.Context) {
 					Message: "Application not found",
 				},
 			})
-			return
+		default:
+			h.logger.Error("Failed to update application plan", "error", err.Error(), "app_id", appID)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INTERNAL_SERVER_ERROR",
+					Message: "Failed to update application plan",
+				},
+			})
 		}
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+	h.logger.Info("Application plan updated successfully", "app_id", appID, "plan", req.Plan)
+
+	plan, _ := domainmodels.LookupPlan(req.Plan)
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"app_id": appID,
+			"plan":   toPlanResponse(plan),
+		},
+	})
+}
+
+// GetUsage はアプリケーションの当月の使用量をプランの上限とともに返します
+func (h *ApplicationHandler) GetUsage(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	if appID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
-				Code:    "INTERNAL_SERVER_ERROR",
-				Message: "Failed to delete application",
+				Code:    "VALIDATION_ERROR",
+				Message: "Application ID is required",
+			},
+		})
+		return
+	}
+	if !h.authorizeByID(c, principal, appID, false) {
+		return
+	}
+
+	// 使用量を取得
+	usage, err := h.applicationService.GetUsage(c.Request.Context(), appID)
+	if err != nil {
+		h.logger.Error("Failed to get application usage", "error", err.Error(), "app_id", appID)
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "NOT_FOUND",
+				Message: "Application not found",
 			},
 		})
 		return
 	}
 
-	h.logger.Info("Application deleted successfully", "app_id", appID)
+	plan, ok := domainmodels.LookupPlan(usage.Plan)
+	if !ok {
+		plan, _ = domainmodels.LookupPlan(domainmodels.DefaultPlan)
+	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: models.UsageResponse{
+			AppID:       usage.AppID,
+			Plan:        toPlanResponse(plan),
+			PeriodStart: usage.PeriodStart,
+			PeriodEnd:   usage.PeriodEnd,
+			Events:      usage.Events,
+			Quota:       usage.Quota,
+			Remaining:   usage.Remaining,
+		},
+	})
+}
+
+// PreviewURLRules はページURLの正規化とグループ化のルールを保存せずに試します
+// url_rules を指定した場合はそのルールで、省略した場合は保存済みの設定で正規化した結果を返します
+func (h *ApplicationHandler) PreviewURLRules(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	app, err := h.applicationService.GetByID(c.Request.Context(), appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "NOT_FOUND",
+				Message: "Application not found",
+			},
+		})
+		return
+	}
+	if !h.authorize(c, principal, app, false) {
+		return
+	}
+
+	var req models.URLRulesPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.URLs) > models.MaxURLRulesPreviewURLs {
+		details := fmt.Sprintf("urls must not contain more than %d URLs", models.MaxURLRulesPreviewURLs)
+		if err != nil {
+			details = err.Error()
+		}
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request format",
+				Details: details,
+			},
+		})
+		return
+	}
+
+	normalizer := app.URLNormalizer()
+	if req.URLRules != nil {
+		if normalizer, err = domainmodels.ParseURLRules(req.URLRules); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid url_rules",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+	}
+
+	rules := normalizer.Rules()
+	response := models.URLRulesPreviewResponse{
+		AppID: app.AppID,
+		URLRules: models.URLRulesResponse{
+			StripQuery:     rules.StripQuery,
+			QueryAllowlist: nonNilStrings(rules.QueryAllowlist),
+			DropFragment:   rules.DropFragment,
+			LowercaseHost:  rules.LowercaseHost,
+			TrailingSlash:  rules.TrailingSlash,
+			PathGroups:     nonNilStrings(rules.PathGroups),
+		},
+		Results: make([]models.URLRulesPreviewResult, 0, len(req.URLs)),
+	}
+	for _, rawURL := range req.URLs {
+		result := normalizer.Normalize(rawURL)
+		response.Results = append(response.Results, models.URLRulesPreviewResult{
+			URL:       rawURL,
+			PagePath:  result.PagePath,
+			PageGroup: result.PageGroup,
+		})
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Data: gin.H{
-			"message": "Application deleted successfully",
+		Data:    response,
+	})
+}
+
+// nonNilStrings は nil の配列を空の配列に置き換えます（JSON で null ではなく [] を返すため）
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// toPlanResponse はプランをレスポンスに変換します
+func toPlanResponse(plan domainmodels.Plan) models.PlanResponse {
+	return models.PlanResponse{
+		Name:              plan.Name,
+		RequestsPerMinute: plan.RequestsPerMinute,
+		RequestsPerHour:   plan.RequestsPerHour,
+		BurstSize:         plan.BurstSize,
+		MonthlyEventQuota: plan.MonthlyEventQuota,
+	}
+}
+
+// List はアプリケーション一覧を取得します
+// スーパーユーザー以外は所属する組織のアプリケーションのみ返します
+func (h *ApplicationHandler) List(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	// ページネーションパラメータを取得
+	pageStr := c.DefaultQuery("page", "1")
+	pageSizeStr := c.DefaultQuery("page_size", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	// アプリケーション一覧を取得
+	limit := pageSize
+	offset := (page - 1) * pageSize
+	var apps []*domainmodels.Application
+	switch {
+	case principal.Superuser:
+		apps, err = h.applicationService.List(c.Request.Context(), limit, offset)
+	case principal.AppID != "":
+		// APIキーで認証した場合はキーが属するアプリケーションのみ
+		apps, err = h.listByAPIKey(c, principal.AppID, offset)
+	default:
+		apps, err = h.applicationService.GetByUserID(c.Request.Context(), principal.UserID, limit, offset)
+	}
+	if err != nil {
+		h.logger.Error("Failed to list applications", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INTERNAL_SERVER_ERROR",
+				Message: "Failed to list applications",
+			},
+		})
+		return
+	}
+
+	var total int64
+	switch {
+	case principal.Superuser:
+		total, err = h.applicationService.Count(c.Request.Context())
+	case principal.AppID != "":
+		total = 1
+	default:
+		total, err = h.applicationService.CountByUserID(c.Request.Context(), principal.UserID)
+	}
+	if err != nil {
+		h.logger.Error("Failed to list applications", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INTERNAL_SERVER_ERROR",
+				Message: "Failed to list applications",
+			},
+		})
+		return
+	}
+
+	// レスポンスを作成
+	responses := make([]models.ApplicationResponse, len(apps))
+	for i, app := range apps {
+		responses[i] = models.ApplicationResponse{
+			AppID:          app.AppID,
+			Name:           app.Name,
+			Description:    app.Description,
+			Domain:         app.Domain,
+			Timezone:       app.Timezone,
+			Plan:           app.Plan,
+			OrganizationID: app.OrganizationID,
+			CreatedAt:      app.CreatedAt,
+			UpdatedAt:      app.UpdatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"applications": responses,
+			"pagination": gin.H{
+				"page":        page,
+				"page_size":   pageSize,
+				"total":       total,
+				"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+			},
+		},
+	})
+}
+
+// listByAPIKey はAPIキーが属するアプリケーションを一覧の1ページとして取得します
+func (h *ApplicationHandler) listByAPIKey(c *gin.Context, appID string, offset int) ([]*domainmodels.Application, error) {
+	if offset > 0 {
+		return []*domainmodels.Application{}, nil
+	}
+	app, err := h.applicationService.GetByID(c.Request.Context(), appID)
+	if err != nil {
+		return nil, err
+	}
+	return []*domainmodels.Application{app}, nil
+}
+
+// Delete はアプリケーションを削除します
+func (h *ApplicationHandler) Delete(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	if appID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Application ID is required",
+			},
+		})
+		return
+	}
+	if !h.authorizeByID(c, principal, appID, true) {
+		return
+	}
+
+	// アプリケーションを削除
+	err := h.applicationService.Delete(c.Request.Context(), appID)
+	if err != nil {
+		h.logger.Error("Failed to delete application", "error", err.Error(), "app_id", appID)
+
+		// アプリケーションが見つからない場合
+		if errors.Is(err, domainmodels.ErrApplicationNotFound) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "NOT_FOUND",
+					Message: "Application not found",
+				},
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INTERNAL_SERVER_ERROR",
+				Message: "Failed to delete application",
+			},
+		})
+		return
+	}
+
+	h.logger.Info("Application deleted successfully", "app_id", appID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"message": "Application deleted successfully",
+		},
+	})
+}
+
+// ListAPIKeys はアプリケーションのAPIキー一覧を取得します
+// キー本体は返さず、識別用のプレフィックスのみ返します
+func (h *ApplicationHandler) ListAPIKeys(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	if !h.authorizeByID(c, principal, appID, false) {
+		return
+	}
+
+	keys, err := h.applicationService.ListAPIKeys(c.Request.Context(), appID)
+	if err != nil {
+		h.logger.Error("Failed to list API keys", "error", err.Error(), "app_id", appID)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INTERNAL_SERVER_ERROR",
+				Message: "Failed to list API keys",
+			},
+		})
+		return
+	}
+
+	responses := make([]models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, toAPIKeyResponse(key, ""))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"keys": responses,
+		},
+	})
+}
+
+// CreateAPIKey はアプリケーションに名前付きのAPIキーを追加します
+// 平文のキーはこのレスポンスでのみ返します
+func (h *ApplicationHandler) CreateAPIKey(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	if !h.authorizeByID(c, principal, appID, true) {
+		return
+	}
+
+	var req models.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	key, apiKey, err := h.applicationService.CreateAPIKey(c.Request.Context(), appID, req.Name, req.Scopes, req.ExpiresAt, req.Signed)
+	if err != nil {
+		switch {
+		case domainmodels.IsValidationError(err):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid API key",
+					Details: err.Error(),
+				},
+			})
+		case errors.Is(err, domainmodels.ErrApplicationNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "NOT_FOUND",
+					Message: "Application not found",
+				},
+			})
+		default:
+			h.logger.Error("Failed to create API key", "error", err.Error(), "app_id", appID)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INTERNAL_SERVER_ERROR",
+					Message: "Failed to create API key",
+				},
+			})
+		}
+		return
+	}
+
+	h.logger.Info("API key created successfully", "app_id", appID, "key_id", key.ID, "name", key.Name)
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    toAPIKeyResponse(key, apiKey),
+	})
+}
+
+// DeleteAPIKey はアプリケーションのAPIキーを削除します
+func (h *ApplicationHandler) DeleteAPIKey(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	keyID := c.Param("key_id")
+	if !h.authorizeByID(c, principal, appID, true) {
+		return
+	}
+
+	if err := h.applicationService.DeleteAPIKey(c.Request.Context(), appID, keyID); err != nil {
+		if errors.Is(err, domainmodels.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "NOT_FOUND",
+					Message: "API key not found",
+				},
+			})
+			return
+		}
+
+		h.logger.Error("Failed to delete API key", "error", err.Error(), "app_id", appID, "key_id", keyID)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INTERNAL_SERVER_ERROR",
+				Message: "Failed to delete API key",
+			},
+		})
+		return
+	}
+
+	h.logger.Info("API key deleted successfully", "app_id", appID, "key_id", keyID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"message": "API key deleted successfully",
+		},
+	})
+}
+
+// GenerateSigningSecret はAPIキーの署名シークレットを発行します
+// 既存のシークレットは置き換え、シークレットはこのレスポンスでのみ返します
+func (h *ApplicationHandler) GenerateSigningSecret(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	keyID := c.Param("key_id")
+	if !h.authorizeByID(c, principal, appID, true) {
+		return
+	}
+
+	secret, err := h.applicationService.GenerateSigningSecret(c.Request.Context(), appID, keyID)
+	if err != nil {
+		h.signingSecretError(c, err, appID, keyID, "Failed to generate signing secret")
+		return
+	}
+
+	h.logger.Info("Signing secret generated successfully", "app_id", appID, "key_id", keyID)
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data: models.SigningSecretResponse{
+			KeyID:         keyID,
+			SigningSecret: secret,
+		},
+	})
+}
+
+// DeleteSigningSecret はAPIキーの署名シークレットを削除します
+// 以降はキーで認証するリクエストに署名が不要になります
+func (h *ApplicationHandler) DeleteSigningSecret(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	keyID := c.Param("key_id")
+	if !h.authorizeByID(c, principal, appID, true) {
+		return
+	}
+
+	if err := h.applicationService.DeleteSigningSecret(c.Request.Context(), appID, keyID); err != nil {
+		h.signingSecretError(c, err, appID, keyID, "Failed to delete signing secret")
+		return
+	}
+
+	h.logger.Info("Signing secret deleted successfully", "app_id", appID, "key_id", keyID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"message": "Signing secret deleted successfully",
+		},
+	})
+}
+
+// signingSecretError は署名シークレットの操作に失敗した場合のレスポンスを返します
+func (h *ApplicationHandler) signingSecretError(c *gin.Context, err error, appID, keyID, message string) {
+	if errors.Is(err, domainmodels.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "NOT_FOUND",
+				Message: "API key not found",
+			},
+		})
+		return
+	}
+
+	h.logger.Error(message, "error", err.Error(), "app_id", appID, "key_id", keyID)
+	c.JSON(http.StatusInternalServerError, models.APIResponse{
+		Success: false,
+		Error: &models.APIError{
+			Code:    "INTERNAL_SERVER_ERROR",
+			Message: message,
+		},
+	})
+}
+
+// RotateAPIKey はAPIキーをローテーションします
+// 新しいキーを発行し、以前のキーは猶予期間が過ぎると自動的に無効になります
+func (h *ApplicationHandler) RotateAPIKey(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	appID := c.Param("id")
+	keyID := c.Param("key_id")
+	if !h.authorizeByID(c, principal, appID, true) {
+		return
+	}
+
+	// リクエストボディは省略可能（省略時はデフォルトの猶予期間）
+	var req models.APIKeyRotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	var gracePeriod *time.Duration
+	if req.GracePeriodSeconds != nil {
+		d := time.Duration(*req.GracePeriodSeconds) * time.Second
+		gracePeriod = &d
+	}
+
+	rotation, err := h.applicationService.RotateAPIKey(c.Request.Context(), appID, keyID, gracePeriod)
+	if err != nil {
+		switch {
+		case domainmodels.IsValidationError(err):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid rotation request",
+					Details: err.Error(),
+				},
+			})
+		case errors.Is(err, domainmodels.ErrAPIKeyNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "NOT_FOUND",
+					Message: "API key not found",
+				},
+			})
+		default:
+			h.logger.Error("Failed to rotate API key", "error", err.Error(), "app_id", appID, "key_id", keyID)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INTERNAL_SERVER_ERROR",
+					Message: "Failed to rotate API key",
+				},
+			})
+		}
+		return
+	}
+
+	h.logger.Info("API key rotated successfully", "app_id", appID, "key_id", rotation.Key.ID, "previous_key_id", keyID)
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data: models.APIKeyRotationResponse{
+			APIKeyResponse: toAPIKeyResponse(rotation.Key, rotation.Secret),
+			PreviousKey:    toAPIKeyResponse(rotation.Previous, ""),
+		},
+	})
+}
+
+// toAPIKeyResponse はAPIキーをレスポンスに変換します（apiKey は発行時のみ指定）
+// 署名シークレットは発行時（apiKey を指定した場合）のみ含めます
+func toAPIKeyResponse(key *domainmodels.APIKey, apiKey string) models.APIKeyResponse {
+	response := models.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Key:        apiKey,
+		Scopes:     key.Scopes,
+		Signed:     key.RequiresSignature(),
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+	}
+	if apiKey != "" {
+		response.SigningSecret = key.SigningSecret
+	}
+	return response
+}
+
+// principal は管理API認証ミドルウェアが設定した呼び出し元を取得します
+// 取得できない場合は 401 を返します
+func (h *ApplicationHandler) principal(c *gin.Context) (*domainmodels.Principal, bool) {
+	if value, exists := c.Get("principal"); exists {
+		if principal, ok := value.(*domainmodels.Principal); ok && principal != nil {
+			return principal, true
+		}
+	}
+
+	c.JSON(http.StatusUnauthorized, models.APIResponse{
+		Success: false,
+		Error: &models.APIError{
+			Code:    "AUTHENTICATION_ERROR",
+			Message: "Admin credentials are required",
+		},
+	})
+	return nil, false
+}
+
+// authorize は呼び出し元がアプリケーションを参照（manage が true の場合は管理）できるかを確認します
+// 参照できないアプリケーションは存在を明かさないよう 404 を返します
+func (h *ApplicationHandler) authorize(c *gin.Context, principal *domainmodels.Principal, app *domainmodels.Application, manage bool) bool {
+	// apps:admin スコープのAPIキーは、キーが属するアプリケーションのみ管理できる
+	if principal.AppID != "" {
+		return h.authorizeAPIKey(c, principal, app.AppID)
+	}
+
+	if !principal.CanView(app.OrganizationID) {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "NOT_FOUND",
+				Message: "Application not found",
+			},
+		})
+		return false
+	}
+	if manage && !principal.CanManage(app.OrganizationID) {
+		h.forbidden(c, principal, app.OrganizationID)
+		return false
+	}
+	return true
+}
+
+// authorizeByID はアプリケーションを取得して、呼び出し元が参照（manage が true の場合は管理）できるかを確認します
+// スーパーユーザーの場合は取得を省略します
+func (h *ApplicationHandler) authorizeByID(c *gin.Context, principal *domainmodels.Principal, appID string, manage bool) bool {
+	if principal.Superuser {
+		return true
+	}
+	if principal.AppID != "" {
+		return h.authorizeAPIKey(c, principal, appID)
+	}
+
+	app, err := h.applicationService.GetByID(c.Request.Context(), appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "NOT_FOUND",
+				Message: "Application not found",
+			},
+		})
+		return false
+	}
+	return h.authorize(c, principal, app, manage)
+}
+
+// authorizeAPIKey はAPIキーで認証した呼び出し元がアプリケーションにアクセスできるかを確認します
+// 他のアプリケーションは存在を明かさないよう 404 を返します
+func (h *ApplicationHandler) authorizeAPIKey(c *gin.Context, principal *domainmodels.Principal, appID string) bool {
+	if principal.CanAccessApplication(appID) {
+		return true
+	}
+
+	c.JSON(http.StatusNotFound, models.APIResponse{
+		Success: false,
+		Error: &models.APIError{
+			Code:    "NOT_FOUND",
+			Message: "Application not found",
+		},
+	})
+	return false
+}
+
+// forbidden は組織での権限が不足している場合の 403 を返します
+func (h *ApplicationHandler) forbidden(c *gin.Context, principal *domainmodels.Principal, organizationID string) {
+	h.logger.Warn("Insufficient organization role", "user_id", principal.UserID, "organization_id", organizationID, "role", principal.Role(organizationID))
+	c.JSON(http.StatusForbidden, models.APIResponse{
+		Success: false,
+		Error: &models.APIError{
+			Code:    "FORBIDDEN",
+			Message: "Owner or admin role in the organization is required",
 		},
 	})
 }
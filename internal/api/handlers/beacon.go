@@ -1,28 +1,111 @@
 package handlers
 
 import (
+	"context"
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"accesslog-tracker/internal/api/models"
 	"accesslog-tracker/internal/beacon/generator"
+	domainmodels "accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/domain/services"
+	"accesslog-tracker/internal/utils/logger"
 )
 
+// beaconProcessTimeout はビーコンヒット1件あたりの非同期処理のタイムアウトです
+const beaconProcessTimeout = 5 * time.Second
+
+// DefaultMaxPendingBeaconHits は同時に処理するビーコンヒット数の上限のデフォルト値です
+const DefaultMaxPendingBeaconHits = 1024
+
+// transparentGIF は1x1ピクセルの透明GIF画像です
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, // GIF89a
+	0x01, 0x00, 0x01, 0x00, // 1x1ピクセル
+	0x80, 0x00, 0x00, // 背景色（透明）
+	0x00, 0x00, 0x00, // パレット
+	0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, // グラフィック制御拡張
+	0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, // 画像記述子
+	0x02, 0x02, 0x44, 0x01, 0x00, // 画像データ
+	0x3b, // 終了
+}
+
+// beaconReservedParams はカスタムパラメータとして扱わないクエリパラメータです
+var beaconReservedParams = map[string]bool{
+	"app_id":     true,
+	"session_id": true,
+	"url":        true,
+	"referrer":   true,
+}
+
+// BeaconHitStats はGIFビーコンヒットの処理結果の集計です
+type BeaconHitStats struct {
+	Accepted int64 `json:"accepted"`
+	Rejected int64 `json:"rejected"`
+	Invalid  int64 `json:"invalid"`
+	Failed   int64 `json:"failed"`
+	Dropped  int64 `json:"dropped"`
+	Overflow int64 `json:"overflow"`
+}
+
 // BeaconHandler はビーコン配信ハンドラーです
 type BeaconHandler struct {
-	generator *generator.BeaconGenerator
+	generator          *generator.BeaconGenerator
+	trackingService    services.TrackingServiceInterface
+	applicationService services.ApplicationServiceInterface
+	logger             logger.Logger
+	startedAt          time.Time
+	pending            sync.WaitGroup
+	slots              chan struct{}
+
+	accepted int64
+	rejected int64
+	invalid  int64
+	failed   int64
+	dropped  int64
+	overflow int64
+}
+
+// BeaconHandlerOption はビーコンハンドラーのオプションです
+type BeaconHandlerOption func(*BeaconHandler)
+
+// WithMaxPendingBeaconHits は同時に処理するビーコンヒット数の上限を設定します
+// 上限を超えたヒットは保存せずに破棄します
+func WithMaxPendingBeaconHits(n int) BeaconHandlerOption {
+	return func(h *BeaconHandler) {
+		if n > 0 {
+			h.slots = make(chan struct{}, n)
+		}
+	}
 }
 
 // NewBeaconHandler は新しいビーコンハンドラーを作成します
-func NewBeaconHandler() *BeaconHandler {
-	return &BeaconHandler{
-		generator: generator.NewBeaconGenerator(),
+func NewBeaconHandler(
+	trackingService services.TrackingServiceInterface,
+	applicationService services.ApplicationServiceInterface,
+	logger logger.Logger,
+	opts ...BeaconHandlerOption,
+) *BeaconHandler {
+	h := &BeaconHandler{
+		generator:          generator.NewBeaconGenerator(),
+		trackingService:    trackingService,
+		applicationService: applicationService,
+		logger:             logger,
+		startedAt:          time.Now(),
+		slots:              make(chan struct{}, DefaultMaxPendingBeaconHits),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Serve はJavaScriptビーコンを配信します
@@ -179,111 +262,31 @@ func (h *BeaconHandler) GenerateBeacon(c *gin.Context) {
 	}
 
 	// トラッキングデータを収集
-	sessionID := c.Query("session_id")
-	url := c.Query("url")
-	if url == "" {
-		url = "/"
-	}
-	referrer := c.Query("referrer")
-	userAgent := c.GetHeader("User-Agent")
-
-	// IPアドレスを取得
-	ipAddress := c.ClientIP()
-	if ipAddress == "" {
-		ipAddress = c.Request.RemoteAddr
-	}
-
-	// カスタムパラメータを収集
-	customParams := make(map[string]interface{})
-	for key, values := range c.Request.URL.Query() {
-		if key != "app_id" && key != "session_id" && key != "url" && key != "referrer" {
-			if len(values) > 0 {
-				customParams[key] = values[0]
-			}
-		}
-	}
-
-	// 1x1ピクセルの透明GIF画像（Base64エンコード）
-	// これは最小限のGIF画像データです
-	gifData := []byte{
-		0x47, 0x49, 0x46, 0x38, 0x39, 0x61, // GIF89a
-		0x01, 0x00, 0x01, 0x00, // 1x1ピクセル
-		0x80, 0x00, 0x00, // 背景色（透明）
-		0x00, 0x00, 0x00, // パレット
-		0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, // グラフィック制御拡張
-		0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, // 画像記述子
-		0x02, 0x02, 0x44, 0x01, 0x00, // 画像データ
-		0x3b, // 終了
-	}
-
-	// レスポンスヘッダーを設定
-	c.Header("Content-Type", "image/gif")
-	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
-	c.Header("Pragma", "no-cache")
-	c.Header("Expires", "0")
+	data := h.collectHit(c, appID)
 
 	// GIF画像を返す
-	c.Data(http.StatusOK, "image/gif", gifData)
+	h.writeGIF(c)
 
-	// 注意: 実際のトラッキングデータの保存は非同期で行うべきです
-	// ここではログ出力のみ行います
-	fmt.Printf("Beacon tracking: app_id=%s, session_id=%s, url=%s, referrer=%s, user_agent=%s, ip=%s, custom_params=%v\n",
-		appID, sessionID, url, referrer, userAgent, ipAddress, customParams)
+	// レスポンス送信後にトラッキングデータを非同期で保存
+	h.recordHit(data, c.GetHeader("Origin"))
 }
 
 // ServeGIF は1x1ピクセルGIFビーコンを配信します
 func (h *BeaconHandler) ServeGIF(c *gin.Context) {
 	// クエリパラメータを取得
 	appID := c.Query("app_id")
-	sessionID := c.Query("session_id")
-	url := c.Query("url")
-	if url == "" {
-		url = "/"
-	}
-	referrer := c.Query("referrer")
-	userAgent := c.GetHeader("User-Agent")
 
-	// IPアドレスを取得
-	ipAddress := c.ClientIP()
-	if ipAddress == "" {
-		ipAddress = c.Request.RemoteAddr
+	// トラッキングデータを収集（app_idがない場合は保存しない）
+	var data *domainmodels.TrackingData
+	if appID != "" {
+		data = h.collectHit(c, appID)
 	}
 
-	// カスタムパラメータを収集
-	customParams := make(map[string]interface{})
-	for key, values := range c.Request.URL.Query() {
-		if key != "app_id" && key != "session_id" && key != "url" && key != "referrer" {
-			if len(values) > 0 {
-				customParams[key] = values[0]
-			}
-		}
-	}
-
-	// これは最小限のGIF画像データです
-	gifData := []byte{
-		0x47, 0x49, 0x46, 0x38, 0x39, 0x61, // GIF89a
-		0x01, 0x00, 0x01, 0x00, // 1x1ピクセル
-		0x80, 0x00, 0x00, // 背景色（透明）
-		0x00, 0x00, 0x00, // パレット
-		0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, // グラフィック制御拡張
-		0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, // 画像記述子
-		0x02, 0x02, 0x44, 0x01, 0x00, // 画像データ
-		0x3b, // 終了
-	}
-
-	// レスポンスヘッダーを設定
-	c.Header("Content-Type", "image/gif")
-	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
-	c.Header("Pragma", "no-cache")
-	c.Header("Expires", "0")
-
 	// GIF画像を返す
-	c.Data(http.StatusOK, "image/gif", gifData)
+	h.writeGIF(c)
 
-	// 注意: 実際のトラッキングデータの保存は非同期で行うべきです
-	// ここではログ出力のみ行います
-	fmt.Printf("Beacon tracking: app_id=%s, session_id=%s, url=%s, referrer=%s, user_agent=%s, ip=%s, custom_params=%v\n",
-		appID, sessionID, url, referrer, userAgent, ipAddress, customParams)
+	// レスポンス送信後にトラッキングデータを非同期で保存
+	h.recordHit(data, c.GetHeader("Origin"))
 }
 
 // GenerateBeaconWithConfig はカスタム設定でビーコンを生成します
@@ -303,22 +306,7 @@ func (h *BeaconHandler) GenerateBeaconWithConfig(c *gin.Context) {
 	}
 
 	// 仕様: POSTもGIFを返す
-	gifData := []byte{
-		0x47, 0x49, 0x46, 0x38, 0x39, 0x61,
-		0x01, 0x00, 0x01, 0x00,
-		0x80, 0x00, 0x00,
-		0x00, 0x00, 0x00,
-		0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00,
-		0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00,
-		0x02, 0x02, 0x44, 0x01, 0x00,
-		0x3b,
-	}
-
-	c.Header("Content-Type", "image/gif")
-	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
-	c.Header("Pragma", "no-cache")
-	c.Header("Expires", "0")
-	c.Data(http.StatusOK, "image/gif", gifData)
+	h.writeGIF(c)
 }
 
 // ProcessBeacon はビーコンリクエストを処理します
@@ -338,13 +326,67 @@ func (h *BeaconHandler) ProcessBeacon(c *gin.Context) {
 	}
 
 	// トラッキングデータを収集
-	sessionID := c.Query("session_id")
+	data := h.collectHit(c, appID)
+
+	// GIF画像を返す
+	h.writeGIF(c)
+
+	// レスポンス送信後にトラッキングデータを非同期で保存
+	h.recordHit(data, c.GetHeader("Origin"))
+}
+
+// Health はビーコンサービスの健全性を返します
+func (h *BeaconHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"status": "healthy",
+			"beacon": map[string]interface{}{
+				"version": "1.0.0",
+				"uptime":  time.Since(h.startedAt).String(),
+				"hits":    h.Stats(),
+			},
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// Stats はGIFビーコンヒットの処理結果の集計を返します
+func (h *BeaconHandler) Stats() BeaconHitStats {
+	return BeaconHitStats{
+		Accepted: atomic.LoadInt64(&h.accepted),
+		Rejected: atomic.LoadInt64(&h.rejected),
+		Invalid:  atomic.LoadInt64(&h.invalid),
+		Failed:   atomic.LoadInt64(&h.failed),
+		Dropped:  atomic.LoadInt64(&h.dropped),
+		Overflow: atomic.LoadInt64(&h.overflow),
+	}
+}
+
+// Wait は処理中のビーコンヒットがすべて完了するまで待機します
+func (h *BeaconHandler) Wait() {
+	h.pending.Wait()
+}
+
+// writeGIF は1x1ピクセルGIFをキャッシュ無効のヘッダー付きで返します
+func (h *BeaconHandler) writeGIF(c *gin.Context) {
+	c.Header("Content-Type", "image/gif")
+	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.Header("Pragma", "no-cache")
+	c.Header("Expires", "0")
+	c.Data(http.StatusOK, "image/gif", transparentGIF)
+}
+
+// collectHit はリクエストからトラッキングデータを組み立てます
+func (h *BeaconHandler) collectHit(c *gin.Context, appID string) *domainmodels.TrackingData {
+	// ページURLはクエリ優先、なければ埋め込み元ページのRefererを使用
 	url := c.Query("url")
+	if url == "" {
+		url = c.GetHeader("Referer")
+	}
 	if url == "" {
 		url = "/"
 	}
-	referrer := c.Query("referrer")
-	userAgent := c.GetHeader("User-Agent")
 
 	// IPアドレスを取得
 	ipAddress := c.ClientIP()
@@ -353,53 +395,112 @@ func (h *BeaconHandler) ProcessBeacon(c *gin.Context) {
 	}
 
 	// カスタムパラメータを収集
-	customParams := make(map[string]interface{})
+	var customParams map[string]interface{}
 	for key, values := range c.Request.URL.Query() {
-		if key != "app_id" && key != "session_id" && key != "url" && key != "referrer" {
-			if len(values) > 0 {
-				customParams[key] = values[0]
-			}
+		if beaconReservedParams[key] || len(values) == 0 {
+			continue
 		}
+		if customParams == nil {
+			customParams = make(map[string]interface{})
+		}
+		customParams[key] = values[0]
 	}
 
-	// 1x1ピクセルの透明GIF画像（Base64エンコード）
-	gifData := []byte{
-		0x47, 0x49, 0x46, 0x38, 0x39, 0x61, // GIF89a
-		0x01, 0x00, 0x01, 0x00, // 1x1ピクセル
-		0x80, 0x00, 0x00, // 背景色（透明）
-		0x00, 0x00, 0x00, // パレット
-		0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, // グラフィック制御拡張
-		0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, // 画像記述子
-		0x02, 0x02, 0x44, 0x01, 0x00, // 画像データ
-		0x3b, // 終了
+	return &domainmodels.TrackingData{
+		AppID:        appID,
+		UserAgent:    c.GetHeader("User-Agent"),
+		URL:          url,
+		IPAddress:    ipAddress,
+		SessionID:    c.Query("session_id"),
+		Referrer:     c.Query("referrer"),
+		CustomParams: customParams,
+		Timestamp:    time.Now(),
 	}
+}
 
-	// レスポンスヘッダーを設定
-	c.Header("Content-Type", "image/gif")
-	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
-	c.Header("Pragma", "no-cache")
-	c.Header("Expires", "0")
+// recordHit はレスポンス送信後にビーコンヒットを非同期で保存します
+// origin はリクエストの Origin ヘッダーです（画像として読み込まれた場合は空）
+// 処理中のヒットが上限に達している場合は、ゴルーチンを増やさずにヒットを破棄します
+func (h *BeaconHandler) recordHit(data *domainmodels.TrackingData, origin string) {
+	if data == nil {
+		return
+	}
 
-	// GIF画像を返す
-	c.Data(http.StatusOK, "image/gif", gifData)
+	select {
+	case h.slots <- struct{}{}:
+	default:
+		atomic.AddInt64(&h.overflow, 1)
+		h.logger.Warn("Beacon hit discarded because too many hits are pending", "app_id", data.AppID)
+		return
+	}
+
+	h.pending.Add(1)
+	go func() {
+		defer h.pending.Done()
+		defer func() { <-h.slots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), beaconProcessTimeout)
+		defer cancel()
 
-	// 注意: 実際のトラッキングデータの保存は非同期で行うべきです
-	// ここではログ出力のみ行います
-	fmt.Printf("Beacon tracking: app_id=%s, session_id=%s, url=%s, referrer=%s, user_agent=%s, ip=%s, custom_params=%v\n",
-		appID, sessionID, url, referrer, userAgent, ipAddress, customParams)
+		h.processHit(ctx, data, origin)
+	}()
 }
 
-// Health はビーコンサービスの健全性を返します
-func (h *BeaconHandler) Health(c *gin.Context) {
-	c.JSON(http.StatusOK, models.APIResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"status": "healthy",
-			"beacon": map[string]interface{}{
-				"version": "1.0.0",
-				"uptime":  time.Since(time.Now()).String(),
-			},
-		},
-		Timestamp: time.Now(),
-	})
+// processHit はアプリケーションと送信元を確認した上でトラッキングサービスに渡します
+func (h *BeaconHandler) processHit(ctx context.Context, data *domainmodels.TrackingData, origin string) {
+	// 未登録・非アクティブなアプリケーションのヒットは保存しない
+	app, err := h.applicationService.GetByID(ctx, data.AppID)
+	if err != nil || app == nil || !app.IsActive() {
+		atomic.AddInt64(&h.rejected, 1)
+		h.logger.Warn("Beacon hit rejected for unknown or inactive application", "app_id", data.AppID)
+		return
+	}
+
+	// origin_policy が reject の場合、許可されない送信元のヒットは保存しない
+	if err := app.CheckTrackingOrigin(data, origin); err != nil {
+		atomic.AddInt64(&h.rejected, 1)
+		h.logger.Warn("Beacon hit rejected for disallowed origin", "app_id", data.AppID, "origin", origin, "url", data.URL)
+		return
+	}
+
+	// 月間のイベント数の上限を超えたヒットは保存しない（使用量を記録できない場合は保存する）
+	usage, err := h.applicationService.ReserveQuota(ctx, app, 1)
+	if err != nil {
+		if errors.Is(err, domainmodels.ErrQuotaExceeded) {
+			atomic.AddInt64(&h.rejected, 1)
+			h.logger.Warn("Beacon hit rejected for exceeded monthly event quota", "app_id", data.AppID)
+			return
+		}
+		h.logger.Error("Failed to record beacon hit usage", "error", err.Error(), "app_id", data.AppID)
+	}
+
+	data.BotPolicy = app.BotPolicy()
+	data.URLNormalizer = app.URLNormalizer()
+	err = h.trackingService.ProcessTrackingData(ctx, data)
+	// 保存しなかったヒット（ボットとして破棄・検証エラーを含む）は使用量に数えない
+	if usage != nil {
+		accepted := 0
+		if err == nil {
+			accepted = 1
+		}
+		if err := h.applicationService.CommitQuota(ctx, usage, 1, accepted); err != nil {
+			h.logger.Error("Failed to commit beacon hit usage", "error", err.Error(), "app_id", data.AppID)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, domainmodels.ErrTrackingBotDropped) {
+			atomic.AddInt64(&h.dropped, 1)
+			return
+		}
+		if domainmodels.IsValidationError(err) {
+			atomic.AddInt64(&h.invalid, 1)
+			h.logger.Warn("Invalid beacon hit", "error", err.Error(), "app_id", data.AppID)
+			return
+		}
+		atomic.AddInt64(&h.failed, 1)
+		h.logger.Error("Failed to save beacon hit", "error", err.Error(), "app_id", data.AppID)
+		return
+	}
+
+	atomic.AddInt64(&h.accepted, 1)
 }
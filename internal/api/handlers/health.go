@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"accesslog-tracker/internal/api/models"
+	"accesslog-tracker/internal/domain/services"
 	"accesslog-tracker/internal/infrastructure/database/postgresql"
 	"accesslog-tracker/internal/infrastructure/cache/redis"
 	"accesslog-tracker/internal/utils/logger"
@@ -13,21 +14,59 @@ import (
 
 // HealthHandler はヘルスチェックAPIのハンドラーです
 type HealthHandler struct {
-	dbConn    *postgresql.Connection
-	redisConn *redis.CacheService
-	logger    logger.Logger
+	dbConn          *postgresql.Connection
+	redisConn       *redis.CacheService
+	trackingService *services.TrackingService
+	logger          logger.Logger
 }
 
 // NewHealthHandler は新しいヘルスチェックハンドラーを作成します
-func NewHealthHandler(dbConn *postgresql.Connection, redisConn *redis.CacheService, logger logger.Logger) *HealthHandler {
+// trackingService が nil の場合、インジェストキューの状態は報告しません
+func NewHealthHandler(dbConn *postgresql.Connection, redisConn *redis.CacheService, trackingService *services.TrackingService, logger logger.Logger) *HealthHandler {
 	return &HealthHandler{
-		dbConn:    dbConn,
-		redisConn: redisConn,
-		logger:    logger,
+		dbConn:          dbConn,
+		redisConn:       redisConn,
+		trackingService: trackingService,
+		logger:          logger,
+	}
+}
+
+// ingestionStatus はインジェストパイプラインの状態を取得します
+// 第2戻り値はキューが満杯かどうかを表します
+func (h *HealthHandler) ingestionStatus() (*models.IngestionStatus, bool) {
+	if h.trackingService == nil {
+		return nil, false
+	}
+
+	stats, ok := h.trackingService.IngestionStats()
+	if !ok {
+		return nil, false
+	}
+
+	return &models.IngestionStatus{
+		QueueDepth:    stats.QueueDepth,
+		QueueCapacity: stats.QueueCapacity,
+		Workers:       stats.Workers,
+		Enqueued:      stats.Enqueued,
+		Written:       stats.Written,
+		Failed:        stats.Failed,
+		Rejected:      stats.Rejected,
+	}, stats.Saturated()
+}
+
+// redisStatus はRedisへの疎通を確認し、接続の状態を取得します
+func (h *HealthHandler) redisStatus(c *gin.Context) *models.RedisStatus {
+	h.redisConn.Ping(c.Request.Context())
+	status := h.redisConn.Status()
+	return &models.RedisStatus{
+		Available: status.Available,
+		Since:     status.Since,
+		LastError: status.LastError,
 	}
 }
 
 // Health はヘルスチェックを実行します
+// Redisを利用できない場合はプロセス内のレート制限とキャッシュで処理を続けるため、degraded として 200 を返します
 func (h *HealthHandler) Health(c *gin.Context) {
 	status := "healthy"
 	services := make(map[string]string)
@@ -42,19 +81,35 @@ func (h *HealthHandler) Health(c *gin.Context) {
 	}
 
 	// Redisのヘルスチェック
-	if err := h.redisConn.Ping(c.Request.Context()); err != nil {
-		status = "unhealthy"
-		services["redis"] = "unhealthy"
-		h.logger.Error("Redis health check failed", "error", err.Error())
+	redisStatus := h.redisStatus(c)
+	if !redisStatus.Available {
+		if status == "healthy" {
+			status = "degraded"
+		}
+		services["redis"] = "degraded"
+		h.logger.Warn("Redis health check failed, running in degraded mode", "error", redisStatus.LastError)
 	} else {
 		services["redis"] = "healthy"
 	}
 
+	// インジェストキューの状態
+	ingestionStatus, saturated := h.ingestionStatus()
+	if ingestionStatus != nil {
+		if saturated {
+			services["ingestion"] = "saturated"
+			h.logger.Warn("Ingestion queue is saturated", "queue_depth", ingestionStatus.QueueDepth)
+		} else {
+			services["ingestion"] = "healthy"
+		}
+	}
+
 	// レスポンスを作成
 	response := models.HealthResponse{
 		Status:    status,
 		Timestamp: time.Now(),
 		Services:  services,
+		Ingestion: ingestionStatus,
+		Redis:     redisStatus,
 	}
 
 	// ステータスコードを決定
@@ -64,12 +119,13 @@ func (h *HealthHandler) Health(c *gin.Context) {
 	}
 
 	c.JSON(statusCode, models.APIResponse{
-		Success: status == "healthy",
+		Success: status != "unhealthy",
 		Data:    response,
 	})
 }
 
 // Readiness はアプリケーションの準備完了状態をチェックします
+// Redisを利用できない場合も準備完了とし、services.redis を degraded にします
 func (h *HealthHandler) Readiness(c *gin.Context) {
 	status := "ready"
 	services := make(map[string]string)
@@ -83,20 +139,32 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 		services["database"] = "ready"
 	}
 
-	// Redisの準備完了チェック
-	if err := h.redisConn.Ping(c.Request.Context()); err != nil {
-		status = "not_ready"
-		services["redis"] = "not_ready"
-		h.logger.Error("Redis readiness check failed", "error", err.Error())
+	// Redisの準備完了チェック（利用できない間もトラフィックを受け付ける）
+	redisStatus := h.redisStatus(c)
+	if !redisStatus.Available {
+		services["redis"] = "degraded"
 	} else {
 		services["redis"] = "ready"
 	}
 
+	// インジェストキューが満杯の間はトラフィックを受け付けない
+	ingestionStatus, saturated := h.ingestionStatus()
+	if ingestionStatus != nil {
+		if saturated {
+			status = "not_ready"
+			services["ingestion"] = "not_ready"
+		} else {
+			services["ingestion"] = "ready"
+		}
+	}
+
 	// レスポンスを作成
 	response := models.HealthResponse{
 		Status:    status,
 		Timestamp: time.Now(),
 		Services:  services,
+		Ingestion: ingestionStatus,
+		Redis:     redisStatus,
 	}
 
 	// ステータスコードを決定
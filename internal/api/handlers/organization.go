@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"accesslog-tracker/internal/api/models"
+	domainmodels "accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/domain/services"
+	"accesslog-tracker/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler は組織・ユーザー・メンバーAPIのハンドラーです
+type OrganizationHandler struct {
+	organizationService services.OrganizationServiceInterface
+	logger              logger.Logger
+}
+
+// NewOrganizationHandler は新しい組織ハンドラーを作成します
+func NewOrganizationHandler(organizationService services.OrganizationServiceInterface, logger logger.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		logger:              logger,
+	}
+}
+
+// Create は新しい組織を作成します（スーパーユーザーのみ）
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+	if !principal.Superuser {
+		h.respondError(c, http.StatusForbidden, "FORBIDDEN", "Admin role is required")
+		return
+	}
+
+	var req models.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	org := &domainmodels.Organization{Name: req.Name}
+	if err := h.organizationService.CreateOrganization(c.Request.Context(), org, req.OwnerUserID); err != nil {
+		h.handleError(c, err, "Failed to create organization")
+		return
+	}
+
+	h.logger.Info("Organization created successfully", "organization_id", org.ID, "owner_user_id", req.OwnerUserID)
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    org,
+	})
+}
+
+// List は呼び出し元が参照できる組織の一覧を取得します
+func (h *OrganizationHandler) List(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	orgs, err := h.organizationService.ListOrganizations(c.Request.Context(), principal)
+	if err != nil {
+		h.handleError(c, err, "Failed to list organizations")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"organizations": orgs,
+		},
+	})
+}
+
+// Get は組織の詳細を取得します
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	orgID := c.Param("id")
+	if !principal.CanView(orgID) {
+		h.respondError(c, http.StatusNotFound, "NOT_FOUND", "Organization not found")
+		return
+	}
+
+	org, err := h.organizationService.GetOrganization(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    org,
+	})
+}
+
+// CreateUser は新しいユーザーを作成します（スーパーユーザーのみ）
+func (h *OrganizationHandler) CreateUser(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+	if !principal.Superuser {
+		h.respondError(c, http.StatusForbidden, "FORBIDDEN", "Admin role is required")
+		return
+	}
+
+	var req models.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	user := &domainmodels.User{Email: req.Email, Name: req.Name}
+	if err := h.organizationService.CreateUser(c.Request.Context(), user); err != nil {
+		h.handleError(c, err, "Failed to create user")
+		return
+	}
+
+	h.logger.Info("User created successfully", "user_id", user.ID)
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    user,
+	})
+}
+
+// ListMembers は組織のメンバー一覧を取得します
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	orgID := c.Param("id")
+	if !principal.CanView(orgID) {
+		h.respondError(c, http.StatusNotFound, "NOT_FOUND", "Organization not found")
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list members")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"members": members,
+		},
+	})
+}
+
+// SetMember はユーザーを組織に追加するか、既存メンバーのロールを変更します
+// 管理者はオーナーの付与・変更はできません
+func (h *OrganizationHandler) SetMember(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	orgID := c.Param("id")
+	userID := c.Param("user_id")
+	if !principal.CanView(orgID) {
+		h.respondError(c, http.StatusNotFound, "NOT_FOUND", "Organization not found")
+		return
+	}
+
+	var req models.MembershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	// 付与するロールと現在のロールの両方を扱える必要がある
+	currentRole, err := h.organizationService.GetMemberRole(c.Request.Context(), orgID, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to update member")
+		return
+	}
+	if !principal.CanManageMember(orgID, req.Role) || (currentRole != "" && !principal.CanManageMember(orgID, currentRole)) {
+		h.respondError(c, http.StatusForbidden, "FORBIDDEN", "Insufficient organization role")
+		return
+	}
+
+	membership := &domainmodels.Membership{OrganizationID: orgID, UserID: userID, Role: req.Role}
+	if err := h.organizationService.SetMember(c.Request.Context(), membership); err != nil {
+		h.handleError(c, err, "Failed to update member")
+		return
+	}
+
+	h.logger.Info("Organization member updated", "organization_id", orgID, "user_id", userID, "role", req.Role)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    membership,
+	})
+}
+
+// RemoveMember はユーザーを組織から削除します
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	principal, ok := h.principal(c)
+	if !ok {
+		return
+	}
+
+	orgID := c.Param("id")
+	userID := c.Param("user_id")
+	if !principal.CanView(orgID) {
+		h.respondError(c, http.StatusNotFound, "NOT_FOUND", "Organization not found")
+		return
+	}
+
+	currentRole, err := h.organizationService.GetMemberRole(c.Request.Context(), orgID, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to remove member")
+		return
+	}
+	if currentRole == "" {
+		h.respondError(c, http.StatusNotFound, "NOT_FOUND", "Member not found")
+		return
+	}
+	if !principal.CanManageMember(orgID, currentRole) {
+		h.respondError(c, http.StatusForbidden, "FORBIDDEN", "Insufficient organization role")
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(c.Request.Context(), orgID, userID); err != nil {
+		h.handleError(c, err, "Failed to remove member")
+		return
+	}
+
+	h.logger.Info("Organization member removed", "organization_id", orgID, "user_id", userID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"message": "Member removed successfully",
+		},
+	})
+}
+
+// principal は管理API認証ミドルウェアが設定した呼び出し元を取得します
+// 取得できない場合は 401 を返します
+func (h *OrganizationHandler) principal(c *gin.Context) (*domainmodels.Principal, bool) {
+	if value, exists := c.Get("principal"); exists {
+		if principal, ok := value.(*domainmodels.Principal); ok && principal != nil {
+			return principal, true
+		}
+	}
+
+	h.respondError(c, http.StatusUnauthorized, "AUTHENTICATION_ERROR", "Admin credentials are required")
+	return nil, false
+}
+
+// handleError はサービスのエラーをHTTPレスポンスに変換します
+func (h *OrganizationHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case domainmodels.IsValidationError(err):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: message,
+				Details: err.Error(),
+			},
+		})
+	case errors.Is(err, domainmodels.ErrOrganizationNotFound):
+		h.respondError(c, http.StatusNotFound, "NOT_FOUND", "Organization not found")
+	case errors.Is(err, domainmodels.ErrUserNotFound):
+		h.respondError(c, http.StatusNotFound, "NOT_FOUND", "User not found")
+	case errors.Is(err, domainmodels.ErrMembershipNotFound):
+		h.respondError(c, http.StatusNotFound, "NOT_FOUND", "Member not found")
+	case errors.Is(err, domainmodels.ErrUserAlreadyExists), errors.Is(err, domainmodels.ErrMembershipLastOwner):
+		h.respondError(c, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		h.logger.Error(message, "error", err.Error())
+		h.respondError(c, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", message)
+	}
+}
+
+// respondError はエラーレスポンスを返します
+func (h *OrganizationHandler) respondError(c *gin.Context, status int, code, message string) {
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error: &models.APIError{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
@@ -1,17 +1,25 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"accesslog-tracker/internal/api/models"
 	domainmodels "accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/domain/services"
+	"accesslog-tracker/internal/ingestion"
+	"accesslog-tracker/internal/urlnorm"
 	"accesslog-tracker/internal/utils/logger"
 	"accesslog-tracker/internal/utils/timeutil"
 )
 
+// ingestionRetryAfter はキューが満杯の場合に返す Retry-After（秒）です
+const ingestionRetryAfter = "1"
+
 // TrackingHandler はトラッキングAPIのハンドラーです
 type TrackingHandler struct {
 	trackingService services.TrackingServiceInterface
@@ -83,8 +91,45 @@ func (h *TrackingHandler) Track(c *gin.Context) {
 		Timestamp:   time.Now(),
 	}
 
-	// トラッキングデータを保存
+	// 送信元がアプリケーションで許可されたオリジンかを確認
+	if err := h.checkOrigin(c, trackingData); err != nil {
+		h.logger.Warn("Tracking data rejected for disallowed origin", "app_id", req.AppID, "origin", c.GetHeader("Origin"), "url", req.URL)
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error:   originNotAllowedError(),
+		})
+		return
+	}
+
+	// トラッキングデータを保存（bot_policy が drop の場合、ボットと判定したイベントは保存せずに成功として返す）
+	trackingData.BotPolicy = botPolicy(c)
+	trackingData.URLNormalizer = urlNormalizer(c)
 	err := h.trackingService.ProcessTrackingData(c.Request.Context(), trackingData)
+	if errors.Is(err, domainmodels.ErrTrackingBotDropped) {
+		h.logger.Info("Tracking data dropped as bot traffic", "app_id", req.AppID, "bot_reason", trackingData.BotReason)
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data: models.TrackingResponse{
+				AppID:     trackingData.AppID,
+				Timestamp: trackingData.Timestamp,
+				Dropped:   true,
+			},
+		})
+		return
+	}
+	if errors.Is(err, ingestion.ErrQueueFull) || errors.Is(err, ingestion.ErrPipelineClosed) {
+		// キューが満杯または停止中の場合はクライアントに再送を促す
+		h.logger.Warn("Tracking data rejected by ingestion queue", "error", err.Error(), "app_id", req.AppID)
+		c.Header("Retry-After", ingestionRetryAfter)
+		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "SERVICE_UNAVAILABLE",
+				Message: "Tracking queue is full, please retry later",
+			},
+		})
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to save tracking data", "error", err.Error(), "app_id", req.AppID)
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
@@ -97,6 +142,9 @@ func (h *TrackingHandler) Track(c *gin.Context) {
 		return
 	}
 
+	// 受け付けたイベント数（Quota ミドルウェアが使用量に数える）
+	c.Set("accepted_events", 1)
+
 	// レスポンスを作成
 	response := models.TrackingResponse{
 		TrackingID: trackingData.ID,
@@ -116,6 +164,224 @@ func (h *TrackingHandler) Track(c *gin.Context) {
 	})
 }
 
+// TrackBatch は複数のトラッキングデータをまとめて受け取って保存します
+// ボディはJSON配列またはNDJSONで、各イベントの処理結果を個別に返します
+func (h *TrackingHandler) TrackBatch(c *gin.Context) {
+	// TrackingBatch ミドルウェアで解析済みのイベントを優先して使用
+	var events []models.TrackingRequest
+	if parsed, ok := c.Get("tracking_events"); ok {
+		events, _ = parsed.([]models.TrackingRequest)
+	}
+	if events == nil {
+		body, err := io.ReadAll(c.Request.Body)
+		if err == nil {
+			events, err = models.ParseBatchTrackingRequest(body)
+		}
+		if err != nil {
+			h.logger.Warn("Invalid batch tracking request", "error", err.Error(), "ip", c.ClientIP())
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid batch request format",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+	}
+
+	// アプリケーションIDをコンテキストから取得
+	appID, exists := c.Get("app_id")
+	if !exists {
+		h.logger.Error("App ID not found in context")
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INTERNAL_SERVER_ERROR",
+				Message: "Application ID not found",
+			},
+		})
+		return
+	}
+
+	results := make([]models.BatchTrackingItemResult, len(events))
+	batch := make([]*domainmodels.TrackingData, 0, len(events))
+	indexes := make([]int, 0, len(events))
+	now := time.Now()
+	policy := botPolicy(c)
+	normalizer := urlNormalizer(c)
+
+	for i, req := range events {
+		results[i].Index = i
+
+		// 各イベントのAppIDと認証されたAppIDが一致するかチェック
+		if req.AppID != appID {
+			results[i].Error = &models.APIError{
+				Code:    "FORBIDDEN",
+				Message: "App ID mismatch",
+			}
+			continue
+		}
+
+		data := &domainmodels.TrackingData{
+			AppID:         req.AppID,
+			UserAgent:     req.UserAgent,
+			URL:           req.URL,
+			IPAddress:     req.IPAddress,
+			SessionID:     req.SessionID,
+			Referrer:      req.Referrer,
+			CustomParams:  req.CustomParams,
+			Timestamp:     now,
+			BotPolicy:     policy,
+			URLNormalizer: normalizer,
+		}
+
+		// 送信元がアプリケーションで許可されたオリジンかを確認
+		if err := h.checkOrigin(c, data); err != nil {
+			results[i].Error = originNotAllowedError()
+			continue
+		}
+
+		batch = append(batch, data)
+		indexes = append(indexes, i)
+	}
+
+	// トラッキングデータをまとめて保存
+	var queueRejected int
+	if len(batch) > 0 {
+		errs := h.trackingService.ProcessTrackingBatch(c.Request.Context(), batch)
+		for k, data := range batch {
+			i := indexes[k]
+			var err error
+			if k < len(errs) {
+				err = errs[k]
+			}
+			if err == nil {
+				results[i].Success = true
+				results[i].TrackingID = data.ID
+				continue
+			}
+			if errors.Is(err, domainmodels.ErrTrackingBotDropped) {
+				results[i].Success = true
+				results[i].Dropped = true
+				continue
+			}
+			if errors.Is(err, ingestion.ErrQueueFull) || errors.Is(err, ingestion.ErrPipelineClosed) {
+				queueRejected++
+			}
+			results[i].Error = batchItemError(err)
+		}
+	}
+
+	response := models.BatchTrackingResponse{Results: results}
+	for _, result := range results {
+		switch {
+		case result.Dropped:
+			response.Dropped++
+		case result.Success:
+			response.Accepted++
+		default:
+			response.Rejected++
+		}
+	}
+
+	// 受け付けたイベント数（Quota ミドルウェアが使用量に数える）
+	c.Set("accepted_events", response.Accepted)
+
+	// すべてのイベントがキューに入らなかった場合はクライアントに再送を促す
+	if response.Accepted == 0 && queueRejected > 0 {
+		h.logger.Warn("Tracking batch rejected by ingestion queue", "app_id", appID, "events", len(events))
+		c.Header("Retry-After", ingestionRetryAfter)
+		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Data:    response,
+			Error: &models.APIError{
+				Code:    "SERVICE_UNAVAILABLE",
+				Message: "Tracking queue is full, please retry later",
+			},
+		})
+		return
+	}
+
+	h.logger.Info("Tracking batch processed",
+		"app_id", appID,
+		"accepted", response.Accepted,
+		"rejected", response.Rejected,
+		"dropped", response.Dropped)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    response,
+	})
+}
+
+// checkOrigin は認証されたアプリケーションの許可オリジンとイベントの送信元を照合します
+// origin_policy が reject の場合は許可されない送信元のイベントに対してエラーを返し、
+// それ以外はイベントに origin_mismatch を付けます
+func (h *TrackingHandler) checkOrigin(c *gin.Context, data *domainmodels.TrackingData) error {
+	app := authenticatedApplication(c)
+	if app == nil {
+		return nil
+	}
+	return app.CheckTrackingOrigin(data, c.GetHeader("Origin"))
+}
+
+// botPolicy は認証されたアプリケーションの bot_policy 設定を返します
+func botPolicy(c *gin.Context) string {
+	if app := authenticatedApplication(c); app != nil {
+		return app.BotPolicy()
+	}
+	return domainmodels.BotPolicyFlag
+}
+
+// urlNormalizer は認証されたアプリケーションの url_rules 設定の正規化を返します（認証されていない場合はデフォルトのルール）
+func urlNormalizer(c *gin.Context) *urlnorm.Normalizer {
+	if app := authenticatedApplication(c); app != nil {
+		return app.URLNormalizer()
+	}
+	return urlnorm.Default()
+}
+
+// authenticatedApplication は認証ミドルウェアがコンテキストに設定したアプリケーションを返します
+func authenticatedApplication(c *gin.Context) *domainmodels.Application {
+	value, exists := c.Get("application")
+	if !exists {
+		return nil
+	}
+	app, _ := value.(*domainmodels.Application)
+	return app
+}
+
+// originNotAllowedError は許可されない送信元のイベントを拒否した場合のAPIエラーです
+func originNotAllowedError() *models.APIError {
+	return &models.APIError{
+		Code:    "ORIGIN_NOT_ALLOWED",
+		Message: "Origin is not allowed for this application",
+	}
+}
+
+// batchItemError はバッチ内のイベントの処理エラーをAPIエラーに変換します
+func batchItemError(err error) *models.APIError {
+	switch {
+	case domainmodels.IsValidationError(err):
+		return &models.APIError{
+			Code:    "VALIDATION_ERROR",
+			Message: err.Error(),
+		}
+	case errors.Is(err, ingestion.ErrQueueFull), errors.Is(err, ingestion.ErrPipelineClosed):
+		return &models.APIError{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "Tracking queue is full, please retry later",
+		}
+	default:
+		return &models.APIError{
+			Code:    "INTERNAL_SERVER_ERROR",
+			Message: "Failed to save tracking data",
+		}
+	}
+}
+
 // GetStatistics は統計データを取得します
 func (h *TrackingHandler) GetStatistics(c *gin.Context) {
 	// クエリパラメータを取得
@@ -162,6 +428,34 @@ func (h *TrackingHandler) GetStatistics(c *gin.Context) {
 		return
 	}
 
+	// グループ化単位と件数のパース
+	groupBy := c.Query("group_by")
+	if groupBy != "" && !domainmodels.IsValidStatsGroupBy(groupBy) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "group_by must be one of day, hour, page, referrer, browser, os, device, country, channel, campaign, page_group",
+			},
+		})
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "limit must be a positive integer",
+				},
+			})
+			return
+		}
+	}
+
 	// 認証されたアプリケーションIDと一致するかチェック
 	authAppID, exists := c.Get("app_id")
 	if !exists || appID != authAppID {
@@ -175,8 +469,25 @@ func (h *TrackingHandler) GetStatistics(c *gin.Context) {
 		return
 	}
 
-	// 統計データを取得
-	stats, err := h.trackingService.GetStatistics(c.Request.Context(), appID, startDate, endDate)
+	// 統計データを取得（終了日はその日の終わりまでを含める）
+	stats, err := h.trackingService.GetStatistics(c.Request.Context(), services.StatisticsQuery{
+		AppID:     appID,
+		StartDate: startDate,
+		EndDate:   timeutil.GetEndOfDay(endDate),
+		GroupBy:   groupBy,
+		Limit:     limit,
+	})
+	if errors.Is(err, domainmodels.ErrStatisticsInvalidPeriod) || errors.Is(err, domainmodels.ErrStatisticsInvalidGroupBy) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid statistics query",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to get statistics", "error", err.Error(), "app_id", appID)
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
@@ -191,13 +502,33 @@ func (h *TrackingHandler) GetStatistics(c *gin.Context) {
 
 	// レスポンスを作成
 	response := models.StatisticsResponse{
-		AppID:         appID,
-		StartDate:     startDate,
-		EndDate:       endDate,
-		TotalRequests: int64(stats.Metrics["total_tracking_count"].(int64)),
-		UniqueVisitors: int64(stats.Metrics["total_tracking_count"].(int64)) / 5, // 簡易的な計算
-		TopPages:      []models.PageStats{},
-		TopReferrers:  []models.ReferrerStats{},
+		AppID:                  appID,
+		StartDate:              startDate,
+		EndDate:                endDate,
+		TotalRequests:          stats.TotalRequests,
+		UniqueVisitors:         stats.UniqueVisitors,
+		UniqueSessions:         stats.UniqueSessions,
+		BotRequests:            stats.BotRequests,
+		MobileRequests:         stats.MobileRequests,
+		AverageSessionDuration: stats.AverageSessionDuration,
+		TopPages:               make([]models.PageStats, 0, len(stats.TopPages)),
+		TopReferrers:           make([]models.ReferrerStats, 0, len(stats.TopReferrers)),
+		GroupBy:                stats.GroupBy,
+		Approximate:            stats.Approximate,
+	}
+	for _, page := range stats.TopPages {
+		response.TopPages = append(response.TopPages, models.PageStats{URL: page.Key, Count: page.Requests})
+	}
+	for _, referrer := range stats.TopReferrers {
+		response.TopReferrers = append(response.TopReferrers, models.ReferrerStats{Referrer: referrer.Key, Count: referrer.Requests})
+	}
+	for _, group := range stats.Groups {
+		response.Groups = append(response.Groups, models.GroupStats{
+			Key:            group.Key,
+			Requests:       group.Requests,
+			UniqueSessions: group.UniqueSessions,
+			UniqueVisitors: group.UniqueIPs,
+		})
 	}
 
 	h.logger.Info("Statistics retrieved successfully", "app_id", appID)
@@ -207,3 +538,154 @@ func (h *TrackingHandler) GetStatistics(c *gin.Context) {
 		Data:    response,
 	})
 }
+
+// GetTimeSeries は時系列統計を取得します
+func (h *TrackingHandler) GetTimeSeries(c *gin.Context) {
+	// クエリパラメータを取得
+	appID := c.Query("app_id")
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+	interval := c.DefaultQuery("interval", domainmodels.TimeSeriesIntervalDay)
+
+	// 必須パラメータのチェック
+	if appID == "" || startDateStr == "" || endDateStr == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "app_id, start_date, and end_date are required",
+			},
+		})
+		return
+	}
+
+	if !domainmodels.IsValidTimeSeriesInterval(interval) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "interval must be one of minute, hour, day, week, month",
+			},
+		})
+		return
+	}
+
+	// 認証されたアプリケーションIDと一致するかチェック
+	authAppID, exists := c.Get("app_id")
+	if !exists || appID != authAppID {
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "FORBIDDEN",
+				Message: "Access denied to this application's data",
+			},
+		})
+		return
+	}
+
+	// タイムゾーンの決定（リクエスト指定 > アプリケーション設定 > UTC）
+	loc := time.UTC
+	if app, ok := c.Get("application"); ok {
+		if application, ok := app.(*domainmodels.Application); ok {
+			loc = application.Location()
+		}
+	}
+	if tz := c.Query("tz"); tz != "" {
+		requested, err := domainmodels.LoadTimezone(tz)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid tz",
+					Details: "tz must be an IANA time zone name such as Asia/Tokyo",
+				},
+			})
+			return
+		}
+		loc = requested
+	}
+
+	// 日付のパース（指定されたタイムゾーンの日付として扱う）
+	startDate, err := timeutil.ParseDateInLocation(startDateStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid start_date format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	endDate, err := timeutil.ParseDateInLocation(endDateStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid end_date format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	// 時系列統計を取得（終了日はその日の終わりまでを含める）
+	series, err := h.trackingService.GetTimeSeries(c.Request.Context(), services.TimeSeriesQuery{
+		AppID:     appID,
+		StartDate: startDate,
+		EndDate:   timeutil.GetEndOfDay(endDate),
+		Interval:  interval,
+		Location:  loc,
+	})
+	if errors.Is(err, domainmodels.ErrStatisticsInvalidPeriod) || errors.Is(err, domainmodels.ErrStatisticsTooManyPoints) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid time series query",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get time series", "error", err.Error(), "app_id", appID)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INTERNAL_SERVER_ERROR",
+				Message: "Failed to get time series",
+			},
+		})
+		return
+	}
+
+	// レスポンスを作成
+	response := models.TimeSeriesResponse{
+		AppID:     appID,
+		Interval:  series.Interval,
+		Timezone:  series.Timezone,
+		StartDate: series.StartDate,
+		EndDate:   series.EndDate,
+		Points:    make([]models.TimeSeriesPoint, 0, len(series.Points)),
+	}
+	for _, point := range series.Points {
+		response.Points = append(response.Points, models.TimeSeriesPoint{
+			Timestamp: point.Bucket,
+			PageViews: point.PageViews,
+			Sessions:  point.Sessions,
+			Visitors:  point.Visitors,
+		})
+	}
+
+	h.logger.Info("Time series retrieved successfully", "app_id", appID, "interval", interval)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    response,
+	})
+}
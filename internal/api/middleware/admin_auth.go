@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"accesslog-tracker/internal/api/models"
+	domainmodels "accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/crypto"
+	"accesslog-tracker/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRole はすべての組織・アプリケーションへのアクセスを許可するJWTのロールです
+const AdminRole = "admin"
+
+// MembershipLookup はユーザーの組織ごとのロールを取得するインターフェースです
+type MembershipLookup interface {
+	GetUserRoles(ctx context.Context, userID string) (map[string]string, error)
+}
+
+// AdminAuthConfig は管理API認証の設定です
+// Token と JWTSecret のどちらも空の場合は、すべてのリクエストを拒否します
+type AdminAuthConfig struct {
+	Token     string           // マスター管理トークン
+	JWTSecret string           // 管理者JWTの署名鍵（HS256）
+	Clock     func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// AdminAuthMiddleware は管理API認証ミドルウェアの構造体です
+type AdminAuthMiddleware struct {
+	config      AdminAuthConfig
+	memberships MembershipLookup
+	logger      logger.Logger
+}
+
+// NewAdminAuthMiddleware は新しい管理API認証ミドルウェアを作成します
+// memberships が nil の場合、admin ロール以外のJWTは拒否します
+func NewAdminAuthMiddleware(config AdminAuthConfig, memberships MembershipLookup, logger logger.Logger) *AdminAuthMiddleware {
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	return &AdminAuthMiddleware{
+		config:      config,
+		memberships: memberships,
+		logger:      logger,
+	}
+}
+
+// Authenticate は Authorization: Bearer ヘッダーのマスター管理トークンまたはJWTによる認証を行い、
+// 呼び出し元（*domainmodels.Principal）を "principal" としてコンテキストに設定します
+// マスター管理トークンと admin ロールのJWTはスーパーユーザー、それ以外のJWTは sub のユーザーとして所属組織のロールを持ちます
+func (m *AdminAuthMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			m.logger.Warn("Admin credentials not provided", "path", c.Request.URL.Path, "ip", c.ClientIP())
+			m.abort(c, http.StatusUnauthorized, "AUTHENTICATION_ERROR", "Admin credentials are required")
+			return
+		}
+
+		// マスター管理トークン
+		if m.config.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(m.config.Token)) == 1 {
+			c.Set("principal", &domainmodels.Principal{Superuser: true})
+			c.Next()
+			return
+		}
+
+		// 管理者JWT
+		if m.config.JWTSecret == "" {
+			m.logger.Warn("Invalid admin token", "path", c.Request.URL.Path, "ip", c.ClientIP())
+			m.abort(c, http.StatusUnauthorized, "AUTHENTICATION_ERROR", "Invalid admin credentials")
+			return
+		}
+
+		claims, err := crypto.ParseJWT(token, m.config.JWTSecret, m.config.Clock())
+		if err != nil {
+			m.logger.Warn("Invalid admin token", "path", c.Request.URL.Path, "ip", c.ClientIP(), "error", err.Error())
+			m.abort(c, http.StatusUnauthorized, "AUTHENTICATION_ERROR", "Invalid admin credentials")
+			return
+		}
+
+		if claims.Role == AdminRole {
+			c.Set("principal", &domainmodels.Principal{UserID: claims.Subject, Superuser: true})
+			m.logger.Debug("Admin authentication successful", "subject", claims.Subject, "path", c.Request.URL.Path)
+			c.Next()
+			return
+		}
+
+		// 組織のメンバーとしてのアクセス
+		if claims.Subject == "" || m.memberships == nil {
+			m.logger.Warn("Admin role required", "subject", claims.Subject, "role", claims.Role, "path", c.Request.URL.Path, "ip", c.ClientIP())
+			m.abort(c, http.StatusForbidden, "FORBIDDEN", "Admin role or organization membership is required")
+			return
+		}
+
+		roles, err := m.memberships.GetUserRoles(c.Request.Context(), claims.Subject)
+		if err != nil {
+			m.logger.Error("Failed to load organization memberships", "subject", claims.Subject, "error", err.Error())
+			m.abort(c, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", "Failed to authenticate")
+			return
+		}
+
+		c.Set("principal", &domainmodels.Principal{UserID: claims.Subject, Roles: roles})
+		m.logger.Debug("User authentication successful", "subject", claims.Subject, "organizations", len(roles), "path", c.Request.URL.Path)
+		c.Next()
+	}
+}
+
+// abort はエラーレスポンスを返して処理を中断します
+func (m *AdminAuthMiddleware) abort(c *gin.Context, status int, code, message string) {
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error: &models.APIError{
+			Code:    code,
+			Message: message,
+		},
+	})
+	c.Abort()
+}
+
+// bearerToken は Authorization ヘッダーから Bearer トークンを取り出します
+func bearerToken(header string) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
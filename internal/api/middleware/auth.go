@@ -2,8 +2,10 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 
 	"accesslog-tracker/internal/api/models"
+	domainmodels "accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/domain/services"
 	"accesslog-tracker/internal/utils/logger"
 
@@ -25,51 +27,11 @@ func NewAuthMiddleware(applicationService services.ApplicationServiceInterface,
 }
 
 // Authenticate はAPIキーによる認証を行います
-func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
+// scopes を指定した場合、キーがそのすべてのスコープを持たなければ 403 を返します
+func (m *AuthMiddleware) Authenticate(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// APIキーをヘッダーから取得
-		apiKey := c.GetHeader("X-API-Key")
-		if apiKey == "" {
-			m.logger.Warn("API key not provided", "path", c.Request.URL.Path, "ip", c.ClientIP())
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Error: &models.APIError{
-					Code:    "AUTHENTICATION_ERROR",
-					Message: "API key is required",
-				},
-			})
-			c.Abort()
-			return
-		}
-
-		// 仕様準拠: APIキーのプレフィックス制約は課さない
-
-		// アプリケーションの存在確認
-		app, err := m.applicationService.GetByAPIKey(c.Request.Context(), apiKey)
-		if err != nil {
-			m.logger.Warn("Invalid API key", "path", c.Request.URL.Path, "ip", c.ClientIP(), "error", err.Error())
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Error: &models.APIError{
-					Code:    "INVALID_API_KEY",
-					Message: "Invalid API key",
-				},
-			})
-			c.Abort()
-			return
-		}
-
-		// アプリケーションがアクティブかチェック
-		if !app.Active {
-			m.logger.Warn("Inactive application", "app_id", app.AppID, "path", c.Request.URL.Path, "ip", c.ClientIP())
-			c.JSON(http.StatusForbidden, models.APIResponse{
-				Success: false,
-				Error: &models.APIError{
-					Code:    "APPLICATION_INACTIVE",
-					Message: "Application is inactive",
-				},
-			})
-			c.Abort()
+		app, ok := m.authenticate(c, scopes)
+		if !ok {
 			return
 		}
 
@@ -82,8 +44,75 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 	}
 }
 
+// authenticate はAPIキーを検証し、失敗した場合はエラーレスポンスを返して処理を中断します
+func (m *AuthMiddleware) authenticate(c *gin.Context, scopes []string) (*domainmodels.Application, bool) {
+	// APIキーをヘッダーから取得
+	apiKey := c.GetHeader("X-API-Key")
+	if apiKey == "" {
+		m.logger.Warn("API key not provided", "path", c.Request.URL.Path, "ip", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "AUTHENTICATION_ERROR",
+				Message: "API key is required",
+			},
+		})
+		c.Abort()
+		return nil, false
+	}
+
+	// 仕様準拠: APIキーのプレフィックス制約は課さない
+
+	// アプリケーションの存在確認
+	app, err := m.applicationService.GetByAPIKey(c.Request.Context(), apiKey)
+	if err != nil {
+		m.logger.Warn("Invalid API key", "path", c.Request.URL.Path, "ip", c.ClientIP(), "error", err.Error())
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_API_KEY",
+				Message: "Invalid API key",
+			},
+		})
+		c.Abort()
+		return nil, false
+	}
+
+	// アプリケーションがアクティブかチェック
+	if !app.Active {
+		m.logger.Warn("Inactive application", "app_id", app.AppID, "path", c.Request.URL.Path, "ip", c.ClientIP())
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "APPLICATION_INACTIVE",
+				Message: "Application is inactive",
+			},
+		})
+		c.Abort()
+		return nil, false
+	}
+
+	// キーのスコープをチェック
+	if !hasScopes(app, scopes) {
+		m.logger.Warn("Insufficient API key scope", "app_id", app.AppID, "path", c.Request.URL.Path, "required", strings.Join(scopes, ","))
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INSUFFICIENT_SCOPE",
+				Message: "API key does not have the required scope",
+				Details: strings.Join(scopes, ","),
+			},
+		})
+		c.Abort()
+		return nil, false
+	}
+
+	return app, true
+}
+
 // OptionalAuth はオプショナルな認証を行います（認証が失敗しても処理を続行）
-func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
+// scopes を指定した場合、キーがそのすべてのスコープを持つ場合のみアプリケーション情報を設定します
+func (m *AuthMiddleware) OptionalAuth(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := c.GetHeader("X-API-Key")
 		if apiKey == "" {
@@ -97,7 +126,7 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 			return
 		}
 
-		if app.Active {
+		if app.Active && hasScopes(app, scopes) {
 			c.Set("app_id", app.AppID)
 			c.Set("application", app)
 		}
@@ -105,3 +134,50 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminAPIKey は管理APIで apps:admin スコープのAPIキーによる認証を行います
+// X-API-Key ヘッダーがない場合は next（管理者認証）に処理を委ねます
+// 認証に成功した場合は、キーが属するアプリケーションのみ操作できる呼び出し元を "principal" として設定します
+// 署名の検証のため、認証したアプリケーションも "application" として設定します
+func (m *AuthMiddleware) AdminAPIKey(next gin.HandlerFunc) gin.HandlerFunc {
+	scopes := []string{domainmodels.ScopeAppsAdmin}
+
+	return func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") == "" {
+			next(c)
+			return
+		}
+
+		app, ok := m.authenticate(c, scopes)
+		if !ok {
+			return
+		}
+
+		c.Set("principal", &domainmodels.Principal{AppID: app.AppID})
+		c.Set("application", app)
+		m.logger.Debug("Admin API key authentication successful", "app_id", app.AppID, "path", c.Request.URL.Path)
+		c.Next()
+	}
+}
+
+// hasScopes は認証に使用したAPIキーがすべてのスコープを持つかどうかを判定します
+// スコープを指定しない場合は常に true を返します
+func hasScopes(app *domainmodels.Application, scopes []string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	return app.AuthenticatedKey != nil && app.AuthenticatedKey.HasScopes(scopes...)
+}
+
+// authenticatedApplication は認証ミドルウェアが設定したアプリケーションを取得します
+func authenticatedApplication(c *gin.Context) *domainmodels.Application {
+	value, exists := c.Get("application")
+	if !exists {
+		return nil
+	}
+	app, ok := value.(*domainmodels.Application)
+	if !ok || app == nil {
+		return nil
+	}
+	return app
+}
@@ -1,50 +1,121 @@
 package middleware
 
 import (
+	"context"
 	"strings"
+	"time"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	domainmodels "accesslog-tracker/internal/domain/models"
 )
 
+// ApplicationLookup はCORSの許可オリジンを解決するためにアプリケーションを取得するインターフェースです
+type ApplicationLookup interface {
+	GetByID(ctx context.Context, id string) (*domainmodels.Application, error)
+	GetByAPIKey(ctx context.Context, apiKey string) (*domainmodels.Application, error)
+}
+
+// CORSConfig はCORSミドルウェアの設定です
+type CORSConfig struct {
+	AllowedOrigins   []string      // すべてのアプリケーションで許可するオリジン（"*.example.com" でサブドメインを許可）
+	AllowedMethods   []string      // 許可するHTTPメソッド
+	AllowedHeaders   []string      // 許可するリクエストヘッダー
+	ExposedHeaders   []string      // クライアントに公開するレスポンスヘッダー
+	AllowCredentials bool          // 認証情報の送信を許可するか
+	MaxAge           time.Duration // プリフライトリクエストのキャッシュ時間
+}
+
+// DefaultCORSConfig はデフォルトのCORS設定を返します
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{
+			"http://localhost:3000",
+			"http://localhost:8080",
+		},
+		AllowedMethods: []string{
+			"GET",
+			"POST",
+			"PUT",
+			"DELETE",
+			"OPTIONS",
+		},
+		AllowedHeaders: []string{
+			"Origin",
+			"Content-Type",
+			"Accept",
+			"Authorization",
+			"X-API-Key",
+			"X-Requested-With",
+			SignatureHeader,
+			SignatureTimestampHeader,
+		},
+		ExposedHeaders: []string{
+			"Content-Length",
+			RateLimitLimitHeader,
+			RateLimitRemainingHeader,
+			RateLimitResetHeader,
+			RetryAfterHeader,
+		},
+		AllowCredentials: true,
+		MaxAge:           24 * time.Hour,
+	}
+}
+
 // CORS はCORSミドルウェアを設定します
-func CORS() gin.HandlerFunc {
-	config := cors.DefaultConfig()
-	
-	// 許可するオリジンを設定（静的許可）
-	config.AllowOrigins = []string{
-		"http://localhost:3000",
-		"http://localhost:8080",
-		"https://example.com",
+// 共通の許可オリジンに加えて、リクエストのアプリケーション（X-API-Key ヘッダーまたは app_id パラメータで特定）の
+// ドメインと allowed_origins 設定に一致するオリジンを許可します
+// applications が nil の場合は共通の許可オリジンのみ許可します
+func CORS(config CORSConfig, applications ApplicationLookup) gin.HandlerFunc {
+	allowlist := domainmodels.OriginAllowlist(trimValues(config.AllowedOrigins))
+
+	corsConfig := cors.Config{
+		AllowMethods:     trimValues(config.AllowedMethods),
+		AllowHeaders:     trimValues(config.AllowedHeaders),
+		ExposeHeaders:    trimValues(config.ExposedHeaders),
+		AllowCredentials: config.AllowCredentials,
+		MaxAge:           config.MaxAge,
+		AllowOriginWithContextFunc: func(c *gin.Context, origin string) bool {
+			if allowlist.Allows(origin) {
+				return true
+			}
+			app := lookupCORSApplication(c, applications)
+			return app != nil && app.IsActive() && app.AllowedOrigins().Allows(origin)
+		},
+	}
+
+	return cors.New(corsConfig)
+}
+
+// lookupCORSApplication はリクエストのアプリケーションを取得します（特定できない場合は nil）
+// プリフライトリクエストには X-API-Key が含まれないため、app_id パラメータでも特定します
+func lookupCORSApplication(c *gin.Context, applications ApplicationLookup) *domainmodels.Application {
+	if applications == nil {
+		return nil
 	}
-	// サブドメイン許可（*.example.com）
-	config.AllowOriginFunc = func(origin string) bool {
-		return strings.HasSuffix(origin, ".example.com") || origin == "https://example.com"
+
+	var (
+		app *domainmodels.Application
+		err error
+	)
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		app, err = applications.GetByAPIKey(c.Request.Context(), apiKey)
+	} else if appID := c.Query("app_id"); appID != "" {
+		app, err = applications.GetByID(c.Request.Context(), appID)
 	}
-	
-	// 許可するHTTPメソッドを設定
-	config.AllowMethods = []string{
-		"GET",
-		"POST",
-		"PUT",
-		"DELETE",
-		"OPTIONS",
+	if err != nil {
+		return nil
 	}
-	
-	// 許可するヘッダーを設定
-	config.AllowHeaders = []string{
-		"Origin",
-		"Content-Type",
-		"Accept",
-		"Authorization",
-		"X-API-Key",
-		"X-Requested-With",
+	return app
+}
+
+// trimValues は設定値の前後の空白を取り除き、空の値を除外します
+func trimValues(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
 	}
-	
-	// 認証情報の送信を許可
-	config.AllowCredentials = true
-	
-	// プリフライトリクエストのキャッシュ時間を設定
-	config.MaxAge = 86400 // 24時間
-	
-	return cors.New(config)
+	return trimmed
 }
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// maxLocalRateLimitKeys はプロセス内のレート制限で保持するキーの上限です
+const maxLocalRateLimitKeys = 100000
+
+// localRateLimitWindows は1分間・1時間のウィンドウの長さ（ミリ秒）です
+var localRateLimitWindows = [2]int64{time.Minute.Milliseconds(), time.Hour.Milliseconds()}
+
+// localWindow は固定ウィンドウの現在と直前のカウンターです
+type localWindow struct {
+	index    int64
+	current  int64
+	previous int64
+}
+
+// localRateLimitEntry はキーごとのカウンターとトークンバケットです
+type localRateLimitEntry struct {
+	windows  [2]localWindow
+	tokens   float64
+	updated  int64 // トークンバケットを最後に更新した時刻（ミリ秒）
+	bucket   bool  // トークンバケットを初期化したか
+	lastSeen int64
+}
+
+// LocalRateLimiter はプロセス内でリクエストを判定するレート制限です
+// Redisを利用できない間のフォールバックとして使い、判定方法はRedisのLuaスクリプトと同じです
+// 制限はインスタンスごとに数えるため、複数のインスタンスでは全体の上限が緩くなります
+type LocalRateLimiter struct {
+	clock func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*localRateLimitEntry
+	lastSweep int64
+}
+
+// NewLocalRateLimiter は新しいプロセス内のレート制限を作成します
+func NewLocalRateLimiter(clock func() time.Time) *LocalRateLimiter {
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &LocalRateLimiter{
+		clock:   clock,
+		entries: make(map[string]*localRateLimitEntry),
+	}
+}
+
+// Allow はキーのリクエストを cost 件分受け付けられるかを limits の制限値で判定し、受け付ける場合は消費します
+func (l *LocalRateLimiter) Allow(key string, limits RateLimitConfig, cost int) RateLimitResult {
+	now := l.clock()
+	nowMs := now.UnixMilli()
+	minute := nowMs / localRateLimitWindows[0]
+
+	result := RateLimitResult{
+		Allowed: true,
+		Limit:   limits.RequestsPerMinute,
+		Reset:   time.UnixMilli((minute + 1) * localRateLimitWindows[0]),
+	}
+	if result.Limit <= 0 {
+		result.Limit = limits.RequestsPerHour
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := l.entry(key, nowMs)
+	remaining := int64(-1)
+	updateRemaining := func(value int64) {
+		if value < 0 {
+			value = 0
+		}
+		if remaining < 0 || value < remaining {
+			remaining = value
+		}
+	}
+
+	var retry int64
+	var used [2]int64
+	for i, limit := range [2]int{limits.RequestsPerMinute, limits.RequestsPerHour} {
+		if limit <= 0 {
+			continue
+		}
+		window := localRateLimitWindows[i]
+		entry.windows[i].rotate(nowMs / window)
+		current, previous := entry.windows[i].current, entry.windows[i].previous
+		elapsed := nowMs % window
+		count := previous*(window-elapsed)/window + current
+		used[i] = count
+		if count+int64(cost) > int64(limit) {
+			result.Allowed = false
+			// 直前のウィンドウの件数が十分に減るまで、または現在のウィンドウが終わるまで待つ
+			wait := window - elapsed
+			free := int64(limit) - current - int64(cost)
+			if free >= 0 && previous > 0 {
+				target := int64(math.Ceil(float64(window) * (1 - float64(free)/float64(previous))))
+				if target > elapsed && target-elapsed < wait {
+					wait = target - elapsed
+				}
+			}
+			if wait > retry {
+				retry = wait
+			}
+		}
+	}
+
+	var rate float64
+	if limits.RequestsPerMinute > 0 {
+		rate = float64(limits.RequestsPerMinute) / float64(localRateLimitWindows[0])
+	} else if limits.RequestsPerHour > 0 {
+		rate = float64(limits.RequestsPerHour) / float64(localRateLimitWindows[1])
+	}
+
+	useBucket := limits.BurstSize > 0 && rate > 0
+	if useBucket {
+		burst := float64(limits.BurstSize)
+		if !entry.bucket {
+			entry.tokens, entry.updated, entry.bucket = burst, nowMs, true
+		} else if nowMs > entry.updated {
+			entry.tokens = math.Min(burst, entry.tokens+float64(nowMs-entry.updated)*rate)
+			entry.updated = nowMs
+		}
+		need := math.Min(float64(cost), burst)
+		if entry.tokens < need {
+			result.Allowed = false
+			if wait := int64(math.Ceil((need - entry.tokens) / rate)); wait > retry {
+				retry = wait
+			}
+		}
+	}
+
+	for i, limit := range [2]int{limits.RequestsPerMinute, limits.RequestsPerHour} {
+		if limit <= 0 {
+			continue
+		}
+		if result.Allowed {
+			entry.windows[i].current += int64(cost)
+			updateRemaining(int64(limit) - used[i] - int64(cost))
+		} else {
+			updateRemaining(int64(limit) - used[i])
+		}
+	}
+	if useBucket {
+		if result.Allowed {
+			entry.tokens -= float64(cost)
+		}
+		updateRemaining(int64(math.Floor(entry.tokens)))
+	}
+
+	result.Remaining = int(remaining)
+	result.RetryAfter = time.Duration(retry) * time.Millisecond
+	return result
+}
+
+// entry はキーのエントリを取得し、なければ作成します
+// 1分ごとに2時間以上使われていないエントリを削除し、上限を超える場合は任意のエントリを削除します
+func (l *LocalRateLimiter) entry(key string, nowMs int64) *localRateLimitEntry {
+	if nowMs-l.lastSweep >= localRateLimitWindows[0] {
+		for k, e := range l.entries {
+			if nowMs-e.lastSeen >= 2*localRateLimitWindows[1] {
+				delete(l.entries, k)
+			}
+		}
+		l.lastSweep = nowMs
+	}
+
+	entry, ok := l.entries[key]
+	if !ok {
+		if len(l.entries) >= maxLocalRateLimitKeys {
+			for k := range l.entries {
+				delete(l.entries, k)
+				break
+			}
+		}
+		entry = &localRateLimitEntry{}
+		l.entries[key] = entry
+	}
+	entry.lastSeen = nowMs
+	return entry
+}
+
+// rotate は現在のウィンドウが index になるようカウンターを進めます
+func (w *localWindow) rotate(index int64) {
+	switch {
+	case w.index == index:
+	case w.index+1 == index:
+		w.previous, w.current = w.current, 0
+	default:
+		w.previous, w.current = 0, 0
+	}
+	w.index = index
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"accesslog-tracker/internal/api/models"
+	domainmodels "accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaService は月間のイベント数の上限を判定するサービスのインターフェースです
+type QuotaService interface {
+	ReserveQuota(ctx context.Context, app *domainmodels.Application, events int) (*domainmodels.Usage, error)
+	CommitQuota(ctx context.Context, usage *domainmodels.Usage, reserved, accepted int) error
+}
+
+// QuotaMiddleware は月間のイベント数の上限を適用するミドルウェアの構造体です
+type QuotaMiddleware struct {
+	quotas QuotaService
+	logger logger.Logger
+}
+
+// NewQuotaMiddleware は新しい月間のイベント数の上限のミドルウェアを作成します
+func NewQuotaMiddleware(quotas QuotaService, logger logger.Logger) *QuotaMiddleware {
+	return &QuotaMiddleware{
+		quotas: quotas,
+		logger: logger,
+	}
+}
+
+// Quota は認証したアプリケーションのプランの月間のイベント数の上限を適用します
+// リクエストのイベント数を確保してからハンドラーを実行し、上限を超える場合はバッチ全体を拒否します
+// 処理後にハンドラーが "accepted_events" に設定した受け付けたイベント数だけを使用量として確定します
+// 上限を超えた場合は 429（QUOTA_EXCEEDED）と、次の集計期間までの秒数を Retry-After ヘッダーで返します
+// 使用量を記録できない場合はリクエストを拒否せずに処理を続けます
+func (m *QuotaMiddleware) Quota() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		app := authenticatedApplication(c)
+		if app == nil {
+			c.Next()
+			return
+		}
+
+		events := c.GetInt("rate_limit_cost")
+		if events <= 0 {
+			events = 1
+		}
+
+		usage, err := m.quotas.ReserveQuota(c.Request.Context(), app, events)
+		if errors.Is(err, domainmodels.ErrQuotaExceeded) {
+			m.logger.Warn("Monthly event quota exceeded", "app_id", app.AppID, "plan", usage.Plan, "events", usage.Events, "quota", usage.Quota)
+			c.Header(RetryAfterHeader, strconv.Itoa(retryAfterSeconds(time.Until(usage.PeriodEnd))))
+			c.JSON(http.StatusTooManyRequests, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "QUOTA_EXCEEDED",
+					Message: "Monthly event quota exceeded for the current plan",
+					Details: fmt.Sprintf("%d of %d events used in %s", usage.Events, usage.Quota, usage.PeriodStart.Format("2006-01")),
+				},
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			m.logger.Error("Failed to record usage", "error", err.Error(), "app_id", app.AppID)
+			c.Next()
+			return
+		}
+
+		// 検証エラー・オリジン拒否・ボットとして破棄・キューに入らなかったイベントは使用量に数えない
+		// ハンドラーがパニックした場合も確保した分を解放する
+		defer func() {
+			accepted := c.GetInt("accepted_events")
+			if err := m.quotas.CommitQuota(context.WithoutCancel(c.Request.Context()), usage, events, accepted); err != nil {
+				m.logger.Error("Failed to commit quota", "error", err.Error(), "app_id", app.AppID, "events", events, "accepted", accepted)
+			}
+		}()
+
+		c.Next()
+	}
+}
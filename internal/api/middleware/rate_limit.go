@@ -1,43 +1,83 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"accesslog-tracker/internal/api/models"
+	domainmodels "accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/utils/iputil"
 	"accesslog-tracker/internal/utils/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
-	"golang.org/x/net/context"
+)
+
+// レート制限のレスポンスヘッダー
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// Redisを利用できない場合のレート制限の動作
+const (
+	RateLimitFailOpen   = "open"   // プロセス内のレート制限で判定して処理を続ける
+	RateLimitFailClosed = "closed" // 503 を返してリクエストを拒否する
 )
 
 // RateLimitConfig はレート制限の設定です
+// 0 以下の制限値はその制限を適用しません
 type RateLimitConfig struct {
-	RequestsPerMinute int
-	RequestsPerHour   int
-	BurstSize         int
+	RequestsPerMinute int              // 直近1分間（スライディングウィンドウ）のリクエスト数の上限
+	RequestsPerHour   int              // 直近1時間（スライディングウィンドウ）のリクエスト数の上限
+	BurstSize         int              // 連続して受け付けるリクエスト数の上限（トークンバケットの容量）
+	FailureMode       string           // Redisを利用できない場合の動作（RateLimitFailOpen・RateLimitFailClosed、空の場合は RateLimitFailOpen）
+	Available         func() bool      // Redisを利用できるか（nil の場合は常に利用できるとみなし、エラー時のみフォールバックする）
+	Clock             func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// RateLimitResult はレート制限の判定結果です
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int           // X-RateLimit-Limit に返す上限（1分間の上限、未設定の場合は1時間の上限）
+	Remaining  int           // 受け付けられる残りのリクエスト数（すべての制限の最小値）
+	Reset      time.Time     // 現在の1分間のウィンドウが終わる時刻
+	RetryAfter time.Duration // 拒否した場合に再試行できるまでの時間
 }
 
 // RateLimitMiddleware はレート制限ミドルウェアの構造体です
 type RateLimitMiddleware struct {
-	redisClient *redis.Client
+	redisClient redis.Scripter
+	local       *LocalRateLimiter
 	logger      logger.Logger
 	config      RateLimitConfig
 }
 
 // NewRateLimitMiddleware は新しいレート制限ミドルウェアを作成します
-func NewRateLimitMiddleware(redisClient *redis.Client, logger logger.Logger, config RateLimitConfig) *RateLimitMiddleware {
+func NewRateLimitMiddleware(redisClient redis.Scripter, logger logger.Logger, config RateLimitConfig) *RateLimitMiddleware {
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
 	return &RateLimitMiddleware{
 		redisClient: redisClient,
+		local:       NewLocalRateLimiter(config.Clock),
 		logger:      logger,
 		config:      config,
 	}
 }
 
 // RateLimit はレート制限を適用します
+// 認証済みのリクエストはアプリケーション全体にプランの制限を、それ以外はクライアントIPごとに設定の制限を適用します
+// すべてのレスポンスに X-RateLimit-* ヘッダーを、制限を超えた場合は Retry-After ヘッダーを付けます
+// Redisを利用できない場合は FailureMode に従い、プロセス内のレート制限で判定するか 503 を返します
 func (m *RateLimitMiddleware) RateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// ヘッダーをマップに変換
@@ -53,11 +93,42 @@ func (m *RateLimitMiddleware) RateLimit() gin.HandlerFunc {
 			appID = "anonymous"
 		}
 
-		minuteKey := fmt.Sprintf("rate_limit:%s:%s:minute", appID, clientIP)
-		ctx := context.Background()
+		// バッチリクエストではイベント数をコストとして消費する
+		cost := c.GetInt("rate_limit_cost")
+		if cost <= 0 {
+			cost = 1
+		}
+
+		// キーのうちハッシュタグ（{}）の部分で、Redis Cluster のスロットが決まる
+		key := fmt.Sprintf("rate_limit:{%s:%s}", appID, clientIP)
+		limits := m.config
+		if app := authenticatedApplication(c); app != nil {
+			key = "rate_limit:{app:" + app.AppID + "}"
+			limits = PlanRateLimitConfig(app.PlanLimits())
+		}
 
-		if err := m.checkRateLimit(ctx, minuteKey, m.config.RequestsPerMinute, time.Minute); err != nil {
+		result, ok := m.check(c.Request.Context(), key, limits, cost)
+		if !ok {
+			c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "SERVICE_UNAVAILABLE",
+					Message: "Rate limit could not be checked. Please try again later.",
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		if result.Limit > 0 {
+			c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+			c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+			c.Header(RateLimitResetHeader, strconv.FormatInt(result.Reset.Unix(), 10))
+		}
+
+		if !result.Allowed {
 			m.logger.Warnf("Rate limit exceeded for app_id: %s, ip: %s", appID, clientIP)
+			c.Header(RetryAfterHeader, strconv.Itoa(retryAfterSeconds(result.RetryAfter)))
 			c.JSON(http.StatusTooManyRequests, models.APIResponse{
 				Success: false,
 				Error: &models.APIError{
@@ -73,24 +144,213 @@ func (m *RateLimitMiddleware) RateLimit() gin.HandlerFunc {
 	}
 }
 
-func (m *RateLimitMiddleware) checkRateLimit(ctx context.Context, key string, limit int, window time.Duration) error {
-	current, err := m.redisClient.Get(ctx, key).Int()
-	if err != nil && err != redis.Nil {
-		return err
+// Allow はキーのリクエストを cost 件分受け付けられるかを判定し、受け付ける場合は消費します
+// 判定と消費はLuaスクリプトで不可分に行います
+// key にハッシュタグ（{}）がない場合は、キー全体をハッシュタグとして Redis Cluster のスロットを決めます
+func (m *RateLimitMiddleware) Allow(ctx context.Context, key string, cost int) (RateLimitResult, error) {
+	return m.allow(ctx, key, m.config, cost)
+}
+
+// check は Redis でキーのリクエストを判定します
+// Redisを利用できない場合、FailureMode が RateLimitFailClosed なら false を、それ以外はプロセス内のレート制限の結果を返します
+func (m *RateLimitMiddleware) check(ctx context.Context, key string, limits RateLimitConfig, cost int) (RateLimitResult, bool) {
+	if m.config.Available == nil || m.config.Available() {
+		result, err := m.allow(ctx, key, limits, cost)
+		if err == nil {
+			return result, true
+		}
+		m.logger.Error("Failed to check rate limit", "error", err.Error(), "key", key)
+	}
+
+	if m.config.FailureMode == RateLimitFailClosed {
+		return RateLimitResult{}, false
+	}
+	return m.local.Allow(key, limits, cost), true
+}
+
+// allow は limits の制限値でキーのリクエストを判定します
+func (m *RateLimitMiddleware) allow(ctx context.Context, key string, limits RateLimitConfig, cost int) (RateLimitResult, error) {
+	now := m.config.Clock()
+	nowMs := now.UnixMilli()
+	minute := nowMs / time.Minute.Milliseconds()
+	hour := nowMs / time.Hour.Milliseconds()
+
+	result := RateLimitResult{
+		Allowed: true,
+		Limit:   limits.RequestsPerMinute,
+		Reset:   time.UnixMilli((minute + 1) * time.Minute.Milliseconds()),
+	}
+	if result.Limit <= 0 {
+		result.Limit = limits.RequestsPerHour
 	}
 
-	if current >= limit {
-		return fmt.Errorf("rate limit exceeded")
+	// スクリプトで扱うカウンターとトークンバケットは、Redis Cluster の同じスロットに置く必要がある
+	key = rateLimitHashTag(key)
+	keys := []string{
+		fmt.Sprintf("%s:m:%d", key, minute),
+		fmt.Sprintf("%s:m:%d", key, minute-1),
+		fmt.Sprintf("%s:h:%d", key, hour),
+		fmt.Sprintf("%s:h:%d", key, hour-1),
+		key + ":b",
+	}
+	values, err := rateLimitScript.Run(ctx, m.redisClient, keys,
+		nowMs, cost, limits.RequestsPerMinute, limits.RequestsPerHour, limits.BurstSize,
+	).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("failed to run rate limit script: %w", err)
 	}
+	if len(values) != 3 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	result.Allowed = values[0] == 1
+	result.Remaining = int(values[1])
+	result.RetryAfter = time.Duration(values[2]) * time.Millisecond
+	return result, nil
+}
 
-	pipe := m.redisClient.Pipeline()
-	pipe.Incr(ctx, key)
-	pipe.Expire(ctx, key, window)
+// rateLimitHashTag はキーにハッシュタグ（{}）がない場合、キー全体をハッシュタグにして返します
+func rateLimitHashTag(key string) string {
+	if strings.Contains(key, "{") {
+		return key
+	}
+	return "{" + key + "}"
+}
 
-	_, err = pipe.Exec(ctx)
-	return err
+// retryAfterSeconds は Retry-After ヘッダーの秒数を返します（切り上げ、最小1秒）
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// rateLimitScript は1分間・1時間のスライディングウィンドウとトークンバケットでリクエストを判定するLuaスクリプトです
+//
+// スライディングウィンドウは現在と直前の固定ウィンドウのカウンターから、直前のウィンドウを経過時間の割合で減らして件数を見積もります
+// トークンバケットは容量 BurstSize で、1分間の上限（未設定の場合は1時間の上限）の速度で補充します
+// 容量を超えるコストはバケットが満杯の場合のみ受け付け、超えた分は以降の補充で返済します
+//
+// KEYS: 1分間の現在・直前のカウンター、1時間の現在・直前のカウンター、トークンバケット
+// ARGV: 現在時刻（ミリ秒）、コスト、1分間の上限、1時間の上限、バースト
+// 戻り値: {受け付けたか（1/0）, 残りのリクエスト数（制限がない場合は -1）, 再試行までのミリ秒}
+var rateLimitScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local cost = tonumber(ARGV[2])
+local limits = {tonumber(ARGV[3]), tonumber(ARGV[4])}
+local windows = {60000, 3600000}
+local burst = tonumber(ARGV[5])
+
+local allowed = 1
+local remaining = -1
+local retry = 0
+local used = {}
+
+local function update_remaining(value)
+  if value < 0 then
+    value = 0
+  end
+  if remaining < 0 or value < remaining then
+    remaining = value
+  end
+end
+
+for i = 1, 2 do
+  local limit = limits[i]
+  if limit > 0 then
+    local window = windows[i]
+    local current = tonumber(redis.call('GET', KEYS[2 * i - 1]) or '0')
+    local previous = tonumber(redis.call('GET', KEYS[2 * i]) or '0')
+    local elapsed = now % window
+    local count = math.floor(previous * (window - elapsed) / window) + current
+    used[i] = count
+    if count + cost > limit then
+      allowed = 0
+      -- 直前のウィンドウの件数が十分に減るまで、または現在のウィンドウが終わるまで待つ
+      local wait = window - elapsed
+      local free = limit - current - cost
+      if free >= 0 and previous > 0 then
+        local target = math.ceil(window * (1 - free / previous))
+        if target > elapsed and target - elapsed < wait then
+          wait = target - elapsed
+        end
+      end
+      if wait > retry then
+        retry = wait
+      end
+    end
+  end
+end
+
+local rate = 0
+if limits[1] > 0 then
+  rate = limits[1] / windows[1]
+elseif limits[2] > 0 then
+  rate = limits[2] / windows[2]
+end
+
+local tokens = nil
+local updated = now
+if burst > 0 and rate > 0 then
+  local bucket = redis.call('HMGET', KEYS[5], 'tokens', 'ts')
+  tokens = tonumber(bucket[1])
+  local ts = tonumber(bucket[2])
+  if tokens == nil or ts == nil then
+    tokens = burst
+  elseif now > ts then
+    tokens = math.min(burst, tokens + (now - ts) * rate)
+  else
+    updated = ts
+  end
+  local need = math.min(cost, burst)
+  if tokens < need then
+    allowed = 0
+    local wait = math.ceil((need - tokens) / rate)
+    if wait > retry then
+      retry = wait
+    end
+  end
+end
+
+if allowed == 1 then
+  for i = 1, 2 do
+    if limits[i] > 0 then
+      redis.call('INCRBY', KEYS[2 * i - 1], cost)
+      redis.call('PEXPIRE', KEYS[2 * i - 1], windows[i] * 2)
+      update_remaining(limits[i] - used[i] - cost)
+    end
+  end
+  if tokens ~= nil then
+    tokens = tokens - cost
+    redis.call('HSET', KEYS[5], 'tokens', tostring(tokens), 'ts', tostring(updated))
+    redis.call('PEXPIRE', KEYS[5], math.ceil((burst - tokens) / rate) + 1000)
+    update_remaining(math.floor(tokens))
+  end
+else
+  for i = 1, 2 do
+    if limits[i] > 0 then
+      update_remaining(limits[i] - used[i])
+    end
+  end
+  if tokens ~= nil then
+    update_remaining(math.floor(tokens))
+  end
+end
+
+return {allowed, remaining, retry}
+`)
+
+// PlanRateLimitConfig はプランの制限値をレート制限設定に変換します
+func PlanRateLimitConfig(plan domainmodels.Plan) RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerMinute: plan.RequestsPerMinute,
+		RequestsPerHour:   plan.RequestsPerHour,
+		BurstSize:         plan.BurstSize,
+	}
 }
 
+// DefaultRateLimitConfig はデフォルトのレート制限設定を返します
 func DefaultRateLimitConfig() RateLimitConfig {
 	return RateLimitConfig{
 		RequestsPerMinute: 2000,  // 増加
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"accesslog-tracker/internal/api/models"
+	domainmodels "accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/crypto"
+	"accesslog-tracker/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// リクエスト署名のヘッダー
+const (
+	// SignatureHeader はリクエストのHMAC-SHA256署名（16進表記、"sha256=" の接頭辞は省略可）のヘッダーです
+	SignatureHeader = "X-ALT-Signature"
+	// SignatureTimestampHeader は署名したUNIX時刻（秒）のヘッダーです
+	SignatureTimestampHeader = "X-ALT-Timestamp"
+)
+
+// NonceStore は使用済みの署名を記録するストアのインターフェースです（*redis.Client が実装します）
+type NonceStore interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+}
+
+// SignatureConfig はリクエスト署名の検証の設定です
+type SignatureConfig struct {
+	MaxSkew time.Duration    // タイムスタンプとサーバー時刻の許容差
+	Clock   func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// DefaultSignatureConfig はデフォルトのリクエスト署名の検証の設定を返します
+func DefaultSignatureConfig() SignatureConfig {
+	return SignatureConfig{
+		MaxSkew: 5 * time.Minute,
+	}
+}
+
+// SignatureMiddleware はリクエスト署名の検証ミドルウェアの構造体です
+type SignatureMiddleware struct {
+	nonces NonceStore
+	logger logger.Logger
+	config SignatureConfig
+}
+
+// NewSignatureMiddleware は新しいリクエスト署名の検証ミドルウェアを作成します
+func NewSignatureMiddleware(nonces NonceStore, logger logger.Logger, config SignatureConfig) *SignatureMiddleware {
+	if config.MaxSkew <= 0 {
+		config.MaxSkew = DefaultSignatureConfig().MaxSkew
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+	return &SignatureMiddleware{
+		nonces: nonces,
+		logger: logger,
+		config: config,
+	}
+}
+
+// Verify はリクエスト署名を検証します
+// 認証ミドルウェアの後に適用し、署名シークレットを持つAPIキーで認証したリクエストのみ検証します
+// 署名対象はメソッド、パス（クエリを含む）、タイムスタンプ、ボディで、タイムスタンプが許容差を超えたリクエストと
+// 同じ署名の再送は拒否します
+func (m *SignatureMiddleware) Verify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := authenticatedKey(c)
+		if key == nil || !key.RequiresSignature() {
+			c.Next()
+			return
+		}
+
+		signature := c.GetHeader(SignatureHeader)
+		timestamp := c.GetHeader(SignatureTimestampHeader)
+		if signature == "" || timestamp == "" {
+			m.reject(c, key, "SIGNATURE_REQUIRED", "Request signature is required")
+			return
+		}
+
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			m.reject(c, key, "SIGNATURE_INVALID", "Invalid request signature timestamp")
+			return
+		}
+		if skew := m.config.Clock().Sub(time.Unix(seconds, 0)); skew > m.config.MaxSkew || skew < -m.config.MaxSkew {
+			m.reject(c, key, "SIGNATURE_EXPIRED", "Request signature timestamp is outside the allowed window")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxBatchBodySize))
+		if err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "PAYLOAD_TOO_LARGE",
+					Message: "Request body is too large",
+					Details: err.Error(),
+				},
+			})
+			c.Abort()
+			return
+		}
+		// 後続のミドルウェアとハンドラーが再度読めるようにボディを戻す
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !crypto.VerifyRequestSignature(key.SigningSecret, c.Request.Method, c.Request.URL.RequestURI(), timestamp, body, signature) {
+			m.reject(c, key, "SIGNATURE_INVALID", "Invalid request signature")
+			return
+		}
+
+		// 許容差の間に同じ署名が再送されないよう、署名を許容差の2倍の期間だけ記録する
+		// 大文字や "sha256=" の接頭辞を付けた同じ署名も再送とみなすよう、サーバーで計算した署名で記録する
+		if m.nonces != nil {
+			expected := crypto.SignRequest(key.SigningSecret, c.Request.Method, c.Request.URL.RequestURI(), timestamp, body)
+			nonceKey := "sig:nonce:" + key.ID + ":" + expected
+			stored, err := m.nonces.SetNX(c.Request.Context(), nonceKey, timestamp, 2*m.config.MaxSkew).Result()
+			if err != nil {
+				m.logger.Error("Failed to record request signature", "error", err.Error(), "app_id", key.AppID)
+				c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+					Success: false,
+					Error: &models.APIError{
+						Code:    "SERVICE_UNAVAILABLE",
+						Message: "Request signature could not be verified",
+					},
+				})
+				c.Abort()
+				return
+			}
+			if !stored {
+				m.reject(c, key, "SIGNATURE_REPLAYED", "Request signature has already been used")
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// reject は署名の検証に失敗したリクエストに 401 を返します
+func (m *SignatureMiddleware) reject(c *gin.Context, key *domainmodels.APIKey, code, message string) {
+	m.logger.Warn("Request signature verification failed", "app_id", key.AppID, "key_id", key.ID, "code", code)
+	c.JSON(http.StatusUnauthorized, models.APIResponse{
+		Success: false,
+		Error: &models.APIError{
+			Code:    code,
+			Message: message,
+		},
+	})
+	c.Abort()
+}
+
+// authenticatedKey は認証ミドルウェアが設定したアプリケーションから認証に使用したAPIキーを取得します
+func authenticatedKey(c *gin.Context) *domainmodels.APIKey {
+	app := authenticatedApplication(c)
+	if app == nil {
+		return nil
+	}
+	return app.AuthenticatedKey
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"accesslog-tracker/internal/api/models"
+)
+
+// MaxBatchBodySize はバッチトラッキングで受け付けるリクエストボディの最大サイズです
+const MaxBatchBodySize = 5 << 20
+
+// TrackingBatch はバッチトラッキングのリクエストを事前に解析するミドルウェアです
+// 解析したイベントを "tracking_events" に、イベント数をレート制限のコスト "rate_limit_cost" に設定します
+// 解析に失敗した場合は何も設定せず、エラーの応答はハンドラーに任せます
+func TrackingBatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxBatchBodySize))
+		if err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "PAYLOAD_TOO_LARGE",
+					Message: "Request body is too large",
+					Details: err.Error(),
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		// ハンドラーが再度読めるようにボディを戻す
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if events, err := models.ParseBatchTrackingRequest(body); err == nil {
+			c.Set("tracking_events", events)
+			c.Set("rate_limit_cost", len(events))
+		}
+
+		c.Next()
+	}
+}
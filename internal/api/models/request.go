@@ -1,9 +1,23 @@
 package models
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 )
 
+// MaxBatchTrackingEvents はバッチトラッキングで1リクエストに含められる最大イベント数です
+const MaxBatchTrackingEvents = 500
+
+// バッチトラッキングリクエストのエラー
+var (
+	ErrEmptyBatch    = errors.New("batch must contain at least one event")
+	ErrBatchTooLarge = fmt.Errorf("batch must not contain more than %d events", MaxBatchTrackingEvents)
+)
+
 // TrackingRequest はトラッキングAPIのリクエスト構造体です
 type TrackingRequest struct {
 	AppID       string                 `json:"app_id" binding:"required"`
@@ -15,12 +29,59 @@ type TrackingRequest struct {
 	CustomParams map[string]interface{} `json:"custom_params"`
 }
 
+// ParseBatchTrackingRequest はJSON配列またはNDJSON形式のバッチリクエストを解析します
+// 本文が '[' で始まる場合はJSON配列、それ以外は1行1イベントのNDJSONとして扱います
+func ParseBatchTrackingRequest(body []byte) ([]TrackingRequest, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	var events []TrackingRequest
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &events); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			raw := bytes.TrimSpace(scanner.Bytes())
+			if len(raw) == 0 {
+				continue
+			}
+			if len(events) >= MaxBatchTrackingEvents {
+				return nil, ErrBatchTooLarge
+			}
+			var event TrackingRequest
+			if err := json.Unmarshal(raw, &event); err != nil {
+				return nil, fmt.Errorf("invalid NDJSON at line %d: %w", line, err)
+			}
+			events = append(events, event)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read NDJSON: %w", err)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(events) > MaxBatchTrackingEvents {
+		return nil, ErrBatchTooLarge
+	}
+
+	return events, nil
+}
+
 // StatisticsRequest は統計APIのリクエスト構造体です
 type StatisticsRequest struct {
 	AppID     string `json:"app_id" binding:"required"`
 	StartDate string `json:"start_date" binding:"required"`
 	EndDate   string `json:"end_date" binding:"required"`
-	GroupBy   string `json:"group_by"` // "day", "hour", "page", "referrer"
+	GroupBy   string `json:"group_by"` // "day", "hour", "page", "referrer", "browser", "os", "device", "country", "channel", "campaign", "page_group"
 	Limit     int    `json:"limit"`    // 結果の制限数
 }
 
@@ -29,6 +90,8 @@ type ApplicationRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
 	Domain      string `json:"domain" binding:"required"`
+	Timezone    string `json:"timezone"` // IANAタイムゾーン名（省略時はUTC）
+	OrganizationID string `json:"organization_id"` // 所有する組織（スーパーユーザー以外は必須）
 }
 
 // ApplicationUpdateRequest はアプリケーション更新APIのリクエスト構造体です
@@ -36,9 +99,54 @@ type ApplicationUpdateRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Domain      string `json:"domain"`
+	Timezone    string `json:"timezone"`
 	Active      *bool  `json:"active"`
 }
 
+// OrganizationRequest は組織作成APIのリクエスト構造体です
+type OrganizationRequest struct {
+	Name        string `json:"name" binding:"required"`
+	OwnerUserID string `json:"owner_user_id"` // 組織のオーナーにするユーザー
+}
+
+// UserRequest はユーザー作成APIのリクエスト構造体です
+type UserRequest struct {
+	Email string `json:"email" binding:"required"`
+	Name  string `json:"name"`
+}
+
+// MembershipRequest は組織メンバーのロール設定APIのリクエスト構造体です
+type MembershipRequest struct {
+	Role string `json:"role" binding:"required"` // owner, admin, viewer
+}
+
+// APIKeyRequest はAPIキー発行APIのリクエスト構造体です
+type APIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes"`     // 許可する操作（省略時は ingest のみ）
+	ExpiresAt *time.Time `json:"expires_at"` // 有効期限（省略時は無期限）
+	Signed    bool       `json:"signed"`     // リクエスト署名を必須にするか（署名シークレットを発行する）
+}
+
+// APIKeyRotationRequest はAPIキーローテーションAPIのリクエスト構造体です
+type APIKeyRotationRequest struct {
+	GracePeriodSeconds *int64 `json:"grace_period_seconds"` // 以前のキーを有効なままにする秒数（省略時はサーバーの設定値）
+}
+
+// MaxURLRulesPreviewURLs はページURLの正規化のプレビューで1リクエストに含められる最大URL数です
+const MaxURLRulesPreviewURLs = 100
+
+// URLRulesPreviewRequest はページURLの正規化のプレビューAPIのリクエスト構造体です
+type URLRulesPreviewRequest struct {
+	URLs     []string               `json:"urls" binding:"required,min=1"`
+	URLRules map[string]interface{} `json:"url_rules"` // 試すルール（省略時は保存済みの url_rules 設定）
+}
+
+// PlanRequest はプラン変更APIのリクエスト構造体です
+type PlanRequest struct {
+	Plan string `json:"plan" binding:"required"` // free, pro, enterprise
+}
+
 // PaginationRequest はページネーション用のリクエスト構造体です
 type PaginationRequest struct {
 	Page     int `json:"page" form:"page"`
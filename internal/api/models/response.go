@@ -25,6 +25,25 @@ type TrackingResponse struct {
 	AppID      string    `json:"app_id"`
 	SessionID  string    `json:"session_id"`
 	Timestamp  time.Time `json:"timestamp"`
+	// Dropped は bot_policy が drop のアプリケーションでボットと判定し、保存しなかったことを表します
+	Dropped bool `json:"dropped,omitempty"`
+}
+
+// BatchTrackingResponse はバッチトラッキングAPIのレスポンス構造体です
+type BatchTrackingResponse struct {
+	Accepted int                       `json:"accepted"`
+	Rejected int                       `json:"rejected"`
+	Dropped  int                       `json:"dropped"` // ボットと判定して保存しなかったイベント数
+	Results  []BatchTrackingItemResult `json:"results"`
+}
+
+// BatchTrackingItemResult はバッチ内の各イベントの処理結果です
+type BatchTrackingItemResult struct {
+	Index      int       `json:"index"`
+	Success    bool      `json:"success"`
+	TrackingID string    `json:"tracking_id,omitempty"`
+	Dropped    bool      `json:"dropped,omitempty"`
+	Error      *APIError `json:"error,omitempty"`
 }
 
 // StatisticsResponse は統計APIのレスポンス構造体です
@@ -34,8 +53,23 @@ type StatisticsResponse struct {
 	EndDate        time.Time `json:"end_date"`
 	TotalRequests  int64     `json:"total_requests"`
 	UniqueVisitors int64     `json:"unique_visitors"`
+	UniqueSessions int64     `json:"unique_sessions"`
+	BotRequests    int64     `json:"bot_requests"`
+	MobileRequests int64     `json:"mobile_requests"`
+	AverageSessionDuration float64 `json:"average_session_duration"`
 	TopPages       []PageStats `json:"top_pages"`
 	TopReferrers   []ReferrerStats `json:"top_referrers"`
+	GroupBy        string    `json:"group_by,omitempty"`
+	Groups         []GroupStats `json:"groups,omitempty"`
+	Approximate    bool      `json:"approximate,omitempty"`
+}
+
+// GroupStats はグループ化した統計の構造体です
+type GroupStats struct {
+	Key            string `json:"key"`
+	Requests       int64  `json:"requests"`
+	UniqueSessions int64  `json:"unique_sessions"`
+	UniqueVisitors int64  `json:"unique_visitors"`
 }
 
 // PageStats はページ統計の構造体です
@@ -50,20 +84,137 @@ type ReferrerStats struct {
 	Count    int64  `json:"count"`
 }
 
+// TimeSeriesResponse は時系列統計APIのレスポンス構造体です
+type TimeSeriesResponse struct {
+	AppID     string            `json:"app_id"`
+	Interval  string            `json:"interval"`
+	Timezone  string            `json:"timezone"`
+	StartDate time.Time         `json:"start_date"`
+	EndDate   time.Time         `json:"end_date"`
+	Points    []TimeSeriesPoint `json:"points"`
+}
+
+// TimeSeriesPoint は時系列統計の1バケットの構造体です
+type TimeSeriesPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	PageViews int64     `json:"page_views"`
+	Sessions  int64     `json:"sessions"`
+	Visitors  int64     `json:"visitors"`
+}
+
 // ApplicationResponse はアプリケーションAPIのレスポンス構造体です
+// APIキーは作成時とローテーション時のみ返します
 type ApplicationResponse struct {
 	AppID      string    `json:"app_id"`
 	Name       string    `json:"name"`
 	Description string   `json:"description"`
 	Domain     string    `json:"domain"`
-	APIKey     string    `json:"api_key"`
+	Timezone   string    `json:"timezone"`
+	APIKey     string    `json:"api_key,omitempty"`
+	Settings   map[string]interface{} `json:"settings,omitempty"`
+	Plan       string    `json:"plan,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// PlanResponse はプランのレスポンス構造体です（制限値が0の場合は制限なし）
+type PlanResponse struct {
+	Name              string `json:"name"`
+	RequestsPerMinute int    `json:"requests_per_minute"`
+	RequestsPerHour   int    `json:"requests_per_hour"`
+	BurstSize         int    `json:"burst_size"`
+	MonthlyEventQuota int64  `json:"monthly_event_quota"`
+}
+
+// UsageResponse は使用量APIのレスポンス構造体です
+type UsageResponse struct {
+	AppID       string       `json:"app_id"`
+	Plan        PlanResponse `json:"plan"`
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"`
+	Events      int64        `json:"events"`
+	Quota       int64        `json:"quota"`     // 0 の場合は上限なし
+	Remaining   int64        `json:"remaining"` // 上限なしの場合は -1
+}
+
+// URLRulesPreviewResponse はページURLの正規化のプレビューAPIのレスポンス構造体です
+type URLRulesPreviewResponse struct {
+	AppID    string                  `json:"app_id"`
+	URLRules URLRulesResponse        `json:"url_rules"` // 適用したルール（省略した項目はデフォルトの値）
+	Results  []URLRulesPreviewResult `json:"results"`
+}
+
+// URLRulesResponse はページURLの正規化のルールの構造体です
+type URLRulesResponse struct {
+	StripQuery     bool     `json:"strip_query"`
+	QueryAllowlist []string `json:"query_allowlist"`
+	DropFragment   bool     `json:"drop_fragment"`
+	LowercaseHost  bool     `json:"lowercase_host"`
+	TrailingSlash  string   `json:"trailing_slash"`
+	PathGroups     []string `json:"path_groups"`
+}
+
+// URLRulesPreviewResult はプレビューの各URLの正規化の結果です
+type URLRulesPreviewResult struct {
+	URL       string `json:"url"`
+	PagePath  string `json:"page_path"`
+	PageGroup string `json:"page_group"`
+}
+
+// APIKeyResponse はAPIキーのレスポンス構造体です
+// キー本体（Key）と署名シークレット（SigningSecret）は発行時のみ返します
+type APIKeyResponse struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Prefix        string     `json:"prefix"`
+	Key           string     `json:"key,omitempty"`
+	Scopes        []string   `json:"scopes"`
+	Signed        bool       `json:"signed"`
+	SigningSecret string     `json:"signing_secret,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// SigningSecretResponse は署名シークレット発行APIのレスポンス構造体です
+// シークレットは保存後に取得できないため、このレスポンスでのみ返します
+type SigningSecretResponse struct {
+	KeyID         string `json:"key_id"`
+	SigningSecret string `json:"signing_secret"`
+}
+
+// APIKeyRotationResponse はAPIキーローテーションAPIのレスポンス構造体です
+// 新しいキーに加えて、猶予期間の終わりを有効期限とした以前のキーを返します
+type APIKeyRotationResponse struct {
+	APIKeyResponse
+	PreviousKey APIKeyResponse `json:"previous_key"`
+}
+
 // HealthResponse はヘルスチェックAPIのレスポンス構造体です
 type HealthResponse struct {
 	Status    string            `json:"status"`
 	Timestamp time.Time         `json:"timestamp"`
 	Services  map[string]string `json:"services"`
+	Ingestion *IngestionStatus  `json:"ingestion,omitempty"`
+	Redis     *RedisStatus      `json:"redis,omitempty"`
+}
+
+// RedisStatus はRedis接続の状態を表す構造体です
+// 利用できない間、レート制限とアプリケーションのキャッシュはプロセス内で処理します
+type RedisStatus struct {
+	Available bool      `json:"available"`
+	Since     time.Time `json:"since"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// IngestionStatus はインジェストパイプラインの状態を表す構造体です
+type IngestionStatus struct {
+	QueueDepth    int   `json:"queue_depth"`
+	QueueCapacity int   `json:"queue_capacity"`
+	Workers       int   `json:"workers"`
+	Enqueued      int64 `json:"enqueued"`
+	Written       int64 `json:"written"`
+	Failed        int64 `json:"failed"`
+	Rejected      int64 `json:"rejected"`
 }
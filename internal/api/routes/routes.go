@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"accesslog-tracker/internal/api/handlers"
 	"accesslog-tracker/internal/api/middleware"
+	domainmodels "accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/domain/services"
 	"accesslog-tracker/internal/infrastructure/database/postgresql"
 	"accesslog-tracker/internal/infrastructure/cache/redis"
@@ -11,58 +12,99 @@ import (
 )
 
 // Setup はAPIルートを設定します
+// 戻り値のビーコンハンドラーは、シャットダウン時に処理中のビーコンヒットを待機するために使用します
 func Setup(
 	router *gin.Engine,
 	trackingService *services.TrackingService,
 	applicationService *services.ApplicationService,
+	organizationService *services.OrganizationService,
 	dbConn *postgresql.Connection,
 	redisConn *redis.CacheService,
+	adminAuthConfig middleware.AdminAuthConfig,
+	corsConfig middleware.CORSConfig,
+	rateLimitConfig middleware.RateLimitConfig,
 	log logger.Logger,
-) {
+) *handlers.BeaconHandler {
 	// ミドルウェアの設定
 	authMiddleware := middleware.NewAuthMiddleware(applicationService, log)
-	rateLimitMiddleware := middleware.NewRateLimitMiddleware(redisConn.GetClient(), log, middleware.DefaultRateLimitConfig())
+	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(adminAuthConfig, organizationService, log)
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(redisConn.GetClient(), log, rateLimitConfig)
+	signatureMiddleware := middleware.NewSignatureMiddleware(redisConn.GetClient(), log, middleware.DefaultSignatureConfig())
+	quotaMiddleware := middleware.NewQuotaMiddleware(applicationService, log)
 
-	// グローバルミドルウェア
-	router.Use(middleware.CORS())
+	// グローバルミドルウェア（CORSはアプリケーションごとの許可オリジンも解決する）
+	router.Use(middleware.CORS(corsConfig, applicationService))
 	router.Use(middleware.Logging(log))
 	router.Use(middleware.RequestLogging(log))
 	router.Use(middleware.ErrorHandler(log))
 	router.Use(middleware.ValidationErrorHandler())
 
 	// ヘルスチェックエンドポイント（認証不要）
-	healthHandler := handlers.NewHealthHandler(dbConn, redisConn, log)
+	healthHandler := handlers.NewHealthHandler(dbConn, redisConn, trackingService, log)
 	router.GET("/health", healthHandler.Health)
 	router.GET("/ready", healthHandler.Readiness)
 	router.GET("/live", healthHandler.Liveness)
 
+	// ビーコンハンドラー（/v1/beacon とルート直下の配信で共有）
+	beaconHandler := handlers.NewBeaconHandler(trackingService, applicationService, log)
+
 	// API v1 ルートグループ
 	v1 := router.Group("/v1")
 	{
 		// トラッキングエンドポイント（認証必須）
+		// 送信は公開用の ingest キー、統計の参照はサーバー側の stats:read キーで認証する
+		// 署名シークレットを持つキーで認証したリクエストは署名を検証する
 		trackingHandler := handlers.NewTrackingHandler(trackingService, log)
 		tracking := v1.Group("/tracking")
-		tracking.Use(authMiddleware.Authenticate())
-		tracking.Use(rateLimitMiddleware.RateLimit())
+		ingest := tracking.Group("", authMiddleware.Authenticate(domainmodels.ScopeIngest), signatureMiddleware.Verify())
+		{
+			// バッチはイベント数でレート制限と月間の上限を適用するため、解析後に適用する
+			ingest.POST("/track", rateLimitMiddleware.RateLimit(), quotaMiddleware.Quota(), trackingHandler.Track)
+			ingest.POST("/batch", middleware.TrackingBatch(), rateLimitMiddleware.RateLimit(), quotaMiddleware.Quota(), trackingHandler.TrackBatch)
+		}
+		statistics := tracking.Group("/statistics", authMiddleware.Authenticate(domainmodels.ScopeStatsRead), signatureMiddleware.Verify())
 		{
-			tracking.POST("/track", trackingHandler.Track)
-			tracking.GET("/statistics", trackingHandler.GetStatistics)
+			statistics.GET("", rateLimitMiddleware.RateLimit(), trackingHandler.GetStatistics)
+			statistics.GET("/timeseries", rateLimitMiddleware.RateLimit(), trackingHandler.GetTimeSeries)
 		}
 
-		// アプリケーション管理エンドポイント（認証不要）
+		// アプリケーション管理エンドポイント（管理者認証またはキーが属するアプリケーションの apps:admin キーが必須）
 		applicationHandler := handlers.NewApplicationHandler(applicationService, log)
 		applications := v1.Group("/applications")
-		applications.Use(rateLimitMiddleware.RateLimit())
+		applications.Use(rateLimitMiddleware.RateLimit(), authMiddleware.AdminAPIKey(adminAuthMiddleware.Authenticate()), signatureMiddleware.Verify())
 		{
 			applications.POST("", applicationHandler.Create)
 			applications.GET("", applicationHandler.List)
 			applications.GET("/:id", applicationHandler.Get)
 			applications.PUT("/:id", applicationHandler.Update)
+			applications.PUT("/:id/settings", applicationHandler.UpdateSettings)
+			applications.PUT("/:id/plan", applicationHandler.UpdatePlan)
+			applications.GET("/:id/usage", applicationHandler.GetUsage)
+			applications.POST("/:id/url-rules/preview", applicationHandler.PreviewURLRules)
 			applications.DELETE("/:id", applicationHandler.Delete)
+			applications.GET("/:id/keys", applicationHandler.ListAPIKeys)
+			applications.POST("/:id/keys", applicationHandler.CreateAPIKey)
+			applications.DELETE("/:id/keys/:key_id", applicationHandler.DeleteAPIKey)
+			applications.POST("/:id/keys/:key_id/rotate", applicationHandler.RotateAPIKey)
+			applications.POST("/:id/keys/:key_id/signing-secret", applicationHandler.GenerateSigningSecret)
+			applications.DELETE("/:id/keys/:key_id/signing-secret", applicationHandler.DeleteSigningSecret)
 		}
 
+		// 組織・ユーザー管理エンドポイント（管理者認証必須）
+		organizationHandler := handlers.NewOrganizationHandler(organizationService, log)
+		organizations := v1.Group("/organizations")
+		organizations.Use(rateLimitMiddleware.RateLimit(), adminAuthMiddleware.Authenticate())
+		{
+			organizations.POST("", organizationHandler.Create)
+			organizations.GET("", organizationHandler.List)
+			organizations.GET("/:id", organizationHandler.Get)
+			organizations.GET("/:id/members", organizationHandler.ListMembers)
+			organizations.PUT("/:id/members/:user_id", organizationHandler.SetMember)
+			organizations.DELETE("/:id/members/:user_id", organizationHandler.RemoveMember)
+		}
+		v1.POST("/users", rateLimitMiddleware.RateLimit(), adminAuthMiddleware.Authenticate(), organizationHandler.CreateUser)
+
 		// ビーコン関連エンドポイント（認証不要）
-		beaconHandler := handlers.NewBeaconHandler()
 		beacon := v1.Group("/beacon")
 		beacon.Use(rateLimitMiddleware.RateLimit())
 		{
@@ -73,7 +115,6 @@ func Setup(
 	}
 
 	// ビーコン配信ルート（APIバージョンなし、認証不要）
-	beaconHandler := handlers.NewBeaconHandler()
 	router.GET("/tracker.js", beaconHandler.Serve)
 	router.GET("/tracker.min.js", beaconHandler.ServeMinified)
 	router.GET("/tracker/:app_id.js", beaconHandler.ServeCustom)
@@ -86,6 +127,8 @@ func Setup(
 
 	// 405ハンドラー
 	router.NoMethod(middleware.MethodNotAllowedHandler())
+
+	return beaconHandler
 }
 
 // SetupTest はテスト用のルートを設定します
@@ -93,50 +136,87 @@ func SetupTest(
 	router *gin.Engine,
 	trackingService *services.TrackingService,
 	applicationService *services.ApplicationService,
+	organizationService *services.OrganizationService,
 	dbConn *postgresql.Connection,
 	redisConn *redis.CacheService,
+	adminAuthConfig middleware.AdminAuthConfig,
+	corsConfig middleware.CORSConfig,
+	rateLimitConfig middleware.RateLimitConfig,
 	log logger.Logger,
-) {
+) *handlers.BeaconHandler {
 	// テスト用のミドルウェア設定（認証を緩和）
 	authMiddleware := middleware.NewAuthMiddleware(applicationService, log)
-	rateLimitMiddleware := middleware.NewRateLimitMiddleware(redisConn.GetClient(), log, middleware.DefaultRateLimitConfig())
+	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(adminAuthConfig, organizationService, log)
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(redisConn.GetClient(), log, rateLimitConfig)
+	signatureMiddleware := middleware.NewSignatureMiddleware(redisConn.GetClient(), log, middleware.DefaultSignatureConfig())
+	quotaMiddleware := middleware.NewQuotaMiddleware(applicationService, log)
 
-	// グローバルミドルウェア
-	router.Use(middleware.CORS())
+	// グローバルミドルウェア（CORSはアプリケーションごとの許可オリジンも解決する）
+	router.Use(middleware.CORS(corsConfig, applicationService))
 	router.Use(middleware.Logging(log))
 	router.Use(middleware.ErrorHandler(log))
 
 	// ヘルスチェックエンドポイント
-	healthHandler := handlers.NewHealthHandler(dbConn, redisConn, log)
+	healthHandler := handlers.NewHealthHandler(dbConn, redisConn, trackingService, log)
 	router.GET("/health", healthHandler.Health)
 
+	// ビーコンハンドラー（/v1/beacon とルート直下の配信で共有）
+	beaconHandler := handlers.NewBeaconHandler(trackingService, applicationService, log)
+
 	// API v1 ルートグループ
 	v1 := router.Group("/v1")
 	{
 		// トラッキングエンドポイント（テスト用に認証を緩和）
 		trackingHandler := handlers.NewTrackingHandler(trackingService, log)
 		tracking := v1.Group("/tracking")
-		tracking.Use(authMiddleware.OptionalAuth()) // オプショナル認証
-		tracking.Use(rateLimitMiddleware.RateLimit())
+		ingest := tracking.Group("", authMiddleware.OptionalAuth(domainmodels.ScopeIngest), signatureMiddleware.Verify()) // オプショナル認証
+		{
+			ingest.POST("/track", rateLimitMiddleware.RateLimit(), quotaMiddleware.Quota(), trackingHandler.Track)
+			ingest.POST("/batch", middleware.TrackingBatch(), rateLimitMiddleware.RateLimit(), quotaMiddleware.Quota(), trackingHandler.TrackBatch)
+		}
+		statistics := tracking.Group("/statistics", authMiddleware.OptionalAuth(domainmodels.ScopeStatsRead), signatureMiddleware.Verify())
 		{
-			tracking.POST("/track", trackingHandler.Track)
-			tracking.GET("/statistics", trackingHandler.GetStatistics)
+			statistics.GET("", rateLimitMiddleware.RateLimit(), trackingHandler.GetStatistics)
+			statistics.GET("/timeseries", rateLimitMiddleware.RateLimit(), trackingHandler.GetTimeSeries)
 		}
 
-		// アプリケーション管理エンドポイント
+		// アプリケーション管理エンドポイント（テストでも管理者認証は緩和しない）
 		applicationHandler := handlers.NewApplicationHandler(applicationService, log)
 		applications := v1.Group("/applications")
-		applications.Use(rateLimitMiddleware.RateLimit())
+		applications.Use(rateLimitMiddleware.RateLimit(), authMiddleware.AdminAPIKey(adminAuthMiddleware.Authenticate()), signatureMiddleware.Verify())
 		{
 			applications.POST("", applicationHandler.Create)
 			applications.GET("", applicationHandler.List)
 			applications.GET("/:id", applicationHandler.Get)
 			applications.PUT("/:id", applicationHandler.Update)
+			applications.PUT("/:id/settings", applicationHandler.UpdateSettings)
+			applications.PUT("/:id/plan", applicationHandler.UpdatePlan)
+			applications.GET("/:id/usage", applicationHandler.GetUsage)
+			applications.POST("/:id/url-rules/preview", applicationHandler.PreviewURLRules)
 			applications.DELETE("/:id", applicationHandler.Delete)
+			applications.GET("/:id/keys", applicationHandler.ListAPIKeys)
+			applications.POST("/:id/keys", applicationHandler.CreateAPIKey)
+			applications.DELETE("/:id/keys/:key_id", applicationHandler.DeleteAPIKey)
+			applications.POST("/:id/keys/:key_id/rotate", applicationHandler.RotateAPIKey)
+			applications.POST("/:id/keys/:key_id/signing-secret", applicationHandler.GenerateSigningSecret)
+			applications.DELETE("/:id/keys/:key_id/signing-secret", applicationHandler.DeleteSigningSecret)
 		}
 
+		// 組織・ユーザー管理エンドポイント（管理者認証必須）
+		organizationHandler := handlers.NewOrganizationHandler(organizationService, log)
+		organizations := v1.Group("/organizations")
+		organizations.Use(rateLimitMiddleware.RateLimit(), adminAuthMiddleware.Authenticate())
+		{
+			organizations.POST("", organizationHandler.Create)
+			organizations.GET("", organizationHandler.List)
+			organizations.GET("/:id", organizationHandler.Get)
+			organizations.GET("/:id/members", organizationHandler.ListMembers)
+			organizations.PUT("/:id/members/:user_id", organizationHandler.SetMember)
+			organizations.DELETE("/:id/members/:user_id", organizationHandler.RemoveMember)
+		}
+		v1.POST("/users", rateLimitMiddleware.RateLimit(), adminAuthMiddleware.Authenticate(), organizationHandler.CreateUser)
+
 		// ビーコン関連エンドポイント（テスト用）
-		beaconHandler := handlers.NewBeaconHandler()
 		beacon := v1.Group("/beacon")
 		beacon.Use(rateLimitMiddleware.RateLimit())
 		{
@@ -147,7 +227,6 @@ func SetupTest(
 	}
 
 	// ビーコン配信ルート（テスト用）
-	beaconHandler := handlers.NewBeaconHandler()
 	router.GET("/tracker.js", beaconHandler.Serve)
 	router.GET("/tracker.min.js", beaconHandler.ServeMinified)
 	router.GET("/tracker/:app_id.js", beaconHandler.ServeCustom)
@@ -157,4 +236,6 @@ func SetupTest(
 
 	// 404ハンドラー
 	router.NoRoute(middleware.NotFoundHandler())
+
+	return beaconHandler
 }
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"accesslog-tracker/internal/api/handlers"
+	"accesslog-tracker/internal/api/middleware"
 	"accesslog-tracker/internal/api/routes"
 	"accesslog-tracker/internal/config"
 	"accesslog-tracker/internal/domain/services"
@@ -20,14 +22,16 @@ import (
 
 // Server はAPIサーバーの構造体です
 type Server struct {
-	config             *config.Config
-	logger             logger.Logger
-	router             *gin.Engine
-	httpServer         *http.Server
-	trackingService    *services.TrackingService
-	applicationService *services.ApplicationService
-	dbConn             *postgresql.Connection
-	redisConn          *redis.CacheService
+	config              *config.Config
+	logger              logger.Logger
+	router              *gin.Engine
+	httpServer          *http.Server
+	trackingService     *services.TrackingService
+	applicationService  *services.ApplicationService
+	organizationService *services.OrganizationService
+	dbConn              *postgresql.Connection
+	redisConn           *redis.CacheService
+	beaconHandler       *handlers.BeaconHandler
 }
 
 // NewServer は新しいAPIサーバーを作成します
@@ -36,6 +40,7 @@ func NewServer(
 	logger logger.Logger,
 	trackingService *services.TrackingService,
 	applicationService *services.ApplicationService,
+	organizationService *services.OrganizationService,
 	dbConn *postgresql.Connection,
 	redisConn *redis.CacheService,
 ) *Server {
@@ -49,17 +54,23 @@ func NewServer(
 	router := gin.New()
 
 	server := &Server{
-		config:             config,
-		logger:             logger,
-		router:             router,
-		trackingService:    trackingService,
-		applicationService: applicationService,
-		dbConn:             dbConn,
-		redisConn:          redisConn,
+		config:              config,
+		logger:              logger,
+		router:              router,
+		trackingService:     trackingService,
+		applicationService:  applicationService,
+		organizationService: organizationService,
+		dbConn:              dbConn,
+		redisConn:           redisConn,
+	}
+
+	// 管理APIの認証設定（JWT_SECRET が開発用のデフォルト値の場合はJWTを受け付けない）
+	if config.GetAdminJWTSecret() == "" && config.Admin.Token == "" {
+		logger.Warn("Neither ADMIN_TOKEN nor JWT_SECRET is configured, application management API will reject all requests")
 	}
 
 	// ルートを設定
-	routes.Setup(router, trackingService, applicationService, dbConn, redisConn, logger)
+	server.beaconHandler = routes.Setup(router, trackingService, applicationService, organizationService, dbConn, redisConn, server.adminAuthConfig(), server.corsConfig(), server.rateLimitConfig(), logger)
 
 	// HTTPサーバーを作成（パフォーマンス最適化）
 	server.httpServer = &http.Server{
@@ -103,10 +114,42 @@ func (s *Server) Stop() error {
 		return err
 	}
 
+	// レスポンス送信後に処理しているビーコンヒットがパイプラインに渡り終えるまで待つ
+	if err := s.waitBeaconHits(ctx); err != nil {
+		s.logger.Error("Failed to wait for pending beacon hits", "error", err.Error())
+		return err
+	}
+
+	// 受付停止後、キューに残ったトラッキングデータを書き込む
+	if err := s.trackingService.Shutdown(ctx); err != nil {
+		s.logger.Error("Failed to drain ingestion pipeline", "error", err.Error())
+		return err
+	}
+
 	s.logger.Info("Server stopped gracefully")
 	return nil
 }
 
+// waitBeaconHits は処理中のビーコンヒットがすべて完了するか、ctx が終了するまで待機します
+func (s *Server) waitBeaconHits(ctx context.Context) error {
+	if s.beaconHandler == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.beaconHandler.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // gracefulShutdown はグレースフルシャットダウンを処理します
 func (s *Server) gracefulShutdown() {
 	// シグナルを待機
@@ -132,5 +175,34 @@ func (s *Server) GetRouter() *gin.Engine {
 
 // SetupTest はテスト用のルートを設定します
 func (s *Server) SetupTest() {
-	routes.SetupTest(s.router, s.trackingService, s.applicationService, s.dbConn, s.redisConn, s.logger)
+	s.beaconHandler = routes.SetupTest(s.router, s.trackingService, s.applicationService, s.organizationService, s.dbConn, s.redisConn, s.adminAuthConfig(), s.corsConfig(), s.rateLimitConfig(), s.logger)
+}
+
+// adminAuthConfig は設定から管理API認証の設定を作成します
+func (s *Server) adminAuthConfig() middleware.AdminAuthConfig {
+	return middleware.AdminAuthConfig{
+		Token:     s.config.Admin.Token,
+		JWTSecret: s.config.GetAdminJWTSecret(),
+	}
+}
+
+// corsConfig は設定からCORSの設定を作成します
+func (s *Server) corsConfig() middleware.CORSConfig {
+	return middleware.CORSConfig{
+		AllowedOrigins:   s.config.GetCORSAllowedOrigins(),
+		AllowedMethods:   s.config.GetCORSAllowedMethods(),
+		AllowedHeaders:   s.config.GetCORSAllowedHeaders(),
+		ExposedHeaders:   s.config.GetCORSExposedHeaders(),
+		AllowCredentials: s.config.CORS.AllowCredentials,
+		MaxAge:           s.config.GetCORSMaxAge(),
+	}
+}
+
+// rateLimitConfig は設定からレート制限の設定を作成します
+// Redisを利用できない間は疎通確認の結果に従い、Redisに問い合わせずにフォールバックします
+func (s *Server) rateLimitConfig() middleware.RateLimitConfig {
+	config := middleware.DefaultRateLimitConfig()
+	config.FailureMode = s.config.Redis.FailureMode
+	config.Available = s.redisConn.Available
+	return config
 }
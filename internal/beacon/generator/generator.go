@@ -123,13 +123,23 @@ func (bg *BeaconGenerator) GenerateJavaScript(config BeaconConfig) (string, erro
         return data;
     }
     
+    // 送信先URL（プリフライトリクエストでもアプリケーションを特定できるよう app_id を付与）
+    function endpointURL(data) {
+        if (!data.app_id) {
+            return config.endpoint;
+        }
+        var separator = config.endpoint.indexOf('?') === -1 ? '?' : '&';
+        return config.endpoint + separator + 'app_id=' + encodeURIComponent(data.app_id);
+    }
+    
     // データ送信
     function sendData(data) {
         log('Sending tracking data: ' + JSON.stringify(data));
+        var endpoint = endpointURL(data);
         
         // fetch APIを使用
         if (window.fetch) {
-            fetch(config.endpoint, {
+            fetch(endpoint, {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
@@ -150,7 +160,7 @@ func (bg *BeaconGenerator) GenerateJavaScript(config BeaconConfig) (string, erro
         } else {
             // フォールバック: XMLHttpRequest
             var xhr = new XMLHttpRequest();
-            xhr.open('POST', config.endpoint, true);
+            xhr.open('POST', endpoint, true);
             xhr.setRequestHeader('Content-Type', 'application/json');
             if (window.ALT_CONFIG && window.ALT_CONFIG.api_key) {
                 xhr.setRequestHeader('X-API-Key', window.ALT_CONFIG.api_key);
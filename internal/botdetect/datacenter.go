@@ -0,0 +1,116 @@
+package botdetect
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DatacenterRanges はデータセンター（クラウド・ホスティング事業者）のIPアドレス範囲です
+// 範囲はプレフィックス長ごとに分けて保持し、アドレスをプレフィックス長の数だけ照合します
+type DatacenterRanges struct {
+	bits     []int
+	prefixes map[netip.Prefix]struct{}
+}
+
+// ParseDatacenterRanges はCIDRの一覧を解析します
+// 1行に1つのCIDR（またはアドレス）を書き、カンマ・空白以降（事業者名など）と "#" で始まる行は無視します
+func ParseDatacenterRanges(data []byte) (*DatacenterRanges, error) {
+	ranges := &DatacenterRanges{prefixes: make(map[netip.Prefix]struct{})}
+	seen := make(map[int]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.IndexAny(text, ", \t"); i >= 0 {
+			text = text[:i]
+		}
+
+		prefix, err := parsePrefix(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid datacenter range on line %d: %w", line, err)
+		}
+		ranges.prefixes[prefix] = struct{}{}
+		if !seen[prefix.Bits()] {
+			seen[prefix.Bits()] = true
+			ranges.bits = append(ranges.bits, prefix.Bits())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read datacenter ranges: %w", err)
+	}
+
+	sort.Ints(ranges.bits)
+	return ranges, nil
+}
+
+// LoadDatacenterRanges はCIDRの一覧のファイルを読み込みます
+func LoadDatacenterRanges(path string) (*DatacenterRanges, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read datacenter ranges: %w", err)
+	}
+	return ParseDatacenterRanges(data)
+}
+
+// Contains はIPアドレスがいずれかの範囲に含まれるかどうかを判定します
+func (r *DatacenterRanges) Contains(ip string) bool {
+	if r == nil || len(r.prefixes) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, bits := range r.bits {
+		if bits > addr.BitLen() {
+			continue
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if _, ok := r.prefixes[prefix]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Len は範囲の数を返します
+func (r *DatacenterRanges) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.prefixes)
+}
+
+// parsePrefix はCIDRまたは単一のアドレスをプレフィックスに変換します（ホスト部は切り捨てる）
+func parsePrefix(text string) (netip.Prefix, error) {
+	if !strings.Contains(text, "/") {
+		addr, err := netip.ParseAddr(text)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(text)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
@@ -0,0 +1,194 @@
+package botdetect
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"accesslog-tracker/internal/utils/lru"
+)
+
+// BotThreshold はボットと判定するスコアの下限です
+// 統計のボット数（access_logs.bot_score）も同じ値で集計します
+const BotThreshold = 50
+
+// MaxScore はスコアの上限です
+const MaxScore = 100
+
+// 判定の理由（bot_reason にカンマ区切りで保存する）
+const (
+	ReasonUserAgent  = "user_agent" // ボットのユーザーエージェントのパターンに一致
+	ReasonHeadless   = "headless"   // ヘッドレスブラウザ・自動化ツールの兆候
+	ReasonDatacenter = "datacenter" // データセンターのIPアドレス範囲からの送信
+	ReasonRate       = "rate"       // 同じIPアドレスからのイベントの頻度が上限を超えた
+)
+
+// 各シグナルのスコア
+// データセンターと頻度はそれぞれ単独ではボットと判定せず、組み合わさった場合にボットと判定する
+const (
+	scoreUserAgent  = 100
+	scoreHeadless   = 80
+	scoreDatacenter = 30
+	scoreRate       = 30
+)
+
+// デフォルト値
+const (
+	DefaultRateLimit     = 120
+	DefaultRateWindow    = time.Minute
+	DefaultRateCacheSize = 100000
+)
+
+// Config はボットの判定の設定です
+type Config struct {
+	PatternsPath   string           // ユーザーエージェントのパターンのファイル（空の場合は組み込みのパターン）
+	DatacenterPath string           // データセンターのCIDRの一覧のファイル（空の場合は判定しない）
+	RateLimit      int              // RateWindow あたりの同じアプリケーション・IPアドレスからのイベント数の上限（0 以下の場合は DefaultRateLimit）
+	RateWindow     time.Duration    // 頻度を数える期間（0 以下の場合は DefaultRateWindow）
+	RateCacheSize  int              // 頻度を数えるIPアドレス数の上限（0 以下の場合は DefaultRateCacheSize）
+	Clock          func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// Event は判定するイベントです
+type Event struct {
+	AppID     string
+	UserAgent string
+	IPAddress string // 匿名化する前のIPアドレス
+	Webdriver bool   // ブラウザが自動化されている兆候（navigator.webdriver）
+}
+
+// Result は判定結果です
+type Result struct {
+	Score   int      // 0〜MaxScore
+	Reasons []string // 一致したシグナル
+}
+
+// IsBot はスコアが BotThreshold 以上かどうかを判定します
+func (r Result) IsBot() bool {
+	return r.Score >= BotThreshold
+}
+
+// Reason は一致したシグナルをカンマ区切りで返します
+func (r Result) Reason() string {
+	return strings.Join(r.Reasons, ",")
+}
+
+// add はシグナルのスコアを加算します
+func (r *Result) add(reason string, score int) {
+	r.Reasons = append(r.Reasons, reason)
+	r.Score += score
+	if r.Score > MaxScore {
+		r.Score = MaxScore
+	}
+}
+
+// rateWindow は1つのアプリケーション・IPアドレスの期間内のイベント数です
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// Detector はイベントがボットによるものかを判定します
+type Detector struct {
+	config     Config
+	patterns   *UserAgentPatterns
+	datacenter *DatacenterRanges
+
+	mu    sync.Mutex
+	rates *lru.Cache[string, *rateWindow]
+}
+
+// New は新しいボットの判定を作成します
+func New(config Config) (*Detector, error) {
+	if config.RateLimit <= 0 {
+		config.RateLimit = DefaultRateLimit
+	}
+	if config.RateWindow <= 0 {
+		config.RateWindow = DefaultRateWindow
+	}
+	if config.RateCacheSize <= 0 {
+		config.RateCacheSize = DefaultRateCacheSize
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	patterns, err := LoadPatterns(config.PatternsPath)
+	if err != nil {
+		return nil, err
+	}
+	var datacenter *DatacenterRanges
+	if config.DatacenterPath != "" {
+		if datacenter, err = LoadDatacenterRanges(config.DatacenterPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Detector{
+		config:     config,
+		patterns:   patterns,
+		datacenter: datacenter,
+		rates: lru.New[string, *rateWindow](lru.Config{
+			Size:  config.RateCacheSize,
+			TTL:   config.RateWindow,
+			Clock: config.Clock,
+		}),
+	}, nil
+}
+
+var (
+	defaultDetectorOnce sync.Once
+	defaultDetector     *Detector
+)
+
+// Default は組み込みのパターンとデフォルトの頻度の上限で判定するボットの判定を返します
+// 組み込みのパターンが不正な場合は panic します
+func Default() *Detector {
+	defaultDetectorOnce.Do(func() {
+		detector, err := New(Config{})
+		if err != nil {
+			panic("botdetect: " + err.Error())
+		}
+		defaultDetector = detector
+	})
+	return defaultDetector
+}
+
+// Detect はイベントのボットのスコアと理由を判定します
+// 頻度はイベントごとに数えるため、同じイベントは1回だけ判定してください
+func (d *Detector) Detect(event Event) Result {
+	var result Result
+	if d.patterns.Match(event.UserAgent) {
+		result.add(ReasonUserAgent, scoreUserAgent)
+	}
+	if event.Webdriver || IsHeadless(event.UserAgent) {
+		result.add(ReasonHeadless, scoreHeadless)
+	}
+	if d.datacenter.Contains(event.IPAddress) {
+		result.add(ReasonDatacenter, scoreDatacenter)
+	}
+	if d.exceedsRate(event.AppID, event.IPAddress) {
+		result.add(ReasonRate, scoreRate)
+	}
+	return result
+}
+
+// exceedsRate は同じアプリケーション・IPアドレスからの期間内のイベント数が上限を超えたかどうかを判定します
+func (d *Detector) exceedsRate(appID, ip string) bool {
+	if ip == "" {
+		return false
+	}
+	key := appID + "|" + ip
+	now := d.config.Clock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	window, ok := d.rates.Get(key)
+	if !ok || now.Sub(window.start) >= d.config.RateWindow {
+		window = &rateWindow{start: now}
+		d.rates.Add(key, window)
+	}
+	window.count++
+	return window.count > d.config.RateLimit
+}
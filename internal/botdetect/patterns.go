@@ -0,0 +1,107 @@
+// Package botdetect はユーザーエージェント・送信元のIPアドレス・ヘッドレスブラウザの兆候・リクエスト頻度から
+// イベントがボットによるものかを判定します
+package botdetect
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// defaultPatterns は組み込みのユーザーエージェントのパターンです
+//
+//go:embed patterns.txt
+var defaultPatterns []byte
+
+// headlessPatterns はヘッドレスブラウザ・自動化ツールのユーザーエージェントに含まれるトークンです
+var headlessPatterns = regexp.MustCompile(`(?i)headlesschrome|phantomjs|slimerjs|puppeteer|playwright|selenium|webdriver|htmlunit|jsdom|nightmare|casperjs`)
+
+// UserAgentPatterns はボットのユーザーエージェントのパターンの一覧です
+type UserAgentPatterns struct {
+	re *regexp.Regexp
+}
+
+// ParsePatterns は COUNTER-Robots 形式（1行に1つの正規表現、"#" で始まる行はコメント）のパターンを解析します
+// パターンは大文字・小文字を区別せずにユーザーエージェントの一部と照合します
+func ParsePatterns(data []byte) (*UserAgentPatterns, error) {
+	var patterns []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid bot pattern on line %d: %w", line, err)
+		}
+		patterns = append(patterns, "(?:"+pattern+")")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read bot patterns: %w", err)
+	}
+	if len(patterns) == 0 {
+		return &UserAgentPatterns{}, nil
+	}
+
+	re, err := regexp.Compile("(?i)" + strings.Join(patterns, "|"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile bot patterns: %w", err)
+	}
+	return &UserAgentPatterns{re: re}, nil
+}
+
+// LoadPatterns はパターンのファイルを読み込みます（空の場合は組み込みのパターン）
+func LoadPatterns(path string) (*UserAgentPatterns, error) {
+	if path == "" {
+		return ParsePatterns(defaultPatterns)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bot patterns: %w", err)
+	}
+	return ParsePatterns(data)
+}
+
+// Match はユーザーエージェントがいずれかのパターンに一致するかどうかを判定します
+// 空のユーザーエージェントは一致しないものとします
+func (p *UserAgentPatterns) Match(userAgent string) bool {
+	if p == nil || p.re == nil || userAgent == "" {
+		return false
+	}
+	return p.re.MatchString(userAgent)
+}
+
+var (
+	defaultOnce         sync.Once
+	defaultUserAgentSet *UserAgentPatterns
+)
+
+// DefaultPatterns は組み込みのパターンを返します
+// 組み込みのパターンが不正な場合は panic します
+func DefaultPatterns() *UserAgentPatterns {
+	defaultOnce.Do(func() {
+		patterns, err := ParsePatterns(defaultPatterns)
+		if err != nil {
+			panic(fmt.Sprintf("botdetect: invalid embedded patterns: %v", err))
+		}
+		defaultUserAgentSet = patterns
+	})
+	return defaultUserAgentSet
+}
+
+// MatchUserAgent はユーザーエージェントが組み込みのパターンに一致するかどうかを判定します
+func MatchUserAgent(userAgent string) bool {
+	return DefaultPatterns().Match(userAgent)
+}
+
+// IsHeadless はユーザーエージェントがヘッドレスブラウザ・自動化ツールのものかどうかを判定します
+func IsHeadless(userAgent string) bool {
+	return headlessPatterns.MatchString(userAgent)
+}
@@ -6,18 +6,34 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultJWTSecret は開発用のJWT署名鍵です（本番環境では必ず JWT_SECRET で変更してください）
+const DefaultJWTSecret = "your-super-secret-jwt-key-change-in-production"
+
 // Config はアプリケーション全体の設定を表します
 type Config struct {
 	App      AppConfig      `yaml:"app"`
 	Database DatabaseConfig `yaml:"database"`
 	Redis    RedisConfig    `yaml:"redis"`
 	JWT      JWTConfig      `yaml:"jwt"`
+	Admin    AdminConfig    `yaml:"admin"`
+	APIKeys  APIKeyConfig   `yaml:"api_keys"`
+	AppCache AppCacheConfig `yaml:"app_cache"`
 	CORS     CORSConfig     `yaml:"cors"`
 	Logging  LoggingConfig  `yaml:"logging"`
+	Ingestion IngestionConfig `yaml:"ingestion"`
+	Rollup    RollupConfig    `yaml:"rollup"`
+	Retention RetentionConfig `yaml:"retention"`
+	Partition PartitionConfig `yaml:"partition"`
+	Usage     UsageConfig     `yaml:"usage"`
+	UserAgent UserAgentConfig `yaml:"user_agent"`
+	GeoIP     GeoIPConfig     `yaml:"geoip"`
+	Bot       BotConfig       `yaml:"bot"`
+	Referrer  ReferrerConfig  `yaml:"referrer"`
 }
 
 // AppConfig はアプリケーション固有の設定を表します
@@ -39,6 +55,7 @@ type DatabaseConfig struct {
 	MaxOpenConns    int    `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
 	MaxIdleConns    int    `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
 	ConnMaxLifetime string `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	AutoMigrate     bool   `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
 }
 
 // RedisConfig はRedis接続設定を表します
@@ -48,6 +65,9 @@ type RedisConfig struct {
 	Password string `yaml:"password" env:"REDIS_PASSWORD"`
 	DB       int    `yaml:"db" env:"REDIS_DB"`
 	PoolSize int    `yaml:"pool_size" env:"REDIS_POOL_SIZE"`
+	// FailureMode はRedisを利用できない場合のレート制限の動作です（open: プロセス内で判定、closed: 503 で拒否）
+	FailureMode   string `yaml:"failure_mode" env:"REDIS_FAILURE_MODE"`
+	ProbeInterval string `yaml:"probe_interval" env:"REDIS_PROBE_INTERVAL"`
 }
 
 // JWTConfig はJWT設定を表します
@@ -57,6 +77,27 @@ type JWTConfig struct {
 	RefreshExpiration string `yaml:"refresh_expiration" env:"JWT_REFRESH_EXPIRATION"`
 }
 
+// AdminConfig は管理API（/v1/applications）の認証設定を表します
+type AdminConfig struct {
+	Token string `yaml:"token" env:"ADMIN_TOKEN"`
+}
+
+// APIKeyConfig はアプリケーションのAPIキーの設定を表します
+type APIKeyConfig struct {
+	// RotationGracePeriod はローテーション後に以前のキーを有効なままにする期間のデフォルト値です
+	RotationGracePeriod string `yaml:"rotation_grace_period" env:"API_KEY_ROTATION_GRACE_PERIOD"`
+}
+
+// AppCacheConfig は認証時のアプリケーションのキャッシュの設定を表します
+type AppCacheConfig struct {
+	// LocalSize はRedisの手前に置くプロセス内のキャッシュのエントリ数の上限です（0 の場合は使わない）
+	LocalSize int `yaml:"local_size" env:"APP_CACHE_LOCAL_SIZE"`
+	// LocalTTL はプロセス内のキャッシュの有効期間です（他のインスタンスでの更新を取りこぼした場合に反映されるまでの上限）
+	LocalTTL string `yaml:"local_ttl" env:"APP_CACHE_LOCAL_TTL"`
+	// InvalidKeyTTL は存在しないAPIキーをキャッシュする期間です
+	InvalidKeyTTL string `yaml:"invalid_key_ttl" env:"APP_CACHE_INVALID_KEY_TTL"`
+}
+
 // CORSConfig はCORS設定を表します
 type CORSConfig struct {
 	AllowedOrigins   string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
@@ -74,6 +115,86 @@ type LoggingConfig struct {
 	Output string `yaml:"output" env:"LOG_OUTPUT"`
 }
 
+// IngestionConfig はトラッキングデータの非同期書き込み設定を表します
+type IngestionConfig struct {
+	Enabled       bool   `yaml:"enabled" env:"INGEST_ENABLED"`
+	QueueSize     int    `yaml:"queue_size" env:"INGEST_QUEUE_SIZE"`
+	Workers       int    `yaml:"workers" env:"INGEST_WORKERS"`
+	BatchSize     int    `yaml:"batch_size" env:"INGEST_BATCH_SIZE"`
+	FlushInterval string `yaml:"flush_interval" env:"INGEST_FLUSH_INTERVAL"`
+	WriteTimeout  string `yaml:"write_timeout" env:"INGEST_WRITE_TIMEOUT"`
+	MaxRetries    int    `yaml:"max_retries" env:"INGEST_MAX_RETRIES"`
+	RetryBackoff  string `yaml:"retry_backoff" env:"INGEST_RETRY_BACKOFF"`
+}
+
+// RollupConfig は事前集計（ロールアップ）の設定を表します
+type RollupConfig struct {
+	Enabled          bool   `yaml:"enabled" env:"ROLLUP_ENABLED"`
+	Interval         string `yaml:"interval" env:"ROLLUP_INTERVAL"`
+	Delay            string `yaml:"delay" env:"ROLLUP_DELAY"`
+	MaxBucketsPerRun int    `yaml:"max_buckets_per_run" env:"ROLLUP_MAX_BUCKETS"`
+}
+
+// RetentionConfig はデータ保持期間によるクリーンアップの設定を表します
+type RetentionConfig struct {
+	Enabled          bool   `yaml:"enabled" env:"RETENTION_ENABLED"`
+	Interval         string `yaml:"interval" env:"RETENTION_INTERVAL"`
+	BatchSize        int    `yaml:"batch_size" env:"RETENTION_BATCH_SIZE"`
+	MaxBatchesPerRun int    `yaml:"max_batches_per_run" env:"RETENTION_MAX_BATCHES"`
+}
+
+// PartitionConfig は access_logs のパーティション管理の設定を表します
+type PartitionConfig struct {
+	Enabled     bool   `yaml:"enabled" env:"PARTITION_ENABLED"`
+	Granularity string `yaml:"granularity" env:"PARTITION_GRANULARITY"`
+	Interval    string `yaml:"interval" env:"PARTITION_INTERVAL"`
+	Premake     int    `yaml:"premake" env:"PARTITION_PREMAKE"`
+	Retain      int    `yaml:"retain" env:"PARTITION_RETAIN"`
+	DropExpired bool   `yaml:"drop_expired" env:"PARTITION_DROP_EXPIRED"`
+}
+
+// UsageConfig は月間の使用量の突き合わせの設定を表します
+type UsageConfig struct {
+	ReconcileEnabled  bool   `yaml:"reconcile_enabled" env:"USAGE_RECONCILE_ENABLED"`
+	ReconcileInterval string `yaml:"reconcile_interval" env:"USAGE_RECONCILE_INTERVAL"`
+}
+
+// UserAgentConfig はインジェスト時のユーザーエージェントの解析の設定を表します
+type UserAgentConfig struct {
+	// RulesPath はuap-core互換のルールファイルのパスです（空の場合は組み込みのルール）
+	RulesPath string `yaml:"rules_path" env:"UA_PARSER_RULES_PATH"`
+	// CacheSize は解析結果をキャッシュするユーザーエージェント数の上限です
+	CacheSize int `yaml:"cache_size" env:"UA_PARSER_CACHE_SIZE"`
+}
+
+// GeoIPConfig はインジェスト時の位置情報の判定の設定を表します
+type GeoIPConfig struct {
+	// CityPath はGeoLite2-City互換のデータベースのパスです（空の場合は国・地域・都市を判定しない）
+	CityPath string `yaml:"city_path" env:"GEOIP_CITY_DB_PATH"`
+	// ASNPath はGeoLite2-ASN互換のデータベースのパスです（空の場合はASNを判定しない）
+	ASNPath string `yaml:"asn_path" env:"GEOIP_ASN_DB_PATH"`
+	// ReloadInterval はデータベースファイルの変更を確認する間隔です
+	ReloadInterval string `yaml:"reload_interval" env:"GEOIP_RELOAD_INTERVAL"`
+}
+
+// BotConfig はインジェスト時のボットの判定の設定を表します
+type BotConfig struct {
+	// PatternsPath はCOUNTER-Robots形式のユーザーエージェントのパターンのファイルです（空の場合は組み込みのパターン）
+	PatternsPath string `yaml:"patterns_path" env:"BOT_PATTERNS_PATH"`
+	// DatacenterRangesPath はデータセンターのCIDRの一覧のファイルです（空の場合は判定しない）
+	DatacenterRangesPath string `yaml:"datacenter_ranges_path" env:"BOT_DATACENTER_RANGES_PATH"`
+	// RateLimit は RateWindow あたりの同じIPアドレスからのイベント数の上限です
+	RateLimit int `yaml:"rate_limit" env:"BOT_RATE_LIMIT"`
+	// RateWindow はイベント数を数える期間です
+	RateWindow string `yaml:"rate_window" env:"BOT_RATE_WINDOW"`
+}
+
+// ReferrerConfig はインジェスト時の流入元の判定の設定を表します
+type ReferrerConfig struct {
+	// SourcesPath はreferer-parser形式の流入元の一覧のファイルです（空の場合は組み込みの一覧）
+	SourcesPath string `yaml:"sources_path" env:"REFERRER_SOURCES_PATH"`
+}
+
 // New は新しい設定インスタンスを作成します
 func New() *Config {
 	return &Config{
@@ -95,22 +216,32 @@ func New() *Config {
 			ConnMaxLifetime: "300s",
 		},
 		Redis: RedisConfig{
-			Host:     "localhost",
-			Port:     6379,
-			Password: "",
-			DB:       0,
-			PoolSize: 10,
+			Host:          "localhost",
+			Port:          6379,
+			Password:      "",
+			DB:            0,
+			PoolSize:      10,
+			FailureMode:   "open",
+			ProbeInterval: "5s",
 		},
 		JWT: JWTConfig{
-			Secret:           "your-super-secret-jwt-key-change-in-production",
+			Secret:           DefaultJWTSecret,
 			Expiration:       "24h",
 			RefreshExpiration: "168h",
 		},
+		APIKeys: APIKeyConfig{
+			RotationGracePeriod: "24h",
+		},
+		AppCache: AppCacheConfig{
+			LocalSize:     10000,
+			LocalTTL:      "30s",
+			InvalidKeyTTL: "1m",
+		},
 		CORS: CORSConfig{
 			AllowedOrigins:   "http://localhost:3000,http://localhost:8080",
 			AllowedMethods:   "GET,POST,PUT,DELETE,OPTIONS",
-			AllowedHeaders:   "Content-Type,Authorization,X-Requested-With",
-			ExposedHeaders:   "Content-Length",
+			AllowedHeaders:   "Origin,Content-Type,Accept,Authorization,X-API-Key,X-Requested-With,X-ALT-Signature,X-ALT-Timestamp",
+			ExposedHeaders:   "Content-Length,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,Retry-After",
 			AllowCredentials: true,
 			MaxAge:           86400,
 		},
@@ -119,6 +250,50 @@ func New() *Config {
 			Format: "json",
 			Output: "stdout",
 		},
+		Ingestion: IngestionConfig{
+			Enabled:       true,
+			QueueSize:     10000,
+			Workers:       4,
+			BatchSize:     500,
+			FlushInterval: "1s",
+			WriteTimeout:  "10s",
+			MaxRetries:    3,
+			RetryBackoff:  "200ms",
+		},
+		Rollup: RollupConfig{
+			Enabled:          true,
+			Interval:         "1m",
+			Delay:            "5m",
+			MaxBucketsPerRun: 24,
+		},
+		Retention: RetentionConfig{
+			Enabled:          true,
+			Interval:         "1h",
+			BatchSize:        5000,
+			MaxBatchesPerRun: 100,
+		},
+		Partition: PartitionConfig{
+			Enabled:     true,
+			Granularity: "month",
+			Interval:    "1h",
+			Premake:     3,
+			Retain:      0,
+			DropExpired: false,
+		},
+		Usage: UsageConfig{
+			ReconcileEnabled:  true,
+			ReconcileInterval: "1m",
+		},
+		UserAgent: UserAgentConfig{
+			CacheSize: 10000,
+		},
+		GeoIP: GeoIPConfig{
+			ReloadInterval: "1m",
+		},
+		Bot: BotConfig{
+			RateLimit:  120,
+			RateWindow: "1m",
+		},
 	}
 }
 
@@ -176,6 +351,9 @@ func (c *Config) LoadFromEnv() error {
 	if val := os.Getenv("DB_SSL_MODE"); val != "" {
 		c.Database.SSLMode = val
 	}
+	if val := os.Getenv("DB_AUTO_MIGRATE"); val != "" {
+		c.Database.AutoMigrate = val == "true"
+	}
 	
 	// Redis設定
 	if val := os.Getenv("REDIS_HOST"); val != "" {
@@ -194,6 +372,210 @@ func (c *Config) LoadFromEnv() error {
 			c.Redis.DB = db
 		}
 	}
+	if val := os.Getenv("REDIS_FAILURE_MODE"); val != "" {
+		c.Redis.FailureMode = val
+	}
+	if val := os.Getenv("REDIS_PROBE_INTERVAL"); val != "" {
+		c.Redis.ProbeInterval = val
+	}
+	
+	// JWT設定
+	if val := os.Getenv("JWT_SECRET"); val != "" {
+		c.JWT.Secret = val
+	}
+	if val := os.Getenv("JWT_EXPIRATION"); val != "" {
+		c.JWT.Expiration = val
+	}
+	if val := os.Getenv("JWT_REFRESH_EXPIRATION"); val != "" {
+		c.JWT.RefreshExpiration = val
+	}
+	
+	// Admin設定
+	if val := os.Getenv("ADMIN_TOKEN"); val != "" {
+		c.Admin.Token = val
+	}
+	
+	// APIキー設定
+	if val := os.Getenv("API_KEY_ROTATION_GRACE_PERIOD"); val != "" {
+		c.APIKeys.RotationGracePeriod = val
+	}
+	
+	// アプリケーションのキャッシュ設定
+	if val := os.Getenv("APP_CACHE_LOCAL_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil {
+			c.AppCache.LocalSize = size
+		}
+	}
+	if val := os.Getenv("APP_CACHE_LOCAL_TTL"); val != "" {
+		c.AppCache.LocalTTL = val
+	}
+	if val := os.Getenv("APP_CACHE_INVALID_KEY_TTL"); val != "" {
+		c.AppCache.InvalidKeyTTL = val
+	}
+	
+	// CORS設定
+	if val := os.Getenv("CORS_ALLOWED_ORIGINS"); val != "" {
+		c.CORS.AllowedOrigins = val
+	}
+	if val := os.Getenv("CORS_ALLOWED_METHODS"); val != "" {
+		c.CORS.AllowedMethods = val
+	}
+	if val := os.Getenv("CORS_ALLOWED_HEADERS"); val != "" {
+		c.CORS.AllowedHeaders = val
+	}
+	if val := os.Getenv("CORS_EXPOSED_HEADERS"); val != "" {
+		c.CORS.ExposedHeaders = val
+	}
+	if val := os.Getenv("CORS_ALLOW_CREDENTIALS"); val != "" {
+		c.CORS.AllowCredentials = val == "true"
+	}
+	if val := os.Getenv("CORS_MAX_AGE"); val != "" {
+		if maxAge, err := strconv.Atoi(val); err == nil {
+			c.CORS.MaxAge = maxAge
+		}
+	}
+	
+	// Ingestion設定
+	if val := os.Getenv("INGEST_ENABLED"); val != "" {
+		c.Ingestion.Enabled = val == "true"
+	}
+	if val := os.Getenv("INGEST_QUEUE_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil {
+			c.Ingestion.QueueSize = size
+		}
+	}
+	if val := os.Getenv("INGEST_WORKERS"); val != "" {
+		if workers, err := strconv.Atoi(val); err == nil {
+			c.Ingestion.Workers = workers
+		}
+	}
+	if val := os.Getenv("INGEST_BATCH_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil {
+			c.Ingestion.BatchSize = size
+		}
+	}
+	if val := os.Getenv("INGEST_FLUSH_INTERVAL"); val != "" {
+		c.Ingestion.FlushInterval = val
+	}
+	if val := os.Getenv("INGEST_WRITE_TIMEOUT"); val != "" {
+		c.Ingestion.WriteTimeout = val
+	}
+	if val := os.Getenv("INGEST_MAX_RETRIES"); val != "" {
+		if retries, err := strconv.Atoi(val); err == nil {
+			c.Ingestion.MaxRetries = retries
+		}
+	}
+	if val := os.Getenv("INGEST_RETRY_BACKOFF"); val != "" {
+		c.Ingestion.RetryBackoff = val
+	}
+	
+	// Rollup設定
+	if val := os.Getenv("ROLLUP_ENABLED"); val != "" {
+		c.Rollup.Enabled = val == "true"
+	}
+	if val := os.Getenv("ROLLUP_INTERVAL"); val != "" {
+		c.Rollup.Interval = val
+	}
+	if val := os.Getenv("ROLLUP_DELAY"); val != "" {
+		c.Rollup.Delay = val
+	}
+	if val := os.Getenv("ROLLUP_MAX_BUCKETS"); val != "" {
+		if buckets, err := strconv.Atoi(val); err == nil {
+			c.Rollup.MaxBucketsPerRun = buckets
+		}
+	}
+	
+	// Retention設定
+	if val := os.Getenv("RETENTION_ENABLED"); val != "" {
+		c.Retention.Enabled = val == "true"
+	}
+	if val := os.Getenv("RETENTION_INTERVAL"); val != "" {
+		c.Retention.Interval = val
+	}
+	if val := os.Getenv("RETENTION_BATCH_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil {
+			c.Retention.BatchSize = size
+		}
+	}
+	if val := os.Getenv("RETENTION_MAX_BATCHES"); val != "" {
+		if batches, err := strconv.Atoi(val); err == nil {
+			c.Retention.MaxBatchesPerRun = batches
+		}
+	}
+	
+	// Usage設定
+	if val := os.Getenv("USAGE_RECONCILE_ENABLED"); val != "" {
+		c.Usage.ReconcileEnabled = val == "true"
+	}
+	if val := os.Getenv("USAGE_RECONCILE_INTERVAL"); val != "" {
+		c.Usage.ReconcileInterval = val
+	}
+	
+	// ユーザーエージェントの解析設定
+	if val := os.Getenv("UA_PARSER_RULES_PATH"); val != "" {
+		c.UserAgent.RulesPath = val
+	}
+	if val := os.Getenv("UA_PARSER_CACHE_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil {
+			c.UserAgent.CacheSize = size
+		}
+	}
+	
+	// 位置情報の判定設定
+	if val := os.Getenv("GEOIP_CITY_DB_PATH"); val != "" {
+		c.GeoIP.CityPath = val
+	}
+	if val := os.Getenv("GEOIP_ASN_DB_PATH"); val != "" {
+		c.GeoIP.ASNPath = val
+	}
+	if val := os.Getenv("GEOIP_RELOAD_INTERVAL"); val != "" {
+		c.GeoIP.ReloadInterval = val
+	}
+	
+	// ボットの判定設定
+	if val := os.Getenv("BOT_PATTERNS_PATH"); val != "" {
+		c.Bot.PatternsPath = val
+	}
+	if val := os.Getenv("BOT_DATACENTER_RANGES_PATH"); val != "" {
+		c.Bot.DatacenterRangesPath = val
+	}
+	if val := os.Getenv("BOT_RATE_LIMIT"); val != "" {
+		if limit, err := strconv.Atoi(val); err == nil {
+			c.Bot.RateLimit = limit
+		}
+	}
+	if val := os.Getenv("BOT_RATE_WINDOW"); val != "" {
+		c.Bot.RateWindow = val
+	}
+	
+	// 流入元の判定設定
+	if val := os.Getenv("REFERRER_SOURCES_PATH"); val != "" {
+		c.Referrer.SourcesPath = val
+	}
+	
+	// Partition設定
+	if val := os.Getenv("PARTITION_ENABLED"); val != "" {
+		c.Partition.Enabled = val == "true"
+	}
+	if val := os.Getenv("PARTITION_GRANULARITY"); val != "" {
+		c.Partition.Granularity = val
+	}
+	if val := os.Getenv("PARTITION_INTERVAL"); val != "" {
+		c.Partition.Interval = val
+	}
+	if val := os.Getenv("PARTITION_PREMAKE"); val != "" {
+		if premake, err := strconv.Atoi(val); err == nil {
+			c.Partition.Premake = premake
+		}
+	}
+	if val := os.Getenv("PARTITION_RETAIN"); val != "" {
+		if retain, err := strconv.Atoi(val); err == nil {
+			c.Partition.Retain = retain
+		}
+	}
+	if val := os.Getenv("PARTITION_DROP_EXPIRED"); val != "" {
+		c.Partition.DropExpired = val == "true"
+	}
 	
 	return c.Validate()
 }
@@ -229,6 +611,21 @@ func (c *Config) Validate() error {
 	if c.Redis.Port <= 0 || c.Redis.Port > 65535 {
 		return errors.New("redis port must be between 1 and 65535")
 	}
+	if c.Redis.FailureMode != "" && c.Redis.FailureMode != "open" && c.Redis.FailureMode != "closed" {
+		return errors.New("redis failure mode must be open or closed")
+	}
+	
+	// APIキー設定の検証
+	if c.APIKeys.RotationGracePeriod != "" {
+		if d, err := time.ParseDuration(c.APIKeys.RotationGracePeriod); err != nil || d < 0 {
+			return errors.New("api key rotation grace period must be a non-negative duration")
+		}
+	}
+	
+	// Partition設定の検証
+	if c.Partition.Granularity != "" && c.Partition.Granularity != "month" && c.Partition.Granularity != "day" {
+		return errors.New("partition granularity must be month or day")
+	}
 	
 	return nil
 }
@@ -245,6 +642,113 @@ func (c *Config) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%d", c.Redis.Host, c.Redis.Port)
 }
 
+// GetRedisProbeInterval はRedisへの疎通確認の間隔を返します
+// 解析できない場合は0を返します
+func (c *Config) GetRedisProbeInterval() time.Duration {
+	d, _ := time.ParseDuration(c.Redis.ProbeInterval)
+	return d
+}
+
+// GetAdminJWTSecret は管理APIのJWT検証に使う署名鍵を返します
+// 開発用のデフォルト値のままの場合は第三者がトークンを偽造できるため空文字を返します
+func (c *Config) GetAdminJWTSecret() string {
+	if c.JWT.Secret == DefaultJWTSecret {
+		return ""
+	}
+	return c.JWT.Secret
+}
+
+// GetIngestionFlushInterval はインジェストのフラッシュ間隔を返します
+// 解析できない場合は0を返します
+func (c *Config) GetIngestionFlushInterval() time.Duration {
+	d, _ := time.ParseDuration(c.Ingestion.FlushInterval)
+	return d
+}
+
+// GetIngestionWriteTimeout はインジェストの書き込みタイムアウトを返します
+// 解析できない場合は0を返します
+func (c *Config) GetIngestionWriteTimeout() time.Duration {
+	d, _ := time.ParseDuration(c.Ingestion.WriteTimeout)
+	return d
+}
+
+// GetIngestionRetryBackoff はインジェストの書き込みを再試行するまでの最初の待機時間を返します
+// 解析できない場合は0を返します
+func (c *Config) GetIngestionRetryBackoff() time.Duration {
+	d, _ := time.ParseDuration(c.Ingestion.RetryBackoff)
+	return d
+}
+
+// GetRollupInterval はロールアップの実行間隔を返します
+// 解析できない場合は0を返します
+func (c *Config) GetRollupInterval() time.Duration {
+	d, _ := time.ParseDuration(c.Rollup.Interval)
+	return d
+}
+
+// GetRollupDelay はバケットの終わりからロールアップするまでの猶予を返します
+// 解析できない場合は0を返します
+func (c *Config) GetRollupDelay() time.Duration {
+	d, _ := time.ParseDuration(c.Rollup.Delay)
+	return d
+}
+
+// GetRetentionInterval はデータ保持期間によるクリーンアップの実行間隔を返します
+// 解析できない場合は0を返します
+func (c *Config) GetRetentionInterval() time.Duration {
+	d, _ := time.ParseDuration(c.Retention.Interval)
+	return d
+}
+
+// GetPartitionInterval はパーティション管理の実行間隔を返します
+// 解析できない場合は0を返します
+func (c *Config) GetPartitionInterval() time.Duration {
+	d, _ := time.ParseDuration(c.Partition.Interval)
+	return d
+}
+
+// GetUsageReconcileInterval は月間の使用量の突き合わせの実行間隔を返します
+// 解析できない場合は0を返します
+func (c *Config) GetUsageReconcileInterval() time.Duration {
+	d, _ := time.ParseDuration(c.Usage.ReconcileInterval)
+	return d
+}
+
+// GetGeoIPReloadInterval はGeoIPデータベースファイルの変更を確認する間隔を返します
+// 解析できない場合は0を返します
+func (c *Config) GetGeoIPReloadInterval() time.Duration {
+	d, _ := time.ParseDuration(c.GeoIP.ReloadInterval)
+	return d
+}
+
+// GetBotRateWindow はボットの判定でイベント数を数える期間を返します
+// 解析できない場合は0を返します
+func (c *Config) GetBotRateWindow() time.Duration {
+	d, _ := time.ParseDuration(c.Bot.RateWindow)
+	return d
+}
+
+// GetAPIKeyRotationGracePeriod はAPIキーのローテーションの猶予期間のデフォルト値を返します
+// 解析できない場合は0を返します
+func (c *Config) GetAPIKeyRotationGracePeriod() time.Duration {
+	d, _ := time.ParseDuration(c.APIKeys.RotationGracePeriod)
+	return d
+}
+
+// GetAppCacheLocalTTL はプロセス内のアプリケーションのキャッシュの有効期間を返します
+// 解析できない場合は0を返します
+func (c *Config) GetAppCacheLocalTTL() time.Duration {
+	d, _ := time.ParseDuration(c.AppCache.LocalTTL)
+	return d
+}
+
+// GetAppCacheInvalidKeyTTL は存在しないAPIキーをキャッシュする期間を返します
+// 解析できない場合は0を返します
+func (c *Config) GetAppCacheInvalidKeyTTL() time.Duration {
+	d, _ := time.ParseDuration(c.AppCache.InvalidKeyTTL)
+	return d
+}
+
 // GetCORSAllowedOrigins はCORS許可オリジンのリストを返します
 func (c *Config) GetCORSAllowedOrigins() []string {
 	if c.CORS.AllowedOrigins == "" {
@@ -268,3 +772,16 @@ func (c *Config) GetCORSAllowedHeaders() []string {
 	}
 	return strings.Split(c.CORS.AllowedHeaders, ",")
 }
+
+// GetCORSExposedHeaders はCORSで公開するレスポンスヘッダーのリストを返します
+func (c *Config) GetCORSExposedHeaders() []string {
+	if c.CORS.ExposedHeaders == "" {
+		return []string{}
+	}
+	return strings.Split(c.CORS.ExposedHeaders, ",")
+}
+
+// GetCORSMaxAge はプリフライトリクエストのキャッシュ時間を返します
+func (c *Config) GetCORSMaxAge() time.Duration {
+	return time.Duration(c.CORS.MaxAge) * time.Second
+}
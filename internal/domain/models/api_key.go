@@ -0,0 +1,119 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultAPIKeyName はアプリケーション作成時に発行するAPIキーの名前です
+const DefaultAPIKeyName = "default"
+
+// APIキーのスコープ
+const (
+	// ScopeIngest はトラッキングデータの送信を許可します（HTMLに埋め込む公開用のキー）
+	ScopeIngest = "ingest"
+	// ScopeStatsRead は統計の参照を許可します（サーバー側でのみ使用するキー）
+	ScopeStatsRead = "stats:read"
+	// ScopeAppsAdmin はキーが属するアプリケーションの管理APIの利用を許可します
+	ScopeAppsAdmin = "apps:admin"
+)
+
+// APIKeyScopes は指定できるスコープの一覧です
+var APIKeyScopes = []string{ScopeIngest, ScopeStatsRead, ScopeAppsAdmin}
+
+// DefaultAPIKeyScopes はスコープを指定せずに発行したキーのスコープです
+// HTMLに埋め込まれても統計を参照できないよう送信のみを許可し、統計の参照は明示的に指定した場合のみ許可します
+var DefaultAPIKeyScopes = []string{ScopeIngest}
+
+const (
+	// DefaultAPIKeyRotationGracePeriod はローテーション後に以前のキーを有効なままにする期間のデフォルト値です
+	DefaultAPIKeyRotationGracePeriod = 24 * time.Hour
+	// MaxAPIKeyRotationGracePeriod はローテーションの猶予期間の上限です
+	MaxAPIKeyRotationGracePeriod = 30 * 24 * time.Hour
+)
+
+// APIKey はアプリケーションのAPIキーを表すモデルです
+// キー本体はソルト付きハッシュとして保存し、平文は発行時にのみ返します
+type APIKey struct {
+	ID         string     `json:"id" db:"id"`
+	AppID      string     `json:"app_id" db:"app_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"key_prefix"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	// SigningSecret はリクエスト署名の検証に使うシークレットです（空の場合は署名不要）
+	SigningSecret string `json:"-" db:"signing_secret"`
+}
+
+// RequiresSignature はAPIキーで認証するリクエストに署名が必要かどうかを判定します
+func (k *APIKey) RequiresSignature() bool {
+	return k.SigningSecret != ""
+}
+
+// Validate はAPIキーの妥当性を検証します
+func (k *APIKey) Validate(now time.Time) error {
+	if strings.TrimSpace(k.Name) == "" {
+		return ErrAPIKeyNameRequired
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(now) {
+		return ErrAPIKeyInvalidExpiry
+	}
+	for _, scope := range k.Scopes {
+		if !IsValidAPIKeyScope(scope) {
+			return ErrAPIKeyInvalidScope
+		}
+	}
+	return nil
+}
+
+// HasScopes はAPIキーが指定したすべてのスコープを持つかどうかを判定します
+func (k *APIKey) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		found := false
+		for _, s := range k.Scopes {
+			if s == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidAPIKeyScope は指定できるスコープかどうかを判定します
+func IsValidAPIKeyScope(scope string) bool {
+	for _, s := range APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired はAPIキーが有効期限切れかどうかを判定します
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(now)
+}
+
+// ValidateRotationGracePeriod はローテーションの猶予期間が 0 以上、上限以下であることを検証します
+func ValidateRotationGracePeriod(gracePeriod time.Duration) error {
+	if gracePeriod < 0 || gracePeriod > MaxAPIKeyRotationGracePeriod {
+		return ErrAPIKeyInvalidGracePeriod
+	}
+	return nil
+}
+
+// APIKeyRotation はAPIキーのローテーション結果です
+type APIKeyRotation struct {
+	// Key は新しく発行したキーです
+	Key *APIKey
+	// Secret は新しいキーの平文です（保存されないため、この結果でのみ取得できる）
+	Secret string
+	// Previous はローテーション前のキーです（ExpiresAt は猶予期間の終わり）
+	Previous *APIKey
+}
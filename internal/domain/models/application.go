@@ -13,10 +13,19 @@ type Application struct {
 	Name        string                 `json:"name" db:"name"`
 	Description string                 `json:"description" db:"description"`
 	Domain      string                 `json:"domain" db:"domain"`
-	APIKey      string                 `json:"api_key" db:"api_key"`
+	// APIKey は平文のAPIキーです（発行時と、APIキーで取得した場合のみ設定され、保存はされない）
+	APIKey      string                 `json:"api_key,omitempty" db:"-"`
 	Active      bool                   `json:"is_active" db:"is_active"`
+	Timezone    string                 `json:"timezone" db:"timezone"`
+	// Plan はレート制限と月間のイベント数の上限を決めるプランです（空の場合は DefaultPlan）
+	Plan        string                 `json:"plan" db:"plan"`
+	Settings    map[string]interface{} `json:"settings,omitempty" db:"settings"`
+	// OrganizationID はアプリケーションを所有する組織のIDです（空の場合はスーパーユーザーのみ管理できる）
+	OrganizationID string              `json:"organization_id,omitempty" db:"organization_id"`
 	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
+	// AuthenticatedKey は取得に使用したAPIキーです（APIキーで取得した場合のみ設定される）
+	AuthenticatedKey *APIKey           `json:"-" db:"-"`
 }
 
 // Validate はアプリケーションの妥当性を検証します
@@ -36,9 +45,46 @@ func (a *Application) Validate() error {
 	if a.APIKey == "" {
 		return ErrApplicationAPIKeyRequired
 	}
+	if !IsValidTimezone(a.Timezone) {
+		return ErrApplicationInvalidTimezone
+	}
 	return nil
 }
 
+// DefaultApplicationTimezone はタイムゾーン未設定のアプリケーションで使うタイムゾーンです
+const DefaultApplicationTimezone = "UTC"
+
+// Location はアプリケーションのタイムゾーンを返します（未設定・不正な場合はUTC）
+func (a *Application) Location() *time.Location {
+	loc, err := LoadTimezone(a.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// LoadTimezone はIANAタイムゾーン名からタイムゾーンを読み込みます（空の場合はUTC）
+func LoadTimezone(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	// "Local" はサーバーの設定に依存するため受け付けない
+	if timezone == "Local" {
+		return nil, ErrApplicationInvalidTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrApplicationInvalidTimezone
+	}
+	return loc, nil
+}
+
+// IsValidTimezone はIANAタイムゾーン名が有効かどうかを判定します（空の場合は有効）
+func IsValidTimezone(timezone string) bool {
+	_, err := LoadTimezone(timezone)
+	return err == nil
+}
+
 // IsValidDomain はドメインが有効かどうかを判定します
 func (a *Application) IsValidDomain() bool {
 	// 簡易的なドメイン検証
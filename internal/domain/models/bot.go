@@ -0,0 +1,60 @@
+package models
+
+import "fmt"
+
+// ボットのトラフィックのアプリケーション設定キー
+const (
+	SettingBotPolicy = "bot_policy" // ボットと判定したイベントの扱い
+)
+
+// CustomParamWebdriver はブラウザが自動化されている（navigator.webdriver が true）ことを示すカスタムパラメータです
+const CustomParamWebdriver = "webdriver"
+
+// ボットと判定したイベントの扱い
+const (
+	// BotPolicyFlag はイベントを保存し、スコアと理由を記録してボットとして集計します（デフォルト）
+	BotPolicyFlag = "flag"
+	// BotPolicyDrop はボットと判定したイベントを保存しません
+	BotPolicyDrop = "drop"
+	// BotPolicyKeep はボットの判定を行わずにイベントを保存します
+	BotPolicyKeep = "keep"
+)
+
+// BotPolicy はアプリケーションの bot_policy 設定を返します（未設定・不正な場合は flag）
+func (a *Application) BotPolicy() string {
+	if policy, ok := a.Settings[SettingBotPolicy].(string); ok && isValidBotPolicy(policy) {
+		return policy
+	}
+	return BotPolicyFlag
+}
+
+// isValidBotPolicy はボットのトラフィックの扱いが有効かどうかを判定します
+func isValidBotPolicy(policy string) bool {
+	switch policy {
+	case BotPolicyFlag, BotPolicyDrop, BotPolicyKeep:
+		return true
+	}
+	return false
+}
+
+// validateBotSettings はボットのトラフィックに関するアプリケーション設定を検証します
+func validateBotSettings(settings map[string]interface{}) error {
+	if value, ok := settings[SettingBotPolicy]; ok && value != nil {
+		if policy, _ := value.(string); !isValidBotPolicy(policy) {
+			return fmt.Errorf("%w: %s must be %q, %q or %q", ErrApplicationInvalidSettings, SettingBotPolicy, BotPolicyFlag, BotPolicyDrop, BotPolicyKeep)
+		}
+	}
+	return nil
+}
+
+// HasWebdriverHint はカスタムパラメータ webdriver でブラウザの自動化が示されているかどうかを判定します
+// ビーコンのクエリパラメータから受け取った文字列の "true" も含みます
+func (t *TrackingData) HasWebdriverHint() bool {
+	switch v := t.CustomParams[CustomParamWebdriver].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true" || v == "1"
+	}
+	return false
+}
@@ -11,6 +11,37 @@ var (
 	ErrApplicationNotFound         = errors.New("application not found")
 	ErrApplicationAlreadyExists    = errors.New("application already exists")
 	ErrApplicationInvalidAPIKey    = errors.New("invalid API key")
+	ErrApplicationInvalidTimezone  = errors.New("invalid timezone")
+	ErrApplicationInvalidSettings  = errors.New("invalid application settings")
+	ErrApplicationInvalidPlan      = errors.New("plan must be free, pro or enterprise")
+)
+
+// 使用量関連のエラー
+var (
+	ErrQuotaExceeded               = errors.New("monthly event quota exceeded")
+)
+
+// APIキー関連のエラー
+var (
+	ErrAPIKeyNotFound              = errors.New("api key not found")
+	ErrAPIKeyAlreadyExists         = errors.New("api key already exists")
+	ErrAPIKeyNameRequired          = errors.New("api key name is required")
+	ErrAPIKeyInvalidExpiry         = errors.New("expires_at must be in the future")
+	ErrAPIKeyInvalidGracePeriod    = errors.New("grace_period_seconds must be between 0 and 2592000")
+	ErrAPIKeyInvalidScope          = errors.New("scopes must be ingest, stats:read or apps:admin")
+)
+
+// 組織・ユーザー関連のエラー
+var (
+	ErrOrganizationNotFound        = errors.New("organization not found")
+	ErrOrganizationNameRequired    = errors.New("organization name is required")
+	ErrOrganizationRequired        = errors.New("organization_id is required")
+	ErrUserNotFound                = errors.New("user not found")
+	ErrUserEmailRequired           = errors.New("a valid email is required")
+	ErrUserAlreadyExists           = errors.New("user already exists")
+	ErrMembershipNotFound          = errors.New("membership not found")
+	ErrMembershipInvalidRole       = errors.New("role must be owner, admin or viewer")
+	ErrMembershipLastOwner         = errors.New("organization must have at least one owner")
 )
 
 // トラッキングデータ関連のエラー
@@ -21,6 +52,8 @@ var (
 	ErrTrackingTimestampRequired   = errors.New("timestamp is required")
 	ErrTrackingDataNotFound        = errors.New("tracking data not found")
 	ErrTrackingInvalidData         = errors.New("invalid tracking data")
+	ErrTrackingOriginNotAllowed    = errors.New("origin is not allowed for this application")
+	ErrTrackingBotDropped          = errors.New("tracking data dropped as bot traffic")
 )
 
 // セッション関連のエラー
@@ -35,9 +68,39 @@ var (
 	ErrStatisticsNotFound          = errors.New("statistics not found")
 	ErrStatisticsInvalidPeriod     = errors.New("invalid statistics period")
 	ErrStatisticsInvalidMetric     = errors.New("invalid statistics metric")
+	ErrStatisticsInvalidGroupBy    = errors.New("invalid statistics group_by")
+	ErrStatisticsInvalidInterval   = errors.New("invalid statistics interval")
+	ErrStatisticsTooManyPoints     = errors.New("too many time series points")
 )
 
 // バリデーション関連のエラー
 var (
 	ErrValidationError             = errors.New("validation error")
 )
+
+// ValidationError は入力データの検証に失敗したことを表すエラーです
+// 元のエラーをラップするため errors.Is による判定も維持されます
+type ValidationError struct {
+	Err error
+}
+
+// NewValidationError は新しい検証エラーを作成します
+func NewValidationError(err error) *ValidationError {
+	return &ValidationError{Err: err}
+}
+
+// Error はエラーメッセージを返します
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap は元のエラーを返します
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// IsValidationError はエラーが検証エラーかどうかを判定します
+func IsValidationError(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// 組織メンバーのロール
+const (
+	RoleOwner  = "owner"  // 組織とメンバーを含むすべてを管理できる
+	RoleAdmin  = "admin"  // アプリケーションとオーナー以外のメンバーを管理できる
+	RoleViewer = "viewer" // アプリケーションの参照のみできる
+)
+
+// Organization はアプリケーションを所有する組織（クライアント）を表すモデルです
+type Organization struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate は組織の妥当性を検証します
+func (o *Organization) Validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return ErrOrganizationNameRequired
+	}
+	return nil
+}
+
+// User は管理APIを利用するユーザーを表すモデルです
+type User struct {
+	ID        string    `json:"id" db:"id"`
+	Email     string    `json:"email" db:"email"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate はユーザーの妥当性を検証します
+func (u *User) Validate() error {
+	if !strings.Contains(u.Email, "@") {
+		return ErrUserEmailRequired
+	}
+	return nil
+}
+
+// Membership は組織へのユーザーの所属とロールを表すモデルです
+type Membership struct {
+	OrganizationID string    `json:"organization_id" db:"organization_id"`
+	UserID         string    `json:"user_id" db:"user_id"`
+	Role           string    `json:"role" db:"role"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsValidRole はロールが有効かどうかを判定します
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleOwner, RoleAdmin, RoleViewer:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanManageRole はロール actor のメンバーがロール target を付与・変更・削除できるかどうかを判定します
+// オーナーはすべてのロールを、管理者はオーナー以外のロールを扱えます
+func CanManageRole(actor, target string) bool {
+	switch actor {
+	case RoleOwner:
+		return true
+	case RoleAdmin:
+		return target != RoleOwner
+	default:
+		return false
+	}
+}
+
+// Principal は管理APIの呼び出し元を表すモデルです
+type Principal struct {
+	UserID    string            // ユーザーID（マスター管理トークンの場合は空）
+	Superuser bool              // すべての組織・アプリケーションにアクセスできる
+	Roles     map[string]string // 組織IDごとのロール
+	AppID     string            // apps:admin スコープのAPIキーで認証した場合、キーが属するアプリケーション
+}
+
+// CanAccessApplication はアプリケーションにアクセスできるかどうかを判定します
+// APIキーで認証した呼び出し元は、キーが属するアプリケーションのみ参照・管理できます
+// それ以外の呼び出し元は組織のロール（CanView / CanManage）で判定します
+func (p *Principal) CanAccessApplication(appID string) bool {
+	return p.AppID == "" || p.AppID == appID
+}
+
+// Role は組織でのロールを返します（所属していない場合は空文字）
+func (p *Principal) Role(organizationID string) string {
+	if organizationID == "" {
+		return ""
+	}
+	return p.Roles[organizationID]
+}
+
+// CanView は組織のリソースを参照できるかどうかを判定します
+// 組織に属さないアプリケーションはスーパーユーザーのみ参照できます
+func (p *Principal) CanView(organizationID string) bool {
+	return p.Superuser || IsValidRole(p.Role(organizationID))
+}
+
+// CanManage は組織のアプリケーションを作成・更新・削除できるかどうかを判定します
+func (p *Principal) CanManage(organizationID string) bool {
+	if p.Superuser {
+		return true
+	}
+	role := p.Role(organizationID)
+	return role == RoleOwner || role == RoleAdmin
+}
+
+// CanManageMember は組織のロール role のメンバーを付与・変更・削除できるかどうかを判定します
+func (p *Principal) CanManageMember(organizationID, role string) bool {
+	return p.Superuser || CanManageRole(p.Role(organizationID), role)
+}
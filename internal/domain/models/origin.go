@@ -0,0 +1,210 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// 許可オリジンのアプリケーション設定キー
+const (
+	SettingAllowedOrigins = "allowed_origins" // ドメイン以外に許可するオリジンまたはホスト
+	SettingOriginPolicy   = "origin_policy"   // 許可されない送信元からのイベントの扱い
+)
+
+// 許可されない送信元からのイベントの扱い
+const (
+	// OriginPolicyFlag はイベントを保存し、カスタムパラメータ origin_mismatch を付けます（デフォルト）
+	OriginPolicyFlag = "flag"
+	// OriginPolicyReject はイベントを保存せずに拒否します
+	OriginPolicyReject = "reject"
+)
+
+// CustomParamOriginMismatch は許可されない送信元からのイベントに付けるカスタムパラメータです
+const CustomParamOriginMismatch = "origin_mismatch"
+
+// MaxAllowedOrigins は allowed_origins に設定できるオリジン数の上限です
+const MaxAllowedOrigins = 100
+
+// OriginAllowlist は許可するオリジンのパターンの一覧です
+//
+// パターンは次のいずれかです
+//   - "https://shop.example.com" のようにスキームを含むオリジン（スキームとホスト、ポートが一致する場合に許可）
+//   - "example.com" のようなホスト（スキームとポートは問わない）
+//   - "*.example.com" のようなワイルドカード（サブドメインのみ許可し、example.com 自体は含まない）
+//   - "*"（すべてのオリジンを許可）
+type OriginAllowlist []string
+
+// Allows はオリジンまたはURLのホストが許可リストのいずれかのパターンに一致するかどうかを判定します
+func (l OriginAllowlist) Allows(rawURL string) bool {
+	u, ok := parseOriginURL(rawURL)
+	if !ok {
+		return false
+	}
+	for _, pattern := range l {
+		if matchOriginPattern(pattern, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedOrigins はアプリケーションのドメインと allowed_origins 設定から許可リストを作成します
+func (a *Application) AllowedOrigins() OriginAllowlist {
+	var origins OriginAllowlist
+	if domain := strings.TrimSpace(a.Domain); domain != "" {
+		origins = append(origins, domain)
+	}
+	if extra, err := settingOrigins(a.Settings[SettingAllowedOrigins]); err == nil {
+		origins = append(origins, extra...)
+	}
+	return origins
+}
+
+// OriginPolicy はアプリケーションの origin_policy 設定を返します（未設定・不正な場合は flag）
+func (a *Application) OriginPolicy() string {
+	if policy, ok := a.Settings[SettingOriginPolicy].(string); ok && policy == OriginPolicyReject {
+		return OriginPolicyReject
+	}
+	return OriginPolicyFlag
+}
+
+// CheckTrackingOrigin はイベントの送信元（Origin ヘッダーとページURLのホスト）がアプリケーションで許可されているかを検証します
+// 許可されない場合、origin_policy が reject なら ErrTrackingOriginNotAllowed を返し、
+// flag ならカスタムパラメータ origin_mismatch を設定します
+// 許可リストが空のアプリケーションは検証しません
+func (a *Application) CheckTrackingOrigin(data *TrackingData, origin string) error {
+	allowlist := a.AllowedOrigins()
+	if len(allowlist) == 0 {
+		return nil
+	}
+
+	mismatch := origin != "" && !allowlist.Allows(origin)
+	// 相対パスなどホストを含まないページURLは照合しない
+	if _, ok := parseOriginURL(data.URL); ok && !allowlist.Allows(data.URL) {
+		mismatch = true
+	}
+
+	if !mismatch {
+		// クライアントが送信した印は信用しない
+		delete(data.CustomParams, CustomParamOriginMismatch)
+		return nil
+	}
+	if a.OriginPolicy() == OriginPolicyReject {
+		return ErrTrackingOriginNotAllowed
+	}
+	if data.CustomParams == nil {
+		data.CustomParams = make(map[string]interface{})
+	}
+	data.CustomParams[CustomParamOriginMismatch] = true
+	return nil
+}
+
+// IsValidOriginPattern は許可リストに指定できるパターンかどうかを判定します
+func IsValidOriginPattern(pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	host := pattern
+	if i := strings.Index(pattern, "://"); i >= 0 {
+		if scheme := pattern[:i]; scheme != "http" && scheme != "https" {
+			return false
+		}
+		host = strings.TrimSuffix(pattern[i+3:], "/")
+	}
+	if host == "" || strings.ContainsAny(host, " \t/?#@") {
+		return false
+	}
+
+	host = strings.TrimPrefix(host, "*.")
+	if host == "" || strings.Contains(host, "*") {
+		return false
+	}
+	u, err := url.Parse("http://" + host)
+	return err == nil && u.Hostname() != ""
+}
+
+// validateOriginSettings は許可オリジンに関するアプリケーション設定を検証します
+func validateOriginSettings(settings map[string]interface{}) error {
+	if value, ok := settings[SettingAllowedOrigins]; ok && value != nil {
+		if _, err := settingOrigins(value); err != nil {
+			return fmt.Errorf("%w: %s %v", ErrApplicationInvalidSettings, SettingAllowedOrigins, err)
+		}
+	}
+	if value, ok := settings[SettingOriginPolicy]; ok && value != nil {
+		if policy, _ := value.(string); policy != OriginPolicyFlag && policy != OriginPolicyReject {
+			return fmt.Errorf("%w: %s must be %q or %q", ErrApplicationInvalidSettings, SettingOriginPolicy, OriginPolicyFlag, OriginPolicyReject)
+		}
+	}
+	return nil
+}
+
+// settingOrigins は設定値を許可オリジンのパターンの一覧に変換します
+func settingOrigins(value interface{}) ([]string, error) {
+	var values []interface{}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		values = v
+	case []string:
+		for _, s := range v {
+			values = append(values, s)
+		}
+	default:
+		return nil, fmt.Errorf("must be an array of origins")
+	}
+
+	if len(values) > MaxAllowedOrigins {
+		return nil, fmt.Errorf("must not contain more than %d origins", MaxAllowedOrigins)
+	}
+
+	origins := make([]string, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be an array of origins")
+		}
+		pattern := strings.ToLower(strings.TrimSpace(s))
+		if !IsValidOriginPattern(pattern) {
+			return nil, fmt.Errorf("contains invalid origin %q", s)
+		}
+		origins = append(origins, pattern)
+	}
+	return origins, nil
+}
+
+// parseOriginURL はオリジンまたはURLを解析します（ホストを含まない場合は false）
+func parseOriginURL(rawURL string) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// matchOriginPattern はURLが許可リストのパターンに一致するかどうかを判定します
+func matchOriginPattern(pattern string, u *url.URL) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "*" {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if i := strings.Index(pattern, "://"); i >= 0 {
+		// スキームを含むパターンはスキームとポートも一致させる
+		if pattern[:i] != strings.ToLower(u.Scheme) {
+			return false
+		}
+		pattern = strings.TrimSuffix(pattern[i+3:], "/")
+		host = strings.ToLower(u.Host)
+	} else if strings.Contains(pattern, ":") {
+		// ポートを含むホストはポートも一致させる
+		host = strings.ToLower(u.Host)
+	}
+
+	if suffix := strings.TrimPrefix(pattern, "*"); suffix != pattern {
+		return strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return host == pattern
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// access_logs のパーティションの単位
+const (
+	PartitionGranularityMonth = "month"
+	PartitionGranularityDay   = "day"
+)
+
+// Partition は access_logs の範囲パーティションを表すモデルです
+// From 以上 To 未満のタイムスタンプのデータを保持します
+type Partition struct {
+	Name string    `json:"name"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Contains は時刻がパーティションの範囲に含まれるかどうかを判定します
+func (p Partition) Contains(t time.Time) bool {
+	return !t.Before(p.From) && t.Before(p.To)
+}
+
+// IsValidPartitionGranularity はパーティションの単位が有効かどうかを判定します
+func IsValidPartitionGranularity(granularity string) bool {
+	return granularity == PartitionGranularityMonth || granularity == PartitionGranularityDay
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// プラン名
+const (
+	PlanFree       = "free"
+	PlanPro        = "pro"
+	PlanEnterprise = "enterprise"
+)
+
+// DefaultPlan はプラン未設定のアプリケーションに適用するプランです
+const DefaultPlan = PlanFree
+
+// Plan はアプリケーションのプランを表すモデルです
+// 制限値が0の場合はその制限を適用しません
+type Plan struct {
+	Name              string `json:"name"`
+	RequestsPerMinute int    `json:"requests_per_minute"` // アプリケーション全体の1分間のリクエスト数の上限
+	RequestsPerHour   int    `json:"requests_per_hour"`   // アプリケーション全体の1時間のリクエスト数の上限
+	BurstSize         int    `json:"burst_size"`          // 連続して受け付けるリクエスト数の上限
+	MonthlyEventQuota int64  `json:"monthly_event_quota"` // 1か月（UTC）に受け付けるイベント数の上限
+}
+
+// plans はプランの定義です
+var plans = map[string]Plan{
+	PlanFree: {
+		Name:              PlanFree,
+		RequestsPerMinute: 2000,
+		RequestsPerHour:   20000,
+		BurstSize:         200,
+		MonthlyEventQuota: 1000000,
+	},
+	PlanPro: {
+		Name:              PlanPro,
+		RequestsPerMinute: 10000,
+		RequestsPerHour:   200000,
+		BurstSize:         1000,
+		MonthlyEventQuota: 20000000,
+	},
+	PlanEnterprise: {
+		Name:              PlanEnterprise,
+		RequestsPerMinute: 50000,
+		RequestsPerHour:   1000000,
+		BurstSize:         5000,
+		MonthlyEventQuota: 0,
+	},
+}
+
+// LookupPlan はプラン名からプランの定義を取得します
+func LookupPlan(name string) (Plan, bool) {
+	plan, ok := plans[name]
+	return plan, ok
+}
+
+// IsValidPlan はプラン名が有効かどうかを判定します
+func IsValidPlan(name string) bool {
+	_, ok := plans[name]
+	return ok
+}
+
+// Plans はすべてのプランの定義を上限の小さい順に返します
+func Plans() []Plan {
+	result := make([]Plan, 0, len(plans))
+	for _, plan := range plans {
+		result = append(result, plan)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].RequestsPerMinute < result[j].RequestsPerMinute
+	})
+	return result
+}
+
+// PlanLimits はアプリケーションのプランの定義を返します（未設定・不明なプランの場合は DefaultPlan）
+func (a *Application) PlanLimits() Plan {
+	if plan, ok := plans[a.Plan]; ok {
+		return plan
+	}
+	return plans[DefaultPlan]
+}
+
+// Usage はアプリケーションの当月のイベント数を表すモデルです
+type Usage struct {
+	AppID       string    `json:"app_id"`
+	Plan        string    `json:"plan"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Events      int64     `json:"events"`
+	Quota       int64     `json:"quota"`     // 0 の場合は上限なし
+	Remaining   int64     `json:"remaining"` // 上限なしの場合は -1
+}
+
+// NewUsage はプランと期間のイベント数から使用量を作成します
+func NewUsage(appID string, plan Plan, period time.Time, events int64) *Usage {
+	usage := &Usage{
+		AppID:       appID,
+		Plan:        plan.Name,
+		PeriodStart: period,
+		PeriodEnd:   NextUsagePeriod(period),
+		Events:      events,
+		Quota:       plan.MonthlyEventQuota,
+		Remaining:   -1,
+	}
+	if usage.Quota > 0 {
+		usage.Remaining = usage.Quota - events
+		if usage.Remaining < 0 {
+			usage.Remaining = 0
+		}
+	}
+	return usage
+}
+
+// UsagePeriod は時刻が含まれる使用量の集計期間（UTCの月）の開始時刻を返します
+func UsagePeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// NextUsagePeriod は次の集計期間の開始時刻を返します
+func NextUsagePeriod(period time.Time) time.Time {
+	return UsagePeriod(period).AddDate(0, 1, 0)
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// データ保持期間のアプリケーション設定キー
+const (
+	SettingRetentionRawDays    = "retention_raw_days"    // access_logs の保持日数
+	SettingRetentionRollupDays = "retention_rollup_days" // ロールアップの保持日数
+)
+
+// MaxRetentionDays は設定できる保持日数の上限です
+const MaxRetentionDays = 36500
+
+// RetentionPolicy はアプリケーションのデータ保持期間を表すモデルです
+// 日数が0の場合は削除しません
+type RetentionPolicy struct {
+	AppID      string `json:"app_id"`
+	RawDays    int    `json:"raw_days"`
+	RollupDays int    `json:"rollup_days"`
+}
+
+// IsEnabled は保持期間が設定されているかどうかを判定します
+func (p RetentionPolicy) IsEnabled() bool {
+	return p.RawDays > 0 || p.RollupDays > 0
+}
+
+// RetentionRun は1アプリケーションのクリーンアップ結果を表すモデルです
+type RetentionRun struct {
+	AppID          string        `json:"app_id"`
+	StartedAt      time.Time     `json:"started_at"`
+	Duration       time.Duration `json:"duration"`
+	EventsDeleted  int64         `json:"events_deleted"`
+	RollupsDeleted int64         `json:"rollups_deleted"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// RetentionPolicy はアプリケーション設定からデータ保持期間を取得します
+func (a *Application) RetentionPolicy() RetentionPolicy {
+	return RetentionPolicyFromSettings(a.AppID, a.Settings)
+}
+
+// RetentionPolicyFromSettings はアプリケーション設定からデータ保持期間を取得します
+// 不正な値は0（削除しない）として扱います
+func RetentionPolicyFromSettings(appID string, settings map[string]interface{}) RetentionPolicy {
+	policy := RetentionPolicy{AppID: appID}
+	if days, err := settingDays(settings[SettingRetentionRawDays]); err == nil {
+		policy.RawDays = days
+	}
+	if days, err := settingDays(settings[SettingRetentionRollupDays]); err == nil {
+		policy.RollupDays = days
+	}
+	return policy
+}
+
+// ValidateSettings はアプリケーション設定の既知のキーを検証します
+// 値が null のキーは設定の削除を表します
+func ValidateSettings(settings map[string]interface{}) error {
+	for _, key := range []string{SettingRetentionRawDays, SettingRetentionRollupDays} {
+		value, ok := settings[key]
+		if !ok || value == nil {
+			continue
+		}
+		if _, err := settingDays(value); err != nil {
+			return fmt.Errorf("%w: %s %v", ErrApplicationInvalidSettings, key, err)
+		}
+	}
+	if err := validateBotSettings(settings); err != nil {
+		return err
+	}
+	if err := validateURLRulesSettings(settings); err != nil {
+		return err
+	}
+	return validateOriginSettings(settings)
+}
+
+// settingDays は設定値を0以上の日数に変換します
+func settingDays(value interface{}) (int, error) {
+	var days float64
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		days = float64(v)
+	case int64:
+		days = float64(v)
+	case float64:
+		days = v
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("must be a number")
+		}
+		days = f
+	default:
+		return 0, fmt.Errorf("must be a number")
+	}
+
+	if days != math.Trunc(days) || days < 0 || days > MaxRetentionDays {
+		return 0, fmt.Errorf("must be an integer between 0 and %d", MaxRetentionDays)
+	}
+	return int(days), nil
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"accesslog-tracker/internal/utils/hll"
+)
+
+// ロールアップの集計粒度
+const (
+	RollupGranularityHour = "hour"
+	RollupGranularityDay  = "day"
+)
+
+// ロールアップの集計軸
+const (
+	RollupDimensionTotal        = "total"
+	RollupDimensionURL          = "url" // 正規化したページURL（導入前のイベントはページURL）
+	RollupDimensionReferrerHost = "referrer_host"
+	RollupDimensionDeviceType   = "device_type"
+	RollupDimensionBrowser      = "browser"
+	RollupDimensionOS           = "os"
+	RollupDimensionCountry      = "country"
+	RollupDimensionChannel      = "channel"
+	RollupDimensionCampaign     = "campaign"
+	RollupDimensionPageGroup    = "page_group"
+)
+
+// RollupRow はアプリケーション・集計粒度・バケット・集計軸の値ごとの集計結果を表すモデルです
+// total 軸の Value は空文字列で、SessionSeconds は total 軸でのみ集計されます
+// SessionSketch・VisitorSketch はバケットをまたいで合算できるセッション・訪問者のスケッチ（hll でエンコード）です
+type RollupRow struct {
+	AppID          string    `json:"app_id"`
+	Granularity    string    `json:"granularity"`
+	Bucket         time.Time `json:"bucket"`
+	Dimension      string    `json:"dimension"`
+	Value          string    `json:"value"`
+	PageViews      int64     `json:"page_views"`
+	Sessions       int64     `json:"sessions"`
+	Visitors       int64     `json:"visitors"`
+	SessionSeconds float64   `json:"session_seconds"`
+	SessionSketch  []byte    `json:"-"`
+	VisitorSketch  []byte    `json:"-"`
+}
+
+// RollupRange はロールアップから読み込む範囲（Start 以上 End 未満）を表すモデルです
+type RollupRange struct {
+	Granularity string    `json:"granularity"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// RollupTotals はロールアップから集計した期間全体の値を表すモデルです
+// バケットをまたいで合算できる件数のみを持ち、セッション数・訪問者数はスケッチから推定します
+// Sessions はバケットごとのセッション数の合計で、SessionSeconds と合わせて平均セッション時間の計算に使います
+type RollupTotals struct {
+	PageViews      int64   `json:"page_views"`
+	BotRequests    int64   `json:"bot_requests"`
+	MobileRequests int64   `json:"mobile_requests"`
+	Sessions       int64   `json:"sessions"`
+	SessionSeconds float64 `json:"session_seconds"`
+}
+
+// UniqueSketch は集計軸の値ごとのセッション・訪問者のスケッチを表すモデルです
+// スケッチのないロールアップ（スケッチの導入前に集計した行）は、バケットごとのユニーク数の合計を
+// LegacySessions・LegacyVisitors に加えます（バケットをまたぐセッション・訪問者は重複して数えます）
+type UniqueSketch struct {
+	Key            string
+	Sessions       *hll.Sketch
+	Visitors       *hll.Sketch
+	LegacySessions int64
+	LegacyVisitors int64
+}
+
+// NewUniqueSketch は空のスケッチを作成します
+func NewUniqueSketch(key string) *UniqueSketch {
+	return &UniqueSketch{Key: key, Sessions: hll.New(), Visitors: hll.New()}
+}
+
+// Merge は other のセッション・訪問者を加えます
+func (u *UniqueSketch) Merge(other *UniqueSketch) {
+	u.Sessions.Merge(other.Sessions)
+	u.Visitors.Merge(other.Visitors)
+	u.LegacySessions += other.LegacySessions
+	u.LegacyVisitors += other.LegacyVisitors
+}
+
+// UniqueSessions はユニークなセッション数の推定値を返します
+func (u *UniqueSketch) UniqueSessions() int64 {
+	return u.Sessions.Count() + u.LegacySessions
+}
+
+// UniqueVisitors はユニークな訪問者数の推定値を返します
+func (u *UniqueSketch) UniqueVisitors() int64 {
+	return u.Visitors.Count() + u.LegacyVisitors
+}
@@ -5,6 +5,11 @@ import (
 	"errors"
 	"strings"
 	"time"
+
+	"accesslog-tracker/internal/botdetect"
+	"accesslog-tracker/internal/referrer"
+	"accesslog-tracker/internal/uaparser"
+	"accesslog-tracker/internal/urlnorm"
 )
 
 // TrackingData はトラッキングデータを表すモデルです
@@ -21,6 +26,43 @@ type TrackingData struct {
 	Timestamp    time.Time              `json:"timestamp" db:"timestamp"`
 	CustomParams map[string]interface{} `json:"custom_params,omitempty" db:"custom_params"`
 	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
+
+	// ユーザーエージェントの解析結果（インジェスト時に設定）
+	Browser        string `json:"browser,omitempty" db:"browser"`
+	BrowserVersion string `json:"browser_version,omitempty" db:"browser_version"`
+	OS             string `json:"os,omitempty" db:"os"`
+	OSVersion      string `json:"os_version,omitempty" db:"os_version"`
+	DeviceFamily   string `json:"device_family,omitempty" db:"device_family"`
+	DeviceType     string `json:"device_type,omitempty" db:"device_type"`
+
+	// IPアドレスから判定した粗い位置情報（インジェスト時に匿名化の前に設定）
+	Country string `json:"country,omitempty" db:"country"`
+	Region  string `json:"region,omitempty" db:"region"`
+	City    string `json:"city,omitempty" db:"city"`
+	ASN     int64  `json:"asn,omitempty" db:"asn"`
+
+	// ボットの判定結果（インジェスト時に設定、理由は一致したシグナルのカンマ区切り）
+	BotScore  int    `json:"bot_score,omitempty" db:"bot_score"`
+	BotReason string `json:"bot_reason,omitempty" db:"bot_reason"`
+	// BotPolicy はアプリケーションの bot_policy 設定です（保存はされない、空の場合は flag）
+	BotPolicy string `json:"-" db:"-"`
+
+	// 流入元の判定結果（インジェスト時にリファラーとページURLのクエリから設定）
+	ReferrerHost string `json:"referrer_host,omitempty" db:"referrer_host"`
+	Channel      string `json:"channel,omitempty" db:"channel"`
+	UTMSource    string `json:"utm_source,omitempty" db:"utm_source"`
+	UTMMedium    string `json:"utm_medium,omitempty" db:"utm_medium"`
+	UTMCampaign  string `json:"utm_campaign,omitempty" db:"utm_campaign"`
+	UTMTerm      string `json:"utm_term,omitempty" db:"utm_term"`
+	UTMContent   string `json:"utm_content,omitempty" db:"utm_content"`
+	GCLID        string `json:"gclid,omitempty" db:"gclid"`
+	FBCLID       string `json:"fbclid,omitempty" db:"fbclid"`
+
+	// アプリケーションの url_rules で正規化したページURLとページのグループ（インジェスト時に設定）
+	PagePath  string `json:"page_path,omitempty" db:"page_path"`
+	PageGroup string `json:"page_group,omitempty" db:"page_group"`
+	// URLNormalizer はアプリケーションの url_rules 設定の正規化です（保存はされない、空の場合はデフォルトのルール）
+	URLNormalizer *urlnorm.Normalizer `json:"-" db:"-"`
 }
 
 // Validate はトラッキングデータの妥当性を検証します
@@ -113,6 +155,67 @@ type TrackingStats struct {
 	CreatedAt       time.Time `json:"created_at"`
 }
 
+// 統計のグループ化単位
+const (
+	StatsGroupByDay       = "day"
+	StatsGroupByHour      = "hour"
+	StatsGroupByPage      = "page"
+	StatsGroupByReferrer  = "referrer"
+	StatsGroupByBrowser   = "browser"
+	StatsGroupByOS        = "os"
+	StatsGroupByDevice    = "device"
+	StatsGroupByCountry   = "country"
+	StatsGroupByChannel   = "channel"
+	StatsGroupByCampaign  = "campaign"
+	StatsGroupByPageGroup = "page_group"
+)
+
+// IsValidStatsGroupBy は統計のグループ化単位が有効かどうかを判定します
+func IsValidStatsGroupBy(groupBy string) bool {
+	switch groupBy {
+	case StatsGroupByDay, StatsGroupByHour, StatsGroupByPage, StatsGroupByReferrer,
+		StatsGroupByBrowser, StatsGroupByOS, StatsGroupByDevice, StatsGroupByCountry,
+		StatsGroupByChannel, StatsGroupByCampaign, StatsGroupByPageGroup:
+		return true
+	}
+	return false
+}
+
+// StatsGroup はグループ化した統計の1行を表すモデルです
+// Key は日（YYYY-MM-DD）、時（YYYY-MM-DDTHH:00:00Z）、URL、リファラー、ブラウザ、OS、デバイスタイプ、国、チャネル、キャンペーン（utm_campaign）、ページのグループのいずれかです
+type StatsGroup struct {
+	Key            string `json:"key"`
+	Requests       int64  `json:"requests"`
+	UniqueSessions int64  `json:"unique_sessions"`
+	UniqueIPs      int64  `json:"unique_ips"`
+}
+
+// 時系列統計の集計間隔
+const (
+	TimeSeriesIntervalMinute = "minute"
+	TimeSeriesIntervalHour   = "hour"
+	TimeSeriesIntervalDay    = "day"
+	TimeSeriesIntervalWeek   = "week"
+	TimeSeriesIntervalMonth  = "month"
+)
+
+// IsValidTimeSeriesInterval は時系列統計の集計間隔が有効かどうかを判定します
+func IsValidTimeSeriesInterval(interval string) bool {
+	switch interval {
+	case TimeSeriesIntervalMinute, TimeSeriesIntervalHour, TimeSeriesIntervalDay, TimeSeriesIntervalWeek, TimeSeriesIntervalMonth:
+		return true
+	}
+	return false
+}
+
+// TimeSeriesPoint は時系列統計の1バケットを表すモデルです
+type TimeSeriesPoint struct {
+	Bucket    time.Time `json:"bucket"`
+	PageViews int64     `json:"page_views"`
+	Sessions  int64     `json:"sessions"`
+	Visitors  int64     `json:"visitors"`
+}
+
 // ToJSON はトラッキング統計をJSONに変換します
 func (t *TrackingStats) ToJSON() ([]byte, error) {
 	return json.Marshal(t)
@@ -123,35 +226,19 @@ func (t *TrackingStats) FromJSON(data []byte) error {
 	return json.Unmarshal(data, t)
 }
 
-// IsBot はユーザーエージェントがボットかどうかを判定します
+// IsBot はイベントがボットによるものかどうかを判定します
+// インジェスト時に判定した結果がある場合はスコアで、ない場合はユーザーエージェントのパターンで判定します
 func (t *TrackingData) IsBot() bool {
-	userAgent := strings.ToLower(t.UserAgent)
-	botKeywords := []string{
-		"bot", "crawler", "spider", "scraper", "googlebot", "bingbot", "yandexbot",
-		"baiduspider", "duckduckbot", "facebookexternalhit", "twitterbot",
+	if t.BotReason != "" {
+		return t.BotScore >= botdetect.BotThreshold
 	}
-	
-	for _, keyword := range botKeywords {
-		if strings.Contains(userAgent, keyword) {
-			return true
-		}
-	}
-	return false
+	return botdetect.MatchUserAgent(t.UserAgent)
 }
 
-// IsMobile はユーザーエージェントがモバイルデバイスかどうかを判定します
+// IsMobile はユーザーエージェントがモバイルデバイス（スマートフォン・タブレット）かどうかを判定します
 func (t *TrackingData) IsMobile() bool {
-	userAgent := strings.ToLower(t.UserAgent)
-	mobileKeywords := []string{
-		"mobile", "android", "iphone", "ipad", "ipod", "blackberry", "windows phone",
-	}
-	
-	for _, keyword := range mobileKeywords {
-		if strings.Contains(userAgent, keyword) {
-			return true
-		}
-	}
-	return false
+	deviceType := t.GetDeviceType()
+	return deviceType == uaparser.DeviceTypeMobile || deviceType == uaparser.DeviceTypeTablet
 }
 
 // GenerateID はトラッキングデータのIDを生成します
@@ -165,66 +252,92 @@ func (t *TrackingData) GenerateID() error {
 	return nil
 }
 
-// GetDeviceType はデバイスタイプを取得します
-func (t *TrackingData) GetDeviceType() string {
+// ParseUserAgent はユーザーエージェントを解析し、ブラウザ・OS・デバイスを設定します
+// ルールに一致しない値は "Unknown" とします
+func (t *TrackingData) ParseUserAgent(parser *uaparser.Parser) {
+	client := parser.Parse(t.UserAgent)
+	t.Browser = knownFamily(client.UserAgent.Family)
+	t.BrowserVersion = client.UserAgent.Version()
+	t.OS = knownFamily(client.OS.Family)
+	t.OSVersion = client.OS.Version()
+	t.DeviceFamily = knownFamily(client.Device.Family)
+	t.DeviceType = client.Device.Type
 	if t.IsBot() {
-		return "bot"
+		t.DeviceType = uaparser.DeviceTypeBot
 	}
-	if t.IsMobile() {
-		if strings.Contains(strings.ToLower(t.UserAgent), "ipad") {
-			return "tablet"
-		}
-		return "mobile"
+}
+
+// parsedUserAgent はユーザーエージェントの解析結果を返します
+// インジェスト時に解析していない場合は組み込みのルールで解析します
+func (t *TrackingData) parsedUserAgent() *TrackingData {
+	if t.DeviceType != "" {
+		return t
+	}
+	parsed := &TrackingData{UserAgent: t.UserAgent}
+	parsed.ParseUserAgent(uaparser.Default())
+	return parsed
+}
+
+// knownFamily はルールに一致しなかったファミリーを "Unknown" に置き換えます
+func knownFamily(family string) string {
+	if family == "" || family == uaparser.Other {
+		return "Unknown"
 	}
-	return "desktop"
+	return family
+}
+
+// GetDeviceType はデバイスタイプ（desktop, mobile, tablet, bot）を取得します
+func (t *TrackingData) GetDeviceType() string {
+	return t.parsedUserAgent().DeviceType
 }
 
 // GetBrowser はブラウザ名を取得します
 func (t *TrackingData) GetBrowser() string {
-	userAgent := strings.ToLower(t.UserAgent)
-	
-	// EdgeはChromeの前にチェックする必要がある（EdgeはChromeベース）
-	if strings.Contains(userAgent, "edg/") {
-		return "Edge"
-	}
-	if strings.Contains(userAgent, "chrome") {
-		return "Chrome"
-	}
-	if strings.Contains(userAgent, "firefox") {
-		return "Firefox"
-	}
-	if strings.Contains(userAgent, "safari") {
-		return "Safari"
-	}
-	if strings.Contains(userAgent, "opera") {
-		return "Opera"
-	}
-	
-	return "Unknown"
+	return t.parsedUserAgent().Browser
 }
 
 // GetOS はオペレーティングシステム名を取得します
 func (t *TrackingData) GetOS() string {
-	userAgent := strings.ToLower(t.UserAgent)
-	
-	if strings.Contains(userAgent, "windows") {
-		return "Windows"
-	}
-	// iOSはmacOSの前にチェックする必要がある（iPhone/iPadはmacOSベース）
-	if strings.Contains(userAgent, "iphone") || strings.Contains(userAgent, "ipad") || strings.Contains(userAgent, "ipod") {
-		return "iOS"
-	}
-	if strings.Contains(userAgent, "macintosh") || strings.Contains(userAgent, "mac os") {
-		return "macOS"
+	return t.parsedUserAgent().OS
+}
+
+// GetReferrerHost はリファラーのホスト名を小文字で取得します（直接流入や解析できない場合は空文字列）
+func (t *TrackingData) GetReferrerHost() string {
+	if t.ReferrerHost != "" {
+		return t.ReferrerHost
 	}
-	if strings.Contains(userAgent, "android") {
-		return "Android"
+	return referrer.Host(t.Referrer)
+}
+
+// GetChannel は流入元のチャネルを取得します
+// インジェスト時に判定していない場合は組み込みの流入元の一覧で判定します
+func (t *TrackingData) GetChannel() string {
+	if t.Channel != "" {
+		return t.Channel
 	}
-	if strings.Contains(userAgent, "linux") {
-		return "Linux"
+	return referrer.Default().Classify(t.URL, t.Referrer).Channel
+}
+
+// GetCampaign はキャンペーン（utm_campaign）を取得します（ない場合は空文字列）
+func (t *TrackingData) GetCampaign() string {
+	if t.Channel != "" {
+		return t.UTMCampaign
 	}
-	
-	return "Unknown"
+	return referrer.ParseCampaign(t.URL).Name
+}
+
+// ClassifyReferrer はリファラーとページURLのクエリから流入元のホスト・チャネル・キャンペーンを設定します
+func (t *TrackingData) ClassifyReferrer(classifier *referrer.Classifier) {
+	result := classifier.Classify(t.URL, t.Referrer)
+	t.ReferrerHost = result.Host
+	t.Channel = result.Channel
+	t.UTMSource = result.Campaign.Source
+	t.UTMMedium = result.Campaign.Medium
+	t.UTMCampaign = result.Campaign.Name
+	t.UTMTerm = result.Campaign.Term
+	t.UTMContent = result.Campaign.Content
+	t.GCLID = result.Campaign.GCLID
+	t.FBCLID = result.Campaign.FBCLID
 }
 
 // IsValidIP はIPアドレスが有効かどうかを判定します（静的関数）
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"accesslog-tracker/internal/urlnorm"
+)
+
+// ページURLの正規化のアプリケーション設定キー
+const (
+	SettingURLRules = "url_rules" // ページURLの正規化とグループ化のルール
+)
+
+// URLNormalizer はアプリケーションの url_rules 設定でページURLを正規化する正規化を返します
+// 未設定・不正な場合は urlnorm.DefaultRules で正規化します
+func (a *Application) URLNormalizer() *urlnorm.Normalizer {
+	if value, ok := a.Settings[SettingURLRules]; ok && value != nil {
+		if normalizer, err := ParseURLRules(value); err == nil {
+			return normalizer
+		}
+	}
+	return urlnorm.Default()
+}
+
+// ParseURLRules は url_rules 設定の値からページURLの正規化を作成します
+// 指定していない項目は urlnorm.DefaultRules の値になり、未知の項目はエラーにします
+func ParseURLRules(value interface{}) (*urlnorm.Normalizer, error) {
+	if value == nil {
+		return urlnorm.Default(), nil
+	}
+	if _, ok := value.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%w: %s must be an object", ErrApplicationInvalidSettings, SettingURLRules)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %v", ErrApplicationInvalidSettings, SettingURLRules, err)
+	}
+	rules := urlnorm.DefaultRules()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("%w: %s %v", ErrApplicationInvalidSettings, SettingURLRules, err)
+	}
+
+	normalizer, err := urlnorm.New(rules)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %v", ErrApplicationInvalidSettings, SettingURLRules, err)
+	}
+	return normalizer, nil
+}
+
+// validateURLRulesSettings はページURLの正規化に関するアプリケーション設定を検証します
+func validateURLRulesSettings(settings map[string]interface{}) error {
+	if value, ok := settings[SettingURLRules]; ok && value != nil {
+		if _, err := ParseURLRules(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeURL はページURLを正規化し、正規化したページURLとページのグループを設定します
+// URLNormalizer が設定されていない場合は urlnorm.DefaultRules で正規化します
+func (t *TrackingData) NormalizeURL() {
+	normalizer := t.URLNormalizer
+	if normalizer == nil {
+		normalizer = urlnorm.Default()
+	}
+	result := normalizer.Normalize(t.URL)
+	t.PagePath = result.PagePath
+	t.PageGroup = result.PageGroup
+}
+
+// GetPagePath は正規化したページURLを取得します（インジェスト時に正規化していない場合はページURL）
+func (t *TrackingData) GetPagePath() string {
+	if t.PagePath != "" {
+		return t.PagePath
+	}
+	return t.URL
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/crypto"
+	"accesslog-tracker/internal/utils/lru"
+)
+
+const (
+	// applicationCacheTTL はアプリケーションをRedisにキャッシュする期間です
+	applicationCacheTTL = 30 * time.Minute
+	// DefaultInvalidAPIKeyCacheTTL は無効なAPIキーをキャッシュする期間のデフォルト値です
+	DefaultInvalidAPIKeyCacheTTL = time.Minute
+	// DefaultLocalCacheSize はプロセス内のキャッシュに保持するエントリ数のデフォルト値です
+	DefaultLocalCacheSize = 10000
+	// DefaultLocalCacheTTL はプロセス内のキャッシュの有効期間のデフォルト値です
+	// 削除の通知を取りこぼしても、この期間が過ぎればRedisの値を読み直します
+	DefaultLocalCacheTTL = 30 * time.Second
+)
+
+// CacheInvalidator は他のインスタンスにプロセス内のキャッシュの削除を通知するインターフェースです
+type CacheInvalidator interface {
+	Publish(ctx context.Context, keys ...string) error
+}
+
+// WithLocalCache はRedisの手前に置くプロセス内のキャッシュの大きさと有効期間を設定します
+// size が 0 以下の場合はプロセス内のキャッシュを使いません
+func WithLocalCache(size int, ttl time.Duration) ApplicationServiceOption {
+	return func(s *ApplicationService) {
+		s.local = nil
+		if size > 0 {
+			s.local = lru.New[string, *cachedApplication](lru.Config{Size: size, TTL: ttl})
+		}
+	}
+}
+
+// WithCacheInvalidator はアプリケーションの更新・削除やキーのローテーション時に、他のインスタンスへキャッシュの削除を通知します
+func WithCacheInvalidator(invalidator CacheInvalidator) ApplicationServiceOption {
+	return func(s *ApplicationService) {
+		s.invalidator = invalidator
+	}
+}
+
+// WithInvalidAPIKeyCacheTTL は無効なAPIキーをキャッシュする期間を設定します（0 以下の場合はキャッシュしない）
+func WithInvalidAPIKeyCacheTTL(ttl time.Duration) ApplicationServiceOption {
+	return func(s *ApplicationService) {
+		s.invalidKeyTTL = ttl
+	}
+}
+
+// cachedApplication はキャッシュに保存するアプリケーションです
+// 平文のAPIキーは保存せず、取得時にリクエストのキーを設定します
+type cachedApplication struct {
+	Application *models.Application `json:"application,omitempty"`
+	// Key はAPIキーで取得した場合の認証に使用したキーです
+	Key *cachedAPIKey `json:"key,omitempty"`
+	// Invalid は存在しないAPIキーのエントリであることを表します
+	Invalid bool `json:"invalid,omitempty"`
+}
+
+// cachedAPIKey はキャッシュに保存するAPIキーです（署名の検証に使うシークレットを含む）
+type cachedAPIKey struct {
+	models.APIKey
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// newCachedApplication はアプリケーションからキャッシュのエントリを作成します
+func newCachedApplication(app *models.Application, withKey bool) *cachedApplication {
+	stored := *app
+	stored.APIKey = ""
+	stored.AuthenticatedKey = nil
+
+	entry := &cachedApplication{Application: &stored}
+	if withKey && app.AuthenticatedKey != nil {
+		entry.Key = &cachedAPIKey{APIKey: *app.AuthenticatedKey, SigningSecret: app.AuthenticatedKey.SigningSecret}
+	}
+	return entry
+}
+
+// application はエントリからアプリケーションのコピーを作成します
+func (e *cachedApplication) application(apiKey string) *models.Application {
+	app := *e.Application
+	app.APIKey = apiKey
+	if e.Key != nil {
+		key := e.Key.APIKey
+		key.SigningSecret = e.Key.SigningSecret
+		app.AuthenticatedKey = &key
+	}
+	return &app
+}
+
+// EvictLocal はプロセス内のキャッシュからキャッシュキーのエントリを削除します
+// 他のインスタンスからの削除の通知を反映するために使います
+func (s *ApplicationService) EvictLocal(keys ...string) {
+	if s.local == nil {
+		return
+	}
+	for _, key := range keys {
+		s.local.Remove(key)
+	}
+}
+
+// PurgeLocal はプロセス内のキャッシュのすべてのエントリを削除します
+func (s *ApplicationService) PurgeLocal() {
+	if s.local != nil {
+		s.local.Purge()
+	}
+}
+
+// getCached はプロセス内のキャッシュ、Redisの順にエントリを取得します
+func (s *ApplicationService) getCached(ctx context.Context, cacheKey string) *cachedApplication {
+	if s.local != nil {
+		if entry, ok := s.local.Get(cacheKey); ok {
+			return entry
+		}
+	}
+
+	var entry cachedApplication
+	if err := s.cache.GetJSON(ctx, cacheKey, &entry); err != nil {
+		return nil
+	}
+	if entry.Application == nil && !entry.Invalid {
+		return nil
+	}
+	if s.local != nil {
+		s.local.Add(cacheKey, &entry)
+	}
+	return &entry
+}
+
+// setCached はエントリをRedisとプロセス内のキャッシュに保存します
+func (s *ApplicationService) setCached(ctx context.Context, cacheKey string, entry *cachedApplication, ttl time.Duration) {
+	s.cache.SetJSON(ctx, cacheKey, entry, ttl)
+	if s.local != nil {
+		s.local.Add(cacheKey, entry)
+	}
+}
+
+// cacheApplication はアプリケーションをキャッシュに保存します
+func (s *ApplicationService) cacheApplication(ctx context.Context, app *models.Application) {
+	if app == nil || app.AppID == "" {
+		return
+	}
+
+	// アプリケーションIDでキャッシュ
+	s.setCached(ctx, applicationCacheKey(app.AppID), newCachedApplication(app, false), applicationCacheTTL)
+
+	// APIキーでキャッシュ（平文のキーは保存せず、ハッシュをキャッシュキーにする）
+	// キーのIDからもエントリを削除できるよう、IDからキャッシュキーへの対応も保存する
+	if app.APIKey != "" && app.AuthenticatedKey != nil {
+		apiKeyCacheKey := apiKeyCacheKey(app.APIKey)
+		s.setCached(ctx, apiKeyCacheKey, newCachedApplication(app, true), applicationCacheTTL)
+		s.cache.Set(ctx, apiKeyIDCacheKey(app.AuthenticatedKey.ID), apiKeyCacheKey, applicationCacheTTL)
+	}
+}
+
+// cacheInvalidAPIKey は存在しないAPIキーをキャッシュし、同じキーでの認証の試行がデータベースに届かないようにします
+func (s *ApplicationService) cacheInvalidAPIKey(ctx context.Context, apiKey string) {
+	if s.invalidKeyTTL <= 0 {
+		return
+	}
+	s.setCached(ctx, apiKeyCacheKey(apiKey), &cachedApplication{Invalid: true}, s.invalidKeyTTL)
+}
+
+// applicationCacheKey はアプリケーションIDのキャッシュキーを返します
+func applicationCacheKey(id string) string {
+	return "app:id:" + id
+}
+
+// apiKeyCacheKey はAPIキーのキャッシュキーを返します
+func apiKeyCacheKey(apiKey string) string {
+	return "app:apikey:" + crypto.HashSHA256(apiKey)
+}
+
+// apiKeyIDCacheKey はAPIキーのIDからキャッシュキーを引くためのキーを返します
+func apiKeyIDCacheKey(keyID string) string {
+	return "app:apikey:id:" + keyID
+}
+
+// deleteCachedApplication はキャッシュからアプリケーションと、そのすべてのAPIキーのエントリを削除します
+// APIキーのエントリもアプリケーションの設定やプランを含むため、あわせて削除します
+func (s *ApplicationService) deleteCachedApplication(ctx context.Context, id string, keys ...*models.APIKey) {
+	s.invalidate(ctx, append([]string{applicationCacheKey(id)}, s.apiKeyCacheKeys(ctx, keys...)...)...)
+}
+
+// deleteCachedAPIKeys はキャッシュからAPIキーのエントリを削除します
+func (s *ApplicationService) deleteCachedAPIKeys(ctx context.Context, keys ...*models.APIKey) {
+	s.invalidate(ctx, s.apiKeyCacheKeys(ctx, keys...)...)
+}
+
+// apiKeyCacheKeys はAPIキーのエントリと、IDからの対応のキャッシュキーを返します
+func (s *ApplicationService) apiKeyCacheKeys(ctx context.Context, keys ...*models.APIKey) []string {
+	cacheKeys := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		idCacheKey := apiKeyIDCacheKey(key.ID)
+		if cacheKey, err := s.cache.Get(ctx, idCacheKey); err == nil && cacheKey != "" {
+			cacheKeys = append(cacheKeys, cacheKey)
+		}
+		cacheKeys = append(cacheKeys, idCacheKey)
+	}
+	return cacheKeys
+}
+
+// invalidate はキャッシュキーをRedisとプロセス内のキャッシュから削除し、他のインスタンスに通知します
+// 通知に失敗した場合も、他のインスタンスのエントリはプロセス内のキャッシュの有効期間が過ぎると削除されます
+func (s *ApplicationService) invalidate(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	for _, key := range keys {
+		s.cache.Delete(ctx, key)
+	}
+	s.EvictLocal(keys...)
+
+	if s.invalidator != nil {
+		s.invalidator.Publish(ctx, keys...)
+	}
+}
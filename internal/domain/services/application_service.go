@@ -2,11 +2,13 @@ package services
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/domain/validators"
 	"accesslog-tracker/internal/utils/crypto"
+	"accesslog-tracker/internal/utils/lru"
 
 	"github.com/google/uuid"
 )
@@ -16,19 +18,29 @@ type ApplicationRepository interface {
 	Create(ctx context.Context, application *models.Application) error
 	GetByID(ctx context.Context, id string) (*models.Application, error)
 	GetByAPIKey(ctx context.Context, apiKey string) (*models.Application, error)
+	GetByAPIKeyID(ctx context.Context, keyID string) (*models.Application, error)
 	GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*models.Application, error)
+	CountByUserID(ctx context.Context, userID string) (int64, error)
 	Update(ctx context.Context, application *models.Application) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]*models.Application, error)
 	Count(ctx context.Context) (int64, error)
-	RegenerateAPIKey(ctx context.Context, id string) (string, error)
+	RotateAPIKey(ctx context.Context, appID, keyID string, newKey *models.APIKey, apiKey string, graceUntil time.Time) (*models.APIKey, error)
+	CreateAPIKey(ctx context.Context, key *models.APIKey, apiKey string) error
+	ListAPIKeys(ctx context.Context, appID string) ([]*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, appID, keyID string) error
+	SetAPIKeySigningSecret(ctx context.Context, appID, keyID, secret string) error
 	UpdateSettings(ctx context.Context, id string, settings map[string]interface{}) error
+	UpdatePlan(ctx context.Context, id, plan string) error
 }
 
 // CacheService はキャッシュサービスのインターフェースです
 type CacheService interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value string, expiration time.Duration) error
+	GetJSON(ctx context.Context, key string, dest interface{}) error
+	SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Delete(ctx context.Context, key string) error
 }
 
 // ApplicationServiceInterface はアプリケーションサービスのインターフェースです
@@ -36,28 +48,65 @@ type ApplicationServiceInterface interface {
 	Create(ctx context.Context, app *models.Application) error
 	GetByID(ctx context.Context, id string) (*models.Application, error)
 	GetByAPIKey(ctx context.Context, apiKey string) (*models.Application, error)
+	GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*models.Application, error)
+	CountByUserID(ctx context.Context, userID string) (int64, error)
 	Update(ctx context.Context, app *models.Application) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]*models.Application, error)
 	Count(ctx context.Context) (int64, error)
-	RegenerateAPIKey(ctx context.Context, id string) (string, error)
+	RotateAPIKey(ctx context.Context, appID, keyID string, gracePeriod *time.Duration) (*models.APIKeyRotation, error)
+	CreateAPIKey(ctx context.Context, appID, name string, scopes []string, expiresAt *time.Time, signed bool) (*models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, appID string) ([]*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, appID, keyID string) error
+	GenerateSigningSecret(ctx context.Context, appID, keyID string) (string, error)
+	DeleteSigningSecret(ctx context.Context, appID, keyID string) error
 	UpdateSettings(ctx context.Context, id string, settings map[string]interface{}) error
+	UpdatePlan(ctx context.Context, id, plan string) error
+	ReserveQuota(ctx context.Context, app *models.Application, events int) (*models.Usage, error)
+	CommitQuota(ctx context.Context, usage *models.Usage, reserved, accepted int) error
+	GetUsage(ctx context.Context, appID string) (*models.Usage, error)
 }
 
 // ApplicationService はアプリケーションのビジネスロジックを提供します
 type ApplicationService struct {
-	repo      ApplicationRepository
-	cache     CacheService
-	validator *validators.ApplicationValidator
+	repo        ApplicationRepository
+	cache       CacheService
+	validator   *validators.ApplicationValidator
+	gracePeriod time.Duration
+
+	local         *lru.Cache[string, *cachedApplication]
+	invalidator   CacheInvalidator
+	invalidKeyTTL time.Duration
+
+	usageCounter UsageCounter
+	usageRepo    UsageRepository
+}
+
+// ApplicationServiceOption はアプリケーションサービスのオプションです
+type ApplicationServiceOption func(*ApplicationService)
+
+// WithAPIKeyRotationGracePeriod はローテーション後に以前のキーを有効なままにする期間のデフォルト値を設定します
+func WithAPIKeyRotationGracePeriod(gracePeriod time.Duration) ApplicationServiceOption {
+	return func(s *ApplicationService) {
+		s.gracePeriod = gracePeriod
+	}
 }
 
 // NewApplicationService は新しいアプリケーションサービスを作成します
-func NewApplicationService(repo ApplicationRepository, cache CacheService) *ApplicationService {
-	return &ApplicationService{
-		repo:      repo,
-		cache:     cache,
-		validator: validators.NewApplicationValidator(),
+func NewApplicationService(repo ApplicationRepository, cache CacheService, opts ...ApplicationServiceOption) *ApplicationService {
+	s := &ApplicationService{
+		repo:        repo,
+		cache:       cache,
+		validator:   validators.NewApplicationValidator(),
+		gracePeriod: models.DefaultAPIKeyRotationGracePeriod,
+		local:       lru.New[string, *cachedApplication](lru.Config{Size: DefaultLocalCacheSize, TTL: DefaultLocalCacheTTL}),
+
+		invalidKeyTTL: DefaultInvalidAPIKeyCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // Create は新しいアプリケーションを作成します
@@ -99,8 +148,8 @@ func (s *ApplicationService) Create(ctx context.Context, app *models.Application
 // GetByID はIDでアプリケーションを取得します
 func (s *ApplicationService) GetByID(ctx context.Context, id string) (*models.Application, error) {
 	// キャッシュから取得を試行
-	if cached := s.getCachedApplication(ctx, id); cached != nil {
-		return cached, nil
+	if cached := s.getCached(ctx, applicationCacheKey(id)); cached != nil && cached.Application != nil {
+		return cached.application(""), nil
 	}
 
 	// リポジトリから取得
@@ -117,15 +166,25 @@ func (s *ApplicationService) GetByID(ctx context.Context, id string) (*models.Ap
 
 // GetByAPIKey はAPIキーでアプリケーションを取得します
 func (s *ApplicationService) GetByAPIKey(ctx context.Context, apiKey string) (*models.Application, error) {
-	// キャッシュから取得を試行
-	cacheKey := "app:apikey:" + apiKey
-	if cached, err := s.cache.Get(ctx, cacheKey); err == nil && cached != "" {
-		return s.GetByID(ctx, cached)
+	// キャッシュから取得を試行（削除・ローテーションしたキーのエントリはその時点で削除する）
+	cacheKey := apiKeyCacheKey(apiKey)
+	if cached := s.getCached(ctx, cacheKey); cached != nil {
+		if cached.Invalid {
+			return nil, models.ErrApplicationInvalidAPIKey
+		}
+		if cached.Key != nil && !cached.Key.IsExpired(time.Now()) {
+			return cached.application(apiKey), nil
+		}
+		// 猶予期間が過ぎたキーはリポジトリで再検証する
+		s.invalidate(ctx, cacheKey)
 	}
 
 	// リポジトリから取得
 	app, err := s.repo.GetByAPIKey(ctx, apiKey)
 	if err != nil {
+		if errors.Is(err, models.ErrApplicationInvalidAPIKey) {
+			s.cacheInvalidAPIKey(ctx, apiKey)
+		}
 		return nil, err
 	}
 
@@ -135,11 +194,16 @@ func (s *ApplicationService) GetByAPIKey(ctx context.Context, apiKey string) (*m
 	return app, nil
 }
 
-// GetByUserID はユーザーIDでアプリケーション一覧を取得します
+// GetByUserID はユーザーが所属する組織のアプリケーション一覧を取得します
 func (s *ApplicationService) GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*models.Application, error) {
 	return s.repo.GetByUserID(ctx, userID, limit, offset)
 }
 
+// CountByUserID はユーザーが所属する組織のアプリケーション数を取得します
+func (s *ApplicationService) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	return s.repo.CountByUserID(ctx, userID)
+}
+
 // Update はアプリケーションを更新します
 func (s *ApplicationService) Update(ctx context.Context, app *models.Application) error {
 	// バリデーション
@@ -155,21 +219,24 @@ func (s *ApplicationService) Update(ctx context.Context, app *models.Application
 		return err
 	}
 
-	// キャッシュを更新
-	s.cacheApplication(ctx, app)
+	// キャッシュを削除
+	s.deleteCachedApplication(ctx, app.AppID, s.listAPIKeys(ctx, app.AppID)...)
 
 	return nil
 }
 
 // Delete はアプリケーションを削除します
 func (s *ApplicationService) Delete(ctx context.Context, id string) error {
+	// 削除後はキーを取得できないため、キャッシュから削除するキーを先に取得
+	keys, _ := s.repo.ListAPIKeys(ctx, id)
+
 	// リポジトリから削除
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return err
 	}
 
 	// キャッシュから削除
-	s.deleteCachedApplication(ctx, id)
+	s.deleteCachedApplication(ctx, id, keys...)
 
 	return nil
 }
@@ -184,29 +251,143 @@ func (s *ApplicationService) Count(ctx context.Context) (int64, error) {
 	return s.repo.Count(ctx)
 }
 
-// RegenerateAPIKey はAPIキーを再生成します
-func (s *ApplicationService) RegenerateAPIKey(ctx context.Context, id string) (string, error) {
+// RotateAPIKey はAPIキーをローテーションします
+// 新しいキーを発行し、以前のキーは猶予期間（nil の場合はデフォルト値）の間だけ有効なままにします
+// 署名が必要なキーの場合は、新しいキー用の署名シークレットも発行します（Key.SigningSecret）
+func (s *ApplicationService) RotateAPIKey(ctx context.Context, appID, keyID string, gracePeriod *time.Duration) (*models.APIKeyRotation, error) {
+	grace := s.gracePeriod
+	if gracePeriod != nil {
+		grace = *gracePeriod
+	}
+
+	// バリデーション
+	if err := models.ValidateRotationGracePeriod(grace); err != nil {
+		return nil, models.NewValidationError(err)
+	}
+
+	now := time.Now()
+	key := &models.APIKey{CreatedAt: now}
+	apiKey := crypto.GenerateAPIKey()
+
 	// リポジトリで更新
-	newAPIKey, err := s.repo.RegenerateAPIKey(ctx, id)
+	previous, err := s.repo.RotateAPIKey(ctx, appID, keyID, key, apiKey, now.Add(grace))
 	if err != nil {
+		return nil, err
+	}
+
+	// キャッシュを削除（以前のキーは次回の認証時に新しい有効期限で再検証する）
+	s.deleteCachedAPIKeys(ctx, previous)
+
+	return &models.APIKeyRotation{Key: key, Secret: apiKey, Previous: previous}, nil
+}
+
+// CreateAPIKey はアプリケーションに名前付きのAPIキーを追加します
+// scopes を省略した場合は models.DefaultAPIKeyScopes のキーを発行します
+// signed の場合は署名シークレット（Key.SigningSecret）も発行し、キーで認証するリクエストに署名を必須にします
+// 平文のキーは保存されないため、戻り値でのみ取得できます
+func (s *ApplicationService) CreateAPIKey(ctx context.Context, appID, name string, scopes []string, expiresAt *time.Time, signed bool) (*models.APIKey, string, error) {
+	key := &models.APIKey{
+		AppID:     appID,
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	// バリデーション
+	if err := key.Validate(key.CreatedAt); err != nil {
+		return nil, "", models.NewValidationError(err)
+	}
+
+	if signed {
+		key.SigningSecret = crypto.GenerateSigningSecret()
+	}
+
+	apiKey := crypto.GenerateAPIKey()
+	if err := s.repo.CreateAPIKey(ctx, key, apiKey); err != nil {
+		return nil, "", err
+	}
+
+	return key, apiKey, nil
+}
+
+// ListAPIKeys はアプリケーションのAPIキー一覧を取得します
+func (s *ApplicationService) ListAPIKeys(ctx context.Context, appID string) ([]*models.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx, appID)
+}
+
+// DeleteAPIKey はアプリケーションのAPIキーを削除します
+func (s *ApplicationService) DeleteAPIKey(ctx context.Context, appID, keyID string) error {
+	if err := s.repo.DeleteAPIKey(ctx, appID, keyID); err != nil {
+		return err
+	}
+
+	// キャッシュから削除
+	s.deleteCachedAPIKeys(ctx, &models.APIKey{ID: keyID})
+
+	return nil
+}
+
+// GenerateSigningSecret はAPIキーの署名シークレットを発行します
+// 既にシークレットがある場合は置き換え、以前のシークレットによる署名は直ちに無効になります
+// キーで認証する以降のリクエストには署名が必須になります
+func (s *ApplicationService) GenerateSigningSecret(ctx context.Context, appID, keyID string) (string, error) {
+	secret := crypto.GenerateSigningSecret()
+	if err := s.repo.SetAPIKeySigningSecret(ctx, appID, keyID, secret); err != nil {
 		return "", err
 	}
 
-	// キャッシュを削除（APIキーが変更されたため）
-	s.deleteCachedApplication(ctx, id)
+	// 以前のシークレットで署名を検証しないよう、キャッシュから削除
+	s.deleteCachedAPIKeys(ctx, &models.APIKey{ID: keyID, AppID: appID})
 
-	return newAPIKey, nil
+	return secret, nil
+}
+
+// DeleteSigningSecret はAPIキーの署名シークレットを削除し、署名なしのリクエストを受け付けるようにします
+func (s *ApplicationService) DeleteSigningSecret(ctx context.Context, appID, keyID string) error {
+	if err := s.repo.SetAPIKeySigningSecret(ctx, appID, keyID, ""); err != nil {
+		return err
+	}
+
+	// キャッシュから削除
+	s.deleteCachedAPIKeys(ctx, &models.APIKey{ID: keyID, AppID: appID})
+
+	return nil
 }
 
 // UpdateSettings はアプリケーション設定を更新します
+// 指定したキーだけを上書きし、値が null のキーは削除します
 func (s *ApplicationService) UpdateSettings(ctx context.Context, id string, settings map[string]interface{}) error {
+	// 既知の設定値を検証
+	if err := models.ValidateSettings(settings); err != nil {
+		return err
+	}
+
 	// リポジトリで更新
 	if err := s.repo.UpdateSettings(ctx, id, settings); err != nil {
 		return err
 	}
 
 	// キャッシュを削除
-	s.deleteCachedApplication(ctx, id)
+	s.deleteCachedApplication(ctx, id, s.listAPIKeys(ctx, id)...)
+
+	return nil
+}
+
+// UpdatePlan はアプリケーションのプランを変更します
+// 変更後のレート制限と月間のイベント数の上限は次のリクエストから適用されます
+func (s *ApplicationService) UpdatePlan(ctx context.Context, id, plan string) error {
+	if !models.IsValidPlan(plan) {
+		return models.NewValidationError(models.ErrApplicationInvalidPlan)
+	}
+
+	// リポジトリで更新
+	if err := s.repo.UpdatePlan(ctx, id, plan); err != nil {
+		return err
+	}
+
+	// キャッシュを削除
+	s.deleteCachedApplication(ctx, id, s.listAPIKeys(ctx, id)...)
 
 	return nil
 }
@@ -222,37 +403,8 @@ func (s *ApplicationService) ValidateAPIKey(ctx context.Context, apiKey string)
 	return err == nil
 }
 
-// cacheApplication はアプリケーションをキャッシュに保存します
-func (s *ApplicationService) cacheApplication(ctx context.Context, app *models.Application) {
-	if app == nil || app.AppID == "" {
-		return
-	}
-
-	// アプリケーションIDでキャッシュ
-	cacheKey := "app:id:" + app.AppID
-	s.cache.Set(ctx, cacheKey, app.AppID, 30*time.Minute)
-
-	// APIキーでキャッシュ
-	if app.APIKey != "" {
-		apiKeyCacheKey := "app:apikey:" + app.APIKey
-		s.cache.Set(ctx, apiKeyCacheKey, app.AppID, 30*time.Minute)
-	}
-}
-
-// getCachedApplication はキャッシュからアプリケーションを取得します
-func (s *ApplicationService) getCachedApplication(ctx context.Context, id string) *models.Application {
-	cacheKey := "app:id:" + id
-	if cached, err := s.cache.Get(ctx, cacheKey); err == nil && cached != "" {
-		// キャッシュからIDを取得して、実際のデータを取得
-		if app, err := s.repo.GetByID(ctx, cached); err == nil {
-			return app
-		}
-	}
-	return nil
-}
-
-// deleteCachedApplication はキャッシュからアプリケーションを削除します
-func (s *ApplicationService) deleteCachedApplication(ctx context.Context, id string) {
-	cacheKey := "app:id:" + id
-	s.cache.Set(ctx, cacheKey, "", 1*time.Second) // 即座に期限切れにする
+// listAPIKeys はキャッシュから削除するアプリケーションのAPIキーを取得します（取得できない場合は nil）
+func (s *ApplicationService) listAPIKeys(ctx context.Context, id string) []*models.APIKey {
+	keys, _ := s.repo.ListAPIKeys(ctx, id)
+	return keys
 }
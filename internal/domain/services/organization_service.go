@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+// OrganizationRepository は組織・ユーザー・メンバーシップリポジトリのインターフェースです
+type OrganizationRepository interface {
+	CreateOrganization(ctx context.Context, org *models.Organization, ownerUserID string) error
+	GetOrganization(ctx context.Context, id string) (*models.Organization, error)
+	ListOrganizations(ctx context.Context) ([]*models.Organization, error)
+	ListOrganizationsByUserID(ctx context.Context, userID string) ([]*models.Organization, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUser(ctx context.Context, id string) (*models.User, error)
+	GetMembership(ctx context.Context, organizationID, userID string) (*models.Membership, error)
+	SetMember(ctx context.Context, membership *models.Membership) error
+	RemoveMember(ctx context.Context, organizationID, userID string) error
+	ListMembers(ctx context.Context, organizationID string) ([]*models.Membership, error)
+	CountOwners(ctx context.Context, organizationID string) (int, error)
+	GetUserRoles(ctx context.Context, userID string) (map[string]string, error)
+}
+
+// OrganizationServiceInterface は組織サービスのインターフェースです
+type OrganizationServiceInterface interface {
+	CreateOrganization(ctx context.Context, org *models.Organization, ownerUserID string) error
+	GetOrganization(ctx context.Context, id string) (*models.Organization, error)
+	ListOrganizations(ctx context.Context, principal *models.Principal) ([]*models.Organization, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	ListMembers(ctx context.Context, organizationID string) ([]*models.Membership, error)
+	SetMember(ctx context.Context, membership *models.Membership) error
+	RemoveMember(ctx context.Context, organizationID, userID string) error
+	GetMemberRole(ctx context.Context, organizationID, userID string) (string, error)
+	GetUserRoles(ctx context.Context, userID string) (map[string]string, error)
+}
+
+// OrganizationService は組織・ユーザー・メンバーシップのビジネスロジックを提供します
+type OrganizationService struct {
+	repo OrganizationRepository
+}
+
+// NewOrganizationService は新しい組織サービスを作成します
+func NewOrganizationService(repo OrganizationRepository) *OrganizationService {
+	return &OrganizationService{
+		repo: repo,
+	}
+}
+
+// CreateOrganization は組織を作成します
+// ownerUserID を指定した場合は、そのユーザーを組織のオーナーとして追加します
+func (s *OrganizationService) CreateOrganization(ctx context.Context, org *models.Organization, ownerUserID string) error {
+	if err := org.Validate(); err != nil {
+		return models.NewValidationError(err)
+	}
+
+	return s.repo.CreateOrganization(ctx, org, ownerUserID)
+}
+
+// GetOrganization はIDで組織を取得します
+func (s *OrganizationService) GetOrganization(ctx context.Context, id string) (*models.Organization, error) {
+	return s.repo.GetOrganization(ctx, id)
+}
+
+// ListOrganizations は呼び出し元が参照できる組織の一覧を取得します
+func (s *OrganizationService) ListOrganizations(ctx context.Context, principal *models.Principal) ([]*models.Organization, error) {
+	if principal.Superuser {
+		return s.repo.ListOrganizations(ctx)
+	}
+	return s.repo.ListOrganizationsByUserID(ctx, principal.UserID)
+}
+
+// CreateUser はユーザーを作成します
+func (s *OrganizationService) CreateUser(ctx context.Context, user *models.User) error {
+	if err := user.Validate(); err != nil {
+		return models.NewValidationError(err)
+	}
+
+	return s.repo.CreateUser(ctx, user)
+}
+
+// ListMembers は組織のメンバー一覧を取得します
+func (s *OrganizationService) ListMembers(ctx context.Context, organizationID string) ([]*models.Membership, error) {
+	return s.repo.ListMembers(ctx, organizationID)
+}
+
+// SetMember はユーザーを組織に追加するか、既存メンバーのロールを変更します
+// 最後のオーナーをオーナー以外に変更することはできません
+func (s *OrganizationService) SetMember(ctx context.Context, membership *models.Membership) error {
+	if !models.IsValidRole(membership.Role) {
+		return models.NewValidationError(models.ErrMembershipInvalidRole)
+	}
+
+	if _, err := s.repo.GetOrganization(ctx, membership.OrganizationID); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetUser(ctx, membership.UserID); err != nil {
+		return err
+	}
+
+	if membership.Role != models.RoleOwner {
+		if err := s.ensureNotLastOwner(ctx, membership.OrganizationID, membership.UserID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.SetMember(ctx, membership)
+}
+
+// RemoveMember はユーザーを組織から削除します
+// 最後のオーナーを削除することはできません
+func (s *OrganizationService) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	if err := s.ensureNotLastOwner(ctx, organizationID, userID); err != nil {
+		return err
+	}
+
+	return s.repo.RemoveMember(ctx, organizationID, userID)
+}
+
+// GetMemberRole は組織でのユーザーのロールを取得します（所属していない場合は空文字）
+func (s *OrganizationService) GetMemberRole(ctx context.Context, organizationID, userID string) (string, error) {
+	membership, err := s.repo.GetMembership(ctx, organizationID, userID)
+	if err == models.ErrMembershipNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return membership.Role, nil
+}
+
+// GetUserRoles はユーザーの組織IDごとのロールを取得します
+func (s *OrganizationService) GetUserRoles(ctx context.Context, userID string) (map[string]string, error) {
+	return s.repo.GetUserRoles(ctx, userID)
+}
+
+// ensureNotLastOwner はユーザーが組織の最後のオーナーでないことを確認します
+func (s *OrganizationService) ensureNotLastOwner(ctx context.Context, organizationID, userID string) error {
+	membership, err := s.repo.GetMembership(ctx, organizationID, userID)
+	if err == models.ErrMembershipNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if membership.Role != models.RoleOwner {
+		return nil
+	}
+
+	owners, err := s.repo.CountOwners(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return models.ErrMembershipLastOwner
+	}
+	return nil
+}
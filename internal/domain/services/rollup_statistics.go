@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+// RollupRepository はロールアップを読み込むリポジトリのインターフェースです
+type RollupRepository interface {
+	GetWatermark(ctx context.Context, granularity string) (time.Time, error)
+	GetRollupTotals(ctx context.Context, appID string, ranges []models.RollupRange) (*models.RollupTotals, error)
+	GetRollupGroups(ctx context.Context, appID, dimension string, ranges []models.RollupRange, limit int) ([]*models.StatsGroup, error)
+	GetRollupSeries(ctx context.Context, appID string, ranges []models.RollupRange) ([]*models.RollupRow, error)
+	GetRollupSketches(ctx context.Context, appID, dimension string, values []string, ranges []models.RollupRange) ([]*models.UniqueSketch, error)
+}
+
+// WithRollups は集計済みの範囲の統計をロールアップから読み込むよう設定します
+func WithRollups(repo RollupRepository) TrackingServiceOption {
+	return func(s *TrackingService) {
+		s.rollups = repo
+	}
+}
+
+// rollupPlan は統計の期間をロールアップで読む範囲と access_logs から読む範囲に分けたものです
+type rollupPlan struct {
+	Ranges   []models.RollupRange // ロールアップから読む範囲
+	RawStart time.Time            // これ以降は access_logs から読む
+}
+
+// planRollups は期間 [start, end) のうちロールアップで読める範囲を決めます
+// granularity が空の場合は時間別と日別を組み合わせ、指定した場合はその粒度のロールアップだけを使います
+// ロールアップが使えない場合は false を返し、呼び出し元は access_logs だけで集計します
+func (s *TrackingService) planRollups(ctx context.Context, start, end time.Time, granularity string) (*rollupPlan, bool) {
+	if s.rollups == nil || !start.Equal(start.Truncate(time.Hour)) {
+		return nil, false
+	}
+
+	hourWatermark, err := s.rollups.GetWatermark(ctx, models.RollupGranularityHour)
+	if err != nil || hourWatermark.IsZero() {
+		return nil, false
+	}
+	// 現在の時間帯は常に access_logs から読む
+	if currentHour := time.Now().Truncate(time.Hour); hourWatermark.After(currentHour) {
+		hourWatermark = currentHour
+	}
+
+	var dayWatermark time.Time
+	if granularity != models.RollupGranularityHour {
+		// 日別が読めない場合は時間別だけを使う
+		if dayWatermark, err = s.rollups.GetWatermark(ctx, models.RollupGranularityDay); err != nil {
+			dayWatermark = time.Time{}
+		}
+	}
+
+	return planRollupRanges(start, end, hourWatermark, dayWatermark, granularity)
+}
+
+// planRollupRanges は集計済みの終端から読み込む範囲を決めます
+// granularity が空の場合は丸1日の範囲を日別、それ以外を時間別のロールアップで読みます
+// 日別だけを使う場合は、開始時刻がUTCの日の区切りで、丸1日が集計済みのときに限ります
+func planRollupRanges(start, end, hourWatermark, dayWatermark time.Time, granularity string) (*rollupPlan, bool) {
+	covered := end.Truncate(time.Hour)
+	if hourWatermark.Before(covered) {
+		covered = hourWatermark
+	}
+	if !covered.After(start) {
+		return nil, false
+	}
+
+	plan := &rollupPlan{RawStart: covered}
+	addRange := func(granularity string, from, to time.Time) {
+		if from.Before(to) {
+			plan.Ranges = append(plan.Ranges, models.RollupRange{Granularity: granularity, Start: from, End: to})
+		}
+	}
+
+	dayStart := startOfUTCDay(start)
+	if dayStart.Before(start) {
+		dayStart = dayStart.AddDate(0, 0, 1)
+	}
+	dayEnd := covered
+	if dayWatermark.Before(dayEnd) {
+		dayEnd = dayWatermark
+	}
+	dayEnd = startOfUTCDay(dayEnd)
+
+	switch granularity {
+	case models.RollupGranularityHour:
+		addRange(models.RollupGranularityHour, start, covered)
+	case models.RollupGranularityDay:
+		if dayWatermark.IsZero() || !dayStart.Equal(start) || !dayStart.Before(dayEnd) {
+			return nil, false
+		}
+		plan.RawStart = dayEnd
+		addRange(models.RollupGranularityDay, dayStart, dayEnd)
+	default:
+		if !dayWatermark.IsZero() && dayStart.Before(dayEnd) {
+			addRange(models.RollupGranularityHour, start, dayStart)
+			addRange(models.RollupGranularityDay, dayStart, dayEnd)
+			addRange(models.RollupGranularityHour, dayEnd, covered)
+		} else {
+			addRange(models.RollupGranularityHour, start, covered)
+		}
+	}
+
+	return plan, true
+}
+
+// seriesRollupGranularity は日・時単位のグループ化で使うロールアップの粒度を返します
+// バケットごとのユニーク数はバケットをまたいで合算できないため、グループと同じ粒度のロールアップだけを使います
+func seriesRollupGranularity(groupBy string) string {
+	switch groupBy {
+	case models.StatsGroupByHour:
+		return models.RollupGranularityHour
+	case models.StatsGroupByDay:
+		return models.RollupGranularityDay
+	}
+	return ""
+}
+
+// startOfUTCDay はUTCの日の開始時刻を返します
+func startOfUTCDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// getStatisticsFromRollups は集計済みの範囲をロールアップから、残りを access_logs から集計して統計を作成します
+// access_logs は集計済みの終端以降だけを読むため、保持期間を過ぎて削除された範囲もロールアップから集計できます
+// セッション数・訪問者数はスケッチを合算した推定値、平均セッション時間はバケットごとのセッション時間から求めた近似値のため、
+// 統計は近似値（Approximate）として返します
+func (s *TrackingService) getStatisticsFromRollups(ctx context.Context, query StatisticsQuery, stats *TrackingStatistics, plan *rollupPlan) (*TrackingStatistics, error) {
+	totals, err := s.rollups.GetRollupTotals(ctx, query.AppID, plan.Ranges)
+	if err != nil {
+		return nil, err
+	}
+	sketches, err := s.rollups.GetRollupSketches(ctx, query.AppID, models.RollupDimensionTotal, []string{""}, plan.Ranges)
+	if err != nil {
+		return nil, err
+	}
+	uniques := models.NewUniqueSketch("")
+	for _, sketch := range sketches {
+		uniques.Merge(sketch)
+	}
+
+	// バケットをまたぐセッションはバケットごとに分かれるため、平均セッション時間は近似値になる
+	sessions := float64(totals.Sessions)
+	sessionSeconds := totals.SessionSeconds
+
+	if !plan.RawStart.After(query.EndDate) {
+		summary, err := s.repo.GetStatsByAppID(ctx, query.AppID, plan.RawStart, query.EndDate)
+		if err != nil {
+			return nil, err
+		}
+
+		totals.PageViews += summary.TotalRequests
+		totals.BotRequests += summary.BotRequests
+		totals.MobileRequests += summary.MobileRequests
+
+		tail, err := s.repo.GetUniqueSketches(ctx, query.AppID, "", nil, plan.RawStart, query.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		tailUniques := models.NewUniqueSketch("")
+		for _, sketch := range tail {
+			tailUniques.Merge(sketch)
+		}
+		tailAverage, err := s.repo.GetAverageSessionDuration(ctx, query.AppID, plan.RawStart, query.EndDate)
+		if err != nil {
+			return nil, err
+		}
+
+		uniques.Merge(tailUniques)
+		tailSessions := float64(tailUniques.Sessions.Count())
+		sessions += tailSessions
+		sessionSeconds += tailAverage * tailSessions
+	}
+
+	averageSession := 0.0
+	if sessions > 0 {
+		averageSession = sessionSeconds / sessions
+	}
+
+	stats.TotalRequests = totals.PageViews
+	stats.UniqueSessions = uniques.UniqueSessions()
+	stats.UniqueVisitors = uniques.UniqueVisitors()
+	stats.BotRequests = totals.BotRequests
+	stats.MobileRequests = totals.MobileRequests
+	stats.AverageSessionDuration = averageSession
+	stats.Approximate = true
+	stats.Metrics["total_tracking_count"] = totals.PageViews
+	stats.Metrics["period_days"] = int(stats.EndDate.Sub(stats.StartDate).Hours() / 24)
+
+	// 上位ページ・リファラー
+	if stats.TopPages, err = s.mergedRollupGroups(ctx, query, plan, models.StatsGroupByPage, query.Limit); err != nil {
+		return nil, err
+	}
+	if stats.TopReferrers, err = s.mergedRollupGroups(ctx, query, plan, models.StatsGroupByReferrer, query.Limit); err != nil {
+		return nil, err
+	}
+
+	// グループ化した統計
+	switch query.GroupBy {
+	case "":
+	case models.StatsGroupByDay, models.StatsGroupByHour:
+		if stats.Groups, err = s.mergedRollupSeriesGroups(ctx, query, plan); err != nil {
+			return nil, err
+		}
+	default:
+		if stats.Groups, err = s.mergedRollupGroups(ctx, query, plan, query.GroupBy, query.Limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+// rollupDimensions はグループ化単位に対応するロールアップの集計軸です
+var rollupDimensions = map[string]string{
+	models.StatsGroupByPage:      models.RollupDimensionURL,
+	models.StatsGroupByReferrer:  models.RollupDimensionReferrerHost,
+	models.StatsGroupByBrowser:   models.RollupDimensionBrowser,
+	models.StatsGroupByOS:        models.RollupDimensionOS,
+	models.StatsGroupByDevice:    models.RollupDimensionDeviceType,
+	models.StatsGroupByCountry:   models.RollupDimensionCountry,
+	models.StatsGroupByChannel:   models.RollupDimensionChannel,
+	models.StatsGroupByCampaign:  models.RollupDimensionCampaign,
+	models.StatsGroupByPageGroup: models.RollupDimensionPageGroup,
+}
+
+// mergedRollupGroups は日・時以外の単位のロールアップと access_logs の集計を合算し、件数の多い順に返します
+// 返すグループのセッション数・訪問者数はロールアップと access_logs のスケッチを合算して推定します
+func (s *TrackingService) mergedRollupGroups(ctx context.Context, query StatisticsQuery, plan *rollupPlan, groupBy string, limit int) ([]*models.StatsGroup, error) {
+	groups, err := s.rollups.GetRollupGroups(ctx, query.AppID, rollupDimensions[groupBy], plan.Ranges, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if !plan.RawStart.After(query.EndDate) {
+		// 残りの範囲は短いため件数を制限せずに合算する
+		tail, err := s.repo.GetGroupedStats(ctx, query.AppID, groupBy, plan.RawStart, query.EndDate, 0)
+		if err != nil {
+			return nil, err
+		}
+		groups = mergeStatsGroups(groups, tail)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Requests != groups[j].Requests {
+			return groups[i].Requests > groups[j].Requests
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	if limit > 0 && len(groups) > limit {
+		groups = groups[:limit]
+	}
+
+	if err := s.fillGroupUniqueCounts(ctx, query, plan, groupBy, groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// fillGroupUniqueCounts はグループのセッション数・訪問者数を、期間全体のスケッチを合算した推定値で置き換えます
+func (s *TrackingService) fillGroupUniqueCounts(ctx context.Context, query StatisticsQuery, plan *rollupPlan, groupBy string, groups []*models.StatsGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(groups))
+	for _, group := range groups {
+		keys = append(keys, group.Key)
+	}
+
+	sketches, err := s.rollups.GetRollupSketches(ctx, query.AppID, rollupDimensions[groupBy], keys, plan.Ranges)
+	if err != nil {
+		return err
+	}
+	if !plan.RawStart.After(query.EndDate) {
+		tail, err := s.repo.GetUniqueSketches(ctx, query.AppID, groupBy, keys, plan.RawStart, query.EndDate)
+		if err != nil {
+			return err
+		}
+		sketches = append(sketches, tail...)
+	}
+
+	index := mergeUniqueSketches(sketches)
+	for _, group := range groups {
+		group.UniqueSessions, group.UniqueIPs = 0, 0
+		if unique, ok := index[group.Key]; ok {
+			group.UniqueSessions = unique.UniqueSessions()
+			group.UniqueIPs = unique.UniqueVisitors()
+		}
+	}
+	return nil
+}
+
+// mergeUniqueSketches は同じキーのスケッチを合算します
+func mergeUniqueSketches(sketches []*models.UniqueSketch) map[string]*models.UniqueSketch {
+	merged := make(map[string]*models.UniqueSketch)
+	for _, sketch := range sketches {
+		existing, ok := merged[sketch.Key]
+		if !ok {
+			existing = models.NewUniqueSketch(sketch.Key)
+			merged[sketch.Key] = existing
+		}
+		existing.Merge(sketch)
+	}
+	return merged
+}
+
+// mergedRollupSeriesGroups は日・時単位のロールアップと access_logs の集計を合算し、時系列順に返します
+// ロールアップはグループと同じ粒度だけを読むため、各バケットはロールアップか access_logs のどちらか一方から集計されます
+func (s *TrackingService) mergedRollupSeriesGroups(ctx context.Context, query StatisticsQuery, plan *rollupPlan) ([]*models.StatsGroup, error) {
+	series, err := s.rollups.GetRollupSeries(ctx, query.AppID, plan.Ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := "2006-01-02"
+	if query.GroupBy == models.StatsGroupByHour {
+		layout = "2006-01-02T15:00:00Z"
+	}
+	groups := make([]*models.StatsGroup, 0, len(series))
+	for _, row := range series {
+		groups = append(groups, &models.StatsGroup{
+			Key:            row.Bucket.UTC().Format(layout),
+			Requests:       row.PageViews,
+			UniqueSessions: row.Sessions,
+			UniqueIPs:      row.Visitors,
+		})
+	}
+
+	if !plan.RawStart.After(query.EndDate) {
+		tail, err := s.repo.GetGroupedStats(ctx, query.AppID, query.GroupBy, plan.RawStart, query.EndDate, 0)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, tail...)
+	}
+
+	groups = mergeStatsGroups(groups)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups, nil
+}
+
+// mergeStatsGroups は同じキーのグループを合算します
+// セッション数・訪問者数は各グループのユニーク数の合計になるため、呼び出し元で期間全体の値に置き換えるか、
+// キーが重複しないグループだけを渡します
+func mergeStatsGroups(lists ...[]*models.StatsGroup) []*models.StatsGroup {
+	merged := make([]*models.StatsGroup, 0)
+	index := make(map[string]*models.StatsGroup)
+	for _, list := range lists {
+		for _, group := range list {
+			if existing, ok := index[group.Key]; ok {
+				existing.Requests += group.Requests
+				existing.UniqueSessions += group.UniqueSessions
+				existing.UniqueIPs += group.UniqueIPs
+				continue
+			}
+			copied := *group
+			index[group.Key] = &copied
+			merged = append(merged, &copied)
+		}
+	}
+	return merged
+}
+
+// timeSeriesRollupPlan は時系列統計でロールアップを使えるかを判定し、読み込む範囲を決めます
+// バケットごとのユニーク数はバケットをまたいで合算できないため、各バケットが1つのロールアップに対応する
+// 時単位（UTCとの時差が1時間単位のタイムゾーン）と、UTCの日単位でのみ使います
+func (s *TrackingService) timeSeriesRollupPlan(ctx context.Context, interval string, points []*models.TimeSeriesPoint, rangeEnd time.Time) (*rollupPlan, bool) {
+	if s.rollups == nil {
+		return nil, false
+	}
+
+	utc := true
+	for _, point := range points {
+		_, offset := point.Bucket.Zone()
+		if offset%3600 != 0 {
+			return nil, false
+		}
+		if offset != 0 {
+			utc = false
+		}
+	}
+
+	switch {
+	case interval == models.TimeSeriesIntervalHour:
+		return s.planRollups(ctx, points[0].Bucket, rangeEnd, models.RollupGranularityHour)
+	case interval == models.TimeSeriesIntervalDay && utc:
+		return s.planRollups(ctx, points[0].Bucket, rangeEnd, models.RollupGranularityDay)
+	}
+	return nil, false
+}
+
+// fillTimeSeriesFromRollups は集計済みの範囲をロールアップから、残りを access_logs から時系列のバケットに反映します
+func (s *TrackingService) fillTimeSeriesFromRollups(ctx context.Context, query TimeSeriesQuery, loc *time.Location, plan *rollupPlan, index map[int64]*models.TimeSeriesPoint, rangeEnd time.Time) error {
+	series, err := s.rollups.GetRollupSeries(ctx, query.AppID, plan.Ranges)
+	if err != nil {
+		return err
+	}
+	for _, row := range series {
+		bucket := truncateToInterval(row.Bucket.In(loc), query.Interval)
+		if point, ok := index[bucket.Unix()]; ok {
+			point.PageViews += row.PageViews
+			point.Sessions += row.Sessions
+			point.Visitors += row.Visitors
+		}
+	}
+
+	if plan.RawStart.Before(rangeEnd) {
+		return s.fillTimeSeries(ctx, query, loc, index, plan.RawStart, rangeEnd)
+	}
+	return nil
+}
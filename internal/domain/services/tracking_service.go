@@ -4,8 +4,13 @@ import (
 	"context"
 	"time"
 
+	"accesslog-tracker/internal/botdetect"
 	"accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/domain/validators"
+	"accesslog-tracker/internal/geoip"
+	"accesslog-tracker/internal/ingestion"
+	"accesslog-tracker/internal/referrer"
+	"accesslog-tracker/internal/uaparser"
 	"accesslog-tracker/internal/utils/iputil"
 	"accesslog-tracker/internal/utils/timeutil"
 
@@ -20,43 +25,170 @@ type TrackingRepository interface {
 	GetBySessionID(ctx context.Context, sessionID string) ([]*models.TrackingData, error)
 	CountByAppID(ctx context.Context, appID string) (int64, error)
 	Delete(ctx context.Context, id string) error
+	GetStatsByAppID(ctx context.Context, appID string, start, end time.Time) (*models.TrackingStats, error)
+	GetGroupedStats(ctx context.Context, appID, groupBy string, start, end time.Time, limit int) ([]*models.StatsGroup, error)
+	GetAverageSessionDuration(ctx context.Context, appID string, start, end time.Time) (float64, error)
+	GetUniqueSketches(ctx context.Context, appID, groupBy string, keys []string, start, end time.Time) ([]*models.UniqueSketch, error)
+	GetTimeSeries(ctx context.Context, appID, interval string, loc *time.Location, start, end time.Time) ([]*models.TimeSeriesPoint, error)
 }
 
 // TrackingServiceInterface はトラッキングサービスのインターフェースです
 type TrackingServiceInterface interface {
 	ProcessTrackingData(ctx context.Context, data *models.TrackingData) error
+	ProcessTrackingBatch(ctx context.Context, batch []*models.TrackingData) []error
 	GetByID(ctx context.Context, id string) (*models.TrackingData, error)
 	GetByAppID(ctx context.Context, appID string, limit, offset int) ([]*models.TrackingData, error)
 	GetBySessionID(ctx context.Context, sessionID string) ([]*models.TrackingData, error)
 	CountByAppID(ctx context.Context, appID string) (int64, error)
 	Delete(ctx context.Context, id string) error
-	GetStatistics(ctx context.Context, appID string, startDate, endDate time.Time) (*TrackingStatistics, error)
+	GetStatistics(ctx context.Context, query StatisticsQuery) (*TrackingStatistics, error)
+	GetTimeSeries(ctx context.Context, query TimeSeriesQuery) (*TimeSeries, error)
 }
 
 // TrackingService はトラッキングのビジネスロジックを提供します
 type TrackingService struct {
 	repo      TrackingRepository
 	validator *validators.TrackingValidator
+	pipeline  *ingestion.Pipeline
+	rollups   RollupRepository
+	uaParser  *uaparser.Parser
+	geo       GeoResolver
+	bots      BotDetector
+	referrers *referrer.Classifier
+}
+
+// BotDetector はイベントがボットによるものかを判定するインターフェースです
+type BotDetector interface {
+	Detect(event botdetect.Event) botdetect.Result
+}
+
+// GeoResolver はIPアドレスの位置情報を判定するインターフェースです
+type GeoResolver interface {
+	Lookup(ip string) (geoip.Location, bool)
+}
+
+// TrackingServiceOption はトラッキングサービスのオプションです
+type TrackingServiceOption func(*TrackingService)
+
+// WithIngestionPipeline はトラッキングデータをインジェストパイプライン経由で非同期に保存するよう設定します
+func WithIngestionPipeline(pipeline *ingestion.Pipeline) TrackingServiceOption {
+	return func(s *TrackingService) {
+		s.pipeline = pipeline
+	}
+}
+
+// WithUserAgentParser はインジェスト時にユーザーエージェントを解析するパーサーを設定します（デフォルトは組み込みのルール）
+func WithUserAgentParser(parser *uaparser.Parser) TrackingServiceOption {
+	return func(s *TrackingService) {
+		s.uaParser = parser
+	}
+}
+
+// WithGeoResolver はインジェスト時にIPアドレスから位置情報を判定するよう設定します
+func WithGeoResolver(resolver GeoResolver) TrackingServiceOption {
+	return func(s *TrackingService) {
+		s.geo = resolver
+	}
+}
+
+// WithBotDetector はインジェスト時にボットを判定する設定を指定します（デフォルトは組み込みのパターン）
+func WithBotDetector(detector BotDetector) TrackingServiceOption {
+	return func(s *TrackingService) {
+		s.bots = detector
+	}
+}
+
+// WithReferrerClassifier はインジェスト時に流入元のチャネルを判定する設定を指定します（デフォルトは組み込みの流入元の一覧）
+func WithReferrerClassifier(classifier *referrer.Classifier) TrackingServiceOption {
+	return func(s *TrackingService) {
+		s.referrers = classifier
+	}
 }
 
 // NewTrackingService は新しいトラッキングサービスを作成します
-func NewTrackingService(repo TrackingRepository) *TrackingService {
-	return &TrackingService{
+func NewTrackingService(repo TrackingRepository, opts ...TrackingServiceOption) *TrackingService {
+	s := &TrackingService{
 		repo:      repo,
 		validator: validators.NewTrackingValidator(),
+		uaParser:  uaparser.Default(),
+		bots:      botdetect.Default(),
+		referrers: referrer.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // ProcessTrackingData はトラッキングデータを処理します
 func (s *TrackingService) ProcessTrackingData(ctx context.Context, data *models.TrackingData) error {
+	if err := s.prepare(data); err != nil {
+		return err
+	}
+
+	// パイプラインが有効な場合はキューに積んで非同期に保存
+	if s.pipeline != nil {
+		return s.pipeline.Enqueue(data)
+	}
+
+	// リポジトリに保存
+	return s.repo.Create(ctx, data)
+}
+
+// ProcessTrackingBatch は複数のトラッキングデータをまとめて処理します
+// 戻り値はバッチと同じ長さで、各要素の処理結果（成功時は nil）を表します
+func (s *TrackingService) ProcessTrackingBatch(ctx context.Context, batch []*models.TrackingData) []error {
+	errs := make([]error, len(batch))
+	valid := make([]*models.TrackingData, 0, len(batch))
+	indexes := make([]int, 0, len(batch))
+
+	for i, data := range batch {
+		if err := s.prepare(data); err != nil {
+			errs[i] = err
+			continue
+		}
+		valid = append(valid, data)
+		indexes = append(indexes, i)
+	}
+
+	if len(valid) == 0 {
+		return errs
+	}
+
+	// パイプラインが有効な場合は1件ずつキューに積む
+	if s.pipeline != nil {
+		for k, data := range valid {
+			errs[indexes[k]] = s.pipeline.Enqueue(data)
+		}
+		return errs
+	}
+
+	// 複数行INSERTに対応したリポジトリではまとめて保存
+	if writer, ok := s.repo.(ingestion.BatchWriter); ok {
+		if err := writer.SaveBatch(ctx, valid); err != nil {
+			for _, i := range indexes {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+
+	for k, data := range valid {
+		errs[indexes[k]] = s.repo.Create(ctx, data)
+	}
+	return errs
+}
+
+// prepare はトラッキングデータを検証し、保存に必要な値を補完します
+func (s *TrackingService) prepare(data *models.TrackingData) error {
 	// バリデーション
 	if err := s.validator.Validate(data); err != nil {
-		return err
+		return models.NewValidationError(err)
 	}
 
 	// カスタムパラメータのバリデーション
 	if err := s.validator.ValidateCustomParams(data.CustomParams); err != nil {
-		return err
+		return models.NewValidationError(err)
 	}
 
 	// IDの生成
@@ -69,6 +201,24 @@ func (s *TrackingService) ProcessTrackingData(ctx context.Context, data *models.
 		data.Timestamp = time.Now()
 	}
 
+	// ユーザーエージェントの解析（クライアントから送られた値は使わない）
+	data.BotScore, data.BotReason = 0, ""
+	data.ParseUserAgent(s.uaParser)
+
+	// ボットの判定（匿名化の前のIPアドレスで判定し、bot_policy が drop の場合は保存しない）
+	if err := s.detectBot(data); err != nil {
+		return err
+	}
+
+	// ページURLの正規化とグループ化（アプリケーションの url_rules、未設定の場合はデフォルトのルール）
+	data.NormalizeURL()
+
+	// 流入元のホスト・チャネル・キャンペーンの判定（クライアントから送られた値は使わない）
+	data.ClassifyReferrer(s.referrers)
+
+	// 位置情報の判定（匿名化の前のIPアドレスで判定し、粗い位置情報だけを保存する）
+	s.resolveLocation(data)
+
 	// IPアドレスの匿名化
 	if data.IPAddress != "" {
 		data.IPAddress = iputil.AnonymizeIP(data.IPAddress)
@@ -82,8 +232,65 @@ func (s *TrackingService) ProcessTrackingData(ctx context.Context, data *models.
 	// 作成時刻の設定
 	data.CreatedAt = time.Now()
 
-	// リポジトリに保存
-	return s.repo.Create(ctx, data)
+	return nil
+}
+
+// detectBot はボットのスコアと理由を設定し、ボットと判定したイベントはデバイスタイプを bot にします
+// bot_policy が keep の場合は判定せず、drop の場合はボットと判定したイベントに ErrTrackingBotDropped を返します
+func (s *TrackingService) detectBot(data *models.TrackingData) error {
+	if s.bots == nil || data.BotPolicy == models.BotPolicyKeep {
+		return nil
+	}
+
+	result := s.bots.Detect(botdetect.Event{
+		AppID:     data.AppID,
+		UserAgent: data.UserAgent,
+		IPAddress: data.IPAddress,
+		Webdriver: data.HasWebdriverHint(),
+	})
+	data.BotScore = result.Score
+	data.BotReason = result.Reason()
+	if !result.IsBot() {
+		return nil
+	}
+
+	data.DeviceType = uaparser.DeviceTypeBot
+	if data.BotPolicy == models.BotPolicyDrop {
+		return models.ErrTrackingBotDropped
+	}
+	return nil
+}
+
+// resolveLocation はIPアドレスから国・地域・都市・ASNを設定します
+// クライアントから送られた値は使いません
+func (s *TrackingService) resolveLocation(data *models.TrackingData) {
+	data.Country, data.Region, data.City, data.ASN = "", "", "", 0
+	if s.geo == nil || data.IPAddress == "" {
+		return
+	}
+	if location, ok := s.geo.Lookup(data.IPAddress); ok {
+		data.Country = location.Country
+		data.Region = location.Region
+		data.City = location.City
+		data.ASN = location.ASN
+	}
+}
+
+// IngestionStats はインジェストパイプラインの状態を返します
+// パイプラインが無効な場合は false を返します
+func (s *TrackingService) IngestionStats() (ingestion.Stats, bool) {
+	if s.pipeline == nil {
+		return ingestion.Stats{}, false
+	}
+	return s.pipeline.Stats(), true
+}
+
+// Shutdown はインジェストパイプラインに残っているデータを書き込んで停止します
+func (s *TrackingService) Shutdown(ctx context.Context) error {
+	if s.pipeline == nil {
+		return nil
+	}
+	return s.pipeline.Stop(ctx)
 }
 
 // GetByID はIDでトラッキングデータを取得します
@@ -111,54 +318,269 @@ func (s *TrackingService) Delete(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
 }
 
+// 統計の上位件数の既定値と上限
+const (
+	DefaultStatisticsLimit = 10
+	MaxStatisticsLimit     = 100
+)
+
+// StatisticsQuery は統計取得の条件を表します
+type StatisticsQuery struct {
+	AppID     string
+	StartDate time.Time
+	EndDate   time.Time
+	GroupBy   string // "day", "hour", "page", "referrer", "browser", "os", "device", "country", "channel", "campaign", "page_group"（空の場合はグループ化しない）
+	Limit     int    // 上位ページ・リファラーと、日・時以外の単位のグループの件数
+}
+
 // GetStatistics はトラッキング統計を取得します
-func (s *TrackingService) GetStatistics(ctx context.Context, appID string, startDate, endDate time.Time) (*TrackingStatistics, error) {
+func (s *TrackingService) GetStatistics(ctx context.Context, query StatisticsQuery) (*TrackingStatistics, error) {
 	// 日付範囲の検証
-	if startDate.After(endDate) {
+	if query.StartDate.After(query.EndDate) {
 		return nil, models.ErrStatisticsInvalidPeriod
 	}
 
+	// グループ化単位の検証
+	if query.GroupBy != "" && !models.IsValidStatsGroupBy(query.GroupBy) {
+		return nil, models.ErrStatisticsInvalidGroupBy
+	}
+
+	// 件数の正規化
+	if query.Limit <= 0 {
+		query.Limit = DefaultStatisticsLimit
+	}
+	if query.Limit > MaxStatisticsLimit {
+		query.Limit = MaxStatisticsLimit
+	}
+
 	// 統計データの取得
 	stats := &TrackingStatistics{
-		AppID:     appID,
-		StartDate: startDate,
-		EndDate:   endDate,
+		AppID:     query.AppID,
+		StartDate: query.StartDate,
+		EndDate:   query.EndDate,
+		GroupBy:   query.GroupBy,
 		Metrics:   make(map[string]interface{}),
 	}
 
+	// 集計済みの範囲はロールアップから読み込む（日・時単位のグループ化ではグループと同じ粒度だけを使う）
+	if plan, ok := s.planRollups(ctx, query.StartDate, query.EndDate.Add(time.Nanosecond), seriesRollupGranularity(query.GroupBy)); ok {
+		return s.getStatisticsFromRollups(ctx, query, stats, plan)
+	}
+
 	// 基本的な統計情報を計算
 	if err := s.calculateBasicStatistics(ctx, stats); err != nil {
 		return nil, err
 	}
 
+	// 上位ページ・リファラーを計算
+	if err := s.calculateTopStatistics(ctx, stats, query.Limit); err != nil {
+		return nil, err
+	}
+
+	// グループ化した統計を計算
+	if query.GroupBy != "" {
+		limit := query.Limit
+		if query.GroupBy == models.StatsGroupByDay || query.GroupBy == models.StatsGroupByHour {
+			// 時系列は期間全体を返す
+			limit = 0
+		}
+		groups, err := s.repo.GetGroupedStats(ctx, query.AppID, query.GroupBy, query.StartDate, query.EndDate, limit)
+		if err != nil {
+			return nil, err
+		}
+		stats.Groups = groups
+	}
+
 	return stats, nil
 }
 
 // TrackingStatistics はトラッキング統計を表します
 type TrackingStatistics struct {
-	AppID     string                 `json:"app_id"`
-	StartDate time.Time              `json:"start_date"`
-	EndDate   time.Time              `json:"end_date"`
-	Metrics   map[string]interface{} `json:"metrics"`
+	AppID                  string                 `json:"app_id"`
+	StartDate              time.Time              `json:"start_date"`
+	EndDate                time.Time              `json:"end_date"`
+	TotalRequests          int64                  `json:"total_requests"`
+	UniqueSessions         int64                  `json:"unique_sessions"`
+	UniqueVisitors         int64                  `json:"unique_visitors"`
+	BotRequests            int64                  `json:"bot_requests"`
+	MobileRequests         int64                  `json:"mobile_requests"`
+	AverageSessionDuration float64                `json:"average_session_duration"`
+	TopPages               []*models.StatsGroup   `json:"top_pages"`
+	TopReferrers           []*models.StatsGroup   `json:"top_referrers"`
+	GroupBy                string                 `json:"group_by,omitempty"`
+	Groups                 []*models.StatsGroup   `json:"groups,omitempty"`
+	Approximate            bool                   `json:"approximate,omitempty"`
+	Metrics                map[string]interface{} `json:"metrics"`
 }
 
-// calculateBasicStatistics は基本的な統計情報を計算します
+// calculateBasicStatistics は期間内の基本的な統計情報を計算します
 func (s *TrackingService) calculateBasicStatistics(ctx context.Context, stats *TrackingStatistics) error {
-	// ここでは簡易的な実装
-	// 実際の実装では、データベースから集計クエリを実行
+	summary, err := s.repo.GetStatsByAppID(ctx, stats.AppID, stats.StartDate, stats.EndDate)
+	if err != nil {
+		return err
+	}
 
-	// 総トラッキング数
-	totalCount, err := s.repo.CountByAppID(ctx, stats.AppID)
+	averageSession, err := s.repo.GetAverageSessionDuration(ctx, stats.AppID, stats.StartDate, stats.EndDate)
 	if err != nil {
 		return err
 	}
 
-	stats.Metrics["total_tracking_count"] = totalCount
+	stats.TotalRequests = summary.TotalRequests
+	stats.UniqueSessions = summary.UniqueSessions
+	stats.UniqueVisitors = summary.UniqueIPs
+	stats.BotRequests = summary.BotRequests
+	stats.MobileRequests = summary.MobileRequests
+	stats.AverageSessionDuration = averageSession
+
+	stats.Metrics["total_tracking_count"] = summary.TotalRequests
 	stats.Metrics["period_days"] = int(stats.EndDate.Sub(stats.StartDate).Hours() / 24)
 
 	return nil
 }
 
+// calculateTopStatistics は上位のページとリファラーを計算します
+func (s *TrackingService) calculateTopStatistics(ctx context.Context, stats *TrackingStatistics, limit int) error {
+	topPages, err := s.repo.GetGroupedStats(ctx, stats.AppID, models.StatsGroupByPage, stats.StartDate, stats.EndDate, limit)
+	if err != nil {
+		return err
+	}
+
+	topReferrers, err := s.repo.GetGroupedStats(ctx, stats.AppID, models.StatsGroupByReferrer, stats.StartDate, stats.EndDate, limit)
+	if err != nil {
+		return err
+	}
+
+	stats.TopPages = topPages
+	stats.TopReferrers = topReferrers
+
+	return nil
+}
+
+// MaxTimeSeriesPoints は1回の時系列統計で返す最大バケット数です
+const MaxTimeSeriesPoints = 10000
+
+// TimeSeriesQuery は時系列統計の取得条件を表します
+type TimeSeriesQuery struct {
+	AppID     string
+	StartDate time.Time
+	EndDate   time.Time
+	Interval  string         // "minute", "hour", "day", "week", "month"
+	Location  *time.Location // バケットの区切りに使うタイムゾーン（nil の場合はUTC）
+}
+
+// TimeSeries は時系列統計を表します
+type TimeSeries struct {
+	AppID     string                    `json:"app_id"`
+	Interval  string                    `json:"interval"`
+	Timezone  string                    `json:"timezone"`
+	StartDate time.Time                 `json:"start_date"`
+	EndDate   time.Time                 `json:"end_date"`
+	Points    []*models.TimeSeriesPoint `json:"points"`
+}
+
+// GetTimeSeries は時系列統計を取得します
+// 期間内のすべてのバケットを返し、データのないバケットは0で埋めます
+func (s *TrackingService) GetTimeSeries(ctx context.Context, query TimeSeriesQuery) (*TimeSeries, error) {
+	// 日付範囲の検証
+	if query.StartDate.After(query.EndDate) {
+		return nil, models.ErrStatisticsInvalidPeriod
+	}
+
+	// 集計間隔の検証
+	if !models.IsValidTimeSeriesInterval(query.Interval) {
+		return nil, models.ErrStatisticsInvalidInterval
+	}
+
+	loc := query.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	// バケットを列挙
+	var points []*models.TimeSeriesPoint
+	index := make(map[int64]*models.TimeSeriesPoint)
+	end := query.EndDate.In(loc)
+	for bucket := truncateToInterval(query.StartDate.In(loc), query.Interval); !bucket.After(end); bucket = nextInterval(bucket, query.Interval) {
+		if len(points) >= MaxTimeSeriesPoints {
+			return nil, models.ErrStatisticsTooManyPoints
+		}
+		point := &models.TimeSeriesPoint{Bucket: bucket}
+		points = append(points, point)
+		index[bucket.Unix()] = point
+	}
+
+	// 集計結果をバケットに反映
+	rangeStart := points[0].Bucket
+	rangeEnd := nextInterval(points[len(points)-1].Bucket, query.Interval)
+	var err error
+	if plan, ok := s.timeSeriesRollupPlan(ctx, query.Interval, points, rangeEnd); ok {
+		err = s.fillTimeSeriesFromRollups(ctx, query, loc, plan, index, rangeEnd)
+	} else {
+		err = s.fillTimeSeries(ctx, query, loc, index, rangeStart, rangeEnd)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &TimeSeries{
+		AppID:     query.AppID,
+		Interval:  query.Interval,
+		Timezone:  loc.String(),
+		StartDate: rangeStart,
+		EndDate:   rangeEnd,
+		Points:    points,
+	}, nil
+}
+
+// fillTimeSeries は access_logs の [start, end) の集計結果を時系列のバケットに反映します
+func (s *TrackingService) fillTimeSeries(ctx context.Context, query TimeSeriesQuery, loc *time.Location, index map[int64]*models.TimeSeriesPoint, start, end time.Time) error {
+	results, err := s.repo.GetTimeSeries(ctx, query.AppID, query.Interval, loc, start, end)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if point, ok := index[result.Bucket.Unix()]; ok {
+			point.PageViews += result.PageViews
+			point.Sessions += result.Sessions
+			point.Visitors += result.Visitors
+		}
+	}
+	return nil
+}
+
+// truncateToInterval は時刻を集計間隔の開始時刻に切り捨てます
+func truncateToInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case models.TimeSeriesIntervalMinute:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	case models.TimeSeriesIntervalHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case models.TimeSeriesIntervalWeek:
+		return timeutil.GetStartOfWeek(t)
+	case models.TimeSeriesIntervalMonth:
+		return timeutil.GetStartOfMonth(t)
+	default:
+		return timeutil.GetStartOfDay(t)
+	}
+}
+
+// nextInterval は次のバケットの開始時刻を返します
+// 分・時は経過時間で、日・週・月は暦で進めるため夏時間の切り替えでも区切りがずれません
+func nextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case models.TimeSeriesIntervalMinute:
+		return t.Add(time.Minute)
+	case models.TimeSeriesIntervalHour:
+		return t.Add(time.Hour)
+	case models.TimeSeriesIntervalWeek:
+		return timeutil.GetStartOfWeek(t.AddDate(0, 0, 7))
+	case models.TimeSeriesIntervalMonth:
+		return timeutil.GetStartOfMonth(t.AddDate(0, 1, 0))
+	default:
+		return timeutil.GetStartOfDay(t.AddDate(0, 0, 1))
+	}
+}
+
 // generateSessionID はセッションIDを生成します
 func (s *TrackingService) generateSessionID(data *models.TrackingData) string {
 	// ユーザーエージェントとIPアドレスからハッシュを生成
@@ -213,19 +635,20 @@ type DailyStatistics struct {
 
 // calculateDailyStatistics は日別統計を計算します
 func (s *TrackingService) calculateDailyStatistics(ctx context.Context, stats *DailyStatistics, startDate, endDate time.Time) error {
-	// 実際の実装では、データベースから集計クエリを実行
-	// ここでは簡易的な実装
+	summary, err := s.repo.GetStatsByAppID(ctx, stats.AppID, startDate, endDate)
+	if err != nil {
+		return err
+	}
 
-	// 総トラッキング数
-	totalCount, err := s.repo.CountByAppID(ctx, stats.AppID)
+	averageSession, err := s.repo.GetAverageSessionDuration(ctx, stats.AppID, startDate, endDate)
 	if err != nil {
 		return err
 	}
 
-	stats.TotalPageViews = totalCount
-	stats.TotalSessions = totalCount / 10 // 簡易的な計算
-	stats.UniqueVisitors = totalCount / 5 // 簡易的な計算
-	stats.AverageSession = 300.0          // 5分（秒単位）
+	stats.TotalPageViews = summary.TotalRequests
+	stats.TotalSessions = summary.UniqueSessions
+	stats.UniqueVisitors = summary.UniqueIPs
+	stats.AverageSession = averageSession
 
 	return nil
 }
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+// UsageCounter は月間のイベント数のカウンター（Redis）のインターフェースです
+type UsageCounter interface {
+	// ReserveUsage は集計期間のイベント数の上限の範囲で events を確保し、確保後の使用量を返します
+	// quota が正で確保後に quota を超える場合は確保せず、現在の使用量と false を返します
+	ReserveUsage(ctx context.Context, appID string, period time.Time, events, quota int64) (int64, bool, error)
+	// CommitUsage は確保した reserved を解放し、受け付けた accepted をイベント数に加算します
+	CommitUsage(ctx context.Context, appID string, period time.Time, reserved, accepted int64) error
+	// GetUsage は集計期間の確定したイベント数を返します
+	GetUsage(ctx context.Context, appID string, period time.Time) (int64, error)
+}
+
+// UsageRepository は突き合わせ済みの月間のイベント数を保存するリポジトリのインターフェースです
+type UsageRepository interface {
+	GetUsage(ctx context.Context, appID string, period time.Time) (int64, error)
+}
+
+// WithUsage は月間のイベント数を数えるカウンターと、突き合わせ済みの値を保存するリポジトリを設定します
+// 設定しない場合は月間のイベント数の上限を適用しません
+func WithUsage(counter UsageCounter, repo UsageRepository) ApplicationServiceOption {
+	return func(s *ApplicationService) {
+		s.usageCounter = counter
+		s.usageRepo = repo
+	}
+}
+
+// ReserveQuota はアプリケーションの当月のイベント数の上限の範囲で events を確保します
+// 確保した分は CommitQuota で受け付けたイベント数を確定するまで使用量として保存されません
+// プランの月間の上限を超える場合は確保せずに models.ErrQuotaExceeded と現在の使用量を返します
+// カウンターが設定されていない場合は何もせずに nil を返します
+func (s *ApplicationService) ReserveQuota(ctx context.Context, app *models.Application, events int) (*models.Usage, error) {
+	if s.usageCounter == nil || app == nil || events <= 0 {
+		return nil, nil
+	}
+
+	plan := app.PlanLimits()
+	period := models.UsagePeriod(time.Now())
+	used, ok, err := s.usageCounter.ReserveUsage(ctx, app.AppID, period, int64(events), plan.MonthlyEventQuota)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := models.NewUsage(app.AppID, plan, period, used)
+	if !ok {
+		return usage, models.ErrQuotaExceeded
+	}
+	return usage, nil
+}
+
+// CommitQuota は ReserveQuota で確保した reserved を解放し、受け付けた accepted を使用量に加算します
+// usage は ReserveQuota が返した使用量で、確保した集計期間のカウンターに加算します
+func (s *ApplicationService) CommitQuota(ctx context.Context, usage *models.Usage, reserved, accepted int) error {
+	if s.usageCounter == nil || usage == nil || reserved <= 0 {
+		return nil
+	}
+	if accepted > reserved {
+		accepted = reserved
+	}
+	if accepted < 0 {
+		accepted = 0
+	}
+	return s.usageCounter.CommitUsage(ctx, usage.AppID, usage.PeriodStart, int64(reserved), int64(accepted))
+}
+
+// GetUsage はアプリケーションの当月の使用量を取得します
+// カウンターと保存済みの値の大きい方を使用し、カウンターを参照できない場合は保存済みの値を返します
+func (s *ApplicationService) GetUsage(ctx context.Context, appID string) (*models.Usage, error) {
+	app, err := s.GetByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	period := models.UsagePeriod(time.Now())
+	var events int64
+	if s.usageRepo != nil {
+		if events, err = s.usageRepo.GetUsage(ctx, appID, period); err != nil {
+			return nil, err
+		}
+	}
+	if s.usageCounter != nil {
+		if counted, err := s.usageCounter.GetUsage(ctx, appID, period); err == nil && counted > events {
+			events = counted
+		}
+	}
+
+	return models.NewUsage(appID, app.PlanLimits(), period, events), nil
+}
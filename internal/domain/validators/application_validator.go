@@ -125,6 +125,10 @@ func (v *ApplicationValidator) ValidateCreate(app *models.Application) error {
 		return err
 	}
 
+	if !models.IsValidTimezone(app.Timezone) {
+		return models.ErrApplicationInvalidTimezone
+	}
+
 	// 作成時はAppIDとAPIキーのバリデーションをスキップ（後で生成されるため）
 	return nil
 }
@@ -152,6 +156,10 @@ func (v *ApplicationValidator) ValidateUpdate(app *models.Application) error {
 		return err
 	}
 
+	if !models.IsValidTimezone(app.Timezone) {
+		return models.ErrApplicationInvalidTimezone
+	}
+
 	// 更新時はAPIキーのバリデーションをスキップ（既存のキーを保持するため）
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"accesslog-tracker/internal/botdetect"
 	"accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/utils/iputil"
 )
@@ -251,20 +252,9 @@ func (v *TrackingValidator) validateCustomParamValue(value interface{}) error {
 	return nil
 }
 
-// IsCrawler はユーザーエージェントがクローラーかどうかを判定します
+// IsCrawler はユーザーエージェントがクローラーかどうかを組み込みのボットのパターンで判定します
 func (v *TrackingValidator) IsCrawler(userAgent string) bool {
-	userAgentLower := strings.ToLower(userAgent)
-	crawlerKeywords := []string{
-		"bot", "crawler", "spider", "scraper", "googlebot", "bingbot", "yandexbot",
-		"baiduspider", "duckduckbot", "facebookexternalhit", "twitterbot",
-	}
-	
-	for _, keyword := range crawlerKeywords {
-		if strings.Contains(userAgentLower, keyword) {
-			return true
-		}
-	}
-	return false
+	return botdetect.MatchUserAgent(userAgent)
 }
 
 // ValidateAppID はアプリケーションIDを検証します
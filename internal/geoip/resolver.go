@@ -0,0 +1,200 @@
+// Package geoip はローカルのMaxMind形式（GeoLite2互換）のデータベースでIPアドレスの位置情報を判定します
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"accesslog-tracker/internal/utils/logger"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DefaultReloadInterval はデータベースファイルの変更を確認する間隔のデフォルト値です
+const DefaultReloadInterval = time.Minute
+
+// Config は位置情報の判定の設定です
+type Config struct {
+	CityPath       string        // GeoLite2-City 互換のデータベース（空の場合は国・地域・都市を判定しない）
+	ASNPath        string        // GeoLite2-ASN 互換のデータベース（空の場合はASNを判定しない）
+	ReloadInterval time.Duration // ファイルの変更を確認する間隔（0 以下の場合は DefaultReloadInterval）
+}
+
+// Location はIPアドレスから判定した粗い位置情報です
+// 個人を特定しないよう、国・地域・都市・ASNだけを扱います
+type Location struct {
+	Country string // ISO 3166-1 alpha-2（例: JP）
+	Region  string // ISO 3166-2（例: JP-13）
+	City    string // 都市名（英語）
+	ASN     int64  // 自律システム番号
+}
+
+// cityRecord は City データベースのレコードのうち使用する項目です
+type cityRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// asnRecord は ASN データベースのレコードのうち使用する項目です
+type asnRecord struct {
+	AutonomousSystemNumber uint `maxminddb:"autonomous_system_number"`
+}
+
+// database は読み込んだデータベースファイルです
+type database struct {
+	reader  *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+// source は1つのデータベースファイルと、現在読み込んでいる内容です
+type source struct {
+	path    string
+	current atomic.Pointer[database]
+}
+
+// Resolver はIPアドレスの位置情報を判定します
+// データベースはメモリに読み込み、ファイルが変更されると読み込み直します（判定中のリクエストには影響しない）
+type Resolver struct {
+	config Config
+	logger logger.Logger
+	city   *source
+	asn    *source
+	mu     sync.Mutex
+}
+
+// NewResolver は新しい位置情報の判定を作成します
+// データベースは Reload または Run で読み込みます
+func NewResolver(config Config, logger logger.Logger) *Resolver {
+	if config.ReloadInterval <= 0 {
+		config.ReloadInterval = DefaultReloadInterval
+	}
+	r := &Resolver{config: config, logger: logger}
+	if config.CityPath != "" {
+		r.city = &source{path: config.CityPath}
+	}
+	if config.ASNPath != "" {
+		r.asn = &source{path: config.ASNPath}
+	}
+	return r
+}
+
+// Reload は変更されたデータベースファイルを読み込みます
+// 読み込みに失敗した場合は、それまでの内容で判定を続けます
+func (r *Resolver) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []string
+	for _, src := range []*source{r.city, r.asn} {
+		if src == nil {
+			continue
+		}
+		reloaded, err := src.reload()
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if reloaded {
+			db := src.current.Load()
+			r.logger.Info("Loaded GeoIP database", "path", src.path, "type", db.reader.Metadata.DatabaseType)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to load GeoIP database: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Run はコンテキストがキャンセルされるまで、定期的にデータベースファイルの変更を確認して読み込み直します
+func (r *Resolver) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				r.logger.Warn("Failed to reload GeoIP database", "error", err.Error())
+			}
+		}
+	}
+}
+
+// Lookup はIPアドレスの位置情報を判定します
+// データベースが読み込まれていない場合や、該当するレコードがない場合は false を返します
+func (r *Resolver) Lookup(ip string) (Location, bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}, false
+	}
+
+	var location Location
+	found := false
+	if db := r.loaded(r.city); db != nil {
+		var record cityRecord
+		if err := db.reader.Lookup(parsed, &record); err == nil && record.Country.ISOCode != "" {
+			location.Country = record.Country.ISOCode
+			if len(record.Subdivisions) > 0 && record.Subdivisions[0].ISOCode != "" {
+				location.Region = record.Country.ISOCode + "-" + record.Subdivisions[0].ISOCode
+			}
+			location.City = record.City.Names["en"]
+			found = true
+		}
+	}
+	if db := r.loaded(r.asn); db != nil {
+		var record asnRecord
+		if err := db.reader.Lookup(parsed, &record); err == nil && record.AutonomousSystemNumber != 0 {
+			location.ASN = int64(record.AutonomousSystemNumber)
+			found = true
+		}
+	}
+	return location, found
+}
+
+// loaded はデータベースファイルの現在の内容を返します（未設定・未読み込みの場合は nil）
+func (r *Resolver) loaded(src *source) *database {
+	if src == nil {
+		return nil
+	}
+	return src.current.Load()
+}
+
+// reload はファイルの更新時刻・サイズが変わっている場合に読み込み直します
+// mmap ではなくメモリに読み込むため、置き換えた後も以前の内容で判定中のリクエストは安全に完了します
+func (s *source) reload() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+	if current := s.current.Load(); current != nil && current.modTime.Equal(info.ModTime()) && current.size == info.Size() {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	s.current.Store(&database{reader: reader, modTime: info.ModTime(), size: info.Size()})
+	return true, nil
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound キャッシュにキーがない（期限切れを含む）
+var ErrKeyNotFound = errors.New("key not found")
+
+// Config プロセス内キャッシュの設定
+type Config struct {
+	MaxEntries int              // 保持するエントリ数の上限
+	MaxTTL     time.Duration    // エントリの有効期間の上限（他のインスタンスでの更新を反映できないため短く保つ）
+	Clock      func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// DefaultConfig デフォルトのプロセス内キャッシュの設定を取得
+func DefaultConfig() Config {
+	return Config{
+		MaxEntries: 10000,
+		MaxTTL:     time.Minute,
+	}
+}
+
+// entry キャッシュのエントリ
+type entry struct {
+	value   string
+	expires time.Time
+}
+
+// Cache プロセス内のTTL付きキャッシュ
+type Cache struct {
+	config  Config
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// NewCache 新しいプロセス内キャッシュを作成
+func NewCache(config Config) *Cache {
+	defaults := DefaultConfig()
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaults.MaxEntries
+	}
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = defaults.MaxTTL
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	return &Cache{
+		config:  config,
+		entries: make(map[string]entry),
+	}
+}
+
+// Get キーの値を取得
+func (c *Cache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	if !c.config.Clock().Before(e.expires) {
+		delete(c.entries, key)
+		return "", ErrKeyNotFound
+	}
+	return e.value, nil
+}
+
+// Set キーと値を設定（有効期間は MaxTTL までに短縮、0 以下の場合は MaxTTL）
+func (c *Cache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if ttl <= 0 || ttl > c.config.MaxTTL {
+		ttl = c.config.MaxTTL
+	}
+	now := c.config.Clock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.config.MaxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = entry{value: value, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete キーを削除
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+// Len 保持しているエントリ数を取得（期限切れで未削除のエントリを含む）
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// evict 期限切れのエントリを削除し、空きがなければ最も早く期限切れになるエントリを削除
+func (c *Cache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || e.expires.Before(oldest) {
+			oldestKey, oldest = key, e.expires
+		}
+	}
+	if len(c.entries) >= c.config.MaxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
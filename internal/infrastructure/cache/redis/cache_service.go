@@ -3,15 +3,34 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
+
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrKeyNotFound キーが存在しない
+var ErrKeyNotFound = errors.New("key not found")
+
+// ConnectionStatus Redis接続の状態
+type ConnectionStatus struct {
+	Available bool      `json:"available"`
+	Since     time.Time `json:"since"`                // 現在の状態になった時刻
+	LastError string    `json:"last_error,omitempty"` // 利用できない場合の直近のエラー
+}
+
 // CacheService Redisキャッシュサービス
 type CacheService struct {
 	client *redis.Client
 	addr   string
+
+	// 接続の状態（Connect・Ping の結果で更新）
+	available atomic.Bool
+	statusMu  sync.RWMutex
+	status    ConnectionStatus
 }
 
 // NewCacheService 新しいRedisキャッシュサービスを作成
@@ -29,10 +48,9 @@ func (c *CacheService) Connect() error {
 		DB:       0,  // デフォルトDB
 	})
 
-	// 接続テスト
+	// 接続テスト（失敗してもクライアントは保持し、以降のコマンドで再接続する）
 	ctx := context.Background()
-	_, err := c.client.Ping(ctx).Result()
-	if err != nil {
+	if err := c.Ping(ctx); err != nil {
 		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
@@ -52,18 +70,50 @@ func (c *CacheService) GetClient() *redis.Client {
 	return c.client
 }
 
-// Ping Redis接続をテスト
+// Ping Redis接続をテストし、結果で接続の状態を更新
 func (c *CacheService) Ping(ctx context.Context) error {
 	if c.client == nil {
-		return fmt.Errorf("Redis client not connected")
+		err := fmt.Errorf("Redis client not connected")
+		c.setStatus(err)
+		return err
 	}
 
 	_, err := c.client.Ping(ctx).Result()
 	if err != nil {
-		return fmt.Errorf("failed to ping Redis: %w", err)
+		err = fmt.Errorf("failed to ping Redis: %w", err)
 	}
+	c.setStatus(err)
 
-	return nil
+	return err
+}
+
+// Available 直近の疎通確認でRedisを利用できたかを取得
+func (c *CacheService) Available() bool {
+	return c.available.Load()
+}
+
+// Status 接続の状態を取得
+func (c *CacheService) Status() ConnectionStatus {
+	c.statusMu.RLock()
+	defer c.statusMu.RUnlock()
+	return c.status
+}
+
+// setStatus 疎通確認の結果で接続の状態を更新
+func (c *CacheService) setStatus(err error) {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+
+	available := err == nil
+	if available != c.status.Available || c.status.Since.IsZero() {
+		c.status.Since = time.Now()
+	}
+	c.status.Available = available
+	c.status.LastError = ""
+	if err != nil {
+		c.status.LastError = err.Error()
+	}
+	c.available.Store(available)
 }
 
 // Set キーと値を設定
@@ -89,7 +139,7 @@ func (c *CacheService) Get(ctx context.Context, key string) (string, error) {
 	value, err := c.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return "", fmt.Errorf("key not found: %s", key)
+			return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
 		}
 		return "", fmt.Errorf("failed to get key %s: %w", key, err)
 	}
@@ -125,7 +175,7 @@ func (c *CacheService) GetJSON(ctx context.Context, key string, dest interface{}
 	jsonData, err := c.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return fmt.Errorf("key not found: %s", key)
+			return fmt.Errorf("%w: %s", ErrKeyNotFound, key)
 		}
 		return fmt.Errorf("failed to get key %s: %w", key, err)
 	}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"accesslog-tracker/internal/infrastructure/cache/memory"
+)
+
+// FallbackCache Redisを利用できない間はプロセス内のキャッシュで読み書きするキャッシュ
+// 書き込みは常にプロセス内のキャッシュにも反映し、Redisが停止した直後から使えるようにする
+// プロセス内のキャッシュには他のインスタンスでの削除が反映されないため、有効期間の短いキャッシュを渡す
+type FallbackCache struct {
+	redis *CacheService
+	local *memory.Cache
+}
+
+// NewFallbackCache 新しいフォールバック付きのキャッシュを作成
+func NewFallbackCache(redis *CacheService, local *memory.Cache) *FallbackCache {
+	return &FallbackCache{
+		redis: redis,
+		local: local,
+	}
+}
+
+// Get キーの値を取得（Redisを利用できない場合はプロセス内のキャッシュから取得）
+func (c *FallbackCache) Get(ctx context.Context, key string) (string, error) {
+	if c.redis.Available() {
+		value, err := c.redis.Get(ctx, key)
+		if err == nil || errors.Is(err, ErrKeyNotFound) {
+			return value, err
+		}
+	}
+	return c.local.Get(ctx, key)
+}
+
+// Set キーと値を設定（Redisを利用できない場合はプロセス内のキャッシュのみに設定）
+func (c *FallbackCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.local.Set(ctx, key, value, ttl)
+
+	if c.redis.Available() {
+		// Redisへの書き込みに失敗してもプロセス内のキャッシュには反映済み
+		c.redis.Set(ctx, key, value, ttl)
+	}
+	return nil
+}
+
+// GetJSON JSON値を取得（Redisを利用できない場合はプロセス内のキャッシュから取得）
+func (c *FallbackCache) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(value), dest); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return nil
+}
+
+// SetJSON JSON値を設定（Redisを利用できない場合はプロセス内のキャッシュのみに設定）
+func (c *FallbackCache) SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return c.Set(ctx, key, string(jsonData), ttl)
+}
+
+// Delete キーを削除（Redisを利用できない場合はプロセス内のキャッシュのみから削除）
+func (c *FallbackCache) Delete(ctx context.Context, key string) error {
+	c.local.Delete(ctx, key)
+
+	if c.redis.Available() {
+		return c.redis.Delete(ctx, key)
+	}
+	return nil
+}
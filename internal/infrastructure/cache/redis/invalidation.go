@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"accesslog-tracker/internal/utils/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ApplicationInvalidationChannel アプリケーションのキャッシュの削除を通知するチャンネル
+const ApplicationInvalidationChannel = "app:invalidate"
+
+// invalidationRetryInterval 購読に失敗した場合に再試行するまでの間隔
+const invalidationRetryInterval = time.Second
+
+// LocalCache 削除の通知を反映するプロセス内のキャッシュ
+type LocalCache interface {
+	// EvictLocal キャッシュキーのエントリを削除
+	EvictLocal(keys ...string)
+	// PurgeLocal すべてのエントリを削除
+	PurgeLocal()
+}
+
+// InvalidationBus Redisのpub/subでプロセス内のキャッシュの削除をインスタンス間に通知
+type InvalidationBus struct {
+	client  *redis.Client
+	channel string
+	logger  logger.Logger
+}
+
+// NewInvalidationBus 新しいキャッシュの削除の通知を作成
+func NewInvalidationBus(client *redis.Client, channel string, logger logger.Logger) *InvalidationBus {
+	return &InvalidationBus{
+		client:  client,
+		channel: channel,
+		logger:  logger,
+	}
+}
+
+// Publish キャッシュキーの削除を通知
+func (b *InvalidationBus) Publish(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invalidation: %w", err)
+	}
+	if err := b.client.Publish(ctx, b.channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish invalidation: %w", err)
+	}
+	return nil
+}
+
+// Subscribe コンテキストがキャンセルされるまで削除の通知を購読し、cache に反映
+// 購読を開始・再開するたびにすべてのエントリを削除し、購読していない間の通知の取りこぼしを補う
+func (b *InvalidationBus) Subscribe(ctx context.Context, cache LocalCache) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			b.logger.Warn("Failed to receive cache invalidation", "error", err.Error())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(invalidationRetryInterval):
+			}
+			continue
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			if msg.Kind == "subscribe" {
+				cache.PurgeLocal()
+			}
+		case *redis.Message:
+			var keys []string
+			if err := json.Unmarshal([]byte(msg.Payload), &keys); err != nil {
+				b.logger.Warn("Invalid cache invalidation", "error", err.Error())
+				continue
+			}
+			cache.EvictLocal(keys...)
+		}
+	}
+}
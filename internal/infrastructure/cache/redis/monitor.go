@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// defaultMonitorInterval 疎通確認のデフォルトの間隔
+const defaultMonitorInterval = 5 * time.Second
+
+// Monitor Redisへの疎通をバックグラウンドで確認し、接続の状態を更新
+// 利用できない間、レート制限とキャッシュはプロセス内の実装で処理を続ける
+type Monitor struct {
+	cache    *CacheService
+	interval time.Duration
+	logger   logger.Logger
+}
+
+// NewMonitor 新しいRedisの疎通監視を作成（interval が0以下の場合は5秒）
+func NewMonitor(cache *CacheService, interval time.Duration, logger logger.Logger) *Monitor {
+	if interval <= 0 {
+		interval = defaultMonitorInterval
+	}
+
+	return &Monitor{
+		cache:    cache,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run コンテキストがキャンセルされるまで定期的に疎通を確認
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// Check 疎通を確認し、利用可否が変わった場合はログに出力
+func (m *Monitor) Check(ctx context.Context) bool {
+	before := m.cache.Status()
+
+	pingCtx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+	err := m.cache.Ping(pingCtx)
+
+	switch {
+	case err != nil && (before.Available || before.Since.IsZero()):
+		m.logger.Warn("Redis is unavailable, falling back to local rate limiting and cache", "error", err.Error())
+	case err == nil && !before.Available && !before.Since.IsZero():
+		m.logger.Info("Redis connection restored", "unavailable_for", time.Since(before.Since).String())
+	}
+	return err == nil
+}
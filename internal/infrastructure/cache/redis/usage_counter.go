@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// usageCounterTTL 月間イベント数のカウンターの有効期間（前月分を突き合わせられるよう2か月強保持）
+const usageCounterTTL = 62 * 24 * time.Hour
+
+// UsageCounter Redisの月間イベント数カウンター
+type UsageCounter struct {
+	client redis.Cmdable
+}
+
+// NewUsageCounter 新しい月間イベント数カウンターを作成
+func NewUsageCounter(client redis.Cmdable) *UsageCounter {
+	return &UsageCounter{
+		client: client,
+	}
+}
+
+// usageReservationTTL 確保したイベント数の有効期間（確定されずに残った確保分はこの期間で消える）
+const usageReservationTTL = time.Minute
+
+// reserveUsageScript 上限を超えない場合のみ確保中のイベント数を加算するLuaスクリプト
+// KEYS: カウンター、確保中のイベント数
+// ARGV: 確保するイベント数、上限（0の場合は上限なし）、確保の有効期間（秒）
+// 戻り値: {確保したか（1/0）, 確保後（確保しなかった場合は現在）のカウンターと確保中の合計}
+var reserveUsageScript = redis.NewScript(`
+local events = tonumber(ARGV[1])
+local quota = tonumber(ARGV[2])
+local used = tonumber(redis.call('GET', KEYS[1]) or '0') + math.max(tonumber(redis.call('GET', KEYS[2]) or '0'), 0)
+if quota > 0 and used + events > quota then
+  return {0, used}
+end
+redis.call('INCRBY', KEYS[2], events)
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+return {1, used + events}
+`)
+
+// commitUsageScript 確保したイベント数を解放し、受け付けたイベント数をカウンターに加算するLuaスクリプト
+// KEYS: カウンター、確保中のイベント数
+// ARGV: 確保したイベント数、受け付けたイベント数、カウンターの有効期間（秒）
+var commitUsageScript = redis.NewScript(`
+if redis.call('DECRBY', KEYS[2], ARGV[1]) <= 0 then
+  redis.call('DEL', KEYS[2])
+end
+if tonumber(ARGV[2]) > 0 then
+  redis.call('INCRBY', KEYS[1], ARGV[2])
+  redis.call('EXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// ReserveUsage 集計期間のイベント数の上限の範囲で events を確保し、確保後の使用量（確保中を含む）を返す
+// quota が正で確保後に quota を超える場合は確保せず、現在の使用量と false を返す
+// 確保した分は CommitUsage で確定するまでカウンターに加算しない（突き合わせで保存されない）
+func (c *UsageCounter) ReserveUsage(ctx context.Context, appID string, period time.Time, events, quota int64) (int64, bool, error) {
+	values, err := reserveUsageScript.Run(ctx, c.client, usageCounterKeys(appID, period),
+		events, quota, int64(usageReservationTTL.Seconds()),
+	).Int64Slice()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to reserve usage: %w", err)
+	}
+	if len(values) != 2 {
+		return 0, false, fmt.Errorf("unexpected usage script result: %v", values)
+	}
+	return values[1], values[0] == 1, nil
+}
+
+// CommitUsage ReserveUsage で確保した reserved を解放し、受け付けた accepted をカウンターに加算
+func (c *UsageCounter) CommitUsage(ctx context.Context, appID string, period time.Time, reserved, accepted int64) error {
+	err := commitUsageScript.Run(ctx, c.client, usageCounterKeys(appID, period),
+		reserved, accepted, int64(usageCounterTTL.Seconds()),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to commit usage: %w", err)
+	}
+	return nil
+}
+
+// GetUsage 集計期間のイベント数を取得（カウンターがない場合は0）
+func (c *UsageCounter) GetUsage(ctx context.Context, appID string, period time.Time) (int64, error) {
+	events, err := c.client.Get(ctx, usageCounterKey(appID, period)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get usage: %w", err)
+	}
+	return events, nil
+}
+
+// ListUsage 集計期間のアプリケーションごとのイベント数を取得
+func (c *UsageCounter) ListUsage(ctx context.Context, period time.Time) (map[string]int64, error) {
+	prefix := usageCounterKey("", period)
+	usage := make(map[string]int64)
+
+	iter := c.client.Scan(ctx, 0, prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		value, err := c.client.Get(ctx, key).Result()
+		if err == redis.Nil {
+			// 走査中に期限切れになったカウンター
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get usage %s: %w", key, err)
+		}
+		events, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid usage counter %s: %w", key, err)
+		}
+		usage[strings.TrimPrefix(key, prefix)] = events
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// AddUsage 集計期間のイベント数に delta を加算（上限は確認しない）
+func (c *UsageCounter) AddUsage(ctx context.Context, appID string, period time.Time, delta int64) error {
+	key := usageCounterKey(appID, period)
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.IncrBy(ctx, key, delta)
+		pipe.Expire(ctx, key, usageCounterTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add usage: %w", err)
+	}
+	return nil
+}
+
+// usageCounterKey 月間イベント数のカウンターのキーを返す
+func usageCounterKey(appID string, period time.Time) string {
+	return "usage:" + models.UsagePeriod(period).Format("2006-01") + ":" + appID
+}
+
+// usageCounterKeys カウンターと確保中のイベント数のキーを返す
+// 確保中のキーはカウンターのキー全体をハッシュタグにし、Redis Cluster の同じスロットに置く
+func usageCounterKeys(appID string, period time.Time) []string {
+	key := usageCounterKey(appID, period)
+	return []string{key, "usage_reserved:{" + key + "}"}
+}
@@ -2,12 +2,16 @@ package repositories
 
 import (
 	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/crypto"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // ApplicationRepository PostgreSQL用のアプリケーションリポジトリ実装
@@ -28,7 +32,7 @@ func (r *ApplicationRepository) Create(ctx context.Context, app *models.Applicat
 		app.AppID = uuid.New().String()
 	}
 	if app.APIKey == "" {
-		app.APIKey = uuid.New().String()
+		app.APIKey = crypto.GenerateAPIKey()
 	}
 	if app.CreatedAt.IsZero() {
 		app.CreatedAt = time.Now()
@@ -36,36 +40,65 @@ func (r *ApplicationRepository) Create(ctx context.Context, app *models.Applicat
 	if app.UpdatedAt.IsZero() {
 		app.UpdatedAt = time.Now()
 	}
+	if app.Timezone == "" {
+		app.Timezone = models.DefaultApplicationTimezone
+	}
+	if app.Plan == "" {
+		app.Plan = models.DefaultPlan
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	query := `
 		INSERT INTO applications (
-			app_id, name, domain, api_key, is_active, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			app_id, name, domain, is_active, timezone, plan, organization_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
-		app.AppID, app.Name, app.Domain, app.APIKey, app.Active, app.CreatedAt, app.UpdatedAt,
+	_, err = tx.ExecContext(ctx, query,
+		app.AppID, app.Name, app.Domain, app.Active, app.Timezone, app.Plan, nullString(app.OrganizationID), app.CreatedAt, app.UpdatedAt,
 	)
 
 	if err != nil {
+		// 存在しない組織を指定した場合
+		if isForeignKeyViolation(err) {
+			return models.ErrOrganizationNotFound
+		}
 		return fmt.Errorf("failed to save application: %w", err)
 	}
 
+	// 作成時のAPIキーを発行
+	key := &models.APIKey{AppID: app.AppID, Name: models.DefaultAPIKeyName, CreatedAt: app.CreatedAt}
+	if err := insertAPIKey(ctx, tx, key, app.APIKey); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save application: %w", err)
+	}
+	app.AuthenticatedKey = key
+
 	return nil
 }
 
 // GetByID アプリケーションIDでアプリケーションを検索
 func (r *ApplicationRepository) GetByID(ctx context.Context, appID string) (*models.Application, error) {
 	query := `
-		SELECT app_id, name, description, domain, api_key, is_active, created_at, updated_at
+		SELECT app_id, name, description, domain, is_active, timezone, settings, plan, organization_id, created_at, updated_at
 		FROM applications 
 		WHERE app_id = $1
 	`
 
 	var app models.Application
 	var description sql.NullString
+	var settings []byte
+	var organizationID sql.NullString
 	err := r.db.QueryRowContext(ctx, query, appID).Scan(
-		&app.AppID, &app.Name, &description, &app.Domain, &app.APIKey, &app.Active, &app.CreatedAt, &app.UpdatedAt,
+		&app.AppID, &app.Name, &description, &app.Domain, &app.Active, &app.Timezone, &settings, &app.Plan, &organizationID, &app.CreatedAt, &app.UpdatedAt,
 	)
 
 	if err != nil {
@@ -82,44 +115,92 @@ func (r *ApplicationRepository) GetByID(ctx context.Context, appID string) (*mod
 		app.Description = ""
 	}
 
+	if app.Settings, err = decodeSettings(settings); err != nil {
+		return nil, err
+	}
+	app.OrganizationID = organizationID.String
+
 	return &app, nil
 }
 
 // GetByAPIKey APIキーでアプリケーションを検索
+// プレフィックスで候補のキーを絞り込み、ソルト付きハッシュで照合する（有効期限切れのキーは除外）
 func (r *ApplicationRepository) GetByAPIKey(ctx context.Context, apiKey string) (*models.Application, error) {
 	query := `
-		SELECT app_id, name, description, domain, api_key, is_active, created_at, updated_at
-		FROM applications 
-		WHERE api_key = $1
+		SELECT ` + apiKeyApplicationColumns + `, k.key_salt, k.key_hash
+		FROM api_keys k
+		JOIN applications a ON a.app_id = k.app_id
+		WHERE k.key_prefix = $1 AND (k.expires_at IS NULL OR k.expires_at > NOW())
 	`
 
-	var app models.Application
-	var description sql.NullString
-	err := r.db.QueryRowContext(ctx, query, apiKey).Scan(
-		&app.AppID, &app.Name, &description, &app.Domain, &app.APIKey, &app.Active, &app.CreatedAt, &app.UpdatedAt,
-	)
-
+	rows, err := r.db.QueryContext(ctx, query, crypto.APIKeyPrefix(apiKey))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("application not found with API key: %s", apiKey)
+		return nil, fmt.Errorf("failed to query application by API key: %w", err)
+	}
+	defer rows.Close()
+
+	var found *models.Application
+	for rows.Next() {
+		var salt, hash string
+		app, err := scanAPIKeyApplication(rows, &salt, &hash)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil && crypto.VerifyAPIKey(apiKey, salt, hash) {
+			found = app
 		}
+	}
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to query application by API key: %w", err)
 	}
 
-	// NULLの場合は空文字列に変換
-	if description.Valid {
-		app.Description = description.String
-	} else {
-		app.Description = ""
+	if found == nil {
+		return nil, models.ErrApplicationInvalidAPIKey
 	}
 
-	return &app, nil
+	found.APIKey = apiKey
+	r.touchAPIKey(ctx, found.AuthenticatedKey)
+
+	return found, nil
+}
+
+// GetByAPIKeyID APIキーのIDでアプリケーションを検索（有効期限切れ・削除済みのキーは除外）
+func (r *ApplicationRepository) GetByAPIKeyID(ctx context.Context, keyID string) (*models.Application, error) {
+	query := `
+		SELECT ` + apiKeyApplicationColumns + `, k.key_salt, k.key_hash
+		FROM api_keys k
+		JOIN applications a ON a.app_id = k.app_id
+		WHERE k.id = $1 AND (k.expires_at IS NULL OR k.expires_at > NOW())
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query application by API key: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to query application by API key: %w", err)
+		}
+		return nil, models.ErrApplicationInvalidAPIKey
+	}
+
+	var salt, hash string
+	app, err := scanAPIKeyApplication(rows, &salt, &hash)
+	if err != nil {
+		return nil, err
+	}
+
+	r.touchAPIKey(ctx, app.AuthenticatedKey)
+
+	return app, nil
 }
 
 // List すべてのアプリケーションをページネーション付きで取得
 func (r *ApplicationRepository) List(ctx context.Context, limit, offset int) ([]*models.Application, error) {
 	query := `
-		SELECT app_id, name, description, domain, api_key, is_active, created_at, updated_at
+		SELECT app_id, name, description, domain, is_active, timezone, settings, plan, organization_id, created_at, updated_at
 		FROM applications 
 		ORDER BY created_at DESC 
 		LIMIT $1 OFFSET $2
@@ -146,15 +227,18 @@ func (r *ApplicationRepository) List(ctx context.Context, limit, offset int) ([]
 // Update アプリケーションを更新
 func (r *ApplicationRepository) Update(ctx context.Context, app *models.Application) error {
 	app.UpdatedAt = time.Now()
+	if app.Timezone == "" {
+		app.Timezone = models.DefaultApplicationTimezone
+	}
 
 	query := `
 		UPDATE applications 
-		SET name = $2, description = $3, domain = $4, api_key = $5, is_active = $6, updated_at = $7
+		SET name = $2, description = $3, domain = $4, is_active = $5, timezone = $6, updated_at = $7
 		WHERE app_id = $1
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
-		app.AppID, app.Name, app.Description, app.Domain, app.APIKey, app.Active, app.UpdatedAt,
+		app.AppID, app.Name, app.Description, app.Domain, app.Active, app.Timezone, app.UpdatedAt,
 	)
 
 	if err != nil {
@@ -198,9 +282,11 @@ func (r *ApplicationRepository) Delete(ctx context.Context, appID string) error
 func (r *ApplicationRepository) scanApplication(rows *sql.Rows) (*models.Application, error) {
 	var app models.Application
 	var description sql.NullString
+	var settings []byte
+	var organizationID sql.NullString
 
 	err := rows.Scan(
-		&app.AppID, &app.Name, &description, &app.Domain, &app.APIKey, &app.Active, &app.CreatedAt, &app.UpdatedAt,
+		&app.AppID, &app.Name, &description, &app.Domain, &app.Active, &app.Timezone, &settings, &app.Plan, &organizationID, &app.CreatedAt, &app.UpdatedAt,
 	)
 
 	if err != nil {
@@ -214,6 +300,11 @@ func (r *ApplicationRepository) scanApplication(rows *sql.Rows) (*models.Applica
 		app.Description = ""
 	}
 
+	if app.Settings, err = decodeSettings(settings); err != nil {
+		return nil, err
+	}
+	app.OrganizationID = organizationID.String
+
 	return &app, nil
 }
 
@@ -230,37 +321,392 @@ func (r *ApplicationRepository) Count(ctx context.Context) (int64, error) {
 	return count, nil
 }
 
-// GetByUserID ユーザーIDでアプリケーションを取得
+// GetByUserID ユーザーが所属する組織のアプリケーションをページネーション付きで取得
 func (r *ApplicationRepository) GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*models.Application, error) {
-	// 簡易的な実装として、全アプリケーションを返す
-	return r.List(ctx, limit, offset)
+	query := `
+		SELECT a.app_id, a.name, a.description, a.domain, a.is_active, a.timezone, a.settings, a.plan, a.organization_id, a.created_at, a.updated_at
+		FROM applications a
+		JOIN organization_members m ON m.organization_id = a.organization_id
+		WHERE m.user_id = $1
+		ORDER BY a.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applications by user: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*models.Application, 0)
+	for rows.Next() {
+		app, err := r.scanApplication(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, app)
+	}
+
+	return results, rows.Err()
+}
+
+// CountByUserID ユーザーが所属する組織のアプリケーション数を取得
+func (r *ApplicationRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM applications a
+		JOIN organization_members m ON m.organization_id = a.organization_id
+		WHERE m.user_id = $1
+	`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count applications by user: %w", err)
+	}
+
+	return count, nil
+}
+
+// RotateAPIKey APIキーをローテーション
+// 新しいキーを同じ名前で発行し、以前のキーの有効期限を graceUntil までに短縮する（有効期限は引き継ぐ）
+func (r *ApplicationRepository) RotateAPIKey(ctx context.Context, appID, keyID string, newKey *models.APIKey, apiKey string, graceUntil time.Time) (*models.APIKey, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, app_id, name, key_prefix, scopes, signing_secret, created_at, last_used_at, expires_at
+		FROM api_keys
+		WHERE app_id = $1 AND id = $2 AND (expires_at IS NULL OR expires_at > NOW())
+		FOR UPDATE
+	`
+
+	var previous models.APIKey
+	var signingSecret sql.NullString
+	var lastUsedAt, expiresAt sql.NullTime
+	err = tx.QueryRowContext(ctx, query, appID, keyID).Scan(
+		&previous.ID, &previous.AppID, &previous.Name, &previous.Prefix, pq.Array(&previous.Scopes), &signingSecret, &previous.CreatedAt, &lastUsedAt, &expiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	previous.SigningSecret = signingSecret.String
+	previous.LastUsedAt = nullTimePtr(lastUsedAt)
+	previous.ExpiresAt = nullTimePtr(expiresAt)
+
+	// 新しいキーは以前のキーの名前・スコープ・有効期限を引き継ぐ
+	// 署名が必要なキーの場合は、新しいキー用のシークレットを発行する
+	newKey.AppID = appID
+	newKey.Name = previous.Name
+	newKey.Scopes = previous.Scopes
+	newKey.ExpiresAt = previous.ExpiresAt
+	newKey.SigningSecret = ""
+	if previous.RequiresSignature() {
+		newKey.SigningSecret = crypto.GenerateSigningSecret()
+	}
+	if newKey.CreatedAt.IsZero() {
+		newKey.CreatedAt = time.Now()
+	}
+
+	if previous.ExpiresAt == nil || graceUntil.Before(*previous.ExpiresAt) {
+		previous.ExpiresAt = &graceUntil
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE api_keys SET expires_at = $2 WHERE id = $1`, previous.ID, previous.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("failed to update API key: %w", err)
+	}
+
+	if err := insertAPIKey(ctx, tx, newKey, apiKey); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	return &previous, nil
+}
+
+// CreateAPIKey アプリケーションにAPIキーを追加（平文のキーはハッシュ化して保存）
+func (r *ApplicationRepository) CreateAPIKey(ctx context.Context, key *models.APIKey, apiKey string) error {
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	err := insertAPIKey(ctx, r.db, key, apiKey)
+	if err != nil && isForeignKeyViolation(err) {
+		return models.ErrApplicationNotFound
+	}
+	return err
+}
+
+// ListAPIKeys アプリケーションのAPIキーを取得（有効期限切れのキーを含む）
+func (r *ApplicationRepository) ListAPIKeys(ctx context.Context, appID string) ([]*models.APIKey, error) {
+	query := `
+		SELECT id, app_id, name, key_prefix, scopes, signing_secret, created_at, last_used_at, expires_at
+		FROM api_keys
+		WHERE app_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*models.APIKey, 0)
+	for rows.Next() {
+		var key models.APIKey
+		var signingSecret sql.NullString
+		var lastUsedAt, expiresAt sql.NullTime
+		if err := rows.Scan(&key.ID, &key.AppID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &signingSecret, &key.CreatedAt, &lastUsedAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		key.SigningSecret = signingSecret.String
+		key.LastUsedAt = nullTimePtr(lastUsedAt)
+		key.ExpiresAt = nullTimePtr(expiresAt)
+		results = append(results, &key)
+	}
+
+	return results, rows.Err()
 }
 
-// RegenerateAPIKey APIキーを再生成
-func (r *ApplicationRepository) RegenerateAPIKey(ctx context.Context, id string) (string, error) {
-	newAPIKey := uuid.New().String()
+// DeleteAPIKey アプリケーションのAPIキーを削除
+func (r *ApplicationRepository) DeleteAPIKey(ctx context.Context, appID, keyID string) error {
+	query := `DELETE FROM api_keys WHERE app_id = $1 AND id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, appID, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
 
-	query := `UPDATE applications SET api_key = $2, updated_at = $3 WHERE app_id = $1`
+// SetAPIKeySigningSecret APIキーのリクエスト署名のシークレットを設定（空文字列の場合は削除）
+func (r *ApplicationRepository) SetAPIKeySigningSecret(ctx context.Context, appID, keyID, secret string) error {
+	query := `UPDATE api_keys SET signing_secret = $3 WHERE app_id = $1 AND id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, id, newAPIKey, time.Now())
+	result, err := r.db.ExecContext(ctx, query, appID, keyID, nullString(secret))
 	if err != nil {
-		return "", fmt.Errorf("failed to regenerate API key: %w", err)
+		return fmt.Errorf("failed to update API key signing secret: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return "", fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		return "", models.ErrApplicationNotFound
+		return models.ErrAPIKeyNotFound
 	}
 
-	return newAPIKey, nil
+	return nil
 }
 
-// UpdateSettings アプリケーション設定を更新
+// UpdatePlan アプリケーションのプランを更新
+func (r *ApplicationRepository) UpdatePlan(ctx context.Context, id, plan string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE applications SET plan = $2, updated_at = $3 WHERE app_id = $1`, id, plan, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update application plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrApplicationNotFound
+	}
+
+	return nil
+}
+
+// UpdateSettings アプリケーション設定を更新（指定したキーだけを上書きし、値が null のキーは削除）
 func (r *ApplicationRepository) UpdateSettings(ctx context.Context, id string, settings map[string]interface{}) error {
-	// 簡易的な実装として、何もしない
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	query := `
+		UPDATE applications
+		SET settings = jsonb_strip_nulls(settings || $2::jsonb), updated_at = $3
+		WHERE app_id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, settingsJSON, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrApplicationNotFound
+	}
+
 	return nil
 }
+
+// decodeSettings JSONBのアプリケーション設定をマップに変換
+func decodeSettings(data []byte) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	if len(data) == 0 {
+		return settings, nil
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	return settings, nil
+}
+
+// nullString 空文字列をNULLとして保存する値に変換
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// isForeignKeyViolation 外部キー制約違反のエラーかどうかを判定
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+// apiKeyApplicationColumns APIキーとアプリケーションを結合して取得する列
+const apiKeyApplicationColumns = `a.app_id, a.name, a.description, a.domain, a.is_active, a.timezone, a.settings, a.plan, a.organization_id, a.created_at, a.updated_at,
+		k.id, k.name, k.key_prefix, k.scopes, k.signing_secret, k.created_at, k.last_used_at, k.expires_at`
+
+// apiKeyLastUsedInterval 最終使用日時を更新する間隔（認証のたびに書き込まないため）
+const apiKeyLastUsedInterval = time.Minute
+
+// dbtx データベース接続またはトランザクション
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// insertAPIKey APIキーのプレフィックスとソルト付きハッシュを保存（スコープ未指定の場合はデフォルトのスコープ）
+// ハッシュにはソルトが付くため一意制約では重複を検出できず、同じプレフィックスのキーと照合する
+func insertAPIKey(ctx context.Context, db dbtx, key *models.APIKey, apiKey string) error {
+	exists, err := apiKeyExists(ctx, db, apiKey)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return models.ErrAPIKeyAlreadyExists
+	}
+
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+	if len(key.Scopes) == 0 {
+		key.Scopes = append([]string(nil), models.DefaultAPIKeyScopes...)
+	}
+	key.Prefix = crypto.APIKeyPrefix(apiKey)
+	salt := crypto.GenerateAPIKeySalt()
+
+	query := `
+		INSERT INTO api_keys (id, app_id, name, key_prefix, scopes, signing_secret, key_salt, key_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err = db.ExecContext(ctx, query,
+		key.ID, key.AppID, key.Name, key.Prefix, pq.Array(key.Scopes), nullString(key.SigningSecret), salt, crypto.HashAPIKey(apiKey, salt), key.CreatedAt, key.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	return nil
+}
+
+// apiKeyExists 同じAPIキーが既に保存されているかを確認
+func apiKeyExists(ctx context.Context, db dbtx, apiKey string) (bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT key_salt, key_hash FROM api_keys WHERE key_prefix = $1`, crypto.APIKeyPrefix(apiKey))
+	if err != nil {
+		return false, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var salt, hash string
+		if err := rows.Scan(&salt, &hash); err != nil {
+			return false, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		if crypto.VerifyAPIKey(apiKey, salt, hash) {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
+// scanAPIKeyApplication apiKeyApplicationColumns の行をアプリケーションと認証に使用したAPIキーに変換
+func scanAPIKeyApplication(rows *sql.Rows, salt, hash *string) (*models.Application, error) {
+	var app models.Application
+	var key models.APIKey
+	var description, organizationID, signingSecret sql.NullString
+	var settings []byte
+	var lastUsedAt, expiresAt sql.NullTime
+
+	err := rows.Scan(
+		&app.AppID, &app.Name, &description, &app.Domain, &app.Active, &app.Timezone, &settings, &app.Plan, &organizationID, &app.CreatedAt, &app.UpdatedAt,
+		&key.ID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &signingSecret, &key.CreatedAt, &lastUsedAt, &expiresAt, salt, hash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan application: %w", err)
+	}
+
+	app.Description = description.String
+	app.OrganizationID = organizationID.String
+	if app.Settings, err = decodeSettings(settings); err != nil {
+		return nil, err
+	}
+
+	key.AppID = app.AppID
+	key.SigningSecret = signingSecret.String
+	key.LastUsedAt = nullTimePtr(lastUsedAt)
+	key.ExpiresAt = nullTimePtr(expiresAt)
+	app.AuthenticatedKey = &key
+
+	return &app, nil
+}
+
+// touchAPIKey APIキーの最終使用日時を更新（前回の更新から apiKeyLastUsedInterval 以上経過している場合のみ）
+// 認証自体は成功しているため、更新に失敗してもエラーにしない
+func (r *ApplicationRepository) touchAPIKey(ctx context.Context, key *models.APIKey) {
+	now := time.Now()
+	if key == nil || (key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < apiKeyLastUsedInterval) {
+		return
+	}
+
+	if _, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, key.ID, now); err == nil {
+		key.LastUsedAt = &now
+	}
+}
+
+// nullTimePtr NULL許容の日時をポインタに変換
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,313 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// OrganizationRepository PostgreSQL用の組織・ユーザー・メンバーシップリポジトリ実装
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository 新しい組織リポジトリを作成
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{
+		db: db,
+	}
+}
+
+// CreateOrganization 組織を作成し、ownerUserID のユーザーをオーナーとして追加
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization, ownerUserID string) error {
+	if org.ID == "" {
+		org.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if org.CreatedAt.IsZero() {
+		org.CreatedAt = now
+	}
+	if org.UpdatedAt.IsZero() {
+		org.UpdatedAt = now
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organizations (id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+	`, org.ID, org.Name, org.CreatedAt, org.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save organization: %w", err)
+	}
+
+	if ownerUserID != "" {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO organization_members (organization_id, user_id, role, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, org.ID, ownerUserID, models.RoleOwner, now)
+		if err != nil {
+			// 存在しないユーザーを指定した場合
+			if isForeignKeyViolation(err) {
+				return models.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to save organization owner: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetOrganization 組織IDで組織を取得
+func (r *OrganizationRepository) GetOrganization(ctx context.Context, id string) (*models.Organization, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM organizations
+		WHERE id = $1
+	`
+
+	var org models.Organization
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&org.ID, &org.Name, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to query organization: %w", err)
+	}
+
+	return &org, nil
+}
+
+// ListOrganizations すべての組織を取得
+func (r *OrganizationRepository) ListOrganizations(ctx context.Context) ([]*models.Organization, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM organizations
+		ORDER BY name, id
+	`
+
+	return r.queryOrganizations(ctx, query)
+}
+
+// ListOrganizationsByUserID ユーザーが所属する組織を取得
+func (r *OrganizationRepository) ListOrganizationsByUserID(ctx context.Context, userID string) ([]*models.Organization, error) {
+	query := `
+		SELECT o.id, o.name, o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name, o.id
+	`
+
+	return r.queryOrganizations(ctx, query, userID)
+}
+
+// queryOrganizations 組織の一覧を取得するクエリを実行
+func (r *OrganizationRepository) queryOrganizations(ctx context.Context, query string, args ...interface{}) ([]*models.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*models.Organization, 0)
+	for rows.Next() {
+		var org models.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt, &org.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		results = append(results, &org)
+	}
+
+	return results, rows.Err()
+}
+
+// CreateUser ユーザーを作成
+func (r *OrganizationRepository) CreateUser(ctx context.Context, user *models.User) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+
+	query := `
+		INSERT INTO users (id, email, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Email, user.Name, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		// IDまたはメールアドレスが重複している場合
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return models.ErrUserAlreadyExists
+		}
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+
+	return nil
+}
+
+// GetUser ユーザーIDでユーザーを取得
+func (r *OrganizationRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	query := `
+		SELECT id, email, name, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+
+	var user models.User
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// GetMembership 組織でのユーザーのメンバーシップを取得
+func (r *OrganizationRepository) GetMembership(ctx context.Context, organizationID, userID string) (*models.Membership, error) {
+	query := `
+		SELECT organization_id, user_id, role, created_at
+		FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2
+	`
+
+	var m models.Membership
+	err := r.db.QueryRowContext(ctx, query, organizationID, userID).Scan(&m.OrganizationID, &m.UserID, &m.Role, &m.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrMembershipNotFound
+		}
+		return nil, fmt.Errorf("failed to query membership: %w", err)
+	}
+
+	return &m, nil
+}
+
+// SetMember ユーザーを組織に追加し、既に所属している場合はロールを更新
+func (r *OrganizationRepository) SetMember(ctx context.Context, membership *models.Membership) error {
+	if membership.CreatedAt.IsZero() {
+		membership.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		membership.OrganizationID, membership.UserID, membership.Role, membership.CreatedAt,
+	).Scan(&membership.CreatedAt)
+	if err != nil {
+		// 存在しない組織またはユーザーを指定した場合
+		if isForeignKeyViolation(err) {
+			return models.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to save membership: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember ユーザーを組織から削除
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	query := `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, organizationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete membership: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return models.ErrMembershipNotFound
+	}
+
+	return nil
+}
+
+// ListMembers 組織のメンバーを取得
+func (r *OrganizationRepository) ListMembers(ctx context.Context, organizationID string) ([]*models.Membership, error) {
+	query := `
+		SELECT organization_id, user_id, role, created_at
+		FROM organization_members
+		WHERE organization_id = $1
+		ORDER BY created_at, user_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memberships: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*models.Membership, 0)
+	for rows.Next() {
+		var m models.Membership
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan membership: %w", err)
+		}
+		results = append(results, &m)
+	}
+
+	return results, rows.Err()
+}
+
+// CountOwners 組織のオーナー数を取得
+func (r *OrganizationRepository) CountOwners(ctx context.Context, organizationID string) (int, error) {
+	query := `SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = $2`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, organizationID, models.RoleOwner).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count owners: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetUserRoles ユーザーの組織IDごとのロールを取得
+func (r *OrganizationRepository) GetUserRoles(ctx context.Context, userID string) (map[string]string, error) {
+	query := `SELECT organization_id, role FROM organization_members WHERE user_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := make(map[string]string)
+	for rows.Next() {
+		var organizationID, role string
+		if err := rows.Scan(&organizationID, &role); err != nil {
+			return nil, fmt.Errorf("failed to scan user role: %w", err)
+		}
+		roles[organizationID] = role
+	}
+
+	return roles, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+const (
+	// partitionedTable パーティション化されたアクセスログテーブル
+	partitionedTable = "access_logs"
+	// defaultPartition どのパーティションにも含まれないデータを保持するデフォルトパーティション
+	defaultPartition = "access_logs_default"
+)
+
+// PartitionRepository PostgreSQL用のアクセスログパーティションリポジトリ実装
+type PartitionRepository struct {
+	db *sql.DB
+}
+
+// NewPartitionRepository 新しいアクセスログパーティションリポジトリを作成
+func NewPartitionRepository(db *sql.DB) *PartitionRepository {
+	return &PartitionRepository{
+		db: db,
+	}
+}
+
+// IsPartitioned access_logs がパーティションテーブルかどうかを取得（マイグレーション 007 の適用状況）
+func (r *PartitionRepository) IsPartitioned(ctx context.Context) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM pg_class WHERE oid = to_regclass($1) AND relkind = 'p')`
+
+	var partitioned bool
+	if err := r.db.QueryRowContext(ctx, query, partitionedTable).Scan(&partitioned); err != nil {
+		return false, fmt.Errorf("failed to check access_logs partitioning: %w", err)
+	}
+
+	return partitioned, nil
+}
+
+// ListPartitions access_logs に接続されている範囲パーティションを範囲の開始順で取得（デフォルトパーティションは含まない）
+func (r *PartitionRepository) ListPartitions(ctx context.Context) ([]models.Partition, error) {
+	query := `
+		SELECT name, bounds[1]::timestamptz, bounds[2]::timestamptz
+		FROM (
+			SELECT c.relname as name,
+			       regexp_match(pg_get_expr(c.relpartbound, c.oid), 'FROM \(''([^'']+)''\) TO \(''([^'']+)''\)') as bounds
+			FROM pg_inherits i
+			JOIN pg_class c ON c.oid = i.inhrelid
+			WHERE i.inhparent = to_regclass($1)
+		) p
+		WHERE bounds IS NOT NULL
+		ORDER BY 2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, partitionedTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+	defer rows.Close()
+
+	partitions := make([]models.Partition, 0)
+	for rows.Next() {
+		var partition models.Partition
+		if err := rows.Scan(&partition.Name, &partition.From, &partition.To); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+		partition.From = partition.From.UTC()
+		partition.To = partition.To.UTC()
+		partitions = append(partitions, partition)
+	}
+
+	return partitions, rows.Err()
+}
+
+// CreatePartition [from, to) の範囲パーティションを作成
+// デフォルトパーティションに範囲内のデータがある場合は、新しいパーティションに移してから接続する
+func (r *PartitionRepository) CreatePartition(ctx context.Context, partition models.Partition) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin partition transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	name := pq.QuoteIdentifier(partition.Name)
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{query: fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, name, partitionedTable)},
+		{
+			query: fmt.Sprintf(`
+				WITH moved AS (
+					DELETE FROM %s WHERE timestamp >= $1 AND timestamp < $2 RETURNING *
+				)
+				INSERT INTO %s SELECT * FROM moved
+			`, defaultPartition, name),
+			args: []interface{}{partition.From, partition.To},
+		},
+		// ATTACH PARTITION はプレースホルダを使えないため、範囲はリテラルで指定する
+		{query: fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (%s) TO (%s)`,
+			partitionedTable, name, partitionBound(partition.From), partitionBound(partition.To))},
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", partition.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit partition %s: %w", partition.Name, err)
+	}
+
+	return nil
+}
+
+// DetachPartition パーティションを access_logs から切り離す（テーブルとデータは残る）
+func (r *PartitionRepository) DetachPartition(ctx context.Context, name string) error {
+	query := fmt.Sprintf(`ALTER TABLE %s DETACH PARTITION %s`, partitionedTable, pq.QuoteIdentifier(name))
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to detach partition %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// DropPartition パーティションをデータごと削除
+func (r *PartitionRepository) DropPartition(ctx context.Context, name string) error {
+	query := fmt.Sprintf(`DROP TABLE %s`, pq.QuoteIdentifier(name))
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to drop partition %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// partitionBound パーティションの境界をUTCのタイムスタンプリテラルに変換
+func partitionBound(t time.Time) string {
+	return pq.QuoteLiteral(t.UTC().Format("2006-01-02 15:04:05") + "+00")
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+// RetentionRepository PostgreSQL用のデータ保持期間リポジトリ実装
+type RetentionRepository struct {
+	db *sql.DB
+}
+
+// NewRetentionRepository 新しいデータ保持期間リポジトリを作成
+func NewRetentionRepository(db *sql.DB) *RetentionRepository {
+	return &RetentionRepository{
+		db: db,
+	}
+}
+
+// ListRetentionPolicies 保持期間が設定されているアプリケーションの保持期間を取得
+func (r *RetentionRepository) ListRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error) {
+	query := `
+		SELECT app_id, settings
+		FROM applications
+		WHERE settings ?| array['retention_raw_days', 'retention_rollup_days']
+		ORDER BY app_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := make([]models.RetentionPolicy, 0)
+	for rows.Next() {
+		var appID string
+		var data []byte
+		if err := rows.Scan(&appID, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan retention policy: %w", err)
+		}
+		settings, err := decodeSettings(data)
+		if err != nil {
+			return nil, err
+		}
+		if policy := models.RetentionPolicyFromSettings(appID, settings); policy.IsEnabled() {
+			policies = append(policies, policy)
+		}
+	}
+
+	return policies, rows.Err()
+}
+
+// DeleteExpiredEvents before より古いトラッキングデータを最大 limit 件削除し、削除件数を返す
+// パーティション化されている場合も timestamp の条件で対象のパーティションだけを走査する
+func (r *RetentionRepository) DeleteExpiredEvents(ctx context.Context, appID string, before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM access_logs
+		WHERE app_id = $1 AND timestamp < $2 AND (id, timestamp) IN (
+			SELECT id, timestamp FROM access_logs
+			WHERE app_id = $1 AND timestamp < $2
+			LIMIT $3
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, appID, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired events: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteExpiredRollups before より前のバケットのロールアップを最大 limit 件削除し、削除件数を返す
+func (r *RetentionRepository) DeleteExpiredRollups(ctx context.Context, appID string, before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM access_log_rollups
+		WHERE ctid IN (
+			SELECT ctid FROM access_log_rollups
+			WHERE app_id = $1 AND bucket < $2
+			LIMIT $3
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, appID, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired rollups: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// SaveRetentionRun クリーンアップの実行結果を保存
+func (r *RetentionRepository) SaveRetentionRun(ctx context.Context, run *models.RetentionRun) error {
+	query := `
+		INSERT INTO retention_runs (app_id, started_at, duration_ms, events_deleted, rollups_deleted, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	var runError sql.NullString
+	if run.Error != "" {
+		runError = sql.NullString{String: run.Error, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		run.AppID, run.StartedAt, run.Duration.Milliseconds(),
+		run.EventsDeleted, run.RollupsDeleted, runError,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save retention run: %w", err)
+	}
+
+	return nil
+}
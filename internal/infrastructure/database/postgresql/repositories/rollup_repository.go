@@ -0,0 +1,367 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/hll"
+
+	"github.com/lib/pq"
+)
+
+// RollupRepository PostgreSQL用のロールアップリポジトリ実装
+type RollupRepository struct {
+	db *sql.DB
+}
+
+// NewRollupRepository 新しいロールアップリポジトリを作成
+func NewRollupRepository(db *sql.DB) *RollupRepository {
+	return &RollupRepository{
+		db: db,
+	}
+}
+
+// GetWatermark 集計粒度の集計済みの終端を取得（未集計の場合はゼロ値）
+func (r *RollupRepository) GetWatermark(ctx context.Context, granularity string) (time.Time, error) {
+	query := `SELECT processed_until FROM rollup_watermarks WHERE granularity = $1`
+
+	var watermark time.Time
+	err := r.db.QueryRowContext(ctx, query, granularity).Scan(&watermark)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get rollup watermark: %w", err)
+	}
+
+	return watermark.UTC(), nil
+}
+
+// GetEarliestEventTime 最も古いトラッキングデータの時刻を取得（データがない場合はゼロ値）
+func (r *RollupRepository) GetEarliestEventTime(ctx context.Context) (time.Time, error) {
+	query := `SELECT MIN(timestamp) FROM access_logs`
+
+	var earliest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query).Scan(&earliest); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get earliest event time: %w", err)
+	}
+	if !earliest.Valid {
+		return time.Time{}, nil
+	}
+
+	return earliest.Time.UTC(), nil
+}
+
+// ScanEvents start 以上 end 未満のトラッキングデータを集計に必要な列だけ読み込んで fn に渡す
+// ユーザーエージェントの解析結果・流入元の判定結果のない行は、集計時に組み込みのルール・流入元の一覧で判定する
+func (r *RollupRepository) ScanEvents(ctx context.Context, start, end time.Time, fn func(*models.TrackingData) error) error {
+	query := `
+		SELECT app_id, COALESCE(user_agent, ''), COALESCE(url, ''), COALESCE(host(ip_address), ''),
+		       COALESCE(session_id, ''), COALESCE(referrer, ''), timestamp,
+		       COALESCE(browser, ''), COALESCE(os, ''), COALESCE(device_type, ''), COALESCE(country, ''),
+		       COALESCE(referrer_host, ''), COALESCE(channel, ''), COALESCE(utm_campaign, ''),
+		       COALESCE(page_path, ''), COALESCE(page_group, '')
+		FROM access_logs
+		WHERE timestamp >= $1 AND timestamp < $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to scan events for rollup: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data models.TrackingData
+		if err := rows.Scan(&data.AppID, &data.UserAgent, &data.URL, &data.IPAddress,
+			&data.SessionID, &data.Referrer, &data.Timestamp,
+			&data.Browser, &data.OS, &data.DeviceType, &data.Country,
+			&data.ReferrerHost, &data.Channel, &data.UTMCampaign,
+			&data.PagePath, &data.PageGroup); err != nil {
+			return fmt.Errorf("failed to scan event for rollup: %w", err)
+		}
+		if err := fn(&data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// replaceRollupsChunkSize は1回のINSERTに含める最大行数（プレースホルダ上限 65535 を超えないように制限）
+const replaceRollupsChunkSize = 1000
+
+// ReplaceRollups バケットの集計結果を1トランザクションで置き換える
+// advance が true の場合は集計済みの終端をバケットの終わりまで進める（後退はしない）
+func (r *RollupRepository) ReplaceRollups(ctx context.Context, granularity string, bucket time.Time, rows []*models.RollupRow, advance bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin rollup transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM access_log_rollups WHERE granularity = $1 AND bucket = $2`,
+		granularity, bucket,
+	); err != nil {
+		return fmt.Errorf("failed to delete rollups: %w", err)
+	}
+
+	for start := 0; start < len(rows); start += replaceRollupsChunkSize {
+		end := start + replaceRollupsChunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if err := r.insertRollupChunk(ctx, tx, rows[start:end]); err != nil {
+			return err
+		}
+	}
+
+	if advance {
+		bucketEnd := bucket.Add(time.Hour)
+		if granularity == models.RollupGranularityDay {
+			bucketEnd = bucket.AddDate(0, 0, 1)
+		}
+
+		query := `
+			INSERT INTO rollup_watermarks (granularity, processed_until, updated_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (granularity) DO UPDATE SET
+				processed_until = GREATEST(rollup_watermarks.processed_until, EXCLUDED.processed_until),
+				updated_at = NOW()
+		`
+		if _, err := tx.ExecContext(ctx, query, granularity, bucketEnd); err != nil {
+			return fmt.Errorf("failed to update rollup watermark: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rollups: %w", err)
+	}
+
+	return nil
+}
+
+// insertRollupChunk 1チャンク分の集計結果を複数行INSERTで保存
+func (r *RollupRepository) insertRollupChunk(ctx context.Context, tx *sql.Tx, chunk []*models.RollupRow) error {
+	const columns = 11
+
+	var query strings.Builder
+	query.WriteString(`INSERT INTO access_log_rollups (
+			app_id, granularity, bucket, dimension, value,
+			page_views, sessions, visitors, session_seconds,
+			session_sketch, visitor_sketch
+		) VALUES `)
+
+	args := make([]interface{}, 0, len(chunk)*columns)
+	for i, row := range chunk {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		base := i * columns
+		query.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			base+1, base+2, base+3, base+4, base+5, base+6, base+7, base+8, base+9, base+10, base+11))
+
+		args = append(args,
+			row.AppID, row.Granularity, row.Bucket, row.Dimension, row.Value,
+			row.PageViews, row.Sessions, row.Visitors, row.SessionSeconds,
+			row.SessionSketch, row.VisitorSketch,
+		)
+	}
+
+	if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+		return fmt.Errorf("failed to save rollups: %w", err)
+	}
+
+	return nil
+}
+
+// GetRollupTotals 範囲内のロールアップから期間全体のページビュー・ボット・モバイルの件数と、
+// 平均セッション時間の計算に使うバケットごとのセッション数・セッション時間の合計を集計
+// ユニークなセッション数・訪問者数はバケットをまたぐと合算できないため GetRollupSketches で推定する
+func (r *RollupRepository) GetRollupTotals(ctx context.Context, appID string, ranges []models.RollupRange) (*models.RollupTotals, error) {
+	var totals models.RollupTotals
+	if len(ranges) == 0 {
+		return &totals, nil
+	}
+
+	args := []interface{}{appID}
+	where := rollupRangeCondition(ranges, &args)
+
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(SUM(page_views) FILTER (WHERE dimension = 'total'), 0) as page_views,
+			COALESCE(SUM(page_views) FILTER (WHERE dimension = 'device_type' AND value = 'bot'), 0) as bot_requests,
+			COALESCE(SUM(page_views) FILTER (WHERE dimension = 'device_type' AND value IN ('mobile', 'tablet')), 0) as mobile_requests,
+			COALESCE(SUM(sessions) FILTER (WHERE dimension = 'total'), 0) as sessions,
+			COALESCE(SUM(session_seconds) FILTER (WHERE dimension = 'total'), 0) as session_seconds
+		FROM access_log_rollups
+		WHERE app_id = $1 AND dimension IN ('total', 'device_type') AND (%s)
+	`, where)
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&totals.PageViews,
+		&totals.BotRequests,
+		&totals.MobileRequests,
+		&totals.Sessions,
+		&totals.SessionSeconds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollup totals: %w", err)
+	}
+
+	return &totals, nil
+}
+
+// GetRollupGroups 範囲内のロールアップを集計軸の値ごとに件数の多い順で集計
+// limit が正の場合は件数を制限する。セッション数・訪問者数はバケットをまたぐと合算できないため GetRollupSketches で推定する
+func (r *RollupRepository) GetRollupGroups(ctx context.Context, appID, dimension string, ranges []models.RollupRange, limit int) ([]*models.StatsGroup, error) {
+	groups := make([]*models.StatsGroup, 0)
+	if len(ranges) == 0 {
+		return groups, nil
+	}
+
+	args := []interface{}{appID, dimension}
+	where := rollupRangeCondition(ranges, &args)
+
+	query := fmt.Sprintf(`
+		SELECT value, SUM(page_views) as requests
+		FROM access_log_rollups
+		WHERE app_id = $1 AND dimension = $2 AND (%s)
+		GROUP BY value
+		ORDER BY requests DESC, value ASC
+	`, where)
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollup groups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group models.StatsGroup
+		if err := rows.Scan(&group.Key, &group.Requests); err != nil {
+			return nil, fmt.Errorf("failed to scan rollup groups: %w", err)
+		}
+		groups = append(groups, &group)
+	}
+
+	return groups, rows.Err()
+}
+
+// GetRollupSketches 範囲内のロールアップのセッション・訪問者のスケッチを集計軸の値ごとに合算
+// values の値のうちロールアップのある値だけを返す。スケッチのない行はバケットごとのユニーク数を合計する
+func (r *RollupRepository) GetRollupSketches(ctx context.Context, appID, dimension string, values []string, ranges []models.RollupRange) ([]*models.UniqueSketch, error) {
+	sketches := make([]*models.UniqueSketch, 0)
+	if len(values) == 0 || len(ranges) == 0 {
+		return sketches, nil
+	}
+
+	args := []interface{}{appID, dimension, pq.Array(values)}
+	where := rollupRangeCondition(ranges, &args)
+
+	query := fmt.Sprintf(`
+		SELECT value, sessions, visitors, session_sketch, visitor_sketch
+		FROM access_log_rollups
+		WHERE app_id = $1 AND dimension = $2 AND value = ANY($3) AND (%s)
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollup sketches: %w", err)
+	}
+	defer rows.Close()
+
+	index := make(map[string]*models.UniqueSketch)
+	for rows.Next() {
+		var value string
+		var sessions, visitors int64
+		var sessionSketch, visitorSketch []byte
+		if err := rows.Scan(&value, &sessions, &visitors, &sessionSketch, &visitorSketch); err != nil {
+			return nil, fmt.Errorf("failed to scan rollup sketches: %w", err)
+		}
+
+		merged, ok := index[value]
+		if !ok {
+			merged = models.NewUniqueSketch(value)
+			index[value] = merged
+			sketches = append(sketches, merged)
+		}
+		if err := mergeSketch(merged.Sessions, sessionSketch, &merged.LegacySessions, sessions); err != nil {
+			return nil, err
+		}
+		if err := mergeSketch(merged.Visitors, visitorSketch, &merged.LegacyVisitors, visitors); err != nil {
+			return nil, err
+		}
+	}
+
+	return sketches, rows.Err()
+}
+
+// mergeSketch はエンコードしたスケッチを sketch に合算し、スケッチがない場合はバケットのユニーク数を legacy に加える
+func mergeSketch(sketch *hll.Sketch, data []byte, legacy *int64, count int64) error {
+	if data == nil {
+		*legacy += count
+		return nil
+	}
+	parsed, err := hll.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse rollup sketch: %w", err)
+	}
+	sketch.Merge(parsed)
+	return nil
+}
+
+// GetRollupSeries 範囲内の total 軸のロールアップをバケットごとに時系列順で取得
+func (r *RollupRepository) GetRollupSeries(ctx context.Context, appID string, ranges []models.RollupRange) ([]*models.RollupRow, error) {
+	series := make([]*models.RollupRow, 0)
+	if len(ranges) == 0 {
+		return series, nil
+	}
+
+	args := []interface{}{appID}
+	where := rollupRangeCondition(ranges, &args)
+
+	query := fmt.Sprintf(`
+		SELECT granularity, bucket, page_views, sessions, visitors, session_seconds
+		FROM access_log_rollups
+		WHERE app_id = $1 AND dimension = 'total' AND (%s)
+		ORDER BY bucket ASC
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollup series: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := models.RollupRow{AppID: appID, Dimension: models.RollupDimensionTotal}
+		if err := rows.Scan(&row.Granularity, &row.Bucket, &row.PageViews, &row.Sessions, &row.Visitors, &row.SessionSeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan rollup series: %w", err)
+		}
+		row.Bucket = row.Bucket.UTC()
+		series = append(series, &row)
+	}
+
+	return series, rows.Err()
+}
+
+// rollupRangeCondition 範囲ごとの条件をORで結合したWHERE句を作成し、引数を args に追加
+func rollupRangeCondition(ranges []models.RollupRange, args *[]interface{}) string {
+	conditions := make([]string, 0, len(ranges))
+	for _, rng := range ranges {
+		*args = append(*args, rng.Granularity, rng.Start, rng.End)
+		n := len(*args)
+		conditions = append(conditions, fmt.Sprintf("(granularity = $%d AND bucket >= $%d AND bucket < $%d)", n-2, n-1, n))
+	}
+	return strings.Join(conditions, " OR ")
+}
@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"accesslog-tracker/internal/botdetect"
 	"accesslog-tracker/internal/domain/models"
 )
 
@@ -31,23 +34,14 @@ func (r *TrackingRepository) Save(ctx context.Context, data *models.TrackingData
 		data.CreatedAt = time.Now()
 	}
 
-	// カスタムパラメータをJSONに変換
-	customParamsJSON, err := json.Marshal(data.CustomParams)
+	args, err := trackingValues(data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal custom params: %w", err)
+		return err
 	}
 
-	query := `
-		INSERT INTO access_logs (
-			id, app_id, user_agent, url, ip_address, session_id, referrer, 
-			timestamp, custom_params, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`
+	query := fmt.Sprintf(`INSERT INTO access_logs (%s) VALUES %s`, trackingColumns, trackingPlaceholders(0))
 
-	_, err = r.db.ExecContext(ctx, query,
-		data.ID, data.AppID, data.UserAgent, data.URL, data.IPAddress, data.SessionID, 
-		data.Referrer, data.Timestamp, customParamsJSON, data.CreatedAt,
-	)
+	_, err = r.db.ExecContext(ctx, query, args...)
 
 	if err != nil {
 		return fmt.Errorf("failed to save tracking data: %w", err)
@@ -56,11 +50,133 @@ func (r *TrackingRepository) Save(ctx context.Context, data *models.TrackingData
 	return nil
 }
 
+// saveBatchChunkSize は1回のINSERTに含める最大行数（プレースホルダ上限 65535 を超えないように制限）
+const saveBatchChunkSize = 1000
+
+// SaveBatch 複数のトラッキングデータを複数行INSERTでまとめて保存
+func (r *TrackingRepository) SaveBatch(ctx context.Context, batch []*models.TrackingData) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(batch); start += saveBatchChunkSize {
+		end := start + saveBatchChunkSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+		if err := r.insertChunk(ctx, tx, batch[start:end]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tracking batch: %w", err)
+	}
+
+	return nil
+}
+
+// insertChunk 1チャンク分のトラッキングデータを複数行INSERTで保存
+func (r *TrackingRepository) insertChunk(ctx context.Context, tx *sql.Tx, chunk []*models.TrackingData) error {
+	var query strings.Builder
+	query.WriteString(fmt.Sprintf(`INSERT INTO access_logs (%s) VALUES `, trackingColumns))
+
+	args := make([]interface{}, 0, len(chunk)*trackingColumnCount)
+	for i, data := range chunk {
+		if data.ID == "" {
+			data.ID = uuid.New().String()
+		}
+		if data.CreatedAt.IsZero() {
+			data.CreatedAt = time.Now()
+		}
+
+		values, err := trackingValues(data)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString(trackingPlaceholders(i * trackingColumnCount))
+		args = append(args, values...)
+	}
+
+	if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+		return fmt.Errorf("failed to save tracking batch: %w", err)
+	}
+
+	return nil
+}
+
+// trackingColumns 保存する access_logs の列（trackingValues と同じ順）
+const trackingColumns = `id, app_id, user_agent, url, ip_address, session_id, referrer,
+			timestamp, custom_params, created_at,
+			browser, browser_version, os, os_version, device_family, device_type,
+			country, region, city, asn, bot_score, bot_reason,
+			referrer_host, channel, utm_source, utm_medium, utm_campaign, utm_term, utm_content, gclid, fbclid,
+			page_path, page_group`
+
+// trackingColumnCount 保存する列の数
+const trackingColumnCount = 33
+
+// trackingValues 保存する列の値を trackingColumns の順に返す
+func trackingValues(data *models.TrackingData) ([]interface{}, error) {
+	// カスタムパラメータをJSONに変換
+	customParamsJSON, err := json.Marshal(data.CustomParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal custom params: %w", err)
+	}
+
+	return []interface{}{
+		data.ID, data.AppID, data.UserAgent, data.URL, data.IPAddress, data.SessionID,
+		data.Referrer, data.Timestamp, customParamsJSON, data.CreatedAt,
+		nullString(data.Browser), nullString(data.BrowserVersion), nullString(data.OS),
+		nullString(data.OSVersion), nullString(data.DeviceFamily), nullString(data.DeviceType),
+		nullString(data.Country), nullString(data.Region), nullString(data.City),
+		sql.NullInt64{Int64: data.ASN, Valid: data.ASN != 0},
+		data.BotScore, nullString(data.BotReason),
+		nullString(data.ReferrerHost), nullString(data.Channel),
+		nullString(data.UTMSource), nullString(data.UTMMedium), nullString(data.UTMCampaign),
+		nullString(data.UTMTerm), nullString(data.UTMContent),
+		nullString(data.GCLID), nullString(data.FBCLID),
+		nullString(data.PagePath), nullString(data.PageGroup),
+	}, nil
+}
+
+// trackingPlaceholders base+1 から始まる1行分のプレースホルダを返す
+func trackingPlaceholders(base int) string {
+	placeholders := make([]string, trackingColumnCount)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", base+i+1)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
+
+// trackingSelectColumns 読み込む access_logs の列（scanTrackingData と同じ順）
+// ユーザーエージェントの解析結果・位置情報・流入元・正規化したページURLは導入前の行や判定できない場合は NULL のため空文字列（ASNは0）にする
+const trackingSelectColumns = `id, app_id, user_agent, url, ip_address, session_id, referrer,
+		       timestamp, custom_params, created_at,
+		       COALESCE(browser, ''), COALESCE(browser_version, ''), COALESCE(os, ''),
+		       COALESCE(os_version, ''), COALESCE(device_family, ''), COALESCE(device_type, ''),
+		       COALESCE(country, ''), COALESCE(region, ''), COALESCE(city, ''), COALESCE(asn, 0),
+		       COALESCE(bot_score, 0), COALESCE(bot_reason, ''),
+		       COALESCE(referrer_host, ''), COALESCE(channel, ''),
+		       COALESCE(utm_source, ''), COALESCE(utm_medium, ''), COALESCE(utm_campaign, ''),
+		       COALESCE(utm_term, ''), COALESCE(utm_content, ''),
+		       COALESCE(gclid, ''), COALESCE(fbclid, ''),
+		       COALESCE(page_path, ''), COALESCE(page_group, '')`
+
 // FindByAppID アプリケーションIDでトラッキングデータを検索
 func (r *TrackingRepository) FindByAppID(ctx context.Context, appID string, limit, offset int) ([]*models.TrackingData, error) {
 	query := `
-		SELECT id, app_id, user_agent, url, ip_address, session_id, referrer, 
-		       timestamp, custom_params, created_at
+		SELECT `+trackingSelectColumns+`
 		FROM access_logs 
 		WHERE app_id = $1 
 		ORDER BY timestamp DESC 
@@ -93,8 +209,7 @@ func (r *TrackingRepository) GetByAppID(ctx context.Context, appID string, limit
 // GetBySessionID セッションIDでトラッキングデータを検索
 func (r *TrackingRepository) GetBySessionID(ctx context.Context, sessionID string) ([]*models.TrackingData, error) {
 	query := `
-		SELECT id, app_id, user_agent, url, ip_address, session_id, referrer, 
-		       timestamp, custom_params, created_at
+		SELECT `+trackingSelectColumns+`
 		FROM access_logs 
 		WHERE session_id = $1 
 		ORDER BY timestamp ASC
@@ -126,8 +241,7 @@ func (r *TrackingRepository) FindBySessionID(ctx context.Context, sessionID stri
 // FindByDateRange 日付範囲でトラッキングデータを検索
 func (r *TrackingRepository) FindByDateRange(ctx context.Context, appID string, start, end time.Time) ([]*models.TrackingData, error) {
 	query := `
-		SELECT id, app_id, user_agent, url, ip_address, session_id, referrer, 
-		       timestamp, custom_params, created_at
+		SELECT `+trackingSelectColumns+`
 		FROM access_logs 
 		WHERE app_id = $1 AND timestamp BETWEEN $2 AND $3
 		ORDER BY timestamp DESC
@@ -152,17 +266,18 @@ func (r *TrackingRepository) FindByDateRange(ctx context.Context, appID string,
 }
 
 // GetStatsByAppID アプリケーションIDの統計情報を取得
+// ボット数はインジェスト時に判定したスコアが botdetect.BotThreshold 以上のイベント数
 func (r *TrackingRepository) GetStatsByAppID(ctx context.Context, appID string, start, end time.Time) (*models.TrackingStats, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT 
 			COUNT(*) as total_requests,
 			COUNT(DISTINCT session_id) as unique_sessions,
 			COUNT(DISTINCT ip_address) as unique_ips,
-			COUNT(CASE WHEN user_agent ILIKE '%bot%' OR user_agent ILIKE '%crawler%' THEN 1 END) as bot_requests,
-			COUNT(CASE WHEN user_agent ILIKE '%mobile%' OR user_agent ILIKE '%android%' OR user_agent ILIKE '%iphone%' THEN 1 END) as mobile_requests
+			COUNT(CASE WHEN bot_score >= %d THEN 1 END) as bot_requests,
+			COUNT(CASE WHEN user_agent ILIKE '%%mobile%%' OR user_agent ILIKE '%%android%%' OR user_agent ILIKE '%%iphone%%' THEN 1 END) as mobile_requests
 		FROM access_logs 
 		WHERE app_id = $1 AND timestamp BETWEEN $2 AND $3
-	`
+	`, botdetect.BotThreshold)
 
 	var stats models.TrackingStats
 	err := r.db.QueryRowContext(ctx, query, appID, start, end).Scan(
@@ -185,6 +300,196 @@ func (r *TrackingRepository) GetStatsByAppID(ctx context.Context, appID string,
 	return &stats, nil
 }
 
+// statsGroupExpressions グループ化単位ごとのキーの式（ユーザー入力をSQLに埋め込まないよう固定の式のみ許可）
+// リファラーはロールアップの集計軸と揃えてホスト名（小文字）で集計する（導入前の行はリファラーから取り出す）
+// ページは正規化したページURLで集計する（導入前の行はページURL）
+// チャネル・キャンペーン・ページのグループはインジェスト時に保存した値で集計する
+var statsGroupExpressions = map[string]string{
+	models.StatsGroupByDay:       `to_char(date_trunc('day', timestamp AT TIME ZONE 'UTC'), 'YYYY-MM-DD')`,
+	models.StatsGroupByHour:      `to_char(date_trunc('hour', timestamp AT TIME ZONE 'UTC'), 'YYYY-MM-DD"T"HH24:00:00"Z"')`,
+	models.StatsGroupByPage:      `COALESCE(page_path, url)`,
+	models.StatsGroupByReferrer:  `COALESCE(referrer_host, lower(substring(referrer from '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^/?#@]*@)?([^/?#:]+)')))`,
+	models.StatsGroupByBrowser:   `browser`,
+	models.StatsGroupByOS:        `os`,
+	models.StatsGroupByDevice:    `device_type`,
+	models.StatsGroupByCountry:   `country`,
+	models.StatsGroupByChannel:   `channel`,
+	models.StatsGroupByCampaign:  `utm_campaign`,
+	models.StatsGroupByPageGroup: `page_group`,
+}
+
+// GetGroupedStats 期間内の統計を日・時・ページ・リファラー・ブラウザ・OS・デバイスタイプ・国・チャネル・キャンペーン・ページのグループ単位で集計
+// 日・時は時系列順、それ以外は件数の多い順に並べ、limit が正の場合は件数を制限する
+// ブラウザ・OS・デバイスタイプ・国・チャネル・キャンペーン・ページのグループはインジェスト時に保存した値で集計する（値のない行は含めない）
+func (r *TrackingRepository) GetGroupedStats(ctx context.Context, appID, groupBy string, start, end time.Time, limit int) ([]*models.StatsGroup, error) {
+	expr, ok := statsGroupExpressions[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", models.ErrStatisticsInvalidGroupBy, groupBy)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			%s as group_key,
+			COUNT(*) as requests,
+			COUNT(DISTINCT session_id) as unique_sessions,
+			COUNT(DISTINCT ip_address) as unique_ips
+		FROM access_logs 
+		WHERE app_id = $1 AND timestamp BETWEEN $2 AND $3
+	`, expr)
+
+	switch groupBy {
+	case models.StatsGroupByDay, models.StatsGroupByHour:
+		query += " GROUP BY group_key ORDER BY group_key ASC"
+	default:
+		query += fmt.Sprintf(" AND %s IS NOT NULL AND %s <> '' GROUP BY group_key ORDER BY requests DESC, group_key ASC", expr, expr)
+	}
+
+	args := []interface{}{appID, start, end}
+	if limit > 0 {
+		query += " LIMIT $4"
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grouped stats: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make([]*models.StatsGroup, 0)
+	for rows.Next() {
+		var group models.StatsGroup
+		if err := rows.Scan(&group.Key, &group.Requests, &group.UniqueSessions, &group.UniqueIPs); err != nil {
+			return nil, fmt.Errorf("failed to scan grouped stats: %w", err)
+		}
+		groups = append(groups, &group)
+	}
+
+	return groups, rows.Err()
+}
+
+// GetUniqueSketches 期間内のセッション・訪問者のスケッチを指定したキーのグループごとに作成
+// groupBy が空の場合は期間全体のスケッチを1つ（キーは空文字列）返し、keys は使わない
+// キーの式は GetGroupedStats と同じで、値のないグループは返さない。ロールアップのスケッチと合算するため、
+// 訪問者はロールアップと同じくIPアドレスの文字列で数える
+func (r *TrackingRepository) GetUniqueSketches(ctx context.Context, appID, groupBy string, keys []string, start, end time.Time) ([]*models.UniqueSketch, error) {
+	expr := `''`
+	args := []interface{}{appID, start, end}
+	condition := ""
+	if groupBy != "" {
+		var ok bool
+		if expr, ok = statsGroupExpressions[groupBy]; !ok {
+			return nil, fmt.Errorf("%w: %s", models.ErrStatisticsInvalidGroupBy, groupBy)
+		}
+		if len(keys) == 0 {
+			return make([]*models.UniqueSketch, 0), nil
+		}
+		condition = fmt.Sprintf(" AND %s = ANY($4)", expr)
+		args = append(args, pq.Array(keys))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT DISTINCT
+			%s as group_key,
+			COALESCE(session_id, '') as session_id,
+			COALESCE(host(ip_address), '') as ip_address
+		FROM access_logs 
+		WHERE app_id = $1 AND timestamp BETWEEN $2 AND $3%s
+	`, expr, condition)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unique sketches: %w", err)
+	}
+	defer rows.Close()
+
+	sketches := make([]*models.UniqueSketch, 0)
+	index := make(map[string]*models.UniqueSketch)
+	for rows.Next() {
+		var key, sessionID, ipAddress string
+		if err := rows.Scan(&key, &sessionID, &ipAddress); err != nil {
+			return nil, fmt.Errorf("failed to scan unique sketches: %w", err)
+		}
+
+		sketch, ok := index[key]
+		if !ok {
+			sketch = models.NewUniqueSketch(key)
+			index[key] = sketch
+			sketches = append(sketches, sketch)
+		}
+		if sessionID != "" {
+			sketch.Sessions.Add(sessionID)
+		}
+		if ipAddress != "" {
+			sketch.Visitors.Add(ipAddress)
+		}
+	}
+
+	return sketches, rows.Err()
+}
+
+// GetTimeSeries 期間内のページビュー・セッション・訪問者数を指定タイムゾーンの間隔ごとに集計
+// 値のないバケットは返さない。範囲は start 以上 end 未満
+func (r *TrackingRepository) GetTimeSeries(ctx context.Context, appID, interval string, loc *time.Location, start, end time.Time) ([]*models.TimeSeriesPoint, error) {
+	if !models.IsValidTimeSeriesInterval(interval) {
+		return nil, fmt.Errorf("%w: %s", models.ErrStatisticsInvalidInterval, interval)
+	}
+
+	query := `
+		SELECT 
+			date_trunc($2::text, timestamp AT TIME ZONE $3::text) as bucket,
+			COUNT(*) as page_views,
+			COUNT(DISTINCT session_id) as sessions,
+			COUNT(DISTINCT ip_address) as visitors
+		FROM access_logs 
+		WHERE app_id = $1 AND timestamp >= $4 AND timestamp < $5
+		GROUP BY bucket
+		ORDER BY bucket ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, appID, interval, loc.String(), start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time series: %w", err)
+	}
+	defer rows.Close()
+
+	points := make([]*models.TimeSeriesPoint, 0)
+	for rows.Next() {
+		var point models.TimeSeriesPoint
+		var bucket time.Time
+		if err := rows.Scan(&bucket, &point.PageViews, &point.Sessions, &point.Visitors); err != nil {
+			return nil, fmt.Errorf("failed to scan time series: %w", err)
+		}
+		// タイムゾーンなしの壁時計の時刻を指定タイムゾーンの時刻に戻す
+		point.Bucket = time.Date(bucket.Year(), bucket.Month(), bucket.Day(), bucket.Hour(), bucket.Minute(), 0, 0, loc)
+		points = append(points, &point)
+	}
+
+	return points, rows.Err()
+}
+
+// GetAverageSessionDuration 期間内のセッションの平均継続時間（秒）を取得
+// セッションの継続時間は最初と最後のイベントの時刻差とする
+func (r *TrackingRepository) GetAverageSessionDuration(ctx context.Context, appID string, start, end time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(AVG(duration), 0)
+		FROM (
+			SELECT EXTRACT(EPOCH FROM MAX(timestamp) - MIN(timestamp)) as duration
+			FROM access_logs 
+			WHERE app_id = $1 AND timestamp BETWEEN $2 AND $3
+				AND session_id IS NOT NULL AND session_id <> ''
+			GROUP BY session_id
+		) sessions
+	`
+
+	var duration float64
+	if err := r.db.QueryRowContext(ctx, query, appID, start, end).Scan(&duration); err != nil {
+		return 0, fmt.Errorf("failed to get average session duration: %w", err)
+	}
+
+	return duration, nil
+}
+
 // DeleteByAppID アプリケーションIDのトラッキングデータを削除
 func (r *TrackingRepository) DeleteByAppID(ctx context.Context, appID string) error {
 	query := `DELETE FROM access_logs WHERE app_id = $1`
@@ -205,6 +510,13 @@ func (r *TrackingRepository) scanTrackingData(rows *sql.Rows) (*models.TrackingD
 	err := rows.Scan(
 		&data.ID, &data.AppID, &data.UserAgent, &data.URL, &data.IPAddress, &data.SessionID, &data.Referrer,
 		&data.Timestamp, &customParamsJSON, &data.CreatedAt,
+		&data.Browser, &data.BrowserVersion, &data.OS, &data.OSVersion, &data.DeviceFamily, &data.DeviceType,
+		&data.Country, &data.Region, &data.City, &data.ASN,
+		&data.BotScore, &data.BotReason,
+		&data.ReferrerHost, &data.Channel,
+		&data.UTMSource, &data.UTMMedium, &data.UTMCampaign, &data.UTMTerm, &data.UTMContent,
+		&data.GCLID, &data.FBCLID,
+		&data.PagePath, &data.PageGroup,
 	)
 
 	if err != nil {
@@ -230,8 +542,7 @@ func (r *TrackingRepository) Create(ctx context.Context, data *models.TrackingDa
 // GetByID IDでトラッキングデータを取得
 func (r *TrackingRepository) GetByID(ctx context.Context, id string) (*models.TrackingData, error) {
 	query := `
-		SELECT id, app_id, user_agent, url, ip_address, session_id, referrer, 
-		       timestamp, custom_params, created_at
+		SELECT `+trackingSelectColumns+`
 		FROM access_logs 
 		WHERE id = $1
 	`
@@ -287,6 +598,13 @@ func (r *TrackingRepository) scanTrackingDataFromRow(row *sql.Row) (*models.Trac
 	err := row.Scan(
 		&data.ID, &data.AppID, &data.UserAgent, &data.URL, &data.IPAddress, &data.SessionID, &data.Referrer,
 		&data.Timestamp, &customParamsJSON, &data.CreatedAt,
+		&data.Browser, &data.BrowserVersion, &data.OS, &data.OSVersion, &data.DeviceFamily, &data.DeviceType,
+		&data.Country, &data.Region, &data.City, &data.ASN,
+		&data.BotScore, &data.BotReason,
+		&data.ReferrerHost, &data.Channel,
+		&data.UTMSource, &data.UTMMedium, &data.UTMCampaign, &data.UTMTerm, &data.UTMContent,
+		&data.GCLID, &data.FBCLID,
+		&data.PagePath, &data.PageGroup,
 	)
 
 	if err != nil {
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+// UsageRepository PostgreSQL用の使用量リポジトリ実装
+type UsageRepository struct {
+	db *sql.DB
+}
+
+// NewUsageRepository 新しい使用量リポジトリを作成
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{
+		db: db,
+	}
+}
+
+// GetUsage 集計期間のイベント数を取得（保存されていない場合は0）
+func (r *UsageRepository) GetUsage(ctx context.Context, appID string, period time.Time) (int64, error) {
+	var events int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT events FROM usage_counters WHERE app_id = $1 AND period = $2::date`,
+		appID, usagePeriodDate(period),
+	).Scan(&events)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query usage: %w", err)
+	}
+	return events, nil
+}
+
+// ListUsage 集計期間のアプリケーションごとのイベント数を取得
+func (r *UsageRepository) ListUsage(ctx context.Context, period time.Time) (map[string]int64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT app_id, events FROM usage_counters WHERE period = $1::date`,
+		usagePeriodDate(period),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[string]int64)
+	for rows.Next() {
+		var appID string
+		var events int64
+		if err := rows.Scan(&appID, &events); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usage[appID] = events
+	}
+
+	return usage, rows.Err()
+}
+
+// SaveUsage 集計期間のイベント数を保存（保存済みの値より小さい場合は更新しない）
+// アプリケーションが削除されている場合は models.ErrApplicationNotFound を返す
+func (r *UsageRepository) SaveUsage(ctx context.Context, appID string, period time.Time, events int64) error {
+	query := `
+		INSERT INTO usage_counters (app_id, period, events, updated_at)
+		VALUES ($1, $2::date, $3, $4)
+		ON CONFLICT (app_id, period) DO UPDATE
+		SET events = GREATEST(usage_counters.events, EXCLUDED.events), updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, appID, usagePeriodDate(period), events, time.Now()); err != nil {
+		if isForeignKeyViolation(err) {
+			return models.ErrApplicationNotFound
+		}
+		return fmt.Errorf("failed to save usage: %w", err)
+	}
+
+	return nil
+}
+
+// usagePeriodDate 集計期間をDATE型の文字列に変換（セッションのタイムゾーンで日付がずれないようにする）
+func usagePeriodDate(period time.Time) string {
+	return models.UsagePeriod(period).Format("2006-01-02")
+}
@@ -0,0 +1,296 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// パイプライン関連のエラー
+var (
+	ErrQueueFull      = errors.New("ingestion queue is full")
+	ErrPipelineClosed = errors.New("ingestion pipeline is closed")
+)
+
+// BatchWriter はトラッキングデータをまとめて書き込むインターフェースです
+type BatchWriter interface {
+	SaveBatch(ctx context.Context, data []*models.TrackingData) error
+}
+
+// Config はインジェストパイプラインの設定です
+type Config struct {
+	QueueSize     int           // キューの最大件数
+	Workers       int           // 書き込みワーカー数
+	BatchSize     int           // 1回の書き込みの最大件数
+	FlushInterval time.Duration // バッチが満たなくても書き込む間隔
+	WriteTimeout  time.Duration // 1バッチの書き込みタイムアウト
+	MaxRetries    int           // バッチの書き込みに失敗した場合の再試行回数（負の場合は再試行しない）
+	RetryBackoff  time.Duration // 最初の再試行までの待機時間（再試行ごとに2倍にする）
+}
+
+// DefaultConfig はデフォルトのパイプライン設定を返します
+func DefaultConfig() Config {
+	return Config{
+		QueueSize:     10000,
+		Workers:       4,
+		BatchSize:     500,
+		FlushInterval: time.Second,
+		WriteTimeout:  10 * time.Second,
+		MaxRetries:    3,
+		RetryBackoff:  200 * time.Millisecond,
+	}
+}
+
+// Stats はパイプラインの状態を表します
+type Stats struct {
+	QueueDepth    int   `json:"queue_depth"`
+	QueueCapacity int   `json:"queue_capacity"`
+	Workers       int   `json:"workers"`
+	Enqueued      int64 `json:"enqueued"`
+	Written       int64 `json:"written"`
+	Failed        int64 `json:"failed"`
+	Rejected      int64 `json:"rejected"`
+	Retried       int64 `json:"retried"`
+}
+
+// Saturated はキューが満杯かどうかを判定します
+func (s Stats) Saturated() bool {
+	return s.QueueCapacity > 0 && s.QueueDepth >= s.QueueCapacity
+}
+
+// Pipeline はトラッキングデータを非同期にバッチ書き込みするパイプラインです
+type Pipeline struct {
+	writer BatchWriter
+	config Config
+	logger logger.Logger
+
+	queue   chan *models.TrackingData
+	mu      sync.RWMutex
+	started bool
+	closed  bool
+	wg      sync.WaitGroup
+
+	enqueued int64
+	written  int64
+	failed   int64
+	rejected int64
+	retried  int64
+}
+
+// NewPipeline は新しいインジェストパイプラインを作成します
+func NewPipeline(writer BatchWriter, config Config, logger logger.Logger) *Pipeline {
+	defaults := DefaultConfig()
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaults.WriteTimeout
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaults.MaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaults.RetryBackoff
+	}
+
+	return &Pipeline{
+		writer: writer,
+		config: config,
+		logger: logger,
+		queue:  make(chan *models.TrackingData, config.QueueSize),
+	}
+}
+
+// Start は書き込みワーカーを起動します
+func (p *Pipeline) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.started || p.closed {
+		return
+	}
+	p.started = true
+
+	for i := 0; i < p.config.Workers; i++ {
+		p.wg.Add(1)
+		go p.worker()
+	}
+
+	p.logger.Info("Ingestion pipeline started",
+		"workers", p.config.Workers,
+		"queue_size", p.config.QueueSize,
+		"batch_size", p.config.BatchSize,
+		"flush_interval", p.config.FlushInterval.String())
+}
+
+// Enqueue はトラッキングデータをキューに追加します
+// キューが満杯の場合はブロックせずに ErrQueueFull を返します
+func (p *Pipeline) Enqueue(data *models.TrackingData) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrPipelineClosed
+	}
+
+	select {
+	case p.queue <- data:
+		atomic.AddInt64(&p.enqueued, 1)
+		return nil
+	default:
+		atomic.AddInt64(&p.rejected, 1)
+		return ErrQueueFull
+	}
+}
+
+// Stop は新規受付を停止し、キューに残ったデータを書き込んでからワーカーを終了します
+func (p *Pipeline) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	started := p.started
+	p.mu.Unlock()
+
+	// ワーカーが起動していない場合は呼び出し元で残りを書き込む
+	if !started {
+		p.wg.Add(1)
+		go p.worker()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.logger.Info("Ingestion pipeline drained",
+			"written", atomic.LoadInt64(&p.written),
+			"failed", atomic.LoadInt64(&p.failed))
+		return nil
+	case <-ctx.Done():
+		p.logger.Error("Ingestion pipeline drain timed out", "queue_depth", len(p.queue))
+		return ctx.Err()
+	}
+}
+
+// Stats はパイプラインの現在の状態を返します
+func (p *Pipeline) Stats() Stats {
+	return Stats{
+		QueueDepth:    len(p.queue),
+		QueueCapacity: cap(p.queue),
+		Workers:       p.config.Workers,
+		Enqueued:      atomic.LoadInt64(&p.enqueued),
+		Written:       atomic.LoadInt64(&p.written),
+		Failed:        atomic.LoadInt64(&p.failed),
+		Rejected:      atomic.LoadInt64(&p.rejected),
+		Retried:       atomic.LoadInt64(&p.retried),
+	}
+}
+
+// worker はキューからデータを取り出し、サイズまたは時間でバッチを書き込みます
+func (p *Pipeline) worker() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.TrackingData, 0, p.config.BatchSize)
+	for {
+		select {
+		case data, ok := <-p.queue:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+			batch = append(batch, data)
+			if len(batch) >= p.config.BatchSize {
+				p.flush(batch)
+				batch = make([]*models.TrackingData, 0, p.config.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.flush(batch)
+				batch = make([]*models.TrackingData, 0, p.config.BatchSize)
+			}
+		}
+	}
+}
+
+// flush はバッチを書き込みます
+// 書き込みに失敗した場合は間隔を空けて再試行し、それでも失敗した場合は1件ずつ書き込んで失敗した行だけを破棄します
+func (p *Pipeline) flush(batch []*models.TrackingData) {
+	if len(batch) == 0 {
+		return
+	}
+
+	backoff := p.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := p.save(batch)
+		if err == nil {
+			atomic.AddInt64(&p.written, int64(len(batch)))
+			return
+		}
+		if attempt >= p.config.MaxRetries {
+			p.logger.Error("Failed to write tracking batch, writing rows individually", "error", err.Error(), "size", len(batch), "attempts", attempt+1)
+			break
+		}
+
+		atomic.AddInt64(&p.retried, 1)
+		p.logger.Warn("Failed to write tracking batch, retrying", "error", err.Error(), "size", len(batch), "attempt", attempt+1, "backoff", backoff.String())
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	p.saveRows(batch)
+}
+
+// save は WriteTimeout の範囲でバッチを書き込みます
+func (p *Pipeline) save(batch []*models.TrackingData) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.WriteTimeout)
+	defer cancel()
+
+	return p.writer.SaveBatch(ctx, batch)
+}
+
+// saveRows はバッチを1件ずつ書き込み、書き込めなかった行を failed に計上します
+// 書き込みの合計時間は WriteTimeout までとし、時間内に書き込めなかった行も破棄します
+func (p *Pipeline) saveRows(batch []*models.TrackingData) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.WriteTimeout)
+	defer cancel()
+
+	var failed int64
+	for i, data := range batch {
+		if ctx.Err() != nil {
+			failed += int64(len(batch) - i)
+			p.logger.Error("Timed out writing tracking data individually", "dropped", len(batch)-i)
+			break
+		}
+		if err := p.writer.SaveBatch(ctx, []*models.TrackingData{data}); err != nil {
+			failed++
+			p.logger.Error("Failed to write tracking data", "error", err.Error(), "app_id", data.AppID, "tracking_id", data.ID)
+			continue
+		}
+		atomic.AddInt64(&p.written, 1)
+	}
+	atomic.AddInt64(&p.failed, failed)
+}
@@ -0,0 +1,234 @@
+package partition
+
+import (
+	"context"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// namePrefix はパーティション名の接頭辞です
+const namePrefix = "access_logs_p"
+
+// Store は access_logs のパーティションを操作するストアのインターフェースです
+type Store interface {
+	// IsPartitioned は access_logs がパーティションテーブルかどうかを返します
+	IsPartitioned(ctx context.Context) (bool, error)
+	// ListPartitions は接続されている範囲パーティションを範囲の開始順で返します
+	ListPartitions(ctx context.Context) ([]models.Partition, error)
+	// CreatePartition は範囲パーティションを作成します
+	CreatePartition(ctx context.Context, partition models.Partition) error
+	// DetachPartition はパーティションを切り離します（データは残ります）
+	DetachPartition(ctx context.Context, name string) error
+	// DropPartition はパーティションをデータごと削除します
+	DropPartition(ctx context.Context, name string) error
+}
+
+// Config はパーティション管理の設定です
+type Config struct {
+	Interval    time.Duration    // パーティションを確認する間隔
+	Granularity string           // パーティションの単位（month または day）
+	Premake     int              // 現在の期間に加えて事前に作成する期間の数
+	Retain      int              // 現在の期間に加えて残す過去の期間の数（0 の場合は切り離さない）
+	DropExpired bool             // 期限切れのパーティションを切り離さずに削除する
+	Clock       func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// DefaultConfig はデフォルトのパーティション管理設定を返します
+func DefaultConfig() Config {
+	return Config{
+		Interval:    time.Hour,
+		Granularity: models.PartitionGranularityMonth,
+		Premake:     3,
+	}
+}
+
+// Result は1回の実行で変更したパーティションです
+type Result struct {
+	Created  []string `json:"created"`
+	Detached []string `json:"detached"`
+	Dropped  []string `json:"dropped"`
+}
+
+// Manager は access_logs の将来のパーティションを事前に作成し、古いパーティションを切り離すワーカーです
+type Manager struct {
+	store  Store
+	config Config
+	logger logger.Logger
+}
+
+// NewManager は新しいパーティション管理ワーカーを作成します
+func NewManager(store Store, config Config, logger logger.Logger) *Manager {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if !models.IsValidPartitionGranularity(config.Granularity) {
+		config.Granularity = defaults.Granularity
+	}
+	if config.Premake < 0 {
+		config.Premake = defaults.Premake
+	}
+	if config.Retain < 0 {
+		config.Retain = 0
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	return &Manager{
+		store:  store,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run はコンテキストがキャンセルされるまで定期的に RunOnce を実行します
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			m.logger.Error("Partition maintenance failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Partition manager stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce は現在から Premake 期間先までのパーティションを作成し、保持期間を過ぎたパーティションを切り離します
+// access_logs がパーティション化されていない場合は何もしません
+func (m *Manager) RunOnce(ctx context.Context) (*Result, error) {
+	result := &Result{}
+
+	partitioned, err := m.store.IsPartitioned(ctx)
+	if err != nil {
+		return result, err
+	}
+	if !partitioned {
+		m.logger.Debug("access_logs is not partitioned, skipping partition maintenance")
+		return result, nil
+	}
+
+	existing, err := m.store.ListPartitions(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	current := Truncate(m.config.Clock(), m.config.Granularity)
+
+	// 将来のパーティションの作成（既存のパーティションと重なる範囲は作成しない）
+	end := current
+	for i := 0; i <= m.config.Premake; i++ {
+		end = Next(end, m.config.Granularity)
+	}
+	for _, partition := range Plan(existing, current, end, m.config.Granularity) {
+		if err := m.store.CreatePartition(ctx, partition); err != nil {
+			return result, err
+		}
+		result.Created = append(result.Created, partition.Name)
+		m.logger.Info("Partition created", "partition", partition.Name, "from", partition.From, "to", partition.To)
+	}
+
+	// 保持期間を過ぎたパーティションの切り離し・削除
+	if m.config.Retain > 0 {
+		cutoff := Previous(current, m.config.Granularity, m.config.Retain)
+		for _, partition := range existing {
+			if partition.To.After(cutoff) {
+				continue
+			}
+			if m.config.DropExpired {
+				if err := m.store.DropPartition(ctx, partition.Name); err != nil {
+					return result, err
+				}
+				result.Dropped = append(result.Dropped, partition.Name)
+				m.logger.Info("Partition dropped", "partition", partition.Name, "to", partition.To)
+			} else {
+				if err := m.store.DetachPartition(ctx, partition.Name); err != nil {
+					return result, err
+				}
+				result.Detached = append(result.Detached, partition.Name)
+				m.logger.Info("Partition detached", "partition", partition.Name, "to", partition.To)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Plan は [start, end) のうち既存のパーティションで覆われていない範囲を、単位ごとに区切ったパーティションとして返します
+// existing は範囲の開始順に並んでいる必要があります
+func Plan(existing []models.Partition, start, end time.Time, granularity string) []models.Partition {
+	planned := make([]models.Partition, 0)
+	addGap := func(from, to time.Time) {
+		for cur := from; cur.Before(to); {
+			next := Next(Truncate(cur, granularity), granularity)
+			if next.After(to) {
+				next = to
+			}
+			planned = append(planned, models.Partition{Name: Name(cur, next), From: cur, To: next})
+			cur = next
+		}
+	}
+
+	cur := start
+	for _, partition := range existing {
+		if !partition.To.After(cur) {
+			continue
+		}
+		if !partition.From.Before(end) {
+			break
+		}
+		if partition.From.After(cur) {
+			addGap(cur, partition.From)
+		}
+		cur = partition.To
+	}
+	if cur.Before(end) {
+		addGap(cur, end)
+	}
+
+	return planned
+}
+
+// Name はパーティション名を返します
+// 丸1か月の範囲は access_logs_pYYYYMM、それ以外は開始日で access_logs_pYYYYMMDD になります
+func Name(from, to time.Time) string {
+	from = from.UTC()
+	if from.Equal(Truncate(from, models.PartitionGranularityMonth)) && to.Equal(from.AddDate(0, 1, 0)) {
+		return namePrefix + from.Format("200601")
+	}
+	return namePrefix + from.Format("20060102")
+}
+
+// Truncate は時刻をUTCの期間の開始時刻に切り捨てます
+func Truncate(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	if granularity == models.PartitionGranularityMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Next は次の期間の開始時刻を返します
+func Next(start time.Time, granularity string) time.Time {
+	if granularity == models.PartitionGranularityMonth {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Previous は n 期間前の開始時刻を返します
+func Previous(start time.Time, granularity string, n int) time.Time {
+	if granularity == models.PartitionGranularityMonth {
+		return start.AddDate(0, -n, 0)
+	}
+	return start.AddDate(0, 0, -n)
+}
@@ -0,0 +1,215 @@
+package referrer
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// チャネル
+const (
+	ChannelDirect        = "direct"         // リファラーもキャンペーンのパラメータもない
+	ChannelOrganicSearch = "organic_search" // 検索エンジンからの自然検索
+	ChannelSocial        = "social"         // SNS
+	ChannelEmail         = "email"          // メール
+	ChannelPaid          = "paid"           // 広告（gclid・fbclid、または utm_medium が cpc など）
+	ChannelInternal      = "internal"       // 同じサイト内の遷移
+	ChannelOther         = "other"          // 上記以外のサイト・キャンペーン
+)
+
+// Channels はすべてのチャネルです
+var Channels = []string{ChannelDirect, ChannelOrganicSearch, ChannelSocial, ChannelEmail, ChannelPaid, ChannelInternal, ChannelOther}
+
+// MaxValueLength は保存するキャンペーンのパラメータ・ホストの最大バイト数です
+const MaxValueLength = 255
+
+// paidMediums は広告とみなす utm_medium です（GA4 のデフォルトチャネルグループに合わせる）
+var paidMediums = regexp.MustCompile(`^(?:.*cp.*|ppc|retargeting|paid.*|display|banner)$`)
+
+// Campaign はページURLのキャンペーンのパラメータです
+type Campaign struct {
+	Source  string // utm_source
+	Medium  string // utm_medium
+	Name    string // utm_campaign
+	Term    string // utm_term
+	Content string // utm_content
+	GCLID   string // gclid（Google 広告のクリックID）
+	FBCLID  string // fbclid（Meta 広告・リンクのクリックID）
+}
+
+// IsEmpty はキャンペーンのパラメータがないかどうかを判定します
+func (c Campaign) IsEmpty() bool {
+	return c == Campaign{}
+}
+
+// ParseCampaign はページURLのクエリからキャンペーンのパラメータを取り出します
+// パラメータ名は大文字・小文字を区別しません
+func ParseCampaign(pageURL string) Campaign {
+	u, err := url.Parse(pageURL)
+	if err != nil || u.RawQuery == "" {
+		return Campaign{}
+	}
+
+	var campaign Campaign
+	for key, values := range u.Query() {
+		if len(values) == 0 {
+			continue
+		}
+		value := truncate(strings.TrimSpace(values[0]))
+		switch strings.ToLower(key) {
+		case "utm_source":
+			campaign.Source = value
+		case "utm_medium":
+			campaign.Medium = value
+		case "utm_campaign":
+			campaign.Name = value
+		case "utm_term":
+			campaign.Term = value
+		case "utm_content":
+			campaign.Content = value
+		case "gclid":
+			campaign.GCLID = value
+		case "fbclid":
+			campaign.FBCLID = value
+		}
+	}
+	return campaign
+}
+
+// Host はURLのホスト名を小文字で返します（解析できない場合は空文字列）
+func Host(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return truncate(strings.ToLower(u.Hostname()))
+}
+
+// Result は流入元の判定結果です
+type Result struct {
+	Host     string   // リファラーのホスト（小文字）
+	Source   string   // 流入元の一覧で一致した名前（一致しない場合は空文字列）
+	Channel  string   // チャネル
+	Campaign Campaign // ページURLのキャンペーンのパラメータ
+}
+
+// Classifier は流入元の一覧でチャネルを判定します
+type Classifier struct {
+	sources *Sources
+}
+
+// New は新しい判定を作成します
+func New(sources *Sources) *Classifier {
+	return &Classifier{sources: sources}
+}
+
+// NewFromFile は流入元の一覧のファイルから判定を作成します（空の場合は組み込みの一覧）
+func NewFromFile(path string) (*Classifier, error) {
+	sources, err := LoadSources(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(sources), nil
+}
+
+// defaultClassifier は組み込みの一覧の判定です
+var defaultClassifier = &Classifier{}
+
+// Default は組み込みの流入元の一覧で判定する判定を返します
+func Default() *Classifier {
+	return defaultClassifier
+}
+
+// Classify はページURLとリファラーから流入元を判定します
+//
+// 判定の順序は次のとおりです
+//  1. gclid・fbclid、または広告の utm_medium（cpc, ppc, paid* など）は paid
+//  2. utm_medium が email・social・organic の場合はそれぞれ email・social・organic_search
+//  3. リファラーがない場合、キャンペーンのパラメータがあれば other、なければ direct
+//  4. リファラーのホストがページと同じサイトの場合は internal
+//  5. 流入元の一覧で一致したカテゴリ（search は organic_search）、一致しない場合は other
+func (c *Classifier) Classify(pageURL, referrerURL string) Result {
+	result := Result{
+		Host:     Host(referrerURL),
+		Campaign: ParseCampaign(pageURL),
+	}
+	source, found := c.lookup(result.Host)
+	if found {
+		result.Source = source.Name
+	}
+	result.Channel = c.channel(result, source, found, Host(pageURL))
+	return result
+}
+
+// lookup はホストの流入元を返します
+func (c *Classifier) lookup(host string) (Source, bool) {
+	sources := c.sources
+	if sources == nil {
+		sources = DefaultSources()
+	}
+	return sources.Lookup(host)
+}
+
+// channel はチャネルを判定します
+func (c *Classifier) channel(result Result, source Source, found bool, pageHost string) string {
+	campaign := result.Campaign
+	medium := strings.ToLower(campaign.Medium)
+	if campaign.GCLID != "" || campaign.FBCLID != "" || paidMediums.MatchString(medium) {
+		return ChannelPaid
+	}
+	switch medium {
+	case "email", "e-mail", "e_mail", "newsletter":
+		return ChannelEmail
+	case "social", "social-network", "social-media", "social_network", "social_media", "sm":
+		return ChannelSocial
+	case "organic":
+		return ChannelOrganicSearch
+	}
+
+	if result.Host == "" {
+		if !campaign.IsEmpty() {
+			return ChannelOther
+		}
+		return ChannelDirect
+	}
+	if sameSite(result.Host, pageHost) {
+		return ChannelInternal
+	}
+	if found {
+		switch source.Category {
+		case CategorySearch:
+			return ChannelOrganicSearch
+		case CategorySocial:
+			return ChannelSocial
+		case CategoryEmail:
+			return ChannelEmail
+		case CategoryPaid:
+			return ChannelPaid
+		}
+	}
+	return ChannelOther
+}
+
+// sameSite はリファラーのホストとページのホストが同じサイトかどうかを判定します（"www." の有無は問わない）
+func sameSite(referrerHost, pageHost string) bool {
+	if pageHost == "" {
+		return false
+	}
+	return strings.TrimPrefix(referrerHost, "www.") == strings.TrimPrefix(pageHost, "www.")
+}
+
+// truncate は値を MaxValueLength バイト以内に切り詰めます（UTF-8 の文字の途中では切らない）
+func truncate(value string) string {
+	if len(value) <= MaxValueLength {
+		return value
+	}
+	cut := MaxValueLength
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
@@ -0,0 +1,136 @@
+// Package referrer はリファラーとページURLから流入元のホスト・チャネル・キャンペーンを判定します
+package referrer
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultSources は組み込みの流入元の一覧です
+//
+//go:embed sources.yaml
+var defaultSources []byte
+
+// 流入元の一覧のカテゴリ
+const (
+	CategorySearch = "search"
+	CategorySocial = "social"
+	CategoryEmail  = "email"
+	CategoryPaid   = "paid"
+)
+
+// Source は流入元です
+type Source struct {
+	Name     string // 流入元の名前（Google, Facebook など）
+	Category string // search, social, email, paid のいずれか
+}
+
+// Sources は流入元の一覧です
+// ドメインはホストと一致するか、ホストがそのサブドメインの場合に一致し、より長いドメインを優先します
+type Sources struct {
+	exact    map[string]Source // "google.com" のようなドメイン
+	wildcard map[string]Source // "google." のようにすべてのトップレベルドメインに一致するドメイン
+}
+
+// sourceEntry は referer-parser の referers.yml 形式の流入元です
+type sourceEntry struct {
+	Domains []string `yaml:"domains"`
+}
+
+// ParseSources は referer-parser の referers.yml 形式（カテゴリ → 流入元の名前 → domains）の一覧を解析します
+// search, social, email, paid 以外のカテゴリは無視します
+func ParseSources(data []byte) (*Sources, error) {
+	var categories map[string]map[string]sourceEntry
+	if err := yaml.Unmarshal(data, &categories); err != nil {
+		return nil, fmt.Errorf("failed to parse referrer sources: %w", err)
+	}
+
+	sources := &Sources{exact: make(map[string]Source), wildcard: make(map[string]Source)}
+	for category, entries := range categories {
+		switch category {
+		case CategorySearch, CategorySocial, CategoryEmail, CategoryPaid:
+		default:
+			continue
+		}
+		for name, entry := range entries {
+			for _, domain := range entry.Domains {
+				domain = strings.ToLower(strings.TrimSpace(domain))
+				if domain == "" || domain == "." {
+					return nil, fmt.Errorf("invalid domain for referrer source %q", name)
+				}
+				source := Source{Name: name, Category: category}
+				if strings.HasSuffix(domain, ".") {
+					sources.wildcard[domain] = source
+				} else {
+					sources.exact[domain] = source
+				}
+			}
+		}
+	}
+	return sources, nil
+}
+
+// LoadSources は流入元の一覧のファイルを読み込みます（空の場合は組み込みの一覧）
+func LoadSources(path string) (*Sources, error) {
+	if path == "" {
+		return ParseSources(defaultSources)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read referrer sources: %w", err)
+	}
+	return ParseSources(data)
+}
+
+var (
+	defaultSourcesOnce sync.Once
+	defaultSourcesList *Sources
+)
+
+// DefaultSources は組み込みの流入元の一覧を返します
+// 組み込みの一覧が不正な場合は panic します
+func DefaultSources() *Sources {
+	defaultSourcesOnce.Do(func() {
+		sources, err := ParseSources(defaultSources)
+		if err != nil {
+			panic(fmt.Sprintf("referrer: invalid embedded sources: %v", err))
+		}
+		defaultSourcesList = sources
+	})
+	return defaultSourcesList
+}
+
+// Lookup はホストの流入元を返します
+// "mail.google.com" のようにサブドメインで別の流入元がある場合はそちらを優先します
+func (s *Sources) Lookup(host string) (Source, bool) {
+	if s == nil || host == "" {
+		return Source{}, false
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for suffix := host; suffix != ""; {
+		if source, ok := s.exact[suffix]; ok {
+			return source, true
+		}
+		for i := strings.IndexByte(suffix, '.'); i >= 0; {
+			if source, ok := s.wildcard[suffix[:i+1]]; ok {
+				return source, true
+			}
+			next := strings.IndexByte(suffix[i+1:], '.')
+			if next < 0 {
+				break
+			}
+			i += next + 1
+		}
+		_, rest, found := strings.Cut(suffix, ".")
+		if !found {
+			break
+		}
+		suffix = rest
+	}
+	return Source{}, false
+}
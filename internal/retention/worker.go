@@ -0,0 +1,174 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// Store はデータ保持期間の適用に使うストアのインターフェースです
+type Store interface {
+	// ListRetentionPolicies は保持期間が設定されているアプリケーションの保持期間を返します
+	ListRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error)
+	// DeleteExpiredEvents は before より古いトラッキングデータを最大 limit 件削除し、削除件数を返します
+	DeleteExpiredEvents(ctx context.Context, appID string, before time.Time, limit int) (int64, error)
+	// DeleteExpiredRollups は before より前のバケットのロールアップを最大 limit 件削除し、削除件数を返します
+	DeleteExpiredRollups(ctx context.Context, appID string, before time.Time, limit int) (int64, error)
+	// SaveRetentionRun はクリーンアップの実行結果を保存します
+	SaveRetentionRun(ctx context.Context, run *models.RetentionRun) error
+}
+
+// Config はクリーンアップワーカーの設定です
+type Config struct {
+	Interval         time.Duration    // クリーンアップを実行する間隔
+	BatchSize        int              // 1回のDELETEで削除する最大件数
+	MaxBatchesPerRun int              // 1回の実行でアプリケーション・テーブルごとに実行する最大DELETE回数
+	Clock            func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// DefaultConfig はデフォルトのクリーンアップワーカー設定を返します
+func DefaultConfig() Config {
+	return Config{
+		Interval:         time.Hour,
+		BatchSize:        5000,
+		MaxBatchesPerRun: 100,
+	}
+}
+
+// Worker はアプリケーションごとの保持期間を過ぎたデータを削除するワーカーです
+type Worker struct {
+	store  Store
+	config Config
+	logger logger.Logger
+}
+
+// NewWorker は新しいクリーンアップワーカーを作成します
+func NewWorker(store Store, config Config, logger logger.Logger) *Worker {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxBatchesPerRun <= 0 {
+		config.MaxBatchesPerRun = defaults.MaxBatchesPerRun
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	return &Worker{
+		store:  store,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run はコンテキストがキャンセルされるまで定期的に RunOnce を実行します
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("Retention cleanup failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Retention worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce は保持期間が設定されているアプリケーションごとに期限切れのデータを削除し、実行結果を返します
+// 1つのアプリケーションの失敗は結果に記録し、残りのアプリケーションの処理を続けます
+func (w *Worker) RunOnce(ctx context.Context) ([]*models.RetentionRun, error) {
+	policies, err := w.store.ListRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]*models.RetentionRun, 0, len(policies))
+	for _, policy := range policies {
+		if ctx.Err() != nil {
+			return runs, ctx.Err()
+		}
+
+		run := w.apply(ctx, policy)
+		runs = append(runs, run)
+
+		if run.Error != "" {
+			w.logger.Error("Retention cleanup failed for application",
+				"app_id", run.AppID, "events_deleted", run.EventsDeleted,
+				"rollups_deleted", run.RollupsDeleted, "duration", run.Duration.String(), "error", run.Error)
+		} else {
+			w.logger.Info("Retention cleanup completed",
+				"app_id", run.AppID, "events_deleted", run.EventsDeleted,
+				"rollups_deleted", run.RollupsDeleted, "duration", run.Duration.String())
+		}
+
+		if err := w.store.SaveRetentionRun(ctx, run); err != nil {
+			w.logger.Warn("Failed to save retention run", "app_id", run.AppID, "error", err.Error())
+		}
+	}
+
+	return runs, nil
+}
+
+// apply は1つのアプリケーションに保持期間を適用します
+func (w *Worker) apply(ctx context.Context, policy models.RetentionPolicy) *models.RetentionRun {
+	now := w.config.Clock()
+	run := &models.RetentionRun{AppID: policy.AppID, StartedAt: now}
+
+	var err error
+	if policy.RawDays > 0 {
+		cutoff := now.AddDate(0, 0, -policy.RawDays)
+		run.EventsDeleted, err = w.deleteInBatches(ctx, func(limit int) (int64, error) {
+			return w.store.DeleteExpiredEvents(ctx, policy.AppID, cutoff, limit)
+		})
+	}
+	if err == nil && policy.RollupDays > 0 {
+		// 日別のロールアップを途中で欠けさせないよう、UTCの日の区切りで削除する
+		cutoff := startOfUTCDay(now.AddDate(0, 0, -policy.RollupDays))
+		run.RollupsDeleted, err = w.deleteInBatches(ctx, func(limit int) (int64, error) {
+			return w.store.DeleteExpiredRollups(ctx, policy.AppID, cutoff, limit)
+		})
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	run.Duration = w.config.Clock().Sub(now)
+	return run
+}
+
+// deleteInBatches は削除件数が BatchSize 未満になるか最大回数に達するまで削除を繰り返し、合計削除件数を返します
+func (w *Worker) deleteInBatches(ctx context.Context, deleteBatch func(limit int) (int64, error)) (int64, error) {
+	var total int64
+	for i := 0; i < w.config.MaxBatchesPerRun; i++ {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		deleted, err := deleteBatch(w.config.BatchSize)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+		if deleted < int64(w.config.BatchSize) {
+			break
+		}
+	}
+	return total, nil
+}
+
+// startOfUTCDay はUTCの日の開始時刻を返します
+func startOfUTCDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,165 @@
+package rollup
+
+import (
+	"sort"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/hll"
+)
+
+// rollupKey は集計行を特定するキーです
+type rollupKey struct {
+	appID     string
+	dimension string
+	value     string
+}
+
+// rollupCounter は1つの集計行の途中経過です
+type rollupCounter struct {
+	pageViews int64
+	sessions  map[string]struct{}
+	visitors  map[string]struct{}
+}
+
+// sessionKey はアプリケーションごとのセッションを特定するキーです
+type sessionKey struct {
+	appID     string
+	sessionID string
+}
+
+// sessionSpan はバケット内のセッションの最初と最後のイベント時刻です
+type sessionSpan struct {
+	first time.Time
+	last  time.Time
+}
+
+// Aggregator は1つのバケットのトラッキングデータを集計軸ごとに集計します
+type Aggregator struct {
+	granularity string
+	bucket      time.Time
+	counters    map[rollupKey]*rollupCounter
+	spans       map[sessionKey]*sessionSpan
+}
+
+// NewAggregator は新しいアグリゲーターを作成します
+func NewAggregator(granularity string, bucket time.Time) *Aggregator {
+	return &Aggregator{
+		granularity: granularity,
+		bucket:      bucket,
+		counters:    make(map[rollupKey]*rollupCounter),
+		spans:       make(map[sessionKey]*sessionSpan),
+	}
+}
+
+// Add はトラッキングデータを集計に加えます
+func (a *Aggregator) Add(data *models.TrackingData) error {
+	a.count(data, models.RollupDimensionTotal, "")
+	if page := data.GetPagePath(); page != "" {
+		a.count(data, models.RollupDimensionURL, page)
+	}
+	if data.PageGroup != "" {
+		a.count(data, models.RollupDimensionPageGroup, data.PageGroup)
+	}
+	if host := data.GetReferrerHost(); host != "" {
+		a.count(data, models.RollupDimensionReferrerHost, host)
+	}
+	a.count(data, models.RollupDimensionDeviceType, data.GetDeviceType())
+	a.count(data, models.RollupDimensionBrowser, data.GetBrowser())
+	a.count(data, models.RollupDimensionOS, data.GetOS())
+	if data.Country != "" {
+		a.count(data, models.RollupDimensionCountry, data.Country)
+	}
+	a.count(data, models.RollupDimensionChannel, data.GetChannel())
+	if campaign := data.GetCampaign(); campaign != "" {
+		a.count(data, models.RollupDimensionCampaign, campaign)
+	}
+
+	if data.SessionID != "" {
+		key := sessionKey{appID: data.AppID, sessionID: data.SessionID}
+		span, ok := a.spans[key]
+		if !ok {
+			a.spans[key] = &sessionSpan{first: data.Timestamp, last: data.Timestamp}
+		} else {
+			if data.Timestamp.Before(span.first) {
+				span.first = data.Timestamp
+			}
+			if data.Timestamp.After(span.last) {
+				span.last = data.Timestamp
+			}
+		}
+	}
+	return nil
+}
+
+// count は1つの集計軸の値にイベントを加えます
+func (a *Aggregator) count(data *models.TrackingData, dimension, value string) {
+	key := rollupKey{appID: data.AppID, dimension: dimension, value: value}
+	counter, ok := a.counters[key]
+	if !ok {
+		counter = &rollupCounter{
+			sessions: make(map[string]struct{}),
+			visitors: make(map[string]struct{}),
+		}
+		a.counters[key] = counter
+	}
+
+	counter.pageViews++
+	if data.SessionID != "" {
+		counter.sessions[data.SessionID] = struct{}{}
+	}
+	if data.IPAddress != "" {
+		counter.visitors[data.IPAddress] = struct{}{}
+	}
+}
+
+// Rows は集計結果をアプリケーション・集計軸・値の順に並べて返します
+func (a *Aggregator) Rows() []*models.RollupRow {
+	sessionSeconds := make(map[string]float64)
+	for key, span := range a.spans {
+		sessionSeconds[key.appID] += span.last.Sub(span.first).Seconds()
+	}
+
+	rows := make([]*models.RollupRow, 0, len(a.counters))
+	for key, counter := range a.counters {
+		row := &models.RollupRow{
+			AppID:       key.appID,
+			Granularity: a.granularity,
+			Bucket:      a.bucket,
+			Dimension:   key.dimension,
+			Value:       key.value,
+			PageViews:   counter.pageViews,
+			Sessions:    int64(len(counter.sessions)),
+			Visitors:    int64(len(counter.visitors)),
+
+			SessionSketch: encodeSketch(counter.sessions),
+			VisitorSketch: encodeSketch(counter.visitors),
+		}
+		if key.dimension == models.RollupDimensionTotal {
+			row.SessionSeconds = sessionSeconds[key.appID]
+		}
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].AppID != rows[j].AppID {
+			return rows[i].AppID < rows[j].AppID
+		}
+		if rows[i].Dimension != rows[j].Dimension {
+			return rows[i].Dimension < rows[j].Dimension
+		}
+		return rows[i].Value < rows[j].Value
+	})
+	return rows
+}
+
+// encodeSketch はバケット内のユニークな値をスケッチにしてエンコードします
+// 統計APIは保存したスケッチを合算し、バケットをまたぐ期間のユニーク数を推定します
+func encodeSketch(values map[string]struct{}) []byte {
+	sketch := hll.New()
+	for value := range values {
+		sketch.Add(value)
+	}
+	data, _ := sketch.MarshalBinary()
+	return data
+}
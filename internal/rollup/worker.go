@@ -0,0 +1,189 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// ErrInvalidRange はバックフィルの範囲が不正な場合のエラーです
+var ErrInvalidRange = errors.New("invalid rollup range")
+
+// granularities はワーカーが維持する集計粒度です（時間単位を先に処理します）
+var granularities = []string{models.RollupGranularityHour, models.RollupGranularityDay}
+
+// Store はロールアップの読み書きに使うストアのインターフェースです
+type Store interface {
+	// GetWatermark は集計済みの終端を返します（未集計の場合はゼロ値）
+	GetWatermark(ctx context.Context, granularity string) (time.Time, error)
+	// GetEarliestEventTime は最も古いトラッキングデータの時刻を返します（データがない場合はゼロ値）
+	GetEarliestEventTime(ctx context.Context) (time.Time, error)
+	// ScanEvents は start 以上 end 未満のトラッキングデータを順に fn に渡します
+	ScanEvents(ctx context.Context, start, end time.Time, fn func(*models.TrackingData) error) error
+	// ReplaceRollups はバケットの集計結果を置き換え、advance が true の場合は集計済みの終端をバケットの終わりまで進めます
+	ReplaceRollups(ctx context.Context, granularity string, bucket time.Time, rows []*models.RollupRow, advance bool) error
+}
+
+// Config はロールアップワーカーの設定です
+type Config struct {
+	Interval         time.Duration    // 未集計のバケットを確認する間隔
+	Delay            time.Duration    // バケットの終わりから集計までの猶予（遅れて届くデータを待つ）
+	MaxBucketsPerRun int              // 1回の実行で集計する粒度ごとの最大バケット数
+	Clock            func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// DefaultConfig はデフォルトのロールアップワーカー設定を返します
+func DefaultConfig() Config {
+	return Config{
+		Interval:         time.Minute,
+		Delay:            5 * time.Minute,
+		MaxBucketsPerRun: 24,
+	}
+}
+
+// Worker は access_logs から時間別・日別のロールアップを増分で作成するワーカーです
+type Worker struct {
+	store  Store
+	config Config
+	logger logger.Logger
+}
+
+// NewWorker は新しいロールアップワーカーを作成します
+func NewWorker(store Store, config Config, logger logger.Logger) *Worker {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Delay < 0 {
+		config.Delay = defaults.Delay
+	}
+	if config.MaxBucketsPerRun <= 0 {
+		config.MaxBucketsPerRun = defaults.MaxBucketsPerRun
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	return &Worker{
+		store:  store,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run はコンテキストがキャンセルされるまで定期的に RunOnce を実行します
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("Rollup failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Rollup worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce は集計済みの終端以降で確定したバケットを集計し、集計したバケット数を返します
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	total := 0
+	for _, granularity := range granularities {
+		processed, err := w.advance(ctx, granularity)
+		total += processed
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// advance は1つの集計粒度について集計済みの終端を進めます
+func (w *Worker) advance(ctx context.Context, granularity string) (int, error) {
+	watermark, err := w.store.GetWatermark(ctx, granularity)
+	if err != nil {
+		return 0, err
+	}
+
+	// 初回は最も古いデータのバケットから開始
+	if watermark.IsZero() {
+		earliest, err := w.store.GetEarliestEventTime(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if earliest.IsZero() {
+			return 0, nil
+		}
+		watermark = Truncate(earliest, granularity)
+	}
+
+	// 終わりから猶予が過ぎたバケットだけを確定として集計
+	settled := w.config.Clock().Add(-w.config.Delay)
+	processed := 0
+	for bucket := watermark; !Next(bucket, granularity).After(settled) && processed < w.config.MaxBucketsPerRun; bucket = Next(bucket, granularity) {
+		if err := w.rollupBucket(ctx, granularity, bucket, true); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+
+	if processed > 0 {
+		w.logger.Debug("Rollup advanced", "granularity", granularity, "buckets", processed)
+	}
+	return processed, nil
+}
+
+// Backfill は from 以上 to 未満に含まれるバケットを再集計します
+// 集計済みの終端は変更しないため、何度実行しても同じ結果になります
+func (w *Worker) Backfill(ctx context.Context, from, to time.Time) (int, error) {
+	if !from.Before(to) {
+		return 0, ErrInvalidRange
+	}
+
+	total := 0
+	for _, granularity := range granularities {
+		for bucket := Truncate(from, granularity); bucket.Before(to); bucket = Next(bucket, granularity) {
+			if err := w.rollupBucket(ctx, granularity, bucket, false); err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+
+	w.logger.Info("Rollup backfill completed", "from", from, "to", to, "buckets", total)
+	return total, nil
+}
+
+// rollupBucket は1つのバケットを集計して保存します
+func (w *Worker) rollupBucket(ctx context.Context, granularity string, bucket time.Time, advance bool) error {
+	aggregator := NewAggregator(granularity, bucket)
+	if err := w.store.ScanEvents(ctx, bucket, Next(bucket, granularity), aggregator.Add); err != nil {
+		return err
+	}
+	return w.store.ReplaceRollups(ctx, granularity, bucket, aggregator.Rows(), advance)
+}
+
+// Truncate は時刻をUTCのバケットの開始時刻に切り捨てます
+func Truncate(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	if granularity == models.RollupGranularityDay {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+// Next は次のバケットの開始時刻を返します
+func Next(bucket time.Time, granularity string) time.Time {
+	if granularity == models.RollupGranularityDay {
+		return bucket.AddDate(0, 0, 1)
+	}
+	return bucket.Add(time.Hour)
+}
@@ -0,0 +1,290 @@
+package uaparser
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"accesslog-tracker/internal/utils/lru"
+)
+
+// Other はルールに一致しなかった場合のファミリーです（uap-coreと同じ）
+const Other = "Other"
+
+// デバイスタイプ
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeBot     = "bot"
+)
+
+// DefaultCacheSize は解析結果をキャッシュするユーザーエージェント数のデフォルト値です
+const DefaultCacheSize = 10000
+
+// UserAgent はブラウザのファミリーとバージョンです
+type UserAgent struct {
+	Family string `json:"family"`
+	Major  string `json:"major,omitempty"`
+	Minor  string `json:"minor,omitempty"`
+	Patch  string `json:"patch,omitempty"`
+}
+
+// Version はバージョンをドット区切りで返します（バージョンがない場合は空文字列）
+func (u UserAgent) Version() string {
+	return joinVersion(u.Major, u.Minor, u.Patch)
+}
+
+// OS はOSのファミリーとバージョンです
+type OS struct {
+	Family string `json:"family"`
+	Major  string `json:"major,omitempty"`
+	Minor  string `json:"minor,omitempty"`
+	Patch  string `json:"patch,omitempty"`
+}
+
+// Version はバージョンをドット区切りで返します（バージョンがない場合は空文字列）
+func (o OS) Version() string {
+	return joinVersion(o.Major, o.Minor, o.Patch)
+}
+
+// Device はデバイスのファミリー・ブランド・モデルです
+type Device struct {
+	Family string `json:"family"`
+	Brand  string `json:"brand,omitempty"`
+	Model  string `json:"model,omitempty"`
+	// Type は desktop, mobile, tablet, bot のいずれかです
+	Type string `json:"type"`
+}
+
+// Client はユーザーエージェントの解析結果です
+type Client struct {
+	UserAgent UserAgent `json:"user_agent"`
+	OS        OS        `json:"os"`
+	Device    Device    `json:"device"`
+}
+
+// userAgentParser はコンパイル済みのブラウザのルールです
+type userAgentParser struct {
+	re   *regexp.Regexp
+	rule UserAgentRule
+}
+
+// osParser はコンパイル済みのOSのルールです
+type osParser struct {
+	re   *regexp.Regexp
+	rule OSRule
+}
+
+// deviceParser はコンパイル済みのデバイスのルールです
+type deviceParser struct {
+	re   *regexp.Regexp
+	rule DeviceRule
+}
+
+// Parser はユーザーエージェントを解析します
+// 同じユーザーエージェントは繰り返し現れるため、解析結果をLRUでキャッシュします
+type Parser struct {
+	userAgents []userAgentParser
+	oses       []osParser
+	devices    []deviceParser
+	cache      *lru.Cache[string, Client]
+}
+
+// New はルールから新しいパーサーを作成します
+// cacheSize が 0 以下の場合は DefaultCacheSize を使います
+func New(rules *Rules, cacheSize int) (*Parser, error) {
+	if cacheSize <= 0 {
+		cacheSize = DefaultCacheSize
+	}
+	p := &Parser{
+		cache: lru.New[string, Client](lru.Config{Size: cacheSize}),
+	}
+
+	for i, rule := range rules.UserAgentParsers {
+		re, err := compile("user agent", i, rule.Regex, rule.RegexFlag)
+		if err != nil {
+			return nil, err
+		}
+		p.userAgents = append(p.userAgents, userAgentParser{re: re, rule: rule})
+	}
+	for i, rule := range rules.OSParsers {
+		re, err := compile("os", i, rule.Regex, rule.RegexFlag)
+		if err != nil {
+			return nil, err
+		}
+		p.oses = append(p.oses, osParser{re: re, rule: rule})
+	}
+	for i, rule := range rules.DeviceParsers {
+		re, err := compile("device", i, rule.Regex, rule.RegexFlag)
+		if err != nil {
+			return nil, err
+		}
+		p.devices = append(p.devices, deviceParser{re: re, rule: rule})
+	}
+
+	return p, nil
+}
+
+// NewFromFile はルールファイルから新しいパーサーを作成します
+// path が空の場合は組み込みのルールを使います
+func NewFromFile(path string, cacheSize int) (*Parser, error) {
+	if path == "" {
+		rules, err := DefaultRules()
+		if err != nil {
+			return nil, err
+		}
+		return New(rules, cacheSize)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(rules, cacheSize)
+}
+
+var (
+	defaultParser     *Parser
+	defaultParserOnce sync.Once
+)
+
+// Default は組み込みのルールのパーサーを返します
+func Default() *Parser {
+	defaultParserOnce.Do(func() {
+		parser, err := NewFromFile("", DefaultCacheSize)
+		if err != nil {
+			// 組み込みのルールはテストで検証しているため、ここでは起こらない
+			panic(err)
+		}
+		defaultParser = parser
+	})
+	return defaultParser
+}
+
+// Parse はユーザーエージェントを解析します
+func (p *Parser) Parse(userAgent string) Client {
+	if client, ok := p.cache.Get(userAgent); ok {
+		return client
+	}
+
+	client := Client{
+		UserAgent: p.parseUserAgent(userAgent),
+		OS:        p.parseOS(userAgent),
+		Device:    p.parseDevice(userAgent),
+	}
+	client.Device.Type = deviceType(userAgent, client)
+
+	p.cache.Add(userAgent, client)
+	return client
+}
+
+// parseUserAgent はブラウザを判定します
+func (p *Parser) parseUserAgent(userAgent string) UserAgent {
+	for _, parser := range p.userAgents {
+		match := parser.re.FindStringSubmatch(userAgent)
+		if match == nil {
+			continue
+		}
+		result := UserAgent{
+			Family: replace(parser.rule.FamilyReplacement, match, 1),
+			Major:  replace(parser.rule.V1Replacement, match, 2),
+			Minor:  replace(parser.rule.V2Replacement, match, 3),
+			Patch:  replace(parser.rule.V3Replacement, match, 4),
+		}
+		if result.Family == "" {
+			result.Family = Other
+		}
+		return result
+	}
+	return UserAgent{Family: Other}
+}
+
+// parseOS はOSを判定します
+func (p *Parser) parseOS(userAgent string) OS {
+	for _, parser := range p.oses {
+		match := parser.re.FindStringSubmatch(userAgent)
+		if match == nil {
+			continue
+		}
+		result := OS{
+			Family: replace(parser.rule.OSReplacement, match, 1),
+			Major:  replace(parser.rule.OSV1Replacement, match, 2),
+			Minor:  replace(parser.rule.OSV2Replacement, match, 3),
+			Patch:  replace(parser.rule.OSV3Replacement, match, 4),
+		}
+		if result.Family == "" {
+			result.Family = Other
+		}
+		return result
+	}
+	return OS{Family: Other}
+}
+
+// parseDevice はデバイスを判定します
+// モデルの置換文字列がない場合は、uap-coreと同じく1番目のキャプチャグループをモデルとします
+// ブランドは置換文字列がある場合のみ設定します
+func (p *Parser) parseDevice(userAgent string) Device {
+	for _, parser := range p.devices {
+		match := parser.re.FindStringSubmatch(userAgent)
+		if match == nil {
+			continue
+		}
+		result := Device{
+			Family: replace(parser.rule.DeviceReplacement, match, 1),
+			Model:  replace(parser.rule.ModelReplacement, match, 1),
+		}
+		if parser.rule.BrandReplacement != "" {
+			result.Brand = replace(parser.rule.BrandReplacement, match, 0)
+		}
+		if result.Family == "" {
+			result.Family = Other
+		}
+		return result
+	}
+	return Device{Family: Other}
+}
+
+// mobileOSFamilies はモバイル端末のOSです
+var mobileOSFamilies = map[string]bool{
+	"iOS":           true,
+	"Android":       true,
+	"Windows Phone": true,
+	"BlackBerry OS": true,
+	"KaiOS":         true,
+}
+
+// deviceType は解析結果からデバイスタイプを判定します
+// uap-coreのルールはデバイスタイプを持たないため、デバイスとOSのファミリーから判定します
+// Androidは "Mobile" トークンのないユーザーエージェントをタブレットとします（Googleの推奨する判定方法）
+func deviceType(userAgent string, client Client) string {
+	family := client.Device.Family
+	switch {
+	case family == "Spider":
+		return DeviceTypeBot
+	case strings.Contains(family, "iPad"), strings.Contains(family, "Tablet"), strings.Contains(family, "Kindle"):
+		return DeviceTypeTablet
+	case client.OS.Family == "Android" && !strings.Contains(userAgent, "Mobile"):
+		return DeviceTypeTablet
+	case mobileOSFamilies[client.OS.Family]:
+		return DeviceTypeMobile
+	case family != Other && family != "Mac":
+		return DeviceTypeMobile
+	}
+	return DeviceTypeDesktop
+}
+
+// joinVersion は空でないバージョンの要素をドットでつなぎます
+func joinVersion(parts ...string) string {
+	version := ""
+	for _, part := range parts {
+		if part == "" {
+			break
+		}
+		if version != "" {
+			version += "."
+		}
+		version += part
+	}
+	return version
+}
@@ -0,0 +1,126 @@
+// Package uaparser はuap-core互換のYAMLの正規表現ルールでユーザーエージェントを解析します
+package uaparser
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRules は組み込みのルールファイルです
+//
+//go:embed regexes.yaml
+var defaultRules []byte
+
+// Rules はuap-core形式のルールファイルを表します
+// 各パーサーは上から順に評価し、最初に一致したルールの結果を使います
+type Rules struct {
+	UserAgentParsers []UserAgentRule `yaml:"user_agent_parsers"`
+	OSParsers        []OSRule        `yaml:"os_parsers"`
+	DeviceParsers    []DeviceRule    `yaml:"device_parsers"`
+}
+
+// UserAgentRule はブラウザを判定するルールです
+// 置換文字列がない場合はキャプチャグループの1番目をファミリー、2〜4番目をバージョンとします
+type UserAgentRule struct {
+	Regex             string `yaml:"regex"`
+	RegexFlag         string `yaml:"regex_flag"`
+	FamilyReplacement string `yaml:"family_replacement"`
+	V1Replacement     string `yaml:"v1_replacement"`
+	V2Replacement     string `yaml:"v2_replacement"`
+	V3Replacement     string `yaml:"v3_replacement"`
+}
+
+// OSRule はOSを判定するルールです
+type OSRule struct {
+	Regex           string `yaml:"regex"`
+	RegexFlag       string `yaml:"regex_flag"`
+	OSReplacement   string `yaml:"os_replacement"`
+	OSV1Replacement string `yaml:"os_v1_replacement"`
+	OSV2Replacement string `yaml:"os_v2_replacement"`
+	OSV3Replacement string `yaml:"os_v3_replacement"`
+}
+
+// DeviceRule はデバイスを判定するルールです
+type DeviceRule struct {
+	Regex             string `yaml:"regex"`
+	RegexFlag         string `yaml:"regex_flag"`
+	DeviceReplacement string `yaml:"device_replacement"`
+	BrandReplacement  string `yaml:"brand_replacement"`
+	ModelReplacement  string `yaml:"model_replacement"`
+}
+
+// DefaultRules は組み込みのルールを返します
+func DefaultRules() (*Rules, error) {
+	return ParseRules(defaultRules)
+}
+
+// LoadRules はルールファイルを読み込みます
+func LoadRules(path string) (*Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open user agent rules: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user agent rules: %w", err)
+	}
+	return ParseRules(data)
+}
+
+// ParseRules はYAMLのルールを解析します
+func ParseRules(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse user agent rules: %w", err)
+	}
+	return &rules, nil
+}
+
+// compile はルールの正規表現をコンパイルします
+// regex_flag が "i" の場合は大文字・小文字を区別しません
+func compile(kind string, index int, pattern, flag string) (*regexp.Regexp, error) {
+	if flag == "i" {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s rule %d: %w", kind, index, err)
+	}
+	return re, nil
+}
+
+// replace は置換文字列の $1〜$9 をキャプチャグループで置き換えます
+// 置換文字列がない場合は group 番目のキャプチャグループを返します
+func replace(replacement string, match []string, group int) string {
+	if replacement == "" {
+		if group < len(match) {
+			return strings.TrimSpace(match[group])
+		}
+		return ""
+	}
+	if !strings.Contains(replacement, "$") {
+		return replacement
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		if c == '$' && i+1 < len(replacement) && replacement[i+1] >= '1' && replacement[i+1] <= '9' {
+			if n := int(replacement[i+1] - '0'); n < len(match) {
+				b.WriteString(match[n])
+			}
+			i++
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return strings.TrimSpace(b.String())
+}
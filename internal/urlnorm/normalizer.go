@@ -0,0 +1,248 @@
+// Package urlnorm はページURLを正規化し、パスのパターンでページをグループ化します
+package urlnorm
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// 末尾のスラッシュの扱い
+const (
+	TrailingSlashKeep  = "keep"  // そのままにする（デフォルト）
+	TrailingSlashStrip = "strip" // ルート以外の末尾のスラッシュを取り除く
+	TrailingSlashAdd   = "add"   // 末尾にスラッシュを付ける（最後のセグメントに拡張子がある場合を除く）
+)
+
+// MaxPathGroups は設定できるパスのパターン数の上限です
+const MaxPathGroups = 100
+
+// MaxQueryAllowlist は設定できる残すクエリパラメータ数の上限です
+const MaxQueryAllowlist = 100
+
+// Rules はページURLの正規化のルールです
+type Rules struct {
+	StripQuery     bool     `json:"strip_query"`     // クエリパラメータを取り除く
+	QueryAllowlist []string `json:"query_allowlist"` // StripQuery の場合も残すクエリパラメータ（大文字・小文字は区別しない）
+	DropFragment   bool     `json:"drop_fragment"`   // フラグメント（#以降）を取り除く
+	LowercaseHost  bool     `json:"lowercase_host"`  // ホストを小文字にする
+	TrailingSlash  string   `json:"trailing_slash"`  // 末尾のスラッシュの扱い（keep, strip, add）
+	PathGroups     []string `json:"path_groups"`     // "/product/:id" のようなパスのパターン（先に一致したものを使う）
+}
+
+// DefaultRules はアプリケーションにルールが設定されていない場合のルールです
+// フラグメントを取り除き、ホストを小文字にします。クエリパラメータと末尾のスラッシュはそのままにします
+func DefaultRules() Rules {
+	return Rules{
+		DropFragment:  true,
+		LowercaseHost: true,
+		TrailingSlash: TrailingSlashKeep,
+	}
+}
+
+// IsValidTrailingSlash は末尾のスラッシュの扱いが有効かどうかを判定します
+func IsValidTrailingSlash(policy string) bool {
+	switch policy {
+	case TrailingSlashKeep, TrailingSlashStrip, TrailingSlashAdd:
+		return true
+	}
+	return false
+}
+
+// Result はページURLの正規化の結果です
+type Result struct {
+	PagePath  string `json:"page_path"`  // 正規化したページURL
+	PageGroup string `json:"page_group"` // 一致したパスのパターン（一致しない場合は正規化したパス）
+}
+
+// Normalizer はルールでページURLを正規化します
+type Normalizer struct {
+	rules     Rules
+	allowlist map[string]struct{}
+	groups    []pathPattern
+}
+
+// pathPattern はパスのパターンをセグメントに分けたものです
+type pathPattern struct {
+	pattern  string
+	segments []string
+}
+
+// New はルールを検証して新しい正規化を作成します
+func New(rules Rules) (*Normalizer, error) {
+	if rules.TrailingSlash == "" {
+		rules.TrailingSlash = TrailingSlashKeep
+	}
+	if !IsValidTrailingSlash(rules.TrailingSlash) {
+		return nil, fmt.Errorf("trailing_slash must be %q, %q or %q", TrailingSlashKeep, TrailingSlashStrip, TrailingSlashAdd)
+	}
+	if len(rules.QueryAllowlist) > MaxQueryAllowlist {
+		return nil, fmt.Errorf("query_allowlist must not contain more than %d parameters", MaxQueryAllowlist)
+	}
+	if len(rules.PathGroups) > MaxPathGroups {
+		return nil, fmt.Errorf("path_groups must not contain more than %d patterns", MaxPathGroups)
+	}
+
+	n := &Normalizer{rules: rules, allowlist: make(map[string]struct{}, len(rules.QueryAllowlist))}
+	for _, name := range rules.QueryAllowlist {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("query_allowlist must not contain empty parameters")
+		}
+		n.allowlist[strings.ToLower(name)] = struct{}{}
+	}
+	for _, pattern := range rules.PathGroups {
+		group, err := parsePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		n.groups = append(n.groups, group)
+	}
+	return n, nil
+}
+
+var defaultNormalizer, _ = New(DefaultRules())
+
+// Default は DefaultRules で正規化する正規化を返します
+func Default() *Normalizer {
+	return defaultNormalizer
+}
+
+// Rules は正規化のルールを返します
+func (n *Normalizer) Rules() Rules {
+	return n.rules
+}
+
+// Normalize はページURLを正規化し、パスのパターンでグループ化します
+// 解析できないURLはそのままページURLとし、グループは空文字列にします
+func (n *Normalizer) Normalize(rawURL string) Result {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Result{PagePath: rawURL}
+	}
+
+	if n.rules.LowercaseHost {
+		u.Host = strings.ToLower(u.Host)
+	}
+	if n.rules.DropFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+	if n.rules.StripQuery {
+		u.RawQuery = n.filterQuery(u.Query())
+		u.ForceQuery = false
+	}
+
+	path := n.applyTrailingSlash(u.Path, u.Host != "")
+	if path != u.Path {
+		u.Path, u.RawPath = path, ""
+	}
+
+	return Result{PagePath: u.String(), PageGroup: n.group(u.Path)}
+}
+
+// filterQuery は残すクエリパラメータだけをキーの順に並べたクエリ文字列を返します
+func (n *Normalizer) filterQuery(query url.Values) string {
+	for key := range query {
+		if _, ok := n.allowlist[strings.ToLower(key)]; !ok {
+			delete(query, key)
+		}
+	}
+	return query.Encode()
+}
+
+// applyTrailingSlash は末尾のスラッシュの扱いをパスに適用します
+// ホストを含むURLの空のパスはルート（/）とします
+func (n *Normalizer) applyTrailingSlash(path string, hasHost bool) string {
+	if path == "" {
+		if hasHost {
+			return "/"
+		}
+		return path
+	}
+	switch n.rules.TrailingSlash {
+	case TrailingSlashStrip:
+		if trimmed := strings.TrimRight(path, "/"); trimmed != "" {
+			return trimmed
+		}
+		return "/"
+	case TrailingSlashAdd:
+		if strings.HasSuffix(path, "/") {
+			return path
+		}
+		last := path[strings.LastIndex(path, "/")+1:]
+		if strings.Contains(last, ".") {
+			return path
+		}
+		return path + "/"
+	}
+	return path
+}
+
+// group はパスに一致する最初のパターンを返します（一致しない場合はパス）
+func (n *Normalizer) group(path string) string {
+	if len(n.groups) > 0 {
+		segments := splitPath(path)
+		for _, group := range n.groups {
+			if group.match(segments) {
+				return group.pattern
+			}
+		}
+	}
+	return path
+}
+
+// parsePathPattern はパスのパターンを解析します
+//
+// パターンは "/" で始まり、セグメントは次のいずれかです
+//   - "product" のような固定の文字列（大文字・小文字を区別する）
+//   - ":id" のような名前付きのパラメータ（空でない1つのセグメントに一致）
+//   - "*"（最後のセグメントにのみ指定でき、1つ以上の残りのセグメントに一致）
+func parsePathPattern(pattern string) (pathPattern, error) {
+	if !strings.HasPrefix(pattern, "/") || strings.ContainsAny(pattern, "?# \t") {
+		return pathPattern{}, fmt.Errorf("path_groups contains invalid pattern %q", pattern)
+	}
+	segments := splitPath(pattern)
+	for i, segment := range segments {
+		switch {
+		case segment == "":
+			return pathPattern{}, fmt.Errorf("path_groups contains invalid pattern %q", pattern)
+		case segment == "*" && i != len(segments)-1:
+			return pathPattern{}, fmt.Errorf("path_groups pattern %q must have * only at the end", pattern)
+		case segment == ":":
+			return pathPattern{}, fmt.Errorf("path_groups pattern %q has an unnamed parameter", pattern)
+		}
+	}
+	return pathPattern{pattern: pattern, segments: segments}, nil
+}
+
+// match はパスのセグメントがパターンに一致するかどうかを判定します
+func (p pathPattern) match(segments []string) bool {
+	for i, segment := range p.segments {
+		if segment == "*" {
+			return len(segments) > i
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != segments[i] {
+			return false
+		}
+	}
+	return len(segments) == len(p.segments)
+}
+
+// splitPath はパスをセグメントに分けます（前後のスラッシュは無視し、ルートは空）
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
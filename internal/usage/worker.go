@@ -0,0 +1,151 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// Counter は月間のイベント数のカウンター（Redis）のインターフェースです
+type Counter interface {
+	// ListUsage は集計期間のアプリケーションごとのイベント数を返します
+	ListUsage(ctx context.Context, period time.Time) (map[string]int64, error)
+	// AddUsage は集計期間のイベント数に delta を加算します
+	AddUsage(ctx context.Context, appID string, period time.Time, delta int64) error
+}
+
+// Store は突き合わせ済みの月間のイベント数を保存するストアのインターフェースです
+type Store interface {
+	// ListUsage は集計期間のアプリケーションごとの保存済みのイベント数を返します
+	ListUsage(ctx context.Context, period time.Time) (map[string]int64, error)
+	// SaveUsage は集計期間のイベント数を保存します（保存済みの値より小さい場合は更新しない）
+	SaveUsage(ctx context.Context, appID string, period time.Time, events int64) error
+}
+
+// Config は使用量の突き合わせワーカーの設定です
+type Config struct {
+	Interval time.Duration    // 突き合わせを実行する間隔
+	Clock    func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// DefaultConfig はデフォルトの使用量の突き合わせワーカー設定を返します
+func DefaultConfig() Config {
+	return Config{
+		Interval: time.Minute,
+	}
+}
+
+// Result は1回の突き合わせの結果です
+type Result struct {
+	Periods  int // 突き合わせた集計期間の数
+	Saved    int // ストアを更新したアプリケーション・期間の数
+	Restored int // カウンターを補正したアプリケーション・期間の数
+}
+
+// Worker はRedisのカウンターとデータベースの月間のイベント数を突き合わせるワーカーです
+// 大きい方の値で両方を更新するため、Redisのデータが失われてもカウンターは保存済みの値から復元されます
+type Worker struct {
+	counter Counter
+	store   Store
+	config  Config
+	logger  logger.Logger
+}
+
+// NewWorker は新しい使用量の突き合わせワーカーを作成します
+func NewWorker(counter Counter, store Store, config Config, logger logger.Logger) *Worker {
+	if config.Interval <= 0 {
+		config.Interval = DefaultConfig().Interval
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	return &Worker{
+		counter: counter,
+		store:   store,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// Run はコンテキストがキャンセルされるまで定期的に RunOnce を実行します
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("Usage reconciliation failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Usage worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce は当月と前月のカウンターと保存済みの値を突き合わせます
+// 前月も対象にするのは、月が変わる直前に加算された分を保存するためです
+func (w *Worker) RunOnce(ctx context.Context) (Result, error) {
+	current := models.UsagePeriod(w.config.Clock())
+	previous := models.UsagePeriod(current.AddDate(0, -1, 0))
+
+	var result Result
+	for _, period := range []time.Time{previous, current} {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := w.reconcile(ctx, period, &result); err != nil {
+			return result, err
+		}
+		result.Periods++
+	}
+
+	if result.Saved > 0 || result.Restored > 0 {
+		w.logger.Info("Usage reconciled", "saved", result.Saved, "restored", result.Restored)
+	}
+	return result, nil
+}
+
+// reconcile は1つの集計期間のカウンターと保存済みの値を突き合わせます
+func (w *Worker) reconcile(ctx context.Context, period time.Time, result *Result) error {
+	counted, err := w.counter.ListUsage(ctx, period)
+	if err != nil {
+		return err
+	}
+	saved, err := w.store.ListUsage(ctx, period)
+	if err != nil {
+		return err
+	}
+
+	for appID, events := range counted {
+		if events <= saved[appID] {
+			continue
+		}
+		if err := w.store.SaveUsage(ctx, appID, period, events); err != nil {
+			if errors.Is(err, models.ErrApplicationNotFound) {
+				// 削除済みのアプリケーションのカウンターは期限切れを待つ
+				continue
+			}
+			return err
+		}
+		result.Saved++
+	}
+
+	for appID, events := range saved {
+		if delta := events - counted[appID]; delta > 0 {
+			if err := w.counter.AddUsage(ctx, appID, period, delta); err != nil {
+				return err
+			}
+			result.Restored++
+		}
+	}
+
+	return nil
+}
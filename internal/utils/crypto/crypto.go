@@ -3,6 +3,7 @@ package crypto
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"regexp"
 )
@@ -31,6 +32,35 @@ func GenerateAPIKey() string {
 	return GenerateRandomString(32)
 }
 
+// APIKeyPrefixLength はAPIキーの検索に使う先頭部分（プレフィックス）の長さです
+const APIKeyPrefixLength = 8
+
+// APIKeyPrefix はAPIキーの検索用プレフィックスを返します
+func APIKeyPrefix(apiKey string) string {
+	if len(apiKey) <= APIKeyPrefixLength {
+		return apiKey
+	}
+	return apiKey[:APIKeyPrefixLength]
+}
+
+// GenerateAPIKeySalt はAPIキーのハッシュに使うソルトを生成します
+func GenerateAPIKeySalt() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// HashAPIKey はソルト付きのAPIキーのSHA256ハッシュを返します
+// APIキーは十分なエントロピーを持つランダム文字列のため、低速なパスワードハッシュは使用しません
+func HashAPIKey(apiKey, salt string) string {
+	return HashSHA256(salt + apiKey)
+}
+
+// VerifyAPIKey はAPIキーがソルト付きハッシュと一致するかどうかを定数時間で確認します
+func VerifyAPIKey(apiKey, salt, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(apiKey, salt)), []byte(hash)) == 1
+}
+
 // ValidateAPIKey はAPIキーが有効かどうかを判定します
 func ValidateAPIKey(apiKey string) bool {
 	if len(apiKey) < 16 {
@@ -0,0 +1,101 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken はトークンの形式・署名・アルゴリズムが不正な場合のエラーです
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired はトークンの有効期限切れ（または有効期間前）のエラーです
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// jwtHeader はHS256で署名するJWTのヘッダーです
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// JWTClaims はJWTのクレームを表します
+type JWTClaims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// SignJWT はクレームをHS256で署名したJWTを生成します
+func SignJWT(claims JWTClaims, secret string) (string, error) {
+	if secret == "" {
+		return "", errors.New("jwt secret is required")
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + signJWT(signingInput, secret), nil
+}
+
+// ParseJWT はHS256で署名されたJWTを検証してクレームを返します
+// 署名・アルゴリズムが不正な場合は ErrInvalidToken、有効期間外の場合は ErrTokenExpired を返します
+func ParseJWT(token, secret string, now time.Time) (*JWTClaims, error) {
+	if secret == "" {
+		return nil, ErrInvalidToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	// alg=none などによる署名回避を防ぐため、ヘッダーのアルゴリズムを確認する
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != "HS256" {
+		return nil, ErrInvalidToken
+	}
+
+	expected := signJWT(parts[0]+"."+parts[1], secret)
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims JWTClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// 有効期限のないトークンは受け付けない
+	if claims.ExpiresAt == 0 || now.Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore {
+		return nil, ErrTokenExpired
+	}
+
+	return &claims, nil
+}
+
+// signJWT は署名対象の文字列のHMAC-SHA256署名をbase64url形式で返します
+func signJWT(signingInput, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
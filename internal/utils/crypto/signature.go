@@ -0,0 +1,39 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SigningSecretPrefix はリクエスト署名のシークレットの接頭辞です（APIキーと区別するため）
+const SigningSecretPrefix = "whsec_"
+
+// SignaturePrefix は署名ヘッダーの値に付けることができる接頭辞です
+const SignaturePrefix = "sha256="
+
+// GenerateSigningSecret はリクエスト署名のシークレットを生成します
+func GenerateSigningSecret() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	return SigningSecretPrefix + hex.EncodeToString(bytes)
+}
+
+// SignRequest はリクエストのHMAC-SHA256署名を16進表記で返します
+// 署名対象は "メソッド\nパス（クエリを含む）\nタイムスタンプ\nボディ" です
+func SignRequest(secret, method, path, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.ToUpper(method) + "\n" + path + "\n" + timestamp + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequestSignature はリクエストの署名が正しいかどうかを定数時間で確認します
+// 署名には "sha256=" の接頭辞を付けることができます
+func VerifyRequestSignature(secret, method, path, timestamp string, body []byte, signature string) bool {
+	signature = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(signature), SignaturePrefix))
+	expected := SignRequest(secret, method, path, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,125 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Config はLRUキャッシュの設定です
+type Config struct {
+	Size  int              // 保持するエントリ数の上限（0 以下の場合は DefaultSize）
+	TTL   time.Duration    // エントリの有効期間（0 以下の場合は期限なし）
+	Clock func() time.Time // 現在時刻（テスト用、nil の場合は time.Now）
+}
+
+// DefaultSize はエントリ数の上限のデフォルト値です
+const DefaultSize = 1000
+
+// entry はキャッシュのエントリです
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// Cache は有効期間付きのLRUキャッシュです
+// 上限を超える場合は最も長く使われていないエントリを削除します
+type Cache[K comparable, V any] struct {
+	config Config
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[K]*list.Element
+}
+
+// New は新しいLRUキャッシュを作成します
+func New[K comparable, V any](config Config) *Cache[K, V] {
+	if config.Size <= 0 {
+		config.Size = DefaultSize
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	return &Cache[K, V]{
+		config: config,
+		ll:     list.New(),
+		items:  make(map[K]*list.Element),
+	}
+}
+
+// Get はキーの値を取得します（期限切れのエントリは削除し、見つからないものとして扱う）
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := elem.Value.(*entry[K, V])
+	if !e.expires.IsZero() && !c.config.Clock().Before(e.expires) {
+		c.removeElement(elem)
+		return zero, false
+	}
+	c.ll.MoveToFront(elem)
+	return e.value, true
+}
+
+// Add はキーと値を追加します（既にある場合は値と有効期間を更新する）
+func (c *Cache[K, V]) Add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if c.config.TTL > 0 {
+		expires = c.config.Clock().Add(c.config.TTL)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	if c.ll.Len() > c.config.Size {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Remove はキーを削除します
+func (c *Cache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Purge はすべてのエントリを削除します
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[K]*list.Element)
+}
+
+// Len は保持しているエントリ数を返します（期限切れで未削除のエントリを含む）
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// removeElement はエントリを削除します
+func (c *Cache[K, V]) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*entry[K, V]).key)
+}
@@ -102,6 +102,15 @@ func ParseDate(dateStr string) (time.Time, error) {
 	return t, nil
 }
 
+// ParseDateInLocation は日付文字列を指定されたタイムゾーンの日付としてパースします（YYYY-MM-DD形式）
+func ParseDateInLocation(dateStr string, loc *time.Location) (time.Time, error) {
+	if dateStr == "" {
+		return time.Time{}, errors.New("empty date string")
+	}
+
+	return time.ParseInLocation("2006-01-02", dateStr, loc)
+}
+
 // IsYesterday は指定された時間が昨日かどうかを判定します
 func IsYesterday(t time.Time) bool {
 	now := time.Now()
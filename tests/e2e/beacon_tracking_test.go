@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,6 +19,14 @@ const (
 	baseURL = "http://test-app:8080"
 )
 
+// adminToken は管理API用のトークンを返します（TEST_ADMIN_TOKEN 未設定時は docker-compose.test.yml の値）
+func adminToken() string {
+	if token := os.Getenv("TEST_ADMIN_TOKEN"); token != "" {
+		return token
+	}
+	return "test-admin-token"
+}
+
 // Application はE2Eテスト用のアプリケーション構造体です
 type Application struct {
 	AppID       string `json:"app_id"`
@@ -43,7 +52,11 @@ func TestBasicE2EFlow(t *testing.T) {
 
 	t.Run("Application Creation and Retrieval", func(t *testing.T) {
 		// 作成したアプリケーションを取得（エンドポイントが存在しない場合はスキップ）
-		resp, err := http.Get(fmt.Sprintf("%s/v1/applications/%s", baseURL, app.AppID))
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/applications/%s", baseURL, app.AppID), nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+adminToken())
+
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil || resp.StatusCode == http.StatusNotFound {
 			t.Skip("Application retrieval endpoint not available")
 		}
@@ -99,6 +112,7 @@ func createTestApplication(t *testing.T) *Application {
 	req, err := http.NewRequest("POST", baseURL+"/v1/applications", bytes.NewBuffer(jsonData))
 	require.NoError(t, err)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+adminToken())
 
 	resp, err := http.DefaultClient.Do(req)
 	require.NoError(t, err)
@@ -147,6 +161,7 @@ func createTestApplication(t *testing.T) *Application {
 func cleanupTestApplication(t *testing.T, appID string) {
 	req, err := http.NewRequest("DELETE", baseURL+"/v1/applications/"+appID, nil)
 	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+adminToken())
 
 	resp, err := http.DefaultClient.Do(req)
 	if err == nil && resp != nil {
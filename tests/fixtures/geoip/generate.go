@@ -0,0 +1,218 @@
+//go:build ignore
+
+// generate.go はテスト用の小さなMaxMind形式（GeoLite2互換）のデータベースを作成します
+//
+//	go run ./tests/fixtures/geoip/generate.go
+//
+// 実在しないドキュメント用のアドレス範囲（RFC 5737 / RFC 3849）だけを含みます
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// network はネットワークとそのレコードです
+type network struct {
+	cidr   string
+	record map[string]interface{}
+}
+
+func city(country, subdivision, name string) map[string]interface{} {
+	return map[string]interface{}{
+		"country":      map[string]interface{}{"iso_code": country},
+		"subdivisions": []interface{}{map[string]interface{}{"iso_code": subdivision}},
+		"city":         map[string]interface{}{"names": map[string]interface{}{"en": name}},
+	}
+}
+
+func asn(number uint32, org string) map[string]interface{} {
+	return map[string]interface{}{
+		"autonomous_system_number":       number,
+		"autonomous_system_organization": org,
+	}
+}
+
+func main() {
+	dir := filepath.Join("tests", "fixtures", "geoip")
+
+	write(filepath.Join(dir, "GeoLite2-City-Test.mmdb"), "GeoLite2-City", []network{
+		{"203.0.113.0/24", city("JP", "13", "Tokyo")},
+		{"198.51.100.0/24", city("US", "CA", "Mountain View")},
+		{"2001:db8::/32", city("DE", "BE", "Berlin")},
+	})
+	// ホットリロードの確認用（203.0.113.0/24 の都市が異なる）
+	write(filepath.Join(dir, "GeoLite2-City-Test-Updated.mmdb"), "GeoLite2-City", []network{
+		{"203.0.113.0/24", city("JP", "27", "Osaka")},
+		{"198.51.100.0/24", city("US", "CA", "Mountain View")},
+		{"2001:db8::/32", city("DE", "BE", "Berlin")},
+	})
+	write(filepath.Join(dir, "GeoLite2-ASN-Test.mmdb"), "GeoLite2-ASN", []network{
+		{"203.0.113.0/24", asn(64500, "Example Net JP")},
+		{"198.51.100.0/24", asn(64501, "Example Net US")},
+	})
+}
+
+// node は探索木のノードです
+type node struct {
+	children [2]*node
+	data     [2]int // 子がない場合のデータのオフセット + 1（0 はデータなし）
+	index    int
+}
+
+// write はIPv6の探索木（IPv4は ::/96 に割り当てる）のデータベースを書き込みます
+func write(path, databaseType string, networks []network) {
+	root := &node{}
+	var data bytes.Buffer
+	for _, n := range networks {
+		_, ipNet, err := net.ParseCIDR(n.cidr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ip := ipNet.IP.To16()
+		ones, _ := ipNet.Mask.Size()
+		if v4 := ipNet.IP.To4(); v4 != nil {
+			// To16 は ::ffff:0:0/96 に割り当てるため、::/96 に置き直す
+			ip = append(make(net.IP, 12), v4...)
+			ones += 96
+		}
+
+		offset := data.Len()
+		encode(&data, n.record)
+
+		current := root
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if i == ones-1 {
+				current.data[bit] = offset + 1
+				break
+			}
+			if current.children[bit] == nil {
+				current.children[bit] = &node{}
+			}
+			current = current.children[bit]
+		}
+	}
+
+	// ノードに番号を付ける（幅優先）
+	var nodes []*node
+	queue := []*node{root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		n.index = len(nodes)
+		nodes = append(nodes, n)
+		for _, child := range n.children {
+			if child != nil {
+				queue = append(queue, child)
+			}
+		}
+	}
+	nodeCount := len(nodes)
+
+	// 24ビットのレコードで探索木を書き込む
+	var out bytes.Buffer
+	for _, n := range nodes {
+		for bit := 0; bit < 2; bit++ {
+			value := nodeCount // データなし
+			if n.children[bit] != nil {
+				value = n.children[bit].index
+			} else if n.data[bit] > 0 {
+				value = nodeCount + 16 + n.data[bit] - 1
+			}
+			out.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+	out.Write(make([]byte, 16))
+	out.Write(data.Bytes())
+
+	out.WriteString("\xab\xcd\xefMaxMind.com")
+	encode(&out, map[string]interface{}{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(6),
+		"database_type":               databaseType,
+		"languages":                   []interface{}{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1704067200),
+		"description":                 map[string]interface{}{"en": "accesslog-tracker test fixture"},
+	})
+
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("wrote", path)
+}
+
+// encode はMaxMind DBのデータ形式で値を書き込みます
+func encode(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		control(buf, 2, len(v))
+		buf.WriteString(v)
+	case uint16:
+		writeUint(buf, 5, uint64(v))
+	case uint32:
+		writeUint(buf, 6, uint64(v))
+	case uint64:
+		writeUint(buf, 9, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		control(buf, 7, len(keys))
+		for _, key := range keys {
+			encode(buf, key)
+			encode(buf, v[key])
+		}
+	case []interface{}:
+		control(buf, 11, len(v))
+		for _, item := range v {
+			encode(buf, item)
+		}
+	default:
+		log.Fatalf("unsupported type %T", value)
+	}
+}
+
+// writeUint は符号なし整数を最小のバイト数で書き込みます
+func writeUint(buf *bytes.Buffer, typ int, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	trimmed := bytes.TrimLeft(b[:], "\x00")
+	control(buf, typ, len(trimmed))
+	buf.Write(trimmed)
+}
+
+// control は型とサイズの制御バイトを書き込みます
+func control(buf *bytes.Buffer, typ, size int) {
+	first := byte(0)
+	if typ <= 7 {
+		first = byte(typ << 5)
+	}
+	var extra []byte
+	switch {
+	case size < 29:
+		first |= byte(size)
+	case size < 285:
+		first |= 29
+		extra = []byte{byte(size - 29)}
+	default:
+		first |= 30
+		extra = []byte{byte((size - 285) >> 8), byte(size - 285)}
+	}
+	buf.WriteByte(first)
+	if typ > 7 {
+		buf.WriteByte(byte(typ - 7))
+	}
+	buf.Write(extra)
+}
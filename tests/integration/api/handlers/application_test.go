@@ -17,6 +17,7 @@ import (
 	"accesslog-tracker/internal/api/middleware"
 	apimodels "accesslog-tracker/internal/api/models"
 	"accesslog-tracker/internal/config"
+	domainmodels "accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/domain/services"
 	redisCache "accesslog-tracker/internal/infrastructure/cache/redis"
 	"accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
@@ -69,6 +70,11 @@ func TestApplicationHandlerIntegration(t *testing.T) {
 	router := gin.New()
 	router.Use(middleware.Logging(log))
 	router.Use(middleware.ErrorHandler(log))
+	// 管理API認証はルートのテストで確認するため、スーパーユーザーとして実行する
+	router.Use(func(c *gin.Context) {
+		c.Set("principal", &domainmodels.Principal{Superuser: true})
+		c.Next()
+	})
 
 	// アプリケーションルートを追加
 	router.POST("/v1/applications", appHandler.Create)
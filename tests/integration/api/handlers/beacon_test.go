@@ -16,8 +16,10 @@ import (
 	"accesslog-tracker/internal/api/middleware"
 	apimodels "accesslog-tracker/internal/api/models"
 	"accesslog-tracker/internal/config"
+	"accesslog-tracker/internal/domain/services"
 	redisCache "accesslog-tracker/internal/infrastructure/cache/redis"
 	"accesslog-tracker/internal/infrastructure/database/postgresql"
+	"accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
 	"accesslog-tracker/internal/utils/logger"
 	apihelpers "accesslog-tracker/tests/integration/api"
 )
@@ -63,14 +65,20 @@ func TestBeaconHandlerIntegration(t *testing.T) {
 	require.NoError(t, err)
 	defer dbConn.Close()
 
+	// サービスを初期化
+	trackingRepo := repositories.NewTrackingRepository(db)
+	appRepo := repositories.NewApplicationRepository(db)
+	trackingService := services.NewTrackingService(trackingRepo)
+	appService := services.NewApplicationService(appRepo, cacheService)
+
 	// ハンドラーを初期化
-	beaconHandler := handlers.NewBeaconHandler()
+	beaconHandler := handlers.NewBeaconHandler(trackingService, appService, log)
 
 	// ルーターをセットアップ
 	router := gin.New()
 	router.Use(middleware.Logging(log))
 	router.Use(middleware.ErrorHandler(log))
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(middleware.DefaultCORSConfig(), appService))
 
 	// ビーコンルートを追加（順序が重要）
 	router.GET("/tracker/:app_id", beaconHandler.ServeCustom) // パラメータのみ
@@ -105,6 +113,22 @@ func TestBeaconHandlerIntegration(t *testing.T) {
 		assert.Equal(t, "image/gif", w.Header().Get("Content-Type"))
 	})
 
+	t.Run("should_persist_beacon_hit", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/beacon?app_id="+app.AppID+"&session_id=beacon_persist_session&url=https://test.example.com/landing", nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+		req.RemoteAddr = "192.168.1.10:12345"
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		beaconHandler.Wait()
+
+		assert.Equal(t, 200, w.Code)
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM access_logs WHERE app_id = $1 AND session_id = $2", app.AppID, "beacon_persist_session").Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
 	t.Run("should_generate_beacon_with_referrer", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/beacon?app_id="+app.AppID+"&referrer=https://example.com", nil)
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
@@ -60,7 +60,7 @@ func TestHealthHandlerIntegration(t *testing.T) {
 	defer dbConn.Close()
 
 	// ハンドラーを初期化
-	healthHandler := handlers.NewHealthHandler(dbConn, cacheService, log)
+	healthHandler := handlers.NewHealthHandler(dbConn, cacheService, nil, log)
 
 	// ルーターをセットアップ
 	router := gin.New()
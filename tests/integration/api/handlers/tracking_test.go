@@ -80,7 +80,7 @@ func TestTrackingHandlerIntegration(t *testing.T) {
 	router := gin.New()
 	router.Use(middleware.Logging(log))
 	router.Use(middleware.ErrorHandler(log))
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(middleware.DefaultCORSConfig(), nil))
 
 	// トラッキングルートを追加（認証コンテキストを設定するミドルウェアを追加）
 	router.POST("/v1/tracking/track", func(c *gin.Context) {
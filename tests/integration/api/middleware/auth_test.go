@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -127,10 +128,14 @@ func TestAuthMiddlewareIntegration(t *testing.T) {
 		}
 
 		_, err := db.Exec(`
-			INSERT INTO applications (app_id, name, description, domain, api_key, is_active, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			INSERT INTO applications (app_id, name, description, domain, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
 			ON CONFLICT (app_id) DO NOTHING
-		`, inactiveApp.AppID, inactiveApp.Name, inactiveApp.Description, inactiveApp.Domain, inactiveApp.APIKey, inactiveApp.Active, inactiveApp.CreatedAt, inactiveApp.UpdatedAt)
+		`, inactiveApp.AppID, inactiveApp.Name, inactiveApp.Description, inactiveApp.Domain, inactiveApp.Active, inactiveApp.CreatedAt, inactiveApp.UpdatedAt)
+		require.NoError(t, err)
+
+		// APIキーはハッシュ化して api_keys に保存する
+		err = repositories.NewApplicationRepository(db).CreateAPIKey(context.Background(), &models.APIKey{AppID: inactiveApp.AppID, Name: models.DefaultAPIKeyName}, inactiveApp.APIKey)
 		require.NoError(t, err)
 
 		req, _ := http.NewRequest("GET", "/test", nil)
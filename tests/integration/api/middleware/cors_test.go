@@ -13,8 +13,10 @@ import (
 
 func TestCORSMiddlewareIntegration(t *testing.T) {
 	// ルーターをセットアップ
+	corsConfig := middleware.DefaultCORSConfig()
+	corsConfig.AllowedOrigins = append(corsConfig.AllowedOrigins, "https://example.com")
 	router := gin.New()
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(corsConfig, nil))
 
 	// テスト用ルートを追加
 	router.GET("/test", func(c *gin.Context) {
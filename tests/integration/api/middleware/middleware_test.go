@@ -74,7 +74,9 @@ func TestMiddlewareIntegration(t *testing.T) {
 
 	// ミドルウェアを初期化
 	authMiddleware := middleware.NewAuthMiddleware(appService, log)
-	corsMiddleware := middleware.CORS()
+	corsConfig := middleware.DefaultCORSConfig()
+	corsConfig.AllowedOrigins = append(corsConfig.AllowedOrigins, "https://example.com")
+	corsMiddleware := middleware.CORS(corsConfig, appService)
 	rateLimitConfig := middleware.RateLimitConfig{
 		RequestsPerMinute: 10,
 		RequestsPerHour:   100,
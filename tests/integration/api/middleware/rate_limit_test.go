@@ -29,11 +29,11 @@ func TestRateLimitMiddlewareIntegration(t *testing.T) {
 	require.NoError(t, err)
 	defer redisClient.Close()
 
-	// 設定を初期化
+	// 設定を初期化（バーストは1分間の上限と同じにして、1分間の上限で拒否されることを確認する）
 	cfg := middleware.RateLimitConfig{
 		RequestsPerMinute: 10,
 		RequestsPerHour:   100,
-		BurstSize:         5,
+		BurstSize:         10,
 	}
 
 	// ロガーを初期化
@@ -236,7 +236,7 @@ func TestRateLimitMiddlewareIntegration(t *testing.T) {
 		customCfg := middleware.RateLimitConfig{
 			RequestsPerMinute: 2,
 			RequestsPerHour:   10,
-			BurstSize:         1,
+			BurstSize:         2,
 		}
 
 		customRateLimitMiddleware := middleware.NewRateLimitMiddleware(redisClient, log, customCfg)
@@ -359,3 +359,102 @@ func TestRateLimitMiddlewareIntegration(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 	})
 }
+
+func TestRateLimitScriptIntegration(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "redis:6379",
+		DB:   0,
+	})
+
+	ctx := context.Background()
+	require.NoError(t, redisClient.Ping(ctx).Err())
+	defer redisClient.Close()
+
+	log := logger.NewLogger()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newLimiter := func(cfg middleware.RateLimitConfig) (*middleware.RateLimitMiddleware, *time.Time) {
+		now := start
+		cfg.Clock = func() time.Time { return now }
+		return middleware.NewRateLimitMiddleware(redisClient, log, cfg), &now
+	}
+
+	t.Run("should_limit_burst_and_refill", func(t *testing.T) {
+		redisClient.FlushDB(ctx)
+		limiter, now := newLimiter(middleware.RateLimitConfig{RequestsPerMinute: 60, BurstSize: 3})
+
+		for i := 0; i < 3; i++ {
+			result, err := limiter.Allow(ctx, "burst", 1)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 2-i, result.Remaining)
+		}
+
+		result, err := limiter.Allow(ctx, "burst", 1)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+
+		// 1分間に60件の速度で補充される
+		*now = now.Add(time.Second)
+		result, err = limiter.Allow(ctx, "burst", 1)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("should_limit_sliding_minute_window", func(t *testing.T) {
+		redisClient.FlushDB(ctx)
+		limiter, now := newLimiter(middleware.RateLimitConfig{RequestsPerMinute: 10})
+
+		result, err := limiter.Allow(ctx, "minute", 10)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+
+		result, err = limiter.Allow(ctx, "minute", 1)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Minute, result.RetryAfter)
+
+		// 次のウィンドウの半ばでは、直前のウィンドウの半分（5件）を数える
+		*now = now.Add(90 * time.Second)
+		result, err = limiter.Allow(ctx, "minute", 5)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		result, err = limiter.Allow(ctx, "minute", 1)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("should_limit_hour_window", func(t *testing.T) {
+		redisClient.FlushDB(ctx)
+		limiter, now := newLimiter(middleware.RateLimitConfig{RequestsPerMinute: 10, RequestsPerHour: 15})
+
+		for i := 0; i < 3; i++ {
+			result, err := limiter.Allow(ctx, "hour", 5)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			*now = now.Add(2 * time.Minute)
+		}
+
+		result, err := limiter.Allow(ctx, "hour", 1)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("should_accept_cost_larger_than_burst_when_full", func(t *testing.T) {
+		redisClient.FlushDB(ctx)
+		limiter, _ := newLimiter(middleware.RateLimitConfig{RequestsPerMinute: 600, BurstSize: 5})
+
+		result, err := limiter.Allow(ctx, "batch", 8)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		// 超えた分を返済するまでは受け付けない
+		result, err = limiter.Allow(ctx, "batch", 1)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+}
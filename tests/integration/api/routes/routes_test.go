@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"accesslog-tracker/internal/api/middleware"
 	"accesslog-tracker/internal/api/routes"
 	apimodels "accesslog-tracker/internal/api/models"
 	"accesslog-tracker/internal/config"
@@ -23,6 +24,9 @@ import (
 	apihelpers "accesslog-tracker/tests/integration/api"
 )
 
+// testAdminToken はテスト用のマスター管理トークンです
+const testAdminToken = "routes-test-admin-token"
+
 func TestRoutesIntegration(t *testing.T) {
 	// テスト用データベースをセットアップ
 	db := apihelpers.SetupTestDatabase(t)
@@ -67,14 +71,16 @@ func TestRoutesIntegration(t *testing.T) {
 	// リポジトリを初期化
 	appRepo := repositories.NewApplicationRepository(db)
 	trackingRepo := repositories.NewTrackingRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
 
 	// サービスを初期化
 	appService := services.NewApplicationService(appRepo, cacheService)
 	trackingService := services.NewTrackingService(trackingRepo)
+	orgService := services.NewOrganizationService(orgRepo)
 
 	// ルーターをセットアップ
 	router := gin.New()
-	routes.Setup(router, trackingService, appService, dbConn, cacheService, log)
+	routes.Setup(router, trackingService, appService, orgService, dbConn, cacheService, middleware.AdminAuthConfig{Token: testAdminToken}, middleware.DefaultCORSConfig(), middleware.DefaultRateLimitConfig(), log)
 
 	t.Run("should_handle_health_check", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/health", nil)
@@ -130,6 +136,7 @@ func TestRoutesIntegration(t *testing.T) {
 
 		req, _ := http.NewRequest("POST", "/v1/applications", bytes.NewBuffer(jsonData))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -142,8 +149,18 @@ func TestRoutesIntegration(t *testing.T) {
 		assert.True(t, response.Success)
 	})
 
+	t.Run("should_reject_application_listing_without_admin_credentials", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/applications", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 401, w.Code)
+	})
+
 	t.Run("should_handle_application_listing", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/v1/applications", nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -316,14 +333,16 @@ func TestRoutesSetupTest(t *testing.T) {
 	// リポジトリを初期化
 	appRepo := repositories.NewApplicationRepository(db)
 	trackingRepo := repositories.NewTrackingRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
 
 	// サービスを初期化
 	appService := services.NewApplicationService(appRepo, cacheService)
 	trackingService := services.NewTrackingService(trackingRepo)
+	orgService := services.NewOrganizationService(orgRepo)
 
 	// テスト用ルーターをセットアップ
 	router := gin.New()
-	routes.SetupTest(router, trackingService, appService, dbConn, cacheService, log)
+	routes.SetupTest(router, trackingService, appService, orgService, dbConn, cacheService, middleware.AdminAuthConfig{Token: testAdminToken}, middleware.DefaultCORSConfig(), middleware.DefaultRateLimitConfig(), log)
 
 	t.Run("should_handle_test_health_check", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/health", nil)
@@ -68,13 +68,15 @@ func TestServerIntegration(t *testing.T) {
 	// リポジトリを初期化
 	appRepo := repositories.NewApplicationRepository(db)
 	trackingRepo := repositories.NewTrackingRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
 
 	// サービスを初期化
 	appService := services.NewApplicationService(appRepo, cacheService)
 	trackingService := services.NewTrackingService(trackingRepo)
+	orgService := services.NewOrganizationService(orgRepo)
 
 	// サーバーを初期化
-	srv := server.NewServer(cfg, log, trackingService, appService, dbConn, cacheService)
+	srv := server.NewServer(cfg, log, trackingService, appService, orgService, dbConn, cacheService)
 
 	t.Run("should_start_and_stop_server", func(t *testing.T) {
 		// サーバーを開始
@@ -285,13 +287,15 @@ func TestServerSetupTest(t *testing.T) {
 	// リポジトリを初期化
 	appRepo := repositories.NewApplicationRepository(db)
 	trackingRepo := repositories.NewTrackingRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
 
 	// サービスを初期化
 	appService := services.NewApplicationService(appRepo, cacheService)
 	trackingService := services.NewTrackingService(trackingRepo)
+	orgService := services.NewOrganizationService(orgRepo)
 
 	// サーバーを初期化
-	srv := server.NewServer(cfg, log, trackingService, appService, dbConn, cacheService)
+	srv := server.NewServer(cfg, log, trackingService, appService, orgService, dbConn, cacheService)
 
 	t.Run("should_setup_test_router", func(t *testing.T) {
 		// ルーターが正しく設定されていることを確認
@@ -359,26 +363,28 @@ func TestServerDirectFunctions(t *testing.T) {
 	// リポジトリを初期化
 	appRepo := repositories.NewApplicationRepository(db)
 	trackingRepo := repositories.NewTrackingRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
 
 	// サービスを初期化
 	appService := services.NewApplicationService(appRepo, cacheService)
 	trackingService := services.NewTrackingService(trackingRepo)
+	orgService := services.NewOrganizationService(orgRepo)
 
 	t.Run("should_test_new_server", func(t *testing.T) {
 		// NewServerを直接テスト
-		srv := server.NewServer(cfg, log, trackingService, appService, dbConn, cacheService)
+		srv := server.NewServer(cfg, log, trackingService, appService, orgService, dbConn, cacheService)
 		assert.NotNil(t, srv)
 		assert.NotNil(t, srv.GetRouter())
 	})
 
 	t.Run("should_test_get_router", func(t *testing.T) {
-		srv := server.NewServer(cfg, log, trackingService, appService, dbConn, cacheService)
+		srv := server.NewServer(cfg, log, trackingService, appService, orgService, dbConn, cacheService)
 		router := srv.GetRouter()
 		assert.NotNil(t, router)
 	})
 
 	t.Run("should_test_setup_test", func(t *testing.T) {
-		srv := server.NewServer(cfg, log, trackingService, appService, dbConn, cacheService)
+		srv := server.NewServer(cfg, log, trackingService, appService, orgService, dbConn, cacheService)
 		// SetupTestを直接呼び出し
 		srv.SetupTest()
 		router := srv.GetRouter()
@@ -386,7 +392,7 @@ func TestServerDirectFunctions(t *testing.T) {
 	})
 
 	t.Run("should_test_start_and_stop", func(t *testing.T) {
-		srv := server.NewServer(cfg, log, trackingService, appService, dbConn, cacheService)
+		srv := server.NewServer(cfg, log, trackingService, appService, orgService, dbConn, cacheService)
 
 		// サーバーを開始
 		go func() {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
 
 	_ "github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
@@ -96,10 +97,14 @@ func CreateTestApplication(t *testing.T, db *sql.DB) *models.Application {
 	}
 
 	_, err := db.Exec(`
-		INSERT INTO applications (app_id, name, description, domain, api_key, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO applications (app_id, name, description, domain, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (app_id) DO NOTHING
-	`, app.AppID, app.Name, app.Description, app.Domain, app.APIKey, app.Active, app.CreatedAt, app.UpdatedAt)
+	`, app.AppID, app.Name, app.Description, app.Domain, app.Active, app.CreatedAt, app.UpdatedAt)
+	require.NoError(t, err)
+
+	// APIキーはハッシュ化して api_keys に保存する
+	err = repositories.NewApplicationRepository(db).CreateAPIKey(context.Background(), &models.APIKey{AppID: app.AppID, Name: models.DefaultAPIKeyName}, app.APIKey)
 	require.NoError(t, err)
 
 	return app
@@ -120,7 +125,7 @@ func CreateTestTrackingData(t *testing.T, db *sql.DB, appID string) *models.Trac
 	_, err := db.Exec(`
 		INSERT INTO access_logs (id, app_id, user_agent, url, ip_address, session_id, timestamp)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (id) DO NOTHING
+		ON CONFLICT (id, timestamp) DO NOTHING
 	`, trackingData.ID, trackingData.AppID, trackingData.UserAgent,
 		trackingData.URL, trackingData.IPAddress, trackingData.SessionID, trackingData.Timestamp)
 	require.NoError(t, err)
@@ -142,9 +147,9 @@ func CreateTestCustomParameter(t *testing.T, db *sql.DB, trackingID string, key,
 func GetTestApplicationByID(t *testing.T, db *sql.DB, appID string) *models.Application {
 	var app models.Application
 	err := db.QueryRow(`
-		SELECT app_id, name, description, domain, api_key, active, created_at, updated_at
+		SELECT app_id, name, description, domain, is_active, created_at, updated_at
 		FROM applications WHERE app_id = $1
-	`, appID).Scan(&app.AppID, &app.Name, &app.Description, &app.Domain, &app.APIKey, &app.Active, &app.CreatedAt, &app.UpdatedAt)
+	`, appID).Scan(&app.AppID, &app.Name, &app.Description, &app.Domain, &app.Active, &app.CreatedAt, &app.UpdatedAt)
 
 	if err != nil {
 		return nil
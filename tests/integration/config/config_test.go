@@ -174,8 +174,8 @@ func TestConfig_LoadFromEnv(t *testing.T) {
 	assert.Equal(t, "168h", cfg.JWT.RefreshExpiration)
 	assert.Equal(t, "http://localhost:3000,http://localhost:8080", cfg.CORS.AllowedOrigins)
 	assert.Equal(t, "GET,POST,PUT,DELETE,OPTIONS", cfg.CORS.AllowedMethods)
-	assert.Equal(t, "Content-Type,Authorization,X-Requested-With", cfg.CORS.AllowedHeaders)
-	assert.Equal(t, "Content-Length", cfg.CORS.ExposedHeaders)
+	assert.Equal(t, "Origin,Content-Type,Accept,Authorization,X-API-Key,X-Requested-With,X-ALT-Signature,X-ALT-Timestamp", cfg.CORS.AllowedHeaders)
+	assert.Equal(t, "Content-Length,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,Retry-After", cfg.CORS.ExposedHeaders)
 	assert.True(t, cfg.CORS.AllowCredentials)
 	assert.Equal(t, 86400, cfg.CORS.MaxAge)
 	assert.Equal(t, "info", cfg.Logging.Level)
@@ -321,7 +321,7 @@ func TestApplicationService_ValidateAPIKey(t *testing.T) {
 	})
 }
 
-func TestApplicationService_RegenerateAPIKey(t *testing.T) {
+func TestApplicationService_RotateAPIKey(t *testing.T) {
 	// テスト環境のセットアップ
 	db := postgresql.NewConnection("test")
 	err := db.Connect("host=localhost port=5432 user=postgres password=password dbname=access_log_tracker_test sslmode=disable")
@@ -339,10 +339,10 @@ func TestApplicationService_RegenerateAPIKey(t *testing.T) {
 
 	// テストデータの準備
 	app := &models.Application{
-		AppID:       "test-app-regenerate-123",
-		Name:        "Test App for Regenerate",
-		Description: "Test application for API key regeneration",
-		Domain:      "test-regenerate.example.com",
+		AppID:       "test-app-rotate-123",
+		Name:        "Test App for Rotate",
+		Description: "Test application for API key rotation",
+		Domain:      "test-rotate.example.com",
 		APIKey:      "old-api-key-123",
 		Active:      true,
 		CreatedAt:   time.Now(),
@@ -358,21 +358,26 @@ func TestApplicationService_RegenerateAPIKey(t *testing.T) {
 
 	oldAPIKey := app.APIKey
 
-	// APIキーを再生成
-	newAPIKey, err := appService.RegenerateAPIKey(ctx, app.AppID)
+	// 古いAPIキーをキャッシュに載せる
+	_, err = appService.GetByAPIKey(ctx, oldAPIKey)
+	require.NoError(t, err)
+
+	// 猶予期間なしでAPIキーをローテーション
+	gracePeriod := time.Duration(0)
+	rotation, err := appService.RotateAPIKey(ctx, app.AppID, app.AuthenticatedKey.ID, &gracePeriod)
 	if err != nil && (strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "failed to connect")) {
 		t.Skip("Database connection not available in test environment")
 	}
 	require.NoError(t, err)
-	assert.NotEmpty(t, newAPIKey)
-	assert.NotEqual(t, oldAPIKey, newAPIKey)
+	assert.NotEmpty(t, rotation.Secret)
+	assert.NotEqual(t, oldAPIKey, rotation.Secret)
 
 	// 新しいAPIキーでアプリケーションを取得
-	updatedApp, err := appService.GetByAPIKey(ctx, newAPIKey)
+	updatedApp, err := appService.GetByAPIKey(ctx, rotation.Secret)
 	require.NoError(t, err)
-	assert.Equal(t, newAPIKey, updatedApp.APIKey)
+	assert.Equal(t, rotation.Secret, updatedApp.APIKey)
 
-	// 古いAPIキーでは取得できないことを確認
+	// 古いAPIキーはキャッシュからも削除され、取得できないことを確認
 	_, err = appService.GetByAPIKey(ctx, oldAPIKey)
 	assert.Error(t, err)
 }
@@ -435,7 +440,7 @@ func TestApplicationService_UpdateSettings(t *testing.T) {
 	assert.NotNil(t, updatedApp)
 }
 
-func TestApplicationService_RegenerateAPIKey_NonExistentApp(t *testing.T) {
+func TestApplicationService_RotateAPIKey_NonExistentApp(t *testing.T) {
 	// テスト環境のセットアップ
 	db := postgresql.NewConnection("test")
 	err := db.Connect("host=localhost port=5432 user=postgres password=password dbname=access_log_tracker_test sslmode=disable")
@@ -451,8 +456,8 @@ func TestApplicationService_RegenerateAPIKey_NonExistentApp(t *testing.T) {
 
 	ctx := context.Background()
 
-	// 存在しないアプリケーションのAPIキーを再生成
-	_, err = appService.RegenerateAPIKey(ctx, "non-existent-app-id")
+	// 存在しないアプリケーションのAPIキーをローテーション
+	_, err = appService.RotateAPIKey(ctx, "non-existent-app-id", "non-existent-key-id", nil)
 	if err != nil && (strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "failed to connect")) {
 		t.Skip("Database connection not available in test environment")
 	}
@@ -174,7 +174,11 @@ func TestTrackingService_GetStatistics_InvalidDateRange(t *testing.T) {
 	startDate := time.Now().AddDate(0, 0, 1) // 明日
 	endDate := time.Now().AddDate(0, 0, -1)  // 昨日
 
-	_, err = trackingService.GetStatistics(ctx, "test-app-invalid", startDate, endDate)
+	_, err = trackingService.GetStatistics(ctx, services.StatisticsQuery{
+		AppID:     "test-app-invalid",
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid statistics period")
 }
@@ -511,6 +515,14 @@ func TestTrackingService_GetDailyStatistics(t *testing.T) {
 	err = trackingService.ProcessTrackingData(ctx, trackingData)
 	require.NoError(t, err)
 
+	// 同じセッションの2件目（セッション継続時間を計測するため）
+	secondData := *trackingData
+	secondData.ID = "test-tracking-daily-456"
+	secondData.URL = "https://example.com/next"
+	secondData.Timestamp = trackingData.Timestamp.Add(time.Second)
+	err = trackingService.ProcessTrackingData(ctx, &secondData)
+	require.NoError(t, err)
+
 	// GetDailyStatisticsをテスト
 	dailyStats, err := trackingService.GetDailyStatistics(ctx, "test-app-daily-123", time.Now())
 	require.NoError(t, err)
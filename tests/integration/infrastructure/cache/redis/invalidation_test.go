@@ -0,0 +1,70 @@
+package redis_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/infrastructure/cache/redis"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// recordingCache は削除の通知を記録するテスト用のプロセス内キャッシュです
+type recordingCache struct {
+	mu      sync.Mutex
+	evicted []string
+	purged  int
+}
+
+func (c *recordingCache) EvictLocal(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evicted = append(c.evicted, keys...)
+}
+
+func (c *recordingCache) PurgeLocal() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.purged++
+}
+
+func (c *recordingCache) snapshot() ([]string, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.evicted...), c.purged
+}
+
+func TestInvalidationBus_Integration(t *testing.T) {
+	cache, cleanup, err := setupTestRedis()
+	require.NoError(t, err)
+	defer cleanup()
+
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+	bus := redis.NewInvalidationBus(cache.GetClient(), "test:app:invalidate", log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	local := &recordingCache{}
+	go bus.Subscribe(ctx, local)
+
+	// 購読を開始するとすべてのエントリを削除する
+	require.Eventually(t, func() bool {
+		_, purged := local.snapshot()
+		return purged == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, bus.Publish(ctx, "app:id:app_1", "app:apikey:hash"))
+
+	assert.Eventually(t, func() bool {
+		evicted, _ := local.snapshot()
+		return len(evicted) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	evicted, _ := local.snapshot()
+	assert.Equal(t, []string{"app:id:app_1", "app:apikey:hash"}, evicted)
+}
@@ -0,0 +1,53 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/infrastructure/cache/redis"
+)
+
+func TestUsageCounter_ReserveCommit_Integration(t *testing.T) {
+	cache, cleanup, err := setupTestRedis()
+	require.NoError(t, err)
+	defer cleanup()
+
+	ctx := context.Background()
+	period := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	counter := redis.NewUsageCounter(cache.GetClient())
+
+	t.Run("reserved events count against quota but are not listed", func(t *testing.T) {
+		used, ok, err := counter.ReserveUsage(ctx, "usage_app_a", period, 8, 10)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(8), used)
+
+		used, ok, err = counter.ReserveUsage(ctx, "usage_app_a", period, 5, 10)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, int64(8), used)
+
+		listed, err := counter.ListUsage(ctx, period)
+		require.NoError(t, err)
+		assert.NotContains(t, listed, "usage_app_a")
+	})
+
+	t.Run("commit counts only accepted events", func(t *testing.T) {
+		require.NoError(t, counter.CommitUsage(ctx, "usage_app_a", period, 8, 3))
+
+		events, err := counter.GetUsage(ctx, "usage_app_a", period)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), events)
+
+		// 確保分は解放されている
+		used, ok, err := counter.ReserveUsage(ctx, "usage_app_a", period, 7, 10)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(10), used)
+		require.NoError(t, counter.CommitUsage(ctx, "usage_app_a", period, 7, 0))
+	})
+}
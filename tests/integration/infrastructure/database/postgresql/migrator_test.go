@@ -0,0 +1,149 @@
+package postgresql_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"accesslog-tracker/deployments/database/migrations"
+	"accesslog-tracker/internal/infrastructure/database/postgresql"
+	"accesslog-tracker/tests/integration/infrastructure"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMigrationSchema はマイグレーション検証用の独立したスキーマに接続します
+func setupMigrationSchema(t *testing.T) *postgresql.Connection {
+	host := infrastructure.GetEnvOrDefault("DB_HOST", "localhost")
+	port := infrastructure.GetEnvOrDefault("DB_PORT", "18433")
+	user := infrastructure.GetEnvOrDefault("DB_USER", "postgres")
+	password := infrastructure.GetEnvOrDefault("DB_PASSWORD", "password")
+	dbname := infrastructure.GetEnvOrDefault("DB_NAME", "access_log_tracker_test")
+	baseDSN := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		host, port, user, password, dbname)
+
+	schema := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+
+	admin := postgresql.NewConnection("migration-admin")
+	require.NoError(t, admin.Connect(baseDSN))
+	_, err := admin.Exec(fmt.Sprintf("CREATE SCHEMA %s", schema))
+	require.NoError(t, err)
+
+	conn := postgresql.NewConnection("migration-test")
+	require.NoError(t, conn.Connect(baseDSN+" search_path="+schema))
+
+	t.Cleanup(func() {
+		conn.Close()
+		admin.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		admin.Close()
+	})
+
+	return conn
+}
+
+func tableExists(t *testing.T, conn *postgresql.Connection, name string) bool {
+	var exists bool
+	err := conn.GetDB().QueryRow(`SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists)
+	require.NoError(t, err)
+	return exists
+}
+
+func TestMigrator_Integration(t *testing.T) {
+	conn := setupMigrationSchema(t)
+	ctx := context.Background()
+
+	migrator, err := postgresql.NewMigrator(conn.GetDB(), migrations.FS)
+	require.NoError(t, err)
+	total := len(migrator.Migrations())
+
+	t.Run("should apply all migrations and match repository schema", func(t *testing.T) {
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Len(t, applied, total)
+
+		assert.True(t, tableExists(t, conn, "access_logs"))
+		assert.False(t, tableExists(t, conn, "tracking_data"))
+
+		var hasDescription bool
+		err = conn.GetDB().QueryRow(`
+			SELECT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = 'applications' AND column_name = 'description'
+			)`).Scan(&hasDescription)
+		require.NoError(t, err)
+		assert.True(t, hasDescription)
+	})
+
+	t.Run("should be a no-op when everything is applied", func(t *testing.T) {
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, total)
+		for _, status := range statuses {
+			assert.True(t, status.Applied)
+			assert.False(t, status.ChecksumMismatch)
+		}
+	})
+
+	t.Run("should redo the last migration", func(t *testing.T) {
+		redone, err := migrator.Redo(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, redone)
+		assert.Equal(t, int64(total), redone.Version)
+		assert.True(t, tableExists(t, conn, "access_logs"))
+	})
+
+	t.Run("should detect checksum mismatch", func(t *testing.T) {
+		_, err := conn.Exec(`UPDATE schema_migrations SET checksum = 'tampered' WHERE version = 1`)
+		require.NoError(t, err)
+
+		_, err = migrator.Up(ctx)
+		assert.ErrorIs(t, err, postgresql.ErrMigrationChecksumMismatch)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		assert.True(t, statuses[0].ChecksumMismatch)
+
+		_, err = conn.Exec(`UPDATE schema_migrations SET checksum = $1 WHERE version = 1`, migrator.Migrations()[0].Checksum)
+		require.NoError(t, err)
+	})
+
+	t.Run("should roll back all migrations", func(t *testing.T) {
+		reverted, err := migrator.Down(ctx, total)
+		require.NoError(t, err)
+		assert.Len(t, reverted, total)
+
+		assert.False(t, tableExists(t, conn, "access_logs"))
+		assert.False(t, tableExists(t, conn, "applications"))
+	})
+
+	t.Run("should serialize concurrent runs with the advisory lock", func(t *testing.T) {
+		var wg sync.WaitGroup
+		results := make([]int, 3)
+		errs := make([]error, 3)
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				applied, err := migrator.Up(ctx)
+				results[i] = len(applied)
+				errs[i] = err
+			}(i)
+		}
+		wg.Wait()
+
+		sum := 0
+		for i := range results {
+			assert.NoError(t, errs[i])
+			sum += results[i]
+		}
+		// いずれか1つの実行だけがすべてのマイグレーションを適用する
+		assert.Equal(t, total, sum)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
@@ -92,7 +93,8 @@ func TestApplicationRepository_Integration(t *testing.T) {
 		assert.Equal(t, app.AppID, retrieved.AppID)
 		assert.Equal(t, app.Name, retrieved.Name)
 		assert.Equal(t, app.Domain, retrieved.Domain)
-		assert.Equal(t, app.APIKey, retrieved.APIKey)
+		// 平文のAPIキーは保存されない
+		assert.Empty(t, retrieved.APIKey)
 		assert.Equal(t, app.Active, retrieved.Active)
 	})
 
@@ -260,7 +262,7 @@ func TestApplicationRepository_Integration(t *testing.T) {
 
 		// 同じAPIキーでの作成を試行
 		err = repo.Create(ctx, app2)
-		assert.Error(t, err) // 重複エラーが期待される
+		assert.ErrorIs(t, err, models.ErrAPIKeyAlreadyExists) // 重複エラーが期待される
 
 		// クリーンアップ
 		repo.Delete(ctx, "test_app_api_key_1")
@@ -291,35 +293,46 @@ func TestApplicationRepository_Integration(t *testing.T) {
 		}
 	})
 
-	t.Run("should handle API key regeneration", func(t *testing.T) {
+	t.Run("should handle API key rotation", func(t *testing.T) {
 		app := &models.Application{
-			AppID:    "test_app_regenerate",
-			Name:     "Regenerate Test App",
-			Domain:   "regenerate.com",
+			AppID:    "test_app_rotate",
+			Name:     "Rotate Test App",
+			Domain:   "rotate.com",
 			APIKey:   "old-api-key",
 			Active:   true,
 		}
 
 		err := repo.Create(ctx, app)
 		assert.NoError(t, err)
+		defer repo.Delete(ctx, "test_app_rotate")
+
+		// 猶予期間付きでローテーション
+		newKey := &models.APIKey{}
+		previous, err := repo.RotateAPIKey(ctx, "test_app_rotate", app.AuthenticatedKey.ID, newKey, "new-api-key", time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, app.AuthenticatedKey.ID, previous.ID)
+		assert.NotNil(t, previous.ExpiresAt)
+		assert.Equal(t, models.DefaultAPIKeyName, newKey.Name)
 
-		// APIキーを再生成
-		newAPIKey, err := repo.RegenerateAPIKey(ctx, "test_app_regenerate")
+		// 猶予期間中は新旧どちらのキーでも取得できる
+		retrieved, err := repo.GetByAPIKey(ctx, "new-api-key")
 		assert.NoError(t, err)
-		assert.NotEmpty(t, newAPIKey)
-		assert.NotEqual(t, "old-api-key", newAPIKey)
+		assert.Equal(t, newKey.ID, retrieved.AuthenticatedKey.ID)
 
-		// 新しいAPIキーでアプリケーションを取得
-		retrieved, err := repo.GetByAPIKey(ctx, newAPIKey)
+		retrieved, err = repo.GetByAPIKey(ctx, "old-api-key")
 		assert.NoError(t, err)
-		assert.Equal(t, "test_app_regenerate", retrieved.AppID)
+		assert.Equal(t, previous.ID, retrieved.AuthenticatedKey.ID)
 
-		// 古いAPIキーでは取得できないことを確認
-		_, err = repo.GetByAPIKey(ctx, "old-api-key")
+		// 猶予期間なしでローテーションすると以前のキーは即座に無効になる
+		_, err = repo.RotateAPIKey(ctx, "test_app_rotate", newKey.ID, &models.APIKey{}, "newer-api-key", time.Now())
+		assert.NoError(t, err)
+
+		_, err = repo.GetByAPIKey(ctx, "new-api-key")
 		assert.Error(t, err)
 
-		// クリーンアップ
-		repo.Delete(ctx, "test_app_regenerate")
+		// 無効になったキーはローテーションできない
+		_, err = repo.RotateAPIKey(ctx, "test_app_rotate", newKey.ID, &models.APIKey{}, "another-api-key", time.Now())
+		assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
 	})
 
 	t.Run("should handle settings update", func(t *testing.T) {
@@ -344,10 +357,82 @@ func TestApplicationRepository_Integration(t *testing.T) {
 		err = repo.UpdateSettings(ctx, "test_app_settings", settings)
 		assert.NoError(t, err)
 
+		// 指定したキーだけを上書きし、null のキーは削除する
+		err = repo.UpdateSettings(ctx, "test_app_settings", map[string]interface{}{
+			"max_requests":   2000,
+			"retention_days": nil,
+		})
+		assert.NoError(t, err)
+
+		updated, err := repo.GetByID(ctx, "test_app_settings")
+		assert.NoError(t, err)
+		assert.Equal(t, true, updated.Settings["tracking_enabled"])
+		assert.Equal(t, float64(2000), updated.Settings["max_requests"])
+		assert.NotContains(t, updated.Settings, "retention_days")
+
+		// 存在しないアプリケーション
+		err = repo.UpdateSettings(ctx, "non_existent_app", settings)
+		assert.ErrorIs(t, err, models.ErrApplicationNotFound)
+
 		// クリーンアップ
 		repo.Delete(ctx, "test_app_settings")
 	})
 
+	t.Run("should manage multiple API keys", func(t *testing.T) {
+		app := &models.Application{
+			AppID:  "test_app_multi_key",
+			Name:   "Multi Key Test App",
+			Domain: "multikey.com",
+			APIKey: "test-api-key-multi-default",
+			Active: true,
+		}
+		require.NoError(t, repo.Create(ctx, app))
+		defer repo.Delete(ctx, app.AppID)
+
+		server := &models.APIKey{AppID: app.AppID, Name: "server", Scopes: []string{models.ScopeStatsRead}}
+		require.NoError(t, repo.CreateAPIKey(ctx, server, "test-api-key-multi-server"))
+
+		expiresAt := time.Now().Add(-time.Minute)
+		expired := &models.APIKey{AppID: app.AppID, Name: "expired", ExpiresAt: &expiresAt}
+		require.NoError(t, repo.CreateAPIKey(ctx, expired, "test-api-key-multi-expired"))
+
+		keys, err := repo.ListAPIKeys(ctx, app.AppID)
+		require.NoError(t, err)
+		assert.Len(t, keys, 3)
+		// 一覧には識別用のプレフィックスのみ含まれる
+		assert.Equal(t, "test-api", keys[0].Prefix)
+		// スコープを指定しないキーは送信のみを許可する
+		assert.Equal(t, []string{models.ScopeIngest}, keys[0].Scopes)
+
+		// どのキーでも認証でき、使用したキーが設定される
+		retrieved, err := repo.GetByAPIKey(ctx, "test-api-key-multi-server")
+		require.NoError(t, err)
+		assert.Equal(t, app.AppID, retrieved.AppID)
+		assert.Equal(t, server.ID, retrieved.AuthenticatedKey.ID)
+		assert.Equal(t, []string{models.ScopeStatsRead}, retrieved.AuthenticatedKey.Scopes)
+		assert.NotNil(t, retrieved.AuthenticatedKey.LastUsedAt)
+
+		retrieved, err = repo.GetByAPIKeyID(ctx, server.ID)
+		require.NoError(t, err)
+		assert.Equal(t, app.AppID, retrieved.AppID)
+
+		// 有効期限切れのキーでは認証できない
+		_, err = repo.GetByAPIKey(ctx, "test-api-key-multi-expired")
+		assert.Error(t, err)
+		_, err = repo.GetByAPIKeyID(ctx, expired.ID)
+		assert.Error(t, err)
+
+		// 削除したキーでは認証できない
+		require.NoError(t, repo.DeleteAPIKey(ctx, app.AppID, server.ID))
+		assert.ErrorIs(t, repo.DeleteAPIKey(ctx, app.AppID, server.ID), models.ErrAPIKeyNotFound)
+		_, err = repo.GetByAPIKey(ctx, "test-api-key-multi-server")
+		assert.Error(t, err)
+
+		// 存在しないアプリケーションにはキーを追加できない
+		err = repo.CreateAPIKey(ctx, &models.APIKey{AppID: "non_existent_app", Name: "server"}, "test-api-key-multi-orphan")
+		assert.ErrorIs(t, err, models.ErrApplicationNotFound)
+	})
+
 	t.Run("should handle pagination edge cases", func(t *testing.T) {
 		// 空の結果セットでのページネーション
 		results, err := repo.List(ctx, 10, 1000) // 大きなオフセット
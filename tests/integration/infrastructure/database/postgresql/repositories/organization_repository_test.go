@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationRepository_Integration(t *testing.T) {
+	_, conn, cleanup, err := setupTestDatabase()
+	if err != nil {
+		t.Skipf("Database not available: %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := repositories.NewOrganizationRepository(conn.GetDB())
+	appRepo := repositories.NewApplicationRepository(conn.GetDB())
+	suffix := time.Now().UnixNano()
+
+	owner := &models.User{Email: fmt.Sprintf("owner_%d@example.com", suffix), Name: "Owner"}
+	viewer := &models.User{Email: fmt.Sprintf("viewer_%d@example.com", suffix), Name: "Viewer"}
+	require.NoError(t, repo.CreateUser(ctx, owner))
+	require.NoError(t, repo.CreateUser(ctx, viewer))
+
+	org := &models.Organization{Name: fmt.Sprintf("Client %d", suffix)}
+	other := &models.Organization{Name: fmt.Sprintf("Other Client %d", suffix)}
+	require.NoError(t, repo.CreateOrganization(ctx, org, owner.ID))
+	require.NoError(t, repo.CreateOrganization(ctx, other, ""))
+
+	t.Run("should reject duplicate user email", func(t *testing.T) {
+		err := repo.CreateUser(ctx, &models.User{Email: owner.Email})
+		assert.ErrorIs(t, err, models.ErrUserAlreadyExists)
+	})
+
+	t.Run("should add creator as owner", func(t *testing.T) {
+		membership, err := repo.GetMembership(ctx, org.ID, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleOwner, membership.Role)
+
+		count, err := repo.CountOwners(ctx, org.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("should add and update members", func(t *testing.T) {
+		require.NoError(t, repo.SetMember(ctx, &models.Membership{OrganizationID: org.ID, UserID: viewer.ID, Role: models.RoleAdmin}))
+		require.NoError(t, repo.SetMember(ctx, &models.Membership{OrganizationID: org.ID, UserID: viewer.ID, Role: models.RoleViewer}))
+
+		members, err := repo.ListMembers(ctx, org.ID)
+		require.NoError(t, err)
+		assert.Len(t, members, 2)
+
+		roles, err := repo.GetUserRoles(ctx, viewer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{org.ID: models.RoleViewer}, roles)
+	})
+
+	t.Run("should scope applications to user organizations", func(t *testing.T) {
+		mine := &models.Application{Name: "Client App", Domain: "client.example.com", OrganizationID: org.ID, Active: true}
+		theirs := &models.Application{Name: "Other App", Domain: "other.example.com", OrganizationID: other.ID, Active: true}
+		require.NoError(t, appRepo.Create(ctx, mine))
+		require.NoError(t, appRepo.Create(ctx, theirs))
+		defer appRepo.Delete(ctx, mine.AppID)
+		defer appRepo.Delete(ctx, theirs.AppID)
+
+		apps, err := appRepo.GetByUserID(ctx, viewer.ID, 100, 0)
+		require.NoError(t, err)
+		require.Len(t, apps, 1)
+		assert.Equal(t, mine.AppID, apps[0].AppID)
+		assert.Equal(t, org.ID, apps[0].OrganizationID)
+
+		count, err := appRepo.CountByUserID(ctx, viewer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		found, err := appRepo.GetByID(ctx, theirs.AppID)
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, found.OrganizationID)
+	})
+
+	t.Run("should reject application for unknown organization", func(t *testing.T) {
+		err := appRepo.Create(ctx, &models.Application{Name: "Orphan", Domain: "orphan.example.com", OrganizationID: "missing-org"})
+		assert.ErrorIs(t, err, models.ErrOrganizationNotFound)
+	})
+
+	t.Run("should remove members", func(t *testing.T) {
+		require.NoError(t, repo.RemoveMember(ctx, org.ID, viewer.ID))
+		assert.ErrorIs(t, repo.RemoveMember(ctx, org.ID, viewer.ID), models.ErrMembershipNotFound)
+
+		_, err := repo.GetMembership(ctx, org.ID, viewer.ID)
+		assert.ErrorIs(t, err, models.ErrMembershipNotFound)
+	})
+
+	t.Run("should return not found errors", func(t *testing.T) {
+		_, err := repo.GetOrganization(ctx, "missing-org")
+		assert.ErrorIs(t, err, models.ErrOrganizationNotFound)
+
+		_, err = repo.GetUser(ctx, "missing-user")
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+	})
+}
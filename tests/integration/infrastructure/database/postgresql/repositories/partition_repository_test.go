@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
+	"accesslog-tracker/internal/partition"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionRepository_Integration(t *testing.T) {
+	trackingRepo, conn, cleanup, err := setupTestDatabase()
+	if err != nil {
+		t.Skipf("Database not available: %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := repositories.NewPartitionRepository(conn.GetDB())
+	app := CreateTestApplication(t, conn.GetDB())
+
+	partitioned, err := repo.IsPartitioned(ctx)
+	require.NoError(t, err)
+	if !partitioned {
+		t.Skip("access_logs is not partitioned")
+	}
+
+	// 2090年のパーティションは他のテストと重ならない
+	from := time.Date(2090, 1, 1, 0, 0, 0, 0, time.UTC)
+	january := models.Partition{Name: partition.Name(from, from.AddDate(0, 1, 0)), From: from, To: from.AddDate(0, 1, 0)}
+	february := models.Partition{Name: partition.Name(january.To, january.To.AddDate(0, 1, 0)), From: january.To, To: january.To.AddDate(0, 1, 0)}
+	defer func() {
+		conn.GetDB().Exec(`DROP TABLE IF EXISTS ` + january.Name)
+		conn.GetDB().Exec(`DROP TABLE IF EXISTS ` + february.Name)
+	}()
+
+	// パーティション作成前のデータはデフォルトパーティションに入る
+	data := &models.TrackingData{
+		AppID:     app.AppID,
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0",
+		URL:       "https://example.com/",
+		SessionID: "partition_session",
+		IPAddress: "192.168.7.1",
+		Timestamp: from.Add(36 * time.Hour),
+	}
+	require.NoError(t, trackingRepo.Save(ctx, data))
+
+	t.Run("should create partitions and move rows from the default partition", func(t *testing.T) {
+		require.NoError(t, repo.CreatePartition(ctx, january))
+		require.NoError(t, repo.CreatePartition(ctx, february))
+
+		partitions, err := repo.ListPartitions(ctx)
+		require.NoError(t, err)
+		var found *models.Partition
+		for i := range partitions {
+			if partitions[i].Name == january.Name {
+				found = &partitions[i]
+			}
+		}
+		require.NotNil(t, found)
+		assert.True(t, january.From.Equal(found.From))
+		assert.True(t, january.To.Equal(found.To))
+
+		var count int
+		err = conn.GetDB().QueryRow(`SELECT COUNT(*) FROM `+january.Name+` WHERE app_id = $1`, app.AppID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		// 重なる範囲は作成できない
+		assert.Error(t, repo.CreatePartition(ctx, models.Partition{Name: "access_logs_p20900115", From: from.AddDate(0, 0, 14), To: from.AddDate(0, 0, 15)}))
+	})
+
+	t.Run("should prune partitions by timestamp", func(t *testing.T) {
+		rows, err := conn.GetDB().Query(`
+			EXPLAIN SELECT COUNT(*) FROM access_logs
+			WHERE app_id = $1 AND timestamp BETWEEN '2090-01-01T00:00:00Z' AND '2090-01-31T00:00:00Z'
+		`, app.AppID)
+		require.NoError(t, err)
+		defer rows.Close()
+
+		var plan []string
+		for rows.Next() {
+			var line string
+			require.NoError(t, rows.Scan(&line))
+			plan = append(plan, line)
+		}
+		joined := strings.Join(plan, "\n")
+		assert.Contains(t, joined, january.Name)
+		assert.NotContains(t, joined, february.Name)
+		assert.NotContains(t, joined, "access_logs_default")
+
+		stats, err := trackingRepo.GetStatsByAppID(ctx, app.AppID, january.From, january.To)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.TotalRequests)
+	})
+
+	t.Run("should detach and drop partitions", func(t *testing.T) {
+		require.NoError(t, repo.DetachPartition(ctx, january.Name))
+		require.NoError(t, repo.DropPartition(ctx, february.Name))
+
+		partitions, err := repo.ListPartitions(ctx)
+		require.NoError(t, err)
+		for _, p := range partitions {
+			assert.NotEqual(t, january.Name, p.Name)
+			assert.NotEqual(t, february.Name, p.Name)
+		}
+
+		// 切り離したパーティションのデータは access_logs から見えないがテーブルには残る
+		count, err := trackingRepo.CountByAppID(ctx, app.AppID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		var detached int
+		err = conn.GetDB().QueryRow(`SELECT COUNT(*) FROM ` + january.Name).Scan(&detached)
+		require.NoError(t, err)
+		assert.Equal(t, 1, detached)
+	})
+}
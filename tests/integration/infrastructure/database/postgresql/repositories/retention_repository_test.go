@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionRepository_Integration(t *testing.T) {
+	trackingRepo, conn, cleanup, err := setupTestDatabase()
+	if err != nil {
+		t.Skipf("Database not available: %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := repositories.NewRetentionRepository(conn.GetDB())
+	appRepo := repositories.NewApplicationRepository(conn.GetDB())
+	app := CreateTestApplication(t, conn.GetDB())
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	for i := 0; i < 5; i++ {
+		err := trackingRepo.Save(ctx, &models.TrackingData{
+			AppID:     app.AppID,
+			UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0",
+			URL:       "https://example.com/",
+			SessionID: fmt.Sprintf("retention_%d", i),
+			IPAddress: "192.168.6.1",
+			Timestamp: now.AddDate(0, 0, -i*10),
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("should list applications with retention settings", func(t *testing.T) {
+		require.NoError(t, appRepo.UpdateSettings(ctx, app.AppID, map[string]interface{}{
+			models.SettingRetentionRawDays:    15,
+			models.SettingRetentionRollupDays: 400,
+		}))
+
+		policies, err := repo.ListRetentionPolicies(ctx)
+		require.NoError(t, err)
+
+		var found *models.RetentionPolicy
+		for i := range policies {
+			if policies[i].AppID == app.AppID {
+				found = &policies[i]
+			}
+		}
+		require.NotNil(t, found)
+		assert.Equal(t, 15, found.RawDays)
+		assert.Equal(t, 400, found.RollupDays)
+	})
+
+	t.Run("should delete expired events in batches", func(t *testing.T) {
+		before := now.AddDate(0, 0, -15)
+
+		deleted, err := repo.DeleteExpiredEvents(ctx, app.AppID, before, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		deleted, err = repo.DeleteExpiredEvents(ctx, app.AppID, before, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		count, err := trackingRepo.CountByAppID(ctx, app.AppID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("should delete expired rollups", func(t *testing.T) {
+		rollupRepo := repositories.NewRollupRepository(conn.GetDB())
+		old := time.Date(2000, 4, 1, 0, 0, 0, 0, time.UTC)
+		for _, bucket := range []time.Time{old, old.AddDate(0, 0, 1)} {
+			require.NoError(t, rollupRepo.ReplaceRollups(ctx, models.RollupGranularityDay, bucket, []*models.RollupRow{{
+				AppID:       app.AppID,
+				Granularity: models.RollupGranularityDay,
+				Bucket:      bucket,
+				Dimension:   models.RollupDimensionTotal,
+				PageViews:   1,
+			}}, false))
+		}
+
+		deleted, err := repo.DeleteExpiredRollups(ctx, app.AppID, old.AddDate(0, 0, 1), 100)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("should save retention run", func(t *testing.T) {
+		err := repo.SaveRetentionRun(ctx, &models.RetentionRun{
+			AppID:          app.AppID,
+			StartedAt:      now,
+			Duration:       1500 * time.Millisecond,
+			EventsDeleted:  3,
+			RollupsDeleted: 1,
+		})
+		require.NoError(t, err)
+
+		var durationMS, eventsDeleted int64
+		err = conn.GetDB().QueryRow(
+			`SELECT duration_ms, events_deleted FROM retention_runs WHERE app_id = $1`, app.AppID,
+		).Scan(&durationMS, &eventsDeleted)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1500), durationMS)
+		assert.Equal(t, int64(3), eventsDeleted)
+	})
+}
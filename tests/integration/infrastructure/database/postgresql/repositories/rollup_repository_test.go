@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/infrastructure/database/postgresql/repositories"
+	"accesslog-tracker/internal/rollup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollupRepository_Integration(t *testing.T) {
+	trackingRepo, conn, cleanup, err := setupTestDatabase()
+	if err != nil {
+		t.Skipf("Database not available: %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := repositories.NewRollupRepository(conn.GetDB())
+	app := CreateTestApplication(t, conn.GetDB())
+
+	// 2000年のデータは他のテストと重ならない
+	base := time.Date(2000, 3, 1, 10, 0, 0, 0, time.UTC)
+	events := []struct {
+		session  string
+		url      string
+		referrer string
+		agent    string
+		offset   time.Duration
+	}{
+		{"rollup_a", "https://example.com/home", "https://www.Google.com/search?q=x", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile Safari/604.1", 0},
+		{"rollup_a", "https://example.com/about", "", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile Safari/604.1", 20 * time.Minute},
+		{"rollup_b", "https://example.com/home", "https://www.google.com/", "Googlebot/2.1", 30 * time.Minute},
+		{"rollup_c", "https://example.com/home", "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0", 90 * time.Minute},
+	}
+	for i, e := range events {
+		err := trackingRepo.Save(ctx, &models.TrackingData{
+			AppID:     app.AppID,
+			UserAgent: e.agent,
+			URL:       e.url,
+			Referrer:  e.referrer,
+			SessionID: e.session,
+			IPAddress: fmt.Sprintf("192.168.5.%d", i+1),
+			Timestamp: base.Add(e.offset),
+		})
+		require.NoError(t, err)
+	}
+
+	rollupBucket := func(granularity string, bucket time.Time) {
+		aggregator := rollup.NewAggregator(granularity, bucket)
+		require.NoError(t, repo.ScanEvents(ctx, bucket, rollup.Next(bucket, granularity), func(data *models.TrackingData) error {
+			if data.AppID != app.AppID {
+				return nil
+			}
+			return aggregator.Add(data)
+		}))
+		require.NoError(t, repo.ReplaceRollups(ctx, granularity, bucket, aggregator.Rows(), false))
+	}
+
+	t.Run("should write and read hourly rollups", func(t *testing.T) {
+		rollupBucket(models.RollupGranularityHour, base)
+		rollupBucket(models.RollupGranularityHour, base.Add(time.Hour))
+		// 再実行しても結果は変わらない
+		rollupBucket(models.RollupGranularityHour, base)
+
+		ranges := []models.RollupRange{{Granularity: models.RollupGranularityHour, Start: base, End: base.Add(2 * time.Hour)}}
+
+		totals, err := repo.GetRollupTotals(ctx, app.AppID, ranges)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), totals.PageViews)
+		assert.Equal(t, int64(1), totals.BotRequests)
+		assert.Equal(t, int64(2), totals.MobileRequests)
+
+		pages, err := repo.GetRollupGroups(ctx, app.AppID, models.RollupDimensionURL, ranges, 1)
+		require.NoError(t, err)
+		require.Len(t, pages, 1)
+		assert.Equal(t, "https://example.com/home", pages[0].Key)
+		assert.Equal(t, int64(3), pages[0].Requests)
+
+		referrers, err := repo.GetRollupGroups(ctx, app.AppID, models.RollupDimensionReferrerHost, ranges, 10)
+		require.NoError(t, err)
+		require.Len(t, referrers, 1)
+		assert.Equal(t, "www.google.com", referrers[0].Key)
+		assert.Equal(t, int64(2), referrers[0].Requests)
+
+		series, err := repo.GetRollupSeries(ctx, app.AppID, ranges)
+		require.NoError(t, err)
+		require.Len(t, series, 2)
+		assert.True(t, base.Equal(series[0].Bucket))
+		assert.Equal(t, int64(3), series[0].PageViews)
+		assert.Equal(t, int64(1), series[1].PageViews)
+	})
+
+	t.Run("should combine daily and hourly ranges", func(t *testing.T) {
+		day := rollup.Truncate(base, models.RollupGranularityDay)
+		rollupBucket(models.RollupGranularityDay, day)
+
+		totals, err := repo.GetRollupTotals(ctx, app.AppID, []models.RollupRange{
+			{Granularity: models.RollupGranularityDay, Start: day, End: day.AddDate(0, 0, 1)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), totals.PageViews)
+
+		totals, err = repo.GetRollupTotals(ctx, app.AppID, []models.RollupRange{
+			{Granularity: models.RollupGranularityDay, Start: day.AddDate(0, 0, -1), End: day},
+			{Granularity: models.RollupGranularityHour, Start: base.Add(time.Hour), End: base.Add(2 * time.Hour)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), totals.PageViews)
+	})
+
+	t.Run("should merge sketches after access logs are deleted", func(t *testing.T) {
+		ranges := []models.RollupRange{{Granularity: models.RollupGranularityHour, Start: base, End: base.Add(2 * time.Hour)}}
+		// 保持期間を過ぎた access_logs が削除されてもロールアップは残る
+		_, err := conn.GetDB().Exec(`DELETE FROM access_logs WHERE app_id = $1`, app.AppID)
+		require.NoError(t, err)
+
+		totals, err := repo.GetRollupTotals(ctx, app.AppID, ranges)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), totals.PageViews)
+		assert.Equal(t, int64(3), totals.Sessions)
+		assert.InDelta(t, 1200.0, totals.SessionSeconds, 0.001)
+
+		uniques, err := repo.GetRollupSketches(ctx, app.AppID, models.RollupDimensionTotal, []string{""}, ranges)
+		require.NoError(t, err)
+		require.Len(t, uniques, 1)
+		assert.Equal(t, int64(3), uniques[0].UniqueSessions())
+		assert.Equal(t, int64(4), uniques[0].UniqueVisitors())
+
+		// 指定した値のうちロールアップのある値だけを返す
+		pages, err := repo.GetRollupSketches(ctx, app.AppID, models.RollupDimensionURL, []string{"https://example.com/home", "https://example.com/missing"}, ranges)
+		require.NoError(t, err)
+		require.Len(t, pages, 1)
+		assert.Equal(t, "https://example.com/home", pages[0].Key)
+		assert.Equal(t, int64(3), pages[0].UniqueSessions())
+	})
+
+	t.Run("should advance the watermark without moving it backwards", func(t *testing.T) {
+		// 集計済みの終端は全体で共有されるため、元の値を退避して戻す
+		previous, err := repo.GetWatermark(ctx, models.RollupGranularityHour)
+		require.NoError(t, err)
+		defer func() {
+			if previous.IsZero() {
+				conn.GetDB().Exec(`DELETE FROM rollup_watermarks WHERE granularity = $1`, models.RollupGranularityHour)
+			} else {
+				conn.GetDB().Exec(`UPDATE rollup_watermarks SET processed_until = $2 WHERE granularity = $1`, models.RollupGranularityHour, previous)
+			}
+		}()
+		_, err = conn.GetDB().Exec(`DELETE FROM rollup_watermarks WHERE granularity = $1`, models.RollupGranularityHour)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.ReplaceRollups(ctx, models.RollupGranularityHour, base.Add(time.Hour), nil, true))
+		require.NoError(t, repo.ReplaceRollups(ctx, models.RollupGranularityHour, base, nil, true))
+
+		watermark, err := repo.GetWatermark(ctx, models.RollupGranularityHour)
+		require.NoError(t, err)
+		assert.True(t, base.Add(2*time.Hour).Equal(watermark))
+
+		// 空の結果で置き換えたバケットは削除されている
+		totals, err := repo.GetRollupTotals(ctx, app.AppID, []models.RollupRange{
+			{Granularity: models.RollupGranularityHour, Start: base, End: base.Add(2 * time.Hour)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), totals.PageViews)
+	})
+}
@@ -44,10 +44,14 @@ func CreateTestApplication(t *testing.T, db *sql.DB) *models.Application {
 	}
 
 	_, err := db.Exec(`
-		INSERT INTO applications (app_id, name, description, domain, api_key, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO applications (app_id, name, description, domain, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (app_id) DO NOTHING
-	`, app.AppID, app.Name, app.Description, app.Domain, app.APIKey, app.Active, app.CreatedAt, app.UpdatedAt)
+	`, app.AppID, app.Name, app.Description, app.Domain, app.Active, app.CreatedAt, app.UpdatedAt)
+	require.NoError(t, err)
+
+	// APIキーはハッシュ化して api_keys に保存する
+	err = repositories.NewApplicationRepository(db).CreateAPIKey(context.Background(), &models.APIKey{AppID: app.AppID, Name: models.DefaultAPIKeyName}, app.APIKey)
 	require.NoError(t, err)
 
 	return app
@@ -234,6 +238,46 @@ func TestTrackingRepository_Integration(t *testing.T) {
 		}
 	})
 
+	t.Run("should save tracking data in batch", func(t *testing.T) {
+		// テスト用アプリケーションを作成
+		appRepo := repositories.NewApplicationRepository(conn.GetDB())
+		testApp := &models.Application{
+			AppID:       "test_app_batch_" + time.Now().Format("20060102150405") + "_" + randomString(5),
+			Name:        "Test Batch Application",
+			Description: "Test application for batch insert",
+			Domain:      "example.com",
+			APIKey:      "alt_test_api_key_batch_" + time.Now().Format("20060102150405") + "_" + randomString(5),
+			Active:      true,
+		}
+		err = appRepo.Create(ctx, testApp)
+		require.NoError(t, err)
+
+		batch := make([]*models.TrackingData, 0, 25)
+		for i := 0; i < 25; i++ {
+			batch = append(batch, &models.TrackingData{
+				AppID:     testApp.AppID,
+				UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
+				URL:       fmt.Sprintf("https://example.com/batch/%d", i),
+				IPAddress: "192.168.1.100",
+				SessionID: "alt_batch_session",
+				Timestamp: time.Now(),
+				CustomParams: map[string]interface{}{
+					"index": i,
+				},
+			})
+		}
+
+		err := repo.SaveBatch(ctx, batch)
+		assert.NoError(t, err)
+		for _, data := range batch {
+			assert.NotEmpty(t, data.ID)
+		}
+
+		count, err := repo.CountByAppID(ctx, testApp.AppID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(25), count)
+	})
+
 	t.Run("should find tracking data by date range", func(t *testing.T) {
 		appID := "test_app_date_range_" + time.Now().Format("20060102150405") + "_" + randomString(5)
 		now := time.Now()
@@ -325,6 +369,156 @@ func TestTrackingRepository_Integration(t *testing.T) {
 		assert.Equal(t, int64(5), count)
 	})
 
+	t.Run("should aggregate grouped stats and session duration", func(t *testing.T) {
+		appID := "test_app_grouped_" + time.Now().Format("20060102150405") + "_" + randomString(5)
+		base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+		appRepo := repositories.NewApplicationRepository(conn.GetDB())
+		testApp := &models.Application{
+			AppID:  appID,
+			Name:   "Test Grouped Stats App",
+			Domain: "example.com",
+			APIKey: "test-api-key-grouped_" + time.Now().Format("20060102150405") + "_" + randomString(5),
+			Active: true,
+		}
+		err = appRepo.Create(ctx, testApp)
+		require.NoError(t, err)
+
+		// session_a: /home → /about（10分）、session_b: /home のみ（0分）
+		events := []struct {
+			session  string
+			url      string
+			referrer string
+			offset   time.Duration
+		}{
+			{"session_a", "https://example.com/home", "https://google.com", 0},
+			{"session_a", "https://example.com/about", "", 10 * time.Minute},
+			{"session_b", "https://example.com/home", "https://google.com", 2 * time.Hour},
+		}
+		for i, e := range events {
+			err := repo.Save(ctx, &models.TrackingData{
+				AppID:     appID,
+				UserAgent: "Mozilla/5.0",
+				URL:       e.url,
+				Referrer:  e.referrer,
+				SessionID: e.session,
+				IPAddress: fmt.Sprintf("192.168.2.%d", i+10),
+				Timestamp: base.Add(e.offset),
+			})
+			require.NoError(t, err)
+		}
+
+		start := base.Add(-time.Hour)
+		end := base.Add(24 * time.Hour)
+
+		pages, err := repo.GetGroupedStats(ctx, appID, models.StatsGroupByPage, start, end, 1)
+		require.NoError(t, err)
+		require.Len(t, pages, 1)
+		assert.Equal(t, "https://example.com/home", pages[0].Key)
+		assert.Equal(t, int64(2), pages[0].Requests)
+		assert.Equal(t, int64(2), pages[0].UniqueSessions)
+
+		// 空のリファラーは集計しない
+		referrers, err := repo.GetGroupedStats(ctx, appID, models.StatsGroupByReferrer, start, end, 10)
+		require.NoError(t, err)
+		require.Len(t, referrers, 1)
+		assert.Equal(t, "google.com", referrers[0].Key)
+
+		hours, err := repo.GetGroupedStats(ctx, appID, models.StatsGroupByHour, start, end, 0)
+		require.NoError(t, err)
+		require.Len(t, hours, 2)
+		assert.Equal(t, "2024-01-15T10:00:00Z", hours[0].Key)
+		assert.Equal(t, int64(2), hours[0].Requests)
+		assert.Equal(t, "2024-01-15T12:00:00Z", hours[1].Key)
+
+		days, err := repo.GetGroupedStats(ctx, appID, models.StatsGroupByDay, start, end, 0)
+		require.NoError(t, err)
+		require.Len(t, days, 1)
+		assert.Equal(t, "2024-01-15", days[0].Key)
+		assert.Equal(t, int64(3), days[0].Requests)
+
+		_, err = repo.GetGroupedStats(ctx, appID, "week", start, end, 0)
+		assert.ErrorIs(t, err, models.ErrStatisticsInvalidGroupBy)
+
+		duration, err := repo.GetAverageSessionDuration(ctx, appID, start, end)
+		require.NoError(t, err)
+		assert.InDelta(t, 300.0, duration, 0.001)
+
+		uniques, err := repo.GetUniqueSketches(ctx, appID, "", nil, start, end)
+		require.NoError(t, err)
+		require.Len(t, uniques, 1)
+		assert.Equal(t, int64(2), uniques[0].UniqueSessions())
+		assert.Equal(t, int64(3), uniques[0].UniqueVisitors())
+
+		// 指定したキーのうち値のあるグループだけを返す
+		pageUniques, err := repo.GetUniqueSketches(ctx, appID, models.StatsGroupByPage, []string{"https://example.com/home", "https://example.com/missing"}, start, end)
+		require.NoError(t, err)
+		require.Len(t, pageUniques, 1)
+		assert.Equal(t, "https://example.com/home", pageUniques[0].Key)
+		assert.Equal(t, int64(2), pageUniques[0].UniqueSessions())
+		assert.Equal(t, int64(2), pageUniques[0].UniqueVisitors())
+
+		// 範囲外のデータは含めない
+		count, err := repo.GetGroupedStats(ctx, appID, models.StatsGroupByDay, base.Add(time.Hour), end, 0)
+		require.NoError(t, err)
+		require.Len(t, count, 1)
+		assert.Equal(t, int64(1), count[0].Requests)
+	})
+
+	t.Run("should bucket time series in the given timezone", func(t *testing.T) {
+		appID := "test_app_series_" + time.Now().Format("20060102150405") + "_" + randomString(5)
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+
+		appRepo := repositories.NewApplicationRepository(conn.GetDB())
+		testApp := &models.Application{
+			AppID:    appID,
+			Name:     "Test Time Series App",
+			Domain:   "example.com",
+			APIKey:   "test-api-key-series_" + time.Now().Format("20060102150405") + "_" + randomString(5),
+			Active:   true,
+			Timezone: "Asia/Tokyo",
+		}
+		err = appRepo.Create(ctx, testApp)
+		require.NoError(t, err)
+
+		saved, err := appRepo.GetByID(ctx, appID)
+		require.NoError(t, err)
+		assert.Equal(t, "Asia/Tokyo", saved.Timezone)
+
+		// UTCでは1月1日だが東京では1月2日になるイベント
+		for i, ts := range []time.Time{
+			time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 17, 30, 0, 0, time.UTC),
+		} {
+			err := repo.Save(ctx, &models.TrackingData{
+				AppID:     appID,
+				UserAgent: "Mozilla/5.0",
+				URL:       "https://example.com/",
+				SessionID: "series_session",
+				IPAddress: fmt.Sprintf("192.168.3.%d", i+10),
+				Timestamp: ts,
+			})
+			require.NoError(t, err)
+		}
+
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, tokyo)
+		end := time.Date(2024, 1, 3, 0, 0, 0, 0, tokyo)
+		points, err := repo.GetTimeSeries(ctx, appID, models.TimeSeriesIntervalDay, tokyo, start, end)
+		require.NoError(t, err)
+		require.Len(t, points, 1)
+		assert.True(t, points[0].Bucket.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, tokyo)))
+		assert.Equal(t, int64(2), points[0].PageViews)
+		assert.Equal(t, int64(1), points[0].Sessions)
+		assert.Equal(t, int64(2), points[0].Visitors)
+
+		points, err = repo.GetTimeSeries(ctx, appID, models.TimeSeriesIntervalHour, tokyo, start, end)
+		require.NoError(t, err)
+		require.Len(t, points, 2)
+		assert.Equal(t, 1, points[0].Bucket.Hour())
+		assert.Equal(t, 2, points[1].Bucket.Hour())
+	})
+
 	t.Run("should delete tracking data by app ID", func(t *testing.T) {
 		appID := "test_app_delete_" + time.Now().Format("20060102150405") + "_" + randomString(5)
 
@@ -41,6 +41,9 @@ type TestTrackingData struct {
 	CustomParams map[string]interface{} `json:"custom_params,omitempty"`
 }
 
+// specAdminToken は管理API用のテストトークンです
+const specAdminToken = "spec-test-admin-token"
+
 // テストサーバーのセットアップ
 func setupSpecificationTestServer(t *testing.T) (*httptest.Server, func()) {
 	// テスト用設定
@@ -60,6 +63,9 @@ func setupSpecificationTestServer(t *testing.T) (*httptest.Server, func()) {
 			Host: "localhost",
 			Port: 16379,
 		},
+		Admin: config.AdminConfig{
+			Token: specAdminToken,
+		},
 	}
 
 	// ロガーの初期化
@@ -79,13 +85,15 @@ func setupSpecificationTestServer(t *testing.T) (*httptest.Server, func()) {
 	// リポジトリの初期化
 	appRepo := repositories.NewApplicationRepository(dbConn.GetDB())
 	trackingRepo := repositories.NewTrackingRepository(dbConn.GetDB())
+	orgRepo := repositories.NewOrganizationRepository(dbConn.GetDB())
 
 	// サービスの初期化
 	appService := services.NewApplicationService(appRepo, redisClient)
 	trackingService := services.NewTrackingService(trackingRepo)
+	orgService := services.NewOrganizationService(orgRepo)
 
 	// サーバーの作成
-	srv := server.NewServer(cfg, log, trackingService, appService, dbConn, redisClient)
+	srv := server.NewServer(cfg, log, trackingService, appService, orgService, dbConn, redisClient)
 
 	// テストサーバーを起動
 	testServer := httptest.NewServer(srv.GetRouter())
@@ -107,7 +115,7 @@ func createTestApplication(t *testing.T, serverURL string) *TestApplication {
 	}
 
 	jsonData, _ := json.Marshal(appData)
-	resp, err := http.Post(serverURL+"/v1/applications", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := sendAdminRequest("POST", serverURL+"/v1/applications", jsonData)
 	require.NoError(t, err)
 	require.Equal(t, http.StatusCreated, resp.StatusCode)
 
@@ -152,6 +160,20 @@ func sendJSONRequest(method, url string, data interface{}, apiKey string) (*http
 	return client.Do(req)
 }
 
+// 管理APIリクエストの送信ヘルパー
+func sendAdminRequest(method, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+specAdminToken)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	return client.Do(req)
+}
+
 // 1. ヘルスチェックエンドポイントのテスト
 func TestHealthCheckEndpoints(t *testing.T) {
 	server, cleanup := setupSpecificationTestServer(t)
@@ -202,7 +224,7 @@ func TestApplicationManagementAPI(t *testing.T) {
 		}
 
 		jsonData, _ := json.Marshal(appData)
-		resp, err := http.Post(server.URL+"/v1/applications", "application/json", bytes.NewBuffer(jsonData))
+		resp, err := sendAdminRequest("POST", server.URL+"/v1/applications", jsonData)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
@@ -227,7 +249,7 @@ func TestApplicationManagementAPI(t *testing.T) {
 	})
 
 	t.Run("GET /v1/applications - アプリケーション一覧取得", func(t *testing.T) {
-		resp, err := http.Get(server.URL + "/v1/applications")
+		resp, err := sendAdminRequest("GET", server.URL+"/v1/applications", nil)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -253,7 +275,7 @@ func TestApplicationManagementAPI(t *testing.T) {
 	t.Run("GET /v1/applications/{id} - アプリケーション詳細取得", func(t *testing.T) {
 		app := createTestApplication(t, server.URL)
 
-		resp, err := http.Get(server.URL + "/v1/applications/" + app.AppID)
+		resp, err := sendAdminRequest("GET", server.URL+"/v1/applications/"+app.AppID, nil)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -266,6 +288,8 @@ func TestApplicationManagementAPI(t *testing.T) {
 		assert.Equal(t, app.AppID, appInfo["app_id"])
 		assert.Equal(t, app.Name, appInfo["name"])
 		assert.Equal(t, app.Domain, appInfo["domain"])
+		// APIキーは作成時のみ返される
+		assert.Nil(t, appInfo["api_key"])
 	})
 
 	t.Run("PUT /v1/applications/{id} - アプリケーション更新", func(t *testing.T) {
@@ -277,11 +301,7 @@ func TestApplicationManagementAPI(t *testing.T) {
 		}
 
 		jsonData, _ := json.Marshal(updateData)
-		req, _ := http.NewRequest("PUT", server.URL+"/v1/applications/"+app.AppID, bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := sendAdminRequest("PUT", server.URL+"/v1/applications/"+app.AppID, jsonData)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -294,9 +314,7 @@ func TestApplicationManagementAPI(t *testing.T) {
 	t.Run("DELETE /v1/applications/{id} - アプリケーション削除", func(t *testing.T) {
 		app := createTestApplication(t, server.URL)
 
-		req, _ := http.NewRequest("DELETE", server.URL+"/v1/applications/"+app.AppID, nil)
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := sendAdminRequest("DELETE", server.URL+"/v1/applications/"+app.AppID, nil)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -555,11 +573,17 @@ func TestErrorHandling(t *testing.T) {
 
 	t.Run("無効なJSONリクエスト", func(t *testing.T) {
 		invalidJSON := []byte(`{"invalid": json}`)
-		resp, err := http.Post(server.URL+"/v1/applications", "application/json", bytes.NewBuffer(invalidJSON))
+		resp, err := sendAdminRequest("POST", server.URL+"/v1/applications", invalidJSON)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
+	t.Run("管理者認証なしのアプリケーション管理", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/v1/applications")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
 	t.Run("無効なAPIキー", func(t *testing.T) {
 		trackingData := TestTrackingData{
 			AppID:     "test_app",
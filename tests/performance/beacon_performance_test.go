@@ -7,6 +7,7 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"os"
 	"sort"
 	"sync"
 	"testing"
@@ -618,6 +619,14 @@ func calculatePercentile(latencies []time.Duration, percentile int) time.Duratio
 	return latencies[index]
 }
 
+// adminToken は管理API用のトークンを返します（TEST_ADMIN_TOKEN 未設定時は docker-compose.test.yml の値）
+func adminToken() string {
+	if token := os.Getenv("TEST_ADMIN_TOKEN"); token != "" {
+		return token
+	}
+	return "test-admin-token"
+}
+
 // createTestApplication はテスト用のアプリケーションを作成します
 func createTestApplication(t testing.TB) *Application {
 	// テストアプリケーションの作成
@@ -634,6 +643,7 @@ func createTestApplication(t testing.TB) *Application {
 	// アプリケーション作成リクエスト
 	req, _ := http.NewRequest("POST", baseURL+"/v1/applications", bytes.NewBuffer(jsonData))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+adminToken())
 	
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
@@ -689,6 +699,7 @@ func createTestApplication(t testing.TB) *Application {
 // cleanupTestApplication はテスト用のアプリケーションを削除します
 func cleanupTestApplication(t testing.TB, appID string) {
 	req, _ := http.NewRequest("DELETE", baseURL+"/v1/applications/"+appID, nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken())
 	
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
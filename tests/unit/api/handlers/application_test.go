@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -19,8 +20,17 @@ import (
 )
 
 func setupTest() (*gin.Engine, *MockApplicationService, *MockLogger, *handlers.ApplicationHandler) {
+	return setupTestWithPrincipal(&domainmodels.Principal{Superuser: true})
+}
+
+// setupTestWithPrincipal は管理API認証ミドルウェアが principal を設定した状態のルーターを作成します
+func setupTestWithPrincipal(principal *domainmodels.Principal) (*gin.Engine, *MockApplicationService, *MockLogger, *handlers.ApplicationHandler) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("principal", principal)
+		c.Next()
+	})
 
 	mockService := new(MockApplicationService)
 	mockLogger := new(MockLogger)
@@ -148,6 +158,9 @@ func TestApplicationHandler_Get_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, response.Success)
 
+	// 作成時以外はAPIキーを返さない
+	assert.NotContains(t, w.Body.String(), "test-api-key")
+
 	mockService.AssertExpectations(t)
 }
 
@@ -292,6 +305,88 @@ func TestApplicationHandler_Update_NotFound(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestApplicationHandler_UpdateSettings_Success(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTest()
+
+	settings := map[string]interface{}{
+		domainmodels.SettingRetentionRawDays:    float64(90),
+		domainmodels.SettingRetentionRollupDays: nil,
+	}
+
+	mockService.On("UpdateSettings", mock.Anything, "test-app-id", settings).Return(nil)
+	mockService.On("GetByID", mock.Anything, "test-app-id").Return(&domainmodels.Application{
+		AppID:    "test-app-id",
+		Name:     "Test App",
+		Domain:   "test.com",
+		APIKey:   "test-api-key",
+		Settings: map[string]interface{}{domainmodels.SettingRetentionRawDays: float64(90)},
+	}, nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything)
+
+	jsonBody, _ := json.Marshal(settings)
+	req := httptest.NewRequest("PUT", "/applications/test-app-id/settings", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.PUT("/applications/:id/settings", handler.UpdateSettings)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                          `json:"success"`
+		Data    apimodels.ApplicationResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, float64(90), response.Data.Settings[domainmodels.SettingRetentionRawDays])
+
+	mockService.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestApplicationHandler_UpdateSettings_InvalidSettings(t *testing.T) {
+	router, mockService, _, handler := setupTest()
+
+	mockService.On("UpdateSettings", mock.Anything, "test-app-id", mock.Anything).
+		Return(fmt.Errorf("%w: retention_raw_days must be an integer", domainmodels.ErrApplicationInvalidSettings))
+
+	req := httptest.NewRequest("PUT", "/applications/test-app-id/settings", bytes.NewBufferString(`{"retention_raw_days": -1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.PUT("/applications/:id/settings", handler.UpdateSettings)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response apimodels.APIResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_UpdateSettings_NotFound(t *testing.T) {
+	router, mockService, _, handler := setupTest()
+
+	mockService.On("UpdateSettings", mock.Anything, "non-existent", mock.Anything).Return(domainmodels.ErrApplicationNotFound)
+
+	req := httptest.NewRequest("PUT", "/applications/non-existent/settings", bytes.NewBufferString(`{"retention_raw_days": 30}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.PUT("/applications/:id/settings", handler.UpdateSettings)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
 func TestApplicationHandler_List_Success(t *testing.T) {
 	router, mockService, _, handler := setupTest()
 
@@ -334,6 +429,10 @@ func TestApplicationHandler_List_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, response.Success)
 
+	// 一覧ではAPIキーを返さない
+	assert.NotContains(t, w.Body.String(), "api_key")
+	assert.NotContains(t, w.Body.String(), "key1")
+
 	mockService.AssertExpectations(t)
 }
 
@@ -473,3 +572,642 @@ func TestApplicationHandler_Delete_ServiceError(t *testing.T) {
 	mockService.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
+
+func TestApplicationHandler_MissingPrincipal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockService := new(MockApplicationService)
+	handler := handlers.NewApplicationHandler(mockService, new(MockLogger))
+
+	req := httptest.NewRequest("GET", "/applications", nil)
+	w := httptest.NewRecorder()
+
+	router.GET("/applications", handler.List)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApplicationHandler_List_ScopedToOrganizations(t *testing.T) {
+	router, mockService, _, handler := setupTestWithPrincipal(&domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleViewer},
+	})
+
+	apps := []*domainmodels.Application{
+		{AppID: "app1", Name: "App 1", Domain: "app1.com", OrganizationID: "org-1"},
+	}
+	mockService.On("GetByUserID", mock.Anything, "user-1", 10, 0).Return(apps, nil)
+	mockService.On("CountByUserID", mock.Anything, "user-1").Return(int64(1), nil)
+
+	req := httptest.NewRequest("GET", "/applications", nil)
+	w := httptest.NewRecorder()
+
+	router.GET("/applications", handler.List)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"organization_id":"org-1"`)
+
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApplicationHandler_Get_OtherOrganization(t *testing.T) {
+	router, mockService, _, handler := setupTestWithPrincipal(&domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleOwner},
+	})
+
+	// 他の組織のアプリケーションは存在を明かさない
+	app := &domainmodels.Application{AppID: "test-app-id", Name: "Other App", OrganizationID: "org-2"}
+	mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+
+	req := httptest.NewRequest("GET", "/applications/test-app-id", nil)
+	w := httptest.NewRecorder()
+
+	router.GET("/applications/:id", handler.Get)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "Other App")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_Update_ViewerForbidden(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTestWithPrincipal(&domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleViewer},
+	})
+
+	existingApp := &domainmodels.Application{AppID: "test-app-id", Name: "App", Domain: "app.com", OrganizationID: "org-1"}
+	mockService.On("GetByID", mock.Anything, "test-app-id").Return(existingApp, nil)
+	mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	jsonBody, _ := json.Marshal(apimodels.ApplicationUpdateRequest{Name: "Renamed"})
+	req := httptest.NewRequest("PUT", "/applications/test-app-id", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.PUT("/applications/:id", handler.Update)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestApplicationHandler_Delete_OtherOrganization(t *testing.T) {
+	router, mockService, _, handler := setupTestWithPrincipal(&domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleAdmin},
+	})
+
+	app := &domainmodels.Application{AppID: "test-app-id", OrganizationID: "org-2"}
+	mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+
+	req := httptest.NewRequest("DELETE", "/applications/test-app-id", nil)
+	w := httptest.NewRecorder()
+
+	router.DELETE("/applications/:id", handler.Delete)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestApplicationHandler_Create_OrganizationRole(t *testing.T) {
+	principal := &domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleAdmin, "org-2": domainmodels.RoleViewer},
+	}
+
+	tests := []struct {
+		name           string
+		organizationID string
+		expectedStatus int
+	}{
+		{name: "organization is required", organizationID: "", expectedStatus: http.StatusBadRequest},
+		{name: "viewer cannot create", organizationID: "org-2", expectedStatus: http.StatusForbidden},
+		{name: "non-member cannot create", organizationID: "org-3", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService, mockLogger, handler := setupTestWithPrincipal(principal)
+			mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+
+			jsonBody, _ := json.Marshal(apimodels.ApplicationRequest{
+				Name:           "Test App",
+				Domain:         "test.com",
+				OrganizationID: tt.organizationID,
+			})
+			req := httptest.NewRequest("POST", "/applications", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.POST("/applications", handler.Create)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestApplicationHandler_CreateAPIKey_Success(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTest()
+
+	key := &domainmodels.APIKey{ID: "key-1", AppID: "test-app-id", Name: "server", Prefix: "abcdefgh", Scopes: []string{domainmodels.ScopeIngest}, CreatedAt: time.Now()}
+	mockService.On("CreateAPIKey", mock.Anything, "test-app-id", "server", []string{domainmodels.ScopeIngest}, (*time.Time)(nil), false).Return(key, "abcdefgh_raw_key_value", nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	jsonBody, _ := json.Marshal(apimodels.APIKeyRequest{Name: "server", Scopes: []string{domainmodels.ScopeIngest}})
+	req := httptest.NewRequest("POST", "/applications/test-app-id/keys", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.POST("/applications/:id/keys", handler.CreateAPIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data apimodels.APIKeyResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "key-1", response.Data.ID)
+	assert.Equal(t, "abcdefgh_raw_key_value", response.Data.Key)
+	assert.Equal(t, []string{domainmodels.ScopeIngest}, response.Data.Scopes)
+	assert.False(t, response.Data.Signed)
+	assert.Empty(t, response.Data.SigningSecret)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_CreateAPIKey_Signed(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTest()
+
+	key := &domainmodels.APIKey{ID: "key-1", AppID: "test-app-id", Name: "server", Prefix: "abcdefgh", SigningSecret: "whsec_test", CreatedAt: time.Now()}
+	mockService.On("CreateAPIKey", mock.Anything, "test-app-id", "server", []string(nil), (*time.Time)(nil), true).Return(key, "abcdefgh_raw_key_value", nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	jsonBody, _ := json.Marshal(apimodels.APIKeyRequest{Name: "server", Signed: true})
+	req := httptest.NewRequest("POST", "/applications/test-app-id/keys", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.POST("/applications/:id/keys", handler.CreateAPIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data apimodels.APIKeyResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Signed)
+	assert.Equal(t, "whsec_test", response.Data.SigningSecret)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_GenerateSigningSecret(t *testing.T) {
+	t.Run("should return new secret", func(t *testing.T) {
+		router, mockService, mockLogger, handler := setupTest()
+
+		mockService.On("GenerateSigningSecret", mock.Anything, "test-app-id", "key-1").Return("whsec_new", nil)
+		mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		req := httptest.NewRequest("POST", "/applications/test-app-id/keys/key-1/signing-secret", nil)
+		w := httptest.NewRecorder()
+
+		router.POST("/applications/:id/keys/:key_id/signing-secret", handler.GenerateSigningSecret)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response struct {
+			Data apimodels.SigningSecretResponse `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "key-1", response.Data.KeyID)
+		assert.Equal(t, "whsec_new", response.Data.SigningSecret)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return not found for unknown key", func(t *testing.T) {
+		router, mockService, _, handler := setupTest()
+
+		mockService.On("GenerateSigningSecret", mock.Anything, "test-app-id", "missing").Return("", domainmodels.ErrAPIKeyNotFound)
+
+		req := httptest.NewRequest("POST", "/applications/test-app-id/keys/missing/signing-secret", nil)
+		w := httptest.NewRecorder()
+
+		router.POST("/applications/:id/keys/:key_id/signing-secret", handler.GenerateSigningSecret)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestApplicationHandler_DeleteSigningSecret(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTest()
+
+	mockService.On("DeleteSigningSecret", mock.Anything, "test-app-id", "key-1").Return(nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	req := httptest.NewRequest("DELETE", "/applications/test-app-id/keys/key-1/signing-secret", nil)
+	w := httptest.NewRecorder()
+
+	router.DELETE("/applications/:id/keys/:key_id/signing-secret", handler.DeleteSigningSecret)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_CreateAPIKey_InvalidExpiry(t *testing.T) {
+	router, mockService, _, handler := setupTest()
+
+	mockService.On("CreateAPIKey", mock.Anything, "test-app-id", "server", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, "", domainmodels.NewValidationError(domainmodels.ErrAPIKeyInvalidExpiry))
+
+	past := time.Now().Add(-time.Hour)
+	jsonBody, _ := json.Marshal(apimodels.APIKeyRequest{Name: "server", ExpiresAt: &past})
+	req := httptest.NewRequest("POST", "/applications/test-app-id/keys", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.POST("/applications/:id/keys", handler.CreateAPIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_ListAPIKeys_ViewerAllowed(t *testing.T) {
+	router, mockService, _, handler := setupTestWithPrincipal(&domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleViewer},
+	})
+
+	app := &domainmodels.Application{AppID: "test-app-id", OrganizationID: "org-1"}
+	keys := []*domainmodels.APIKey{{ID: "key-1", Name: "default", Prefix: "abcdefgh"}}
+	mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+	mockService.On("ListAPIKeys", mock.Anything, "test-app-id").Return(keys, nil)
+
+	req := httptest.NewRequest("GET", "/applications/test-app-id/keys", nil)
+	w := httptest.NewRecorder()
+
+	router.GET("/applications/:id/keys", handler.ListAPIKeys)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "abcdefgh")
+	assert.NotContains(t, w.Body.String(), `"key"`)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_CreateAPIKey_ViewerForbidden(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTestWithPrincipal(&domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleViewer},
+	})
+
+	app := &domainmodels.Application{AppID: "test-app-id", OrganizationID: "org-1"}
+	mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+	mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	jsonBody, _ := json.Marshal(apimodels.APIKeyRequest{Name: "server"})
+	req := httptest.NewRequest("POST", "/applications/test-app-id/keys", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.POST("/applications/:id/keys", handler.CreateAPIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApplicationHandler_DeleteAPIKey_NotFound(t *testing.T) {
+	router, mockService, _, handler := setupTest()
+
+	mockService.On("DeleteAPIKey", mock.Anything, "test-app-id", "missing").Return(domainmodels.ErrAPIKeyNotFound)
+
+	req := httptest.NewRequest("DELETE", "/applications/test-app-id/keys/missing", nil)
+	w := httptest.NewRecorder()
+
+	router.DELETE("/applications/:id/keys/:key_id", handler.DeleteAPIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_RotateAPIKey_Success(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTest()
+
+	graceUntil := time.Now().Add(time.Hour)
+	rotation := &domainmodels.APIKeyRotation{
+		Key:      &domainmodels.APIKey{ID: "key-2", AppID: "test-app-id", Name: "server", Prefix: "ijklmnop", CreatedAt: time.Now()},
+		Secret:   "ijklmnop_raw_key_value",
+		Previous: &domainmodels.APIKey{ID: "key-1", AppID: "test-app-id", Name: "server", Prefix: "abcdefgh", ExpiresAt: &graceUntil},
+	}
+	gracePeriod := time.Hour
+	mockService.On("RotateAPIKey", mock.Anything, "test-app-id", "key-1", &gracePeriod).Return(rotation, nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	req := httptest.NewRequest("POST", "/applications/test-app-id/keys/key-1/rotate", bytes.NewBufferString(`{"grace_period_seconds":3600}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.POST("/applications/:id/keys/:key_id/rotate", handler.RotateAPIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data apimodels.APIKeyRotationResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "key-2", response.Data.ID)
+	assert.Equal(t, "ijklmnop_raw_key_value", response.Data.Key)
+	assert.Equal(t, "key-1", response.Data.PreviousKey.ID)
+	assert.Empty(t, response.Data.PreviousKey.Key)
+	assert.NotNil(t, response.Data.PreviousKey.ExpiresAt)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_RotateAPIKey_DefaultGracePeriod(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTest()
+
+	rotation := &domainmodels.APIKeyRotation{
+		Key:      &domainmodels.APIKey{ID: "key-2"},
+		Secret:   "ijklmnop_raw_key_value",
+		Previous: &domainmodels.APIKey{ID: "key-1"},
+	}
+	mockService.On("RotateAPIKey", mock.Anything, "test-app-id", "key-1", (*time.Duration)(nil)).Return(rotation, nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// ボディを省略した場合はサーバーの設定値を使う
+	req := httptest.NewRequest("POST", "/applications/test-app-id/keys/key-1/rotate", nil)
+	w := httptest.NewRecorder()
+
+	router.POST("/applications/:id/keys/:key_id/rotate", handler.RotateAPIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_RotateAPIKey_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"invalid grace period", domainmodels.NewValidationError(domainmodels.ErrAPIKeyInvalidGracePeriod), http.StatusBadRequest},
+		{"key not found", domainmodels.ErrAPIKeyNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService, _, handler := setupTest()
+
+			mockService.On("RotateAPIKey", mock.Anything, "test-app-id", "key-1", mock.Anything).Return(nil, tt.err)
+
+			req := httptest.NewRequest("POST", "/applications/test-app-id/keys/key-1/rotate", bytes.NewBufferString(`{"grace_period_seconds":-1}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.POST("/applications/:id/keys/:key_id/rotate", handler.RotateAPIKey)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestApplicationHandler_APIKeyPrincipal(t *testing.T) {
+	principal := &domainmodels.Principal{AppID: "test-app-id"}
+
+	t.Run("should manage own application", func(t *testing.T) {
+		router, mockService, _, handler := setupTestWithPrincipal(principal)
+
+		keys := []*domainmodels.APIKey{{ID: "key-1", Name: "default", Prefix: "abcdefgh"}}
+		mockService.On("ListAPIKeys", mock.Anything, "test-app-id").Return(keys, nil)
+
+		req := httptest.NewRequest("GET", "/applications/test-app-id/keys", nil)
+		w := httptest.NewRecorder()
+
+		router.GET("/applications/:id/keys", handler.ListAPIKeys)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should hide other applications", func(t *testing.T) {
+		router, mockService, _, handler := setupTestWithPrincipal(principal)
+
+		req := httptest.NewRequest("DELETE", "/applications/other-app-id", nil)
+		w := httptest.NewRecorder()
+
+		router.DELETE("/applications/:id", handler.Delete)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should list only own application", func(t *testing.T) {
+		router, mockService, _, handler := setupTestWithPrincipal(principal)
+
+		app := &domainmodels.Application{AppID: "test-app-id", Name: "Test App"}
+		mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+
+		req := httptest.NewRequest("GET", "/applications", nil)
+		w := httptest.NewRecorder()
+
+		router.GET("/applications", handler.List)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"app_id":"test-app-id"`)
+		assert.Contains(t, w.Body.String(), `"total":1`)
+		mockService.AssertNotCalled(t, "GetByUserID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestApplicationHandler_UpdatePlan(t *testing.T) {
+	t.Run("should change plan as superuser", func(t *testing.T) {
+		router, mockService, mockLogger, handler := setupTest()
+
+		mockService.On("UpdatePlan", mock.Anything, "test-app-id", domainmodels.PlanPro).Return(nil)
+		mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		jsonBody, _ := json.Marshal(apimodels.PlanRequest{Plan: domainmodels.PlanPro})
+		req := httptest.NewRequest("PUT", "/applications/test-app-id/plan", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.PUT("/applications/:id/plan", handler.UpdatePlan)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"monthly_event_quota":20000000`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should reject unknown plan", func(t *testing.T) {
+		router, mockService, _, handler := setupTest()
+
+		mockService.On("UpdatePlan", mock.Anything, "test-app-id", "platinum").
+			Return(domainmodels.NewValidationError(domainmodels.ErrApplicationInvalidPlan))
+
+		jsonBody, _ := json.Marshal(apimodels.PlanRequest{Plan: "platinum"})
+		req := httptest.NewRequest("PUT", "/applications/test-app-id/plan", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.PUT("/applications/:id/plan", handler.UpdatePlan)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should forbid organization owner", func(t *testing.T) {
+		router, mockService, _, handler := setupTestWithPrincipal(&domainmodels.Principal{
+			UserID: "user-1",
+			Roles:  map[string]string{"org-1": domainmodels.RoleOwner},
+		})
+
+		app := &domainmodels.Application{AppID: "test-app-id", OrganizationID: "org-1"}
+		mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+
+		jsonBody, _ := json.Marshal(apimodels.PlanRequest{Plan: domainmodels.PlanEnterprise})
+		req := httptest.NewRequest("PUT", "/applications/test-app-id/plan", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.PUT("/applications/:id/plan", handler.UpdatePlan)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "UpdatePlan", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestApplicationHandler_GetUsage(t *testing.T) {
+	router, mockService, _, handler := setupTestWithPrincipal(&domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleViewer},
+	})
+
+	app := &domainmodels.Application{AppID: "test-app-id", OrganizationID: "org-1", Plan: domainmodels.PlanFree}
+	period := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+	mockService.On("GetUsage", mock.Anything, "test-app-id").Return(domainmodels.NewUsage("test-app-id", app.PlanLimits(), period, 250000), nil)
+
+	req := httptest.NewRequest("GET", "/applications/test-app-id/usage", nil)
+	w := httptest.NewRecorder()
+
+	router.GET("/applications/:id/usage", handler.GetUsage)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data apimodels.UsageResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, domainmodels.PlanFree, response.Data.Plan.Name)
+	assert.Equal(t, int64(250000), response.Data.Events)
+	assert.Equal(t, int64(750000), response.Data.Remaining)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), response.Data.PeriodEnd.UTC())
+	mockService.AssertExpectations(t)
+}
+
+func TestApplicationHandler_PreviewURLRules(t *testing.T) {
+	router, mockService, _, handler := setupTestWithPrincipal(&domainmodels.Principal{
+		UserID: "user-1",
+		Roles:  map[string]string{"org-1": domainmodels.RoleViewer},
+	})
+	router.POST("/applications/:id/url-rules/preview", handler.PreviewURLRules)
+
+	app := &domainmodels.Application{
+		AppID:          "test-app-id",
+		OrganizationID: "org-1",
+		Settings: map[string]interface{}{
+			domainmodels.SettingURLRules: map[string]interface{}{
+				"strip_query": true,
+				"path_groups": []interface{}{"/product/:id"},
+			},
+		},
+	}
+	mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+
+	preview := func(body string) (*httptest.ResponseRecorder, apimodels.URLRulesPreviewResponse) {
+		req := httptest.NewRequest("POST", "/applications/test-app-id/url-rules/preview", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Data apimodels.URLRulesPreviewResponse `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response.Data
+	}
+
+	t.Run("saved rules", func(t *testing.T) {
+		w, data := preview(`{"urls": ["https://Example.com/product/123?ref=a#x", "https://example.com/about/"]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, data.URLRules.StripQuery)
+		assert.Equal(t, []string{}, data.URLRules.QueryAllowlist)
+		assert.Equal(t, []apimodels.URLRulesPreviewResult{
+			{URL: "https://Example.com/product/123?ref=a#x", PagePath: "https://example.com/product/123", PageGroup: "/product/:id"},
+			{URL: "https://example.com/about/", PagePath: "https://example.com/about/", PageGroup: "/about/"},
+		}, data.Results)
+	})
+
+	t.Run("rules in the request are not saved", func(t *testing.T) {
+		w, data := preview(`{"urls": ["https://example.com/about/?page=2&ref=a"], "url_rules": {"strip_query": true, "query_allowlist": ["page"], "trailing_slash": "strip"}}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "strip", data.URLRules.TrailingSlash)
+		assert.Equal(t, "https://example.com/about?page=2", data.Results[0].PagePath)
+		mockService.AssertNotCalled(t, "UpdateSettings", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		w, _ := preview(`{"urls": ["https://example.com/"], "url_rules": {"trailing_slash": "sometimes"}}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w, _ = preview(`{"urls": ["https://example.com/"], "url_rules": {"unknown": true}}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid urls", func(t *testing.T) {
+		w, _ := preview(`{"urls": []}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		urls := make([]string, apimodels.MaxURLRulesPreviewURLs+1)
+		for i := range urls {
+			urls[i] = fmt.Sprintf("https://example.com/%d", i)
+		}
+		body, _ := json.Marshal(map[string]interface{}{"urls": urls})
+		w, _ = preview(string(body))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
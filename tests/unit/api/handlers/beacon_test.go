@@ -1,23 +1,48 @@
-package handlers_test
+package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"accesslog-tracker/internal/api/handlers"
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/logger"
 )
 
+// newBeaconHandler はヒットを保存しない（未登録アプリ扱いの）ビーコンハンドラーを作成します
+func newBeaconHandler() *handlers.BeaconHandler {
+	handler, _, mockAppService := setupBeaconTest()
+	mockAppService.On("GetByID", mock.Anything, mock.Anything).Return(nil, models.ErrApplicationNotFound).Maybe()
+	return handler
+}
+
+func setupBeaconTest() (*handlers.BeaconHandler, *MockTrackingService, *MockApplicationService) {
+	gin.SetMode(gin.TestMode)
+
+	mockTrackingService := new(MockTrackingService)
+	mockAppService := new(MockApplicationService)
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+
+	handler := handlers.NewBeaconHandler(mockTrackingService, mockAppService, log)
+	return handler, mockTrackingService, mockAppService
+}
+
 func TestBeaconHandler_Serve(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	t.Run("should serve JavaScript beacon", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.js", handler.Serve)
 
 		req := httptest.NewRequest("GET", "/tracker.js", nil)
@@ -32,7 +57,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve JavaScript beacon with ETag", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.js", handler.Serve)
 
 		req := httptest.NewRequest("GET", "/tracker.js", nil)
@@ -47,7 +72,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle JavaScript beacon with invalid HTTP method", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/tracker.js", handler.Serve)
 
 		req := httptest.NewRequest("POST", "/tracker.js", nil)
@@ -61,7 +86,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve JavaScript beacon with query parameters", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.js", handler.Serve)
 
 		req := httptest.NewRequest("GET", "/tracker.js?v=1.0.0&debug=true", nil)
@@ -75,7 +100,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve JavaScript beacon with Accept-Encoding header", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.js", handler.Serve)
 
 		req := httptest.NewRequest("GET", "/tracker.js", nil)
@@ -90,7 +115,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve minified JavaScript beacon", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.min.js", handler.ServeMinified)
 
 		req := httptest.NewRequest("GET", "/tracker.min.js", nil)
@@ -105,7 +130,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve minified JavaScript beacon with ETag", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.min.js", handler.ServeMinified)
 
 		req := httptest.NewRequest("GET", "/tracker.min.js", nil)
@@ -120,7 +145,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle minified beacon with invalid HTTP method", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/tracker.min.js", handler.ServeMinified)
 
 		req := httptest.NewRequest("POST", "/tracker.min.js", nil)
@@ -134,7 +159,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve minified beacon with query parameters", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.min.js", handler.ServeMinified)
 
 		req := httptest.NewRequest("GET", "/tracker.min.js?v=1.0.0", nil)
@@ -148,7 +173,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve custom JavaScript beacon", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker/:app_id.js", handler.ServeCustom)
 
 		req := httptest.NewRequest("GET", "/tracker/test_app_123.js", nil)
@@ -164,7 +189,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve custom JavaScript beacon with valid app_id", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker/:app_id.js", handler.ServeCustom)
 
 		req := httptest.NewRequest("GET", "/tracker/valid_app_123.js", nil)
@@ -179,7 +204,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle custom beacon with empty app_id", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker/:app_id.js", handler.ServeCustom)
 
 		req := httptest.NewRequest("GET", "/tracker/.js", nil)
@@ -193,7 +218,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle custom beacon with invalid app_id format", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker/:app_id.js", handler.ServeCustom)
 
 		req := httptest.NewRequest("GET", "/tracker/invalid_app_id.js", nil)
@@ -207,7 +232,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve GIF beacon", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.gif", handler.ServeGIF)
 
 		req := httptest.NewRequest("GET", "/tracker.gif?app_id=test_app_123", nil)
@@ -221,7 +246,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve GIF beacon without app_id", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.gif", handler.ServeGIF)
 
 		req := httptest.NewRequest("GET", "/tracker.gif", nil)
@@ -235,7 +260,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should serve GIF beacon with custom parameters", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/tracker.gif", handler.ServeGIF)
 
 		req := httptest.NewRequest("GET", "/tracker.gif?app_id=test_app_123&session_id=test_session&url=https://example.com", nil)
@@ -249,7 +274,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle GIF beacon with invalid HTTP method", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/tracker.gif", handler.ServeGIF)
 
 		req := httptest.NewRequest("POST", "/tracker.gif?app_id=test_app_123", nil)
@@ -263,7 +288,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should generate beacon with config", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/v1/beacon/generate", handler.GenerateBeaconWithConfig)
 
 		configJSON := `{
@@ -284,7 +309,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle invalid JSON in beacon config", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/v1/beacon/generate", handler.GenerateBeaconWithConfig)
 
 		req := httptest.NewRequest("POST", "/v1/beacon/generate", strings.NewReader("invalid json"))
@@ -298,7 +323,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle beacon config with missing endpoint", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/v1/beacon/generate", handler.GenerateBeaconWithConfig)
 
 		configJSON := `{
@@ -317,7 +342,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle beacon config with empty endpoint", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/v1/beacon/generate", handler.GenerateBeaconWithConfig)
 
 		configJSON := `{
@@ -337,7 +362,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle beacon config with custom parameters", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/v1/beacon/generate", handler.GenerateBeaconWithConfig)
 
 		configJSON := `{
@@ -361,7 +386,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should return health status", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/v1/beacon/health", handler.Health)
 
 		req := httptest.NewRequest("GET", "/v1/beacon/health", nil)
@@ -375,7 +400,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle health check with invalid method", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/v1/beacon/health", handler.Health)
 
 		req := httptest.NewRequest("POST", "/v1/beacon/health", nil)
@@ -389,7 +414,7 @@ func TestBeaconHandler_Serve(t *testing.T) {
 
 	t.Run("should handle health check with query parameters", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/v1/beacon/health", handler.Health)
 
 		req := httptest.NewRequest("GET", "/v1/beacon/health?format=json", nil)
@@ -407,7 +432,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should generate beacon with app_id", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil)
@@ -421,7 +446,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle missing app_id", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon", nil)
@@ -433,7 +458,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should generate beacon with custom parameters", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&session_id=test_session&url=https://example.com", nil)
@@ -446,7 +471,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with referrer parameter", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&referrer=https://google.com", nil)
@@ -459,7 +484,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with user agent parameter", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&user_agent=Mozilla/5.0", nil)
@@ -473,7 +498,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with IP address parameter", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&ip_address=192.168.1.1", nil)
@@ -486,7 +511,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with X-Forwarded-For header", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil)
@@ -500,7 +525,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with X-Real-IP header", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil)
@@ -514,7 +539,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with multiple X-Forwarded-For IPs", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil)
@@ -528,7 +553,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with empty parameters", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&session_id=&referrer=", nil)
@@ -541,7 +566,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with encoded URL parameters", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&url=https%3A//example.com%3Fparam%3Dvalue", nil)
@@ -554,7 +579,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle invalid HTTP method for beacon", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.POST("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("POST", "/beacon?app_id=test_app_123", nil)
@@ -568,7 +593,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with invalid app_id format", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=invalid_app_id", nil)
@@ -581,7 +606,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with very long app_id", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		longAppID := strings.Repeat("a", 1000)
@@ -595,7 +620,7 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 
 	t.Run("should handle beacon with special characters in app_id", func(t *testing.T) {
 		router := gin.New()
-		handler := handlers.NewBeaconHandler()
+		handler := newBeaconHandler()
 		router.GET("/beacon", handler.GenerateBeacon)
 
 		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123%20with%20spaces", nil)
@@ -606,3 +631,345 @@ func TestBeaconHandler_GenerateBeacon(t *testing.T) {
 		assert.NotEmpty(t, w.Body.Bytes())
 	})
 }
+
+func TestBeaconHandler_RecordHit(t *testing.T) {
+	const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+	activeApp := &models.Application{AppID: "test_app_123", Active: true}
+
+	t.Run("should persist hit through tracking service after writing GIF", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		release := make(chan struct{})
+		var saved *models.TrackingData
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(activeApp, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, mock.Anything, 1).Return(nil, nil)
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.AnythingOfType("*models.TrackingData")).
+			Run(func(args mock.Arguments) {
+				<-release
+				saved = args.Get(1).(*models.TrackingData)
+			}).
+			Return(nil).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&session_id=sess_1&referrer=https://google.com&campaign=spring", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Referer", "https://example.com/landing")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// 保存処理がブロックされていてもGIFは返却済み
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/gif", w.Header().Get("Content-Type"))
+
+		close(release)
+		handler.Wait()
+
+		if assert.NotNil(t, saved) {
+			assert.Equal(t, "test_app_123", saved.AppID)
+			assert.Equal(t, "sess_1", saved.SessionID)
+			assert.Equal(t, "https://example.com/landing", saved.URL)
+			assert.Equal(t, "https://google.com", saved.Referrer)
+			assert.Equal(t, userAgent, saved.UserAgent)
+			assert.Equal(t, "192.0.2.1", saved.IPAddress)
+			assert.Equal(t, map[string]interface{}{"campaign": "spring"}, saved.CustomParams)
+			assert.False(t, saved.Timestamp.IsZero())
+		}
+		assert.Equal(t, int64(1), handler.Stats().Accepted)
+		mockAppService.AssertExpectations(t)
+		mockTrackingService.AssertExpectations(t)
+	})
+
+	t.Run("should prefer url query parameter over Referer header", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/v1/beacon/generate", handler.GenerateBeacon)
+
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(activeApp, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, mock.Anything, 1).Return(nil, nil)
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.MatchedBy(func(data *models.TrackingData) bool {
+			return data.URL == "https://example.com/page"
+		})).Return(nil).Once()
+
+		req := httptest.NewRequest("GET", "/v1/beacon/generate?app_id=test_app_123&url=https://example.com/page", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Referer", "https://example.com/other")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTrackingService.AssertExpectations(t)
+	})
+
+	t.Run("should persist GIF hit from ServeGIF", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/tracker.gif", handler.ServeGIF)
+
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(activeApp, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, mock.Anything, 1).Return(nil, nil)
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.Anything).Return(nil).Once()
+
+		req := httptest.NewRequest("GET", "/tracker.gif?app_id=test_app_123&url=https://example.com", nil)
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(1), handler.Stats().Accepted)
+		mockTrackingService.AssertExpectations(t)
+	})
+
+	t.Run("should not record GIF hit without app_id", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/tracker.gif", handler.ServeGIF)
+
+		req := httptest.NewRequest("GET", "/tracker.gif", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAppService.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		mockTrackingService.AssertNotCalled(t, "ProcessTrackingData", mock.Anything, mock.Anything)
+		assert.Equal(t, handlers.BeaconHitStats{}, handler.Stats())
+	})
+
+	t.Run("should reject hit for unknown application", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		mockAppService.On("GetByID", mock.Anything, "unknown_app_1").Return(nil, models.ErrApplicationNotFound).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=unknown_app_1&url=https://example.com", nil)
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/gif", w.Header().Get("Content-Type"))
+		assert.Equal(t, int64(1), handler.Stats().Rejected)
+		mockTrackingService.AssertNotCalled(t, "ProcessTrackingData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject hit for inactive application", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		inactiveApp := &models.Application{AppID: "inactive_app", Active: false}
+		mockAppService.On("GetByID", mock.Anything, "inactive_app").Return(inactiveApp, nil).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=inactive_app&url=https://example.com", nil)
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(1), handler.Stats().Rejected)
+		mockTrackingService.AssertNotCalled(t, "ProcessTrackingData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject hit from disallowed page when origin policy is reject", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		app := &models.Application{
+			AppID:    "test_app_123",
+			Domain:   "example.com",
+			Active:   true,
+			Settings: map[string]interface{}{models.SettingOriginPolicy: models.OriginPolicyReject},
+		}
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(app, nil).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Referer", "https://evil.test/page")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(1), handler.Stats().Rejected)
+		mockTrackingService.AssertNotCalled(t, "ProcessTrackingData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should flag hit from disallowed page by default", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		app := &models.Application{AppID: "test_app_123", Domain: "example.com", Active: true}
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(app, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, mock.Anything, 1).Return(nil, nil)
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.MatchedBy(func(data *models.TrackingData) bool {
+			return data.CustomParams[models.CustomParamOriginMismatch] == true
+		})).Return(nil).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&url=https://evil.test/page", nil)
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, int64(1), handler.Stats().Accepted)
+		mockTrackingService.AssertExpectations(t)
+	})
+
+	t.Run("should reject hit when monthly event quota is exceeded", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(activeApp, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, activeApp, 1).
+			Return(&models.Usage{AppID: "test_app_123", Events: 1000000, Quota: 1000000}, models.ErrQuotaExceeded).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&url=https://example.com", nil)
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(1), handler.Stats().Rejected)
+		mockTrackingService.AssertNotCalled(t, "ProcessTrackingData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should save hit when usage cannot be recorded", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(activeApp, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, activeApp, 1).Return(nil, errors.New("redis unavailable")).Once()
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.Anything).Return(nil).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&url=https://example.com", nil)
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, int64(1), handler.Stats().Accepted)
+		mockTrackingService.AssertExpectations(t)
+	})
+
+	t.Run("should count invalid and failed hits separately", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(activeApp, nil).Twice()
+		mockAppService.On("ReserveQuota", mock.Anything, mock.Anything, 1).Return(nil, nil)
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.Anything).
+			Return(models.NewValidationError(models.ErrTrackingUserAgentRequired)).Once()
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.Anything).
+			Return(errors.New("database error")).Once()
+
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			handler.Wait()
+		}
+
+		stats := handler.Stats()
+		assert.Equal(t, int64(1), stats.Invalid)
+		assert.Equal(t, int64(1), stats.Failed)
+		assert.Equal(t, int64(0), stats.Accepted)
+	})
+
+	t.Run("should not commit quota for hits dropped as bot traffic", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		usage := &models.Usage{AppID: "test_app_123", Events: 10, Quota: 1000000}
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(activeApp, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, activeApp, 1).Return(usage, nil).Once()
+		mockAppService.On("CommitQuota", mock.Anything, usage, 1, 0).Return(nil).Once()
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.Anything).Return(models.ErrTrackingBotDropped).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123&url=https://example.com", nil)
+		req.Header.Set("User-Agent", "Googlebot/2.1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		handler.Wait()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(1), handler.Stats().Dropped)
+		mockAppService.AssertExpectations(t)
+	})
+
+	t.Run("should discard hits beyond the pending limit", func(t *testing.T) {
+		mockTrackingService := new(MockTrackingService)
+		mockAppService := new(MockApplicationService)
+		log := logger.NewLogger()
+		log.SetOutput(io.Discard)
+		handler := handlers.NewBeaconHandler(mockTrackingService, mockAppService, log, handlers.WithMaxPendingBeaconHits(1))
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").
+			Run(func(args mock.Arguments) {
+				close(started)
+				<-release
+			}).
+			Return(activeApp, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, mock.Anything, 1).Return(nil, nil)
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.Anything).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		<-started
+
+		// 1件目の処理中に届いたヒットはGIFを返して破棄する
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(1), handler.Stats().Overflow)
+
+		close(release)
+		handler.Wait()
+
+		stats := handler.Stats()
+		assert.Equal(t, int64(1), stats.Accepted)
+		assert.Equal(t, int64(1), stats.Overflow)
+		mockAppService.AssertExpectations(t)
+		mockTrackingService.AssertExpectations(t)
+	})
+
+	t.Run("should report hit counts in health", func(t *testing.T) {
+		handler, mockTrackingService, mockAppService := setupBeaconTest()
+		router := gin.New()
+		router.GET("/beacon", handler.ProcessBeacon)
+		router.GET("/health", handler.Health)
+
+		mockAppService.On("GetByID", mock.Anything, "test_app_123").Return(activeApp, nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, mock.Anything, 1).Return(nil, nil)
+		mockTrackingService.On("ProcessTrackingData", mock.Anything, mock.Anything).Return(nil).Once()
+
+		req := httptest.NewRequest("GET", "/beacon?app_id=test_app_123", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		handler.Wait()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"hits":{"accepted":1,"rejected":0,"invalid":0,"failed":0,"dropped":0,"overflow":0}`)
+		assert.Eventually(t, func() bool { return handler.Stats().Accepted == 1 }, time.Second, 10*time.Millisecond)
+	})
+}
@@ -138,21 +138,94 @@ func (m *MockApplicationService) Count(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockApplicationService) GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*models.Application, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Application), args.Error(1)
+}
+
+func (m *MockApplicationService) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockApplicationService) Delete(ctx context.Context, appID string) error {
 	args := m.Called(ctx, appID)
 	return args.Error(0)
 }
 
-func (m *MockApplicationService) RegenerateAPIKey(ctx context.Context, appID string) (string, error) {
+func (m *MockApplicationService) RotateAPIKey(ctx context.Context, appID, keyID string, gracePeriod *time.Duration) (*models.APIKeyRotation, error) {
+	args := m.Called(ctx, appID, keyID, gracePeriod)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKeyRotation), args.Error(1)
+}
+
+func (m *MockApplicationService) CreateAPIKey(ctx context.Context, appID, name string, scopes []string, expiresAt *time.Time, signed bool) (*models.APIKey, string, error) {
+	args := m.Called(ctx, appID, name, scopes, expiresAt, signed)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).(*models.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockApplicationService) ListAPIKeys(ctx context.Context, appID string) ([]*models.APIKey, error) {
 	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.APIKey), args.Error(1)
+}
+
+func (m *MockApplicationService) DeleteAPIKey(ctx context.Context, appID, keyID string) error {
+	args := m.Called(ctx, appID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockApplicationService) GenerateSigningSecret(ctx context.Context, appID, keyID string) (string, error) {
+	args := m.Called(ctx, appID, keyID)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockApplicationService) DeleteSigningSecret(ctx context.Context, appID, keyID string) error {
+	args := m.Called(ctx, appID, keyID)
+	return args.Error(0)
+}
+
 func (m *MockApplicationService) UpdateSettings(ctx context.Context, appID string, settings map[string]interface{}) error {
 	args := m.Called(ctx, appID, settings)
 	return args.Error(0)
 }
 
+func (m *MockApplicationService) UpdatePlan(ctx context.Context, appID, plan string) error {
+	args := m.Called(ctx, appID, plan)
+	return args.Error(0)
+}
+
+func (m *MockApplicationService) ReserveQuota(ctx context.Context, app *models.Application, events int) (*models.Usage, error) {
+	args := m.Called(ctx, app, events)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Usage), args.Error(1)
+}
+
+func (m *MockApplicationService) CommitQuota(ctx context.Context, usage *models.Usage, reserved, accepted int) error {
+	args := m.Called(ctx, usage, reserved, accepted)
+	return args.Error(0)
+}
+
+func (m *MockApplicationService) GetUsage(ctx context.Context, appID string) (*models.Usage, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Usage), args.Error(1)
+}
+
 // MockTrackingService はトラッキングサービスのモックです
 type MockTrackingService struct {
 	mock.Mock
@@ -163,14 +236,30 @@ func (m *MockTrackingService) ProcessTrackingData(ctx context.Context, data *mod
 	return args.Error(0)
 }
 
-func (m *MockTrackingService) GetStatistics(ctx context.Context, appID string, startDate, endDate time.Time) (*services.TrackingStatistics, error) {
-	args := m.Called(ctx, appID, startDate, endDate)
+func (m *MockTrackingService) ProcessTrackingBatch(ctx context.Context, batch []*models.TrackingData) []error {
+	args := m.Called(ctx, batch)
+	if args.Get(0) == nil {
+		return make([]error, len(batch))
+	}
+	return args.Get(0).([]error)
+}
+
+func (m *MockTrackingService) GetStatistics(ctx context.Context, query services.StatisticsQuery) (*services.TrackingStatistics, error) {
+	args := m.Called(ctx, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*services.TrackingStatistics), args.Error(1)
 }
 
+func (m *MockTrackingService) GetTimeSeries(ctx context.Context, query services.TimeSeriesQuery) (*services.TimeSeries, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TimeSeries), args.Error(1)
+}
+
 func (m *MockTrackingService) GetByID(ctx context.Context, id string) (*models.TrackingData, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
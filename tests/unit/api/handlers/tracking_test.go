@@ -6,12 +6,16 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"accesslog-tracker/internal/api/handlers"
+	"accesslog-tracker/internal/api/middleware"
 	"accesslog-tracker/internal/api/models"
+	domainmodels "accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/domain/services"
+	"accesslog-tracker/internal/ingestion"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -137,6 +141,396 @@ func TestTrackingHandler_Track_ServiceError(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestTrackingHandler_Track_QueueFull(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTrackingTest()
+	
+	reqBody := models.TrackingRequest{
+		AppID:     "test-app-id",
+		UserAgent: "Mozilla/5.0 (Test Browser)",
+		URL:       "https://test.com/page",
+	}
+	
+	mockService.On("ProcessTrackingData", mock.Anything, mock.Anything).Return(ingestion.ErrQueueFull)
+	mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	
+	jsonBody, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/track", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	
+	router.POST("/track", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		handler.Track(c)
+	})
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	
+	var response models.APIResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, "SERVICE_UNAVAILABLE", response.Error.Code)
+	
+	mockService.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func performBatchRequest(router *gin.Engine, handler *handlers.TrackingHandler, body string) (*httptest.ResponseRecorder, models.BatchTrackingResponse) {
+	router.POST("/batch", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		handler.TrackBatch(c)
+	})
+	
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	var response struct {
+		Success bool                         `json:"success"`
+		Data    models.BatchTrackingResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response.Data
+}
+
+func TestTrackingHandler_TrackBatch_JSONArray(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTrackingTest()
+	
+	mockService.On("ProcessTrackingBatch", mock.Anything, mock.MatchedBy(func(batch []*domainmodels.TrackingData) bool {
+		return len(batch) == 2 && batch[0].URL == "https://test.com/a" && batch[1].URL == "https://test.com/b"
+	})).Run(func(args mock.Arguments) {
+		batch := args.Get(1).([]*domainmodels.TrackingData)
+		batch[0].ID = "tracking-a"
+		batch[1].ID = "tracking-b"
+	}).Return([]error{nil, nil})
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	
+	body := `[{"app_id":"test-app-id","user_agent":"Mozilla/5.0","url":"https://test.com/a"},` +
+		`{"app_id":"test-app-id","user_agent":"Mozilla/5.0","url":"https://test.com/b"}]`
+	w, response := performBatchRequest(router, handler, body)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, response.Accepted)
+	assert.Equal(t, 0, response.Rejected)
+	assert.Equal(t, "tracking-a", response.Results[0].TrackingID)
+	assert.Equal(t, "tracking-b", response.Results[1].TrackingID)
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestTrackingHandler_TrackBatch_PerItemResults(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTrackingTest()
+	
+	// 2件目はAppIDが異なるためサービスに渡されない
+	mockService.On("ProcessTrackingBatch", mock.Anything, mock.MatchedBy(func(batch []*domainmodels.TrackingData) bool {
+		return len(batch) == 2
+	})).Return([]error{nil, domainmodels.NewValidationError(domainmodels.ErrTrackingURLRequired)})
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	
+	body := "{\"app_id\":\"test-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"https://test.com/a\"}\n" +
+		"{\"app_id\":\"other-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"https://test.com/b\"}\n" +
+		"{\"app_id\":\"test-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"invalid\"}\n"
+	w, response := performBatchRequest(router, handler, body)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, response.Accepted)
+	assert.Equal(t, 2, response.Rejected)
+	assert.Len(t, response.Results, 3)
+	assert.True(t, response.Results[0].Success)
+	assert.Equal(t, "FORBIDDEN", response.Results[1].Error.Code)
+	assert.Equal(t, 2, response.Results[2].Index)
+	assert.Equal(t, "VALIDATION_ERROR", response.Results[2].Error.Code)
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestTrackingHandler_TrackBatch_QueueFull(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTrackingTest()
+	
+	mockService.On("ProcessTrackingBatch", mock.Anything, mock.Anything).Return([]error{ingestion.ErrQueueFull})
+	mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	
+	w, _ := performBatchRequest(router, handler, `[{"app_id":"test-app-id","url":"https://test.com/a"}]`)
+	
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	mockService.AssertExpectations(t)
+}
+
+func TestTrackingHandler_Track_OriginPolicy(t *testing.T) {
+	app := &domainmodels.Application{
+		AppID:    "test-app-id",
+		Domain:   "test.com",
+		Active:   true,
+		Settings: map[string]interface{}{domainmodels.SettingOriginPolicy: domainmodels.OriginPolicyReject},
+	}
+	body := `{"app_id":"test-app-id","user_agent":"Mozilla/5.0","url":"https://test.com/page"}`
+
+	perform := func(router *gin.Engine, handler *handlers.TrackingHandler, app *domainmodels.Application, origin string) *httptest.ResponseRecorder {
+		router.POST("/track", func(c *gin.Context) {
+			c.Set("app_id", "test-app-id")
+			c.Set("application", app)
+			handler.Track(c)
+		})
+		req := httptest.NewRequest("POST", "/track", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should reject disallowed origin", func(t *testing.T) {
+		router, mockService, mockLogger, handler := setupTrackingTest()
+		mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		w := perform(router, handler, app, "https://evil.test")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var response models.APIResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "ORIGIN_NOT_ALLOWED", response.Error.Code)
+		mockService.AssertNotCalled(t, "ProcessTrackingData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should flag disallowed origin by default", func(t *testing.T) {
+		router, mockService, mockLogger, handler := setupTrackingTest()
+		flagged := &domainmodels.Application{AppID: "test-app-id", Domain: "test.com", Active: true}
+		mockService.On("ProcessTrackingData", mock.Anything, mock.MatchedBy(func(data *domainmodels.TrackingData) bool {
+			return data.CustomParams[domainmodels.CustomParamOriginMismatch] == true
+		})).Return(nil).Once()
+		mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		w := perform(router, handler, flagged, "https://evil.test")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestTrackingHandler_TrackBatch_OriginPolicy(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTrackingTest()
+	app := &domainmodels.Application{
+		AppID:    "test-app-id",
+		Domain:   "test.com",
+		Active:   true,
+		Settings: map[string]interface{}{domainmodels.SettingOriginPolicy: domainmodels.OriginPolicyReject},
+	}
+
+	// ページURLのホストが異なる2件目はサービスに渡されない
+	mockService.On("ProcessTrackingBatch", mock.Anything, mock.MatchedBy(func(batch []*domainmodels.TrackingData) bool {
+		return len(batch) == 1 && batch[0].URL == "https://test.com/a"
+	})).Return([]error{nil})
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	router.POST("/batch", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		c.Set("application", app)
+		handler.TrackBatch(c)
+	})
+	body := "{\"app_id\":\"test-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"https://test.com/a\"}\n" +
+		"{\"app_id\":\"test-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"https://evil.test/b\"}\n"
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response struct {
+		Data models.BatchTrackingResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, response.Data.Accepted)
+	assert.Equal(t, "ORIGIN_NOT_ALLOWED", response.Data.Results[1].Error.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTrackingHandler_BotPolicyDrop(t *testing.T) {
+	app := &domainmodels.Application{
+		AppID:    "test-app-id",
+		Domain:   "test.com",
+		Active:   true,
+		Settings: map[string]interface{}{domainmodels.SettingBotPolicy: domainmodels.BotPolicyDrop},
+	}
+
+	t.Run("track", func(t *testing.T) {
+		router, mockService, mockLogger, handler := setupTrackingTest()
+		mockService.On("ProcessTrackingData", mock.Anything, mock.MatchedBy(func(data *domainmodels.TrackingData) bool {
+			return data.BotPolicy == domainmodels.BotPolicyDrop
+		})).Return(domainmodels.ErrTrackingBotDropped).Once()
+		mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		router.POST("/track", func(c *gin.Context) {
+			c.Set("app_id", "test-app-id")
+			c.Set("application", app)
+			handler.Track(c)
+		})
+		req := httptest.NewRequest("POST", "/track", strings.NewReader(`{"app_id":"test-app-id","user_agent":"Googlebot/2.1","url":"https://test.com/page"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Success bool                    `json:"success"`
+			Data    models.TrackingResponse `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, response.Success)
+		assert.True(t, response.Data.Dropped)
+		assert.Empty(t, response.Data.TrackingID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		router, mockService, mockLogger, handler := setupTrackingTest()
+		mockService.On("ProcessTrackingBatch", mock.Anything, mock.MatchedBy(func(batch []*domainmodels.TrackingData) bool {
+			return len(batch) == 2 && batch[0].BotPolicy == domainmodels.BotPolicyDrop
+		})).Return([]error{domainmodels.ErrTrackingBotDropped, nil}).Once()
+		mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		router.POST("/batch", func(c *gin.Context) {
+			c.Set("app_id", "test-app-id")
+			c.Set("application", app)
+			handler.TrackBatch(c)
+		})
+		body := "{\"app_id\":\"test-app-id\",\"user_agent\":\"Googlebot/2.1\",\"url\":\"https://test.com/a\"}\n" +
+			"{\"app_id\":\"test-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"https://test.com/b\"}\n"
+		req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Data models.BatchTrackingResponse `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, response.Data.Accepted)
+		assert.Equal(t, 0, response.Data.Rejected)
+		assert.Equal(t, 1, response.Data.Dropped)
+		assert.True(t, response.Data.Results[0].Success)
+		assert.True(t, response.Data.Results[0].Dropped)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestTrackingHandler_TrackBatch_InvalidBatch(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"empty array", "[]"},
+		{"invalid json", "not json"},
+		{"too many events", strings.Repeat(`{"app_id":"test-app-id"}`+"\n", models.MaxBatchTrackingEvents+1)},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService, mockLogger, handler := setupTrackingTest()
+			mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			
+			w, _ := performBatchRequest(router, handler, tt.body)
+			
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+			mockService.AssertNotCalled(t, "ProcessTrackingBatch", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTrackingHandler_QuotaChargesAcceptedEvents(t *testing.T) {
+	app := &domainmodels.Application{
+		AppID:    "test-app-id",
+		Domain:   "test.com",
+		Active:   true,
+		Settings: map[string]interface{}{domainmodels.SettingBotPolicy: domainmodels.BotPolicyDrop},
+	}
+
+	// Quota ミドルウェアを通してハンドラーを呼び出すルーター
+	setupQuotaTest := func() (*gin.Engine, *MockTrackingService, *MockApplicationService, *MockLogger) {
+		router, mockService, mockLogger, handler := setupTrackingTest()
+		mockAppService := new(MockApplicationService)
+		quota := middleware.NewQuotaMiddleware(mockAppService, mockLogger)
+		router.Use(func(c *gin.Context) {
+			c.Set("app_id", app.AppID)
+			c.Set("application", app)
+			c.Next()
+		})
+		router.POST("/track", quota.Quota(), handler.Track)
+		router.POST("/batch", middleware.TrackingBatch(), quota.Quota(), handler.TrackBatch)
+		return router, mockService, mockAppService, mockLogger
+	}
+
+	t.Run("partly invalid batch is charged only for valid events", func(t *testing.T) {
+		router, mockService, mockAppService, mockLogger := setupQuotaTest()
+		usage := domainmodels.NewUsage(app.AppID, app.PlanLimits(), domainmodels.UsagePeriod(time.Now()), 4)
+
+		// 2件目はAppIDが異なるためサービスに渡されず、3件目は検証エラー、4件目はボットとして破棄される
+		mockService.On("ProcessTrackingBatch", mock.Anything, mock.MatchedBy(func(batch []*domainmodels.TrackingData) bool {
+			return len(batch) == 3
+		})).Return([]error{nil, domainmodels.NewValidationError(domainmodels.ErrTrackingURLRequired), domainmodels.ErrTrackingBotDropped}).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, app, 4).Return(usage, nil).Once()
+		mockAppService.On("CommitQuota", mock.Anything, usage, 4, 1).Return(nil).Once()
+		mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		body := "{\"app_id\":\"test-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"https://test.com/a\"}\n" +
+			"{\"app_id\":\"other-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"https://test.com/b\"}\n" +
+			"{\"app_id\":\"test-app-id\",\"user_agent\":\"Mozilla/5.0\",\"url\":\"invalid\"}\n" +
+			"{\"app_id\":\"test-app-id\",\"user_agent\":\"Googlebot/2.1\",\"url\":\"https://test.com/d\"}\n"
+		req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Data models.BatchTrackingResponse `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, response.Data.Accepted)
+		assert.Equal(t, 2, response.Data.Rejected)
+		assert.Equal(t, 1, response.Data.Dropped)
+		mockService.AssertExpectations(t)
+		mockAppService.AssertExpectations(t)
+	})
+
+	t.Run("accepted event is committed", func(t *testing.T) {
+		router, mockService, mockAppService, mockLogger := setupQuotaTest()
+		usage := domainmodels.NewUsage(app.AppID, app.PlanLimits(), domainmodels.UsagePeriod(time.Now()), 1)
+
+		mockService.On("ProcessTrackingData", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, app, 1).Return(usage, nil).Once()
+		mockAppService.On("CommitQuota", mock.Anything, usage, 1, 1).Return(nil).Once()
+		mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		req := httptest.NewRequest("POST", "/track", strings.NewReader(`{"app_id":"test-app-id","user_agent":"Mozilla/5.0","url":"https://test.com/page"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAppService.AssertExpectations(t)
+	})
+
+	t.Run("dropped bot event is not committed", func(t *testing.T) {
+		router, mockService, mockAppService, mockLogger := setupQuotaTest()
+		usage := domainmodels.NewUsage(app.AppID, app.PlanLimits(), domainmodels.UsagePeriod(time.Now()), 1)
+
+		mockService.On("ProcessTrackingData", mock.Anything, mock.Anything).Return(domainmodels.ErrTrackingBotDropped).Once()
+		mockAppService.On("ReserveQuota", mock.Anything, app, 1).Return(usage, nil).Once()
+		mockAppService.On("CommitQuota", mock.Anything, usage, 1, 0).Return(nil).Once()
+		mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		req := httptest.NewRequest("POST", "/track", strings.NewReader(`{"app_id":"test-app-id","user_agent":"Googlebot/2.1","url":"https://test.com/page"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAppService.AssertExpectations(t)
+	})
+}
+
 func TestTrackingHandler_GetStatistics_Success(t *testing.T) {
 	router, mockService, mockLogger, handler := setupTrackingTest()
 	
@@ -149,7 +543,7 @@ func TestTrackingHandler_GetStatistics_Success(t *testing.T) {
 		},
 	}
 	
-	mockService.On("GetStatistics", mock.Anything, "test-app-id", mock.Anything, mock.Anything).Return(stats, nil)
+	mockService.On("GetStatistics", mock.Anything, mock.MatchedBy(func(q services.StatisticsQuery) bool { return q.AppID == "test-app-id" })).Return(stats, nil)
 	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything)
 	
 	req := httptest.NewRequest("GET", "/statistics?app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-31", nil)
@@ -172,6 +566,78 @@ func TestTrackingHandler_GetStatistics_Success(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestTrackingHandler_GetStatistics_Aggregates(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTrackingTest()
+	
+	stats := &services.TrackingStatistics{
+		AppID:                  "test-app-id",
+		TotalRequests:          120,
+		UniqueSessions:         30,
+		UniqueVisitors:         25,
+		BotRequests:            5,
+		MobileRequests:         40,
+		AverageSessionDuration: 95.5,
+		TopPages:               []*domainmodels.StatsGroup{{Key: "/home", Requests: 80}},
+		TopReferrers:           []*domainmodels.StatsGroup{{Key: "https://google.com", Requests: 50}},
+		GroupBy:                "day",
+		Groups:                 []*domainmodels.StatsGroup{{Key: "2024-01-01", Requests: 120, UniqueSessions: 30, UniqueIPs: 25}},
+	}
+	
+	// 終了日はその日の終わりまで含める
+	mockService.On("GetStatistics", mock.Anything, mock.MatchedBy(func(q services.StatisticsQuery) bool {
+		return q.AppID == "test-app-id" && q.GroupBy == "day" && q.Limit == 5 &&
+			q.EndDate.Equal(time.Date(2024, 1, 31, 23, 59, 59, 999999999, time.UTC))
+	})).Return(stats, nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything)
+	
+	req := httptest.NewRequest("GET", "/statistics?app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-31&group_by=day&limit=5", nil)
+	w := httptest.NewRecorder()
+	
+	router.GET("/statistics", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		handler.GetStatistics(c)
+	})
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	
+	var response struct {
+		Success bool                      `json:"success"`
+		Data    models.StatisticsResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, int64(120), response.Data.TotalRequests)
+	assert.Equal(t, int64(25), response.Data.UniqueVisitors)
+	assert.Equal(t, int64(30), response.Data.UniqueSessions)
+	assert.Equal(t, 95.5, response.Data.AverageSessionDuration)
+	assert.Equal(t, []models.PageStats{{URL: "/home", Count: 80}}, response.Data.TopPages)
+	assert.Equal(t, []models.ReferrerStats{{Referrer: "https://google.com", Count: 50}}, response.Data.TopReferrers)
+	assert.Equal(t, "day", response.Data.GroupBy)
+	assert.Equal(t, []models.GroupStats{{Key: "2024-01-01", Requests: 120, UniqueSessions: 30, UniqueVisitors: 25}}, response.Data.Groups)
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestTrackingHandler_GetStatistics_InvalidGroupByAndLimit(t *testing.T) {
+	router, _, _, handler := setupTrackingTest()
+	
+	router.GET("/statistics", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		handler.GetStatistics(c)
+	})
+	
+	for _, query := range []string{"group_by=week", "limit=0", "limit=abc"} {
+		req := httptest.NewRequest("GET", "/statistics?app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-31&"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), "VALIDATION_ERROR", query)
+	}
+}
+
 func TestTrackingHandler_GetStatistics_MissingParameters(t *testing.T) {
 	router, _, _, handler := setupTrackingTest()
 	
@@ -211,7 +677,7 @@ func TestTrackingHandler_GetStatistics_InvalidDateFormat(t *testing.T) {
 func TestTrackingHandler_GetStatistics_ServiceError(t *testing.T) {
 	router, mockService, mockLogger, handler := setupTrackingTest()
 	
-	mockService.On("GetStatistics", mock.Anything, "test-app-id", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+	mockService.On("GetStatistics", mock.Anything, mock.MatchedBy(func(q services.StatisticsQuery) bool { return q.AppID == "test-app-id" })).Return(nil, errors.New("database error"))
 	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	
 	req := httptest.NewRequest("GET", "/statistics?app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-31", nil)
@@ -255,3 +721,99 @@ func TestTrackingHandler_GetStatistics_AccessDenied(t *testing.T) {
 	assert.False(t, response.Success)
 	assert.Equal(t, "FORBIDDEN", response.Error.Code)
 }
+
+func TestTrackingHandler_GetTimeSeries_Success(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTrackingTest()
+	
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	series := &services.TimeSeries{
+		AppID:    "test-app-id",
+		Interval: "day",
+		Timezone: "Asia/Tokyo",
+		Points: []*domainmodels.TimeSeriesPoint{
+			{Bucket: time.Date(2024, 1, 1, 0, 0, 0, 0, tokyo), PageViews: 0},
+			{Bucket: time.Date(2024, 1, 2, 0, 0, 0, 0, tokyo), PageViews: 10, Sessions: 4, Visitors: 3},
+		},
+	}
+	
+	// アプリケーションのタイムゾーンで日付を解釈する
+	mockService.On("GetTimeSeries", mock.Anything, mock.MatchedBy(func(q services.TimeSeriesQuery) bool {
+		return q.AppID == "test-app-id" && q.Interval == "day" && q.Location.String() == "Asia/Tokyo" &&
+			q.StartDate.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, tokyo)) &&
+			q.EndDate.Equal(time.Date(2024, 1, 2, 23, 59, 59, 999999999, tokyo))
+	})).Return(series, nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	
+	req := httptest.NewRequest("GET", "/timeseries?app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-02", nil)
+	w := httptest.NewRecorder()
+	
+	router.GET("/timeseries", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		c.Set("application", &domainmodels.Application{AppID: "test-app-id", Timezone: "Asia/Tokyo"})
+		handler.GetTimeSeries(c)
+	})
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	
+	var response struct {
+		Success bool                      `json:"success"`
+		Data    models.TimeSeriesResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, "Asia/Tokyo", response.Data.Timezone)
+	assert.Len(t, response.Data.Points, 2)
+	assert.Equal(t, int64(10), response.Data.Points[1].PageViews)
+	assert.Contains(t, w.Body.String(), "2024-01-02T00:00:00+09:00")
+	
+	mockService.AssertExpectations(t)
+}
+
+func TestTrackingHandler_GetTimeSeries_RequestTimezone(t *testing.T) {
+	router, mockService, mockLogger, handler := setupTrackingTest()
+	
+	mockService.On("GetTimeSeries", mock.Anything, mock.MatchedBy(func(q services.TimeSeriesQuery) bool {
+		return q.Interval == "hour" && q.Location.String() == "America/New_York"
+	})).Return(&services.TimeSeries{AppID: "test-app-id", Interval: "hour", Timezone: "America/New_York"}, nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	
+	req := httptest.NewRequest("GET", "/timeseries?app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-01&interval=hour&tz=America/New_York", nil)
+	w := httptest.NewRecorder()
+	
+	router.GET("/timeseries", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		c.Set("application", &domainmodels.Application{AppID: "test-app-id", Timezone: "Asia/Tokyo"})
+		handler.GetTimeSeries(c)
+	})
+	router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTrackingHandler_GetTimeSeries_InvalidParameters(t *testing.T) {
+	router, _, _, handler := setupTrackingTest()
+	
+	router.GET("/timeseries", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		handler.GetTimeSeries(c)
+	})
+	
+	tests := map[string]int{
+		"app_id=test-app-id&start_date=2024-01-01":                                      http.StatusBadRequest,
+		"app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-31&interval=year":    http.StatusBadRequest,
+		"app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-31&tz=Mars/Olympus": http.StatusBadRequest,
+		"app_id=test-app-id&start_date=2024-01-01&end_date=2024-01-31&tz=Local":        http.StatusBadRequest,
+		"app_id=test-app-id&start_date=01/01/2024&end_date=2024-01-31":                 http.StatusBadRequest,
+		"app_id=other-app-id&start_date=2024-01-01&end_date=2024-01-31":                http.StatusForbidden,
+	}
+	for query, status := range tests {
+		req := httptest.NewRequest("GET", "/timeseries?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	
+		assert.Equal(t, status, w.Code, query)
+	}
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/api/middleware"
+	"accesslog-tracker/internal/api/models"
+	domainmodels "accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/crypto"
+	"accesslog-tracker/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAdminToken = "test-admin-token"
+	testJWTSecret  = "test-jwt-secret"
+)
+
+// fakeMembershipLookup はユーザーIDごとのロールを返すテスト用の実装です
+type fakeMembershipLookup struct {
+	roles map[string]map[string]string
+	err   error
+}
+
+func (f *fakeMembershipLookup) GetUserRoles(ctx context.Context, userID string) (map[string]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.roles[userID], nil
+}
+
+func setupAdminAuthTest(config middleware.AdminAuthConfig) *gin.Engine {
+	return setupAdminAuthTestWithMemberships(config, nil)
+}
+
+func setupAdminAuthTestWithMemberships(config middleware.AdminAuthConfig, memberships middleware.MembershipLookup) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+
+	router := gin.New()
+	router.GET("/admin", middleware.NewAdminAuthMiddleware(config, memberships, log).Authenticate(), func(c *gin.Context) {
+		principal := c.MustGet("principal").(*domainmodels.Principal)
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":   principal.UserID,
+			"superuser": principal.Superuser,
+			"roles":     principal.Roles,
+		})
+	})
+	return router
+}
+
+func decodePrincipal(t *testing.T, w *httptest.ResponseRecorder) (userID string, superuser bool, roles map[string]string) {
+	var body struct {
+		UserID    string            `json:"user_id"`
+		Superuser bool              `json:"superuser"`
+		Roles     map[string]string `json:"roles"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.UserID, body.Superuser, body.Roles
+}
+
+func adminRequest(router *gin.Engine, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/admin", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func signTestJWT(t *testing.T, subject, role string, expiresAt time.Time) string {
+	token, err := crypto.SignJWT(crypto.JWTClaims{Subject: subject, Role: role, ExpiresAt: expiresAt.Unix()}, testJWTSecret)
+	require.NoError(t, err)
+	return token
+}
+
+func TestAdminAuthMiddleware_AdminToken(t *testing.T) {
+	router := setupAdminAuthTest(middleware.AdminAuthConfig{Token: testAdminToken})
+
+	w := adminRequest(router, "Bearer "+testAdminToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, superuser, _ := decodePrincipal(t, w)
+	assert.True(t, superuser)
+
+	w = adminRequest(router, "Bearer wrong-token")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminAuthMiddleware_MissingCredentials(t *testing.T) {
+	router := setupAdminAuthTest(middleware.AdminAuthConfig{Token: testAdminToken, JWTSecret: testJWTSecret})
+
+	for _, authorization := range []string{"", testAdminToken, "Basic " + testAdminToken, "Bearer "} {
+		w := adminRequest(router, authorization)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, authorization)
+
+		var response models.APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.Success)
+		assert.Equal(t, "AUTHENTICATION_ERROR", response.Error.Code)
+	}
+}
+
+func TestAdminAuthMiddleware_JWT(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	router := setupAdminAuthTest(middleware.AdminAuthConfig{
+		JWTSecret: testJWTSecret,
+		Clock:     func() time.Time { return now },
+	})
+
+	t.Run("admin role", func(t *testing.T) {
+		w := adminRequest(router, "Bearer "+signTestJWT(t, "alice", middleware.AdminRole, now.Add(time.Hour)))
+		assert.Equal(t, http.StatusOK, w.Code)
+		userID, superuser, _ := decodePrincipal(t, w)
+		assert.Equal(t, "alice", userID)
+		assert.True(t, superuser)
+	})
+
+	t.Run("non-admin role without membership lookup", func(t *testing.T) {
+		w := adminRequest(router, "Bearer "+signTestJWT(t, "bob", "viewer", now.Add(time.Hour)))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		w := adminRequest(router, "Bearer "+signTestJWT(t, "alice", middleware.AdminRole, now.Add(-time.Minute)))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("token signed with another secret", func(t *testing.T) {
+		token, err := crypto.SignJWT(crypto.JWTClaims{Subject: "mallory", Role: middleware.AdminRole, ExpiresAt: now.Add(time.Hour).Unix()}, "another-secret")
+		require.NoError(t, err)
+
+		w := adminRequest(router, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestAdminAuthMiddleware_NotConfigured(t *testing.T) {
+	// 管理トークンもJWT署名鍵も設定されていない場合はすべて拒否する
+	router := setupAdminAuthTest(middleware.AdminAuthConfig{})
+
+	w := adminRequest(router, "Bearer anything")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	token, err := crypto.SignJWT(crypto.JWTClaims{Subject: "alice", Role: middleware.AdminRole, ExpiresAt: time.Now().Add(time.Hour).Unix()}, testJWTSecret)
+	require.NoError(t, err)
+	w = adminRequest(router, "Bearer "+token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminAuthMiddleware_OrganizationMember(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	config := middleware.AdminAuthConfig{
+		JWTSecret: testJWTSecret,
+		Clock:     func() time.Time { return now },
+	}
+	memberships := &fakeMembershipLookup{roles: map[string]map[string]string{
+		"bob": {"org-1": domainmodels.RoleOwner, "org-2": domainmodels.RoleViewer},
+	}}
+	router := setupAdminAuthTestWithMemberships(config, memberships)
+
+	t.Run("member gets organization roles", func(t *testing.T) {
+		w := adminRequest(router, "Bearer "+signTestJWT(t, "bob", "", now.Add(time.Hour)))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		userID, superuser, roles := decodePrincipal(t, w)
+		assert.Equal(t, "bob", userID)
+		assert.False(t, superuser)
+		assert.Equal(t, map[string]string{"org-1": domainmodels.RoleOwner, "org-2": domainmodels.RoleViewer}, roles)
+	})
+
+	t.Run("user without memberships", func(t *testing.T) {
+		w := adminRequest(router, "Bearer "+signTestJWT(t, "carol", "", now.Add(time.Hour)))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		_, superuser, roles := decodePrincipal(t, w)
+		assert.False(t, superuser)
+		assert.Empty(t, roles)
+	})
+
+	t.Run("token without subject", func(t *testing.T) {
+		w := adminRequest(router, "Bearer "+signTestJWT(t, "", "", now.Add(time.Hour)))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("membership lookup failure", func(t *testing.T) {
+		failing := setupAdminAuthTestWithMemberships(config, &fakeMembershipLookup{err: errors.New("database unavailable")})
+		w := adminRequest(failing, "Bearer "+signTestJWT(t, "bob", "", now.Add(time.Hour)))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
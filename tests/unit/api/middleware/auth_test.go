@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"accesslog-tracker/internal/api/middleware"
 	"accesslog-tracker/internal/api/models"
@@ -62,21 +63,94 @@ func (m *MockApplicationService) Count(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockApplicationService) GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*domainmodels.Application, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainmodels.Application), args.Error(1)
+}
+
+func (m *MockApplicationService) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockApplicationService) Delete(ctx context.Context, appID string) error {
 	args := m.Called(ctx, appID)
 	return args.Error(0)
 }
 
-func (m *MockApplicationService) RegenerateAPIKey(ctx context.Context, appID string) (string, error) {
+func (m *MockApplicationService) RotateAPIKey(ctx context.Context, appID, keyID string, gracePeriod *time.Duration) (*domainmodels.APIKeyRotation, error) {
+	args := m.Called(ctx, appID, keyID, gracePeriod)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainmodels.APIKeyRotation), args.Error(1)
+}
+
+func (m *MockApplicationService) CreateAPIKey(ctx context.Context, appID, name string, scopes []string, expiresAt *time.Time, signed bool) (*domainmodels.APIKey, string, error) {
+	args := m.Called(ctx, appID, name, scopes, expiresAt, signed)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).(*domainmodels.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockApplicationService) ListAPIKeys(ctx context.Context, appID string) ([]*domainmodels.APIKey, error) {
 	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainmodels.APIKey), args.Error(1)
+}
+
+func (m *MockApplicationService) DeleteAPIKey(ctx context.Context, appID, keyID string) error {
+	args := m.Called(ctx, appID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockApplicationService) GenerateSigningSecret(ctx context.Context, appID, keyID string) (string, error) {
+	args := m.Called(ctx, appID, keyID)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockApplicationService) DeleteSigningSecret(ctx context.Context, appID, keyID string) error {
+	args := m.Called(ctx, appID, keyID)
+	return args.Error(0)
+}
+
 func (m *MockApplicationService) UpdateSettings(ctx context.Context, appID string, settings map[string]interface{}) error {
 	args := m.Called(ctx, appID, settings)
 	return args.Error(0)
 }
 
+func (m *MockApplicationService) UpdatePlan(ctx context.Context, appID, plan string) error {
+	args := m.Called(ctx, appID, plan)
+	return args.Error(0)
+}
+
+func (m *MockApplicationService) ReserveQuota(ctx context.Context, app *domainmodels.Application, events int) (*domainmodels.Usage, error) {
+	args := m.Called(ctx, app, events)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainmodels.Usage), args.Error(1)
+}
+
+func (m *MockApplicationService) CommitQuota(ctx context.Context, usage *domainmodels.Usage, reserved, accepted int) error {
+	args := m.Called(ctx, usage, reserved, accepted)
+	return args.Error(0)
+}
+
+func (m *MockApplicationService) GetUsage(ctx context.Context, appID string) (*domainmodels.Usage, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainmodels.Usage), args.Error(1)
+}
+
 // MockLogger はロガーのモックです
 type MockLogger struct {
 	mock.Mock
@@ -429,3 +503,139 @@ func TestAuthMiddleware_OptionalAuth_WithInactiveApp(t *testing.T) {
 	
 	mockService.AssertExpectations(t)
 }
+
+func TestAuthMiddleware_Authenticate_Scopes(t *testing.T) {
+	ingestKey := &domainmodels.Application{
+		AppID:            "test-app-id",
+		Active:           true,
+		AuthenticatedKey: &domainmodels.APIKey{ID: "key-1", Scopes: []string{domainmodels.ScopeIngest}},
+	}
+
+	tests := []struct {
+		name           string
+		scope          string
+		expectedStatus int
+	}{
+		{"ingest key can send tracking data", domainmodels.ScopeIngest, http.StatusOK},
+		{"ingest key cannot read statistics", domainmodels.ScopeStatsRead, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService, mockLogger, authMiddleware := setupAuthTest()
+
+			mockService.On("GetByAPIKey", mock.Anything, "alt_ingest_key").Return(ingestKey, nil)
+			mockLogger.On("Debug", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+			mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("X-API-Key", "alt_ingest_key")
+			w := httptest.NewRecorder()
+
+			router.GET("/test", authMiddleware.Authenticate(tt.scope), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "success"})
+			})
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				var response models.APIResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "INSUFFICIENT_SCOPE", response.Error.Code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_OptionalAuth_WithoutScope(t *testing.T) {
+	router, mockService, _, authMiddleware := setupAuthTest()
+
+	app := &domainmodels.Application{
+		AppID:            "test-app-id",
+		Active:           true,
+		AuthenticatedKey: &domainmodels.APIKey{ID: "key-1", Scopes: []string{domainmodels.ScopeIngest}},
+	}
+	mockService.On("GetByAPIKey", mock.Anything, "alt_ingest_key").Return(app, nil)
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-API-Key", "alt_ingest_key")
+	w := httptest.NewRecorder()
+
+	router.GET("/test", authMiddleware.OptionalAuth(domainmodels.ScopeStatsRead), func(c *gin.Context) {
+		_, exists := c.Get("app_id")
+		assert.False(t, exists)
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+	})
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthMiddleware_AdminAPIKey(t *testing.T) {
+	next := func(c *gin.Context) {
+		c.Set("principal", &domainmodels.Principal{Superuser: true})
+	}
+	handler := func(c *gin.Context) {
+		principal, _ := c.Get("principal")
+		c.JSON(http.StatusOK, principal)
+	}
+
+	t.Run("should fall back to admin authentication without API key", func(t *testing.T) {
+		router, mockService, _, authMiddleware := setupAuthTest()
+
+		req := httptest.NewRequest("GET", "/applications", nil)
+		req.Header.Set("Authorization", "Bearer admin-token")
+		w := httptest.NewRecorder()
+
+		router.GET("/applications", authMiddleware.AdminAPIKey(next), handler)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"Superuser":true`)
+		mockService.AssertNotCalled(t, "GetByAPIKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should restrict apps:admin key to its application", func(t *testing.T) {
+		router, mockService, mockLogger, authMiddleware := setupAuthTest()
+
+		app := &domainmodels.Application{
+			AppID:            "test-app-id",
+			Active:           true,
+			AuthenticatedKey: &domainmodels.APIKey{ID: "key-1", Scopes: []string{domainmodels.ScopeAppsAdmin}},
+		}
+		mockService.On("GetByAPIKey", mock.Anything, "alt_admin_key").Return(app, nil)
+		mockLogger.On("Debug", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		req := httptest.NewRequest("GET", "/applications", nil)
+		req.Header.Set("X-API-Key", "alt_admin_key")
+		w := httptest.NewRecorder()
+
+		router.GET("/applications", authMiddleware.AdminAPIKey(next), handler)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"AppID":"test-app-id"`)
+		assert.Contains(t, w.Body.String(), `"Superuser":false`)
+	})
+
+	t.Run("should reject key without apps:admin scope", func(t *testing.T) {
+		router, mockService, mockLogger, authMiddleware := setupAuthTest()
+
+		app := &domainmodels.Application{
+			AppID:            "test-app-id",
+			Active:           true,
+			AuthenticatedKey: &domainmodels.APIKey{ID: "key-1", Scopes: domainmodels.DefaultAPIKeyScopes},
+		}
+		mockService.On("GetByAPIKey", mock.Anything, "alt_ingest_key").Return(app, nil)
+		mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		req := httptest.NewRequest("GET", "/applications", nil)
+		req.Header.Set("X-API-Key", "alt_ingest_key")
+		w := httptest.NewRecorder()
+
+		router.GET("/applications", authMiddleware.AdminAPIKey(next), handler)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
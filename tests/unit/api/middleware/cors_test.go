@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"accesslog-tracker/internal/api/middleware"
+	domainmodels "accesslog-tracker/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupCORSRouter(applications middleware.ApplicationLookup) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	config := middleware.DefaultCORSConfig()
+	config.AllowedOrigins = []string{"http://localhost:3000", "https://*.dashboard.example.net"}
+
+	router := gin.New()
+	router.Use(middleware.CORS(config, applications))
+	router.Any("/v1/tracking/track", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestCORS_GlobalAllowlist(t *testing.T) {
+	router := setupCORSRouter(nil)
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"http://localhost:3000", http.StatusOK},
+		{"https://admin.dashboard.example.net", http.StatusOK},
+		{"https://dashboard.example.net", http.StatusForbidden},
+		{"https://www.example.com", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/v1/tracking/track", nil)
+		req.Header.Set("Origin", tt.origin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want, w.Code, tt.origin)
+		if tt.want == http.StatusOK {
+			assert.Equal(t, tt.origin, w.Header().Get("Access-Control-Allow-Origin"))
+		}
+	}
+}
+
+func TestCORS_ApplicationOrigins(t *testing.T) {
+	app := &domainmodels.Application{
+		AppID:  "test-app-id",
+		Domain: "example.com",
+		Active: true,
+		Settings: map[string]interface{}{
+			domainmodels.SettingAllowedOrigins: []interface{}{"*.example.com"},
+		},
+	}
+
+	t.Run("should resolve application from app_id on preflight", func(t *testing.T) {
+		mockService := new(MockApplicationService)
+		mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+		router := setupCORSRouter(mockService)
+
+		req := httptest.NewRequest("OPTIONS", "/v1/tracking/track?app_id=test-app-id", nil)
+		req.Header.Set("Origin", "https://shop.example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-API-Key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://shop.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-Api-Key")
+	})
+
+	t.Run("should resolve application from API key", func(t *testing.T) {
+		mockService := new(MockApplicationService)
+		mockService.On("GetByAPIKey", mock.Anything, "alt_test_key").Return(app, nil)
+		router := setupCORSRouter(mockService)
+
+		req := httptest.NewRequest("POST", "/v1/tracking/track", nil)
+		req.Header.Set("Origin", "https://www.example.com")
+		req.Header.Set("X-API-Key", "alt_test_key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://www.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		mockService.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject origin of another site", func(t *testing.T) {
+		mockService := new(MockApplicationService)
+		mockService.On("GetByID", mock.Anything, "test-app-id").Return(app, nil)
+		router := setupCORSRouter(mockService)
+
+		req := httptest.NewRequest("GET", "/v1/tracking/track?app_id=test-app-id", nil)
+		req.Header.Set("Origin", "https://evil.test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("should reject inactive or unknown application", func(t *testing.T) {
+		inactive := *app
+		inactive.Active = false
+		mockService := new(MockApplicationService)
+		mockService.On("GetByID", mock.Anything, "test-app-id").Return(&inactive, nil)
+		mockService.On("GetByID", mock.Anything, "unknown").Return(nil, domainmodels.ErrApplicationNotFound)
+		router := setupCORSRouter(mockService)
+
+		for _, appID := range []string{"test-app-id", "unknown"} {
+			req := httptest.NewRequest("GET", "/v1/tracking/track?app_id="+appID, nil)
+			req.Header.Set("Origin", "https://www.example.com")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code, appID)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/api/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalRateLimiter_SlidingWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := middleware.NewLocalRateLimiter(func() time.Time { return now })
+	limits := middleware.RateLimitConfig{RequestsPerMinute: 10}
+
+	result := limiter.Allow("key", limits, 8)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 10, result.Limit)
+	assert.Equal(t, 2, result.Remaining)
+	assert.Equal(t, now.Add(time.Minute).Unix(), result.Reset.Unix())
+
+	result = limiter.Allow("key", limits, 3)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+	assert.Equal(t, time.Minute, result.RetryAfter)
+
+	// 別のキーは独立して数える
+	assert.True(t, limiter.Allow("other", limits, 10).Allowed)
+
+	// 次のウィンドウの半分が過ぎると直前のウィンドウの件数は半分と見積もる
+	now = now.Add(90 * time.Second)
+	result = limiter.Allow("key", limits, 6)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.False(t, limiter.Allow("key", limits, 1).Allowed)
+}
+
+func TestLocalRateLimiter_Burst(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := middleware.NewLocalRateLimiter(func() time.Time { return now })
+	// 1分間に60件（1秒に1件）の速度で補充する容量5のバケット
+	limits := middleware.RateLimitConfig{RequestsPerMinute: 60, BurstSize: 5}
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, limiter.Allow("key", limits, 1).Allowed)
+	}
+	result := limiter.Allow("key", limits, 1)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	now = now.Add(2 * time.Second)
+	result = limiter.Allow("key", limits, 1)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/api/middleware"
+	domainmodels "accesslog-tracker/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeQuotaService は指定した使用量とエラーを返す QuotaService です
+type fakeQuotaService struct {
+	usage *domainmodels.Usage
+	err   error
+
+	events   int
+	calls    int
+	commits  int
+	reserved int
+	accepted int
+}
+
+func (s *fakeQuotaService) ReserveQuota(ctx context.Context, app *domainmodels.Application, events int) (*domainmodels.Usage, error) {
+	s.calls++
+	s.events = events
+	return s.usage, s.err
+}
+
+func (s *fakeQuotaService) CommitQuota(ctx context.Context, usage *domainmodels.Usage, reserved, accepted int) error {
+	s.commits++
+	s.reserved += reserved
+	s.accepted += accepted
+	return nil
+}
+
+func setupQuotaRouter(quotas middleware.QuotaService, app *domainmodels.Application, cost, accepted int) (*gin.Engine, *MockLogger) {
+	gin.SetMode(gin.TestMode)
+	mockLogger := new(MockLogger)
+	quota := middleware.NewQuotaMiddleware(quotas, mockLogger)
+
+	router := gin.New()
+	router.POST("/track", func(c *gin.Context) {
+		if app != nil {
+			c.Set("application", app)
+		}
+		if cost > 0 {
+			c.Set("rate_limit_cost", cost)
+		}
+		c.Next()
+	}, quota.Quota(), func(c *gin.Context) {
+		c.Set("accepted_events", accepted)
+		c.Status(http.StatusOK)
+	})
+	return router, mockLogger
+}
+
+func TestQuota_Allowed(t *testing.T) {
+	app := &domainmodels.Application{AppID: "test-app-id"}
+	quotas := &fakeQuotaService{usage: domainmodels.NewUsage(app.AppID, app.PlanLimits(), domainmodels.UsagePeriod(time.Now()), 10)}
+	router, _ := setupQuotaRouter(quotas, app, 5, 5)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	// バッチのイベント数を確保し、受け付けた分を確定する
+	assert.Equal(t, 5, quotas.events)
+	assert.Equal(t, 1, quotas.commits)
+	assert.Equal(t, 5, quotas.reserved)
+	assert.Equal(t, 5, quotas.accepted)
+}
+
+func TestQuota_CommitsOnlyAcceptedEvents(t *testing.T) {
+	app := &domainmodels.Application{AppID: "test-app-id"}
+	quotas := &fakeQuotaService{usage: domainmodels.NewUsage(app.AppID, app.PlanLimits(), domainmodels.UsagePeriod(time.Now()), 10)}
+	router, _ := setupQuotaRouter(quotas, app, 5, 2)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+
+	// 確保した分はすべて解放し、受け付けたイベントだけを使用量に加算する
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, quotas.events)
+	assert.Equal(t, 1, quotas.commits)
+	assert.Equal(t, 5, quotas.reserved)
+	assert.Equal(t, 2, quotas.accepted)
+}
+
+func TestQuota_Exceeded(t *testing.T) {
+	app := &domainmodels.Application{AppID: "test-app-id"}
+	period := domainmodels.UsagePeriod(time.Now())
+	quotas := &fakeQuotaService{
+		usage: domainmodels.NewUsage(app.AppID, app.PlanLimits(), period, 1000000),
+		err:   domainmodels.ErrQuotaExceeded,
+	}
+	router, mockLogger := setupQuotaRouter(quotas, app, 0, 0)
+	mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "QUOTA_EXCEEDED")
+	assert.Contains(t, w.Body.String(), "1000000 of 1000000 events used in "+period.Format("2006-01"))
+	assert.NotEmpty(t, w.Header().Get(middleware.RetryAfterHeader))
+	assert.Equal(t, 1, quotas.events)
+	assert.Equal(t, 0, quotas.commits)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestQuota_CounterUnavailable(t *testing.T) {
+	quotas := &fakeQuotaService{err: errors.New("connection refused")}
+	router, mockLogger := setupQuotaRouter(quotas, &domainmodels.Application{AppID: "test-app-id"}, 0, 0)
+	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+
+	// 使用量を記録できない場合は拒否せず、確定もしない
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, quotas.commits)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestQuota_Unauthenticated(t *testing.T) {
+	quotas := &fakeQuotaService{}
+	router, _ := setupQuotaRouter(quotas, nil, 0, 0)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, quotas.calls)
+}
@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/api/middleware"
+	domainmodels "accesslog-tracker/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeScripter はレート制限スクリプトの呼び出しを記録し、指定した結果を返す redis.Scripter です
+type fakeScripter struct {
+	result   []interface{}
+	err      error
+	noScript bool
+
+	keys  []string
+	args  []interface{}
+	evals int
+}
+
+func (s *fakeScripter) run(keys []string, args ...interface{}) *redis.Cmd {
+	s.keys = keys
+	s.args = args
+	if s.err != nil {
+		return redis.NewCmdResult(nil, s.err)
+	}
+	return redis.NewCmdResult(s.result, nil)
+}
+
+func (s *fakeScripter) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	s.evals++
+	return s.run(keys, args...)
+}
+
+func (s *fakeScripter) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd {
+	if s.noScript {
+		return redis.NewCmdResult(nil, scriptError("NOSCRIPT No matching script. Please use EVAL."))
+	}
+	return s.run(keys, args...)
+}
+
+func (s *fakeScripter) EvalRO(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	return s.Eval(ctx, script, keys, args...)
+}
+
+func (s *fakeScripter) EvalShaRO(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd {
+	return s.EvalSha(ctx, sha1, keys, args...)
+}
+
+func (s *fakeScripter) ScriptExists(ctx context.Context, hashes ...string) *redis.BoolSliceCmd {
+	return redis.NewBoolSliceResult(make([]bool, len(hashes)), nil)
+}
+
+func (s *fakeScripter) ScriptLoad(ctx context.Context, script string) *redis.StringCmd {
+	return redis.NewStringResult("", nil)
+}
+
+// scriptError はRedisが返すエラー（redis.Error）です
+type scriptError string
+
+func (e scriptError) Error() string { return string(e) }
+
+func (e scriptError) RedisError() {}
+
+// rateLimitTestNow は 12:00:30 UTC（1分間のウィンドウの途中）です
+var rateLimitTestNow = time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+
+func setupRateLimitRouter(scripter redis.Scripter, cost int) (*gin.Engine, *MockLogger) {
+	gin.SetMode(gin.TestMode)
+	mockLogger := new(MockLogger)
+
+	config := middleware.RateLimitConfig{
+		RequestsPerMinute: 60,
+		RequestsPerHour:   1000,
+		BurstSize:         10,
+		Clock:             func() time.Time { return rateLimitTestNow },
+	}
+	rateLimit := middleware.NewRateLimitMiddleware(scripter, mockLogger, config)
+
+	router := gin.New()
+	router.POST("/track", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		if cost > 0 {
+			c.Set("rate_limit_cost", cost)
+		}
+		c.Next()
+	}, rateLimit.RateLimit(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router, mockLogger
+}
+
+func TestRateLimit_Allowed(t *testing.T) {
+	scripter := &fakeScripter{result: []interface{}{int64(1), int64(7), int64(0)}}
+	router, _ := setupRateLimitRouter(scripter, 3)
+
+	req := httptest.NewRequest("POST", "/track", nil)
+	req.RemoteAddr = "192.0.2.1:12345"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "60", w.Header().Get(middleware.RateLimitLimitHeader))
+	assert.Equal(t, "7", w.Header().Get(middleware.RateLimitRemainingHeader))
+	assert.Equal(t, strconv.FormatInt(time.Date(2024, 1, 1, 12, 1, 0, 0, time.UTC).Unix(), 10), w.Header().Get(middleware.RateLimitResetHeader))
+	assert.Empty(t, w.Header().Get(middleware.RetryAfterHeader))
+
+	// 現在時刻のウィンドウと直前のウィンドウのカウンター、トークンバケットを渡す
+	minute := rateLimitTestNow.UnixMilli() / time.Minute.Milliseconds()
+	hour := rateLimitTestNow.UnixMilli() / time.Hour.Milliseconds()
+	// Redis Cluster で同じスロットに置くため、すべてのキーが同じハッシュタグを持つ
+	prefix := "rate_limit:{test-app-id:192.0.2.1}"
+	assert.Equal(t, []string{
+		prefix + ":m:" + strconv.FormatInt(minute, 10),
+		prefix + ":m:" + strconv.FormatInt(minute-1, 10),
+		prefix + ":h:" + strconv.FormatInt(hour, 10),
+		prefix + ":h:" + strconv.FormatInt(hour-1, 10),
+		prefix + ":b",
+	}, scripter.keys)
+	assert.Equal(t, []interface{}{rateLimitTestNow.UnixMilli(), 3, 60, 1000, 10}, scripter.args)
+}
+
+func TestRateLimit_Exceeded(t *testing.T) {
+	scripter := &fakeScripter{result: []interface{}{int64(0), int64(0), int64(1500)}}
+	router, mockLogger := setupRateLimitRouter(scripter, 0)
+	mockLogger.On("Warnf", mock.Anything, mock.Anything).Once()
+
+	req := httptest.NewRequest("POST", "/track", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "RATE_LIMIT_EXCEEDED")
+	assert.Equal(t, "0", w.Header().Get(middleware.RateLimitRemainingHeader))
+	// 再試行までの時間は秒に切り上げる
+	assert.Equal(t, "2", w.Header().Get(middleware.RetryAfterHeader))
+	assert.Equal(t, 1, scripter.args[1])
+	mockLogger.AssertExpectations(t)
+}
+
+func TestRateLimit_ScriptNotLoaded(t *testing.T) {
+	scripter := &fakeScripter{result: []interface{}{int64(1), int64(9), int64(0)}, noScript: true}
+	router, _ := setupRateLimitRouter(scripter, 0)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, scripter.evals)
+	assert.Equal(t, "9", w.Header().Get(middleware.RateLimitRemainingHeader))
+}
+
+func TestRateLimit_RedisUnavailable(t *testing.T) {
+	scripter := &fakeScripter{err: errors.New("connection refused")}
+	router, mockLogger := setupRateLimitRouter(scripter, 0)
+	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+
+	// Redisに接続できない場合はプロセス内のレート制限で判定する
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "60", w.Header().Get(middleware.RateLimitLimitHeader))
+	assert.Equal(t, "9", w.Header().Get(middleware.RateLimitRemainingHeader))
+	mockLogger.AssertExpectations(t)
+}
+
+func TestRateLimit_RedisUnavailableFailClosed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	scripter := &fakeScripter{}
+	rateLimit := middleware.NewRateLimitMiddleware(scripter, new(MockLogger), middleware.RateLimitConfig{
+		RequestsPerMinute: 60,
+		FailureMode:       middleware.RateLimitFailClosed,
+		Available:         func() bool { return false },
+	})
+
+	router := gin.New()
+	router.POST("/track", rateLimit.RateLimit(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "SERVICE_UNAVAILABLE")
+	// 利用できないことが分かっている間はRedisに問い合わせない
+	assert.Nil(t, scripter.keys)
+}
+
+func TestRateLimit_RedisUnavailableFailOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	scripter := &fakeScripter{}
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warnf", mock.Anything, mock.Anything).Once()
+	rateLimit := middleware.NewRateLimitMiddleware(scripter, mockLogger, middleware.RateLimitConfig{
+		RequestsPerMinute: 2,
+		Available:         func() bool { return false },
+		Clock:             func() time.Time { return rateLimitTestNow },
+	})
+
+	router := gin.New()
+	router.POST("/track", rateLimit.RateLimit(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	codes := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/track", nil))
+		codes = append(codes, w.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+	assert.Nil(t, scripter.keys)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestRateLimit_ApplicationPlan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	scripter := &fakeScripter{result: []interface{}{int64(1), int64(9999), int64(0)}}
+	config := middleware.DefaultRateLimitConfig()
+	config.Clock = func() time.Time { return rateLimitTestNow }
+	rateLimit := middleware.NewRateLimitMiddleware(scripter, new(MockLogger), config)
+
+	router := gin.New()
+	router.POST("/track", func(c *gin.Context) {
+		c.Set("app_id", "test-app-id")
+		c.Set("application", &domainmodels.Application{AppID: "test-app-id", Plan: domainmodels.PlanPro})
+		c.Next()
+	}, rateLimit.RateLimit(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("POST", "/track", nil)
+	req.RemoteAddr = "192.0.2.1:12345"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 認証したアプリケーションはIPアドレスに関係なくプランの制限値で数える
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "10000", w.Header().Get(middleware.RateLimitLimitHeader))
+	assert.True(t, strings.HasPrefix(scripter.keys[0], "rate_limit:{app:test-app-id}:m:"))
+	assert.Equal(t, []interface{}{rateLimitTestNow.UnixMilli(), 1, 10000, 200000, 1000}, scripter.args)
+}
+
+func TestRateLimit_AllowHashTag(t *testing.T) {
+	scripter := &fakeScripter{result: []interface{}{int64(1), int64(9), int64(0)}}
+	rateLimit := middleware.NewRateLimitMiddleware(scripter, new(MockLogger), middleware.RateLimitConfig{
+		RequestsPerMinute: 60,
+		RequestsPerHour:   1000,
+		BurstSize:         10,
+		Clock:             func() time.Time { return rateLimitTestNow },
+	})
+
+	_, err := rateLimit.Allow(context.Background(), "burst", 1)
+
+	// ハッシュタグのないキーはキー全体をハッシュタグにする
+	assert.NoError(t, err)
+	for _, key := range scripter.keys {
+		assert.True(t, strings.HasPrefix(key, "{burst}:"), key)
+	}
+}
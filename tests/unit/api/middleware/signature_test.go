@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"accesslog-tracker/internal/api/middleware"
+	domainmodels "accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/utils/crypto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeNonceStore は使用済みの署名をメモリに記録する NonceStore です
+type fakeNonceStore struct {
+	keys map[string]time.Duration
+	err  error
+}
+
+func (s *fakeNonceStore) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	if s.err != nil {
+		return redis.NewBoolResult(false, s.err)
+	}
+	if _, exists := s.keys[key]; exists {
+		return redis.NewBoolResult(false, nil)
+	}
+	s.keys[key] = expiration
+	return redis.NewBoolResult(true, nil)
+}
+
+var signatureTestNow = time.Unix(1700000000, 0)
+
+func setupSignatureRouter(nonces middleware.NonceStore, key *domainmodels.APIKey) (*gin.Engine, *[]byte) {
+	gin.SetMode(gin.TestMode)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+
+	config := middleware.DefaultSignatureConfig()
+	config.Clock = func() time.Time { return signatureTestNow }
+	signature := middleware.NewSignatureMiddleware(nonces, mockLogger, config)
+
+	var body []byte
+	router := gin.New()
+	router.POST("/v1/tracking/track", func(c *gin.Context) {
+		if key != nil {
+			c.Set("application", &domainmodels.Application{AppID: key.AppID, AuthenticatedKey: key})
+		}
+		c.Next()
+	}, signature.Verify(), func(c *gin.Context) {
+		body, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusOK)
+	})
+	return router, &body
+}
+
+func signedRequest(secret, payload string, timestamp time.Time) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	req := httptest.NewRequest("POST", "/v1/tracking/track?source=server", strings.NewReader(payload))
+	req.Header.Set(middleware.SignatureTimestampHeader, ts)
+	req.Header.Set(middleware.SignatureHeader, "sha256="+crypto.SignRequest(secret, "POST", "/v1/tracking/track?source=server", ts, []byte(payload)))
+	return req
+}
+
+func errorCode(w *httptest.ResponseRecorder) string {
+	for _, code := range []string{"SIGNATURE_REQUIRED", "SIGNATURE_INVALID", "SIGNATURE_EXPIRED", "SIGNATURE_REPLAYED", "SERVICE_UNAVAILABLE"} {
+		if strings.Contains(w.Body.String(), `"code":"`+code+`"`) {
+			return code
+		}
+	}
+	return ""
+}
+
+func TestSignature_Verify(t *testing.T) {
+	key := &domainmodels.APIKey{ID: "key-1", AppID: "test-app-id", SigningSecret: "whsec_test"}
+	payload := `{"app_id":"test-app-id","url":"/page"}`
+
+	t.Run("should accept valid signature and keep body", func(t *testing.T) {
+		nonces := &fakeNonceStore{keys: map[string]time.Duration{}}
+		router, body := setupSignatureRouter(nonces, key)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest("whsec_test", payload, signatureTestNow.Add(-time.Minute)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, payload, string(*body))
+		assert.Len(t, nonces.keys, 1)
+		for _, ttl := range nonces.keys {
+			assert.Equal(t, 10*time.Minute, ttl)
+		}
+	})
+
+	t.Run("should reject replayed signature", func(t *testing.T) {
+		router, _ := setupSignatureRouter(&fakeNonceStore{keys: map[string]time.Duration{}}, key)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest("whsec_test", payload, signatureTestNow))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest("whsec_test", payload, signatureTestNow))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "SIGNATURE_REPLAYED", errorCode(w))
+	})
+
+	t.Run("should reject replayed signature in another notation", func(t *testing.T) {
+		router, _ := setupSignatureRouter(&fakeNonceStore{keys: map[string]time.Duration{}}, key)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest("whsec_test", payload, signatureTestNow))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// 大文字や接頭辞の有無を変えただけの同じ署名も再送として拒否する
+		signature := strings.TrimPrefix(signedRequest("whsec_test", payload, signatureTestNow).Header.Get(middleware.SignatureHeader), crypto.SignaturePrefix)
+		for _, variant := range []string{strings.ToUpper(signature), signature, crypto.SignaturePrefix + strings.ToUpper(signature)} {
+			req := signedRequest("whsec_test", payload, signatureTestNow)
+			req.Header.Set(middleware.SignatureHeader, variant)
+
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, variant)
+			assert.Equal(t, "SIGNATURE_REPLAYED", errorCode(w), variant)
+		}
+	})
+
+	t.Run("should reject invalid or missing signature", func(t *testing.T) {
+		router, _ := setupSignatureRouter(&fakeNonceStore{keys: map[string]time.Duration{}}, key)
+
+		tampered := signedRequest("whsec_test", payload, signatureTestNow)
+		tampered.Body = io.NopCloser(strings.NewReader(`{"app_id":"test-app-id","url":"/other"}`))
+		missing := httptest.NewRequest("POST", "/v1/tracking/track", strings.NewReader(payload))
+		badTimestamp := signedRequest("whsec_test", payload, signatureTestNow)
+		badTimestamp.Header.Set(middleware.SignatureTimestampHeader, "yesterday")
+
+		tests := []struct {
+			req  *http.Request
+			code string
+		}{
+			{signedRequest("whsec_other", payload, signatureTestNow), "SIGNATURE_INVALID"},
+			{tampered, "SIGNATURE_INVALID"},
+			{missing, "SIGNATURE_REQUIRED"},
+			{badTimestamp, "SIGNATURE_INVALID"},
+		}
+		for _, tt := range tests {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.code, errorCode(w))
+		}
+	})
+
+	t.Run("should reject timestamp outside window", func(t *testing.T) {
+		router, _ := setupSignatureRouter(&fakeNonceStore{keys: map[string]time.Duration{}}, key)
+
+		for _, ts := range []time.Time{signatureTestNow.Add(-6 * time.Minute), signatureTestNow.Add(6 * time.Minute)} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, signedRequest("whsec_test", payload, ts))
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, "SIGNATURE_EXPIRED", errorCode(w))
+		}
+	})
+
+	t.Run("should return unavailable when nonce store fails", func(t *testing.T) {
+		router, _ := setupSignatureRouter(&fakeNonceStore{err: errors.New("connection refused")}, key)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest("whsec_test", payload, signatureTestNow))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("should skip keys without signing secret", func(t *testing.T) {
+		nonces := &fakeNonceStore{keys: map[string]time.Duration{}}
+		for _, k := range []*domainmodels.APIKey{nil, {ID: "key-2", AppID: "test-app-id"}} {
+			router, body := setupSignatureRouter(nonces, k)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/v1/tracking/track", strings.NewReader(payload)))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, payload, string(*body))
+		}
+		assert.Empty(t, nonces.keys)
+	})
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"accesslog-tracker/internal/api/middleware"
+	"accesslog-tracker/internal/api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// capturedBatch はハンドラーが受け取った値を記録します
+type capturedBatch struct {
+	events []models.TrackingRequest
+	exists bool
+	cost   int
+	body   []byte
+}
+
+func setupTrackingBatchRouter(captured *capturedBatch) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/batch", middleware.TrackingBatch(), func(c *gin.Context) {
+		if events, exists := c.Get("tracking_events"); exists {
+			captured.events = events.([]models.TrackingRequest)
+			captured.exists = true
+		}
+		captured.cost = c.GetInt("rate_limit_cost")
+		captured.body, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestTrackingBatch_JSONArray(t *testing.T) {
+	var captured capturedBatch
+	router := setupTrackingBatchRouter(&captured)
+
+	payload := `[{"app_id":"test_app_123","url":"/a"},{"app_id":"test_app_123","url":"/b"}]`
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(payload))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, captured.cost)
+	assert.True(t, captured.exists)
+	assert.Len(t, captured.events, 2)
+	assert.Equal(t, "/b", captured.events[1].URL)
+
+	// ハンドラーから本文を再度読める
+	assert.Equal(t, payload, string(captured.body))
+}
+
+func TestTrackingBatch_NDJSON(t *testing.T) {
+	var captured capturedBatch
+	router := setupTrackingBatchRouter(&captured)
+
+	payload := "{\"app_id\":\"test_app_123\",\"url\":\"/a\"}\n\n{\"app_id\":\"test_app_123\",\"url\":\"/b\"}\n{\"app_id\":\"test_app_123\",\"url\":\"/c\"}\n"
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, captured.cost)
+}
+
+func TestTrackingBatch_InvalidBody(t *testing.T) {
+	var captured capturedBatch
+	router := setupTrackingBatchRouter(&captured)
+
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader("not json"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 解析に失敗してもハンドラーに処理を任せる
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, captured.exists)
+	assert.Equal(t, 0, captured.cost)
+	assert.Equal(t, "not json", string(captured.body))
+}
+
+func TestTrackingBatch_BodyTooLarge(t *testing.T) {
+	var captured capturedBatch
+	router := setupTrackingBatchRouter(&captured)
+
+	payload := bytes.Repeat([]byte("a"), middleware.MaxBatchBodySize+1)
+	req := httptest.NewRequest("POST", "/batch", bytes.NewReader(payload))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "PAYLOAD_TOO_LARGE")
+}
+
+func TestParseBatchTrackingRequest_Limits(t *testing.T) {
+	_, err := models.ParseBatchTrackingRequest([]byte("[]"))
+	assert.ErrorIs(t, err, models.ErrEmptyBatch)
+
+	_, err = models.ParseBatchTrackingRequest([]byte("  \n "))
+	assert.ErrorIs(t, err, models.ErrEmptyBatch)
+
+	line := `{"app_id":"test_app_123"}` + "\n"
+	_, err = models.ParseBatchTrackingRequest([]byte(strings.Repeat(line, models.MaxBatchTrackingEvents+1)))
+	assert.ErrorIs(t, err, models.ErrBatchTooLarge)
+
+	events, err := models.ParseBatchTrackingRequest([]byte(strings.Repeat(line, models.MaxBatchTrackingEvents)))
+	assert.NoError(t, err)
+	assert.Len(t, events, models.MaxBatchTrackingEvents)
+
+	_, err = models.ParseBatchTrackingRequest([]byte(line + "{broken\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
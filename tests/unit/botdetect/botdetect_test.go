@@ -0,0 +1,158 @@
+package botdetect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/botdetect"
+)
+
+const chromeUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+func TestMatchUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      bool
+	}{
+		{"Googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"Baiduspider", "Mozilla/5.0 (compatible; Baiduspider/2.0; +http://www.baidu.com/search/spider.html)", true},
+		{"AdsBot", "AdsBot-Google (+http://www.google.com/adsbot.html)", true},
+		{"link preview", "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"WhatsApp preview", "WhatsApp/2.23.20.0", true},
+		{"monitoring", "Mozilla/5.0+(compatible; UptimeRobot/2.0; http://www.uptimerobot.com/)", true},
+		{"HTTP library", "python-requests/2.31.0", true},
+		{"curl", "curl/8.4.0", true},
+		{"Chrome", chromeUserAgent, false},
+		{"Safari on iPhone", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, botdetect.MatchUserAgent(tt.userAgent))
+		})
+	}
+}
+
+func TestParsePatterns(t *testing.T) {
+	patterns, err := botdetect.ParsePatterns([]byte("# comment\n\nmyagent\n^acme-\n"))
+	require.NoError(t, err)
+
+	assert.True(t, patterns.Match("Mozilla/5.0 MyAgent/1.0"))
+	assert.True(t, patterns.Match("ACME-Monitor/2"))
+	assert.False(t, patterns.Match("Mozilla/5.0 acme-monitor"))
+	assert.False(t, patterns.Match(chromeUserAgent))
+
+	_, err = botdetect.ParsePatterns([]byte("valid\n(broken\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestDatacenterRanges(t *testing.T) {
+	ranges, err := botdetect.ParseDatacenterRanges([]byte(`
+# provider ranges
+203.0.113.0/24,Example Cloud
+198.51.100.17 single host
+2001:db8:100::/48
+::ffff:192.0.2.0/120
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 4, ranges.Len())
+
+	assert.True(t, ranges.Contains("203.0.113.200"))
+	assert.True(t, ranges.Contains("198.51.100.17"))
+	assert.False(t, ranges.Contains("198.51.100.18"))
+	assert.True(t, ranges.Contains("2001:db8:100:1::1"))
+	assert.False(t, ranges.Contains("2001:db8:200::1"))
+	assert.True(t, ranges.Contains("192.0.2.10"))
+	assert.True(t, ranges.Contains("::ffff:203.0.113.5"))
+	assert.False(t, ranges.Contains("invalid"))
+
+	_, err = botdetect.ParseDatacenterRanges([]byte("203.0.113.0/33\n"))
+	assert.Error(t, err)
+}
+
+func TestDetector_Detect(t *testing.T) {
+	dir := t.TempDir()
+	datacenter := filepath.Join(dir, "datacenter.txt")
+	require.NoError(t, os.WriteFile(datacenter, []byte("203.0.113.0/24\n"), 0o600))
+
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	detector, err := botdetect.New(botdetect.Config{
+		DatacenterPath: datacenter,
+		RateLimit:      2,
+		RateWindow:     time.Minute,
+		Clock:          func() time.Time { return now },
+	})
+	require.NoError(t, err)
+
+	t.Run("human", func(t *testing.T) {
+		result := detector.Detect(botdetect.Event{AppID: "app", UserAgent: chromeUserAgent, IPAddress: "192.0.2.1"})
+		assert.Equal(t, 0, result.Score)
+		assert.False(t, result.IsBot())
+		assert.Empty(t, result.Reason())
+	})
+
+	t.Run("user agent pattern", func(t *testing.T) {
+		result := detector.Detect(botdetect.Event{AppID: "app", UserAgent: "Googlebot/2.1", IPAddress: "192.0.2.2"})
+		assert.Equal(t, botdetect.MaxScore, result.Score)
+		assert.True(t, result.IsBot())
+		assert.Equal(t, botdetect.ReasonUserAgent, result.Reason())
+	})
+
+	t.Run("headless browser", func(t *testing.T) {
+		result := detector.Detect(botdetect.Event{
+			AppID:     "app",
+			UserAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.0.0 Safari/537.36",
+			IPAddress: "192.0.2.3",
+		})
+		assert.True(t, result.IsBot())
+		assert.Equal(t, botdetect.ReasonHeadless, result.Reason())
+
+		result = detector.Detect(botdetect.Event{AppID: "app", UserAgent: chromeUserAgent, IPAddress: "192.0.2.4", Webdriver: true})
+		assert.True(t, result.IsBot())
+	})
+
+	t.Run("datacenter alone is not a bot", func(t *testing.T) {
+		result := detector.Detect(botdetect.Event{AppID: "app", UserAgent: chromeUserAgent, IPAddress: "203.0.113.10"})
+		assert.False(t, result.IsBot())
+		assert.Equal(t, botdetect.ReasonDatacenter, result.Reason())
+	})
+
+	t.Run("datacenter with high rate", func(t *testing.T) {
+		event := botdetect.Event{AppID: "app", UserAgent: chromeUserAgent, IPAddress: "203.0.113.20"}
+		detector.Detect(event)
+		detector.Detect(event)
+
+		result := detector.Detect(event)
+		assert.True(t, result.IsBot())
+		assert.Equal(t, "datacenter,rate", result.Reason())
+
+		// 別のアプリケーションの頻度は別に数える
+		assert.False(t, detector.Detect(botdetect.Event{AppID: "other", UserAgent: chromeUserAgent, IPAddress: "203.0.113.20"}).IsBot())
+	})
+
+	t.Run("rate window resets", func(t *testing.T) {
+		event := botdetect.Event{AppID: "app", UserAgent: chromeUserAgent, IPAddress: "192.0.2.50"}
+		for i := 0; i < 3; i++ {
+			detector.Detect(event)
+		}
+		assert.Equal(t, botdetect.ReasonRate, detector.Detect(event).Reason())
+
+		now = now.Add(time.Minute)
+		assert.Empty(t, detector.Detect(event).Reason())
+	})
+}
+
+func TestNew_InvalidFiles(t *testing.T) {
+	_, err := botdetect.New(botdetect.Config{PatternsPath: filepath.Join(t.TempDir(), "missing.txt")})
+	assert.Error(t, err)
+
+	_, err = botdetect.New(botdetect.Config{DatacenterPath: filepath.Join(t.TempDir(), "missing.txt")})
+	assert.Error(t, err)
+}
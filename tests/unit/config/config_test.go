@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"accesslog-tracker/internal/config"
@@ -59,12 +60,38 @@ func TestConfig_LoadFromEnv(t *testing.T) {
 	os.Setenv("APP_PORT", "9090")
 	os.Setenv("DB_HOST", "env-db-host")
 	os.Setenv("DB_PORT", "5433")
+	os.Setenv("INGEST_QUEUE_SIZE", "2048")
+	os.Setenv("INGEST_FLUSH_INTERVAL", "250ms")
+	os.Setenv("INGEST_RETRY_BACKOFF", "50ms")
+	os.Setenv("ROLLUP_DELAY", "10m")
+	os.Setenv("ROLLUP_MAX_BUCKETS", "48")
+	os.Setenv("RETENTION_INTERVAL", "30m")
+	os.Setenv("RETENTION_BATCH_SIZE", "1000")
+	os.Setenv("PARTITION_GRANULARITY", "day")
+	os.Setenv("PARTITION_RETAIN", "90")
+	os.Setenv("JWT_SECRET", "env-jwt-secret")
+	os.Setenv("ADMIN_TOKEN", "env-admin-token")
+	os.Setenv("API_KEY_ROTATION_GRACE_PERIOD", "1h")
+	os.Setenv("REDIS_FAILURE_MODE", "closed")
 	
 	defer func() {
 		os.Unsetenv("APP_NAME")
 		os.Unsetenv("APP_PORT")
 		os.Unsetenv("DB_HOST")
 		os.Unsetenv("DB_PORT")
+		os.Unsetenv("INGEST_QUEUE_SIZE")
+		os.Unsetenv("INGEST_FLUSH_INTERVAL")
+		os.Unsetenv("INGEST_RETRY_BACKOFF")
+		os.Unsetenv("ROLLUP_DELAY")
+		os.Unsetenv("ROLLUP_MAX_BUCKETS")
+		os.Unsetenv("RETENTION_INTERVAL")
+		os.Unsetenv("RETENTION_BATCH_SIZE")
+		os.Unsetenv("PARTITION_GRANULARITY")
+		os.Unsetenv("PARTITION_RETAIN")
+		os.Unsetenv("JWT_SECRET")
+		os.Unsetenv("ADMIN_TOKEN")
+		os.Unsetenv("API_KEY_ROTATION_GRACE_PERIOD")
+		os.Unsetenv("REDIS_FAILURE_MODE")
 	}()
 	
 	cfg := config.New()
@@ -75,6 +102,34 @@ func TestConfig_LoadFromEnv(t *testing.T) {
 	assert.Equal(t, 9090, cfg.App.Port)
 	assert.Equal(t, "env-db-host", cfg.Database.Host)
 	assert.Equal(t, 5433, cfg.Database.Port)
+	assert.Equal(t, 2048, cfg.Ingestion.QueueSize)
+	assert.Equal(t, 250*time.Millisecond, cfg.GetIngestionFlushInterval())
+	assert.Equal(t, 50*time.Millisecond, cfg.GetIngestionRetryBackoff())
+	assert.Equal(t, 3, cfg.Ingestion.MaxRetries)
+	assert.Equal(t, 10*time.Minute, cfg.GetRollupDelay())
+	assert.Equal(t, 48, cfg.Rollup.MaxBucketsPerRun)
+	assert.Equal(t, time.Minute, cfg.GetRollupInterval())
+	assert.Equal(t, 30*time.Minute, cfg.GetRetentionInterval())
+	assert.Equal(t, 1000, cfg.Retention.BatchSize)
+	assert.Equal(t, 100, cfg.Retention.MaxBatchesPerRun)
+	assert.Equal(t, "day", cfg.Partition.Granularity)
+	assert.Equal(t, 90, cfg.Partition.Retain)
+	assert.Equal(t, 3, cfg.Partition.Premake)
+	assert.Equal(t, time.Hour, cfg.GetPartitionInterval())
+	assert.Equal(t, "env-admin-token", cfg.Admin.Token)
+	assert.Equal(t, "env-jwt-secret", cfg.GetAdminJWTSecret())
+	assert.Equal(t, time.Hour, cfg.GetAPIKeyRotationGracePeriod())
+	assert.Equal(t, "closed", cfg.Redis.FailureMode)
+	assert.Equal(t, 5*time.Second, cfg.GetRedisProbeInterval())
+}
+
+func TestConfig_GetAdminJWTSecret_DefaultSecret(t *testing.T) {
+	cfg := config.New()
+
+	// 開発用のデフォルト値は管理APIのJWT検証に使わない
+	assert.Equal(t, config.DefaultJWTSecret, cfg.JWT.Secret)
+	assert.Empty(t, cfg.GetAdminJWTSecret())
+	assert.Empty(t, cfg.Admin.Token)
 }
 
 func TestConfig_Validate(t *testing.T) {
@@ -142,6 +197,73 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			isValid: false,
 		},
+		{
+			name: "invalid partition granularity",
+			config: &config.Config{
+				App: config.AppConfig{
+					Name: "test-app",
+					Port: 8080,
+				},
+				Database: config.DatabaseConfig{
+					Host: "localhost",
+					Port: 5432,
+					Name: "test_db",
+					User: "test_user",
+				},
+				Redis: config.RedisConfig{
+					Host: "localhost",
+					Port: 6379,
+				},
+				Partition: config.PartitionConfig{
+					Granularity: "week",
+				},
+			},
+			isValid: false,
+		},
+		{
+			name: "invalid redis failure mode",
+			config: &config.Config{
+				App: config.AppConfig{
+					Name: "test-app",
+					Port: 8080,
+				},
+				Database: config.DatabaseConfig{
+					Host: "localhost",
+					Port: 5432,
+					Name: "test_db",
+					User: "test_user",
+				},
+				Redis: config.RedisConfig{
+					Host:        "localhost",
+					Port:        6379,
+					FailureMode: "retry",
+				},
+			},
+			isValid: false,
+		},
+		{
+			name: "invalid api key rotation grace period",
+			config: &config.Config{
+				App: config.AppConfig{
+					Name: "test-app",
+					Port: 8080,
+				},
+				Database: config.DatabaseConfig{
+					Host: "localhost",
+					Port: 5432,
+					Name: "test_db",
+					User: "test_user",
+				},
+				Redis: config.RedisConfig{
+					Host: "localhost",
+					Port: 6379,
+				},
+				APIKeys: config.APIKeyConfig{
+					RotationGracePeriod: "-1h",
+				},
+			},
+			isValid: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+func TestAPIKey_Validate(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	assert.NoError(t, (&models.APIKey{Name: "server"}).Validate(now))
+	assert.NoError(t, (&models.APIKey{Name: "server", ExpiresAt: &future}).Validate(now))
+	assert.ErrorIs(t, (&models.APIKey{Name: " "}).Validate(now), models.ErrAPIKeyNameRequired)
+	assert.ErrorIs(t, (&models.APIKey{Name: "server", ExpiresAt: &past}).Validate(now), models.ErrAPIKeyInvalidExpiry)
+	assert.NoError(t, (&models.APIKey{Name: "server", Scopes: []string{models.ScopeIngest, models.ScopeAppsAdmin}}).Validate(now))
+	assert.ErrorIs(t, (&models.APIKey{Name: "server", Scopes: []string{"stats:write"}}).Validate(now), models.ErrAPIKeyInvalidScope)
+}
+
+func TestAPIKey_HasScopes(t *testing.T) {
+	key := &models.APIKey{Scopes: []string{models.ScopeIngest}}
+
+	assert.True(t, key.HasScopes())
+	assert.True(t, key.HasScopes(models.ScopeIngest))
+	assert.False(t, key.HasScopes(models.ScopeStatsRead))
+	assert.False(t, key.HasScopes(models.ScopeIngest, models.ScopeStatsRead))
+
+	// スコープを指定せずに発行したキーは統計を参照できない
+	defaultKey := &models.APIKey{Scopes: models.DefaultAPIKeyScopes}
+	assert.True(t, defaultKey.HasScopes(models.ScopeIngest))
+	assert.False(t, defaultKey.HasScopes(models.ScopeStatsRead))
+	assert.False(t, defaultKey.HasScopes(models.ScopeAppsAdmin))
+}
+
+func TestAPIKey_IsExpired(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+
+	assert.False(t, (&models.APIKey{}).IsExpired(now))
+	assert.False(t, (&models.APIKey{ExpiresAt: &future}).IsExpired(now))
+	assert.True(t, (&models.APIKey{ExpiresAt: &now}).IsExpired(now))
+}
+
+func TestPrincipal_CanAccessApplication(t *testing.T) {
+	assert.True(t, (&models.Principal{Superuser: true}).CanAccessApplication("app-1"))
+	assert.True(t, (&models.Principal{AppID: "app-1"}).CanAccessApplication("app-1"))
+	assert.False(t, (&models.Principal{AppID: "app-1"}).CanAccessApplication("app-2"))
+}
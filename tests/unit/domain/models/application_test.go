@@ -267,3 +267,39 @@ func TestIsValidAPIKey(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		expected string
+		wantErr  bool
+	}{
+		{"empty defaults to UTC", "", "UTC", false},
+		{"IANA name", "Asia/Tokyo", "Asia/Tokyo", false},
+		{"local is rejected", "Local", "", true},
+		{"unknown name", "Mars/Olympus", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := models.LoadTimezone(tt.timezone)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, models.ErrApplicationInvalidTimezone)
+				assert.False(t, models.IsValidTimezone(tt.timezone))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, loc.String())
+		})
+	}
+}
+
+func TestApplication_Location(t *testing.T) {
+	app := &models.Application{Timezone: "Europe/Berlin"}
+	assert.Equal(t, "Europe/Berlin", app.Location().String())
+
+	// 不正なタイムゾーンはUTCとして扱う
+	app.Timezone = "invalid"
+	assert.Equal(t, "UTC", app.Location().String())
+}
@@ -0,0 +1,47 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+func TestApplication_BotPolicy(t *testing.T) {
+	assert.Equal(t, models.BotPolicyFlag, (&models.Application{}).BotPolicy())
+
+	for _, policy := range []string{models.BotPolicyFlag, models.BotPolicyDrop, models.BotPolicyKeep} {
+		app := &models.Application{Settings: map[string]interface{}{models.SettingBotPolicy: policy}}
+		assert.Equal(t, policy, app.BotPolicy())
+	}
+
+	// 不正な値は flag として扱う
+	app := &models.Application{Settings: map[string]interface{}{models.SettingBotPolicy: "block"}}
+	assert.Equal(t, models.BotPolicyFlag, app.BotPolicy())
+}
+
+func TestValidateSettings_BotPolicy(t *testing.T) {
+	for _, policy := range []interface{}{models.BotPolicyFlag, models.BotPolicyDrop, models.BotPolicyKeep, nil} {
+		assert.NoError(t, models.ValidateSettings(map[string]interface{}{models.SettingBotPolicy: policy}), "%v", policy)
+	}
+	for _, policy := range []interface{}{"block", true, 1} {
+		assert.ErrorIs(t, models.ValidateSettings(map[string]interface{}{models.SettingBotPolicy: policy}), models.ErrApplicationInvalidSettings, "%v", policy)
+	}
+}
+
+func TestTrackingData_HasWebdriverHint(t *testing.T) {
+	assert.False(t, (&models.TrackingData{}).HasWebdriverHint())
+	assert.True(t, (&models.TrackingData{CustomParams: map[string]interface{}{models.CustomParamWebdriver: true}}).HasWebdriverHint())
+	assert.True(t, (&models.TrackingData{CustomParams: map[string]interface{}{models.CustomParamWebdriver: "true"}}).HasWebdriverHint())
+	assert.False(t, (&models.TrackingData{CustomParams: map[string]interface{}{models.CustomParamWebdriver: false}}).HasWebdriverHint())
+}
+
+func TestTrackingData_IsBot_StoredScore(t *testing.T) {
+	// インジェスト時の判定結果がある場合はスコアで判定する
+	data := &models.TrackingData{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36", BotScore: 80, BotReason: "headless"}
+	assert.True(t, data.IsBot())
+
+	data = &models.TrackingData{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36", BotScore: 30, BotReason: "datacenter"}
+	assert.False(t, data.IsBot())
+}
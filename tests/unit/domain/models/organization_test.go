@@ -0,0 +1,80 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+func TestOrganization_Validate(t *testing.T) {
+	assert.NoError(t, (&models.Organization{Name: "Client A"}).Validate())
+	assert.ErrorIs(t, (&models.Organization{Name: "  "}).Validate(), models.ErrOrganizationNameRequired)
+}
+
+func TestUser_Validate(t *testing.T) {
+	assert.NoError(t, (&models.User{Email: "alice@example.com"}).Validate())
+	assert.ErrorIs(t, (&models.User{Email: "alice"}).Validate(), models.ErrUserEmailRequired)
+}
+
+func TestCanManageRole(t *testing.T) {
+	tests := []struct {
+		actor  string
+		target string
+		want   bool
+	}{
+		{models.RoleOwner, models.RoleOwner, true},
+		{models.RoleOwner, models.RoleViewer, true},
+		{models.RoleAdmin, models.RoleOwner, false},
+		{models.RoleAdmin, models.RoleAdmin, true},
+		{models.RoleAdmin, models.RoleViewer, true},
+		{models.RoleViewer, models.RoleViewer, false},
+		{"", models.RoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, models.CanManageRole(tt.actor, tt.target), "%s -> %s", tt.actor, tt.target)
+	}
+}
+
+func TestPrincipal(t *testing.T) {
+	superuser := &models.Principal{Superuser: true}
+	member := &models.Principal{
+		UserID: "user-1",
+		Roles: map[string]string{
+			"org-owner":  models.RoleOwner,
+			"org-admin":  models.RoleAdmin,
+			"org-viewer": models.RoleViewer,
+		},
+	}
+
+	t.Run("superuser", func(t *testing.T) {
+		assert.True(t, superuser.CanView("any-org"))
+		assert.True(t, superuser.CanView(""))
+		assert.True(t, superuser.CanManage("any-org"))
+		assert.True(t, superuser.CanManageMember("any-org", models.RoleOwner))
+	})
+
+	t.Run("view", func(t *testing.T) {
+		assert.True(t, member.CanView("org-owner"))
+		assert.True(t, member.CanView("org-viewer"))
+		assert.False(t, member.CanView("org-other"))
+		// 組織に属さないアプリケーションはスーパーユーザーのみ
+		assert.False(t, member.CanView(""))
+	})
+
+	t.Run("manage", func(t *testing.T) {
+		assert.True(t, member.CanManage("org-owner"))
+		assert.True(t, member.CanManage("org-admin"))
+		assert.False(t, member.CanManage("org-viewer"))
+		assert.False(t, member.CanManage("org-other"))
+	})
+
+	t.Run("manage members", func(t *testing.T) {
+		assert.True(t, member.CanManageMember("org-owner", models.RoleOwner))
+		assert.False(t, member.CanManageMember("org-admin", models.RoleOwner))
+		assert.True(t, member.CanManageMember("org-admin", models.RoleViewer))
+		assert.False(t, member.CanManageMember("org-viewer", models.RoleViewer))
+	})
+}
@@ -0,0 +1,140 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+func TestOriginAllowlist_Allows(t *testing.T) {
+	allowlist := models.OriginAllowlist{"example.com", "*.shop.example.net", "https://app.example.org", "localhost:3000"}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://example.com", true},
+		{"http://example.com:8080", true},
+		{"https://EXAMPLE.com/page?q=1", true},
+		{"https://www.example.com", false},
+		{"https://a.shop.example.net", true},
+		{"https://a.b.shop.example.net", true},
+		{"https://shop.example.net", false},
+		{"https://evilshop.example.net", false},
+		{"https://app.example.org", true},
+		{"http://app.example.org", false},
+		{"https://app.example.org:8443", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:8080", false},
+		{"null", false},
+		{"/relative/path", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, allowlist.Allows(tt.origin), tt.origin)
+	}
+
+	assert.True(t, models.OriginAllowlist{"*"}.Allows("https://anything.test"))
+	assert.False(t, models.OriginAllowlist{}.Allows("https://example.com"))
+}
+
+func TestIsValidOriginPattern(t *testing.T) {
+	for _, pattern := range []string{"*", "example.com", "*.example.com", "https://example.com", "https://*.example.com", "localhost:3000"} {
+		assert.True(t, models.IsValidOriginPattern(pattern), pattern)
+	}
+	for _, pattern := range []string{"", "*.", "ftp://example.com", "https://", "example.com/path", "foo*.example.com", "exa mple.com"} {
+		assert.False(t, models.IsValidOriginPattern(pattern), pattern)
+	}
+}
+
+func TestApplication_AllowedOrigins(t *testing.T) {
+	app := &models.Application{
+		Domain: "example.com",
+		Settings: map[string]interface{}{
+			models.SettingAllowedOrigins: []interface{}{"*.example.com", " HTTPS://Partner.example.net "},
+		},
+	}
+
+	assert.Equal(t, models.OriginAllowlist{"example.com", "*.example.com", "https://partner.example.net"}, app.AllowedOrigins())
+	assert.Equal(t, models.OriginPolicyFlag, app.OriginPolicy())
+
+	// 不正な設定は無視してドメインのみ許可する
+	app.Settings[models.SettingAllowedOrigins] = "*.example.com"
+	assert.Equal(t, models.OriginAllowlist{"example.com"}, app.AllowedOrigins())
+}
+
+func TestApplication_CheckTrackingOrigin(t *testing.T) {
+	newApp := func(policy string) *models.Application {
+		return &models.Application{
+			Domain: "example.com",
+			Settings: map[string]interface{}{
+				models.SettingAllowedOrigins: []interface{}{"*.example.com"},
+				models.SettingOriginPolicy:   policy,
+			},
+		}
+	}
+
+	t.Run("should accept matching origin and page URL", func(t *testing.T) {
+		data := &models.TrackingData{URL: "https://www.example.com/page", CustomParams: map[string]interface{}{models.CustomParamOriginMismatch: true}}
+
+		assert.NoError(t, newApp(models.OriginPolicyReject).CheckTrackingOrigin(data, "https://example.com"))
+		// クライアントが送信した印は取り除く
+		assert.NotContains(t, data.CustomParams, models.CustomParamOriginMismatch)
+	})
+
+	t.Run("should ignore page URL without host", func(t *testing.T) {
+		data := &models.TrackingData{URL: "/page"}
+		assert.NoError(t, newApp(models.OriginPolicyReject).CheckTrackingOrigin(data, ""))
+	})
+
+	t.Run("should flag mismatched origin", func(t *testing.T) {
+		data := &models.TrackingData{URL: "https://example.com/page"}
+
+		assert.NoError(t, newApp(models.OriginPolicyFlag).CheckTrackingOrigin(data, "https://evil.test"))
+		assert.Equal(t, true, data.CustomParams[models.CustomParamOriginMismatch])
+	})
+
+	t.Run("should reject mismatched page URL", func(t *testing.T) {
+		data := &models.TrackingData{URL: "https://evil.test/page"}
+
+		err := newApp(models.OriginPolicyReject).CheckTrackingOrigin(data, "")
+		assert.ErrorIs(t, err, models.ErrTrackingOriginNotAllowed)
+		assert.Nil(t, data.CustomParams)
+	})
+
+	t.Run("should skip applications without allowlist", func(t *testing.T) {
+		data := &models.TrackingData{URL: "https://evil.test/page"}
+
+		assert.NoError(t, (&models.Application{}).CheckTrackingOrigin(data, "https://evil.test"))
+		assert.Nil(t, data.CustomParams)
+	})
+}
+
+func TestValidateSettings_Origins(t *testing.T) {
+	valid := []map[string]interface{}{
+		{models.SettingAllowedOrigins: []interface{}{"*.example.com", "https://partner.example.net"}},
+		{models.SettingAllowedOrigins: nil, models.SettingOriginPolicy: nil},
+		{models.SettingOriginPolicy: models.OriginPolicyReject},
+	}
+	for _, settings := range valid {
+		assert.NoError(t, models.ValidateSettings(settings), "%v", settings)
+	}
+
+	tooMany := make([]interface{}, models.MaxAllowedOrigins+1)
+	for i := range tooMany {
+		tooMany[i] = "example.com"
+	}
+	invalid := []map[string]interface{}{
+		{models.SettingAllowedOrigins: "example.com"},
+		{models.SettingAllowedOrigins: []interface{}{"example.com", 1}},
+		{models.SettingAllowedOrigins: []interface{}{"ftp://example.com"}},
+		{models.SettingAllowedOrigins: tooMany},
+		{models.SettingOriginPolicy: "block"},
+	}
+	for _, settings := range invalid {
+		assert.ErrorIs(t, models.ValidateSettings(settings), models.ErrApplicationInvalidSettings, "%v", settings)
+	}
+}
@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+func TestApplication_PlanLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		plan     string
+		expected string
+	}{
+		{"pro plan", models.PlanPro, models.PlanPro},
+		{"unset plan falls back to default", "", models.DefaultPlan},
+		{"unknown plan falls back to default", "platinum", models.DefaultPlan},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &models.Application{Plan: tt.plan}
+			assert.Equal(t, tt.expected, app.PlanLimits().Name)
+		})
+	}
+}
+
+func TestPlans(t *testing.T) {
+	plans := models.Plans()
+
+	names := make([]string, 0, len(plans))
+	for _, plan := range plans {
+		names = append(names, plan.Name)
+		assert.True(t, models.IsValidPlan(plan.Name))
+	}
+	assert.Equal(t, []string{models.PlanFree, models.PlanPro, models.PlanEnterprise}, names)
+	assert.False(t, models.IsValidPlan("platinum"))
+}
+
+func TestNewUsage(t *testing.T) {
+	period := models.UsagePeriod(time.Date(2024, 12, 31, 23, 59, 0, 0, time.FixedZone("JST", 9*60*60)))
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), period)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), models.NextUsagePeriod(period))
+
+	free, _ := models.LookupPlan(models.PlanFree)
+	usage := models.NewUsage("app", free, period, 1200000)
+	assert.Equal(t, int64(1000000), usage.Quota)
+	assert.Equal(t, int64(0), usage.Remaining)
+
+	enterprise, _ := models.LookupPlan(models.PlanEnterprise)
+	usage = models.NewUsage("app", enterprise, period, 1200000)
+	assert.Equal(t, int64(0), usage.Quota)
+	assert.Equal(t, int64(-1), usage.Remaining)
+}
@@ -0,0 +1,89 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/domain/models"
+)
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "valid retention days",
+			settings: map[string]interface{}{models.SettingRetentionRawDays: float64(90), models.SettingRetentionRollupDays: 730},
+			wantErr:  false,
+		},
+		{
+			name:     "null removes setting",
+			settings: map[string]interface{}{models.SettingRetentionRawDays: nil},
+			wantErr:  false,
+		},
+		{
+			name:     "json number",
+			settings: map[string]interface{}{models.SettingRetentionRawDays: json.Number("30")},
+			wantErr:  false,
+		},
+		{
+			name:     "unknown keys are kept as is",
+			settings: map[string]interface{}{"theme": "dark"},
+			wantErr:  false,
+		},
+		{
+			name:     "negative days",
+			settings: map[string]interface{}{models.SettingRetentionRawDays: float64(-1)},
+			wantErr:  true,
+		},
+		{
+			name:     "fractional days",
+			settings: map[string]interface{}{models.SettingRetentionRollupDays: 1.5},
+			wantErr:  true,
+		},
+		{
+			name:     "too many days",
+			settings: map[string]interface{}{models.SettingRetentionRawDays: models.MaxRetentionDays + 1},
+			wantErr:  true,
+		},
+		{
+			name:     "string value",
+			settings: map[string]interface{}{models.SettingRetentionRawDays: "30"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.ValidateSettings(tt.settings)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, models.ErrApplicationInvalidSettings)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApplication_RetentionPolicy(t *testing.T) {
+	app := &models.Application{
+		AppID: "test_app_123",
+		Settings: map[string]interface{}{
+			models.SettingRetentionRawDays:    float64(30),
+			models.SettingRetentionRollupDays: "invalid",
+		},
+	}
+
+	policy := app.RetentionPolicy()
+	assert.Equal(t, "test_app_123", policy.AppID)
+	assert.Equal(t, 30, policy.RawDays)
+	// 不正な値は削除しない扱いになる
+	assert.Equal(t, 0, policy.RollupDays)
+	assert.True(t, policy.IsEnabled())
+
+	assert.False(t, (&models.Application{AppID: "test_app_456"}).RetentionPolicy().IsEnabled())
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/uaparser"
 )
 
 func TestTrackingData_Validate(t *testing.T) {
@@ -310,6 +311,8 @@ func TestTrackingData_GetDeviceType(t *testing.T) {
 		{"iPhone", "Mozilla/5.0 (iPhone; CPU iPhone OS 14_0 like Mac OS X)", "mobile"},
 		{"iPad", "Mozilla/5.0 (iPad; CPU OS 14_0 like Mac OS X)", "tablet"},
 		{"Desktop", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36", "desktop"},
+		{"Android phone", "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36", "mobile"},
+		{"Android tablet", "Mozilla/5.0 (Linux; Android 12; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "tablet"},
 	}
 
 	for _, tt := range tests {
@@ -331,6 +334,9 @@ func TestTrackingData_GetBrowser(t *testing.T) {
 		{"Firefox", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:89.0) Gecko/20100101 Firefox/89.0", "Firefox"},
 		{"Safari", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15", "Safari"},
 		{"Edge", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 Edg/91.0.864.59", "Edge"},
+		{"Chrome on iOS", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1", "Chrome"},
+		{"Samsung Internet", "Mozilla/5.0 (Linux; Android 13; SAMSUNG SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36", "Samsung Internet"},
+		{"Opera", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 OPR/106.0.0.0", "Opera"},
 		{"Unknown", "Unknown Browser", "Unknown"},
 	}
 
@@ -354,6 +360,7 @@ func TestTrackingData_GetOS(t *testing.T) {
 		{"iOS", "Mozilla/5.0 (iPhone; CPU iPhone OS 14_0 like Mac OS X) AppleWebKit/605.1.15", "iOS"},
 		{"Android", "Mozilla/5.0 (Linux; Android 10; SM-G975F) AppleWebKit/537.36", "Android"},
 		{"Linux", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36", "Linux"},
+		{"Chrome on iOS", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1", "iOS"},
 		{"Unknown", "Unknown OS", "Unknown"},
 	}
 
@@ -461,3 +468,22 @@ func TestTrackingStats_FromJSON(t *testing.T) {
 	assert.Equal(t, int64(50), stats.BotRequests)
 	assert.Equal(t, int64(400), stats.MobileRequests)
 }
+
+func TestTrackingData_ParseUserAgent(t *testing.T) {
+	data := &models.TrackingData{
+		UserAgent: "Mozilla/5.0 (Linux; Android 13; SAMSUNG SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36",
+	}
+	data.ParseUserAgent(uaparser.Default())
+
+	assert.Equal(t, "Samsung Internet", data.Browser)
+	assert.Equal(t, "23.0", data.BrowserVersion)
+	assert.Equal(t, "Android", data.OS)
+	assert.Equal(t, "13", data.OSVersion)
+	assert.Equal(t, "Generic Smartphone", data.DeviceFamily)
+	assert.Equal(t, "mobile", data.DeviceType)
+
+	// 保存済みの解析結果を優先する
+	stored := &models.TrackingData{UserAgent: data.UserAgent, Browser: "Chrome", OS: "Android", DeviceType: "tablet"}
+	assert.Equal(t, "Chrome", stored.GetBrowser())
+	assert.Equal(t, "tablet", stored.GetDeviceType())
+}
@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/urlnorm"
+)
+
+func TestApplication_URLNormalizer(t *testing.T) {
+	assert.Equal(t, urlnorm.DefaultRules(), (&models.Application{}).URLNormalizer().Rules())
+
+	app := &models.Application{Settings: map[string]interface{}{
+		models.SettingURLRules: map[string]interface{}{
+			"strip_query":     true,
+			"query_allowlist": []interface{}{"page"},
+			"trailing_slash":  "strip",
+		},
+	}}
+	rules := app.URLNormalizer().Rules()
+	assert.True(t, rules.StripQuery)
+	assert.Equal(t, []string{"page"}, rules.QueryAllowlist)
+	assert.Equal(t, urlnorm.TrailingSlashStrip, rules.TrailingSlash)
+	// 指定していない項目はデフォルトの値
+	assert.True(t, rules.DropFragment)
+	assert.True(t, rules.LowercaseHost)
+
+	// 不正な値はデフォルトのルールとして扱う
+	app = &models.Application{Settings: map[string]interface{}{models.SettingURLRules: "strip"}}
+	assert.Equal(t, urlnorm.DefaultRules(), app.URLNormalizer().Rules())
+}
+
+func TestValidateSettings_URLRules(t *testing.T) {
+	valid := []interface{}{
+		nil,
+		map[string]interface{}{},
+		map[string]interface{}{"drop_fragment": false, "path_groups": []interface{}{"/product/:id", "/blog/*"}},
+	}
+	for _, value := range valid {
+		assert.NoError(t, models.ValidateSettings(map[string]interface{}{models.SettingURLRules: value}), "%v", value)
+	}
+
+	invalid := []interface{}{
+		"strip",
+		map[string]interface{}{"strip_query": "yes"},
+		map[string]interface{}{"trailing_slash": "sometimes"},
+		map[string]interface{}{"path_groups": []interface{}{"product"}},
+		map[string]interface{}{"unknown": true},
+	}
+	for _, value := range invalid {
+		assert.ErrorIs(t, models.ValidateSettings(map[string]interface{}{models.SettingURLRules: value}), models.ErrApplicationInvalidSettings, "%v", value)
+	}
+}
+
+func TestTrackingData_NormalizeURL(t *testing.T) {
+	normalizer, err := urlnorm.New(urlnorm.Rules{StripQuery: true, PathGroups: []string{"/product/:id"}})
+	require.NoError(t, err)
+
+	data := &models.TrackingData{URL: "https://example.com/product/1?ref=a", URLNormalizer: normalizer}
+	data.NormalizeURL()
+	assert.Equal(t, "https://example.com/product/1", data.PagePath)
+	assert.Equal(t, "/product/:id", data.PageGroup)
+	assert.Equal(t, "https://example.com/product/1", data.GetPagePath())
+
+	// 正規化していないイベントは元のページURL
+	assert.Equal(t, "https://example.com/a#b", (&models.TrackingData{URL: "https://example.com/a#b"}).GetPagePath())
+}
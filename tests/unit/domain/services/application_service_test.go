@@ -2,14 +2,19 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"accesslog-tracker/internal/domain/models"
 	"accesslog-tracker/internal/domain/services"
+	"accesslog-tracker/internal/utils/crypto"
 )
 
 // MockApplicationRepository はアプリケーションリポジトリのモックです
@@ -69,9 +74,45 @@ func (m *MockApplicationRepository) GetByUserID(ctx context.Context, userID stri
 	return args.Get(0).([]*models.Application), args.Error(1)
 }
 
-func (m *MockApplicationRepository) RegenerateAPIKey(ctx context.Context, appID string) (string, error) {
+func (m *MockApplicationRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockApplicationRepository) RotateAPIKey(ctx context.Context, appID, keyID string, newKey *models.APIKey, apiKey string, graceUntil time.Time) (*models.APIKey, error) {
+	args := m.Called(ctx, appID, keyID, newKey, apiKey, graceUntil)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockApplicationRepository) GetByAPIKeyID(ctx context.Context, keyID string) (*models.Application, error) {
+	args := m.Called(ctx, keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Application), args.Error(1)
+}
+
+func (m *MockApplicationRepository) CreateAPIKey(ctx context.Context, key *models.APIKey, apiKey string) error {
+	args := m.Called(ctx, key, apiKey)
+	return args.Error(0)
+}
+
+func (m *MockApplicationRepository) ListAPIKeys(ctx context.Context, appID string) ([]*models.APIKey, error) {
 	args := m.Called(ctx, appID)
-	return args.String(0), args.Error(1)
+	return args.Get(0).([]*models.APIKey), args.Error(1)
+}
+
+func (m *MockApplicationRepository) DeleteAPIKey(ctx context.Context, appID, keyID string) error {
+	args := m.Called(ctx, appID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockApplicationRepository) SetAPIKeySigningSecret(ctx context.Context, appID, keyID, secret string) error {
+	args := m.Called(ctx, appID, keyID, secret)
+	return args.Error(0)
 }
 
 func (m *MockApplicationRepository) UpdateSettings(ctx context.Context, appID string, settings map[string]interface{}) error {
@@ -79,6 +120,11 @@ func (m *MockApplicationRepository) UpdateSettings(ctx context.Context, appID st
 	return args.Error(0)
 }
 
+func (m *MockApplicationRepository) UpdatePlan(ctx context.Context, id, plan string) error {
+	args := m.Called(ctx, id, plan)
+	return args.Error(0)
+}
+
 // MockCacheService はキャッシュサービスのモックです
 type MockCacheService struct {
 	mock.Mock
@@ -94,6 +140,37 @@ func (m *MockCacheService) Set(ctx context.Context, key string, value string, ex
 	return args.Error(0)
 }
 
+// GetJSON は1つ目の戻り値のJSON文字列を dest に読み込みます
+func (m *MockCacheService) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	args := m.Called(ctx, key)
+	if value := args.String(0); value != "" {
+		if err := json.Unmarshal([]byte(value), dest); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockCacheService) SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *MockCacheService) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// mockInvalidator は通知したキャッシュキーを記録するテスト用の通知です
+type mockInvalidator struct {
+	keys []string
+}
+
+func (i *mockInvalidator) Publish(ctx context.Context, keys ...string) error {
+	i.keys = append(i.keys, keys...)
+	return nil
+}
+
 func TestApplicationService_Create(t *testing.T) {
 	mockRepo := &MockApplicationRepository{}
 	mockCache := &MockCacheService{}
@@ -107,8 +184,12 @@ func TestApplicationService_Create(t *testing.T) {
 	}
 
 	t.Run("should create application successfully", func(t *testing.T) {
-		mockRepo.On("Create", ctx, mock.AnythingOfType("*models.Application")).Return(nil).Once()
-		mockCache.On("Set", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Twice()
+		// リポジトリは作成時に発行したAPIキーを設定する
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*models.Application")).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Application).AuthenticatedKey = &models.APIKey{ID: "key_123"}
+		}).Return(nil).Once()
+		mockCache.On("SetJSON", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil).Twice()
+		mockCache.On("Set", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
 
 		err := service.Create(ctx, app)
 
@@ -116,6 +197,9 @@ func TestApplicationService_Create(t *testing.T) {
 		assert.NotEmpty(t, app.AppID)
 		assert.NotEmpty(t, app.APIKey)
 		assert.True(t, app.Active)
+		mockCache.AssertCalled(t, "SetJSON", ctx, "app:id:"+app.AppID, mock.Anything, mock.AnythingOfType("time.Duration"))
+		mockCache.AssertCalled(t, "SetJSON", ctx, "app:apikey:"+crypto.HashSHA256(app.APIKey), mock.Anything, mock.AnythingOfType("time.Duration"))
+		mockCache.AssertCalled(t, "Set", ctx, "app:apikey:id:key_123", "app:apikey:"+crypto.HashSHA256(app.APIKey), mock.AnythingOfType("time.Duration"))
 		mockRepo.AssertExpectations(t)
 		mockCache.AssertExpectations(t)
 	})
@@ -133,7 +217,7 @@ func TestApplicationService_Create(t *testing.T) {
 func TestApplicationService_GetByID(t *testing.T) {
 	mockRepo := &MockApplicationRepository{}
 	mockCache := &MockCacheService{}
-	service := services.NewApplicationService(mockRepo, mockCache)
+	service := services.NewApplicationService(mockRepo, mockCache, services.WithLocalCache(0, 0))
 
 	ctx := context.Background()
 	expectedApp := &models.Application{
@@ -147,10 +231,9 @@ func TestApplicationService_GetByID(t *testing.T) {
 
 	t.Run("should get application from cache", func(t *testing.T) {
 		cacheKey := "app:id:test_app_123"
-		cachedAppID := "test_app_123"
 
-		mockCache.On("Get", ctx, cacheKey).Return(cachedAppID, nil).Once()
-		mockRepo.On("GetByID", ctx, cachedAppID).Return(expectedApp, nil).Once()
+		// キャッシュにはアプリケーション全体を保存し、リポジトリには問い合わせない
+		mockCache.On("GetJSON", ctx, cacheKey).Return(`{"application":{"app_id":"test_app_123","name":"Test App","domain":"test.example.com","is_active":true}}`, nil).Once()
 
 		result, err := service.GetByID(ctx, "test_app_123")
 
@@ -158,17 +241,16 @@ func TestApplicationService_GetByID(t *testing.T) {
 		assert.Equal(t, expectedApp.AppID, result.AppID)
 		assert.Equal(t, expectedApp.Name, result.Name)
 		mockCache.AssertExpectations(t)
-		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetByID", ctx, "test_app_123")
 	})
 
 	t.Run("should get application from repository when not in cache", func(t *testing.T) {
 		cacheKey := "app:id:test_app_123"
-		apiKeyCacheKey := "app:apikey:test_api_key"
 
-		mockCache.On("Get", ctx, cacheKey).Return("", assert.AnError).Once()
+		// IDで取得した場合はAPIキーのキャッシュは作成しない
+		mockCache.On("GetJSON", ctx, cacheKey).Return("", assert.AnError).Once()
 		mockRepo.On("GetByID", ctx, "test_app_123").Return(expectedApp, nil).Once()
-		mockCache.On("Set", ctx, cacheKey, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
-		mockCache.On("Set", ctx, apiKeyCacheKey, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockCache.On("SetJSON", ctx, cacheKey, mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil).Once()
 
 		result, err := service.GetByID(ctx, "test_app_123")
 
@@ -182,7 +264,7 @@ func TestApplicationService_GetByID(t *testing.T) {
 	t.Run("should handle repository error", func(t *testing.T) {
 		cacheKey := "app:id:test_app_123"
 
-		mockCache.On("Get", ctx, cacheKey).Return("", assert.AnError).Once()
+		mockCache.On("GetJSON", ctx, cacheKey).Return("", assert.AnError).Once()
 		mockRepo.On("GetByID", ctx, "test_app_123").Return(nil, assert.AnError).Once()
 
 		result, err := service.GetByID(ctx, "test_app_123")
@@ -197,26 +279,29 @@ func TestApplicationService_GetByID(t *testing.T) {
 func TestApplicationService_GetByAPIKey(t *testing.T) {
 	mockRepo := &MockApplicationRepository{}
 	mockCache := &MockCacheService{}
-	service := services.NewApplicationService(mockRepo, mockCache)
+	service := services.NewApplicationService(mockRepo, mockCache, services.WithLocalCache(0, 0))
 
 	ctx := context.Background()
 	expectedApp := &models.Application{
-		AppID:       "test_app_123",
-		Name:        "Test App",
-		Description: "Test application",
-		Domain:      "test.example.com",
-		APIKey:      "test_api_key",
-		Active:      true,
+		AppID:            "test_app_123",
+		Name:             "Test App",
+		Description:      "Test application",
+		Domain:           "test.example.com",
+		APIKey:           "test_api_key",
+		Active:           true,
+		AuthenticatedKey: &models.APIKey{ID: "key_123", AppID: "test_app_123", Name: models.DefaultAPIKeyName, SigningSecret: "alts_secret"},
 	}
+	// 平文のキーはキャッシュキーに含めない
+	cacheKey := "app:apikey:" + crypto.HashSHA256("test_api_key")
 
 	t.Run("should get application by API key", func(t *testing.T) {
-		cacheKey := "app:apikey:test_api_key"
 		idCacheKey := "app:id:test_app_123"
 
-		mockCache.On("Get", ctx, cacheKey).Return("", assert.AnError).Once()
+		mockCache.On("GetJSON", ctx, cacheKey).Return("", assert.AnError).Once()
 		mockRepo.On("GetByAPIKey", ctx, "test_api_key").Return(expectedApp, nil).Once()
-		mockCache.On("Set", ctx, idCacheKey, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
-		mockCache.On("Set", ctx, cacheKey, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockCache.On("SetJSON", ctx, idCacheKey, mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockCache.On("SetJSON", ctx, cacheKey, mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockCache.On("Set", ctx, "app:apikey:id:key_123", cacheKey, mock.AnythingOfType("time.Duration")).Return(nil).Once()
 
 		result, err := service.GetByAPIKey(ctx, "test_api_key")
 
@@ -225,20 +310,172 @@ func TestApplicationService_GetByAPIKey(t *testing.T) {
 		assert.Equal(t, expectedApp.APIKey, result.APIKey)
 		mockRepo.AssertExpectations(t)
 		mockCache.AssertExpectations(t)
+
+		// キャッシュには平文のキーを保存しない
+		stored, err := json.Marshal(mockCache.Calls[2].Arguments.Get(2))
+		require.NoError(t, err)
+		assert.NotContains(t, string(stored), "test_api_key")
+		assert.Contains(t, string(stored), "alts_secret")
 	})
 
-	t.Run("should handle repository error", func(t *testing.T) {
-		cacheKey := "app:apikey:invalid_key"
+	t.Run("should get application from cache without repository", func(t *testing.T) {
+		mockCache.On("GetJSON", ctx, cacheKey).Return(`{"application":{"app_id":"test_app_123","plan":"pro"},"key":{"id":"key_123","app_id":"test_app_123","scopes":["ingest"],"signing_secret":"alts_secret"}}`, nil).Once()
+
+		result, err := service.GetByAPIKey(ctx, "test_api_key")
+
+		require.NoError(t, err)
+		assert.Equal(t, "test_app_123", result.AppID)
+		assert.Equal(t, models.PlanPro, result.Plan)
+		assert.Equal(t, "test_api_key", result.APIKey)
+		require.NotNil(t, result.AuthenticatedKey)
+		assert.Equal(t, "key_123", result.AuthenticatedKey.ID)
+		assert.Equal(t, []string{models.ScopeIngest}, result.AuthenticatedKey.Scopes)
+		assert.True(t, result.AuthenticatedKey.RequiresSignature())
+		mockRepo.AssertNotCalled(t, "GetByAPIKeyID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should revalidate cached key after grace period", func(t *testing.T) {
+		expired := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+		mockCache.On("GetJSON", ctx, cacheKey).Return(`{"application":{"app_id":"test_app_123"},"key":{"id":"key_123","expires_at":"`+expired+`"}}`, nil).Once()
+		mockCache.On("Delete", ctx, cacheKey).Return(nil).Once()
+		mockRepo.On("GetByAPIKey", ctx, "test_api_key").Return(nil, models.ErrApplicationInvalidAPIKey).Once()
+		mockCache.On("SetJSON", ctx, cacheKey, mock.Anything, services.DefaultInvalidAPIKeyCacheTTL).Return(nil).Once()
+
+		result, err := service.GetByAPIKey(ctx, "test_api_key")
 
-		mockCache.On("Get", ctx, cacheKey).Return("", assert.AnError).Once()
-		mockRepo.On("GetByAPIKey", ctx, "invalid_key").Return(nil, assert.AnError).Once()
+		assert.ErrorIs(t, err, models.ErrApplicationInvalidAPIKey)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should reject cached invalid key without repository", func(t *testing.T) {
+		invalidCacheKey := "app:apikey:" + crypto.HashSHA256("invalid_key")
+		mockCache.On("GetJSON", ctx, invalidCacheKey).Return(`{"invalid":true}`, nil).Once()
 
 		result, err := service.GetByAPIKey(ctx, "invalid_key")
 
+		assert.ErrorIs(t, err, models.ErrApplicationInvalidAPIKey)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "GetByAPIKey", ctx, "invalid_key")
+	})
+
+	t.Run("should handle repository error", func(t *testing.T) {
+		invalidCacheKey := "app:apikey:" + crypto.HashSHA256("broken_key")
+
+		// データベースのエラーは無効なキーとしてキャッシュしない
+		mockCache.On("GetJSON", ctx, invalidCacheKey).Return("", assert.AnError).Once()
+		mockRepo.On("GetByAPIKey", ctx, "broken_key").Return(nil, assert.AnError).Once()
+
+		result, err := service.GetByAPIKey(ctx, "broken_key")
+
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 		mockCache.AssertExpectations(t)
+		mockCache.AssertNotCalled(t, "SetJSON", ctx, invalidCacheKey, mock.Anything, mock.Anything)
+	})
+}
+
+func TestApplicationService_LocalCache(t *testing.T) {
+	ctx := context.Background()
+	app := &models.Application{
+		AppID:            "test_app_123",
+		Name:             "Test App",
+		APIKey:           "test_api_key",
+		AuthenticatedKey: &models.APIKey{ID: "key_123", AppID: "test_app_123"},
+	}
+	cacheKey := "app:apikey:" + crypto.HashSHA256("test_api_key")
+
+	t.Run("should serve repeated lookups from process cache", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		mockCache := &MockCacheService{}
+		service := services.NewApplicationService(mockRepo, mockCache)
+
+		mockCache.On("GetJSON", ctx, cacheKey).Return("", assert.AnError).Once()
+		mockRepo.On("GetByAPIKey", ctx, "test_api_key").Return(app, nil).Once()
+		mockCache.On("SetJSON", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil).Twice()
+		mockCache.On("Set", ctx, "app:apikey:id:key_123", cacheKey, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		for i := 0; i < 3; i++ {
+			result, err := service.GetByAPIKey(ctx, "test_api_key")
+			require.NoError(t, err)
+			assert.Equal(t, "test_app_123", result.AppID)
+			assert.Equal(t, "test_api_key", result.APIKey)
+		}
+		result, err := service.GetByID(ctx, "test_app_123")
+		require.NoError(t, err)
+		assert.Empty(t, result.APIKey)
+		assert.Nil(t, result.AuthenticatedKey)
+
+		// Redisとデータベースへの問い合わせは初回のみ
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should cache invalid key", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		mockCache := &MockCacheService{}
+		service := services.NewApplicationService(mockRepo, mockCache)
+		invalidCacheKey := "app:apikey:" + crypto.HashSHA256("invalid_key")
+
+		mockCache.On("GetJSON", ctx, invalidCacheKey).Return("", assert.AnError).Once()
+		mockRepo.On("GetByAPIKey", ctx, "invalid_key").Return(nil, models.ErrApplicationInvalidAPIKey).Once()
+		mockCache.On("SetJSON", ctx, invalidCacheKey, mock.Anything, services.DefaultInvalidAPIKeyCacheTTL).Return(nil).Once()
+
+		for i := 0; i < 2; i++ {
+			_, err := service.GetByAPIKey(ctx, "invalid_key")
+			assert.ErrorIs(t, err, models.ErrApplicationInvalidAPIKey)
+		}
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should invalidate process cache and notify on update", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		mockCache := &MockCacheService{}
+		invalidator := &mockInvalidator{}
+		service := services.NewApplicationService(mockRepo, mockCache, services.WithCacheInvalidator(invalidator))
+		settings := map[string]interface{}{"setting1": "value1"}
+
+		mockCache.On("GetJSON", ctx, cacheKey).Return("", assert.AnError).Twice()
+		mockRepo.On("GetByAPIKey", ctx, "test_api_key").Return(app, nil).Twice()
+		mockCache.On("SetJSON", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil)
+		mockCache.On("Set", ctx, "app:apikey:id:key_123", cacheKey, mock.AnythingOfType("time.Duration")).Return(nil)
+		mockRepo.On("UpdateSettings", ctx, "test_app_123", settings).Return(nil).Once()
+		mockRepo.On("ListAPIKeys", ctx, "test_app_123").Return([]*models.APIKey{{ID: "key_123"}}, nil).Once()
+		mockCache.On("Get", ctx, "app:apikey:id:key_123").Return(cacheKey, nil).Once()
+		mockCache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil).Times(3)
+
+		_, err := service.GetByAPIKey(ctx, "test_api_key")
+		require.NoError(t, err)
+		require.NoError(t, service.UpdateSettings(ctx, "test_app_123", settings))
+		// 設定を更新するとAPIキーのエントリも削除され、次の認証で読み直す
+		_, err = service.GetByAPIKey(ctx, "test_api_key")
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{"app:id:test_app_123", cacheKey, "app:apikey:id:key_123"}, invalidator.keys)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should evict entries notified by other instances", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		mockCache := &MockCacheService{}
+		service := services.NewApplicationService(mockRepo, mockCache)
+
+		mockCache.On("GetJSON", ctx, "app:id:test_app_123").Return(`{"application":{"app_id":"test_app_123","name":"Old"}}`, nil).Once()
+		mockCache.On("GetJSON", ctx, "app:id:test_app_123").Return(`{"application":{"app_id":"test_app_123","name":"New"}}`, nil).Once()
+
+		result, err := service.GetByID(ctx, "test_app_123")
+		require.NoError(t, err)
+		assert.Equal(t, "Old", result.Name)
+
+		service.EvictLocal("app:id:test_app_123")
+		result, err = service.GetByID(ctx, "test_app_123")
+		require.NoError(t, err)
+		assert.Equal(t, "New", result.Name)
+		mockCache.AssertExpectations(t)
 	})
 }
 
@@ -259,7 +496,8 @@ func TestApplicationService_Update(t *testing.T) {
 
 	t.Run("should update application successfully", func(t *testing.T) {
 		mockRepo.On("Update", ctx, app).Return(nil).Once()
-		mockCache.On("Set", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Twice()
+		mockRepo.On("ListAPIKeys", ctx, "test_app_123").Return([]*models.APIKey{}, nil).Once()
+		mockCache.On("Delete", ctx, "app:id:test_app_123").Return(nil).Once()
 
 		err := service.Update(ctx, app)
 
@@ -286,8 +524,15 @@ func TestApplicationService_Delete(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("should delete application successfully", func(t *testing.T) {
+		apiKeyCacheKey := "app:apikey:" + crypto.HashSHA256("test_api_key")
+
+		mockRepo.On("ListAPIKeys", ctx, "test_app_123").Return([]*models.APIKey{{ID: "key_123"}}, nil).Once()
 		mockRepo.On("Delete", ctx, "test_app_123").Return(nil).Once()
-		mockCache.On("Set", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockCache.On("Delete", ctx, "app:id:test_app_123").Return(nil).Once()
+		// APIキーのエントリもIDからの対応を使って削除する
+		mockCache.On("Get", ctx, "app:apikey:id:key_123").Return(apiKeyCacheKey, nil).Once()
+		mockCache.On("Delete", ctx, apiKeyCacheKey).Return(nil).Once()
+		mockCache.On("Delete", ctx, "app:apikey:id:key_123").Return(nil).Once()
 
 		err := service.Delete(ctx, "test_app_123")
 
@@ -297,6 +542,7 @@ func TestApplicationService_Delete(t *testing.T) {
 	})
 
 	t.Run("should handle repository error", func(t *testing.T) {
+		mockRepo.On("ListAPIKeys", ctx, "test_app_123").Return([]*models.APIKey{}, nil).Once()
 		mockRepo.On("Delete", ctx, "test_app_123").Return(assert.AnError).Once()
 
 		err := service.Delete(ctx, "test_app_123")
@@ -382,35 +628,245 @@ func TestApplicationService_Count(t *testing.T) {
 	})
 }
 
-func TestApplicationService_RegenerateAPIKey(t *testing.T) {
-	mockRepo := &MockApplicationRepository{}
-	mockCache := &MockCacheService{}
-	service := services.NewApplicationService(mockRepo, mockCache)
-
+func TestApplicationService_RotateAPIKey(t *testing.T) {
 	ctx := context.Background()
+	previous := &models.APIKey{ID: "key_123", AppID: "test_app_123", Name: models.DefaultAPIKeyName}
+	apiKeyCacheKey := "app:apikey:" + crypto.HashSHA256("test_api_key")
 
-	t.Run("should regenerate API key successfully", func(t *testing.T) {
-		newAPIKey := "new_api_key_123"
-		mockRepo.On("RegenerateAPIKey", ctx, "test_app_123").Return(newAPIKey, nil).Once()
-		mockCache.On("Set", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+	t.Run("should rotate API key with default grace period", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		mockCache := &MockCacheService{}
+		service := services.NewApplicationService(mockRepo, mockCache)
+		before := time.Now()
 
-		result, err := service.RegenerateAPIKey(ctx, "test_app_123")
+		mockRepo.On("RotateAPIKey", ctx, "test_app_123", "key_123", mock.AnythingOfType("*models.APIKey"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(previous, nil).Once()
+		mockCache.On("Get", ctx, "app:apikey:id:key_123").Return(apiKeyCacheKey, nil).Once()
+		mockCache.On("Delete", ctx, apiKeyCacheKey).Return(nil).Once()
+		mockCache.On("Delete", ctx, "app:apikey:id:key_123").Return(nil).Once()
+
+		rotation, err := service.RotateAPIKey(ctx, "test_app_123", "key_123", nil)
 
 		assert.NoError(t, err)
-		assert.Equal(t, newAPIKey, result)
+		assert.Len(t, rotation.Secret, 32)
+		assert.Equal(t, previous, rotation.Previous)
+		graceUntil := mockRepo.Calls[0].Arguments.Get(5).(time.Time)
+		assert.WithinDuration(t, before.Add(models.DefaultAPIKeyRotationGracePeriod), graceUntil, time.Minute)
 		mockRepo.AssertExpectations(t)
 		mockCache.AssertExpectations(t)
 	})
 
-	t.Run("should handle repository error", func(t *testing.T) {
-		mockRepo.On("RegenerateAPIKey", ctx, "test_app_123").Return("", assert.AnError).Once()
+	t.Run("should use configured and requested grace periods", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		mockCache := &MockCacheService{}
+		service := services.NewApplicationService(mockRepo, mockCache, services.WithAPIKeyRotationGracePeriod(time.Hour))
+		before := time.Now()
 
-		result, err := service.RegenerateAPIKey(ctx, "test_app_123")
+		mockRepo.On("RotateAPIKey", ctx, "test_app_123", "key_123", mock.AnythingOfType("*models.APIKey"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(previous, nil).Twice()
+		mockCache.On("Get", ctx, "app:apikey:id:key_123").Return("", assert.AnError).Twice()
+		mockCache.On("Delete", ctx, "app:apikey:id:key_123").Return(nil).Twice()
 
-		assert.Error(t, err)
-		assert.Empty(t, result)
+		_, err := service.RotateAPIKey(ctx, "test_app_123", "key_123", nil)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, before.Add(time.Hour), mockRepo.Calls[0].Arguments.Get(5).(time.Time), time.Minute)
+
+		immediate := time.Duration(0)
+		_, err = service.RotateAPIKey(ctx, "test_app_123", "key_123", &immediate)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, before, mockRepo.Calls[1].Arguments.Get(5).(time.Time), time.Minute)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("should reject invalid grace period", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		service := services.NewApplicationService(mockRepo, &MockCacheService{})
+		tooLong := models.MaxAPIKeyRotationGracePeriod + time.Second
+
+		_, err := service.RotateAPIKey(ctx, "test_app_123", "key_123", &tooLong)
+
+		assert.True(t, models.IsValidationError(err))
+		assert.ErrorIs(t, err, models.ErrAPIKeyInvalidGracePeriod)
+		mockRepo.AssertNotCalled(t, "RotateAPIKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return not found for unknown key", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		service := services.NewApplicationService(mockRepo, &MockCacheService{})
+
+		mockRepo.On("RotateAPIKey", ctx, "test_app_123", "missing", mock.AnythingOfType("*models.APIKey"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil, models.ErrAPIKeyNotFound).Once()
+
+		rotation, err := service.RotateAPIKey(ctx, "test_app_123", "missing", nil)
+
+		assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
+		assert.Nil(t, rotation)
+	})
+}
+
+func TestApplicationService_CreateAPIKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should issue named API key", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		service := services.NewApplicationService(mockRepo, &MockCacheService{})
+		expiresAt := time.Now().Add(24 * time.Hour)
+
+		mockRepo.On("CreateAPIKey", ctx, mock.AnythingOfType("*models.APIKey"), mock.AnythingOfType("string")).Return(nil).Once()
+
+		key, apiKey, err := service.CreateAPIKey(ctx, "test_app_123", "server", []string{models.ScopeStatsRead}, &expiresAt, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "test_app_123", key.AppID)
+		assert.Equal(t, "server", key.Name)
+		assert.Equal(t, &expiresAt, key.ExpiresAt)
+		assert.Equal(t, []string{models.ScopeStatsRead}, key.Scopes)
+		assert.Len(t, apiKey, 32)
+		assert.False(t, key.RequiresSignature())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should issue signing secret for signed key", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		service := services.NewApplicationService(mockRepo, &MockCacheService{})
+
+		mockRepo.On("CreateAPIKey", ctx, mock.MatchedBy(func(key *models.APIKey) bool {
+			return strings.HasPrefix(key.SigningSecret, crypto.SigningSecretPrefix)
+		}), mock.AnythingOfType("string")).Return(nil).Once()
+
+		key, _, err := service.CreateAPIKey(ctx, "test_app_123", "server", nil, nil, true)
+
+		assert.NoError(t, err)
+		assert.True(t, key.RequiresSignature())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject expiry in the past", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		service := services.NewApplicationService(mockRepo, &MockCacheService{})
+		expiresAt := time.Now().Add(-time.Hour)
+
+		_, _, err := service.CreateAPIKey(ctx, "test_app_123", "server", nil, &expiresAt, false)
+
+		assert.True(t, models.IsValidationError(err))
+		assert.ErrorIs(t, err, models.ErrAPIKeyInvalidExpiry)
+		mockRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject unknown scope", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		service := services.NewApplicationService(mockRepo, &MockCacheService{})
+
+		_, _, err := service.CreateAPIKey(ctx, "test_app_123", "server", []string{"stats:write"}, nil, false)
+
+		assert.True(t, models.IsValidationError(err))
+		assert.ErrorIs(t, err, models.ErrAPIKeyInvalidScope)
+		mockRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return not found for unknown application", func(t *testing.T) {
+		mockRepo := &MockApplicationRepository{}
+		service := services.NewApplicationService(mockRepo, &MockCacheService{})
+
+		mockRepo.On("CreateAPIKey", ctx, mock.AnythingOfType("*models.APIKey"), mock.AnythingOfType("string")).Return(models.ErrApplicationNotFound).Once()
+
+		key, apiKey, err := service.CreateAPIKey(ctx, "missing", "server", nil, nil, false)
+
+		assert.ErrorIs(t, err, models.ErrApplicationNotFound)
+		assert.Nil(t, key)
+		assert.Empty(t, apiKey)
+	})
+}
+
+func TestApplicationService_DeleteAPIKey(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &MockApplicationRepository{}
+	mockCache := &MockCacheService{}
+	service := services.NewApplicationService(mockRepo, mockCache)
+	apiKeyCacheKey := "app:apikey:" + crypto.HashSHA256("test_api_key")
+
+	mockRepo.On("DeleteAPIKey", ctx, "test_app_123", "key_123").Return(nil).Once()
+	mockRepo.On("DeleteAPIKey", ctx, "test_app_123", "missing").Return(models.ErrAPIKeyNotFound).Once()
+	mockCache.On("Get", ctx, "app:apikey:id:key_123").Return(apiKeyCacheKey, nil).Once()
+	mockCache.On("Delete", ctx, apiKeyCacheKey).Return(nil).Once()
+	mockCache.On("Delete", ctx, "app:apikey:id:key_123").Return(nil).Once()
+
+	assert.NoError(t, service.DeleteAPIKey(ctx, "test_app_123", "key_123"))
+	assert.ErrorIs(t, service.DeleteAPIKey(ctx, "test_app_123", "missing"), models.ErrAPIKeyNotFound)
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestApplicationService_SigningSecret(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &MockApplicationRepository{}
+	mockCache := &MockCacheService{}
+	service := services.NewApplicationService(mockRepo, mockCache)
+	mockCache.On("Get", ctx, "app:apikey:id:key_123").Return("", assert.AnError).Twice()
+	mockCache.On("Delete", ctx, "app:apikey:id:key_123").Return(nil).Twice()
+
+	var stored string
+	mockRepo.On("SetAPIKeySigningSecret", ctx, "test_app_123", "key_123", mock.MatchedBy(func(secret string) bool {
+		return secret != ""
+	})).Run(func(args mock.Arguments) {
+		stored = args.String(3)
+	}).Return(nil).Once()
+	mockRepo.On("SetAPIKeySigningSecret", ctx, "test_app_123", "missing", mock.AnythingOfType("string")).Return(models.ErrAPIKeyNotFound).Once()
+	mockRepo.On("SetAPIKeySigningSecret", ctx, "test_app_123", "key_123", "").Return(nil).Once()
+
+	secret, err := service.GenerateSigningSecret(ctx, "test_app_123", "key_123")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, crypto.SigningSecretPrefix))
+	assert.Equal(t, stored, secret)
+
+	_, err = service.GenerateSigningSecret(ctx, "test_app_123", "missing")
+	assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
+
+	assert.NoError(t, service.DeleteSigningSecret(ctx, "test_app_123", "key_123"))
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestApplicationService_SigningSecret_InvalidatesCache(t *testing.T) {
+	ctx := context.Background()
+	cacheKey := "app:apikey:" + crypto.HashSHA256("test_api_key")
+	appWithSecret := func(secret string) *models.Application {
+		return &models.Application{
+			AppID:            "test_app_123",
+			APIKey:           "test_api_key",
+			AuthenticatedKey: &models.APIKey{ID: "key_123", AppID: "test_app_123", SigningSecret: secret},
+		}
+	}
+
+	mockRepo := &MockApplicationRepository{}
+	mockCache := &MockCacheService{}
+	invalidator := &mockInvalidator{}
+	service := services.NewApplicationService(mockRepo, mockCache, services.WithCacheInvalidator(invalidator))
+
+	var rotated string
+	mockCache.On("GetJSON", ctx, cacheKey).Return("", assert.AnError).Twice()
+	mockRepo.On("GetByAPIKey", ctx, "test_api_key").Return(appWithSecret("alts_old"), nil).Once()
+	mockCache.On("SetJSON", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil)
+	mockCache.On("Set", ctx, "app:apikey:id:key_123", cacheKey, mock.AnythingOfType("time.Duration")).Return(nil)
+	mockRepo.On("SetAPIKeySigningSecret", ctx, "test_app_123", "key_123", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		rotated = args.String(3)
+		mockRepo.On("GetByAPIKey", ctx, "test_api_key").Return(appWithSecret(rotated), nil).Once()
+	}).Return(nil).Once()
+	mockCache.On("Get", ctx, "app:apikey:id:key_123").Return(cacheKey, nil).Once()
+	mockCache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil).Twice()
+
+	// キャッシュを温めてから署名シークレットをローテーションする
+	result, err := service.GetByAPIKey(ctx, "test_api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "alts_old", result.AuthenticatedKey.SigningSecret)
+
+	secret, err := service.GenerateSigningSecret(ctx, "test_app_123", "key_123")
+	require.NoError(t, err)
+
+	// ローテーション後の認証では新しいシークレットを返す
+	result, err = service.GetByAPIKey(ctx, "test_api_key")
+	require.NoError(t, err)
+	assert.Equal(t, secret, result.AuthenticatedKey.SigningSecret)
+	assert.ElementsMatch(t, []string{cacheKey, "app:apikey:id:key_123"}, invalidator.keys)
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
 }
 
 func TestApplicationService_UpdateSettings(t *testing.T) {
@@ -426,7 +882,8 @@ func TestApplicationService_UpdateSettings(t *testing.T) {
 
 	t.Run("should update settings successfully", func(t *testing.T) {
 		mockRepo.On("UpdateSettings", ctx, "test_app_123", settings).Return(nil).Once()
-		mockCache.On("Set", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockRepo.On("ListAPIKeys", ctx, "test_app_123").Return([]*models.APIKey{}, nil).Once()
+		mockCache.On("Delete", ctx, "app:id:test_app_123").Return(nil).Once()
 
 		err := service.UpdateSettings(ctx, "test_app_123", settings)
 
@@ -443,4 +900,221 @@ func TestApplicationService_UpdateSettings(t *testing.T) {
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
 	})
+	t.Run("should reject invalid retention settings", func(t *testing.T) {
+		invalid := map[string]interface{}{models.SettingRetentionRawDays: -1}
+
+		err := service.UpdateSettings(ctx, "test_app_123", invalid)
+
+		assert.ErrorIs(t, err, models.ErrApplicationInvalidSettings)
+		mockRepo.AssertNotCalled(t, "UpdateSettings", ctx, "test_app_123", invalid)
+	})
+}
+
+// fakeUsageCounter はメモリ上で月間のイベント数を数えるテスト用カウンターです
+type fakeUsageCounter struct {
+	events   map[string]int64
+	reserved map[string]int64
+	err      error
+}
+
+func (c *fakeUsageCounter) ReserveUsage(ctx context.Context, appID string, period time.Time, events, quota int64) (int64, bool, error) {
+	if c.err != nil {
+		return 0, false, c.err
+	}
+	if c.reserved == nil {
+		c.reserved = make(map[string]int64)
+	}
+	current := c.events[appID] + c.reserved[appID]
+	if quota > 0 && current+events > quota {
+		return current, false, nil
+	}
+	c.reserved[appID] += events
+	return current + events, true, nil
+}
+
+func (c *fakeUsageCounter) CommitUsage(ctx context.Context, appID string, period time.Time, reserved, accepted int64) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.reserved[appID] -= reserved
+	c.events[appID] += accepted
+	return nil
+}
+
+func (c *fakeUsageCounter) GetUsage(ctx context.Context, appID string, period time.Time) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.events[appID], nil
+}
+
+// fakeUsageRepository は保存済みの月間のイベント数を返すテスト用リポジトリです
+type fakeUsageRepository struct {
+	events map[string]int64
+}
+
+func (r *fakeUsageRepository) GetUsage(ctx context.Context, appID string, period time.Time) (int64, error) {
+	return r.events[appID], nil
+}
+
+func TestApplicationService_ReserveQuota(t *testing.T) {
+	ctx := context.Background()
+	app := &models.Application{AppID: "test_app_123", Plan: models.PlanFree}
+
+	t.Run("should reserve events within quota", func(t *testing.T) {
+		counter := &fakeUsageCounter{events: map[string]int64{"test_app_123": 10}}
+		service := services.NewApplicationService(&MockApplicationRepository{}, &MockCacheService{}, services.WithUsage(counter, nil))
+
+		usage, err := service.ReserveQuota(ctx, app, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(15), usage.Events)
+		assert.Equal(t, int64(1000000), usage.Quota)
+		assert.Equal(t, int64(1000000-15), usage.Remaining)
+		assert.Equal(t, models.PlanFree, usage.Plan)
+		assert.Equal(t, models.NextUsagePeriod(usage.PeriodStart), usage.PeriodEnd)
+	})
+
+	t.Run("should reject events over quota without reserving", func(t *testing.T) {
+		counter := &fakeUsageCounter{events: map[string]int64{"test_app_123": 999999}}
+		service := services.NewApplicationService(&MockApplicationRepository{}, &MockCacheService{}, services.WithUsage(counter, nil))
+
+		usage, err := service.ReserveQuota(ctx, app, 2)
+
+		assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+		require.NotNil(t, usage)
+		assert.Equal(t, int64(999999), usage.Events)
+		assert.Equal(t, int64(999999), counter.events["test_app_123"])
+	})
+
+	t.Run("should not limit enterprise plan", func(t *testing.T) {
+		counter := &fakeUsageCounter{events: map[string]int64{"big_app": 1 << 40}}
+		service := services.NewApplicationService(&MockApplicationRepository{}, &MockCacheService{}, services.WithUsage(counter, nil))
+
+		usage, err := service.ReserveQuota(ctx, &models.Application{AppID: "big_app", Plan: models.PlanEnterprise}, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), usage.Quota)
+		assert.Equal(t, int64(-1), usage.Remaining)
+	})
+
+	t.Run("should do nothing without counter", func(t *testing.T) {
+		service := services.NewApplicationService(&MockApplicationRepository{}, &MockCacheService{})
+
+		usage, err := service.ReserveQuota(ctx, app, 1)
+
+		assert.NoError(t, err)
+		assert.Nil(t, usage)
+	})
+
+	t.Run("should return counter error", func(t *testing.T) {
+		counter := &fakeUsageCounter{err: errors.New("connection refused")}
+		service := services.NewApplicationService(&MockApplicationRepository{}, &MockCacheService{}, services.WithUsage(counter, nil))
+
+		_, err := service.ReserveQuota(ctx, app, 1)
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, models.ErrQuotaExceeded)
+	})
+}
+
+func TestApplicationService_CommitQuota(t *testing.T) {
+	ctx := context.Background()
+	app := &models.Application{AppID: "test_app_123", Plan: models.PlanFree}
+
+	t.Run("should count only accepted events", func(t *testing.T) {
+		counter := &fakeUsageCounter{events: map[string]int64{"test_app_123": 10}}
+		service := services.NewApplicationService(&MockApplicationRepository{}, &MockCacheService{}, services.WithUsage(counter, nil))
+
+		usage, err := service.ReserveQuota(ctx, app, 5)
+		require.NoError(t, err)
+		// 確保中のイベントは確定したイベント数に含めない
+		assert.Equal(t, int64(10), counter.events["test_app_123"])
+		require.NoError(t, service.CommitQuota(ctx, usage, 5, 2))
+
+		assert.Equal(t, int64(12), counter.events["test_app_123"])
+		assert.Equal(t, int64(0), counter.reserved["test_app_123"])
+	})
+
+	t.Run("should count reserved events against quota", func(t *testing.T) {
+		counter := &fakeUsageCounter{events: map[string]int64{"test_app_123": 999990}}
+		service := services.NewApplicationService(&MockApplicationRepository{}, &MockCacheService{}, services.WithUsage(counter, nil))
+
+		_, err := service.ReserveQuota(ctx, app, 8)
+		require.NoError(t, err)
+		_, err = service.ReserveQuota(ctx, app, 8)
+
+		assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+	})
+
+	t.Run("should do nothing without usage", func(t *testing.T) {
+		counter := &fakeUsageCounter{events: map[string]int64{"test_app_123": 10}}
+		service := services.NewApplicationService(&MockApplicationRepository{}, &MockCacheService{}, services.WithUsage(counter, nil))
+
+		assert.NoError(t, service.CommitQuota(ctx, nil, 3, 0))
+		assert.Equal(t, int64(10), counter.events["test_app_123"])
+	})
+}
+
+func TestApplicationService_GetUsage(t *testing.T) {
+	ctx := context.Background()
+	app := &models.Application{AppID: "test_app_123", Plan: models.PlanPro}
+
+	newService := func(counter *fakeUsageCounter, saved int64) *services.ApplicationService {
+		mockRepo := &MockApplicationRepository{}
+		mockCache := &MockCacheService{}
+		mockCache.On("GetJSON", ctx, "app:id:test_app_123").Return("", errors.New("cache miss"))
+		mockCache.On("SetJSON", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("GetByID", ctx, "test_app_123").Return(app, nil)
+		repo := &fakeUsageRepository{events: map[string]int64{"test_app_123": saved}}
+		return services.NewApplicationService(mockRepo, mockCache, services.WithUsage(counter, repo))
+	}
+
+	t.Run("should use larger of counter and saved usage", func(t *testing.T) {
+		service := newService(&fakeUsageCounter{events: map[string]int64{"test_app_123": 120}}, 100)
+
+		usage, err := service.GetUsage(ctx, "test_app_123")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(120), usage.Events)
+		assert.Equal(t, int64(20000000-120), usage.Remaining)
+	})
+
+	t.Run("should fall back to saved usage when counter is unavailable", func(t *testing.T) {
+		service := newService(&fakeUsageCounter{err: errors.New("connection refused")}, 100)
+
+		usage, err := service.GetUsage(ctx, "test_app_123")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), usage.Events)
+	})
+}
+
+func TestApplicationService_UpdatePlan(t *testing.T) {
+	mockRepo := &MockApplicationRepository{}
+	mockCache := &MockCacheService{}
+	service := services.NewApplicationService(mockRepo, mockCache)
+	ctx := context.Background()
+
+	t.Run("should update plan and invalidate cache", func(t *testing.T) {
+		mockRepo.On("UpdatePlan", ctx, "test_app_123", models.PlanPro).Return(nil).Once()
+		mockRepo.On("ListAPIKeys", ctx, "test_app_123").Return([]*models.APIKey{{ID: "key_123"}}, nil).Once()
+		mockCache.On("Get", ctx, "app:apikey:id:key_123").Return("", assert.AnError).Once()
+		mockCache.On("Delete", ctx, "app:id:test_app_123").Return(nil).Once()
+		mockCache.On("Delete", ctx, "app:apikey:id:key_123").Return(nil).Once()
+
+		err := service.UpdatePlan(ctx, "test_app_123", models.PlanPro)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should reject unknown plan", func(t *testing.T) {
+		err := service.UpdatePlan(ctx, "test_app_123", "platinum")
+
+		assert.ErrorIs(t, err, models.ErrApplicationInvalidPlan)
+		assert.True(t, models.IsValidationError(err))
+		mockRepo.AssertNotCalled(t, "UpdatePlan", ctx, "test_app_123", "platinum")
+	})
 }
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/domain/services"
+)
+
+// MockOrganizationRepository は組織リポジトリのモックです
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization, ownerUserID string) error {
+	args := m.Called(ctx, org, ownerUserID)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetOrganization(ctx context.Context, id string) (*models.Organization, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListOrganizations(ctx context.Context) ([]*models.Organization, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListOrganizationsByUserID(ctx context.Context, userID string) ([]*models.Organization, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) CreateUser(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetMembership(ctx context.Context, organizationID, userID string) (*models.Membership, error) {
+	args := m.Called(ctx, organizationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Membership), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) SetMember(ctx context.Context, membership *models.Membership) error {
+	args := m.Called(ctx, membership)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	args := m.Called(ctx, organizationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) ListMembers(ctx context.Context, organizationID string) ([]*models.Membership, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).([]*models.Membership), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) CountOwners(ctx context.Context, organizationID string) (int, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetUserRoles(ctx context.Context, userID string) (map[string]string, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func TestOrganizationService_CreateOrganization(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create organization with owner", func(t *testing.T) {
+		mockRepo := &MockOrganizationRepository{}
+		service := services.NewOrganizationService(mockRepo)
+		org := &models.Organization{Name: "Client A"}
+		mockRepo.On("CreateOrganization", ctx, org, "user-1").Return(nil).Once()
+
+		assert.NoError(t, service.CreateOrganization(ctx, org, "user-1"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject empty name", func(t *testing.T) {
+		mockRepo := &MockOrganizationRepository{}
+		service := services.NewOrganizationService(mockRepo)
+
+		err := service.CreateOrganization(ctx, &models.Organization{}, "user-1")
+
+		assert.True(t, models.IsValidationError(err))
+		assert.ErrorIs(t, err, models.ErrOrganizationNameRequired)
+		mockRepo.AssertNotCalled(t, "CreateOrganization", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestOrganizationService_ListOrganizations(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &MockOrganizationRepository{}
+	service := services.NewOrganizationService(mockRepo)
+
+	all := []*models.Organization{{ID: "org-1"}, {ID: "org-2"}}
+	mine := []*models.Organization{{ID: "org-1"}}
+	mockRepo.On("ListOrganizations", ctx).Return(all, nil).Once()
+	mockRepo.On("ListOrganizationsByUserID", ctx, "user-1").Return(mine, nil).Once()
+
+	orgs, err := service.ListOrganizations(ctx, &models.Principal{Superuser: true})
+	assert.NoError(t, err)
+	assert.Len(t, orgs, 2)
+
+	orgs, err = service.ListOrganizations(ctx, &models.Principal{UserID: "user-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, mine, orgs)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrganizationService_SetMember(t *testing.T) {
+	ctx := context.Background()
+	org := &models.Organization{ID: "org-1", Name: "Client A"}
+	user := &models.User{ID: "user-1", Email: "alice@example.com"}
+
+	t.Run("should add member", func(t *testing.T) {
+		mockRepo := &MockOrganizationRepository{}
+		service := services.NewOrganizationService(mockRepo)
+		membership := &models.Membership{OrganizationID: "org-1", UserID: "user-1", Role: models.RoleViewer}
+
+		mockRepo.On("GetOrganization", ctx, "org-1").Return(org, nil).Once()
+		mockRepo.On("GetUser", ctx, "user-1").Return(user, nil).Once()
+		mockRepo.On("GetMembership", ctx, "org-1", "user-1").Return(nil, models.ErrMembershipNotFound).Once()
+		mockRepo.On("SetMember", ctx, membership).Return(nil).Once()
+
+		assert.NoError(t, service.SetMember(ctx, membership))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid role", func(t *testing.T) {
+		mockRepo := &MockOrganizationRepository{}
+		service := services.NewOrganizationService(mockRepo)
+
+		err := service.SetMember(ctx, &models.Membership{OrganizationID: "org-1", UserID: "user-1", Role: "superadmin"})
+
+		assert.True(t, models.IsValidationError(err))
+		assert.ErrorIs(t, err, models.ErrMembershipInvalidRole)
+		mockRepo.AssertNotCalled(t, "SetMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not demote last owner", func(t *testing.T) {
+		mockRepo := &MockOrganizationRepository{}
+		service := services.NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganization", ctx, "org-1").Return(org, nil).Once()
+		mockRepo.On("GetUser", ctx, "user-1").Return(user, nil).Once()
+		mockRepo.On("GetMembership", ctx, "org-1", "user-1").Return(&models.Membership{Role: models.RoleOwner}, nil).Once()
+		mockRepo.On("CountOwners", ctx, "org-1").Return(1, nil).Once()
+
+		err := service.SetMember(ctx, &models.Membership{OrganizationID: "org-1", UserID: "user-1", Role: models.RoleAdmin})
+
+		assert.ErrorIs(t, err, models.ErrMembershipLastOwner)
+		mockRepo.AssertNotCalled(t, "SetMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return not found for unknown user", func(t *testing.T) {
+		mockRepo := &MockOrganizationRepository{}
+		service := services.NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganization", ctx, "org-1").Return(org, nil).Once()
+		mockRepo.On("GetUser", ctx, "missing").Return(nil, models.ErrUserNotFound).Once()
+
+		err := service.SetMember(ctx, &models.Membership{OrganizationID: "org-1", UserID: "missing", Role: models.RoleViewer})
+
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+	})
+}
+
+func TestOrganizationService_RemoveMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should not remove last owner", func(t *testing.T) {
+		mockRepo := &MockOrganizationRepository{}
+		service := services.NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetMembership", ctx, "org-1", "user-1").Return(&models.Membership{Role: models.RoleOwner}, nil).Once()
+		mockRepo.On("CountOwners", ctx, "org-1").Return(1, nil).Once()
+
+		err := service.RemoveMember(ctx, "org-1", "user-1")
+
+		assert.ErrorIs(t, err, models.ErrMembershipLastOwner)
+		mockRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should remove owner when another owner remains", func(t *testing.T) {
+		mockRepo := &MockOrganizationRepository{}
+		service := services.NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetMembership", ctx, "org-1", "user-1").Return(&models.Membership{Role: models.RoleOwner}, nil).Once()
+		mockRepo.On("CountOwners", ctx, "org-1").Return(2, nil).Once()
+		mockRepo.On("RemoveMember", ctx, "org-1", "user-1").Return(nil).Once()
+
+		assert.NoError(t, service.RemoveMember(ctx, "org-1", "user-1"))
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,101 @@
+package geoip
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/geoip"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// fixtureDir は tests/fixtures/geoip/generate.go で作成したテスト用のデータベースのディレクトリです
+const fixtureDir = "../../fixtures/geoip"
+
+func newTestLogger() logger.Logger {
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func TestResolver_Lookup(t *testing.T) {
+	resolver := geoip.NewResolver(geoip.Config{
+		CityPath: filepath.Join(fixtureDir, "GeoLite2-City-Test.mmdb"),
+		ASNPath:  filepath.Join(fixtureDir, "GeoLite2-ASN-Test.mmdb"),
+	}, newTestLogger())
+	require.NoError(t, resolver.Reload())
+
+	location, ok := resolver.Lookup("203.0.113.42")
+	require.True(t, ok)
+	assert.Equal(t, geoip.Location{Country: "JP", Region: "JP-13", City: "Tokyo", ASN: 64500}, location)
+
+	location, ok = resolver.Lookup("198.51.100.7")
+	require.True(t, ok)
+	assert.Equal(t, geoip.Location{Country: "US", Region: "US-CA", City: "Mountain View", ASN: 64501}, location)
+
+	// IPv6（ASNのデータはない）
+	location, ok = resolver.Lookup("2001:db8::1")
+	require.True(t, ok)
+	assert.Equal(t, geoip.Location{Country: "DE", Region: "DE-BE", City: "Berlin"}, location)
+
+	// データベースにないアドレス・不正な値
+	_, ok = resolver.Lookup("192.0.2.1")
+	assert.False(t, ok)
+	_, ok = resolver.Lookup("invalid")
+	assert.False(t, ok)
+}
+
+func TestResolver_NotLoaded(t *testing.T) {
+	resolver := geoip.NewResolver(geoip.Config{
+		CityPath: filepath.Join(t.TempDir(), "missing.mmdb"),
+	}, newTestLogger())
+
+	assert.Error(t, resolver.Reload())
+	_, ok := resolver.Lookup("203.0.113.42")
+	assert.False(t, ok)
+}
+
+func TestResolver_HotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	copyFile(t, filepath.Join(fixtureDir, "GeoLite2-City-Test.mmdb"), path)
+
+	resolver := geoip.NewResolver(geoip.Config{CityPath: path}, newTestLogger())
+	require.NoError(t, resolver.Reload())
+
+	location, ok := resolver.Lookup("203.0.113.42")
+	require.True(t, ok)
+	assert.Equal(t, "Tokyo", location.City)
+
+	// ファイルが変わっていなければ読み込み直さない
+	require.NoError(t, resolver.Reload())
+
+	// ファイルを置き換えると読み込み直す
+	copyFile(t, filepath.Join(fixtureDir, "GeoLite2-City-Test-Updated.mmdb"), path)
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(t, resolver.Reload())
+
+	location, ok = resolver.Lookup("203.0.113.42")
+	require.True(t, ok)
+	assert.Equal(t, geoip.Location{Country: "JP", Region: "JP-27", City: "Osaka"}, location)
+
+	// 壊れたファイルに置き換えた場合は以前の内容で判定を続ける
+	require.NoError(t, os.WriteFile(path, []byte("broken"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.Error(t, resolver.Reload())
+
+	location, ok = resolver.Lookup("203.0.113.42")
+	require.True(t, ok)
+	assert.Equal(t, "Osaka", location.City)
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, data, 0o600))
+}
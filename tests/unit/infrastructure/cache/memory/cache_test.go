@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/infrastructure/cache/memory"
+)
+
+func TestCache_GetSet(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := memory.NewCache(memory.Config{
+		MaxEntries: 10,
+		MaxTTL:     time.Minute,
+		Clock:      func() time.Time { return now },
+	})
+	ctx := context.Background()
+
+	_, err := cache.Get(ctx, "missing")
+	assert.ErrorIs(t, err, memory.ErrKeyNotFound)
+
+	assert.NoError(t, cache.Set(ctx, "short", "a", 10*time.Second))
+	// 有効期間は MaxTTL までに短縮する
+	assert.NoError(t, cache.Set(ctx, "long", "b", time.Hour))
+
+	value, err := cache.Get(ctx, "short")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", value)
+
+	now = now.Add(30 * time.Second)
+	_, err = cache.Get(ctx, "short")
+	assert.ErrorIs(t, err, memory.ErrKeyNotFound)
+	value, err = cache.Get(ctx, "long")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+
+	now = now.Add(30 * time.Second)
+	_, err = cache.Get(ctx, "long")
+	assert.ErrorIs(t, err, memory.ErrKeyNotFound)
+}
+
+func TestCache_Eviction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := memory.NewCache(memory.Config{
+		MaxEntries: 2,
+		MaxTTL:     time.Minute,
+		Clock:      func() time.Time { return now },
+	})
+	ctx := context.Background()
+
+	cache.Set(ctx, "a", "1", 10*time.Second)
+	cache.Set(ctx, "b", "2", 20*time.Second)
+	cache.Set(ctx, "c", "3", 30*time.Second)
+
+	// 上限を超える場合は最も早く期限切れになるエントリを削除する
+	assert.Equal(t, 2, cache.Len())
+	_, err := cache.Get(ctx, "a")
+	assert.ErrorIs(t, err, memory.ErrKeyNotFound)
+	value, err := cache.Get(ctx, "c")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", value)
+
+	assert.NoError(t, cache.Delete(ctx, "c"))
+	assert.Equal(t, 1, cache.Len())
+}
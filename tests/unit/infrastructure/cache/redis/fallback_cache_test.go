@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/infrastructure/cache/memory"
+	"accesslog-tracker/internal/infrastructure/cache/redis"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// newUnavailableCacheService は接続できないアドレスに接続したキャッシュサービスを作成します
+func newUnavailableCacheService(t *testing.T) *redis.CacheService {
+	service := redis.NewCacheService("127.0.0.1:1")
+	assert.Error(t, service.Connect())
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func TestCacheService_StatusAfterFailedConnect(t *testing.T) {
+	service := newUnavailableCacheService(t)
+
+	status := service.Status()
+	assert.False(t, service.Available())
+	assert.False(t, status.Available)
+	assert.False(t, status.Since.IsZero())
+	assert.NotEmpty(t, status.LastError)
+	// 接続できなくてもクライアントは保持し、復帰後のコマンドで再接続する
+	assert.NotNil(t, service.GetClient())
+}
+
+func TestFallbackCache_RedisUnavailable(t *testing.T) {
+	service := newUnavailableCacheService(t)
+	cache := redis.NewFallbackCache(service, memory.NewCache(memory.DefaultConfig()))
+	ctx := context.Background()
+
+	assert.NoError(t, cache.Set(ctx, "app:id:app_1", "app_1", 30*time.Minute))
+
+	value, err := cache.Get(ctx, "app:id:app_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "app_1", value)
+
+	_, err = cache.Get(ctx, "app:id:missing")
+	assert.ErrorIs(t, err, memory.ErrKeyNotFound)
+}
+
+func TestFallbackCache_JSONRedisUnavailable(t *testing.T) {
+	service := newUnavailableCacheService(t)
+	cache := redis.NewFallbackCache(service, memory.NewCache(memory.DefaultConfig()))
+	ctx := context.Background()
+
+	type entry struct {
+		AppID string `json:"app_id"`
+	}
+	assert.NoError(t, cache.SetJSON(ctx, "app:id:app_1", entry{AppID: "app_1"}, 30*time.Minute))
+
+	var got entry
+	assert.NoError(t, cache.GetJSON(ctx, "app:id:app_1", &got))
+	assert.Equal(t, "app_1", got.AppID)
+
+	assert.NoError(t, cache.Delete(ctx, "app:id:app_1"))
+	assert.ErrorIs(t, cache.GetJSON(ctx, "app:id:app_1", &got), memory.ErrKeyNotFound)
+}
+
+func TestMonitor_Check(t *testing.T) {
+	service := newUnavailableCacheService(t)
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+	monitor := redis.NewMonitor(service, 100*time.Millisecond, log)
+
+	assert.False(t, monitor.Check(context.Background()))
+	assert.False(t, service.Available())
+}
@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/deployments/database/migrations"
+	"accesslog-tracker/internal/infrastructure/database/postgresql"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("should load and order migrations by version", func(t *testing.T) {
+		source := fstest.MapFS{
+			"010_add_index.up.sql":   {Data: []byte("CREATE INDEX idx ON t(c);")},
+			"010_add_index.down.sql": {Data: []byte("DROP INDEX idx;")},
+			"002_create_t.up.sql":    {Data: []byte("CREATE TABLE t (c INT);")},
+			"README.md":              {Data: []byte("ignored")},
+		}
+
+		loaded, err := postgresql.LoadMigrations(source)
+
+		require.NoError(t, err)
+		require.Len(t, loaded, 2)
+		assert.Equal(t, int64(2), loaded[0].Version)
+		assert.Equal(t, "create_t", loaded[0].Name)
+		assert.Empty(t, loaded[0].Down)
+		assert.Equal(t, int64(10), loaded[1].Version)
+		assert.Equal(t, "DROP INDEX idx;", loaded[1].Down)
+		assert.Len(t, loaded[1].Checksum, 64)
+	})
+
+	t.Run("should change checksum when up script changes", func(t *testing.T) {
+		first, err := postgresql.LoadMigrations(fstest.MapFS{
+			"001_a.up.sql": {Data: []byte("SELECT 1;")},
+		})
+		require.NoError(t, err)
+		second, err := postgresql.LoadMigrations(fstest.MapFS{
+			"001_a.up.sql": {Data: []byte("SELECT 2;")},
+		})
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first[0].Checksum, second[0].Checksum)
+	})
+
+	t.Run("should reject migration without up script", func(t *testing.T) {
+		_, err := postgresql.LoadMigrations(fstest.MapFS{
+			"001_a.down.sql": {Data: []byte("SELECT 1;")},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject conflicting names for the same version", func(t *testing.T) {
+		_, err := postgresql.LoadMigrations(fstest.MapFS{
+			"001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"001_b.up.sql": {Data: []byte("SELECT 1;")},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	loaded, err := postgresql.LoadMigrations(migrations.FS)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	for i, m := range loaded {
+		assert.Equal(t, int64(i+1), m.Version, "migration versions should be sequential")
+		assert.NotEmpty(t, m.Down, "migration %d_%s should have a down script", m.Version, m.Name)
+	}
+}
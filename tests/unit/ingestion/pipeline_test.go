@@ -0,0 +1,293 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/ingestion"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// fakeBatchWriter は書き込まれたバッチを記録するテスト用ライターです
+type fakeBatchWriter struct {
+	mu      sync.Mutex
+	batches [][]*models.TrackingData
+	err     error
+	block   chan struct{}
+	// failures は最初に失敗させる書き込みの回数です
+	failures int
+	// reject が true を返す行を含むバッチは書き込みに失敗します
+	reject func(data *models.TrackingData) bool
+	calls  int
+}
+
+func (w *fakeBatchWriter) SaveBatch(ctx context.Context, data []*models.TrackingData) error {
+	if w.block != nil {
+		<-w.block
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.calls++
+	if w.err != nil {
+		return w.err
+	}
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("connection reset")
+	}
+	for _, d := range data {
+		if w.reject != nil && w.reject(d) {
+			return errors.New("invalid row")
+		}
+	}
+	batch := make([]*models.TrackingData, len(data))
+	copy(batch, data)
+	w.batches = append(w.batches, batch)
+	return nil
+}
+
+func (w *fakeBatchWriter) batchSizes() []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sizes := make([]int, 0, len(w.batches))
+	for _, b := range w.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func (w *fakeBatchWriter) total() int {
+	total := 0
+	for _, size := range w.batchSizes() {
+		total += size
+	}
+	return total
+}
+
+func newTestLogger() logger.Logger {
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func newTrackingData(i int) *models.TrackingData {
+	return &models.TrackingData{
+		ID:        fmt.Sprintf("tracking_%d", i),
+		AppID:     "test_app_123",
+		UserAgent: "Mozilla/5.0",
+		URL:       "https://example.com/page",
+		Timestamp: time.Now(),
+	}
+}
+
+func TestNewPipeline_Defaults(t *testing.T) {
+	pipeline := ingestion.NewPipeline(&fakeBatchWriter{}, ingestion.Config{}, newTestLogger())
+
+	stats := pipeline.Stats()
+	defaults := ingestion.DefaultConfig()
+	assert.Equal(t, defaults.QueueSize, stats.QueueCapacity)
+	assert.Equal(t, defaults.Workers, stats.Workers)
+	assert.Equal(t, 0, stats.QueueDepth)
+}
+
+func TestPipeline_FlushOnBatchSize(t *testing.T) {
+	writer := &fakeBatchWriter{}
+	pipeline := ingestion.NewPipeline(writer, ingestion.Config{
+		QueueSize:     100,
+		Workers:       1,
+		BatchSize:     5,
+		FlushInterval: time.Hour,
+	}, newTestLogger())
+	pipeline.Start()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, pipeline.Enqueue(newTrackingData(i)))
+	}
+
+	assert.Eventually(t, func() bool {
+		return writer.total() == 10
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{5, 5}, writer.batchSizes())
+
+	require.NoError(t, pipeline.Stop(context.Background()))
+	assert.Equal(t, int64(10), pipeline.Stats().Written)
+}
+
+func TestPipeline_FlushOnInterval(t *testing.T) {
+	writer := &fakeBatchWriter{}
+	pipeline := ingestion.NewPipeline(writer, ingestion.Config{
+		QueueSize:     100,
+		Workers:       1,
+		BatchSize:     100,
+		FlushInterval: 20 * time.Millisecond,
+	}, newTestLogger())
+	pipeline.Start()
+	defer pipeline.Stop(context.Background())
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, pipeline.Enqueue(newTrackingData(i)))
+	}
+
+	assert.Eventually(t, func() bool {
+		return writer.total() == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{3}, writer.batchSizes())
+}
+
+func TestPipeline_Backpressure(t *testing.T) {
+	writer := &fakeBatchWriter{}
+	pipeline := ingestion.NewPipeline(writer, ingestion.Config{
+		QueueSize: 2,
+		Workers:   1,
+	}, newTestLogger())
+
+	// ワーカーを起動しないのでキューは消費されない
+	require.NoError(t, pipeline.Enqueue(newTrackingData(0)))
+	require.NoError(t, pipeline.Enqueue(newTrackingData(1)))
+
+	err := pipeline.Enqueue(newTrackingData(2))
+	assert.True(t, errors.Is(err, ingestion.ErrQueueFull))
+
+	stats := pipeline.Stats()
+	assert.Equal(t, 2, stats.QueueDepth)
+	assert.Equal(t, int64(2), stats.Enqueued)
+	assert.Equal(t, int64(1), stats.Rejected)
+	assert.True(t, stats.Saturated())
+
+	// 停止時にキューに残ったデータが書き込まれる
+	require.NoError(t, pipeline.Stop(context.Background()))
+	assert.Equal(t, 2, writer.total())
+}
+
+func TestPipeline_StopDrainsQueue(t *testing.T) {
+	writer := &fakeBatchWriter{}
+	pipeline := ingestion.NewPipeline(writer, ingestion.Config{
+		QueueSize:     100,
+		Workers:       2,
+		BatchSize:     50,
+		FlushInterval: time.Hour,
+	}, newTestLogger())
+	pipeline.Start()
+
+	for i := 0; i < 30; i++ {
+		require.NoError(t, pipeline.Enqueue(newTrackingData(i)))
+	}
+
+	require.NoError(t, pipeline.Stop(context.Background()))
+	assert.Equal(t, 30, writer.total())
+	assert.Equal(t, 0, pipeline.Stats().QueueDepth)
+
+	// 停止後は受け付けない
+	err := pipeline.Enqueue(newTrackingData(0))
+	assert.True(t, errors.Is(err, ingestion.ErrPipelineClosed))
+
+	// 2回目の停止は何もしない
+	assert.NoError(t, pipeline.Stop(context.Background()))
+}
+
+func TestPipeline_StopTimeout(t *testing.T) {
+	writer := &fakeBatchWriter{block: make(chan struct{})}
+	defer close(writer.block)
+
+	pipeline := ingestion.NewPipeline(writer, ingestion.Config{
+		QueueSize:     10,
+		Workers:       1,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+	}, newTestLogger())
+	pipeline.Start()
+
+	require.NoError(t, pipeline.Enqueue(newTrackingData(0)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := pipeline.Stop(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestPipeline_WriteFailure(t *testing.T) {
+	writer := &fakeBatchWriter{err: errors.New("database error")}
+	pipeline := ingestion.NewPipeline(writer, ingestion.Config{
+		QueueSize:     10,
+		Workers:       1,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		MaxRetries:    2,
+		RetryBackoff:  time.Millisecond,
+	}, newTestLogger())
+	pipeline.Start()
+
+	require.NoError(t, pipeline.Enqueue(newTrackingData(0)))
+	require.NoError(t, pipeline.Enqueue(newTrackingData(1)))
+	require.NoError(t, pipeline.Stop(context.Background()))
+
+	// 3回書き込んだ後、1件ずつ書き込んでも失敗した行を破棄する
+	stats := pipeline.Stats()
+	assert.Equal(t, int64(2), stats.Failed)
+	assert.Equal(t, int64(0), stats.Written)
+	assert.Equal(t, int64(2), stats.Retried)
+	assert.Equal(t, 5, writer.calls)
+}
+
+func TestPipeline_RetryTransientFailure(t *testing.T) {
+	writer := &fakeBatchWriter{failures: 2}
+	pipeline := ingestion.NewPipeline(writer, ingestion.Config{
+		QueueSize:     10,
+		Workers:       1,
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	}, newTestLogger())
+	pipeline.Start()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, pipeline.Enqueue(newTrackingData(i)))
+	}
+	require.NoError(t, pipeline.Stop(context.Background()))
+
+	// 再試行でバッチのまま書き込む
+	stats := pipeline.Stats()
+	assert.Equal(t, int64(3), stats.Written)
+	assert.Equal(t, int64(0), stats.Failed)
+	assert.Equal(t, int64(2), stats.Retried)
+	assert.Equal(t, []int{3}, writer.batchSizes())
+}
+
+func TestPipeline_DropOnlyInvalidRow(t *testing.T) {
+	writer := &fakeBatchWriter{reject: func(data *models.TrackingData) bool {
+		return data.ID == "tracking_1"
+	}}
+	pipeline := ingestion.NewPipeline(writer, ingestion.Config{
+		QueueSize:     10,
+		Workers:       1,
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		MaxRetries:    1,
+		RetryBackoff:  time.Millisecond,
+	}, newTestLogger())
+	pipeline.Start()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, pipeline.Enqueue(newTrackingData(i)))
+	}
+	require.NoError(t, pipeline.Stop(context.Background()))
+
+	// 書き込めない行だけを破棄し、残りは1件ずつ書き込む
+	stats := pipeline.Stats()
+	assert.Equal(t, int64(2), stats.Written)
+	assert.Equal(t, int64(1), stats.Failed)
+	assert.Equal(t, []int{1, 1}, writer.batchSizes())
+}
@@ -0,0 +1,179 @@
+package partition
+
+import (
+	"context"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/partition"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// fakeStore はメモリ上のパーティション一覧を扱うテスト用ストアです
+type fakeStore struct {
+	partitioned bool
+	partitions  []models.Partition
+	detached    []string
+	dropped     []string
+}
+
+func (s *fakeStore) IsPartitioned(ctx context.Context) (bool, error) {
+	return s.partitioned, nil
+}
+
+func (s *fakeStore) ListPartitions(ctx context.Context) ([]models.Partition, error) {
+	sort.Slice(s.partitions, func(i, j int) bool { return s.partitions[i].From.Before(s.partitions[j].From) })
+	return append([]models.Partition(nil), s.partitions...), nil
+}
+
+func (s *fakeStore) CreatePartition(ctx context.Context, partition models.Partition) error {
+	s.partitions = append(s.partitions, partition)
+	return nil
+}
+
+func (s *fakeStore) DetachPartition(ctx context.Context, name string) error {
+	s.detached = append(s.detached, name)
+	return s.remove(name)
+}
+
+func (s *fakeStore) DropPartition(ctx context.Context, name string) error {
+	s.dropped = append(s.dropped, name)
+	return s.remove(name)
+}
+
+func (s *fakeStore) remove(name string) error {
+	kept := s.partitions[:0]
+	for _, p := range s.partitions {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	s.partitions = kept
+	return nil
+}
+
+func newTestLogger() logger.Logger {
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func month(year int, m time.Month) models.Partition {
+	from := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	return models.Partition{Name: partition.Name(from, to), From: from, To: to}
+}
+
+func TestPlan(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no partitions", func(t *testing.T) {
+		planned := partition.Plan(nil, start, start.AddDate(0, 3, 0), models.PartitionGranularityMonth)
+		require.Len(t, planned, 3)
+		assert.Equal(t, "access_logs_p202403", planned[0].Name)
+		assert.Equal(t, "access_logs_p202405", planned[2].Name)
+		assert.True(t, start.AddDate(0, 3, 0).Equal(planned[2].To))
+	})
+
+	t.Run("fills gaps between existing partitions", func(t *testing.T) {
+		existing := []models.Partition{month(2024, time.February), month(2024, time.April)}
+		planned := partition.Plan(existing, start, start.AddDate(0, 3, 0), models.PartitionGranularityMonth)
+		require.Len(t, planned, 2)
+		assert.Equal(t, "access_logs_p202403", planned[0].Name)
+		assert.Equal(t, "access_logs_p202405", planned[1].Name)
+	})
+
+	t.Run("daily partitions after a monthly partition", func(t *testing.T) {
+		existing := []models.Partition{month(2024, time.March)}
+		planned := partition.Plan(existing, time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), models.PartitionGranularityDay)
+		require.Len(t, planned, 2)
+		assert.Equal(t, "access_logs_p20240401", planned[0].Name)
+		assert.Equal(t, "access_logs_p20240402", planned[1].Name)
+	})
+
+	t.Run("monthly partition after daily partitions", func(t *testing.T) {
+		day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		existing := []models.Partition{{Name: "access_logs_p20240301", From: day, To: day.AddDate(0, 0, 1)}}
+		planned := partition.Plan(existing, start, start.AddDate(0, 1, 0), models.PartitionGranularityMonth)
+		require.Len(t, planned, 1)
+		// 月の途中から始まる範囲は開始日の名前になる
+		assert.Equal(t, "access_logs_p20240302", planned[0].Name)
+		assert.True(t, start.AddDate(0, 1, 0).Equal(planned[0].To))
+	})
+}
+
+func TestManager_RunOnce(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("creates future partitions", func(t *testing.T) {
+		store := &fakeStore{partitioned: true, partitions: []models.Partition{month(2024, time.March)}}
+		manager := partition.NewManager(store, partition.Config{
+			Premake: 2,
+			Clock:   func() time.Time { return now },
+		}, newTestLogger())
+
+		result, err := manager.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"access_logs_p202404", "access_logs_p202405"}, result.Created)
+
+		// 2回目は何も作成しない
+		result, err = manager.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, result.Created)
+	})
+
+	t.Run("detaches expired partitions", func(t *testing.T) {
+		store := &fakeStore{partitioned: true, partitions: []models.Partition{
+			month(2023, time.December), month(2024, time.January), month(2024, time.February), month(2024, time.March),
+		}}
+		manager := partition.NewManager(store, partition.Config{
+			Premake: 0,
+			Retain:  2,
+			Clock:   func() time.Time { return now },
+		}, newTestLogger())
+
+		result, err := manager.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"access_logs_p202312"}, result.Detached)
+		assert.Empty(t, result.Dropped)
+		assert.Empty(t, store.dropped)
+	})
+
+	t.Run("drops expired daily partitions", func(t *testing.T) {
+		day := time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)
+		store := &fakeStore{partitioned: true}
+		for i := 0; i < 3; i++ {
+			from := day.AddDate(0, 0, i)
+			store.partitions = append(store.partitions, models.Partition{Name: partition.Name(from, from.AddDate(0, 0, 1)), From: from, To: from.AddDate(0, 0, 1)})
+		}
+		manager := partition.NewManager(store, partition.Config{
+			Granularity: models.PartitionGranularityDay,
+			Premake:     1,
+			Retain:      1,
+			DropExpired: true,
+			Clock:       func() time.Time { return now },
+		}, newTestLogger())
+
+		result, err := manager.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"access_logs_p20240316"}, result.Created)
+		assert.Equal(t, []string{"access_logs_p20240313"}, result.Dropped)
+		assert.Empty(t, store.detached)
+	})
+
+	t.Run("skips tables that are not partitioned", func(t *testing.T) {
+		store := &fakeStore{}
+		manager := partition.NewManager(store, partition.Config{Clock: func() time.Time { return now }}, newTestLogger())
+
+		result, err := manager.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, result.Created)
+		assert.Empty(t, store.partitions)
+	})
+}
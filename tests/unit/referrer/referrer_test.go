@@ -0,0 +1,122 @@
+package referrer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/referrer"
+)
+
+func TestClassifier_Classify(t *testing.T) {
+	classifier := referrer.Default()
+
+	tests := []struct {
+		name     string
+		pageURL  string
+		referrer string
+		host     string
+		source   string
+		channel  string
+	}{
+		{"direct", "https://example.com/", "", "", "", referrer.ChannelDirect},
+		{"google", "https://example.com/", "https://www.google.com/", "www.google.com", "Google", referrer.ChannelOrganicSearch},
+		{"google country domain", "https://example.com/", "https://www.google.co.jp/search?q=x", "www.google.co.jp", "Google", referrer.ChannelOrganicSearch},
+		{"yahoo japan search", "https://example.com/", "https://search.yahoo.co.jp/search?p=x", "search.yahoo.co.jp", "Yahoo!", referrer.ChannelOrganicSearch},
+		{"gmail is not search", "https://example.com/", "https://mail.google.com/", "mail.google.com", "Gmail", referrer.ChannelEmail},
+		{"social subdomain", "https://example.com/", "https://l.facebook.com/l.php", "l.facebook.com", "Facebook", referrer.ChannelSocial},
+		{"twitter short link", "https://example.com/", "https://t.co/abc", "t.co", "Twitter", referrer.ChannelSocial},
+		{"paid network", "https://example.com/", "https://ad.doubleclick.net/", "ad.doubleclick.net", "Google Ads", referrer.ChannelPaid},
+		{"internal", "https://www.example.com/b", "https://example.com/a", "example.com", "", referrer.ChannelInternal},
+		{"unknown site", "https://example.com/", "https://blog.example.org/post", "blog.example.org", "", referrer.ChannelOther},
+		{"gclid", "https://example.com/?gclid=abc", "https://www.google.com/", "www.google.com", "Google", referrer.ChannelPaid},
+		{"fbclid", "https://example.com/?fbclid=abc", "https://l.facebook.com/", "l.facebook.com", "Facebook", referrer.ChannelPaid},
+		{"cpc medium", "https://example.com/?utm_source=google&utm_medium=cpc", "", "", "", referrer.ChannelPaid},
+		{"email medium", "https://example.com/?utm_source=newsletter&utm_medium=Email", "", "", "", referrer.ChannelEmail},
+		{"social medium", "https://example.com/?utm_medium=social", "https://example.com/", "example.com", "", referrer.ChannelSocial},
+		{"campaign without referrer", "https://example.com/?utm_campaign=spring", "", "", "", referrer.ChannelOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := classifier.Classify(tt.pageURL, tt.referrer)
+			assert.Equal(t, tt.host, result.Host)
+			assert.Equal(t, tt.source, result.Source)
+			assert.Equal(t, tt.channel, result.Channel)
+		})
+	}
+}
+
+func TestParseCampaign(t *testing.T) {
+	campaign := referrer.ParseCampaign("https://example.com/lp?UTM_Source=google&utm_medium=cpc&utm_campaign=spring%20sale" +
+		"&utm_term=shoes&utm_content=banner-a&gclid=Cj0&fbclid=IwAR&other=1")
+	assert.Equal(t, referrer.Campaign{
+		Source:  "google",
+		Medium:  "cpc",
+		Name:    "spring sale",
+		Term:    "shoes",
+		Content: "banner-a",
+		GCLID:   "Cj0",
+		FBCLID:  "IwAR",
+	}, campaign)
+
+	assert.True(t, referrer.ParseCampaign("https://example.com/").IsEmpty())
+	assert.True(t, referrer.ParseCampaign("://invalid").IsEmpty())
+
+	// 長すぎる値は UTF-8 の文字の途中で切らずに切り詰める
+	long := referrer.ParseCampaign("https://example.com/?utm_campaign=" + strings.Repeat("あ", 100))
+	assert.LessOrEqual(t, len(long.Name), referrer.MaxValueLength)
+	assert.True(t, strings.HasPrefix(strings.Repeat("あ", 100), long.Name))
+}
+
+func TestSources_Lookup(t *testing.T) {
+	sources, err := referrer.ParseSources([]byte(`
+search:
+  Example Search:
+    domains:
+      - search.example.
+social:
+  Example Social:
+    domains:
+      - social.example.com
+unknown:
+  Ignored:
+    domains:
+      - ignored.example.com
+`))
+	require.NoError(t, err)
+
+	source, ok := sources.Lookup("search.example.de")
+	assert.True(t, ok)
+	assert.Equal(t, referrer.Source{Name: "Example Search", Category: referrer.CategorySearch}, source)
+
+	source, ok = sources.Lookup("M.Social.Example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "Example Social", source.Name)
+
+	_, ok = sources.Lookup("ignored.example.com")
+	assert.False(t, ok)
+	_, ok = sources.Lookup("example.com")
+	assert.False(t, ok)
+
+	_, err = referrer.ParseSources([]byte("search: [broken"))
+	assert.Error(t, err)
+}
+
+func TestNewFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sources.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("social:\n  Intranet:\n    domains:\n      - intranet.example.com\n"), 0o600))
+
+	classifier, err := referrer.NewFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, referrer.ChannelSocial, classifier.Classify("https://example.com/", "https://intranet.example.com/").Channel)
+	// 指定した一覧にない流入元は other
+	assert.Equal(t, referrer.ChannelOther, classifier.Classify("https://example.com/", "https://www.google.com/").Channel)
+
+	_, err = referrer.NewFromFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,214 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/retention"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// fakeStore はメモリ上のイベントとロールアップを扱うテスト用ストアです
+type fakeStore struct {
+	policies  []models.RetentionPolicy
+	events    map[string][]time.Time // アプリケーションID → イベント時刻
+	rollups   map[string][]time.Time // アプリケーションID → バケット
+	runs      []*models.RetentionRun
+	deletes   int
+	deleteErr map[string]error
+	now       *time.Time
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		events:    make(map[string][]time.Time),
+		rollups:   make(map[string][]time.Time),
+		deleteErr: make(map[string]error),
+	}
+}
+
+func (s *fakeStore) ListRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error) {
+	return s.policies, nil
+}
+
+func (s *fakeStore) DeleteExpiredEvents(ctx context.Context, appID string, before time.Time, limit int) (int64, error) {
+	if err := s.deleteErr[appID]; err != nil {
+		return 0, err
+	}
+	var deleted int64
+	s.events[appID], deleted = deleteBefore(s.events[appID], before, limit)
+	s.deletes++
+	if s.now != nil {
+		*s.now = s.now.Add(time.Second)
+	}
+	return deleted, nil
+}
+
+func (s *fakeStore) DeleteExpiredRollups(ctx context.Context, appID string, before time.Time, limit int) (int64, error) {
+	var deleted int64
+	s.rollups[appID], deleted = deleteBefore(s.rollups[appID], before, limit)
+	s.deletes++
+	return deleted, nil
+}
+
+func (s *fakeStore) SaveRetentionRun(ctx context.Context, run *models.RetentionRun) error {
+	s.runs = append(s.runs, run)
+	return nil
+}
+
+// deleteBefore は before より前の時刻を最大 limit 件取り除きます
+func deleteBefore(times []time.Time, before time.Time, limit int) ([]time.Time, int64) {
+	kept := make([]time.Time, 0, len(times))
+	var deleted int64
+	for _, t := range times {
+		if t.Before(before) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, deleted
+}
+
+func newTestLogger() logger.Logger {
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+// hoursBefore は now から1時間ごとに遡った時刻を count 件返します
+func hoursBefore(now time.Time, from, count int) []time.Time {
+	times := make([]time.Time, 0, count)
+	for i := 0; i < count; i++ {
+		times = append(times, now.Add(-time.Duration(from+i)*time.Hour))
+	}
+	return times
+}
+
+func TestWorker_RunOnce(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC)
+	store := newFakeStore()
+	store.policies = []models.RetentionPolicy{
+		{AppID: "app_a", RawDays: 1, RollupDays: 2},
+		{AppID: "app_b", RollupDays: 1},
+	}
+	// 24時間より古いイベントが4件
+	store.events["app_a"] = hoursBefore(now, 20, 9)
+	store.events["app_b"] = hoursBefore(now, 30, 3)
+	// 2日前の日の区切りより前のバケットが3件
+	store.rollups["app_a"] = []time.Time{
+		time.Date(2024, 3, 7, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 8, 11, 0, 0, 0, time.UTC),
+	}
+	store.rollups["app_b"] = []time.Time{
+		time.Date(2024, 3, 8, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+	}
+
+	worker := retention.NewWorker(store, retention.Config{
+		BatchSize: 2,
+		Clock:     func() time.Time { return now },
+	}, newTestLogger())
+
+	runs, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+
+	assert.Equal(t, "app_a", runs[0].AppID)
+	assert.Equal(t, int64(4), runs[0].EventsDeleted)
+	assert.Equal(t, int64(3), runs[0].RollupsDeleted)
+	assert.Empty(t, runs[0].Error)
+	assert.Len(t, store.events["app_a"], 5)
+	assert.Len(t, store.rollups["app_a"], 2)
+
+	// 生データの保持期間が未設定のアプリケーションはイベントを削除しない
+	assert.Equal(t, int64(0), runs[1].EventsDeleted)
+	assert.Equal(t, int64(1), runs[1].RollupsDeleted)
+	assert.Len(t, store.events["app_b"], 3)
+
+	// 実行結果はアプリケーションごとに保存される
+	assert.Equal(t, runs, store.runs)
+}
+
+func TestWorker_RunOnce_Batches(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("max batches per run", func(t *testing.T) {
+		store := newFakeStore()
+		store.policies = []models.RetentionPolicy{{AppID: "app_a", RawDays: 1}}
+		store.events["app_a"] = hoursBefore(now, 48, 10)
+
+		worker := retention.NewWorker(store, retention.Config{
+			BatchSize:        3,
+			MaxBatchesPerRun: 2,
+			Clock:            func() time.Time { return now },
+		}, newTestLogger())
+
+		runs, err := worker.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), runs[0].EventsDeleted)
+		assert.Equal(t, 2, store.deletes)
+
+		// 残りは次回の実行で削除される
+		runs, err = worker.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), runs[0].EventsDeleted)
+		assert.Empty(t, store.events["app_a"])
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		clock := now
+		store := newFakeStore()
+		store.now = &clock
+		store.policies = []models.RetentionPolicy{{AppID: "app_a", RawDays: 1}}
+		store.events["app_a"] = hoursBefore(now, 48, 3)
+
+		worker := retention.NewWorker(store, retention.Config{
+			BatchSize: 2,
+			Clock:     func() time.Time { return clock },
+		}, newTestLogger())
+
+		runs, err := worker.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.True(t, now.Equal(runs[0].StartedAt))
+		assert.Equal(t, 2*time.Second, runs[0].Duration)
+	})
+}
+
+func TestWorker_RunOnce_Errors(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := newFakeStore()
+	store.policies = []models.RetentionPolicy{
+		{AppID: "app_a", RawDays: 1, RollupDays: 1},
+		{AppID: "app_b", RawDays: 1},
+	}
+	store.events["app_a"] = hoursBefore(now, 48, 1)
+	store.rollups["app_a"] = hoursBefore(now, 72, 1)
+	store.events["app_b"] = hoursBefore(now, 48, 1)
+	store.deleteErr["app_a"] = errors.New("connection refused")
+
+	worker := retention.NewWorker(store, retention.Config{Clock: func() time.Time { return now }}, newTestLogger())
+
+	runs, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+
+	// 失敗したアプリケーションは結果に記録し、ロールアップの削除も行わない
+	assert.Equal(t, "connection refused", runs[0].Error)
+	assert.Len(t, store.rollups["app_a"], 1)
+
+	// 他のアプリケーションの処理は続ける
+	assert.Empty(t, runs[1].Error)
+	assert.Equal(t, int64(1), runs[1].EventsDeleted)
+	assert.Len(t, store.runs, 2)
+}
@@ -0,0 +1,331 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/rollup"
+	"accesslog-tracker/internal/utils/hll"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// fakeStore はメモリ上のイベントとロールアップを扱うテスト用ストアです
+type fakeStore struct {
+	events     []*models.TrackingData
+	rollups    map[string][]*models.RollupRow // 集計粒度/バケット → 集計結果
+	watermarks map[string]time.Time
+	replaced   []string
+	scanErr    error
+}
+
+func newFakeStore(events ...*models.TrackingData) *fakeStore {
+	return &fakeStore{
+		events:     events,
+		rollups:    make(map[string][]*models.RollupRow),
+		watermarks: make(map[string]time.Time),
+	}
+}
+
+func bucketKey(granularity string, bucket time.Time) string {
+	return granularity + "/" + bucket.UTC().Format(time.RFC3339)
+}
+
+func (s *fakeStore) GetWatermark(ctx context.Context, granularity string) (time.Time, error) {
+	return s.watermarks[granularity], nil
+}
+
+func (s *fakeStore) GetEarliestEventTime(ctx context.Context) (time.Time, error) {
+	var earliest time.Time
+	for _, e := range s.events {
+		if earliest.IsZero() || e.Timestamp.Before(earliest) {
+			earliest = e.Timestamp
+		}
+	}
+	return earliest, nil
+}
+
+func (s *fakeStore) ScanEvents(ctx context.Context, start, end time.Time, fn func(*models.TrackingData) error) error {
+	if s.scanErr != nil {
+		return s.scanErr
+	}
+	for _, e := range s.events {
+		if !e.Timestamp.Before(start) && e.Timestamp.Before(end) {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) ReplaceRollups(ctx context.Context, granularity string, bucket time.Time, rows []*models.RollupRow, advance bool) error {
+	key := bucketKey(granularity, bucket)
+	s.rollups[key] = rows
+	s.replaced = append(s.replaced, key)
+	if advance {
+		if end := rollup.Next(bucket, granularity); end.After(s.watermarks[granularity]) {
+			s.watermarks[granularity] = end
+		}
+	}
+	return nil
+}
+
+// total は集計結果の total 軸の行を返します
+func (s *fakeStore) total(granularity string, bucket time.Time, appID string) *models.RollupRow {
+	for _, row := range s.rollups[bucketKey(granularity, bucket)] {
+		if row.AppID == appID && row.Dimension == models.RollupDimensionTotal {
+			return row
+		}
+	}
+	return nil
+}
+
+func newTestLogger() logger.Logger {
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func newEvent(appID, session, ip string, ts time.Time) *models.TrackingData {
+	return &models.TrackingData{
+		AppID:     appID,
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0",
+		URL:       "https://example.com/",
+		SessionID: session,
+		IPAddress: ip,
+		Timestamp: ts,
+	}
+}
+
+func findRow(rows []*models.RollupRow, appID, dimension, value string) *models.RollupRow {
+	for _, row := range rows {
+		if row.AppID == appID && row.Dimension == dimension && row.Value == value {
+			return row
+		}
+	}
+	return nil
+}
+
+func TestAggregator_Rows(t *testing.T) {
+	bucket := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	aggregator := rollup.NewAggregator(models.RollupGranularityHour, bucket)
+
+	first := newEvent("app_a", "s1", "192.168.1.1", bucket.Add(time.Minute))
+	first.Referrer = "https://WWW.Google.com/search?q=x"
+	first.Country = "JP"
+	second := newEvent("app_a", "s1", "192.168.1.1", bucket.Add(11*time.Minute))
+	second.URL = "https://example.com/about?utm_campaign=spring&utm_medium=email"
+	second.PagePath = "https://example.com/about"
+	second.PageGroup = "/about"
+	bot := newEvent("app_a", "s2", "192.168.1.2", bucket.Add(20*time.Minute))
+	bot.UserAgent = "Googlebot/2.1"
+	other := newEvent("app_b", "s3", "192.168.1.3", bucket.Add(30*time.Minute))
+
+	for _, e := range []*models.TrackingData{first, second, bot, other} {
+		require.NoError(t, aggregator.Add(e))
+	}
+	rows := aggregator.Rows()
+
+	// アプリケーション・集計軸・値の順に並ぶ
+	assert.True(t, sort.SliceIsSorted(rows, func(i, j int) bool {
+		if rows[i].AppID != rows[j].AppID {
+			return rows[i].AppID < rows[j].AppID
+		}
+		if rows[i].Dimension != rows[j].Dimension {
+			return rows[i].Dimension < rows[j].Dimension
+		}
+		return rows[i].Value < rows[j].Value
+	}))
+
+	total := findRow(rows, "app_a", models.RollupDimensionTotal, "")
+	require.NotNil(t, total)
+	assert.Equal(t, int64(3), total.PageViews)
+	assert.Equal(t, int64(2), total.Sessions)
+	assert.Equal(t, int64(2), total.Visitors)
+	assert.Equal(t, 600.0, total.SessionSeconds)
+	assert.True(t, bucket.Equal(total.Bucket))
+	assert.Equal(t, models.RollupGranularityHour, total.Granularity)
+	// バケットをまたいで合算できるよう、セッション・訪問者のスケッチを保存する
+	sessions, err := hll.Parse(total.SessionSketch)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), sessions.Count())
+	visitors, err := hll.Parse(total.VisitorSketch)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), visitors.Count())
+
+	home := findRow(rows, "app_a", models.RollupDimensionURL, "https://example.com/")
+	require.NotNil(t, home)
+	assert.Equal(t, int64(2), home.PageViews)
+	assert.Equal(t, 0.0, home.SessionSeconds)
+
+	// ページはインジェスト時に正規化したページURLで集計する
+	about := findRow(rows, "app_a", models.RollupDimensionURL, "https://example.com/about")
+	require.NotNil(t, about)
+	assert.Equal(t, int64(1), about.PageViews)
+	aboutGroup := findRow(rows, "app_a", models.RollupDimensionPageGroup, "/about")
+	require.NotNil(t, aboutGroup)
+	assert.Equal(t, int64(1), aboutGroup.PageViews)
+
+	referrer := findRow(rows, "app_a", models.RollupDimensionReferrerHost, "www.google.com")
+	require.NotNil(t, referrer)
+	assert.Equal(t, int64(1), referrer.PageViews)
+	// 空のリファラーは集計しない
+	assert.Nil(t, findRow(rows, "app_a", models.RollupDimensionReferrerHost, ""))
+
+	botRow := findRow(rows, "app_a", models.RollupDimensionDeviceType, "bot")
+	require.NotNil(t, botRow)
+	assert.Equal(t, int64(1), botRow.PageViews)
+
+	chrome := findRow(rows, "app_a", models.RollupDimensionBrowser, "Chrome")
+	require.NotNil(t, chrome)
+	assert.Equal(t, int64(2), chrome.PageViews)
+
+	windows := findRow(rows, "app_a", models.RollupDimensionOS, "Windows")
+	require.NotNil(t, windows)
+	assert.Equal(t, int64(2), windows.PageViews)
+	assert.Equal(t, int64(1), windows.Sessions)
+
+	japan := findRow(rows, "app_a", models.RollupDimensionCountry, "JP")
+	require.NotNil(t, japan)
+	assert.Equal(t, int64(1), japan.PageViews)
+	// 国のないイベントは集計しない
+	assert.Nil(t, findRow(rows, "app_a", models.RollupDimensionCountry, ""))
+
+	// 判定結果のないイベントはリファラーとページURLからチャネルを判定する
+	for channel, pageViews := range map[string]int64{"organic_search": 1, "email": 1, "direct": 1} {
+		row := findRow(rows, "app_a", models.RollupDimensionChannel, channel)
+		require.NotNil(t, row, channel)
+		assert.Equal(t, pageViews, row.PageViews, channel)
+	}
+	spring := findRow(rows, "app_a", models.RollupDimensionCampaign, "spring")
+	require.NotNil(t, spring)
+	assert.Equal(t, int64(1), spring.PageViews)
+	// キャンペーンのないイベントは集計しない
+	assert.Nil(t, findRow(rows, "app_a", models.RollupDimensionCampaign, ""))
+
+	otherTotal := findRow(rows, "app_b", models.RollupDimensionTotal, "")
+	require.NotNil(t, otherTotal)
+	assert.Equal(t, int64(1), otherTotal.PageViews)
+	assert.Equal(t, 0.0, otherTotal.SessionSeconds)
+}
+
+func TestWorker_RunOnce(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	store := newFakeStore(
+		newEvent("app_a", "s1", "192.168.1.1", base.Add(5*time.Minute)),
+		newEvent("app_a", "s1", "192.168.1.1", base.Add(65*time.Minute)),
+		newEvent("app_a", "s2", "192.168.1.2", base.Add(150*time.Minute)),
+	)
+
+	now := base.Add(2*time.Hour + 3*time.Minute)
+	worker := rollup.NewWorker(store, rollup.Config{
+		Delay:            5 * time.Minute,
+		MaxBucketsPerRun: 24,
+		Clock:            func() time.Time { return now },
+	}, newTestLogger())
+
+	// 11時台は猶予中のため 10時台だけを集計する
+	processed, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.True(t, base.Add(time.Hour).Equal(store.watermarks[models.RollupGranularityHour]))
+
+	now = base.Add(2*time.Hour + 6*time.Minute)
+	processed, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.True(t, base.Add(2*time.Hour).Equal(store.watermarks[models.RollupGranularityHour]))
+	assert.Equal(t, int64(1), store.total(models.RollupGranularityHour, base.Add(time.Hour), "app_a").PageViews)
+
+	// 日が確定すると日別も集計する
+	now = base.Add(14*time.Hour + 10*time.Minute)
+	_, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	day := rollup.Truncate(base, models.RollupGranularityDay)
+	assert.True(t, day.AddDate(0, 0, 1).Equal(store.watermarks[models.RollupGranularityDay]))
+	dayTotal := store.total(models.RollupGranularityDay, day, "app_a")
+	require.NotNil(t, dayTotal)
+	assert.Equal(t, int64(3), dayTotal.PageViews)
+	assert.Equal(t, int64(2), dayTotal.Sessions)
+	assert.Equal(t, 3600.0, dayTotal.SessionSeconds)
+
+	// 新しく確定したバケットがなければ何もしない
+	replaced := len(store.replaced)
+	processed, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+	assert.Len(t, store.replaced, replaced)
+}
+
+func TestWorker_RunOnce_Limits(t *testing.T) {
+	base := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("empty store", func(t *testing.T) {
+		store := newFakeStore()
+		worker := rollup.NewWorker(store, rollup.Config{Clock: func() time.Time { return base }}, newTestLogger())
+
+		processed, err := worker.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, processed)
+		assert.Empty(t, store.watermarks)
+	})
+
+	t.Run("max buckets per run", func(t *testing.T) {
+		store := newFakeStore(newEvent("app_a", "s1", "192.168.1.1", base))
+		worker := rollup.NewWorker(store, rollup.Config{
+			MaxBucketsPerRun: 3,
+			Clock:            func() time.Time { return base.Add(10 * time.Hour) },
+		}, newTestLogger())
+
+		processed, err := worker.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, processed)
+		assert.True(t, base.Add(3*time.Hour).Equal(store.watermarks[models.RollupGranularityHour]))
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		store := newFakeStore(newEvent("app_a", "s1", "192.168.1.1", base))
+		store.scanErr = errors.New("connection refused")
+		worker := rollup.NewWorker(store, rollup.Config{
+			Clock: func() time.Time { return base.Add(10 * time.Hour) },
+		}, newTestLogger())
+
+		_, err := worker.RunOnce(context.Background())
+		assert.Error(t, err)
+		assert.True(t, store.watermarks[models.RollupGranularityHour].IsZero())
+	})
+}
+
+func TestWorker_Backfill(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	store := newFakeStore(newEvent("app_a", "s1", "192.168.1.1", base))
+	worker := rollup.NewWorker(store, rollup.Config{}, newTestLogger())
+
+	from := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	processed, err := worker.Backfill(context.Background(), from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 25, processed)
+	assert.Equal(t, int64(1), store.total(models.RollupGranularityHour, base.Truncate(time.Hour), "app_a").PageViews)
+	assert.Equal(t, int64(1), store.total(models.RollupGranularityDay, from, "app_a").PageViews)
+
+	// 集計済みの終端は変更しない
+	assert.Empty(t, store.watermarks)
+
+	// 再実行しても結果は同じ
+	_, err = worker.Backfill(context.Background(), from, to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), store.total(models.RollupGranularityDay, from, "app_a").PageViews)
+
+	_, err = worker.Backfill(context.Background(), to, from)
+	assert.ErrorIs(t, err, rollup.ErrInvalidRange)
+}
@@ -0,0 +1,187 @@
+package uaparser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/uaparser"
+)
+
+func TestParser_DefaultRules(t *testing.T) {
+	parser := uaparser.Default()
+
+	tests := []struct {
+		name           string
+		userAgent      string
+		browser        string
+		browserVersion string
+		os             string
+		osVersion      string
+		deviceType     string
+	}{
+		{
+			name:           "Chrome on Windows",
+			userAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.130 Safari/537.36",
+			browser:        "Chrome",
+			browserVersion: "120.0.6099",
+			os:             "Windows",
+			osVersion:      "10",
+			deviceType:     uaparser.DeviceTypeDesktop,
+		},
+		{
+			name:           "Chrome on iOS",
+			userAgent:      "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			browser:        "Chrome",
+			browserVersion: "120.0.6099",
+			os:             "iOS",
+			osVersion:      "17.2",
+			deviceType:     uaparser.DeviceTypeMobile,
+		},
+		{
+			name:           "Samsung Internet",
+			userAgent:      "Mozilla/5.0 (Linux; Android 13; SAMSUNG SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36",
+			browser:        "Samsung Internet",
+			browserVersion: "23.0",
+			os:             "Android",
+			osVersion:      "13",
+			deviceType:     uaparser.DeviceTypeMobile,
+		},
+		{
+			name:           "Opera (OPR)",
+			userAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 OPR/106.0.0.0",
+			browser:        "Opera",
+			browserVersion: "106.0.0",
+			os:             "Windows",
+			osVersion:      "10",
+			deviceType:     uaparser.DeviceTypeDesktop,
+		},
+		{
+			name:           "Android tablet",
+			userAgent:      "Mozilla/5.0 (Linux; Android 12; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			browser:        "Chrome",
+			browserVersion: "120.0.0",
+			os:             "Android",
+			osVersion:      "12",
+			deviceType:     uaparser.DeviceTypeTablet,
+		},
+		{
+			name:           "Edge",
+			userAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			browser:        "Edge",
+			browserVersion: "120.0.2210",
+			os:             "Windows",
+			osVersion:      "10",
+			deviceType:     uaparser.DeviceTypeDesktop,
+		},
+		{
+			name:           "Safari on macOS",
+			userAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			browser:        "Safari",
+			browserVersion: "17.2",
+			os:             "macOS",
+			osVersion:      "10.15.7",
+			deviceType:     uaparser.DeviceTypeDesktop,
+		},
+		{
+			name:           "iPad",
+			userAgent:      "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Mobile/15E148 Safari/604.1",
+			browser:        "Safari",
+			browserVersion: "16.6",
+			os:             "iOS",
+			osVersion:      "16.6",
+			deviceType:     uaparser.DeviceTypeTablet,
+		},
+		{
+			name:           "Googlebot",
+			userAgent:      "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			browser:        "Googlebot",
+			browserVersion: "2.1",
+			os:             uaparser.Other,
+			deviceType:     uaparser.DeviceTypeBot,
+		},
+		{
+			name:       "Unknown",
+			userAgent:  "curl/8.4.0",
+			browser:    uaparser.Other,
+			os:         uaparser.Other,
+			deviceType: uaparser.DeviceTypeDesktop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := parser.Parse(tt.userAgent)
+			assert.Equal(t, tt.browser, client.UserAgent.Family)
+			assert.Equal(t, tt.browserVersion, client.UserAgent.Version())
+			assert.Equal(t, tt.os, client.OS.Family)
+			assert.Equal(t, tt.osVersion, client.OS.Version())
+			assert.Equal(t, tt.deviceType, client.Device.Type)
+		})
+	}
+}
+
+func TestParser_CustomRules(t *testing.T) {
+	rules, err := uaparser.ParseRules([]byte(`
+user_agent_parsers:
+  - regex: '(MyApp)/(\d+)\.(\d+)'
+    family_replacement: '$1 Android'
+os_parsers:
+  - regex: 'myos (\d+)'
+    regex_flag: 'i'
+    os_replacement: 'MyOS'
+    os_v1_replacement: '$1'
+device_parsers:
+  - regex: '; ([A-Z]+-\d+)\)'
+    device_replacement: 'Phone $1'
+    brand_replacement: 'Acme'
+`))
+	require.NoError(t, err)
+
+	parser, err := uaparser.New(rules, 10)
+	require.NoError(t, err)
+
+	client := parser.Parse("MyApp/3.4 (MYOS 7; AB-100)")
+	assert.Equal(t, uaparser.UserAgent{Family: "MyApp Android", Major: "3", Minor: "4"}, client.UserAgent)
+	assert.Equal(t, "MyOS", client.OS.Family)
+	assert.Equal(t, "7", client.OS.Version())
+	assert.Equal(t, "Phone AB-100", client.Device.Family)
+	assert.Equal(t, "Acme", client.Device.Brand)
+	assert.Equal(t, "AB-100", client.Device.Model)
+	assert.Equal(t, uaparser.DeviceTypeMobile, client.Device.Type)
+
+	// キャッシュされた結果も同じ
+	assert.Equal(t, client, parser.Parse("MyApp/3.4 (MYOS 7; AB-100)"))
+}
+
+func TestParser_InvalidRules(t *testing.T) {
+	rules, err := uaparser.ParseRules([]byte(`
+user_agent_parsers:
+  - regex: '(Broken'
+`))
+	require.NoError(t, err)
+
+	_, err = uaparser.New(rules, 0)
+	assert.Error(t, err)
+
+	_, err = uaparser.NewFromFile(filepath.Join(t.TempDir(), "missing.yaml"), 0)
+	assert.Error(t, err)
+}
+
+func TestNewFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regexes.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+user_agent_parsers:
+  - regex: '(Firefox)/(\d+)\.(\d+)'
+`), 0o600))
+
+	parser, err := uaparser.NewFromFile(path, 0)
+	require.NoError(t, err)
+
+	// ファイルのルールだけを使う
+	assert.Equal(t, "Firefox", parser.Parse("Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0").UserAgent.Family)
+	assert.Equal(t, uaparser.Other, parser.Parse("Mozilla/5.0 (X11; Linux x86_64) Chrome/120.0.0.0").UserAgent.Family)
+}
@@ -0,0 +1,88 @@
+package urlnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/urlnorm"
+)
+
+func TestDefault_Normalize(t *testing.T) {
+	result := urlnorm.Default().Normalize("https://Example.COM/Product/123?ref=a#reviews")
+	assert.Equal(t, "https://example.com/Product/123?ref=a", result.PagePath)
+	assert.Equal(t, "/Product/123", result.PageGroup)
+
+	// ホストだけのURLはルートとする
+	assert.Equal(t, urlnorm.Result{PagePath: "https://example.com/", PageGroup: "/"}, urlnorm.Default().Normalize("https://example.com"))
+	// 解析できないURLはそのまま
+	assert.Equal(t, urlnorm.Result{PagePath: "http://[::1"}, urlnorm.Default().Normalize("http://[::1"))
+}
+
+func TestNormalizer_Normalize(t *testing.T) {
+	normalizer, err := urlnorm.New(urlnorm.Rules{
+		StripQuery:     true,
+		QueryAllowlist: []string{"Page", "q"},
+		DropFragment:   true,
+		LowercaseHost:  true,
+		TrailingSlash:  urlnorm.TrailingSlashStrip,
+		PathGroups:     []string{"/product/:id", "/product/:id/reviews", "/blog/*", "/"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		url      string
+		pagePath string
+		group    string
+	}{
+		{"query stripped", "https://example.com/product/123?ref=a", "https://example.com/product/123", "/product/:id"},
+		{"same page with fragment", "https://example.com/product/123?ref=b#x", "https://example.com/product/123", "/product/:id"},
+		{"allowlisted query kept in key order", "https://example.com/search/?q=shoes&utm_source=x&page=2", "https://example.com/search?page=2&q=shoes", "/search"},
+		{"nested pattern", "https://example.com/product/123/reviews/", "https://example.com/product/123/reviews", "/product/:id/reviews"},
+		{"wildcard", "https://example.com/blog/2024/01/hello", "https://example.com/blog/2024/01/hello", "/blog/*"},
+		{"wildcard needs a segment", "https://example.com/blog/", "https://example.com/blog", "/blog"},
+		{"root", "https://example.com/?ref=a", "https://example.com/", "/"},
+		{"relative url", "/product/9", "/product/9", "/product/:id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := normalizer.Normalize(tt.url)
+			assert.Equal(t, tt.pagePath, result.PagePath)
+			assert.Equal(t, tt.group, result.PageGroup)
+		})
+	}
+}
+
+func TestNormalizer_TrailingSlashAdd(t *testing.T) {
+	normalizer, err := urlnorm.New(urlnorm.Rules{TrailingSlash: urlnorm.TrailingSlashAdd})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/about/", normalizer.Normalize("https://example.com/about").PagePath)
+	assert.Equal(t, "https://example.com/about/", normalizer.Normalize("https://example.com/about/").PagePath)
+	// 拡張子のあるファイルには付けない
+	assert.Equal(t, "https://example.com/index.html", normalizer.Normalize("https://example.com/index.html").PagePath)
+	// フラグメントを残すルール
+	assert.Equal(t, "https://example.com/docs/#install", normalizer.Normalize("https://example.com/docs#install").PagePath)
+}
+
+func TestNew_InvalidRules(t *testing.T) {
+	for _, rules := range []urlnorm.Rules{
+		{TrailingSlash: "sometimes"},
+		{QueryAllowlist: []string{" "}},
+		{PathGroups: []string{"product/:id"}},
+		{PathGroups: []string{"/product/*/reviews"}},
+		{PathGroups: []string{"/product/:"}},
+		{PathGroups: []string{"/search?q=x"}},
+		{PathGroups: make([]string, urlnorm.MaxPathGroups+1)},
+	} {
+		_, err := urlnorm.New(rules)
+		assert.Error(t, err, "%+v", rules)
+	}
+
+	normalizer, err := urlnorm.New(urlnorm.Rules{})
+	require.NoError(t, err)
+	assert.Equal(t, urlnorm.TrailingSlashKeep, normalizer.Rules().TrailingSlash)
+}
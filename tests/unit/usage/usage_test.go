@@ -0,0 +1,176 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/domain/models"
+	"accesslog-tracker/internal/domain/services"
+	"accesslog-tracker/internal/usage"
+	"accesslog-tracker/internal/utils/logger"
+)
+
+// fakeUsage は集計期間ごとのアプリケーションのイベント数をメモリに保持するテスト用のカウンター・ストアです
+type fakeUsage struct {
+	events   map[time.Time]map[string]int64
+	reserved map[string]int64 // 確保中のイベント数
+	missing  map[string]bool  // 削除済みのアプリケーション
+	listErr  error
+}
+
+func newFakeUsage() *fakeUsage {
+	return &fakeUsage{
+		events:   make(map[time.Time]map[string]int64),
+		reserved: make(map[string]int64),
+		missing:  make(map[string]bool),
+	}
+}
+
+func (f *fakeUsage) set(period time.Time, appID string, events int64) {
+	if f.events[period] == nil {
+		f.events[period] = make(map[string]int64)
+	}
+	f.events[period][appID] = events
+}
+
+func (f *fakeUsage) get(period time.Time, appID string) int64 {
+	return f.events[period][appID]
+}
+
+func (f *fakeUsage) ListUsage(ctx context.Context, period time.Time) (map[string]int64, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	result := make(map[string]int64)
+	for appID, events := range f.events[period] {
+		result[appID] = events
+	}
+	return result, nil
+}
+
+func (f *fakeUsage) AddUsage(ctx context.Context, appID string, period time.Time, delta int64) error {
+	f.set(period, appID, f.get(period, appID)+delta)
+	return nil
+}
+
+func (f *fakeUsage) ReserveUsage(ctx context.Context, appID string, period time.Time, events, quota int64) (int64, bool, error) {
+	used := f.get(period, appID) + f.reserved[appID]
+	if quota > 0 && used+events > quota {
+		return used, false, nil
+	}
+	f.reserved[appID] += events
+	return used + events, true, nil
+}
+
+func (f *fakeUsage) CommitUsage(ctx context.Context, appID string, period time.Time, reserved, accepted int64) error {
+	f.reserved[appID] -= reserved
+	return f.AddUsage(ctx, appID, period, accepted)
+}
+
+func (f *fakeUsage) GetUsage(ctx context.Context, appID string, period time.Time) (int64, error) {
+	return f.get(period, appID), nil
+}
+
+func (f *fakeUsage) SaveUsage(ctx context.Context, appID string, period time.Time, events int64) error {
+	if f.missing[appID] {
+		return models.ErrApplicationNotFound
+	}
+	if events > f.get(period, appID) {
+		f.set(period, appID, events)
+	}
+	return nil
+}
+
+func newTestLogger() logger.Logger {
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func TestWorker_RunOnce(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 30, 0, time.UTC)
+	current := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	previous := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	counter := newFakeUsage()
+	store := newFakeUsage()
+	// 月が変わる直前に加算された前月分
+	counter.set(previous, "app_a", 1500)
+	store.set(previous, "app_a", 1200)
+	// Redisのデータが失われ、保存済みの値の方が大きい
+	counter.set(current, "app_a", 3)
+	store.set(current, "app_a", 40)
+	// 削除済みのアプリケーションのカウンターは保存しない
+	counter.set(current, "app_deleted", 10)
+	store.missing["app_deleted"] = true
+	// 一致している場合は更新しない
+	counter.set(current, "app_b", 7)
+	store.set(current, "app_b", 7)
+
+	worker := usage.NewWorker(counter, store, usage.Config{
+		Clock: func() time.Time { return now },
+	}, newTestLogger())
+
+	result, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, usage.Result{Periods: 2, Saved: 1, Restored: 1}, result)
+	assert.Equal(t, int64(1500), store.get(previous, "app_a"))
+	assert.Equal(t, int64(40), counter.get(current, "app_a"))
+	assert.Equal(t, int64(40), store.get(current, "app_a"))
+	assert.Equal(t, int64(0), store.get(current, "app_deleted"))
+	assert.Equal(t, int64(7), counter.get(current, "app_b"))
+
+	// 突き合わせ後は変化しない
+	result, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, usage.Result{Periods: 2}, result)
+}
+
+func TestWorker_RunOnceCounterError(t *testing.T) {
+	counter := newFakeUsage()
+	counter.listErr = errors.New("connection refused")
+
+	worker := usage.NewWorker(counter, newFakeUsage(), usage.DefaultConfig(), newTestLogger())
+
+	_, err := worker.RunOnce(context.Background())
+	assert.Error(t, err)
+}
+
+func TestWorker_RunOnceDuringRequest(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	period := models.UsagePeriod(now)
+	app := &models.Application{AppID: "app_a", Plan: models.PlanFree}
+
+	counter := newFakeUsage()
+	store := newFakeUsage()
+	counter.set(period, "app_a", 10)
+	store.set(period, "app_a", 10)
+	quotas := services.NewApplicationService(nil, nil, services.WithUsage(counter, store))
+	worker := usage.NewWorker(counter, store, usage.Config{
+		Clock: func() time.Time { return now },
+	}, newTestLogger())
+
+	// バッチのイベント数を確保した後、ハンドラーの処理中に突き合わせが実行される
+	reserved, err := quotas.ReserveQuota(ctx, app, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), reserved.Events)
+	_, err = worker.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), store.get(period, "app_a"))
+
+	// 受け付けた2件だけを確定し、次の突き合わせで保存する
+	require.NoError(t, quotas.CommitQuota(ctx, reserved, 5, 2))
+	_, err = worker.RunOnce(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(12), store.get(period, "app_a"))
+	assert.Equal(t, int64(12), counter.get(period, "app_a"))
+}
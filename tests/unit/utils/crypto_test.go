@@ -1,7 +1,10 @@
 package utils_test
 
 import (
+	"encoding/base64"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"accesslog-tracker/internal/utils/crypto"
@@ -97,3 +100,92 @@ func TestCryptoUtil_ValidateAPIKey(t *testing.T) {
 		})
 	}
 }
+
+func TestCryptoUtil_HashAPIKey(t *testing.T) {
+	apiKey := crypto.GenerateAPIKey()
+	salt := crypto.GenerateAPIKeySalt()
+	hash := crypto.HashAPIKey(apiKey, salt)
+
+	assert.Len(t, salt, 32)
+	assert.NotEqual(t, salt, crypto.GenerateAPIKeySalt())
+	assert.NotContains(t, hash, apiKey)
+	// 同じキーでもソルトが異なればハッシュも異なる
+	assert.NotEqual(t, hash, crypto.HashAPIKey(apiKey, crypto.GenerateAPIKeySalt()))
+
+	assert.True(t, crypto.VerifyAPIKey(apiKey, salt, hash))
+	assert.False(t, crypto.VerifyAPIKey(apiKey+"x", salt, hash))
+	assert.False(t, crypto.VerifyAPIKey(apiKey, "other-salt", hash))
+}
+
+func TestCryptoUtil_APIKeyPrefix(t *testing.T) {
+	assert.Equal(t, "alt_test", crypto.APIKeyPrefix("alt_test_api_key_001"))
+	assert.Equal(t, "short", crypto.APIKeyPrefix("short"))
+	assert.Len(t, crypto.APIKeyPrefix(crypto.GenerateAPIKey()), crypto.APIKeyPrefixLength)
+}
+
+func TestCryptoUtil_RequestSignature(t *testing.T) {
+	secret := crypto.GenerateSigningSecret()
+	assert.True(t, strings.HasPrefix(secret, crypto.SigningSecretPrefix))
+	assert.NotEqual(t, secret, crypto.GenerateSigningSecret())
+
+	body := []byte(`{"a":1}`)
+	signature := crypto.SignRequest("whsec_test", "post", "/v1/tracking/track?x=1", "1700000000", body)
+	assert.Equal(t, "d84028f719e0bf6384368afa4c082f787fa095a6a91a9cb8975feb4f106b9a45", signature)
+
+	assert.True(t, crypto.VerifyRequestSignature("whsec_test", "POST", "/v1/tracking/track?x=1", "1700000000", body, signature))
+	assert.True(t, crypto.VerifyRequestSignature("whsec_test", "POST", "/v1/tracking/track?x=1", "1700000000", body, "sha256="+strings.ToUpper(signature)))
+	// 署名対象のいずれかが異なる場合は失敗する
+	assert.False(t, crypto.VerifyRequestSignature("whsec_other", "POST", "/v1/tracking/track?x=1", "1700000000", body, signature))
+	assert.False(t, crypto.VerifyRequestSignature("whsec_test", "PUT", "/v1/tracking/track?x=1", "1700000000", body, signature))
+	assert.False(t, crypto.VerifyRequestSignature("whsec_test", "POST", "/v1/tracking/track?x=2", "1700000000", body, signature))
+	assert.False(t, crypto.VerifyRequestSignature("whsec_test", "POST", "/v1/tracking/track?x=1", "1700000001", body, signature))
+	assert.False(t, crypto.VerifyRequestSignature("whsec_test", "POST", "/v1/tracking/track?x=1", "1700000000", []byte(`{"a":2}`), signature))
+	assert.False(t, crypto.VerifyRequestSignature("whsec_test", "POST", "/v1/tracking/track?x=1", "1700000000", body, ""))
+}
+
+func TestCryptoUtil_JWT(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	secret := "test-secret"
+	claims := crypto.JWTClaims{Subject: "alice", Role: "admin", ExpiresAt: now.Add(time.Hour).Unix()}
+
+	token, err := crypto.SignJWT(claims, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(strings.Split(token, ".")))
+
+	t.Run("valid token", func(t *testing.T) {
+		parsed, err := crypto.ParseJWT(token, secret, now)
+		assert.NoError(t, err)
+		assert.Equal(t, claims, *parsed)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := crypto.ParseJWT(token, "other-secret", now)
+		assert.ErrorIs(t, err, crypto.ErrInvalidToken)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := crypto.ParseJWT(token, secret, now.Add(2*time.Hour))
+		assert.ErrorIs(t, err, crypto.ErrTokenExpired)
+	})
+
+	t.Run("without expiration", func(t *testing.T) {
+		noExp, err := crypto.SignJWT(crypto.JWTClaims{Subject: "alice", Role: "admin"}, secret)
+		assert.NoError(t, err)
+		_, err = crypto.ParseJWT(noExp, secret, now)
+		assert.ErrorIs(t, err, crypto.ErrTokenExpired)
+	})
+
+	t.Run("alg none", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+		_, err := crypto.ParseJWT(header+"."+parts[1]+".", secret, now)
+		assert.ErrorIs(t, err, crypto.ErrInvalidToken)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory","role":"admin","exp":9999999999}`))
+		_, err := crypto.ParseJWT(parts[0]+"."+payload+"."+parts[2], secret, now)
+		assert.ErrorIs(t, err, crypto.ErrInvalidToken)
+	})
+}
@@ -0,0 +1,84 @@
+package utils_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"accesslog-tracker/internal/utils/hll"
+)
+
+func TestHLL_CountSmall(t *testing.T) {
+	sketch := hll.New()
+	assert.Equal(t, int64(0), sketch.Count())
+
+	for i := 0; i < 3; i++ {
+		sketch.Add("session_a")
+		sketch.Add("session_b")
+	}
+	// 少ない件数はほぼ正確に数える
+	assert.Equal(t, int64(2), sketch.Count())
+}
+
+func TestHLL_CountLarge(t *testing.T) {
+	sketch := hll.New()
+	for i := 0; i < 100000; i++ {
+		sketch.Add(fmt.Sprintf("192.0.%d.%d", i/256, i%256))
+	}
+
+	assert.InDelta(t, 100000, sketch.Count(), 100000*0.05)
+}
+
+func TestHLL_Merge(t *testing.T) {
+	// 2つのバケットにまたがる値は重複して数えない
+	first := hll.New()
+	second := hll.New()
+	for i := 0; i < 1000; i++ {
+		first.Add(fmt.Sprintf("session_%d", i))
+		second.Add(fmt.Sprintf("session_%d", i+500))
+	}
+	first.Merge(second)
+
+	assert.InDelta(t, 1500, first.Count(), 1500*0.05)
+}
+
+func TestHLL_MarshalBinary(t *testing.T) {
+	t.Run("should round-trip sparse sketches", func(t *testing.T) {
+		sketch := hll.New()
+		sketch.Add("192.0.2.1")
+		sketch.Add("192.0.2.2")
+
+		data, err := sketch.MarshalBinary()
+		require.NoError(t, err)
+		// 値のあるレジスタだけを保存する
+		assert.Len(t, data, 2+2*3)
+
+		parsed, err := hll.Parse(data)
+		require.NoError(t, err)
+		assert.Equal(t, sketch.Count(), parsed.Count())
+	})
+
+	t.Run("should round-trip dense sketches", func(t *testing.T) {
+		sketch := hll.New()
+		for i := 0; i < 20000; i++ {
+			sketch.Add(fmt.Sprintf("session_%d", i))
+		}
+
+		data, err := sketch.MarshalBinary()
+		require.NoError(t, err)
+		assert.Len(t, data, 2+1<<hll.Precision)
+
+		parsed, err := hll.Parse(data)
+		require.NoError(t, err)
+		assert.Equal(t, sketch.Count(), parsed.Count())
+	})
+
+	t.Run("should reject invalid sketches", func(t *testing.T) {
+		for _, data := range [][]byte{nil, {1}, {1, 10}, {3, hll.Precision}, {1, hll.Precision, 0, 1}, {2, hll.Precision, 1}} {
+			_, err := hll.Parse(data)
+			assert.ErrorIs(t, err, hll.ErrInvalidSketch)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"accesslog-tracker/internal/utils/lru"
+)
+
+func TestLRU_Eviction(t *testing.T) {
+	cache := lru.New[string, int](lru.Config{Size: 2})
+
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+	// a を使うと b が最も長く使われていないエントリになる
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+	cache.Add("c", 3)
+
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	cache.Remove("a")
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+
+	cache.Purge()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := lru.New[string, string](lru.Config{
+		Size:  10,
+		TTL:   30 * time.Second,
+		Clock: func() time.Time { return now },
+	})
+
+	cache.Add("key", "value")
+	now = now.Add(29 * time.Second)
+	value, ok := cache.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+
+	now = now.Add(time.Second)
+	_, ok = cache.Get("key")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}